@@ -1,21 +1,21 @@
 package UI
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"tradovate-execution-engine/engine/config"
-	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/contracts"
 	"tradovate-execution-engine/engine/internal/execution"
 	"tradovate-execution-engine/engine/internal/logger"
-	"tradovate-execution-engine/engine/internal/marketdata"
 	"tradovate-execution-engine/engine/internal/models"
 	"tradovate-execution-engine/engine/internal/portfolio"
-	"tradovate-execution-engine/engine/internal/tradovate"
 
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/textarea"
@@ -66,10 +66,16 @@ var (
 
 	disabledStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240"))
-)
 
-var (
-	sessionStart time.Time
+	killSwitchStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("196")).
+			Foreground(lipgloss.Color("231")).
+			Bold(true)
+
+	liveAccountStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("202")).
+				Foreground(lipgloss.Color("231")).
+				Bold(true)
 )
 
 const (
@@ -92,14 +98,17 @@ const (
 	modeEditor
 )
 
-const (
-	StrategyDisabled StrategyStatus = iota
-	StrategyStarting
-	StrategyRunning
-	StrategyStopping
-	StrategyStopped
-	StrategyError
-)
+// closeSettleTime is how long a filled :close waits for its position update
+// before an open position is reported
+const closeSettleTime = 2 * time.Second
+
+// WithDemoData makes connecting use the engine's synthetic market, so the UI
+// runs offline without credentials
+func (m model) WithDemoData() model {
+	m.demoData = true
+	m.mainLogger.Warn("Demo data: connecting uses a synthetic market, nothing reaches Tradovate")
+	return m
+}
 
 func InitialModel() model {
 	// Create Loggers
@@ -113,6 +122,23 @@ func InitialModel() model {
 	mainLog.Println("System initialized")
 	mainLog.Printf("Starting trading engine v1.0.0")
 
+	engine := app.NewEngine(mainLog, orderLog, strategyLog)
+
+	// Log levels from an existing config apply before the first connect
+	if data, err := os.ReadFile(config.GetConfigPath()); err == nil {
+		if cfg, err := config.ParseConfig(data); err != nil {
+			mainLog.Warnf("Log levels not applied: %v", err)
+		} else if err := engine.ApplyLogging(cfg.Logging); err != nil {
+			mainLog.Warnf("Log levels not applied: %v", err)
+		}
+	}
+
+	return newModel(engine, mainLog, orderLog, strategyLog)
+}
+
+// newModel builds the UI state around an engine and its three logs, with the
+// command registry listed on the Commands page
+func newModel(engine *app.Engine, mainLog, orderLog, strategyLog *logger.Logger) model {
 	// Initialize Editor
 	ta := textarea.New()
 	ta.Placeholder = "Config content..."
@@ -120,6 +146,7 @@ func InitialModel() model {
 
 	availableStrats := execution.GetAvailableStrategies()
 	mainLog.Infof("Discovered %d registered strategies: %v", len(availableStrats), availableStrats)
+	catalog := execution.DescribeAll()
 
 	return model{
 		activeTab:      TabMain,
@@ -130,9 +157,10 @@ func InitialModel() model {
 		mainLogger:     mainLog,
 		orderLogger:    orderLog,
 		strategyLogger: strategyLog,
+		engine:         engine,
 
 		configPath:           config.GetConfigPath(),
-		strategyName:         "No strategy selected",
+		strategyStatePath:    app.StrategyStatePath(),
 		logScrollOffset:      1000000,
 		orderLogScrollOffset: 1000000,
 		stratLogScrollOffset: 1000000,
@@ -140,40 +168,39 @@ func InitialModel() model {
 		historyIndex:         0,
 		configEditor:         ta,
 		availableStrategies:  availableStrats,
-		strategyParams:       make(map[string]string),
+		strategyCatalog:      catalog,
+		strategies:           make(map[string]*StrategyState),
+		pendingCloses:        make(map[string]pendingClose),
 
 		// Empty data - will be populated from OrderManager
 		positions:  []PositionRow{},
 		orders:     []OrderRow{},
 		pnlHistory: []PnLDataPoint{},
-		commands: []Command{
-			{Name: "buy", Description: "Place a buy order", Usage: ":buy <symbol> <quantity>", Category: "Trading"},
-			{Name: "sell", Description: "Place a sell order", Usage: ":sell <symbol> <quantity>", Category: "Trading"},
-			{Name: "flatten", Description: "Flatten all positions", Usage: ":flatten", Category: "Trading"},
-			{Name: "mode", Description: "Switch trading mode (live/visual)", Usage: ":mode <live|visual> or mode <l|v>", Category: "System"},
-			{Name: "config", Description: "Edit configuration", Usage: ":config", Category: "System"},
-			{Name: "strategy", Description: "Select strategy", Usage: ":strategy <name>", Category: "System"},
-			{Name: "export", Description: "Export logs", Usage: ":export <log|orders|strat>", Category: "System"},
-			{Name: "help", Description: "Show commands page", Usage: ":help", Category: "Navigation"},
-			{Name: "quit", Description: "Exit the application", Usage: ":quit or :q", Category: "System"},
-		},
+		commands:   registeredCommands(),
 	}
 }
 
+// attachEngine copies the connected engine's managers and clients into the model
+func (m model) attachEngine() model {
+	m.tm = m.engine.TokenManager()
+	m.om = m.engine.OrderManager()
+	m.broker = m.om
+	m.marketDataClient = m.engine.MarketDataClient()
+	m.marketDataSubscriptionManager = m.engine.MarketData()
+	m.tradingClient = m.engine.TradingClient()
+	m.tradingClientSubscriptionManager = m.engine.Trading()
+	m.pt = m.engine.Portfolio()
+	m.ts = m.engine.TrailingStops()
+	m.connected = true
+	return m
+}
+
 func tickCmd() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
-func (r *StrategyRuntime) SetStatus(s StrategyStatus) {
-	r.status.Store(int32(s))
-}
-
-func (r *StrategyRuntime) Status() StrategyStatus {
-	return StrategyStatus(r.status.Load())
-}
-
 func (m model) Init() tea.Cmd {
 	return tickCmd()
 }
@@ -206,6 +233,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			m.orders = uiOrders
+			m = m.refreshWorkingOrders(execOrders)
 
 			// Use PortfolioTracker as the source of truth if available
 			if m.pt != nil {
@@ -220,31 +248,42 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							Quantity: entry.NetPos,
 							AvgPrice: entry.BuyPrice,
 							PnL:      entry.PL,
+							NetPnL:   entry.PL - m.pt.RoundTripFees(entry.Name, entry.NetPos),
+							Mark:     entry.LastPrice,
+							MarkType: entry.Mark,
 						})
 					}
 					unrealizedTotal += entry.PL
 				}
+				// Stable order so the Positions tab selection doesn't jump between ticks
+				sort.Slice(uiPositions, func(i, j int) bool {
+					return uiPositions[i].Symbol < uiPositions[j].Symbol
+				})
 				m.positions = uiPositions
+				if m.selectedPosition >= len(m.positions) {
+					m.selectedPosition = max(len(m.positions)-1, 0)
+				}
+
+				if len(m.pendingCloses) > 0 {
+					m = m.checkPendingCloses()
+				}
+				m = m.checkPositionAlerts()
+
 				m.unrealizedPnL = m.pt.GetTotalPL()
 				m.dailyrealizedPnL = m.pt.GetRealizedPnL()
 				m.realizedPnL = m.pt.GetSessionRealizedPnL()
 				m.totalPnL = m.unrealizedPnL + m.dailyrealizedPnL
+				m.dailyFees = m.pt.GetFeesSince(time.Time{})
+				m.sessionFees = m.pt.GetFeesSince(m.engine.SessionStart())
 
-				// Check daily loss limit
-				if m.om != nil && m.om.GetRiskManager().IsDailyLossExceeded(m.totalPnL) {
-					// 1. Always flatten if we have open positions
-					if len(m.positions) > 0 {
-						m.mainLogger.Error("Daily loss limit exceeded! Flattening all positions.")
-						m.om.FlattenPositions()
-						m.statusMsg = errorStyle.Render("DAILY LOSS LIMIT REACHED - POSITIONS FLATTENED")
-					}
-
-					// 2. Stop the strategy if it's running
-					if m.currentStrategy != nil && m.currentStrategy.Runtime.Status() == StrategyRunning {
-						m.mainLogger.Error("Daily loss limit exceeded! Stopping strategy.")
-						m.stopCurrentStrategy()
-						m.statusMsg = errorStyle.Render("DAILY LOSS LIMIT REACHED - STRATEGY STOPPED")
-					}
+				// The engine's risk supervisor enforces the daily loss limit; report when it trips
+				riskState := m.engine.RiskState()
+				if riskState.Tripped && !m.riskState.Tripped {
+					m.statusMsg = errorStyle.Render(fmt.Sprintf("DAILY LOSS LIMIT REACHED - %d STRATEGIES STOPPED, POSITIONS FLATTENED", riskState.Stopped))
+				}
+				m.riskState = riskState
+				if m.engine.CheckLossStreak() {
+					m.statusMsg = errorStyle.Render("LOSS STREAK BREAKER TRIPPED - STRATEGIES STOPPED")
 				}
 			}
 
@@ -256,37 +295,143 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					PnL:  m.totalPnL,
 				})
 				// Keep history limited
-				if len(m.pnlHistory) > 100 {
+				if len(m.pnlHistory) > maxPnLHistory {
 					m.pnlHistory = m.pnlHistory[1:]
 				}
 			}
 		}
 
+		for _, s := range m.strategies {
+			if s.Instance.Runtime.Status() == app.StrategyRunning {
+				s.recordMetrics(s.Instance.Strategy().GetMetrics())
+			}
+		}
+
+		if m.om != nil {
+			m = m.checkSchedule(time.Time(msg))
+		}
+		if began, ended := m.engine.CheckNews(time.Time(msg), m.tradingMode == ModeLive); began || ended {
+			if window, held := m.engine.NewsLockout(); held {
+				m.statusMsg = errorStyle.Render(fmt.Sprintf("NEWS LOCKOUT (%s) until %s - strategies paused, :override to trade through", window.Name, window.End.Format("15:04")))
+			} else {
+				m.statusMsg = successStyle.Render("News lockout over - strategies resumed")
+			}
+		}
+		for _, d := range m.engine.CheckPositionDrift(time.Time(msg)) {
+			if inst := m.engine.Strategy(d.StrategyID); inst != nil && inst.Runtime.IsDrifted() {
+				m.statusMsg = errorStyle.Render(fmt.Sprintf("POSITION DRIFT on %s: strategy %+d, broker %+d - %s paused, :resync %s to trade again", d.Symbol, d.Strategy, d.Broker, d.StrategyID, d.StrategyID))
+			} else {
+				m.statusMsg = errorStyle.Render(fmt.Sprintf("POSITION DRIFT on %s: %s synced to the broker's %+d", d.Symbol, d.StrategyID, d.Broker))
+			}
+		}
+		if err := m.engine.CheckTokenRenewal(); err != nil {
+			m.statusMsg = errorStyle.Render("TOKEN RENEWAL FAILED - :reauth to log in again before the session expires")
+		}
+		if dropped := m.engine.CheckOrderQueue(time.Time(msg)); dropped > 0 {
+			m.statusMsg = errorStyle.Render(fmt.Sprintf("%d QUEUED ORDER(S) DROPPED - Tradovate unreachable until the market had moved, see the Order Log", dropped))
+		}
+		if m.engine.CheckMaintenance(time.Time(msg)) {
+			m.statusMsg = "Maintenance window over - reconnecting..."
+			return m, tea.Batch(tickCmd(), m.reconnectCmd())
+		}
+
 		return m, tickCmd()
 
 	case connMsg:
 		if msg.err != nil {
+			m.connected = false
+			var invalid *config.ValidationError
+			if errors.As(msg.err, &invalid) {
+				// The engine logged each problem; the editor lists them too
+				m.configProblems = invalid.Problems
+				m.statusMsg = errorStyle.Render(fmt.Sprintf("Config invalid: %s - :config to fix", strings.Join(invalid.Keys(), ", ")))
+				return m, nil
+			}
 			m.mainLogger.Errorf("Connection error: %v", msg.err)
 			m.statusMsg = errorStyle.Render("Connection failed")
-			m.connected = false
 		}
 		return m, nil
 
 	case connMsgSuccess:
 		m.config = msg.config
-		m.tm = msg.tokenManager
-		m.om = msg.orderManager
-		m.marketDataClient = msg.mdClient
-		m.marketDataSubscriptionManager = msg.mdSubscriber
-		m.tradingClient = msg.tradingClient
-		m.tradingClientSubscriptionManager = msg.tradingSubscriber
-		m.pt = msg.portfolioTracker
-		m.connected = true
-
-		m.mainLogger.Info(">>> CONNECTION SUCCESSFUL <<<")
+		m.configProblems = nil
+		m = m.attachEngine()
+		// Each connection's portfolio numbers its alerts from the start
+		m.alertSeq = 0
+		m.positionAlerts = nil
+
 		m.statusMsg = successStyle.Render("Connected to Tradovate")
+		if m.demoData {
+			m.statusMsg = successStyle.Render("Connected to demo market data")
+		} else if m.engine.LiveConfirmPending() {
+			m.statusMsg = errorStyle.Render("Connected to the LIVE account - :confirm-live before the first order")
+		}
+		return m, nil
+
+	case backtestMsg:
+		if msg.err != nil {
+			m.strategyLogger.Errorf("Backtest failed: %v", msg.err)
+			m.statusMsg = errorStyle.Render("Backtest failed: " + msg.err.Error())
+			return m, nil
+		}
+		m.strategyLogger.Info(">>> BACKTEST COMPLETE <<<")
+		for _, t := range msg.report.Trades {
+			m.strategyLogger.Infof("  %s %d @ %.2f -> %.2f (%s) | PnL: $%.2f",
+				t.Side, t.Quantity, t.EntryPrice, t.ExitPrice, t.ExitTime, t.PnL)
+		}
+		m.strategyLogger.Info(msg.report.Summary())
+		m.statusMsg = successStyle.Render(msg.report.Summary())
+		return m, nil
+
+	case reauthMsg:
+		if msg.err != nil {
+			m.statusMsg = errorStyle.Render("Re-authentication failed: " + msg.err.Error())
+			return m, nil
+		}
+		m.statusMsg = successStyle.Render("Re-authenticated - WebSockets authorized with the new session")
 		return m, nil
 
+	case findMsg:
+		if msg.err != nil {
+			m.statusMsg = errorStyle.Render("Contract search for " + msg.text + " failed: " + msg.err.Error())
+			return m, nil
+		}
+		return m.openContractSearch(msg.text, msg.matches), nil
+
+	case contractMsg:
+		if msg.err != nil {
+			m.statusMsg = errorStyle.Render("Failed to resolve " + msg.root + ": " + msg.err.Error())
+			return m, nil
+		}
+		m.statusMsg = successStyle.Render(fmt.Sprintf("%s resolves to %s", msg.root, msg.contract))
+		return m, nil
+
+	case accountsMsg:
+		if msg.err != nil {
+			m.statusMsg = errorStyle.Render("Failed to list accounts: " + msg.err.Error())
+			return m, nil
+		}
+		activeID, _ := m.tm.GetAccountID()
+		m.mainLogger.Info("Available accounts:")
+		for _, a := range msg.accounts {
+			marker := " "
+			if a.ID == activeID {
+				marker = "*"
+			}
+			m.mainLogger.Infof(" %s %s (%d)", marker, a.Name, a.ID)
+		}
+		m.statusMsg = successStyle.Render(fmt.Sprintf("Trading %s. Set tradovate.accountSpec in config to change", m.tm.GetAccountSpec()))
+		return m, nil
+
+	case shutdownDoneMsg:
+		if msg.timedOut {
+			m.mainLogger.Warn("Shutdown timed out, exiting anyway")
+		} else {
+			m.mainLogger.Info(">>> SHUTDOWN COMPLETE <<<")
+		}
+		m.flushLogs()
+		return m, tea.Quit
+
 	case editorFinishedMsg:
 		if msg.err != nil {
 			m.mainLogger.Errorf("Editor error: %v", msg.err)
@@ -304,9 +449,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Input is ignored until the shutdown sequence finishes
+	if m.shuttingDown {
+		return m, nil
+	}
+
 	// Global keybindings
 	if msg.Type == tea.KeyCtrlC {
-		return m, tea.Quit
+		return m.beginShutdown()
 	}
 
 	switch m.mode {
@@ -328,10 +478,70 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleSearchInput(msg)
 	}
 
+	// A pending cancel prompt takes the next key
+	if m.confirmCancelID != "" {
+		return m.handleCancelConfirm(msg.String()), nil
+	}
+	if m.confirmAction != "" {
+		return m.handleActionConfirm(msg.String()), nil
+	}
+
+	// The strategy catalog scrolls with w/s; other keys close it
+	if m.catalogOpen {
+		var handled bool
+		if m, handled = m.handleStrategyCatalog(msg.String()); handled {
+			return m, nil
+		}
+	}
+
+	// Contract search results scroll the same way
+	if m.findOpen {
+		var handled bool
+		if m, handled = m.handleContractSearch(msg.String()); handled {
+			return m, nil
+		}
+	}
+
+	// A log selection takes its keys; the rest still work while it is shown
+	if m.logSelect != nil {
+		var handled bool
+		if m, handled = m.handleLogSelection(msg.String()); handled {
+			return m, nil
+		}
+	}
+
 	switch msg.String() {
+	case "v":
+		m = m.startLogSelection()
+
+	case "t":
+		m.toggleLogWrap()
+
+	case "j", "k":
+		if m.activeTab == TabOrderManagement {
+			step := 1
+			if msg.String() == "k" {
+				step = -1
+			}
+			m = m.moveOrderSelection(step)
+		}
+
+	case "c":
+		if m.activeTab == TabOrderManagement {
+			m = m.requestCancel()
+		} else if m.activeTab == TabStrategy && m.current() != nil {
+			m.mode = modeCommand
+			m.commandInput = ":clone "
+		}
+
+	case "r":
+		if m.activeTab == TabStrategy {
+			m = m.setMetricsRecording(!m.engine.MetricsRecording())
+		}
+
 	case "q":
 		if m.activeTab != TabMain {
-			return m, tea.Quit
+			return m.beginShutdown()
 		}
 
 	case "a":
@@ -376,30 +586,12 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.mainLogger.Info(">>> DISCONNECTING... <<<")
 			m.connected = false
 
-			m.stopCurrentStrategy()
-
-			if m.tm != nil {
-				m.tm.StopTokenRefreshMonitor()
-			}
-
-			if m.pt != nil {
-				_ = m.pt.Stop()
-				m.pt = nil
-			}
-
-			if m.marketDataSubscriptionManager != nil {
-				_ = m.marketDataSubscriptionManager.UnsubscribeAll()
-			}
-
-			if m.marketDataClient != nil {
-				_ = m.marketDataClient.Disconnect()
-				m.marketDataClient = nil
-			}
-
-			if m.tradingClient != nil {
-				_ = m.tradingClient.Disconnect()
-				m.tradingClient = nil
-			}
+			m.closeConnections()
+			m.tm = nil
+			m.ts = nil
+			m.pt = nil
+			m.marketDataClient = nil
+			m.tradingClient = nil
 
 			m.statusMsg = errorStyle.Render("Disconnected from Tradovate")
 			m.mainLogger.Info(">>> SUCCESSFULLY DISCONNECTED <<<")
@@ -416,7 +608,7 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				content, _ := os.ReadFile(m.configPath)
 				m.configEditor.SetValue(string(content))
 				m.configEditor.SetWidth(m.width - 4)
-				m.configEditor.SetHeight(m.height - 10)
+				m.configEditor.SetHeight(m.height - 12) // Room for the risk help
 				m.mode = modeEditor
 
 				m.config = config
@@ -432,7 +624,7 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.mode = modeCommand
 		m.commandInput = ":strategy "
-		m.statusMsg = "Enter strategy name..."
+		m.statusMsg = "Enter strategy name or instance ID..."
 	case "#": // Shift+3
 		var content string
 		var logName string
@@ -440,7 +632,7 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		switch m.activeTab {
 		case TabStrategy:
 			m.mainLogger.Info(">>> EXPORTING STRATEGY LOG TO FILE... <<<")
-			content = m.strategyLogger.ExportToString()
+			content = m.strategyLogView().ExportToString()
 			logName = "strat_log_"
 		case TabOrderManagement:
 			m.mainLogger.Info(">>> EXPORTING ORDER LOG TO FILE... <<<")
@@ -478,7 +670,7 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.configEditor.SetValue(string(content))
 		m.configEditor.SetWidth(m.width - 4)
-		m.configEditor.SetHeight(m.height - 10)
+		m.configEditor.SetHeight(m.height - 12) // Room for the risk help
 		m.mode = modeEditor
 		m.statusMsg = ""
 		m.mainLogger.Printf(">>> CONFIG EDITOR OPENED: %s <<<", m.configPath)
@@ -497,68 +689,16 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "w", "up":
 		// Scroll up based on context
 		switch m.activeTab {
-		case TabMain:
-			// Calculate max scroll for Main Log to clamp "infinity"
-			availableLines := m.height - 11
-			if availableLines < 1 {
-				availableLines = 1
-			}
-
-			entriesLen := m.mainLogger.Count()
-			maxScroll := entriesLen - availableLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
+		case TabMain, TabOrderManagement, TabStrategy:
 			// Clamp if we are past the bottom (e.g. from auto-scroll)
-			if m.logScrollOffset > maxScroll {
-				m.logScrollOffset = maxScroll
-			}
-
-			if m.logScrollOffset > 0 {
-				m.logScrollOffset--
+			_, offset := m.activeLog()
+			*offset = min(*offset, m.activeLogMaxScroll())
+			if *offset > 0 {
+				*offset--
 			}
-		case TabOrderManagement:
-			// Calculate max scroll for Order Log
-			availableLines := m.height - 11
-			if availableLines < 1 {
-				availableLines = 1
-			}
-
-			entriesLen := m.orderLogger.Count()
-			maxScroll := entriesLen - availableLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
-			// Clamp
-			if m.orderLogScrollOffset > maxScroll {
-				m.orderLogScrollOffset = maxScroll
-			}
-
-			if m.orderLogScrollOffset > 0 {
-				m.orderLogScrollOffset--
-			}
-		case TabStrategy:
-			// Calculate max scroll for Main Log to clamp "infinity"
-			availableLines := m.height - 11
-			if availableLines < 1 {
-				availableLines = 1
-			}
-
-			entriesLen := m.strategyLogger.Count()
-			maxScroll := entriesLen - availableLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
-			// Clamp if we are past the bottom (e.g. from auto-scroll)
-			if m.stratLogScrollOffset > maxScroll {
-				m.stratLogScrollOffset = maxScroll
-			}
-
-			if m.stratLogScrollOffset > 0 {
-				m.stratLogScrollOffset--
+		case TabPositions:
+			if m.selectedPosition > 0 {
+				m.selectedPosition--
 			}
 		default:
 			if m.scrollOffset > 0 {
@@ -569,55 +709,15 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "s", "down":
 		// Scroll down based on context
 		switch m.activeTab {
-		case TabMain:
-			// Calculate max scroll for Main Log
-			availableLines := m.height - 11
-			if availableLines < 1 {
-				availableLines = 1
-			}
-
-			entriesLen := m.mainLogger.Count()
-			maxScroll := entriesLen - availableLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
-			if m.logScrollOffset < maxScroll {
-				m.logScrollOffset++
-			}
-
-		case TabOrderManagement:
-			// Calculate max scroll for Order Log
-			availableLines := m.height - 11
-			if availableLines < 1 {
-				availableLines = 1
-			}
-
-			entriesLen := m.orderLogger.Count()
-			maxScroll := entriesLen - availableLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
-			if m.orderLogScrollOffset < maxScroll {
-				m.orderLogScrollOffset++
+		case TabMain, TabOrderManagement, TabStrategy:
+			_, offset := m.activeLog()
+			if *offset < m.activeLogMaxScroll() {
+				*offset++
 			}
 
-		case TabStrategy:
-			// Calculate max scroll for Order Log
-			availableLines := m.height - 11
-			if availableLines < 1 {
-				availableLines = 1
-			}
-
-			entriesLen := m.strategyLogger.Count()
-			maxScroll := entriesLen - availableLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
-			if m.stratLogScrollOffset < maxScroll {
-				m.stratLogScrollOffset++
+		case TabPositions:
+			if m.selectedPosition < len(m.positions)-1 {
+				m.selectedPosition++
 			}
 
 		default:
@@ -633,6 +733,11 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case "enter":
+		if m.activeTab == TabPositions && m.selectedPosition < len(m.positions) {
+			m = m.closePosition(m.positions[m.selectedPosition].Symbol)
+		}
+
 	case "W":
 		// Go to top (Shift+W)
 		switch m.activeTab {
@@ -649,46 +754,9 @@ func (m model) handleNormalMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "S":
 		// Go to bottom (Shift+S)
 		switch m.activeTab {
-		case TabMain:
-			availableLines := m.height - 11
-			if availableLines < 1 {
-				availableLines = 1
-			}
-
-			entriesLen := m.mainLogger.Count()
-			maxScroll := entriesLen - availableLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
-			m.logScrollOffset = maxScroll
-
-		case TabOrderManagement:
-			availableLines := m.height - 11
-			if availableLines < 1 {
-				availableLines = 1
-			}
-
-			entriesLen := m.orderLogger.Count()
-			maxScroll := entriesLen - availableLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
-			m.orderLogScrollOffset = maxScroll
-		case TabStrategy:
-			availableLines := m.height - 11
-			if availableLines < 1 {
-				availableLines = 1
-			}
-
-			entriesLen := m.strategyLogger.Count()
-			maxScroll := entriesLen - availableLines
-			if maxScroll < 0 {
-				maxScroll = 0
-			}
-
-			m.stratLogScrollOffset = maxScroll
+		case TabMain, TabOrderManagement, TabStrategy:
+			_, offset := m.activeLog()
+			*offset = m.activeLogMaxScroll()
 		default:
 			contentHeight := m.height - 5
 			fullContent := m.renderCommandsContent()
@@ -738,7 +806,18 @@ func (m model) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Any other key ends Tab cycling and keeps the completion typed so far
+	if msg.Type != tea.KeyTab && msg.Type != tea.KeyShiftTab {
+		m.completionBase = ""
+	}
+
 	switch msg.Type {
+	case tea.KeyTab:
+		m = m.completeCommand(1)
+
+	case tea.KeyShiftTab:
+		m = m.completeCommand(-1)
+
 	case tea.KeyEsc:
 		m.mode = modeNormal
 		m.commandInput = ""
@@ -836,13 +915,21 @@ func (m *model) handleEditorMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
 	case tea.KeyCtrlS:
 		// Save content
-		err := os.WriteFile(m.configPath, []byte(m.configEditor.Value()), 0644)
+		cfg, err := m.saveEditorConfig()
 		if err != nil {
 			m.statusMsg = errorStyle.Render("Failed to save config: " + err.Error())
 			m.mainLogger.Errorf("Save failed: %v", err)
 		} else {
+			if m.configProblems != nil {
+				// Keep listing what the save left unfixed
+				m.configProblems = nil
+				var invalid *config.ValidationError
+				if errors.As(cfg.Validate(), &invalid) {
+					m.configProblems = invalid.Problems
+				}
+			}
 			if m.connected {
-				m.statusMsg = successStyle.Render("Config saved. Reconnect (!) to apply changes.")
+				m.statusMsg = successStyle.Render("Config saved. Use :reload to apply risk limits (credentials need a reconnect).")
 			} else {
 				m.statusMsg = successStyle.Render("Config saved successfully")
 			}
@@ -887,474 +974,6 @@ func (m *model) handleEditorMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return *m, cmd
 }
 
-func (m model) executeCommand() (model, tea.Cmd) {
-	// Force scroll to bottom when a command is executed
-	m.logScrollOffset = 1000000
-	m.orderLogScrollOffset = 1000000
-	m.stratLogScrollOffset = 1000000
-
-	cmd := strings.TrimPrefix(m.commandInput, ":")
-	parts := strings.Fields(cmd)
-
-	if len(parts) == 0 {
-		return m, nil
-	}
-
-	switch parts[0] {
-	case "q", "quit", "Q":
-		return m, tea.Quit
-
-	case "buy", "sell":
-		if !m.connected {
-			m.statusMsg = errorStyle.Render("Must be connected to API to trade")
-			return m, nil
-		}
-		if m.om != nil && m.om.GetRiskManager().IsDailyLossExceeded(m.totalPnL) {
-			m.statusMsg = errorStyle.Render("Trading disabled: daily loss limit exceeded")
-			return m, nil
-		}
-		if m.tradingMode != ModeLive {
-			m.statusMsg = errorStyle.Render("Cannot place orders in Visual mode. Switch to Live mode with :mode live")
-			m.mainLogger.Errorf("Order rejected: Not in Live mode")
-			return m, nil
-		}
-		if len(parts) < 3 {
-			m.statusMsg = errorStyle.Render("Usage: :" + parts[0] + " <symbol> <quantity>")
-			return m, nil
-		}
-
-		symbol := parts[1]
-		qtyStr := parts[2]
-
-		if !strings.HasPrefix(qtyStr, "") {
-			m.statusMsg = errorStyle.Render("Usage: :" + parts[0] + " <symbol> <quantity>")
-			return m, nil
-		}
-
-		var qty int
-		if _, err := fmt.Sscanf(qtyStr, "%d", &qty); err != nil {
-			m.statusMsg = errorStyle.Render("Invalid quantity format. Use a number")
-			return m, nil
-		}
-
-		if m.om == nil {
-			m.statusMsg = errorStyle.Render("Order Manager not initialized")
-			m.mainLogger.Error("Order Manager not initialized")
-			return m, nil
-		}
-
-		side := models.SideBuy
-		if parts[0] == "sell" {
-			side = models.SideSell
-		}
-
-		m.mainLogger.Printf("Submitting %s order for %d %s...", strings.ToUpper(parts[0]), qty, symbol)
-
-		order, err := m.om.SubmitMarketOrder(symbol, side, qty)
-		if err != nil {
-			m.statusMsg = errorStyle.Render("Order failed: " + err.Error())
-			m.mainLogger.Errorf("Order failed: %v", err)
-			return m, nil
-		}
-
-		m.statusMsg = successStyle.Render(fmt.Sprintf("%s order placed for %s (ID: %s)", strings.ToUpper(parts[0]), symbol, order.ID))
-		m.mainLogger.Printf("%s order placed for %s (ID: %s)", strings.ToUpper(parts[0]), symbol, order.ID)
-		m.orderLogger.Printf("%s %s - Price: Market, Qty: %d, ID: %s", strings.ToUpper(parts[0]), symbol, qty, order.ID)
-
-	case "flatten":
-		if !m.connected {
-			m.statusMsg = errorStyle.Render("Must be connected to API to flatten positions")
-			return m, nil
-		}
-		if m.tradingMode != ModeLive {
-			m.statusMsg = errorStyle.Render("Cannot flatten in Visual mode")
-			m.mainLogger.Errorf("Flatten rejected: Not in Live mode")
-			return m, nil
-		}
-
-		if m.om != nil {
-			if len(m.positions) == 0 {
-				m.mainLogger.Error("Flatten failed: No Open Positions")
-				m.statusMsg = errorStyle.Render("Flatten failed: No Open Positions")
-				return m, nil
-			}
-			m.stopCurrentStrategy()
-
-			if err := m.om.FlattenPositions(); err != nil {
-				m.statusMsg = errorStyle.Render("Flatten failed: " + err.Error())
-				return m, nil
-			}
-		}
-		m.statusMsg = successStyle.Render("All positions flattened")
-		m.mainLogger.Info("All positions flattened")
-		m.orderLogger.Info("FLATTEN - All positions closed")
-
-	case "mode":
-		if len(parts) < 2 {
-			m.statusMsg = errorStyle.Render("Usage: :mode <live|visual>")
-			return m, nil
-		}
-		switch strings.ToLower(parts[1]) {
-		case "l", "L":
-			m.tradingMode = ModeLive
-			m.statusMsg = successStyle.Render("Switched to LIVE mode")
-			m.mainLogger.Info("Switched to LIVE trading mode")
-		case "v", "V":
-			m.tradingMode = ModeVisual
-			m.statusMsg = "Switched to VISUAL mode"
-			m.mainLogger.Info("Switched to VISUAL mode")
-		default:
-			m.statusMsg = errorStyle.Render("Invalid mode. Use l for'live' or v for 'visual'")
-		}
-
-	case "config":
-		content, err := os.ReadFile(m.configPath)
-		if err != nil {
-			m.statusMsg = errorStyle.Render("Failed to read config: " + err.Error())
-			return m, nil
-		}
-		m.isLogView = false
-		m.editorTitle = "CONFIG EDITOR"
-		m.configEditor.SetValue(string(content))
-		m.configEditor.SetWidth(m.width - 4)
-		m.configEditor.SetHeight(m.height - 10)
-		m.mode = modeEditor
-		m.statusMsg = "Editing config. Press Ctrl+S to save, ESC to exit, or : to run commands"
-		return m, nil
-
-	case "w", "write":
-		if m.configEditor.Value() != "" && !m.isLogView {
-			err := os.WriteFile(m.configPath, []byte(m.configEditor.Value()), 0644)
-			if err != nil {
-				m.statusMsg = errorStyle.Render("Failed to save config: " + err.Error())
-			} else {
-				m.statusMsg = successStyle.Render("Config saved")
-			}
-		}
-		return m, nil
-
-	case "x":
-		// Save and exit
-		if m.configEditor.Value() != "" && !m.isLogView {
-			_ = os.WriteFile(m.configPath, []byte(m.configEditor.Value()), 0644)
-		}
-		m.mode = modeNormal
-		m.isLogView = false
-		m.statusMsg = "" // Reset status
-		return m, nil
-
-	case "export":
-		if len(parts) < 2 {
-			m.statusMsg = errorStyle.Render("Usage: :export <main|orders|strat>")
-			return m, nil
-		}
-		logsDir := filepath.Join(config.GetProjectRoot(), "external", "logs")
-		_ = os.MkdirAll(logsDir, 0755)
-		switch parts[1] {
-		case "main":
-			content := m.mainLogger.ExportToString()
-			filename := filepath.Join(logsDir, "main_log_"+time.Now().Format("January 2, 2006 3:04:05 PM")+".txt")
-			err := os.WriteFile(filename, []byte(content), 0644)
-			if err != nil {
-				m.statusMsg = errorStyle.Render("Export failed: " + err.Error())
-			} else {
-				m.statusMsg = successStyle.Render("Log exported to " + filename)
-				m.mainLogger.Printf("Main log exported to %s", filename)
-			}
-			return m, nil
-		case "orders":
-			content := m.orderLogger.ExportToString()
-			filename := filepath.Join(logsDir, "orders_log_"+time.Now().Format("January 2, 2006 3:04:05 PM")+".txt")
-			err := os.WriteFile(filename, []byte(content), 0644)
-			if err != nil {
-				m.statusMsg = errorStyle.Render("Export failed: " + err.Error())
-			} else {
-				m.statusMsg = successStyle.Render("Order log exported to " + filename)
-				m.mainLogger.Printf("Order log exported to %s", filename)
-			}
-			return m, nil
-		case "strat":
-			content := m.strategyLogger.ExportToString()
-			filename := filepath.Join(logsDir, "strat_log_"+time.Now().Format("January 2, 2006 3:04:05 PM")+".txt")
-			err := os.WriteFile(filename, []byte(content), 0644)
-			if err != nil {
-				m.statusMsg = errorStyle.Render("Export failed: " + err.Error())
-			} else {
-				m.statusMsg = successStyle.Render("Order log exported to " + filename)
-				m.mainLogger.Printf("Order log exported to %s", filename)
-			}
-			return m, nil
-		default:
-			m.statusMsg = errorStyle.Render("Invalid export target. Use 'log' or 'orders'")
-		}
-
-	case "help":
-		m.activeTab = TabCommands
-		m.statusMsg = "Switched to Commands"
-
-	case "strategy":
-
-		if m.currentStrategy != nil && m.currentStrategy.Runtime.Status() == StrategyRunning {
-			m.statusMsg = errorStyle.Render("Cannot change strategy while running. Stop it first")
-			return m, nil
-		}
-		if len(parts) < 2 {
-			m.statusMsg = errorStyle.Render("Usage: :strategy <name>")
-			return m, nil
-		}
-		stratName := parts[1]
-		strat, err := execution.CreateStrategy(stratName, m.strategyLogger)
-		if err != nil {
-			m.statusMsg = errorStyle.Render("Failed to load strategy: " + err.Error())
-			return m, nil
-		}
-
-		m.activeTab = TabStrategy
-		m.scrollOffset = 0
-
-		m.selectedStrategy = stratName
-		m.currentStrategy = &StrategyState{
-			Name:        strat.Name(),
-			Params:      strat.GetParams(),
-			Instance:    strat,
-			Description: strat.Description(),
-			Runtime:     &StrategyRuntime{},
-		}
-
-		// Reset params
-		m.strategyParams = make(map[string]string)
-		for _, p := range m.currentStrategy.Params {
-			m.strategyParams[p.Name] = fmt.Sprintf("%v", p.Value)
-		}
-
-		m.strategyName = strat.Name()
-		m.statusMsg = successStyle.Render("Loaded strategy: " + strat.Name())
-		m.strategyLogger.Printf("Loaded strategy: %s", strat.Name())
-
-	case "set":
-		if m.currentStrategy != nil && m.currentStrategy.Runtime.Status() == StrategyRunning {
-			m.statusMsg = errorStyle.Render("Cannot change parameters while strategy is running. Stop it first")
-			return m, nil
-		}
-		if m.currentStrategy == nil {
-			m.statusMsg = errorStyle.Render("No strategy selected. Use :strategy <name> first")
-			return m, nil
-		}
-		if len(parts) < 3 {
-			m.statusMsg = errorStyle.Render("Usage: :set <param> <value>")
-			return m, nil
-		}
-		paramName := parts[1]
-		paramValue := parts[2]
-
-		found := false
-		for _, p := range m.currentStrategy.Params {
-			if p.Name == paramName {
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			m.statusMsg = errorStyle.Render("Unknown parameter: " + paramName)
-			return m, nil
-		}
-
-		m.strategyParams[paramName] = paramValue
-		m.statusMsg = successStyle.Render(fmt.Sprintf("Set %s = %s", paramName, paramValue))
-		m.strategyLogger.Printf("Parameter set: %s = %s", paramName, paramValue)
-
-	case "start":
-		if m.currentStrategy == nil {
-			m.statusMsg = errorStyle.Render("No strategy selected")
-			return m, nil
-		}
-		if m.om != nil && m.om.GetRiskManager().IsDailyLossExceeded(m.totalPnL) {
-			m.statusMsg = errorStyle.Render("Cannot start strategy: daily loss limit exceeded")
-			m.mainLogger.Error("Cannot start strategy: daily loss limit exceeded")
-			return m, nil
-		}
-		if m.currentStrategy.Runtime.Status() == StrategyRunning {
-			m.statusMsg = errorStyle.Render("Strategy is already running")
-			return m, nil
-		}
-		if !m.connected {
-			m.statusMsg = errorStyle.Render("Must be connected to start strategy")
-			return m, nil
-		}
-
-		// Apply params
-		for k, v := range m.strategyParams {
-			if err := m.currentStrategy.Instance.SetParam(k, v); err != nil {
-				m.statusMsg = errorStyle.Render("Failed to set param " + k + ": " + err.Error())
-				return m, nil
-			}
-		}
-
-		// Init strategy
-		if err := m.currentStrategy.Instance.Init(m.om); err != nil {
-			m.statusMsg = errorStyle.Render("Failed to initialize strategy: " + err.Error())
-			return m, nil
-		}
-
-		m.currentStrategy.Symbol = m.strategyParams["symbol"]
-
-		m.currentStrategy.Runtime.SetStatus(StrategyStarting)
-
-		m.statusMsg = successStyle.Render("Strategy STARTED")
-		m.strategyLogger.Info(">>> STRATEGY STARTED <<<")
-
-		var lastBar LastBar
-
-		var historicalLoaded bool
-
-		m.marketDataSubscriptionManager.AddChartHandler(func(update marketdata.ChartUpdate) {
-			m.strategyLogger.Debugf("CHART UPDATE RECEIVED at %s", time.Now().Format("15:04:05"))
-			m.strategyLogger.Debugf("Chart handler called with %d charts", len(update.Charts))
-
-			for _, chart := range update.Charts {
-				m.strategyLogger.Debugf("Chart ID: %d | Bars: %d | EOH: %v",
-					chart.ID, len(chart.Bars), chart.EOH)
-
-				// Check for end of history marker
-				if chart.EOH {
-					m.strategyLogger.Debug("End of historical data - now receiving live updates")
-
-					// Enable strategy for live trading
-					if s, ok := m.currentStrategy.Instance.(interface{ SetEnabled(bool) }); ok {
-						s.SetEnabled(true)
-						m.strategyLogger.Info("Strategy enabled for LIVE trading")
-						m.strategyLogger.Debug("tdsubs: ", m.tradingClientSubscriptionManager.GetActiveSubscriptions())
-						m.strategyLogger.Debug("mdsubs: ", m.marketDataSubscriptionManager.GetActiveSubscriptions())
-					}
-
-					historicalLoaded = true
-					continue
-				}
-
-				m.strategyLogger.Debugf("=== Chart ID: %d ===", chart.ID)
-				m.strategyLogger.Debugf("Number of bars: %d", len(chart.Bars))
-
-				if !historicalLoaded {
-					// Process each bar
-					for _, bar := range chart.Bars {
-						// Skip only exact duplicates (same timestamp AND same close price)
-						if bar.Timestamp == lastBar.Timestamp && bar.Close == lastBar.Close {
-							continue
-						}
-						lastBar = LastBar{Timestamp: bar.Timestamp, Close: bar.Close}
-
-						// Update Strategy
-						if s, ok := m.currentStrategy.Instance.(interface {
-							OnBar(string, float64) error
-						}); ok {
-							s.OnBar(bar.Timestamp, bar.Close)
-						}
-
-					}
-				}
-			}
-		})
-
-		m.strategyLogger.Debug("Chart Handler Added")
-
-		var barAgg = &BarAggregator{firstTick: true}
-
-		livebarcounter := 0
-
-		m.marketDataSubscriptionManager.AddQuoteHandler(func(quote marketdata.Quote) {
-
-			if !historicalLoaded {
-				return
-			}
-
-			if trade, ok := quote.Entries["Trade"]; ok {
-				price := trade.Price
-
-				// Use the quote's actual timestamp, not time.Now()
-				quoteTime, err := time.Parse(time.RFC3339, quote.Timestamp)
-				if err != nil {
-					return
-				}
-
-				currentMinute := quoteTime.UTC().Truncate(time.Minute).Format("2006-01-02T15:04Z")
-
-				// New minute = new bar
-				if currentMinute != barAgg.currentMinute {
-					// Close previous bar if exists
-					if !barAgg.firstTick {
-
-						// Update Strategy
-						if s, ok := m.currentStrategy.Instance.(interface {
-							OnBar(string, float64) error
-						}); ok {
-							s.OnBar(barAgg.currentMinute, barAgg.close)
-						}
-
-						livebarcounter++
-					}
-
-					// Start new bar
-					barAgg.currentMinute = currentMinute
-					barAgg.open = price
-					barAgg.high = price
-					barAgg.low = price
-					barAgg.close = price
-					barAgg.firstTick = false
-				} else {
-					// Update current bar
-					if price > barAgg.high {
-						barAgg.high = price
-					}
-					if price < barAgg.low {
-						barAgg.low = price
-					}
-					barAgg.close = price
-				}
-			}
-		})
-
-		m.strategyLogger.Debug("Quote Handler added")
-
-		symbol := m.strategyParams["symbol"]
-		go func() {
-			m.marketDataSubscriptionManager.SubscribeQuote(symbol)
-
-			mdparams := marketdata.HistoricalDataParams{
-				Symbol: symbol,
-				ChartDescription: marketdata.ChartDesc{
-					UnderlyingType:  "MinuteBar",
-					ElementSize:     1,
-					ElementSizeUnit: "UnderlyingUnits",
-				},
-				TimeRange: marketdata.TimeRange{
-					ClosestTimestamp: time.Now().Format(time.RFC3339),
-					AsMuchAsElements: 25,
-				},
-			}
-
-			err2 := m.marketDataSubscriptionManager.GetChart(mdparams)
-			if err2 != nil {
-				m.strategyLogger.Errorf("Failed to get chart: %v", err2)
-			}
-
-			m.currentStrategy.Runtime.SetStatus(StrategyRunning)
-
-			m.strategyLogger.Debug("tdsubs: ", m.tradingClientSubscriptionManager.GetActiveSubscriptions())
-			m.strategyLogger.Debug("mdsubs: ", m.marketDataSubscriptionManager.GetActiveSubscriptions())
-		}()
-
-	case "stop":
-
-		m.stopCurrentStrategy()
-
-	default:
-		m.statusMsg = errorStyle.Render(fmt.Sprintf("Unknown command: %s", parts[0]))
-	}
-
-	return m, nil
-}
-
 func (m model) View() string {
 	if m.width == 0 {
 		return "Initializing..."
@@ -1397,18 +1016,64 @@ func (m model) renderTabs() string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
 }
 
+// configHelp explains the risk limits below the config editor
+const configHelp = `risk.symbols overrides maxContracts, maxOrderQty and dailyLossLimit per product root,
+e.g. "symbols": {"CL": {"maxContracts": 1, "dailyLossLimit": 300}}. Unset fields use the global limit.`
+
+// saveEditorConfig writes the config editor's text once it parses and
+// validates, so a bad edit is not left for the next load to fail on
+func (m model) saveEditorConfig() (*config.Config, error) {
+	text := m.configEditor.Value()
+	cfg, err := config.ParseConfig([]byte(text))
+	if err != nil {
+		return nil, err
+	}
+	return cfg, os.WriteFile(m.configPath, []byte(text), 0644)
+}
+
+// configProblemsHelp lists the keys the last failed validation found wrong
+func (m model) configProblemsHelp() string {
+	if len(m.configProblems) == 0 {
+		return ""
+	}
+	lines := []string{"Fix before connecting:"}
+	for _, p := range m.configProblems {
+		lines = append(lines, "  "+p.Error())
+	}
+	return "\n" + errorStyle.Render(strings.Join(lines, "\n"))
+}
+
 func (m model) renderContent() string {
 	contentHeight := m.height - 5
 
 	if m.mode == modeEditor {
-		footer := "[Ctrl+S: Save | ESC: Cancel]"
+		footer := "[Ctrl+S: Save | ESC: Cancel]\n" + configHelp + m.configProblemsHelp()
 		if m.isLogView {
 			footer = "[Ctrl+E: Export to File | Ctrl+C: Copy | Ctrl+A: All | ESC: Exit]"
 		}
 		return contentStyle.
 			Width(m.width - 4).
 			Height(contentHeight).
-			Render(fmt.Sprintf("═══ %s ═══\n\n%s\n\n%s", m.editorTitle, m.configEditor.View(), footer))
+			Render(fmt.Sprintf("═══ %s ═══\n\n%s\n\n%s", m.editorTitle, m.configEditor.View(), footer))
+	}
+
+	if m.confirmAction != "" {
+		return contentStyle.
+			Width(m.width - 4).
+			Height(contentHeight).
+			Render(m.renderActionConfirm())
+	}
+	if m.catalogOpen {
+		return contentStyle.
+			Width(m.width - 4).
+			Height(contentHeight).
+			Render(m.renderStrategyCatalog())
+	}
+	if m.findOpen {
+		return contentStyle.
+			Width(m.width - 4).
+			Height(contentHeight).
+			Render(m.renderContractSearch())
 	}
 
 	var content string
@@ -1465,6 +1130,12 @@ func (m model) renderMainHub(contentHeight int) string {
 	if !m.connected {
 		connColor = "196" // Red
 		connText = "DISCONNECTED"
+	} else if m.engine.InMaintenance() {
+		connColor = "214" // Orange
+		connText = "MAINTENANCE"
+	} else if !m.feedsHealthy() {
+		connColor = "214" // Orange
+		connText = "STALE"
 	}
 
 	modeText := "VISUAL"
@@ -1481,7 +1152,7 @@ func (m model) renderMainHub(contentHeight int) string {
 	)
 
 	leftPanel.WriteString(header + "\n\n")
-	leftPanel.WriteString(fmt.Sprintf("Strategy: %s\n", m.strategyName))
+	leftPanel.WriteString(fmt.Sprintf("Strategy: %s\n", m.strategyLabel(m.current())))
 	leftPanel.WriteString(strings.Repeat("─", leftWidth-4) + "\n")
 
 	// Menu Items (Compact)
@@ -1542,16 +1213,22 @@ func (m model) renderMainHub(contentHeight int) string {
 
 	// Right panel - Logger with scroll
 	// Pass the exact calculation for width to avoid wrapping issues
-	rightContent := m.renderLogPanel(rightWidth, contentHeight, "System Log", m.mainLogger, &m.logScrollOffset)
+	rightContent := m.renderLogPanel(rightWidth, contentHeight, "System Log", m.mainLogger, &m.logScrollOffset, m.logWraps(TabMain))
 
 	// Combine panels
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftContent, rightContent)
 }
 
-func (m model) renderLogPanel(width, height int, title string, log *logger.Logger, scrollOffset *int) string {
+func (m model) renderLogPanel(width, height int, title string, log *logger.Logger, scrollOffset *int, wrap bool) string {
 	var logContent strings.Builder
 
 	entries := log.GetEntries()
+	if level := log.MinLevel(); level != logger.LevelInfo {
+		title += " [" + string(level) + "]"
+	}
+	if !wrap {
+		title += " [truncated]"
+	}
 
 	// Layout Math:
 	// Total Height = height
@@ -1574,10 +1251,15 @@ func (m model) renderLogPanel(width, height int, title string, log *logger.Logge
 		availableLines = 1
 	}
 
-	maxScroll := len(entries) - availableLines
-	if maxScroll < 0 {
-		maxScroll = 0
+	logWidth := width - 4 // Border (2) + Padding (2)
+	if logWidth < 20 {
+		logWidth = 20
 	}
+	// Time(8) + space + Level(5) + space + msg
+	maxMsgLen := logMsgWidth(width)
+
+	// Wrapped entries take several lines, so count back from the newest
+	maxScroll := logScrollTo(entries, len(entries)-1, availableLines, maxMsgLen, wrap)
 
 	// Improved Auto-scroll logic:
 	// If we were at the bottom (or very close), stay at the bottom even if many logs arrived.
@@ -1593,31 +1275,19 @@ func (m model) renderLogPanel(width, height int, title string, log *logger.Logge
 		*scrollOffset = 0
 	}
 
-	// Get visible entries
-	startIdx := *scrollOffset
-	endIdx := *scrollOffset + availableLines
-	if endIdx > len(entries) {
-		endIdx = len(entries)
-	}
-	if startIdx >= len(entries) {
-		startIdx = len(entries) - 1
-		if startIdx < 0 {
-			startIdx = 0
+	selFirst, selLast, selected := -1, -1, 0
+	if sel := m.logSelectionFor(log); sel != nil {
+		if first, last, ok := sel.selectedRange(); ok {
+			kept := log.FirstIndex()
+			selFirst, selLast = first-kept, last-kept
+			selected = last - first + 1
 		}
 	}
 
-	visibleEntries := []logger.LogEntry{}
-	if startIdx < len(entries) {
-		visibleEntries = entries[startIdx:endIdx]
-	}
-
-	// Render log entries
-	logWidth := width - 4 // Border (2) + Padding (2)
-	if logWidth < 20 {
-		logWidth = 20
-	}
-
-	for _, entry := range visibleEntries {
+	// Render entries from the offset while they fit
+	linesRendered := 0
+	for row := *scrollOffset; row < len(entries) && linesRendered < availableLines; row++ {
+		entry := entries[row]
 		timeStr := entry.Timestamp.Format("15:04:05")
 		levelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 
@@ -1632,26 +1302,37 @@ func (m model) renderLogPanel(width, height int, title string, log *logger.Logge
 			levelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 		}
 
-		// Truncate message if too long
-		msg := entry.Message
-		// Time(8) + space + Level(5) + space + msg
-		prefixLen := 15
-		maxMsgLen := logWidth - prefixLen
-		if maxMsgLen < 10 {
-			maxMsgLen = 10
-		}
-		if len(msg) > maxMsgLen {
-			msg = msg[:maxMsgLen-3] + "..."
+		// Wrap the message, or truncate it if too long
+		var msgLines []string
+		if wrap {
+			msgLines = wrapLogMessage(entry.Message, maxMsgLen)
+		} else {
+			msg := strings.ReplaceAll(entry.Message, "\n", " ")
+			if len(msg) > maxMsgLen {
+				msg = msg[:maxMsgLen-3] + "..."
+			}
+			msgLines = []string{msg}
 		}
+		// An entry taller than the panel shows as much of it as fits
+		msgLines = msgLines[:min(len(msgLines), availableLines-linesRendered)]
 
-		logContent.WriteString(fmt.Sprintf("%s %s %s\n",
-			timeStr,
-			levelStyle.Render(fmt.Sprintf("%-5s", entry.Level)),
-			msg))
+		for i, msg := range msgLines {
+			line := fmt.Sprintf("%s %-5s %s", timeStr, entry.Level, msg)
+			if i > 0 {
+				line = strings.Repeat(" ", logPrefixLen) + msg
+			}
+			switch {
+			case row >= selFirst && row <= selLast:
+				line = selectedLogStyle.Render(line)
+			case i == 0:
+				line = fmt.Sprintf("%s %s %s", timeStr, levelStyle.Render(fmt.Sprintf("%-5s", entry.Level)), msg)
+			}
+			logContent.WriteString(line + "\n")
+		}
+		linesRendered += len(msgLines)
 	}
 
 	// Fill empty lines to maintain height stability if not enough entries
-	linesRendered := len(visibleEntries)
 	if linesRendered < availableLines {
 		logContent.WriteString(strings.Repeat("\n", availableLines-linesRendered))
 	}
@@ -1662,9 +1343,12 @@ func (m model) renderLogPanel(width, height int, title string, log *logger.Logge
 		scrollPercent = float64(*scrollOffset) / float64(maxScroll) * 100
 	}
 	indicator := fmt.Sprintf("[%d/%d %.0f%%]", *scrollOffset+1, len(entries), scrollPercent)
-	if len(entries) <= availableLines {
+	if maxScroll == 0 {
 		indicator = "[All]"
 	}
+	if selected > 0 {
+		indicator = fmt.Sprintf("[%d selected - y copy, Esc cancel] ", selected) + indicator
+	}
 
 	logContent.WriteString("\n" + lipgloss.NewStyle().
 		Foreground(lipgloss.Color("244")).
@@ -1752,13 +1436,45 @@ func (m model) renderOrderManagement(contentHeight int) string {
 		unrealizedStyle = errorStyle
 	}
 
-	leftPanel.WriteString(fmt.Sprintf("%-22s %s\n", "Total P&L:", pnlStyle.Render(fmt.Sprintf("$%.2f", m.totalPnL))))
-	leftPanel.WriteString(fmt.Sprintf("%-22s %s\n", "Daily Realized P&L:", dailyRealizedStyle.Render(fmt.Sprintf("$%.2f", m.dailyrealizedPnL))))
-	leftPanel.WriteString(fmt.Sprintf("%-22s %s\n", "Session Realized P&L:", realizedStyle.Render(fmt.Sprintf("$%.2f", m.realizedPnL))))
+	// Gross, then net of the fees of closed trades
+	grossNet := func(style lipgloss.Style, gross, fees float64) string {
+		netStyle := successStyle
+		if gross-fees < 0 {
+			netStyle = errorStyle
+		}
+		return style.Render(fmt.Sprintf("$%.2f", gross)) + " / net " + netStyle.Render(fmt.Sprintf("$%.2f", gross-fees))
+	}
+	leftPanel.WriteString(fmt.Sprintf("%-22s %s\n", "Total P&L:", grossNet(pnlStyle, m.totalPnL, m.dailyFees)))
+	leftPanel.WriteString(fmt.Sprintf("%-22s %s\n", "Daily Realized P&L:", grossNet(dailyRealizedStyle, m.dailyrealizedPnL, m.dailyFees)))
+	leftPanel.WriteString(fmt.Sprintf("%-22s %s\n", "Session Realized P&L:", grossNet(realizedStyle, m.realizedPnL, m.sessionFees)))
 	leftPanel.WriteString(fmt.Sprintf("%-22s %s\n", "Unrealized P&L:", unrealizedStyle.Render(fmt.Sprintf("$%.2f", m.unrealizedPnL))))
+	leftPanel.WriteString(fmt.Sprintf("%-22s $%.2f (session $%.2f)\n", "Fees:", m.dailyFees, m.sessionFees))
 	leftPanel.WriteString("\n")
 	leftPanel.WriteString(fmt.Sprintf("%-22s %d\n", "Open Positions:", len(m.positions)))
 
+	leftPanel.WriteString("\n═══ SESSION P&L ═══\n\n")
+	leftPanel.WriteString(m.renderPnLChart(leftWidth - 2))
+
+	if m.pt != nil {
+		leftPanel.WriteString("\n═══ ACCOUNT ═══\n\n")
+		leftPanel.WriteString(m.renderAccount())
+	}
+
+	if m.om != nil {
+		leftPanel.WriteString("\n═══ RECENT ORDERS ═══\n\n")
+		leftPanel.WriteString(m.renderRecentOrders(8))
+	}
+
+	if m.pt != nil {
+		leftPanel.WriteString("\n═══ CLOSED TRADES ═══\n\n")
+		leftPanel.WriteString(m.renderClosedTrades(6))
+	}
+
+	if m.om != nil {
+		leftPanel.WriteString("\n═══ EXECUTION LATENCY ═══\n\n")
+		leftPanel.WriteString(m.renderLatency())
+	}
+
 	if m.tradingMode == ModeLive {
 		leftPanel.WriteString("\n\n═══ LIVE ACTIONS ═══\n\n")
 
@@ -1782,11 +1498,147 @@ func (m model) renderOrderManagement(contentHeight int) string {
 		Padding(1).
 		Render(leftPanel.String())
 
-	// Right panel - Order Logger
-	rightContent := m.renderLogPanel(rightWidth, contentHeight, "Order Log", m.orderLogger, &m.orderLogScrollOffset)
+	// Right panel - Working orders above the Order Logger
+	table := m.renderWorkingOrders(rightWidth)
+	logHeight := max(contentHeight-lipgloss.Height(table), 8)
+	rightContent := lipgloss.JoinVertical(lipgloss.Left, table,
+		m.renderLogPanel(rightWidth, logHeight, "Order Log", m.orderLogger, &m.orderLogScrollOffset, m.logWraps(TabOrderManagement)))
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftContent, rightContent)
 }
+
+// renderAccount shows the cash balance, its change on the day and the margin in use
+func (m model) renderAccount() string {
+	b := m.pt.GetAccountBalance()
+	if b.UpdatedAt.IsZero() {
+		return disabledStyle.Render("Waiting for cash balance") + "\n"
+	}
+
+	changeStyle := successStyle
+	if b.DayChange() < 0 {
+		changeStyle = errorStyle
+	}
+	balanceStyle := lipgloss.NewStyle()
+	if m.config != nil && m.config.Risk.MinBalanceWarning > 0 && b.Amount < m.config.Risk.MinBalanceWarning {
+		balanceStyle = errorStyle
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-22s %s\n", "Balance:", balanceStyle.Render(fmt.Sprintf("$%.2f", b.Amount))))
+	sb.WriteString(fmt.Sprintf("%-22s $%.2f\n", "Start of Day:", b.AmountSOD))
+	sb.WriteString(fmt.Sprintf("%-22s %s\n", "Day Change:", changeStyle.Render(fmt.Sprintf("$%.2f", b.DayChange()))))
+	sb.WriteString(fmt.Sprintf("%-22s $%.2f\n", "Week Realized P&L:", b.WeekRealizedPnL))
+	if available, ok := m.pt.GetAvailableMargin(); ok {
+		sb.WriteString(fmt.Sprintf("%-22s $%.2f\n", "Initial Margin:", b.InitialMargin))
+		sb.WriteString(fmt.Sprintf("%-22s $%.2f\n", "Available Margin:", available))
+	}
+	return sb.String()
+}
+
+// renderRecentOrders lists the newest orders with their fill progress
+func (m model) renderRecentOrders(limit int) string {
+	orders := m.om.GetAllOrders()
+	if len(orders) == 0 {
+		return disabledStyle.Render("No orders this session") + "\n"
+	}
+
+	m.om.Mu.RLock()
+	defer m.om.Mu.RUnlock()
+
+	sort.Slice(orders, func(i, j int) bool { return orders[i].SubmittedAt.After(orders[j].SubmittedAt) })
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+
+	var b strings.Builder
+	for _, o := range orders {
+		fill := fmt.Sprintf("%d/%d", o.FilledQty(), o.Quantity)
+		if avg := o.AvgFillPrice(); avg > 0 {
+			fill += fmt.Sprintf(" @ %.2f", avg)
+		}
+		if o.IsExternal() {
+			fill += disabledStyle.Render("  external")
+		}
+		b.WriteString(fmt.Sprintf("%-4s %-8s %-14s %s %s\n",
+			o.Side, o.Symbol, o.Describe(), orderStatusStyle(o.Status).Render(fmt.Sprintf("%-16s", o.Status)), fill))
+	}
+	return b.String()
+}
+
+// renderClosedTrades lists the latest closed trades, oldest first, with their PnL
+func (m model) renderClosedTrades(limit int) string {
+	trades := m.pt.GetClosedTrades()
+	if len(trades) == 0 {
+		return disabledStyle.Render("No closed trades today") + "\n"
+	}
+	if len(trades) > limit {
+		trades = trades[len(trades)-limit:]
+	}
+
+	var b strings.Builder
+	b.WriteString(disabledStyle.Render(fmt.Sprintf("%-8s %-8s %-5s %3s %9s %9s %9s %9s", "Time", "Symbol", "Side", "Qty", "Entry", "Exit", "PnL", "Net")) + "\n")
+	for _, t := range trades {
+		exit := "--:--:--"
+		if !t.ExitTime.IsZero() {
+			exit = t.ExitTime.Local().Format("15:04:05")
+		}
+		pnlStyle, netStyle := successStyle, successStyle
+		if t.PnL < 0 {
+			pnlStyle = errorStyle
+		}
+		if t.NetPnL < 0 {
+			netStyle = errorStyle
+		}
+		b.WriteString(fmt.Sprintf("%-8s %-8s %-5s %3d %9.2f %9.2f %s %s\n",
+			exit, t.Symbol, t.Side, t.Qty, t.EntryPrice, t.ExitPrice,
+			pnlStyle.Render(fmt.Sprintf("%9.2f", t.PnL)), netStyle.Render(fmt.Sprintf("%9.2f", t.NetPnL))))
+	}
+	return b.String()
+}
+
+// renderLatency shows the p50/p95/max of each execution stage for filled live orders
+func (m model) renderLatency() string {
+	stats := m.om.GetExecutionStats()
+	if stats.SignalToFill.Count == 0 {
+		return disabledStyle.Render("No filled live orders yet") + "\n"
+	}
+
+	var b strings.Builder
+	for _, stage := range []struct {
+		label string
+		stats execution.LatencyStats
+	}{
+		{"Signal → submit", stats.SignalToSubmit},
+		{"Submit → ack", stats.SubmitToAck},
+		{"Ack → fill", stats.AckToFill},
+		{"Signal → fill", stats.SignalToFill},
+	} {
+		b.WriteString(fmt.Sprintf("%-16s %s\n", stage.label, stage.stats))
+	}
+	return b.String()
+}
+
+// orderStatusStyle colours an order status; partial fills, orders queued for a
+// lost connection and bracket legs waiting on their parent stand out from
+// finished orders
+func orderStatusStyle(status models.OrderStatus) lipgloss.Style {
+	switch status {
+	case models.StatusFilled:
+		return successStyle
+	case models.StatusPartiallyFilled:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	case models.StatusQueued:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("141")).Bold(true)
+	case models.StatusSuspended:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("75")).Italic(true)
+	case models.StatusRejected, models.StatusFailed:
+		return errorStyle
+	case models.StatusCanceled, models.StatusExpired:
+		return disabledStyle
+	}
+	return lipgloss.NewStyle()
+}
+
 func (m model) renderStrategyTab(contentHeight int) string {
 	// Total available width
 	availableWidth := m.width
@@ -1816,72 +1668,88 @@ func (m model) renderStrategyTab(contentHeight int) string {
 	leftPanel.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Render("═══ STRATEGY CONTROL ═══") + "\n\n")
 
 	// Strategy Status
+	cur := m.current()
+	statusText, statusColor := "INACTIVE", "196" // Red
+	if cur != nil {
+		statusText, statusColor = strategyStatusText(cur.Instance)
+	}
 
-	statusColor := "196" // Red
-	statusText := "INACTIVE"
-
-	if m.currentStrategy != nil {
-		switch m.currentStrategy.Runtime.Status() {
-		case StrategyDisabled:
-			statusColor = "196" // Red
-			statusText = "INACTIVE"
-
-		case StrategyStarting:
-			statusColor = "214" // Orange
-			statusText = "STARTING..."
-
-		case StrategyRunning:
-			statusColor = "46" // Green
-			statusText = "RUNNING"
-
-		case StrategyError:
-			statusColor = "196" // Red
-			statusText = "ERROR"
-
-		case StrategyStopping:
-			statusColor = "214" // Orange
-			statusText = "STOPPING..."
-
-		case StrategyStopped:
-			statusColor = "196" // Red
-			statusText = "INACTIVE"
-
+	leftPanel.WriteString("Status: " + lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor)).Bold(true).Render(statusText) + "\n")
+	if cur != nil {
+		if failure := cur.Instance.Runtime.Failure(); failure != "" {
+			leftPanel.WriteString(errorStyle.Render("Error: "+failure) + "\n")
+			leftPanel.WriteString(disabledStyle.Render("  Stack trace in the strategy log; :reset to clear") + "\n")
+		}
+		if dropped := cur.Instance.Runtime.DroppedQuotes(); dropped > 0 {
+			leftPanel.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(fmt.Sprintf("Dropped quotes: %d", dropped)) + "\n")
+		}
+		if m.pt != nil {
+			// Trades opened by this instance's orders, whoever closed them
+			stats := m.pt.GetOriginStats(cur.Instance.ID)
+			pnlStyle := successStyle
+			if stats.RealizedPnL < 0 {
+				pnlStyle = errorStyle
+			}
+			leftPanel.WriteString(fmt.Sprintf("Trades: %d  Win: %.0f%%  PnL: %s\n",
+				stats.Trades, stats.WinRate, pnlStyle.Render(fmt.Sprintf("$%.2f", stats.RealizedPnL))))
 		}
 	}
+	leftPanel.WriteString("\n")
 
-	leftPanel.WriteString("Status: " + lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor)).Bold(true).Render(statusText) + "\n\n")
-
-	// Available Strategies
+	// Available Strategies, with their descriptions in :strategies
 	leftPanel.WriteString(lipgloss.NewStyle().Bold(true).Render("Available Strategies:") + "\n")
-	for _, s := range m.availableStrategies {
+	for _, s := range m.strategyCatalog {
+		leftPanel.WriteString(fmt.Sprintf("  %s %s\n", s.Key, disabledStyle.Render(fmt.Sprintf("(%s, %d params)", s.Name, len(s.Params)))))
+	}
+	leftPanel.WriteString(disabledStyle.Render("  :strategies for details") + "\n\n")
+
+	// Loaded instances; the selected one is shown in detail
+	leftPanel.WriteString(lipgloss.NewStyle().Bold(true).Render("Instances:") + "\n")
+	instances := m.engine.Strategies()
+	if len(instances) == 0 {
+		leftPanel.WriteString("  none - :strategy add <name>\n")
+	}
+	for _, inst := range instances {
 		prefix := "  "
 		style := lipgloss.NewStyle()
-		if s == m.selectedStrategy {
+		if inst.ID == m.selectedInstance {
 			prefix = "> "
 			style = menuItemStyle
 		}
-		leftPanel.WriteString(style.Render(prefix+s) + "\n")
+		text, color := strategyStatusText(inst)
+		symbol := inst.Symbol()
+		if symbol == "" {
+			symbol = inst.Params()["symbol"] // Not started yet
+		}
+		leftPanel.WriteString(style.Render(fmt.Sprintf("%s%s %s %s", prefix, inst.ID, inst.Name, symbol)) + " " +
+			lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(text) + "\n")
 	}
 	leftPanel.WriteString("\n")
 
 	// Strategy Configuration
-	if m.currentStrategy != nil {
+	if cur != nil {
+		values := cur.Instance.Params()
 		leftPanel.WriteString(lipgloss.NewStyle().Bold(true).Render("Configuration:") + "\n")
-		for _, p := range m.currentStrategy.Params {
-			val := m.strategyParams[p.Name]
+		for _, p := range cur.Params {
+			val := values[p.Name]
 			if val == "" {
 				val = fmt.Sprintf("%v", p.Value)
 			}
-			leftPanel.WriteString(fmt.Sprintf("  %-12s: %s\n", p.Name, val))
+			line := fmt.Sprintf("  %-12s: %s", p.Name, val)
+			if r := p.Range(); r != "" {
+				line += " " + disabledStyle.Render("("+r+")")
+			}
+			leftPanel.WriteString(line + "\n")
 		}
 		leftPanel.WriteString("\n")
-
-		leftPanel.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Commands:") + "\n")
-		leftPanel.WriteString("  :strategy <name>\n")
-		leftPanel.WriteString("  :set <param> <val>\n")
-		leftPanel.WriteString("  :start | :stop\n")
 	}
 
+	leftPanel.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Commands:") + "\n")
+	leftPanel.WriteString("  :strategy add <name>\n")
+	leftPanel.WriteString("  :strategy <id>\n")
+	leftPanel.WriteString("  :set <param> <val>\n")
+	leftPanel.WriteString("  :start | :stop [id]\n")
+
 	leftContent := lipgloss.NewStyle().
 		Width(leftWidth).
 		Height(contentHeight).
@@ -1892,8 +1760,8 @@ func (m model) renderStrategyTab(contentHeight int) string {
 	var midPanel strings.Builder
 	midPanel.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")).Render("═══ PARAM VIEW ═══") + "\n\n")
 
-	if m.currentStrategy != nil && m.currentStrategy.Runtime.Status() == StrategyRunning {
-		metrics := m.currentStrategy.Instance.GetMetrics()
+	if cur != nil && cur.Instance.Runtime.Status() == app.StrategyRunning {
+		metrics := cur.Instance.Strategy().GetMetrics()
 		if len(metrics) > 0 {
 			// Sort keys for consistent display order
 			keys := make([]string, 0, len(metrics))
@@ -1903,9 +1771,15 @@ func (m model) renderStrategyTab(contentHeight int) string {
 			sort.Strings(keys)
 
 			for _, name := range keys {
-				val := metrics[name]
+				val := fmt.Sprintf("%.2f", metrics[name])
 				midPanel.WriteString(fmt.Sprintf("%-12s: ", name))
-				midPanel.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(fmt.Sprintf("%.2f", val)) + "\n")
+				midPanel.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Render(val))
+				// Border and padding take 4 columns, the label 14, plus a space
+				if width := midWidth - 4 - 14 - len(val) - 1; width > 0 {
+					line := sparkline(cur.metricHistory[name], width)
+					midPanel.WriteString(" " + lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(line))
+				}
+				midPanel.WriteString("\n")
 			}
 		} else {
 			midPanel.WriteString("Waiting for data...")
@@ -1922,8 +1796,12 @@ func (m model) renderStrategyTab(contentHeight int) string {
 		Padding(1).
 		Render(midPanel.String())
 
-	// Right panel - Strategy Logger
-	rightContent := m.renderLogPanel(rightWidth, contentHeight, "Strategy Log", m.strategyLogger, &m.stratLogScrollOffset)
+	// Right panel - the selected instance's log
+	logTitle := "Strategy Log"
+	if cur != nil {
+		logTitle = "Strategy Log [" + cur.Instance.ID + "]"
+	}
+	rightContent := m.renderLogPanel(rightWidth, contentHeight, logTitle, m.strategyLogView(), &m.stratLogScrollOffset, m.logWraps(TabStrategy))
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftContent, midContent, rightContent)
 }
@@ -1932,19 +1810,38 @@ func (m model) renderPositions() string {
 		return "No positions"
 	}
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("%-10s %8s %12s %12s\n", "Symbol", "Qty", "Avg Price", "P&L"))
-	sb.WriteString(strings.Repeat("─", 50) + "\n")
+	sb.WriteString(fmt.Sprintf("  %-10s %8s %12s %16s %12s %12s\n", "Symbol", "Qty", "Avg Price", "Mark", "P&L", "Net P&L"))
+	sb.WriteString(strings.Repeat("─", 82) + "\n")
 
-	for _, pos := range m.positions {
-		pnlStyle := successStyle
+	for i, pos := range m.positions {
+		pnlStyle, netStyle := successStyle, successStyle
 		if pos.PnL < 0 {
 			pnlStyle = errorStyle
 		}
-		sb.WriteString(fmt.Sprintf("%-10s %8d %12.2f %s\n",
-			pos.Symbol,
+		if pos.NetPnL < 0 {
+			netStyle = errorStyle
+		}
+		cursor := "  "
+		if i == m.selectedPosition {
+			cursor = "> "
+		}
+		symbol := fmt.Sprintf("%-10s", pos.Symbol)
+		if style, ok := alertStyle(m.alertLevel(pos.Symbol)); ok {
+			symbol = style.Render(symbol)
+		}
+		// The mark type explains a P&L that differs from the broker's
+		mark := "-"
+		if pos.Mark != 0 {
+			mark = fmt.Sprintf("%.2f %-5s", pos.Mark, pos.MarkType)
+		}
+		sb.WriteString(fmt.Sprintf("%s%s %8d %12.2f %16s %12s %12s\n",
+			cursor,
+			symbol,
 			pos.Quantity,
 			pos.AvgPrice,
+			mark,
 			pnlStyle.Render(fmt.Sprintf("$%.2f", pos.PnL)),
+			netStyle.Render(fmt.Sprintf("$%.2f", pos.NetPnL)),
 		))
 	}
 
@@ -2073,11 +1970,32 @@ func (m model) renderCommandsScrollable(viewHeight int) string {
 
 	return visibleContent + scrollIndicator
 }
+
+// feedsHealthy reports whether both WebSockets are still receiving frames
+func (m model) feedsHealthy() bool {
+	if m.marketDataClient != nil && !m.marketDataClient.IsConnected() {
+		return false
+	}
+	if m.tradingClient != nil && !m.tradingClient.IsConnected() {
+		return false
+	}
+	return true
+}
+
 func (m model) renderStatusBar() string {
+	// While typing a command the status bar lists its completions
+	if m.mode == modeCommand {
+		if list := m.renderCompletionList(); list != "" {
+			return statusBarStyle.Width(m.width).Render(list)
+		}
+	}
+
 	connStatus := "●"
 	connColor := "46" // Green
 	if !m.connected {
 		connColor = "196" // Red
+	} else if !m.feedsHealthy() || m.engine.InMaintenance() {
+		connColor = "214" // Orange
 	}
 
 	modeIndicator := ""
@@ -2087,8 +2005,56 @@ func (m model) renderStatusBar() string {
 		modeIndicator = lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Render(" [VISUAL]")
 	}
 
+	if m.demoData {
+		modeIndicator += lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(" [DEMO DATA]")
+	}
+
+	if m.connected && m.engine.InMaintenance() {
+		modeIndicator += lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(" [MAINTENANCE]")
+	}
+
+	if m.engine.MetricsRecording() {
+		modeIndicator += lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(" [REC METRICS]")
+	}
+
+	if window, held := m.engine.NewsLockout(); m.connected && held {
+		modeIndicator += errorStyle.Render(" [NEWS LOCKOUT until " + window.End.Format("15:04") + "]")
+	}
+
+	if m.om != nil && m.om.GetSchedule().Enabled() {
+		if m.inSession {
+			modeIndicator += successStyle.Render(" [IN SESSION]")
+		} else {
+			modeIndicator += errorStyle.Render(" [OUT OF SESSION]")
+		}
+	}
+
 	left := fmt.Sprintf("%s Connected%s", lipgloss.NewStyle().Foreground(lipgloss.Color(connColor)).Render(connStatus), modeIndicator)
 
+	// The kill switch and the live account stay in view even while a status message is shown
+	kill := ""
+	if m.engine != nil && m.engine.IsLiveAccount() {
+		if m.engine.LiveConfirmPending() {
+			kill = liveAccountStyle.Render(" LIVE ACCOUNT - :confirm-live to trade ") + " "
+		} else {
+			kill = liveAccountStyle.Render(" LIVE ACCOUNT ") + " "
+		}
+	}
+	if m.engine != nil && m.engine.KillSwitchEngaged() {
+		kill += killSwitchStyle.Render(" KILL SWITCH ENGAGED - :arm to resume ") + " "
+	}
+	if m.connected && m.riskState.Tripped {
+		kill += killSwitchStyle.Render(fmt.Sprintf(" DAILY LOSS LIMIT $%.2f / -$%.2f ", m.riskState.DailyPnL, m.riskState.Limit)) + " "
+	}
+	if m.om != nil {
+		kill += m.renderLossStreak()
+	}
+	if m.connected {
+		kill += m.renderTokenExpiry()
+		kill += m.renderPositionAlerts()
+	}
+	left = kill + left
+
 	// Calculate spacing safely to avoid negative repeat counts
 	spacing := m.width - lipgloss.Width(left)
 	if spacing < 1 {
@@ -2097,26 +2063,71 @@ func (m model) renderStatusBar() string {
 	statusText := left + strings.Repeat(" ", spacing)
 
 	if m.statusMsg != "" {
-		statusText = m.statusMsg
+		statusText = kill + m.statusMsg
 	}
 
 	return statusBarStyle.Width(m.width).Render(statusText)
 }
+
+// renderTokenExpiry counts down the last minutes of the access token, and
+// calls for :reauth once it could not be renewed
+func (m model) renderTokenExpiry() string {
+	left, ok := m.engine.TokenExpiry()
+	failed := m.engine.TokenRenewalError() != nil
+	if !ok || (!failed && left >= app.TokenWarning) {
+		return ""
+	}
+	countdown := "EXPIRED"
+	if left > 0 {
+		secs := int(left.Seconds())
+		countdown = fmt.Sprintf("EXPIRES IN %d:%02d", secs/60, secs%60)
+	}
+	if failed {
+		return killSwitchStyle.Render(" TOKEN RENEWAL FAILED - "+countdown+" - :reauth ") + " "
+	}
+	return liveAccountStyle.Render(" TOKEN "+countdown+" ") + " "
+}
+
+// renderLossStreak shows the loss streak breaker while it is tripped, and the
+// current run of losing trades once there is one
+func (m model) renderLossStreak() string {
+	rm := m.om.GetRiskManager()
+	if tripped, until := rm.LossBreaker(); tripped {
+		resume := ":arm to resume"
+		if !until.IsZero() {
+			resume = fmt.Sprintf("resumes %s or :arm", until.Local().Format("15:04:05"))
+		}
+		return killSwitchStyle.Render(fmt.Sprintf(" LOSS STREAK %d - %s ", rm.GetLossStreak(), resume)) + " "
+	}
+	if losses := rm.GetLossStreak(); losses > 0 {
+		text := fmt.Sprintf("Losses: %d", losses)
+		if limit := rm.GetConfig().Risk.MaxConsecutiveLosses; limit > 0 {
+			text += fmt.Sprintf("/%d", limit)
+		}
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(text) + " "
+	}
+	return ""
+}
+
 func (m model) renderCommandBar() string {
 	var content string
 	switch m.mode {
 	case modeCommand:
-		content = m.commandInput
+		content = m.renderCommandInput()
 	case modeEditor:
 		content = "EDITOR: Type content, 'Ctrl+S' to save, 'ESC' to exit"
 	default:
 		switch {
+		case m.logSelect != nil:
+			content = "w/s to extend the selection, y=copy to clipboard, v or Esc=cancel"
 		case m.activeTab == TabCommands && m.searchActive:
 			content = "Searching... (Press ESC to exit search)"
 		case m.activeTab == TabCommands:
 			content = "w/s or ↑/↓ to scroll, W=top, S=bottom, f=search, :=command, q=quit"
-		case m.activeTab == TabMain || m.activeTab == TabOrderManagement:
-			content = "w/s to scroll logs, :=command, a/d or 1-5 to switch tabs, q=quit"
+		case m.activeTab == TabOrderManagement:
+			content = "j/k to select an order, c=cancel, w/s to scroll log, v=select log lines, :=command, a/d or 1-5 to switch tabs, q=quit"
+		case m.activeTab == TabMain:
+			content = "w/s to scroll logs, v=select log lines, :=command, a/d or 1-5 to switch tabs, q=quit"
 		default:
 			content = "Press ':' for commands, 'q' to quit, 'a/d' or '1-5' to switch tabs"
 		}
@@ -2125,188 +2136,472 @@ func (m model) renderCommandBar() string {
 	return commandBarStyle.Width(m.width).Render(content)
 }
 
-func (m model) stopCurrentStrategy() tea.Cmd {
+// addStrategy creates an instance of a registered strategy and shows it
+func (m model) addStrategy(name string) model {
+	inst, err := m.engine.AddStrategy(name, nil)
+	if err != nil {
+		m.statusMsg = errorStyle.Render("Failed to load strategy: " + err.Error())
+		return m
+	}
+	m = m.showStrategy(inst)
+	m.statusMsg = successStyle.Render(fmt.Sprintf("Loaded strategy: %s as %s", inst.Strategy().Name(), inst.ID))
+	return m
+}
+
+// restoreStrategy adds an instance with the strategy and parameters last saved
+// by :set or :start. Parameters the strategy no longer accepts are dropped.
+func (m model) restoreStrategy() model {
+	inst, dropped, err := m.engine.RestoreStrategy(m.strategyStatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		m.statusMsg = errorStyle.Render("No saved strategy to restore")
+		return m
+	}
+	if err != nil {
+		m.statusMsg = errorStyle.Render("Failed to restore strategy: " + err.Error())
+		return m
+	}
+	m = m.showStrategy(inst)
+	msg := fmt.Sprintf("Restored strategy: %s as %s", inst.Strategy().Name(), inst.ID)
+	if len(dropped) > 0 {
+		msg += fmt.Sprintf(" (dropped %s, see strategy log)", strings.Join(dropped, ", "))
+	}
+	m.statusMsg = successStyle.Render(msg)
+	return m
+}
 
-	if m.currentStrategy == nil {
-		m.statusMsg = errorStyle.Render("No strategy selected")
-		return nil
+// saveStrategy records an instance's configuration for :strategy restore
+func (m model) saveStrategy(s *StrategyState) {
+	if err := m.engine.SaveStrategy(s.Instance.ID, m.strategyStatePath); err != nil {
+		m.mainLogger.Warnf("Failed to save strategy state: %v", err)
 	}
+}
 
-	if m.currentStrategy.Runtime.Status() != StrategyRunning {
-		m.statusMsg = errorStyle.Render("Strategy is not running")
-		return nil
+// showStrategy tracks a newly added instance and selects it on the Strategy tab
+func (m model) showStrategy(inst *app.StrategyInstance) model {
+	strat := inst.Strategy()
+	m.strategies[inst.ID] = &StrategyState{
+		Instance:    inst,
+		Params:      strat.GetParams(),
+		Description: strat.Description(),
 	}
-	m.currentStrategy.Runtime.SetStatus(StrategyStopping)
 
-	// Reset strategy instance state so it can be re-initialized
-	m.currentStrategy.Instance.Reset()
-	m.currentStrategy.Runtime.SetStatus(StrategyStopped)
+	m.activeTab = TabStrategy
+	m.scrollOffset = 0
+	m.selectedInstance = inst.ID
+	m.stratLogScrollOffset = 1000000
+	return m
+}
 
-	m.statusMsg = successStyle.Render("Strategy STOPPED")
-	m.strategyLogger.Info(">>> STRATEGY STOPPED <<<")
-	return nil
+// stopStrategy stops one instance and clears its metric history
+func (m model) stopStrategy(s *StrategyState) model {
+	if err := m.engine.StopStrategy(s.Instance.ID); err != nil {
+		m.statusMsg = errorStyle.Render(fmt.Sprintf("Strategy %s is not running", s.Instance.ID))
+		return m
+	}
+	s.resetMetricHistory()
+
+	m.statusMsg = successStyle.Render(fmt.Sprintf("Strategy %s STOPPED", s.Instance.ID))
+	return m
 }
 
-func (m model) connectCmd() tea.Cmd {
-	return func() tea.Msg {
-		var cfg *config.Config
-		var err error
+// current returns the instance shown on the Strategy tab, or nil
+func (m model) current() *StrategyState {
+	return m.strategies[m.selectedInstance]
+}
 
-		// Always reload config from disk to ensure latest values are used
-		cfg, err = config.LoadOrCreateConfig(m.mainLogger)
-		if err != nil {
-			return connMsg{err: fmt.Errorf("config load error: %v", err)}
-		}
+// targetStrategy returns the instance named by a command's ID argument,
+// defaulting to the one shown
+func (m model) targetStrategy(args []string) *StrategyState {
+	if len(args) > 0 {
+		return m.strategies[args[0]]
+	}
+	return m.current()
+}
 
-		tm := auth.NewTokenManager(cfg)
-		tm.SetLogger(m.mainLogger)
+// strategyLabel names an instance for the status bar, e.g. "MA Crossover [1]"
+func (m model) strategyLabel(s *StrategyState) string {
+	if s == nil {
+		return "No strategy selected"
+	}
+	return fmt.Sprintf("%s [%s]", s.Instance.Strategy().Name(), s.Instance.ID)
+}
 
-		m.mainLogger.Info("Attempting Authentication...")
-		// Authenticate
-		if err := tm.Authenticate(); err != nil {
-			// ADD MORE CONTEXT HERE
-			m.mainLogger.Errorf("Authentication failed: %v", err)
-			return connMsg{err: fmt.Errorf("auth error: %w", err)}
-		}
+// strategyLogView returns the log the Strategy tab shows: the selected
+// instance's, or every instance's when none is selected
+func (m model) strategyLogView() *logger.Logger {
+	if s := m.current(); s != nil {
+		return s.Instance.Log
+	}
+	return m.strategyLogger
+}
+
+// strategyStatusText is the label and color for an instance's status. A
+// running instance shows its warm-up progress until it is ready to trade.
+func strategyStatusText(inst *app.StrategyInstance) (text, color string) {
+	switch inst.Runtime.Status() {
+	case app.StrategyStarting:
+		return "STARTING...", "214" // Orange
+	case app.StrategyRunning:
+		if !inst.Runtime.IsReady() {
+			fed, need := inst.WarmupProgress()
+			return fmt.Sprintf("WARMING UP: %d/%d bars", min(fed, need), need), "214" // Orange
+		}
+		if inst.Runtime.IsDrifted() {
+			return "PAUSED: POSITION DRIFT", "196" // Red
+		}
+		if inst.Runtime.IsStale() {
+			return "PAUSED: NO DATA", "214" // Orange
+		}
+		return "RUNNING", "46" // Green
+	case app.StrategyStopping:
+		return "STOPPING...", "214" // Orange
+	case app.StrategyError:
+		return "ERROR", "196" // Red
+	default:
+		return "INACTIVE", "196" // Red
+	}
+}
 
-		sessionStart = time.Now().UTC()
+// checkSchedule runs the engine's schedule check and shows the cutoff. Positions
+// are only flattened at the cutoff in Live mode.
+func (m model) checkSchedule(now time.Time) model {
+	inSession, cutoff := m.engine.CheckSchedule(now, m.tradingMode == ModeLive)
+	m.inSession = inSession
+	if cutoff {
+		m.statusMsg = errorStyle.Render("SESSION CUTOFF - STRATEGY STOPPED AND POSITIONS FLATTENED")
+	}
+	return m
+}
 
-		m.mainLogger.Infof("Session Start Time: %s", sessionStart)
+// recordMetrics appends one sample per metric, dropping the oldest beyond maxMetricSamples
+func (s *StrategyState) recordMetrics(metrics map[string]float64) {
+	if s.metricHistory == nil {
+		s.metricHistory = make(map[string][]float64)
+	}
+	for name, val := range metrics {
+		history := append(s.metricHistory[name], val)
+		if len(history) > maxMetricSamples {
+			history = append(history[:0], history[len(history)-maxMetricSamples:]...)
+		}
+		s.metricHistory[name] = history
+	}
+}
 
-		m.mainLogger.Info("Authentication Successful")
+// resetMetricHistory clears the samples when the strategy is stopped or restarted
+func (s *StrategyState) resetMetricHistory() {
+	s.metricHistory = nil
+}
 
-		om := execution.NewOrderManager(tm, cfg, m.orderLogger)
+// sparkBlocks are the sparkline levels from lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
 
-		var marketDataClient *tradovate.TradovateWebSocketClient
-		var tradingClient *tradovate.TradovateWebSocketClient
-		var marketDataSubscriptionManager *tradovate.DataSubscriber
-		var tradingClientSubscriptionManager *tradovate.DataSubscriber
+// sparkline renders the last width values scaled between their min and max
+func sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	if len(values) == 0 {
+		return ""
+	}
 
-		//Get fresh tokens
-		accessToken, err := tm.GetAccessToken()
-		if err != nil {
-			return connMsg{err: fmt.Errorf("failed to get access token: %w", err)}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
 		}
-		m.mainLogger.Debug("Auth token aquired")
+		if v > hi {
+			hi = v
+		}
+	}
 
-		tm.SetLogger(m.mainLogger)
+	out := make([]rune, len(values))
+	for i, v := range values {
+		level := len(sparkBlocks) / 2
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		out[i] = sparkBlocks[level]
+	}
+	return string(out)
+}
 
-		mdToken, err := tm.GetMDAccessToken()
-		if err != nil {
-			return connMsg{err: fmt.Errorf("failed to get MD token: %w", err)}
+// parseOrderOptions reads the optional order type and time in force that follow
+// :buy/:sell <symbol> <quantity>:
+//
+//	limit <price> | @ <price> | stop <price> | stoplimit <stop> <limit>, then day | gtc | ioc
+func parseOrderOptions(args []string) (execution.OrderOptions, error) {
+	var opts execution.OrderOptions
+	// @6005.25 is @ 6005.25
+	if len(args) > 0 && len(args[0]) > 1 && strings.HasPrefix(args[0], "@") {
+		args = append([]string{"@", args[0][1:]}, args[1:]...)
+	}
+	price := func(i int) (float64, error) {
+		if i >= len(args) {
+			return 0, fmt.Errorf("%s needs a price", args[0])
+		}
+		p, err := strconv.ParseFloat(args[i], 64)
+		if err != nil || p <= 0 {
+			return 0, fmt.Errorf("invalid price %q", args[i])
+		}
+		return p, nil
+	}
+
+	var err error
+	rest := args
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "limit", "@":
+			opts.Type = models.TypeLimit
+			opts.Price, err = price(1)
+			rest = args[min(2, len(args)):]
+		case "stop":
+			opts.Type = models.TypeStop
+			opts.StopPrice, err = price(1)
+			rest = args[min(2, len(args)):]
+		case "stoplimit":
+			opts.Type = models.TypeStopLimit
+			if opts.StopPrice, err = price(1); err == nil {
+				opts.Price, err = price(2)
+			}
+			rest = args[min(3, len(args)):]
+		}
+	}
+	if err != nil {
+		return opts, err
+	}
+
+	switch len(rest) {
+	case 0:
+	case 1:
+		opts.TimeInForce, err = models.ParseTimeInForce(rest[0])
+	default:
+		err = fmt.Errorf("unexpected %q", strings.Join(rest[1:], " "))
+	}
+	return opts, err
+}
+
+// checkOrderTicks refuses limit and stop prices between the ticks of symbol,
+// naming the nearest one, when its tick size is known
+func checkOrderTicks(symbol string, opts execution.OrderOptions, tick float64) error {
+	for _, price := range []float64{opts.Price, opts.StopPrice} {
+		if price != 0 && !contracts.OnTick(price, tick) {
+			return fmt.Errorf("price %g is not on the %g tick of %s (nearest %g)", price, tick, symbol, contracts.RoundPrice(price, tick))
 		}
-		m.mainLogger.Debug("Market data token aquired")
+	}
+	return nil
+}
+
+// orderPriceText describes an order's prices for the order log
+func orderPriceText(o *models.Order) string {
+	var text string
+	switch o.Type {
+	case models.TypeLimit:
+		text = fmt.Sprintf("Limit %.2f", o.Price)
+	case models.TypeStop:
+		text = fmt.Sprintf("Stop %.2f", o.StopPrice)
+	case models.TypeStopLimit:
+		text = fmt.Sprintf("Stop %.2f Limit %.2f", o.StopPrice, o.Price)
+	default:
+		text = "Market"
+	}
+	if o.TimeInForce != "" {
+		text += " " + string(o.TimeInForce)
+	}
+	return text
+}
 
-		// Create new WebSocket clients
-		marketDataClient = tradovate.NewTradovateWebSocketClient(mdToken, cfg.Tradovate.Environment, "md")
-		marketDataClient.SetLogger(m.mainLogger)
+// closePosition submits an opposing market order for a symbol's full NetPos. The result
+// is confirmed on a later tick once the fill has been reflected in the position.
+func (m model) closePosition(symbol string) model {
+	if !m.connected || m.om == nil || m.pt == nil {
+		m.statusMsg = errorStyle.Render("Must be connected to API to close positions")
+		return m
+	}
+	if m.tradingMode != ModeLive {
+		m.statusMsg = errorStyle.Render("Cannot close positions in Visual mode")
+		m.mainLogger.Errorf("Close rejected: Not in Live mode")
+		return m
+	}
 
-		tradingClient = tradovate.NewTradovateWebSocketClient(accessToken, cfg.Tradovate.Environment, "")
-		tradingClient.SetLogger(m.mainLogger)
+	pos, ok := m.pt.GetPLSummary()[symbol]
+	if !ok || pos.NetPos == 0 {
+		m.statusMsg = errorStyle.Render("No open position for " + symbol)
+		return m
+	}
 
-		// Create subscription managers
-		marketDataSubscriptionManager = tradovate.NewDataSubscriptionManager(marketDataClient)
-		marketDataSubscriptionManager.SetLogger(m.strategyLogger)
+	side := models.SideSell
+	if pos.NetPos < 0 {
+		side = models.SideBuy
+	}
+	qty := models.Abs(pos.NetPos)
 
-		tradingClientSubscriptionManager = tradovate.NewDataSubscriptionManager(tradingClient)
-		tradingClientSubscriptionManager.SetLogger(m.mainLogger)
+	order, err := m.om.Flatten(symbol, side, qty)
+	if err != nil {
+		m.statusMsg = errorStyle.Render("Close failed: " + err.Error())
+		m.orderLogger.Errorf("CLOSE %s failed: %v", symbol, err)
+		return m
+	}
 
-		// Connect the clients
-		if err := marketDataSubscriptionManager.Connect(); err != nil {
-			return connMsg{err: fmt.Errorf("Error connecting market data client: %w", err)}
-		}
-		m.mainLogger.Info("Market Data WebSocket connected")
+	m.pendingCloses[order.ID] = pendingClose{symbol: symbol}
+	m.statusMsg = successStyle.Render(fmt.Sprintf("Closing %s: %s %d (ID: %s)", symbol, side, qty, order.ID))
+	m.orderLogger.Printf("CLOSE %s - %s %d, ID: %s", symbol, side, qty, order.ID)
+	return m
+}
 
-		if err := tradingClientSubscriptionManager.Connect(); err != nil {
-			return connMsg{err: fmt.Errorf("Error connecting trading client: %w", err)}
+// checkPendingCloses reports the outcome of :close orders. A filled order does not
+// guarantee a flat position (partial fills), so NetPos is re-checked once the
+// position update has arrived or closeSettleTime has passed.
+func (m model) checkPendingCloses() model {
+	for orderID, pc := range m.pendingCloses {
+		order, ok := m.om.GetOrder(orderID)
+		if !ok {
+			delete(m.pendingCloses, orderID)
+			continue
+		}
+		m.om.Mu.RLock()
+		status, reason := order.Status, order.RejectReason
+		m.om.Mu.RUnlock()
+
+		switch status {
+		case models.StatusFilled:
+			pos := m.pt.GetPLSummary()[pc.symbol]
+			if pos.NetPos != 0 {
+				if pc.filledAt.IsZero() {
+					pc.filledAt = time.Now()
+					m.pendingCloses[orderID] = pc
+				}
+				if time.Since(pc.filledAt) < closeSettleTime {
+					continue
+				}
+			}
+			delete(m.pendingCloses, orderID)
+			if pos.NetPos != 0 {
+				m.statusMsg = errorStyle.Render(fmt.Sprintf("%s still open (%d) after close, use :close again", pc.symbol, pos.NetPos))
+				m.orderLogger.Warnf("CLOSE %s - partially closed, NetPos now %d", pc.symbol, pos.NetPos)
+			} else {
+				m.statusMsg = successStyle.Render(pc.symbol + " position closed")
+				m.orderLogger.Infof("CLOSE %s - position closed", pc.symbol)
+			}
+		case models.StatusRejected, models.StatusFailed, models.StatusCanceled, models.StatusExpired:
+			delete(m.pendingCloses, orderID)
+			m.statusMsg = errorStyle.Render(fmt.Sprintf("Close %s %s: %s", pc.symbol, status, reason))
+			m.orderLogger.Errorf("CLOSE %s - order %s %s", pc.symbol, orderID, status)
 		}
-		m.mainLogger.Info("Trading WebSocket connected")
+	}
+	return m
+}
 
-		// Set message handlers
-		marketDataClient.SetMessageHandler(marketDataSubscriptionManager.HandleEvent)
-		tradingClient.SetMessageHandler(tradingClientSubscriptionManager.HandleEvent)
+// closeConnections stops the strategy and disconnects the engine
+func (m model) closeConnections() {
+	m.engine.Disconnect()
+}
 
-		m.mainLogger.Debug("Message Handlers Set")
+// beginShutdown stops every strategy and starts the shutdown sequence. The program quits
+// once the sequence finishes or the configured timeout passes.
+func (m model) beginShutdown() (model, tea.Cmd) {
+	m.shuttingDown = true
+	m.statusMsg = "Shutting down…"
+	m.mainLogger.Info(">>> SHUTTING DOWN... <<<")
 
-		//Set up order status handlers
-		setupOrderHandlers := func() {
-			tradingClientSubscriptionManager.OnOrderUpdate = func(data json.RawMessage) {
-				var order struct {
-					ID        int    `json:"id"`
-					OrderType string `json:"orderType"`
-					Action    string `json:"action"`
-					OrdStatus string `json:"ordStatus"` // Note: Tradovate uses "ordStatus" not "orderStatus"
-					Timestamp string `json:"timestamp"`
-				}
+	m.engine.StopAllStrategies()
 
-				if err := json.Unmarshal(data, &order); err != nil {
-					m.orderLogger.Warnf("Failed to parse order update: %v", err)
-					return
-				}
+	return m, func() tea.Msg {
+		return shutdownDoneMsg{timedOut: m.runShutdown() != nil}
+	}
+}
 
-				orderTime, err := time.Parse(time.RFC3339Nano, order.Timestamp)
-				if err != nil {
-					m.orderLogger.Errorf("invalid order timestamp %q: %v", order.Timestamp, err)
-				}
+// runShutdown cancels working orders and flattens if configured, then closes all
+// connections, returning an error if it timed out. flattenOnExit only applies in
+// Live mode. The engine is shut down even when disconnected so its metrics
+// listener closes.
+func (m model) runShutdown() error {
+	return m.engine.Shutdown(m.tradingMode == ModeLive)
+}
 
-				if orderTime.Before(sessionStart) {
-					return
-				}
+// flushLogs writes all three logs to external/logs
+func (m model) flushLogs() {
+	for prefix, l := range map[string]*logger.Logger{
+		"main_log_":   m.mainLogger,
+		"orders_log_": m.orderLogger,
+		"strat_log_":  m.strategyLogger,
+	} {
+		if _, err := exportLog(l, prefix, false); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export %s: %v\n", prefix, err)
+		}
+	}
+}
 
-				ts := orderTime.Format("03:04:05 PM")
-
-				switch order.OrdStatus {
-				case "PendingNew":
-					m.orderLogger.Infof("[%s UTC] ORDER PENDING  | ID=%d | %s %s",
-						ts, order.ID, order.Action, order.OrderType)
-
-				case "Filled":
-					m.orderLogger.Infof("[%s UTC] ORDER FILLED   | ID=%d | %s %s",
-						ts, order.ID, order.Action, order.OrderType)
-
-				case "Rejected":
-					m.orderLogger.Errorf("[%s UTC] ORDER REJECTED | ID=%d | %s %s",
-						ts, order.ID, order.Action, order.OrderType)
-
-				case "Working":
-					m.orderLogger.Infof("[%s UTC] ORDER WORKING  | ID=%d | %s %s",
-						ts, order.ID, order.Action, order.OrderType)
-				case "Canceled":
-					m.orderLogger.Infof("[%s UTC] ORDER CANCELED | ID=%d | %s %s",
-						ts, order.ID, order.Action, order.OrderType)
-				default:
-					m.orderLogger.Warnf("[%s UTC] UNKNOWN ORDER STATUS | ID=%d | %s %s",
-						ts, order.ID, order.Action, order.OrderType)
-				}
-			}
+// exportLog writes a logger's contents to a timestamped .txt (or .json) file in external/logs
+func exportLog(l *logger.Logger, prefix string, asJSON bool) (string, error) {
+	logsDir := filepath.Join(config.GetProjectRoot(), "external", "logs")
+	_ = os.MkdirAll(logsDir, 0755)
+	name := prefix + time.Now().Format("January 2, 2006 3:04:05 PM")
+
+	if asJSON {
+		data, err := l.ExportToJSON()
+		if err != nil {
+			return "", err
 		}
+		filename := filepath.Join(logsDir, name+".json")
+		return filename, os.WriteFile(filename, data, 0644)
+	}
+
+	filename := filepath.Join(logsDir, name+".txt")
+	return filename, os.WriteFile(filename, []byte(l.ExportToString()), 0644)
+}
 
-		// Set up handlers initially
-		setupOrderHandlers()
-		m.mainLogger.Debug("OnOrderUpdate Set")
+// writeSessionReport builds the session report and writes it to external/reports
+func (m model) writeSessionReport() (string, string, error) {
+	history := make([]portfolio.PnLSample, len(m.pnlHistory))
+	for i, p := range m.pnlHistory {
+		history[i] = portfolio.PnLSample{Time: p.Time, PnL: p.PnL}
+	}
+	snap := m.pt.Snapshot(m.engine.SessionStart())
+	report := portfolio.BuildSessionReport(snap, m.om.GetAllOrders(), history)
+	return report.WriteFiles(filepath.Join(config.GetProjectRoot(), "external", "reports"))
+}
 
-		userID := tm.GetUserID()
-		tracker := portfolio.NewPortfolioTracker(tradingClientSubscriptionManager, marketDataSubscriptionManager, userID, m.mainLogger)
+func (m model) connectCmd() tea.Cmd {
+	return func() tea.Msg {
+		var cfg *config.Config
+		var err error
 
-		if err := tracker.Start(cfg.Tradovate.Environment); err != nil {
-			return connMsg{err: fmt.Errorf("Failed to start PortfolioTracker: %w", err)}
+		// Always reload config from disk to ensure latest values are used
+		cfg, err = config.LoadOrCreateConfig(m.mainLogger)
+		if err != nil {
+			return connMsg{err: fmt.Errorf("config load error: %v", err)}
 		}
 
-		om.SetPortfolioTracker(tracker)
+		connect := m.engine.Connect
+		if m.demoData {
+			connect = m.engine.ConnectDemo
+		}
+		if err := connect(cfg); err != nil {
+			return connMsg{err: err}
+		}
 
-		tm.StartTokenRefreshMonitor(func() {
-			m.mainLogger.Debug("Reconnection complete after token refresh")
-		})
+		return connMsgSuccess{config: cfg}
+	}
+}
 
-		return connMsgSuccess{
-			config:            cfg,
-			tokenManager:      tm,
-			orderManager:      om,
-			mdClient:          marketDataClient,
-			mdSubscriber:      marketDataSubscriptionManager,
-			tradingClient:     tradingClient,
-			tradingSubscriber: tradingClientSubscriptionManager,
-			portfolioTracker:  tracker,
+// reconnectCmd reconnects the engine after a maintenance window, restarting
+// the strategies that were running
+func (m model) reconnectCmd() tea.Cmd {
+	return func() tea.Msg {
+		if err := m.engine.Reconnect(); err != nil {
+			return connMsg{err: err}
 		}
+		return connMsgSuccess{config: m.engine.Config()}
+	}
+}
+
+// setMetricsRecording turns strategy metrics CSVs on or off
+func (m model) setMetricsRecording(on bool) model {
+	m.engine.SetMetricsRecording(on)
+	if on {
+		m.statusMsg = successStyle.Render("Recording strategy metrics on every bar to " + execution.MetricsDir())
+	} else {
+		m.statusMsg = "Strategy metrics recording stopped, files flushed"
 	}
+	return m
 }
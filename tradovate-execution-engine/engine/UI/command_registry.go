@@ -0,0 +1,155 @@
+package UI
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandRegistry is every command the command bar runs, in the order the
+// Commands page lists them. It is filled in init since handlers look commands
+// up for their usage lines.
+var commandRegistry []commandSpec
+
+func init() {
+	commandRegistry = []commandSpec{
+		{Command: Command{Name: "buy", Description: "Place a buy order (market unless a type is given)", Usage: ":buy <symbol> <quantity> [limit <price> | @ <price> | stop <price> | stoplimit <stop> <limit>] [day|gtc|ioc]", Category: "Trading"},
+			args: argRange{2, 6}, intArgs: []int{2}, run: cmdBuy},
+		{Command: Command{Name: "sell", Description: "Place a sell order (market unless a type is given)", Usage: ":sell <symbol> <quantity> [limit <price> | @ <price> | stop <price> | stoplimit <stop> <limit>] [day|gtc|ioc]", Category: "Trading"},
+			args: argRange{2, 6}, intArgs: []int{2}, run: cmdSell},
+		{Command: Command{Name: "cancel", Description: "Cancel a working order by its ID, as shown in the Order Mgmt table", Usage: ":cancel <order id>", Category: "Trading"},
+			args: argRange{1, 1}, run: cmdCancel},
+		{Command: Command{Name: "oco", Description: "Rest a buy stop and a sell stop where a fill on either cancels the other", Usage: ":oco <symbol> <quantity> <buy stop> <sell stop> or :oco cancel <pair id>", Category: "Trading"},
+			args: argRange{2, 4}, run: cmdOCO},
+		{Command: Command{Name: "flatten", Description: "Preview, then cancel working orders and flatten all positions (:flatten! skips the preview)", Usage: ":flatten", Category: "Trading"},
+			run: cmdFlatten},
+		{Command: Command{Name: "kill", Description: "Kill switch: stop strategies, cancel orders, flatten, and refuse new orders (:kill! skips the preview)", Usage: ":kill", Category: "Trading"},
+			run: cmdKill},
+		{Command: Command{Name: "arm", Description: "Accept orders again after :kill or a loss streak breaker trip", Usage: ":arm", Category: "Trading"},
+			run: cmdArm},
+		{Command: Command{Name: "confirm-live", Description: "Acknowledge trading on the live account; required once before its first order", Usage: ":confirm-live", Category: "Trading"},
+			run: cmdConfirmLive},
+		{Command: Command{Name: "record", Description: "Record running strategies' metrics to external/metrics CSVs on every bar (r on the Strategy tab toggles)", Usage: ":record <on|off>", Category: "System"},
+			args: argRange{1, 1}, run: cmdRecord},
+		{Command: Command{Name: "override", Description: "Trade through the news lockout in force, after confirming", Usage: ":override", Category: "Trading"},
+			run: cmdOverride},
+		{Command: Command{Name: "close", Description: "Close one position (or Enter on the Positions tab)", Usage: ":close <symbol>", Category: "Trading"},
+			args: argRange{1, 1}, run: cmdClose},
+		{Command: Command{Name: "trail", Description: "Trail a protective stop behind an open position", Usage: ":trail <symbol> <ticks> [min step ticks] or :trail off <symbol>", Category: "Trading"},
+			args: argRange{2, 3}, run: cmdTrail},
+		{Command: Command{Name: "mode", Description: "Switch trading mode (live/visual)", Usage: ":mode <live|visual> or mode <l|v>", Category: "System"},
+			args: argRange{1, 1}, run: cmdMode},
+		{Command: Command{Name: "config", Description: "Edit configuration", Usage: ":config", Category: "System"},
+			run: cmdConfig},
+		{Command: Command{Name: "reload", Description: "Reload config and apply risk limits, fees and schedule without reconnecting", Usage: ":reload", Category: "System"},
+			run: cmdReload},
+		{Command: Command{Name: "strategy", Description: "Add a strategy instance, show one, remove one, or restore the last saved one", Usage: ":strategy add <name> | :strategy <id> | :strategy remove <id> | :strategy restore", Category: "System"},
+			args: argRange{1, 2}, run: cmdStrategy},
+		{Command: Command{Name: "clone", Description: "Add a stopped copy of the shown instance's configuration on another symbol (c on the Strategy tab)", Usage: ":clone <symbol>", Category: "System"},
+			args: argRange{1, 1}, run: cmdClone},
+		{Command: Command{Name: "set", Description: "Change a parameter of the shown instance while it is stopped", Usage: ":set <param> <value>", Category: "System"},
+			args: argRange{2, 2}, run: cmdSet},
+		{Command: Command{Name: "strategies", Description: "List registered strategies with their descriptions and default parameters", Usage: ":strategies", Category: "System"},
+			run: cmdStrategies},
+		{Command: Command{Name: "start", Description: "Start a strategy instance (default: the one shown)", Usage: ":start [id]", Category: "System"},
+			args: argRange{0, 1}, run: cmdStart},
+		{Command: Command{Name: "stop", Description: "Stop a strategy instance (default: the one shown)", Usage: ":stop [id]", Category: "System"},
+			args: argRange{0, 1}, run: cmdStop},
+		{Command: Command{Name: "reset", Description: "Clear the error of a failed strategy instance so it can be started again", Usage: ":reset [id]", Category: "System"},
+			args: argRange{0, 1}, run: cmdReset},
+		{Command: Command{Name: "resync", Description: "Have a strategy paused for a position drift take the broker's position and trade again", Usage: ":resync [id]", Category: "System"},
+			args: argRange{0, 1}, run: cmdResync},
+		{Command: Command{Name: "find", Description: "Search Tradovate contracts by name, with their descriptions and expirations", Usage: ":find <text>", Category: "System"},
+			args: argRange{1, 1}, run: cmdFind},
+		{Command: Command{Name: "contract", Description: "Show or pin the contract a product root resolves to", Usage: ":contract <root> [symbol|auto]", Category: "System"},
+			args: argRange{1, 2}, run: cmdContract},
+		{Command: Command{Name: "backtest", Description: "Backtest the selected strategy on recent minute bars", Usage: ":backtest <minutes>", Category: "System"},
+			args: argRange{1, 1}, intArgs: []int{1}, run: cmdBacktest},
+		{Command: Command{Name: "loglevel", Description: "Show or change the lowest level a log keeps until the next connect or :reload", Usage: ":loglevel <main|order|strategy> [debug|info|warn|error]", Category: "System"},
+			args: argRange{1, 2}, run: cmdLogLevel},
+		{Command: Command{Name: "reauth", Description: "Log in to Tradovate again and re-authorize both WebSockets, keeping subscriptions, when the token could not be renewed", Usage: ":reauth", Category: "System"},
+			run: cmdReauth},
+		{Command: Command{Name: "accounts", Description: "List accounts on this login and show the one in use", Usage: ":accounts", Category: "System"},
+			run: cmdAccounts},
+		{Command: Command{Name: "report", Description: "Write the session report (.txt and .json) to external/reports", Usage: ":report", Category: "System"},
+			run: cmdReport},
+		{Command: Command{Name: "export", Description: "Export logs", Usage: ":export <main|orders|strat> [text|json]", Category: "System"},
+			args: argRange{1, 2}, run: cmdExport},
+		{Command: Command{Name: "help", Description: "Show commands page", Usage: ":help", Category: "Navigation"},
+			run: cmdHelp},
+		{Command: Command{Name: "quit", Description: "Exit the application", Usage: ":quit or :q", Category: "System"},
+			aliases: []string{"q", "Q"}, run: cmdQuit},
+
+		// Shortcuts the Commands page mentions in other entries
+		{Command: Command{Name: "flatten!", Usage: ":flatten!"}, hidden: true, run: cmdFlattenNow},
+		{Command: Command{Name: "kill!", Usage: ":kill!"}, hidden: true, run: cmdKillNow},
+		{Command: Command{Name: "write", Usage: ":w"}, aliases: []string{"w"}, hidden: true, run: cmdWrite},
+		{Command: Command{Name: "x", Usage: ":x"}, hidden: true, run: cmdSaveExit},
+	}
+}
+
+// lookupCommand returns the registered command named or aliased name, nil if none
+func lookupCommand(name string) *commandSpec {
+	for i := range commandRegistry {
+		spec := &commandRegistry[i]
+		if spec.Name == name {
+			return spec
+		}
+		for _, alias := range spec.aliases {
+			if alias == name {
+				return spec
+			}
+		}
+	}
+	return nil
+}
+
+// registeredCommands returns the commands listed on the Commands page
+func registeredCommands() []Command {
+	var commands []Command
+	for _, spec := range commandRegistry {
+		if !spec.hidden {
+			commands = append(commands, spec.Command)
+		}
+	}
+	return commands
+}
+
+// commandUsage returns the usage line of a registered command
+func commandUsage(name string) string {
+	if spec := lookupCommand(name); spec != nil {
+		return spec.Usage
+	}
+	return ":" + name
+}
+
+// executeCommand runs the command bar input through its registered handler
+func (m model) executeCommand() (model, tea.Cmd) {
+	// Force scroll to bottom when a command is executed
+	m.logScrollOffset = 1000000
+	m.orderLogScrollOffset = 1000000
+	m.stratLogScrollOffset = 1000000
+
+	parts := strings.Fields(strings.TrimPrefix(m.commandInput, ":"))
+	if len(parts) == 0 {
+		return m, nil
+	}
+
+	spec := lookupCommand(parts[0])
+	if spec == nil {
+		m.statusMsg = errorStyle.Render(fmt.Sprintf("Unknown command: %s", parts[0]))
+		return m, nil
+	}
+	args := parts[1:]
+	if len(args) < spec.args.min {
+		m.statusMsg = errorStyle.Render("Usage: " + spec.Usage)
+		return m, nil
+	}
+
+	cmd, err := spec.run(&m, args)
+	if err != nil {
+		m.statusMsg = errorStyle.Render(err.Error())
+	}
+	return m, cmd
+}
@@ -0,0 +1,79 @@
+package UI
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// ansiCodes matches the terminal styling lipgloss adds to status messages
+var ansiCodes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// CommandSession runs command bar input against a model without a terminal,
+// so the command handlers can be driven from tests
+type CommandSession struct {
+	m model
+}
+
+// NewCommandSession returns a session in Visual mode around engine, logging to
+// log. A connected engine is attached, with its config, as it would be on connect. Strategy state
+// saved by :set and :start goes to a file under the temp directory.
+func NewCommandSession(engine *app.Engine, log *logger.Logger) *CommandSession {
+	m := newModel(engine, log, log, log)
+	m.strategyStatePath = filepath.Join(os.TempDir(), "ui_command_session_state.json")
+	if engine.OrderManager() != nil {
+		m.config = engine.Config()
+		m = m.attachEngine()
+	}
+	return &CommandSession{m: m}
+}
+
+// SetConfigPath points :config and :reload at the config file at path
+func (s *CommandSession) SetConfigPath(path string) {
+	s.m.configPath = path
+}
+
+// SetBroker sends :buy and :sell to b and treats the session as connected
+func (s *CommandSession) SetBroker(b execution.Broker) {
+	s.m.broker = b
+	s.m.connected = true
+}
+
+// SetLive switches between Live and Visual mode
+func (s *CommandSession) SetLive(live bool) {
+	s.m.tradingMode = ModeVisual
+	if live {
+		s.m.tradingMode = ModeLive
+	}
+}
+
+// Run executes one line of command bar input, with or without its leading
+// colon, and returns the status message it left without styling
+func (s *CommandSession) Run(input string) string {
+	s.m.commandInput = ":" + strings.TrimPrefix(input, ":")
+	s.m.statusMsg = ""
+	s.m, _ = s.m.executeCommand()
+	s.m.commandInput = ""
+	return ansiCodes.ReplaceAllString(s.m.statusMsg, "")
+}
+
+// CheckCloses reports the outcome of :close orders as a tick does and returns
+// the status message it left without styling
+func (s *CommandSession) CheckCloses() string {
+	s.m = s.m.checkPendingCloses()
+	return ansiCodes.ReplaceAllString(s.m.statusMsg, "")
+}
+
+// Confirming returns the action waiting for y/n, "" if none
+func (s *CommandSession) Confirming() string {
+	return s.m.confirmAction
+}
+
+// Selected returns the ID of the strategy instance shown on the Strategy tab
+func (s *CommandSession) Selected() string {
+	return s.m.selectedInstance
+}
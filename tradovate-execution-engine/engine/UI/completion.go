@@ -0,0 +1,339 @@
+package UI
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/execution"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// orderTypeArgs is how many words each :buy/:sell order type takes, itself included
+var orderTypeArgs = map[string]int{
+	"limit":     2,
+	"@":         2,
+	"stop":      2,
+	"stoplimit": 3,
+}
+
+// splitCommand splits command bar input into its completed words and the word
+// still being typed, which is empty after a trailing space
+func splitCommand(input string) (words []string, partial string) {
+	input = strings.TrimPrefix(input, ":")
+	words = strings.Fields(input)
+	if len(words) > 0 && !strings.HasSuffix(input, " ") {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	return words, partial
+}
+
+// completions returns the candidates for the word being typed, in display order
+func (m model) completions(input string) []string {
+	words, partial := splitCommand(input)
+
+	var choices []string
+	if len(words) == 0 {
+		for _, c := range m.commands {
+			choices = append(choices, c.Name)
+		}
+	} else {
+		choices = m.argumentChoices(words[0], words[1:])
+	}
+
+	var matches []string
+	seen := make(map[string]bool)
+	for _, c := range choices {
+		if seen[c] || !strings.HasPrefix(strings.ToLower(c), strings.ToLower(partial)) {
+			continue
+		}
+		seen[c] = true
+		matches = append(matches, c)
+	}
+	return matches
+}
+
+// argumentChoices lists what may follow cmd once args have been typed
+func (m model) argumentChoices(cmd string, args []string) []string {
+	switch cmd {
+	case "strategy":
+		switch {
+		case len(args) == 0:
+			return append(append([]string{"add", "remove", "restore"}, m.availableStrategies...), m.instanceIDs()...)
+		case len(args) == 1 && args[0] == "add":
+			return m.availableStrategies
+		case len(args) == 1 && args[0] == "remove":
+			return m.instanceIDs()
+		}
+	case "set":
+		cur := m.current()
+		if cur == nil {
+			return nil
+		}
+		switch len(args) {
+		case 0:
+			names := make([]string, 0, len(cur.Params))
+			for _, p := range cur.Params {
+				names = append(names, p.Name)
+			}
+			return names
+		case 1:
+			for _, p := range cur.Params {
+				if p.Name == args[0] {
+					return p.Options
+				}
+			}
+		}
+	case "close":
+		if len(args) == 0 {
+			return m.positionSymbols()
+		}
+	case "buy", "sell":
+		switch {
+		case len(args) == 0 && cmd == "sell":
+			return m.positionSymbols()
+		case len(args) == 2:
+			return []string{"limit", "stop", "stoplimit", "day", "gtc", "ioc"}
+		case len(args) > 2 && orderTypeArgs[strings.ToLower(args[2])] == len(args)-2:
+			return []string{"day", "gtc", "ioc"}
+		}
+	case "trail":
+		switch {
+		case len(args) == 0:
+			return append([]string{"off"}, m.positionSymbols()...)
+		case len(args) == 1 && args[0] == "off":
+			return m.positionSymbols()
+		}
+	case "cancel":
+		if len(args) == 0 {
+			return m.workingOrderIDs()
+		}
+	case "oco":
+		if len(args) == 0 {
+			return []string{"cancel"}
+		}
+		if len(args) == 1 && args[0] == "cancel" {
+			return m.workingOCOPairs()
+		}
+	case "start", "stop", "reset", "resync":
+		if len(args) == 0 {
+			return m.instanceIDs()
+		}
+	case "mode":
+		if len(args) == 0 {
+			return []string{"live", "visual"}
+		}
+	case "record":
+		if len(args) == 0 {
+			return []string{"on", "off"}
+		}
+	case "loglevel":
+		switch len(args) {
+		case 0:
+			return app.LogTargets
+		case 1:
+			return []string{"debug", "info", "warn", "error"}
+		}
+	case "export":
+		switch len(args) {
+		case 0:
+			return []string{"main", "orders", "strat"}
+		case 1:
+			return []string{"text", "json"}
+		}
+	}
+	return nil
+}
+
+// instanceIDs lists strategy instance IDs in the order they were added
+func (m model) instanceIDs() []string {
+	if m.engine == nil {
+		return nil
+	}
+	var ids []string
+	for _, inst := range m.engine.Strategies() {
+		ids = append(ids, inst.ID)
+	}
+	return ids
+}
+
+// workingOCOPairs lists the IDs of OCO pairs whose legs are both still working
+func (m model) workingOCOPairs() []string {
+	if m.om == nil {
+		return nil
+	}
+	var ids []string
+	for _, pair := range m.om.GetOCOPairs() {
+		if pair.State == execution.OCOWorking {
+			ids = append(ids, pair.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// positionSymbols lists the symbols with an open position
+func (m model) positionSymbols() []string {
+	var symbols []string
+	for _, p := range m.positions {
+		if p.Quantity != 0 {
+			symbols = append(symbols, p.Symbol)
+		}
+	}
+	return symbols
+}
+
+// completeCommand replaces the word being typed with the next candidate.
+// Repeated Tab presses cycle through the candidates for the original input;
+// a single candidate is accepted and followed by a space.
+func (m model) completeCommand(step int) model {
+	if m.completionBase == "" {
+		m.completionBase = m.commandInput
+		m.completionIndex = 0
+	} else {
+		m.completionIndex += step
+	}
+
+	candidates := m.completions(m.completionBase)
+	if len(candidates) == 0 {
+		m.completionBase = ""
+		return m
+	}
+	index := ((m.completionIndex % len(candidates)) + len(candidates)) % len(candidates)
+
+	_, partial := splitCommand(m.completionBase)
+	m.commandInput = m.completionBase[:len(m.completionBase)-len(partial)] + candidates[index]
+	if len(candidates) == 1 {
+		m.commandInput += " "
+		m.completionBase = ""
+	}
+	return m
+}
+
+// validateCommand returns why the input cannot run as typed, or "" if it may.
+// Missing arguments are not errors since the user may still be typing them.
+func (m model) validateCommand(input string) string {
+	words, partial := splitCommand(input)
+	if len(words) == 0 {
+		if partial != "" && len(m.completions(input)) == 0 && lookupCommand(partial) == nil {
+			return "Unknown command: " + partial
+		}
+		return ""
+	}
+
+	name := words[0]
+	spec := lookupCommand(name)
+	if spec == nil {
+		return "Unknown command: " + name
+	}
+
+	args := words[1:]
+	if partial != "" {
+		args = append(args, partial)
+	}
+	if len(args) > spec.args.max {
+		return "Too many arguments. Usage: " + spec.Usage
+	}
+	if name == "set" && len(args) == 2 {
+		if problem := m.validateParamValue(args[0], args[1]); problem != "" {
+			return problem
+		}
+	}
+	for _, pos := range spec.intArgs {
+		if pos <= len(args) {
+			if _, err := strconv.Atoi(args[pos-1]); err != nil {
+				return fmt.Sprintf("%q is not a number. Usage: %s", args[pos-1], spec.Usage)
+			}
+		}
+	}
+	return ""
+}
+
+// validateParamValue checks a :set value against the shown strategy's schema
+func (m model) validateParamValue(name, value string) string {
+	cur := m.current()
+	if cur == nil {
+		return ""
+	}
+	for _, p := range cur.Params {
+		if p.Name == name {
+			if err := p.Validate(value); err != nil {
+				return err.Error()
+			}
+			return ""
+		}
+	}
+	return "Unknown parameter: " + name
+}
+
+// renderCommandInput draws the input with the first candidate's remaining
+// letters as a ghost suggestion, and a validation error or usage hint on the right
+func (m model) renderCommandInput() string {
+	content := m.commandInput
+	if m.completionBase == "" {
+		if _, partial := splitCommand(m.commandInput); partial != "" {
+			if candidates := m.completions(m.commandInput); len(candidates) > 0 && len(candidates[0]) > len(partial) {
+				content += disabledStyle.Render(candidates[0][len(partial):])
+			}
+		}
+	}
+
+	var hint string
+	if problem := m.validateCommand(m.commandInput); problem != "" {
+		hint = errorStyle.Render(problem)
+	} else if words, partial := splitCommand(m.commandInput); len(words) > 0 {
+		args := len(words) - 1
+		if partial != "" {
+			args++
+		}
+		if spec := lookupCommand(words[0]); spec != nil && args < spec.args.min {
+			hint = disabledStyle.Render(spec.Usage)
+		}
+	}
+	if hint == "" {
+		return content
+	}
+
+	// Right-align the hint inside the bar's padding
+	spacing := m.width - 2 - lipgloss.Width(content) - lipgloss.Width(hint)
+	if spacing < 2 {
+		spacing = 2
+	}
+	return content + strings.Repeat(" ", spacing) + hint
+}
+
+// renderCompletionList draws the candidates when there is more than one, with
+// the one Tab would insert (or has inserted) highlighted. Returns "" otherwise.
+func (m model) renderCompletionList() string {
+	base := m.commandInput
+	selected := 0
+	if m.completionBase != "" {
+		base = m.completionBase
+	}
+	candidates := m.completions(base)
+	if len(candidates) < 2 {
+		return ""
+	}
+	if m.completionBase != "" {
+		selected = ((m.completionIndex % len(candidates)) + len(candidates)) % len(candidates)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Tab: ")
+	for i, c := range candidates {
+		item := disabledStyle.Render(c)
+		if i == selected {
+			item = menuItemStyle.Render(c)
+		}
+		// Leave room for the "..." marker
+		if lipgloss.Width(sb.String())+lipgloss.Width(item)+5 > m.width-2 {
+			sb.WriteString(disabledStyle.Render("..."))
+			break
+		}
+		sb.WriteString(item + "  ")
+	}
+	return sb.String()
+}
@@ -0,0 +1,74 @@
+package UI
+
+import (
+	"fmt"
+	"strings"
+	"tradovate-execution-engine/engine/internal/contracts"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openContractSearch shows the contracts :find returned for text in the content area
+func (m model) openContractSearch(text string, matches []contracts.ContractMatch) model {
+	m.findText = text
+	m.findResults = matches
+	m.findOpen = true
+	m.findScroll = 0
+	m.catalogOpen = false
+	m.statusMsg = fmt.Sprintf("%d contracts match %s: w/s scroll, Esc close", len(matches), text)
+	return m
+}
+
+// handleContractSearch takes a key while search results are shown, like
+// handleStrategyCatalog
+func (m model) handleContractSearch(key string) (model, bool) {
+	maxScroll := max(len(m.findLines())-m.catalogHeight(), 0)
+	switch key {
+	case "w", "up":
+		m.findScroll = max(min(m.findScroll, maxScroll)-1, 0)
+	case "s", "down":
+		m.findScroll = min(m.findScroll+1, maxScroll)
+	case "W":
+		m.findScroll = 0
+	case "S":
+		m.findScroll = maxScroll
+	case "esc":
+		m.findOpen = false
+		m.statusMsg = ""
+	default:
+		m.findOpen = false
+		return m, false
+	}
+	return m, true
+}
+
+// findLines lays out one contract per line: name, expiration and description
+func (m model) findLines() []string {
+	if len(m.findResults) == 0 {
+		return []string{"No contracts match " + m.findText}
+	}
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	lines := []string{headerStyle.Render(fmt.Sprintf("  %-12s %-12s %s", "Contract", "Expires", "Description")), ""}
+	for _, c := range m.findResults {
+		expires := "-"
+		if !c.Expiration.IsZero() {
+			expires = c.Expiration.Format("2006-01-02")
+		}
+		lines = append(lines, fmt.Sprintf("  %s %-12s %s", menuItemStyle.Render(fmt.Sprintf("%-12s", c.Name)), expires, c.Description))
+	}
+	return lines
+}
+
+// renderContractSearch draws the visible part of the search results
+func (m model) renderContractSearch() string {
+	lines := m.findLines()
+	height := m.catalogHeight()
+	start := min(m.findScroll, max(len(lines)-height, 0))
+	end := min(start+height, len(lines))
+
+	hint := "[Esc close]"
+	if len(lines) > height {
+		hint = fmt.Sprintf("[Lines %d-%d of %d - w/s or ↑/↓ scroll, W=top, S=bottom, Esc close]", start+1, end, len(lines))
+	}
+	return fmt.Sprintf("═══ CONTRACTS MATCHING %s ═══\n\n%s\n\n%s", m.findText, strings.Join(lines[start:end], "\n"), disabledStyle.Render(hint))
+}
@@ -0,0 +1,158 @@
+package UI
+
+import (
+	"fmt"
+	"strings"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// previewAction works out what :flatten or :kill would send and shows it for a
+// y/n answer. :flatten! and :kill! skip the preview.
+func (m model) previewAction(action string) model {
+	plan, err := m.actionPlan(action)
+	if err != nil {
+		m.statusMsg = errorStyle.Render("Preview failed: " + err.Error())
+		return m
+	}
+	if action == "flatten" && plan.Empty() {
+		m.statusMsg = errorStyle.Render("Nothing to flatten: no open positions or working orders")
+		return m
+	}
+	m.confirmAction = action
+	m.confirmPlan = plan
+	m.statusMsg = errorStyle.Render(fmt.Sprintf("Send the %s shown above? y/n", action))
+	return m
+}
+
+// actionPlan returns the current plan for :flatten or :kill
+func (m model) actionPlan(action string) (app.KillPlan, error) {
+	if action == "kill" {
+		return m.engine.PreviewKillSwitch()
+	}
+	orders, err := m.engine.PreviewFlatten()
+	return app.KillPlan{FlattenPlan: orders}, err
+}
+
+// handleActionConfirm answers the flatten, kill or override prompt: y sends it,
+// any other key does not. A plan that changed since it was shown is shown again
+// instead.
+func (m model) handleActionConfirm(key string) model {
+	action := m.confirmAction
+	shown := m.confirmPlan
+	m.confirmAction = ""
+	m.confirmPlan = app.KillPlan{}
+	if key != "y" && key != "Y" {
+		m.statusMsg = strings.ToUpper(action[:1]) + action[1:] + " aborted"
+		return m
+	}
+	if action == "override" {
+		return m.runNewsOverride()
+	}
+
+	plan, err := m.actionPlan(action)
+	if err != nil {
+		m.statusMsg = errorStyle.Render("Preview failed: " + err.Error())
+		return m
+	}
+	if planText(plan) != planText(shown) {
+		m = m.previewAction(action)
+		if m.confirmAction != "" {
+			m.statusMsg = errorStyle.Render(fmt.Sprintf("Positions or orders changed, check the %s again. y/n", action))
+		}
+		return m
+	}
+
+	if action == "kill" {
+		return m.runKillSwitch()
+	}
+	return m.runFlatten()
+}
+
+// runFlatten cancels working orders and closes all positions
+func (m model) runFlatten() model {
+	if !m.connected {
+		m.statusMsg = errorStyle.Render("Must be connected to API to flatten positions")
+		return m
+	}
+	if m.tradingMode != ModeLive {
+		m.statusMsg = errorStyle.Render("Cannot flatten in Visual mode")
+		m.mainLogger.Errorf("Flatten rejected: Not in Live mode")
+		return m
+	}
+	if err := m.engine.Flatten(); err != nil {
+		m.statusMsg = errorStyle.Render("Flatten failed: " + err.Error())
+		return m
+	}
+	m.statusMsg = successStyle.Render("All positions flattened")
+	return m
+}
+
+// runNewsOverride lifts the news lockout in force
+func (m model) runNewsOverride() model {
+	window, err := m.engine.OverrideNewsLockout()
+	if err != nil {
+		m.statusMsg = errorStyle.Render("Override failed: " + err.Error())
+		return m
+	}
+	m.statusMsg = errorStyle.Render(fmt.Sprintf("News lockout overridden - strategies trade through %s", window.Name))
+	return m
+}
+
+// runKillSwitch engages the kill switch
+func (m model) runKillSwitch() model {
+	if err := m.engine.KillSwitch(); err != nil {
+		m.statusMsg = errorStyle.Render("Kill switch engaged with errors: " + err.Error())
+		return m
+	}
+	m.statusMsg = errorStyle.Render("Kill switch engaged. Use :arm to trade again")
+	return m
+}
+
+// renderActionConfirm draws the pending flatten or kill plan, or the news
+// lockout to override, in the content area
+func (m model) renderActionConfirm() string {
+	if m.confirmAction == "override" {
+		window, _ := m.engine.NewsLockout()
+		return fmt.Sprintf("═══ NEWS LOCKOUT OVERRIDE ═══\n\n%s\nStrategies resume now and trade until %s.\nPositions already closed stay closed.\n\n%s",
+			window, window.End.Format("15:04"), errorStyle.Render("y to override, any other key to abort"))
+	}
+	title := "FLATTEN PREVIEW"
+	if m.confirmAction == "kill" {
+		title = "KILL SWITCH PREVIEW"
+	}
+	body := planText(m.confirmPlan)
+	return fmt.Sprintf("═══ %s ═══\n\n%s\n%s", title, body,
+		errorStyle.Render("y to send, any other key to abort"))
+}
+
+// planText lists a plan one line per action. It is also how a plan is compared
+// with the one shown.
+func planText(plan app.KillPlan) string {
+	var b strings.Builder
+	if len(plan.Strategies) > 0 {
+		b.WriteString(fmt.Sprintf("Stop strategies: %s\n", strings.Join(plan.Strategies, ", ")))
+	}
+	if len(plan.TrailingStops) > 0 {
+		b.WriteString(fmt.Sprintf("Remove trailing stops: %s\n", strings.Join(plan.TrailingStops, ", ")))
+	}
+
+	b.WriteString(fmt.Sprintf("\nCancel working orders (%d)\n", len(plan.Cancels)))
+	for _, o := range plan.Cancels {
+		price := o.Price
+		if o.Type == models.TypeStop {
+			price = o.StopPrice
+		}
+		b.WriteString(fmt.Sprintf("  %-8s %-4s %4d %-6s @ %.2f  ID: %s\n",
+			o.Symbol, o.Side, o.Quantity-o.FilledQty(), o.Type, price, o.ID))
+	}
+
+	b.WriteString(fmt.Sprintf("\nClose positions (%d)\n", len(plan.Closes)))
+	if len(plan.Closes) > 0 {
+		b.WriteString(fmt.Sprintf("  %-8s %7s %-4s %4s\n", "Symbol", "NetPos", "Side", "Qty"))
+	}
+	for _, c := range plan.Closes {
+		b.WriteString(fmt.Sprintf("  %-8s %+7d %-4s %4d  MARKET\n", c.Symbol, c.NetPos, c.Side, c.Quantity))
+	}
+	return b.String()
+}
@@ -0,0 +1,122 @@
+package UI
+
+import (
+	"fmt"
+	"strings"
+	"tradovate-execution-engine/engine/internal/logger"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// selectedLogStyle highlights log lines in a visual selection
+var selectedLogStyle = lipgloss.NewStyle().Reverse(true)
+
+// logSelection is a visual selection in a log panel. Both ends are entry
+// positions as logger.FirstIndex counts them, so the selection stays on the same
+// entries as new ones arrive and old ones are trimmed.
+type logSelection struct {
+	log    *logger.Logger
+	anchor int // Entry where v was pressed
+	cursor int // Entry w/s move
+}
+
+// activeLog returns the log panel of the active tab and its scroll offset, nil
+// on tabs without one
+func (m *model) activeLog() (*logger.Logger, *int) {
+	switch m.activeTab {
+	case TabMain:
+		return m.mainLogger, &m.logScrollOffset
+	case TabOrderManagement:
+		return m.orderLogger, &m.orderLogScrollOffset
+	case TabStrategy:
+		return m.strategyLogView(), &m.stratLogScrollOffset
+	}
+	return nil, nil
+}
+
+// logSelectionFor returns the selection in log, nil if log has none
+func (m model) logSelectionFor(log *logger.Logger) *logSelection {
+	if m.logSelect == nil || m.logSelect.log != log {
+		return nil
+	}
+	return m.logSelect
+}
+
+// selectedRange returns the first and last selected entries, clamped to the
+// entries the log still keeps. ok is false once none of them are kept.
+func (s *logSelection) selectedRange() (first, last int, ok bool) {
+	first, last = min(s.anchor, s.cursor), max(s.anchor, s.cursor)
+	kept := s.log.FirstIndex()
+	first, last = max(first, kept), min(last, kept+s.log.Count()-1)
+	return first, last, first <= last
+}
+
+// startLogSelection starts a selection on the newest entry in view of the
+// active tab's log panel
+func (m model) startLogSelection() model {
+	log, offset := m.activeLog()
+	if log == nil {
+		return m
+	}
+	count := log.Count()
+	if count == 0 {
+		m.statusMsg = "Log is empty"
+		return m
+	}
+	at := log.FirstIndex() + m.lastLogEntryInView(log, *offset)
+	m.logSelect = &logSelection{log: log, anchor: at, cursor: at}
+	m.statusMsg = "Selecting log lines: w/s extend, y copy, v or Esc cancel"
+	return m
+}
+
+// handleLogSelection takes a key while a selection is active on the shown log
+// panel. It reports false for keys it leaves to normal mode.
+func (m model) handleLogSelection(key string) (model, bool) {
+	log, offset := m.activeLog()
+	sel := m.logSelectionFor(log)
+	if sel == nil {
+		m.logSelect = nil
+		return m, false
+	}
+
+	switch key {
+	case "w", "up", "s", "down":
+		step := 1
+		if key == "w" || key == "up" {
+			step = -1
+		}
+		kept := log.FirstIndex()
+		sel.cursor = min(max(sel.cursor+step, kept), kept+log.Count()-1)
+
+		// Keep the moving end in view
+		row := sel.cursor - kept
+		if row < *offset {
+			*offset = row
+		} else if row > m.lastLogEntryInView(log, *offset) {
+			*offset = logScrollTo(log.GetEntries(), row, max(m.height-11, 1), logMsgWidth(m.logPanelWidth(m.activeTab)), m.logWraps(m.activeTab))
+		}
+	case "y":
+		m.logSelect = nil
+		first, last, ok := sel.selectedRange()
+		if !ok {
+			m.statusMsg = "The selected lines are no longer in the log"
+			return m, true
+		}
+		var text strings.Builder
+		for _, entry := range log.EntryRange(first, last) {
+			text.WriteString(logger.FormatEntry(entry) + "\n")
+		}
+		if err := clipboard.WriteAll(text.String()); err != nil {
+			m.statusMsg = errorStyle.Render("Copy failed: " + err.Error())
+			return m, true
+		}
+		m.statusMsg = successStyle.Render(fmt.Sprintf("Copied %d log lines to clipboard", last-first+1))
+	case "v", "esc":
+		m.logSelect = nil
+		m.statusMsg = "Selection cancelled"
+	default:
+		return m, false
+	}
+	return m, true
+}
@@ -0,0 +1,132 @@
+package UI
+
+import (
+	"strings"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// logPrefixLen is the width of the "15:04:05 LEVEL " prefix of a log line;
+// wrapped continuation lines are indented by it
+const logPrefixLen = 15
+
+// logWraps reports whether the log panel of tab word-wraps long messages
+// rather than truncating them with "..."
+func (m model) logWraps(tab Tab) bool {
+	return !m.truncateLogs[tab]
+}
+
+// toggleLogWrap switches the active tab's log panel between wrapping and
+// truncating long messages
+func (m *model) toggleLogWrap() {
+	if log, _ := m.activeLog(); log == nil {
+		return
+	}
+	if m.truncateLogs == nil {
+		m.truncateLogs = make(map[Tab]bool)
+	}
+	m.truncateLogs[m.activeTab] = !m.truncateLogs[m.activeTab]
+	if m.truncateLogs[m.activeTab] {
+		m.statusMsg = "Log: long messages truncated (t to wrap)"
+	} else {
+		m.statusMsg = "Log: long messages wrapped (t to truncate)"
+	}
+}
+
+// logMsgWidth is the room for the message of a log line in a panel width wide
+func logMsgWidth(width int) int {
+	return max(max(width-4, 20)-logPrefixLen, 10) // Border (2) + Padding (2)
+}
+
+// logPanelWidth is the width of the log panel on tab, as the tab's render
+// function lays it out
+func (m model) logPanelWidth(tab Tab) int {
+	if tab == TabStrategy {
+		return max(m.width-2*(m.width*25/100)-2, 20)
+	}
+	return max(m.width-m.width*4/10-2, 20)
+}
+
+// activeLogMaxScroll is the offset of the active tab's log panel that shows its
+// newest entries at the bottom
+func (m model) activeLogMaxScroll() int {
+	log, _ := m.activeLog()
+	if log == nil {
+		return 0
+	}
+	entries := log.GetEntries()
+	return logScrollTo(entries, len(entries)-1, max(m.height-11, 1), logMsgWidth(m.logPanelWidth(m.activeTab)), m.logWraps(m.activeTab))
+}
+
+// wrapLogMessage breaks msg into lines of at most width characters, at spaces
+// where it can and mid-word where a word is longer than a line. Line breaks in
+// msg are kept.
+func wrapLogMessage(msg string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(msg, "\n") {
+		var line string
+		for _, word := range strings.Fields(para) {
+			for len(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				lines = append(lines, word[:width])
+				word = word[width:]
+			}
+			switch {
+			case line == "":
+				line = word
+			case len(line)+1+len(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// logEntryHeight is how many lines entry takes in a panel
+func logEntryHeight(entry logger.LogEntry, msgWidth int, wrap bool) int {
+	if !wrap {
+		return 1
+	}
+	return len(wrapLogMessage(entry.Message, msgWidth))
+}
+
+// logScrollTo returns the smallest offset at which entries up to last still
+// fit in lines rendered lines, so last shows at the bottom of the panel. An
+// entry taller than the panel gets it to itself.
+func logScrollTo(entries []logger.LogEntry, last, lines, msgWidth int, wrap bool) int {
+	if !wrap {
+		return max(last+1-lines, 0)
+	}
+	start, used := last, 0
+	for ; start >= 0; start-- {
+		used += logEntryHeight(entries[start], msgWidth, wrap)
+		if used > lines {
+			break
+		}
+	}
+	return min(start+1, max(last, 0))
+}
+
+// lastLogEntryInView is the newest entry of log, counted from the oldest kept,
+// that the active tab's panel shows scrolled to offset
+func (m model) lastLogEntryInView(log *logger.Logger, offset int) int {
+	entries := log.GetEntries()
+	if len(entries) == 0 {
+		return 0
+	}
+	lines, msgWidth, wrap := max(m.height-11, 1), logMsgWidth(m.logPanelWidth(m.activeTab)), m.logWraps(m.activeTab)
+	row := min(max(offset, 0), len(entries)-1)
+	for used := logEntryHeight(entries[row], msgWidth, wrap); row+1 < len(entries); row++ {
+		used += logEntryHeight(entries[row+1], msgWidth, wrap)
+		if used > lines {
+			break
+		}
+	}
+	return row
+}
@@ -0,0 +1,192 @@
+package UI
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// maxPnLHistory is how many P&L samples the model keeps: 24 hours at one
+	// sample every 10 seconds
+	maxPnLHistory = 8640
+
+	// pnlChartRows is the height of the braille chart in terminal rows
+	pnlChartRows = 5
+
+	// minPnLChartPlot is the narrowest braille plot area; below it the chart
+	// falls back to a one line sparkline
+	minPnLChartPlot = 12
+)
+
+// pnlChartPoint is the P&L range one dot column of the chart covers
+type pnlChartPoint struct {
+	low, high, last float64
+}
+
+// renderPnLChart draws total P&L over the session in width columns: the
+// current, lowest and highest values, then a braille line chart with its y-axis
+// scaled to the data and the zero line marked. Narrow panels get a sparkline.
+func (m model) renderPnLChart(width int) string {
+	values := make([]float64, 0, len(m.pnlHistory)+1)
+	for _, p := range m.pnlHistory {
+		values = append(values, p.PnL)
+	}
+	if m.connected {
+		values = append(values, m.totalPnL)
+	}
+	if len(values) < 2 {
+		return disabledStyle.Render("Collecting P&L history") + "\n"
+	}
+
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+
+	var b strings.Builder
+	current := values[len(values)-1]
+	b.WriteString(fmt.Sprintf("Now %s  Min %s  Max %s\n",
+		pnlStyleFor(current).Render(fmt.Sprintf("$%.2f", current)),
+		pnlStyleFor(low).Render(fmt.Sprintf("$%.2f", low)),
+		pnlStyleFor(high).Render(fmt.Sprintf("$%.2f", high))))
+
+	// Flat history still needs a range to draw in
+	if high == low {
+		low, high = low-1, high+1
+	}
+	topLabel, bottomLabel := fmt.Sprintf("$%.0f", high), fmt.Sprintf("$%.0f", low)
+	labelWidth := max(len(topLabel), len(bottomLabel), len("$0"))
+	plotWidth := width - labelWidth - 1
+	if plotWidth < minPnLChartPlot {
+		spread := make([]float64, 0, width)
+		for _, p := range decimate(values, max(min(width, len(values)), 1)) {
+			spread = append(spread, p.last)
+		}
+		b.WriteString(pnlStyleFor(current).Render(sparkline(spread, width)) + "\n")
+		return b.String()
+	}
+
+	b.WriteString(brailleChart(values, plotWidth, labelWidth, low, high, topLabel, bottomLabel))
+	start := m.pnlHistory[0].Time.Format("15:04")
+	b.WriteString(strings.Repeat(" ", labelWidth) + "└" +
+		disabledStyle.Render(start+strings.Repeat(" ", max(plotWidth-len(start)-3, 1))+"now") + "\n")
+	return b.String()
+}
+
+// brailleChart plots values plotWidth cells wide and pnlChartRows high, two dot
+// columns and four dot rows per cell, with the axis labels on the left
+func brailleChart(values []float64, plotWidth, labelWidth int, low, high float64, topLabel, bottomLabel string) string {
+	dotRows := pnlChartRows * 4
+	points := decimate(values, plotWidth*2)
+	dotRow := func(v float64) int {
+		return int(math.Round((high - v) / (high - low) * float64(dotRows-1)))
+	}
+
+	// Cell bits, and the value that colours each cell
+	cells := make([][]rune, pnlChartRows)
+	signs := make([][]float64, pnlChartRows)
+	for r := range cells {
+		cells[r] = make([]rune, plotWidth)
+		signs[r] = make([]float64, plotWidth)
+	}
+	prev := points[0].last
+	for x, p := range points {
+		// Join each column to the last to keep the line unbroken
+		top, bottom := dotRow(math.Max(p.high, prev)), dotRow(math.Min(p.low, prev))
+		for y := top; y <= bottom; y++ {
+			cells[y/4][x/2] |= brailleDot(x%2, y%4)
+			signs[y/4][x/2] = p.last
+		}
+		prev = p.last
+	}
+
+	zeroRow, zeroBit := -1, rune(0)
+	if low <= 0 && high >= 0 {
+		y := dotRow(0)
+		zeroRow = y / 4
+		zeroBit = brailleDot(0, y%4) | brailleDot(1, y%4)
+	}
+
+	var b strings.Builder
+	for r := range cells {
+		label := ""
+		switch r {
+		case 0:
+			label = topLabel
+		case pnlChartRows - 1:
+			label = bottomLabel
+		case zeroRow:
+			label = "$0"
+		}
+		axis := "│"
+		if label != "" {
+			axis = "┤"
+		}
+		b.WriteString(fmt.Sprintf("%*s%s", labelWidth, label, axis))
+
+		for c, bits := range cells[r] {
+			switch {
+			case bits != 0:
+				b.WriteString(pnlStyleFor(signs[r][c]).Render(string(0x2800 + bits)))
+			case r == zeroRow:
+				b.WriteString(disabledStyle.Render(string(0x2800 + zeroBit)))
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// decimate spreads values over n columns. With more values than columns each
+// column keeps the range and last value of its share, so spikes are not lost;
+// with fewer, values are interpolated between.
+func decimate(values []float64, n int) []pnlChartPoint {
+	points := make([]pnlChartPoint, n)
+	if len(values) >= n {
+		for i := range points {
+			from, to := i*len(values)/n, (i+1)*len(values)/n
+			p := pnlChartPoint{low: values[from], high: values[from]}
+			for _, v := range values[from:to] {
+				p.low, p.high = math.Min(p.low, v), math.Max(p.high, v)
+			}
+			p.last = values[to-1]
+			points[i] = p
+		}
+		return points
+	}
+	for i := range points {
+		pos := 0.0
+		if n > 1 {
+			pos = float64(i) * float64(len(values)-1) / float64(n-1)
+		}
+		j := int(pos)
+		v := values[j]
+		if j+1 < len(values) {
+			v += (values[j+1] - v) * (pos - float64(j))
+		}
+		points[i] = pnlChartPoint{low: v, high: v, last: v}
+	}
+	return points
+}
+
+// brailleDot is the bit of the dot at column x (0-1) and row y (0-3) of a
+// braille cell
+func brailleDot(x, y int) rune {
+	if y == 3 {
+		return rune(0x40 << x)
+	}
+	return rune(1 << (y + 3*x))
+}
+
+// pnlStyleFor colours a P&L value green when it is not negative, red otherwise
+func pnlStyleFor(v float64) lipgloss.Style {
+	if v < 0 {
+		return errorStyle
+	}
+	return successStyle
+}
@@ -0,0 +1,69 @@
+package UI
+
+import (
+	"fmt"
+	"os"
+	"tradovate-execution-engine/engine/internal/portfolio"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Position loss alert styles: yellow for a warning, red for critical
+var (
+	alertWarningStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("226")).
+				Bold(true)
+
+	alertCriticalStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("196")).
+				Bold(true)
+)
+
+// alertStyle is the style a position at level is shown in
+func alertStyle(level portfolio.AlertLevel) (lipgloss.Style, bool) {
+	switch level {
+	case portfolio.AlertWarning:
+		return alertWarningStyle, true
+	case portfolio.AlertCritical:
+		return alertCriticalStyle, true
+	}
+	return lipgloss.Style{}, false
+}
+
+// checkPositionAlerts flashes each new position loss alert in the status bar,
+// ringing the terminal bell when Risk.PositionAlertBell is set, and keeps the
+// alerts still active for the status bar and Positions tab
+func (m model) checkPositionAlerts() model {
+	for _, alert := range m.pt.PositionAlertsSince(m.alertSeq) {
+		m.alertSeq = alert.Seq
+		style, _ := alertStyle(alert.Level)
+		m.statusMsg = style.Render(fmt.Sprintf("%s ALERT: %s unrealized $%.2f past -$%.2f",
+			alert.Level, alert.Symbol, alert.PnL, alert.Threshold))
+		if m.config != nil && m.config.Risk.PositionAlertBell {
+			os.Stdout.WriteString("\a")
+		}
+	}
+	m.positionAlerts = m.pt.ActivePositionAlerts()
+	return m
+}
+
+// alertLevel returns the active alert level of symbol's position
+func (m model) alertLevel(symbol string) portfolio.AlertLevel {
+	for _, alert := range m.positionAlerts {
+		if alert.Symbol == symbol {
+			return alert.Level
+		}
+	}
+	return portfolio.AlertNone
+}
+
+// renderPositionAlerts shows a badge per position whose loss is past an alert
+// level, kept in view while a status message is shown
+func (m model) renderPositionAlerts() string {
+	badges := ""
+	for _, alert := range m.positionAlerts {
+		style, _ := alertStyle(alert.Level)
+		badges += style.Render(fmt.Sprintf("%s %s -$%.0f", alert.Level, alert.Symbol, alert.Threshold)) + " "
+	}
+	return badges
+}
@@ -0,0 +1,88 @@
+package UI
+
+import (
+	"fmt"
+	"strings"
+	"tradovate-execution-engine/engine/internal/execution"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openStrategyCatalog shows every registered strategy with its description and
+// default params in the content area
+func (m model) openStrategyCatalog() model {
+	m.strategyCatalog = execution.DescribeAll()
+	m.catalogOpen = true
+	m.findOpen = false
+	m.catalogScroll = 0
+	m.statusMsg = fmt.Sprintf("%d strategies registered: w/s scroll, Esc close", len(m.strategyCatalog))
+	return m
+}
+
+// handleStrategyCatalog takes a key while the catalog is shown. Scroll keys
+// move it and Esc closes it; any other key closes it and is handled as usual,
+// reported by a false return.
+func (m model) handleStrategyCatalog(key string) (model, bool) {
+	maxScroll := max(len(m.catalogLines())-m.catalogHeight(), 0)
+	switch key {
+	case "w", "up":
+		m.catalogScroll = max(min(m.catalogScroll, maxScroll)-1, 0)
+	case "s", "down":
+		m.catalogScroll = min(m.catalogScroll+1, maxScroll)
+	case "W":
+		m.catalogScroll = 0
+	case "S":
+		m.catalogScroll = maxScroll
+	case "esc":
+		m.catalogOpen = false
+		m.statusMsg = ""
+	default:
+		m.catalogOpen = false
+		return m, false
+	}
+	return m, true
+}
+
+// catalogHeight is how many catalog lines fit below its title and above the hint
+func (m model) catalogHeight() int {
+	return max(m.height-11, 1)
+}
+
+// catalogLines lays out each strategy: its registry name and display name, the
+// description wrapped to the content width, then its params and defaults
+func (m model) catalogLines() []string {
+	width := max(m.width-10, 40)
+	descStyle := lipgloss.NewStyle().Width(width - 2)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
+	var lines []string
+	if len(m.strategyCatalog) == 0 {
+		return []string{"No strategies registered"}
+	}
+	for _, info := range m.strategyCatalog {
+		lines = append(lines, menuItemStyle.Render(info.Key)+" - "+info.Name)
+		for _, line := range strings.Split(descStyle.Render(info.Description), "\n") {
+			lines = append(lines, "  "+strings.TrimRight(line, " "))
+		}
+		lines = append(lines, "", headerStyle.Render(fmt.Sprintf("  %-18s %-10s %-20s %s", "Param", "Default", "Allowed", "Description")))
+		for _, p := range info.Params {
+			lines = append(lines, fmt.Sprintf("  %-18s %-10s %-20s %s", p.Name, p.Value, p.Range(), p.Description))
+		}
+		lines = append(lines, "", headerStyle.Render("  :strategy add "+info.Key), "")
+	}
+	return lines
+}
+
+// renderStrategyCatalog draws the visible part of the catalog
+func (m model) renderStrategyCatalog() string {
+	lines := m.catalogLines()
+	height := m.catalogHeight()
+	start := min(m.catalogScroll, max(len(lines)-height, 0))
+	end := min(start+height, len(lines))
+
+	hint := "[Esc close]"
+	if len(lines) > height {
+		hint = fmt.Sprintf("[Lines %d-%d of %d - w/s or ↑/↓ scroll, W=top, S=bottom, Esc close]", start+1, end, len(lines))
+	}
+	return fmt.Sprintf("═══ STRATEGIES ═══\n\n%s\n\n%s", strings.Join(lines[start:end], "\n"), disabledStyle.Render(hint))
+}
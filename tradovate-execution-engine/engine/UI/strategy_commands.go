@@ -0,0 +1,219 @@
+package UI
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/backtest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errNoStrategy is returned by commands that act on the shown instance when none is
+var errNoStrategy = errors.New("No strategy selected. Use :strategy add <name> first")
+
+// cmdStrategy adds, shows, removes or restores an instance:
+// :strategy add <name> | :strategy <id> | :strategy remove <id> | :strategy restore
+func cmdStrategy(m *model, args []string) (tea.Cmd, error) {
+	switch {
+	case args[0] == "add" && len(args) > 1:
+		*m = m.addStrategy(args[1])
+	case args[0] == "restore":
+		*m = m.restoreStrategy()
+	case args[0] == "remove" && len(args) > 1:
+		if err := m.engine.RemoveStrategy(args[1]); err != nil {
+			return nil, err
+		}
+		delete(m.strategies, args[1])
+		if m.selectedInstance == args[1] {
+			m.selectedInstance = ""
+			if list := m.engine.Strategies(); len(list) > 0 {
+				m.selectedInstance = list[len(list)-1].ID
+			}
+		}
+		m.statusMsg = successStyle.Render("Removed strategy " + args[1])
+	case m.strategies[args[0]] != nil:
+		m.activeTab = TabStrategy
+		m.selectedInstance = args[0]
+		m.stratLogScrollOffset = 1000000
+		m.statusMsg = "Showing strategy " + m.strategyLabel(m.current())
+	default:
+		// A registered name on its own adds an instance
+		*m = m.addStrategy(args[0])
+	}
+	return nil, nil
+}
+
+// cmdStrategies opens the catalog of registered strategies
+func cmdStrategies(m *model, args []string) (tea.Cmd, error) {
+	*m = m.openStrategyCatalog()
+	return nil, nil
+}
+
+// cmdClone adds a stopped copy of the shown instance on another symbol: :clone <symbol>
+func cmdClone(m *model, args []string) (tea.Cmd, error) {
+	cur := m.current()
+	if cur == nil {
+		return nil, errNoStrategy
+	}
+	symbol := strings.ToUpper(args[0])
+	inst, err := m.engine.CloneStrategy(cur.Instance.ID, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot clone strategy: %w", err)
+	}
+	*m = m.showStrategy(inst)
+	m.statusMsg = successStyle.Render(fmt.Sprintf("Cloned %s to %s on %s - :start when ready", cur.Instance.ID, inst.ID, symbol))
+	return nil, nil
+}
+
+// cmdSet changes a parameter of the shown, stopped instance: :set <param> <value>
+func cmdSet(m *model, args []string) (tea.Cmd, error) {
+	cur := m.current()
+	if cur == nil {
+		return nil, errNoStrategy
+	}
+	if cur.Instance.Runtime.Status() == app.StrategyRunning {
+		return nil, errors.New("Cannot change parameters while strategy is running. Stop it first")
+	}
+	paramName, paramValue := args[0], args[1]
+
+	// Checked against Tradovate when connected, so a typo fails here, not at :start
+	if strings.EqualFold(paramName, "symbol") {
+		if err := m.engine.ValidateSymbol(paramValue); err != nil {
+			return nil, fmt.Errorf("Cannot set symbol: %w", err)
+		}
+	}
+
+	if err := m.engine.SetParam(cur.Instance.ID, paramName, paramValue); err != nil {
+		return nil, fmt.Errorf("Cannot set parameter: %w", err)
+	}
+
+	m.statusMsg = successStyle.Render(fmt.Sprintf("Set %s = %s", paramName, paramValue))
+	m.saveStrategy(cur)
+	return nil, nil
+}
+
+// cmdStart starts an instance, by default the one shown: :start [id]
+func cmdStart(m *model, args []string) (tea.Cmd, error) {
+	s := m.targetStrategy(args)
+	if s == nil {
+		return nil, errors.New("No strategy selected")
+	}
+	if err := m.engine.StartStrategy(s.Instance.ID); err != nil {
+		return nil, fmt.Errorf("Cannot start strategy: %w", err)
+	}
+
+	s.resetMetricHistory()
+	m.saveStrategy(s)
+
+	m.statusMsg = successStyle.Render(fmt.Sprintf("Strategy %s STARTED on %s", s.Instance.ID, s.Instance.Symbol()))
+	return nil, nil
+}
+
+// cmdStop stops an instance, by default the one shown: :stop [id]
+func cmdStop(m *model, args []string) (tea.Cmd, error) {
+	s := m.targetStrategy(args)
+	if s == nil {
+		return nil, errors.New("No strategy selected")
+	}
+	*m = m.stopStrategy(s)
+	return nil, nil
+}
+
+// cmdReset clears the error of a failed instance: :reset [id]
+func cmdReset(m *model, args []string) (tea.Cmd, error) {
+	s := m.targetStrategy(args)
+	if s == nil {
+		return nil, errors.New("No strategy selected")
+	}
+	if err := m.engine.ResetStrategy(s.Instance.ID); err != nil {
+		return nil, fmt.Errorf("Cannot reset strategy: %w", err)
+	}
+	m.statusMsg = successStyle.Render(fmt.Sprintf("Strategy %s reset - :start it when ready", s.Instance.ID))
+	return nil, nil
+}
+
+// cmdResync has an instance paused for a position drift take the broker's position: :resync [id]
+func cmdResync(m *model, args []string) (tea.Cmd, error) {
+	s := m.targetStrategy(args)
+	if s == nil {
+		return nil, errors.New("No strategy selected")
+	}
+	netPos, err := m.engine.ResyncPosition(s.Instance.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot resync: %w", err)
+	}
+	m.statusMsg = successStyle.Render(fmt.Sprintf("Strategy %s resynced at %+d contracts", s.Instance.ID, netPos))
+	return nil, nil
+}
+
+// cmdRecord turns metrics CSVs on or off: :record <on|off>
+func cmdRecord(m *model, args []string) (tea.Cmd, error) {
+	switch strings.ToLower(args[0]) {
+	case "on":
+		*m = m.setMetricsRecording(true)
+	case "off":
+		*m = m.setMetricsRecording(false)
+	default:
+		return nil, errors.New("Usage: " + commandUsage("record"))
+	}
+	return nil, nil
+}
+
+// cmdBacktest replays the shown instance over recent minute bars: :backtest <minutes>.
+// The bars are fetched and the run made off the UI loop.
+func cmdBacktest(m *model, args []string) (tea.Cmd, error) {
+	cur := m.current()
+	if cur == nil {
+		return nil, errNoStrategy
+	}
+	if status := cur.Instance.Runtime.Status(); status == app.StrategyRunning || status == app.StrategyStarting {
+		return nil, errors.New("Cannot backtest while strategy is running. Stop it first")
+	}
+	if !m.connected {
+		return nil, errors.New("Must be connected to fetch historical bars")
+	}
+	minutes, err := strconv.Atoi(args[0])
+	if err != nil || minutes <= 0 {
+		return nil, errors.New("Invalid minutes: " + args[0])
+	}
+
+	params := cur.Instance.Params()
+	symbol := params["symbol"]
+	cfg := backtest.Config{
+		Strategy:      cur.Instance.Name,
+		Params:        params,
+		Symbol:        symbol,
+		ValuePerPoint: m.pt.GetValuePerPoint(symbol),
+		TickSize:      m.pt.GetTickSize(symbol),
+		Risk:          m.config.Risk,
+		Fees:          m.config.Fees,
+		Slippage:      m.config.Slippage,
+	}
+	md := m.marketDataSubscriptionManager
+	om := m.om
+
+	m.statusMsg = fmt.Sprintf("Backtesting %s over the last %d minutes...", m.strategyLabel(cur), minutes)
+	m.strategyLogger.Infof("Backtest requested: %s %s, last %d minutes", cur.Instance.Name, symbol, minutes)
+
+	return func() tea.Msg {
+		symbol, err := om.ResolveSymbol(symbol)
+		if err != nil {
+			return backtestMsg{err: err}
+		}
+		cfg.Symbol = symbol
+		cfg.Params["symbol"] = symbol
+
+		to := time.Now().UTC()
+		bars, err := backtest.FetchBars(md, symbol, to.Add(-time.Duration(minutes)*time.Minute), to, 30*time.Second)
+		if err != nil {
+			return backtestMsg{err: err}
+		}
+		cfg.Bars = bars
+		report, err := backtest.Run(cfg, nil)
+		return backtestMsg{report: report, err: err}
+	}, nil
+}
@@ -0,0 +1,328 @@
+package UI
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/schedule"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cmdQuit shuts the engine down and exits
+func cmdQuit(m *model, args []string) (tea.Cmd, error) {
+	next, cmd := m.beginShutdown()
+	*m = next
+	return cmd, nil
+}
+
+// cmdMode switches between live and visual trading: :mode <live|visual>
+func cmdMode(m *model, args []string) (tea.Cmd, error) {
+	switch strings.ToLower(args[0]) {
+	case "l", "live":
+		m.tradingMode = ModeLive
+		m.statusMsg = successStyle.Render("Switched to LIVE mode")
+		m.mainLogger.Info("Switched to LIVE trading mode")
+	case "v", "visual":
+		m.tradingMode = ModeVisual
+		m.statusMsg = "Switched to VISUAL mode"
+		m.mainLogger.Info("Switched to VISUAL mode")
+	default:
+		return nil, errors.New("Invalid mode. Use l for 'live' or v for 'visual'")
+	}
+	return nil, nil
+}
+
+// cmdConfig opens the config file in the editor
+func cmdConfig(m *model, args []string) (tea.Cmd, error) {
+	content, err := os.ReadFile(m.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read config: %w", err)
+	}
+	m.isLogView = false
+	m.editorTitle = "CONFIG EDITOR"
+	m.configEditor.SetValue(string(content))
+	m.configEditor.SetWidth(m.width - 4)
+	m.configEditor.SetHeight(m.height - 12) // Room for the risk help
+	m.mode = modeEditor
+	m.statusMsg = "Editing config. Press Ctrl+S to save, ESC to exit, or : to run commands"
+	return nil, nil
+}
+
+// cmdReload re-reads the config file and applies what can change without
+// reconnecting, listing what still needs a reconnect
+func cmdReload(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected || m.om == nil || m.config == nil {
+		m.statusMsg = "Not connected - config will be loaded on next connect"
+		return nil, nil
+	}
+
+	var newCfg *config.Config
+	data, err := os.ReadFile(m.configPath)
+	if err == nil {
+		newCfg, err = config.ParseConfig(data)
+	}
+	if err != nil {
+		m.mainLogger.Errorf("Config reload failed: %v", err)
+		return nil, fmt.Errorf("Reload failed: %w", err)
+	}
+
+	reconnectChanges := config.ReconnectRequiredChanges(m.config, newCfg)
+
+	// Every section is checked before any is applied, so a bad one leaves the
+	// running config as it was
+	var sched *schedule.TradingSchedule
+	if len(config.ScheduleChanges(m.config, newCfg)) > 0 {
+		if sched, err = schedule.NewTradingSchedule(newCfg.Schedule); err != nil {
+			m.mainLogger.Errorf("Schedule not applied: %v", err)
+			return nil, fmt.Errorf("Schedule not applied: %w", err)
+		}
+	}
+	if len(config.MaintenanceChanges(m.config, newCfg)) > 0 {
+		if _, err := schedule.NewMaintenanceWindows(newCfg.Maintenance, m.config.Tradovate.Environment); err != nil {
+			m.mainLogger.Errorf("Maintenance windows not applied: %v", err)
+			return nil, fmt.Errorf("Maintenance windows not applied: %w", err)
+		}
+	}
+	if len(config.NewsChanges(m.config, newCfg)) > 0 {
+		if _, err := schedule.NewNewsCalendar(newCfg.News, filepath.Dir(m.configPath)); err != nil {
+			m.mainLogger.Errorf("News calendar not applied: %v", err)
+			return nil, fmt.Errorf("News calendar not applied: %w", err)
+		}
+	}
+
+	// Only hot sections are taken, credentials stay as connected
+	applied := *m.config
+	var sections []string
+	hotChanges := 0
+	for _, section := range reloadSections {
+		changes := section.changes(m.config, newCfg)
+		if len(changes) == 0 {
+			continue
+		}
+		section.take(&applied, newCfg)
+		sections = append(sections, strings.ToLower(section.name))
+		hotChanges += len(changes)
+		for _, change := range changes {
+			m.mainLogger.Infof("%s config reloaded: %s", section.name, change)
+		}
+	}
+	if hotChanges > 0 {
+		if sched != nil {
+			m.om.SetSchedule(sched)
+		}
+		m.engine.ApplyConfig(&applied)
+		m.config = &applied
+	}
+
+	for _, field := range reconnectChanges {
+		m.mainLogger.Warnf("Config field %q changed - reconnect (!) required to apply", field)
+	}
+
+	applyText := fmt.Sprintf("Applied %d %s change(s)", hotChanges, strings.Join(sections, "/"))
+	switch {
+	case len(reconnectChanges) > 0 && hotChanges > 0:
+		m.statusMsg = errorStyle.Render(fmt.Sprintf("%s. Reconnect (!) required for: %s", applyText, strings.Join(reconnectChanges, ", ")))
+	case len(reconnectChanges) > 0:
+		m.statusMsg = errorStyle.Render("Reconnect (!) required for: " + strings.Join(reconnectChanges, ", "))
+	case hotChanges > 0:
+		m.statusMsg = successStyle.Render(applyText + " without reconnecting")
+	default:
+		m.statusMsg = "Config reloaded - no changes detected"
+	}
+	return nil, nil
+}
+
+// reloadSection is a config section :reload applies without reconnecting
+type reloadSection struct {
+	name    string // As logged, "Risk config reloaded: ..."
+	changes func(oldCfg, newCfg *config.Config) []string
+	take    func(applied, newCfg *config.Config) // Copies the section into applied
+}
+
+// reloadSections are the hot-applied sections in the order they are reported
+var reloadSections = []reloadSection{
+	{"Risk", config.RiskChanges, func(applied, newCfg *config.Config) { applied.Risk = newCfg.Risk }},
+	{"Schedule", config.ScheduleChanges, func(applied, newCfg *config.Config) { applied.Schedule = newCfg.Schedule }},
+	{"Maintenance", config.MaintenanceChanges, func(applied, newCfg *config.Config) { applied.Maintenance = newCfg.Maintenance }},
+	{"News", config.NewsChanges, func(applied, newCfg *config.Config) { applied.News = newCfg.News }},
+	{"Fee", config.FeeChanges, func(applied, newCfg *config.Config) { applied.Fees = newCfg.Fees }},
+	{"Slippage", config.SlippageChanges, func(applied, newCfg *config.Config) { applied.Slippage = newCfg.Slippage }},
+	{"Logging", config.LoggingChanges, func(applied, newCfg *config.Config) { applied.Logging = newCfg.Logging }},
+}
+
+// cmdWrite saves the config being edited
+func cmdWrite(m *model, args []string) (tea.Cmd, error) {
+	if m.configEditor.Value() != "" && !m.isLogView {
+		if _, err := m.saveEditorConfig(); err != nil {
+			return nil, fmt.Errorf("Failed to save config: %w", err)
+		}
+		m.statusMsg = successStyle.Render("Config saved")
+	}
+	return nil, nil
+}
+
+// cmdSaveExit saves the config being edited and leaves the editor
+func cmdSaveExit(m *model, args []string) (tea.Cmd, error) {
+	if m.configEditor.Value() != "" && !m.isLogView {
+		if _, err := m.saveEditorConfig(); err != nil {
+			// Stay in the editor so the mistake can be fixed
+			return nil, fmt.Errorf("Failed to save config: %w", err)
+		}
+	}
+	m.mode = modeNormal
+	m.isLogView = false
+	m.statusMsg = "" // Reset status
+	return nil, nil
+}
+
+// cmdLogLevel shows or sets a log's level: :loglevel <main|order|strategy> [level]
+func cmdLogLevel(m *model, args []string) (tea.Cmd, error) {
+	if len(args) == 1 {
+		level, err := m.engine.LogLevel(args[0])
+		if err != nil {
+			return nil, err
+		}
+		m.statusMsg = fmt.Sprintf("%s log level: %s", args[0], level)
+		return nil, nil
+	}
+	if len(args) != 2 {
+		return nil, errors.New("Usage: " + commandUsage("loglevel"))
+	}
+	level, err := logger.ParseLevel(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if err := m.engine.SetLogLevel(args[0], level); err != nil {
+		return nil, err
+	}
+	m.statusMsg = successStyle.Render(fmt.Sprintf("%s log level set to %s", args[0], level))
+	m.mainLogger.Printf("%s log level set to %s", args[0], level)
+	return nil, nil
+}
+
+// cmdAccounts lists the accounts on this login
+func cmdAccounts(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected || m.tm == nil {
+		return nil, errors.New("Not connected")
+	}
+	tm := m.tm
+	return func() tea.Msg {
+		accounts, err := tm.ListAccounts()
+		return accountsMsg{accounts: accounts, err: err}
+	}, nil
+}
+
+// cmdReport writes the session report to external/reports
+func cmdReport(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected || m.pt == nil || m.om == nil {
+		return nil, errors.New("Must be connected to generate a session report")
+	}
+	txtPath, _, err := m.writeSessionReport()
+	if err != nil {
+		m.mainLogger.Errorf("Session report failed: %v", err)
+		return nil, fmt.Errorf("Report failed: %w", err)
+	}
+	m.statusMsg = successStyle.Render("Session report written to " + txtPath)
+	m.mainLogger.Printf("Session report written to %s", txtPath)
+	return nil, nil
+}
+
+// cmdExport writes a log to a file: :export <main|orders|strat> [text|json]
+func cmdExport(m *model, args []string) (tea.Cmd, error) {
+	asJSON := false
+	if len(args) > 1 {
+		switch args[1] {
+		case "json":
+			asJSON = true
+		case "text", "txt":
+		default:
+			return nil, errors.New("Invalid export format. Use 'text' or 'json'")
+		}
+	}
+
+	var log *logger.Logger
+	var prefix, label string
+	switch args[0] {
+	case "main":
+		log, prefix, label = m.mainLogger, "main_log_", "Main log"
+	case "orders":
+		log, prefix, label = m.orderLogger, "orders_log_", "Order log"
+	case "strat":
+		log, prefix, label = m.strategyLogger, "strat_log_", "Strategy log"
+	default:
+		return nil, errors.New("Invalid export target. Use 'main', 'orders' or 'strat'")
+	}
+
+	filename, err := exportLog(log, prefix, asJSON)
+	if err != nil {
+		return nil, fmt.Errorf("Export failed: %w", err)
+	}
+	m.statusMsg = successStyle.Render(label + " exported to " + filename)
+	m.mainLogger.Printf("%s exported to %s", label, filename)
+	return nil, nil
+}
+
+// cmdHelp switches to the Commands page
+func cmdHelp(m *model, args []string) (tea.Cmd, error) {
+	m.activeTab = TabCommands
+	m.statusMsg = "Switched to Commands"
+	return nil, nil
+}
+
+// cmdReauth logs in to Tradovate again, off the UI loop
+func cmdReauth(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected || m.demoData {
+		return nil, errors.New("Must be connected to Tradovate to re-authenticate")
+	}
+	m.statusMsg = "Re-authenticating..."
+	engine := m.engine
+	return func() tea.Msg {
+		return reauthMsg{err: engine.Reauthenticate()}
+	}, nil
+}
+
+// cmdFind searches Tradovate contracts by name: :find <text>
+func cmdFind(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected {
+		return nil, errors.New("Must be connected to search contracts")
+	}
+	text := strings.ToUpper(args[0])
+	m.statusMsg = "Searching contracts for " + text + "..."
+	engine := m.engine
+	return func() tea.Msg {
+		matches, err := engine.ContractSearch(text, 0)
+		return findMsg{text: text, matches: matches, err: err}
+	}, nil
+}
+
+// cmdContract shows or pins the contract a root resolves to: :contract <root> [symbol|auto]
+func cmdContract(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected || m.om == nil || m.om.GetSymbolResolver() == nil {
+		return nil, errors.New("Must be connected to resolve contracts")
+	}
+	resolver := m.om.GetSymbolResolver()
+	root := strings.ToUpper(args[0])
+	if len(args) > 1 {
+		if strings.EqualFold(args[1], "auto") {
+			resolver.ClearOverride(root)
+			m.statusMsg = successStyle.Render(root + " will be resolved automatically")
+			m.strategyLogger.Infof("Contract override cleared for %s", root)
+			return nil, nil
+		}
+		if err := resolver.SetOverride(root, args[1]); err != nil {
+			return nil, err
+		}
+		m.statusMsg = successStyle.Render(fmt.Sprintf("%s pinned to %s", root, strings.ToUpper(args[1])))
+		m.strategyLogger.Infof("Contract override: %s -> %s", root, strings.ToUpper(args[1]))
+		return nil, nil
+	}
+	return func() tea.Msg {
+		contract, err := resolver.Resolve(root)
+		return contractMsg{root: root, contract: contract, err: err}
+	}, nil
+}
@@ -0,0 +1,237 @@
+package UI
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"tradovate-execution-engine/engine/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cmdBuy places a manual buy order: :buy <symbol> <quantity> [type] [tif]
+func cmdBuy(m *model, args []string) (tea.Cmd, error) {
+	return nil, m.placeOrder(models.SideBuy, args)
+}
+
+// cmdSell places a manual sell order: :sell <symbol> <quantity> [type] [tif]
+func cmdSell(m *model, args []string) (tea.Cmd, error) {
+	return nil, m.placeOrder(models.SideSell, args)
+}
+
+// placeOrder sends a :buy or :sell through the model's broker, tagged as manual
+func (m *model) placeOrder(side models.OrderSide, args []string) error {
+	name := strings.ToLower(string(side))
+	if !m.connected {
+		return errors.New("Must be connected to API to trade")
+	}
+	if m.engine.RiskState().Tripped {
+		return errors.New("Trading disabled: daily loss limit exceeded")
+	}
+	if m.tradingMode != ModeLive {
+		m.mainLogger.Errorf("Order rejected: Not in Live mode")
+		return errors.New("Cannot place orders in Visual mode. Switch to Live mode with :mode live")
+	}
+
+	symbol := args[0]
+	var qty int
+	if _, err := fmt.Sscanf(args[1], "%d", &qty); err != nil {
+		return errors.New("Invalid quantity format. Use a number")
+	}
+
+	opts, err := parseOrderOptions(args[2:])
+	if err != nil {
+		return fmt.Errorf("%w. Usage: %s", err, commandUsage(name))
+	}
+
+	if m.broker == nil {
+		m.mainLogger.Error("Order Manager not initialized")
+		return errors.New("Order Manager not initialized")
+	}
+
+	if err := m.engine.ValidateSymbol(symbol); err != nil {
+		m.mainLogger.Errorf("Order not sent: %v", err)
+		return fmt.Errorf("Order not sent: %w", err)
+	}
+	if err := checkOrderTicks(symbol, opts, m.broker.GetTickSize(symbol)); err != nil {
+		return fmt.Errorf("Order not sent: %w", err)
+	}
+
+	label := strings.ToUpper(name)
+	m.mainLogger.Printf("Submitting %s order for %d %s...", label, qty, symbol)
+
+	opts.Origin = models.OriginManual
+	order, err := m.broker.SubmitOrder(symbol, side, qty, opts)
+	if err != nil && order != nil && order.Status == models.StatusRejected {
+		m.mainLogger.Errorf("Order rejected: %s", order.RejectReason)
+		return errors.New("Order rejected: " + order.RejectReason)
+	}
+	if err != nil {
+		m.mainLogger.Errorf("Order failed: %v", err)
+		return fmt.Errorf("Order failed: %w", err)
+	}
+
+	m.statusMsg = successStyle.Render(fmt.Sprintf("%s order placed for %s (ID: %s)", label, symbol, order.ID))
+	m.mainLogger.Printf("%s order placed for %s (ID: %s)", label, symbol, order.ID)
+	m.orderLogger.Printf("%s %s - Price: %s, Qty: %d, ID: %s", label, symbol, orderPriceText(order), qty, order.ID)
+	return nil
+}
+
+// cmdCancel cancels one working order: :cancel <order id>
+func cmdCancel(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected || m.om == nil {
+		return nil, errors.New("Must be connected to API to cancel orders")
+	}
+	if m.tradingMode != ModeLive {
+		return nil, errors.New("Cannot cancel orders in Visual mode")
+	}
+	if len(args) != 1 {
+		return nil, errors.New("Usage: " + commandUsage("cancel"))
+	}
+	*m = m.cancelOrderByID(args[0])
+	return nil, nil
+}
+
+// cmdOCO rests or cancels an OCO pair: :oco <symbol> <quantity> <buy stop> <sell stop>
+// or :oco cancel <pair id>
+func cmdOCO(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected || m.om == nil {
+		return nil, errors.New("Must be connected to API to trade")
+	}
+	if m.tradingMode != ModeLive {
+		return nil, errors.New("Cannot place orders in Visual mode. Switch to Live mode with :mode live")
+	}
+
+	if len(args) == 2 && args[0] == "cancel" {
+		if err := m.om.CancelOCOPair(args[1]); err != nil {
+			m.orderLogger.Errorf("OCO CANCEL %s failed: %v", args[1], err)
+			return nil, fmt.Errorf("OCO cancel failed: %w", err)
+		}
+		m.statusMsg = successStyle.Render("Cancelled OCO pair " + args[1])
+		m.orderLogger.Printf("OCO CANCEL %s", args[1])
+		return nil, nil
+	}
+
+	if len(args) != 4 {
+		return nil, errors.New("Usage: " + commandUsage("oco"))
+	}
+	var qty int
+	var buyStop, sellStop float64
+	if _, err := fmt.Sscanf(args[1]+" "+args[2]+" "+args[3], "%d %f %f", &qty, &buyStop, &sellStop); err != nil {
+		return nil, errors.New("Invalid quantity or price. Usage: " + commandUsage("oco"))
+	}
+
+	pairID, err := m.om.SubmitOCOPair(args[0], qty, buyStop, sellStop)
+	if err != nil {
+		m.mainLogger.Errorf("OCO failed: %v", err)
+		return nil, fmt.Errorf("OCO failed: %w", err)
+	}
+	m.statusMsg = successStyle.Render(fmt.Sprintf("OCO pair placed for %s (ID: %s)", args[0], pairID))
+	m.orderLogger.Printf("OCO %s - Buy stop: %.2f, Sell stop: %.2f, Qty: %d, ID: %s", args[0], buyStop, sellStop, qty, pairID)
+	return nil, nil
+}
+
+// cmdFlatten previews what :flatten would cancel and close, then asks to confirm
+func cmdFlatten(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected {
+		return nil, errors.New("Must be connected to API to flatten positions")
+	}
+	if m.tradingMode != ModeLive {
+		m.mainLogger.Errorf("Flatten rejected: Not in Live mode")
+		return nil, errors.New("Cannot flatten in Visual mode")
+	}
+	*m = m.previewAction("flatten")
+	return nil, nil
+}
+
+// cmdFlattenNow flattens without the preview
+func cmdFlattenNow(m *model, args []string) (tea.Cmd, error) {
+	*m = m.runFlatten()
+	return nil, nil
+}
+
+// cmdKill previews the kill switch, then asks to confirm
+func cmdKill(m *model, args []string) (tea.Cmd, error) {
+	*m = m.previewAction("kill")
+	return nil, nil
+}
+
+// cmdKillNow throws the kill switch without the preview
+func cmdKillNow(m *model, args []string) (tea.Cmd, error) {
+	*m = m.runKillSwitch()
+	return nil, nil
+}
+
+// cmdArm accepts orders again after the kill switch or a breaker trip
+func cmdArm(m *model, args []string) (tea.Cmd, error) {
+	if err := m.engine.Arm(); err != nil {
+		return nil, fmt.Errorf("Arm failed: %w", err)
+	}
+	m.statusMsg = successStyle.Render("Trading re-armed. Strategies stay stopped until started")
+	return nil, nil
+}
+
+// cmdConfirmLive acknowledges trading on the live account
+func cmdConfirmLive(m *model, args []string) (tea.Cmd, error) {
+	if err := m.engine.ConfirmLive(); err != nil {
+		return nil, fmt.Errorf("Confirm failed: %w", err)
+	}
+	m.statusMsg = errorStyle.Render("Live trading confirmed - orders go to the LIVE account")
+	return nil, nil
+}
+
+// cmdOverride asks to trade through the news lockout in force
+func cmdOverride(m *model, args []string) (tea.Cmd, error) {
+	window, held := m.engine.NewsLockout()
+	if !held {
+		return nil, errors.New("No news lockout in force")
+	}
+	m.confirmAction = "override"
+	m.statusMsg = errorStyle.Render(fmt.Sprintf("Trade through the %s news lockout? y/n", window.Name))
+	return nil, nil
+}
+
+// cmdClose closes one position: :close <symbol>
+func cmdClose(m *model, args []string) (tea.Cmd, error) {
+	*m = m.closePosition(args[0])
+	return nil, nil
+}
+
+// cmdTrail trails a stop behind a position: :trail <symbol> <ticks> [min step ticks]
+// or :trail off <symbol>
+func cmdTrail(m *model, args []string) (tea.Cmd, error) {
+	if !m.connected || m.ts == nil {
+		return nil, errors.New("Must be connected to API to trail stops")
+	}
+	if m.tradingMode != ModeLive {
+		return nil, errors.New("Cannot trail stops in Visual mode")
+	}
+
+	if args[0] == "off" {
+		if err := m.ts.Stop(args[1]); err != nil {
+			return nil, fmt.Errorf("Trail stop failed: %w", err)
+		}
+		m.statusMsg = successStyle.Render("Trailing stop removed for " + args[1])
+		return nil, nil
+	}
+
+	symbol := args[0]
+	var ticks int
+	if _, err := fmt.Sscanf(args[1], "%d", &ticks); err != nil || ticks <= 0 {
+		return nil, errors.New("Invalid ticks. Use a positive number")
+	}
+	step := 1
+	if len(args) > 2 {
+		if _, err := fmt.Sscanf(args[2], "%d", &step); err != nil || step <= 0 {
+			return nil, errors.New("Invalid min step. Use a positive number")
+		}
+	}
+
+	if err := m.ts.Start(symbol, ticks, step); err != nil {
+		m.mainLogger.Errorf("Trail failed: %v", err)
+		return nil, fmt.Errorf("Trail failed: %w", err)
+	}
+	m.statusMsg = successStyle.Render(fmt.Sprintf("Trailing stop %d ticks behind %s", ticks, symbol))
+	m.mainLogger.Infof("Trailing stop started for %s (%d ticks)", symbol, ticks)
+	return nil, nil
+}
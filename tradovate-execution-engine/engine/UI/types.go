@@ -1,16 +1,20 @@
 package UI
 
 import (
-	"sync/atomic"
 	"time"
 	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
 	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/backtest"
+	"tradovate-execution-engine/engine/internal/contracts"
 	"tradovate-execution-engine/engine/internal/execution"
 	"tradovate-execution-engine/engine/internal/logger"
 	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/risk"
 	"tradovate-execution-engine/engine/internal/tradovate"
 
 	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 type Tab int
@@ -19,17 +23,14 @@ type mode int
 
 type tickMsg time.Time
 
-type StrategyStatus int32
-
-type StrategyRuntime struct {
-	status atomic.Int32
-}
-
 type PositionRow struct {
 	Symbol   string
 	Quantity int
 	AvgPrice float64
 	PnL      float64
+	NetPnL   float64 // PnL less the round trip fees of closing it
+	Mark     float64 // Price PnL is marked at
+	MarkType portfolio.MarkType
 }
 
 type OrderRow struct {
@@ -40,6 +41,7 @@ type OrderRow struct {
 	Price    float64
 	Status   string
 	Time     time.Time
+	External bool // Placed outside the engine
 }
 
 type Command struct {
@@ -49,64 +51,112 @@ type Command struct {
 	Category    string
 }
 
+// argRange is the fewest and most arguments a command accepts
+type argRange struct {
+	min, max int
+}
+
+// commandHandler runs a command on the model with the words typed after its
+// name. A returned error is shown in the status bar; a returned tea.Cmd runs
+// the slow part (a REST call, a backtest) off the UI loop.
+type commandHandler func(m *model, args []string) (tea.Cmd, error)
+
+// commandSpec is a command in the registry: how the Commands page lists it,
+// what it accepts and what runs it
+type commandSpec struct {
+	Command
+	aliases []string // Other names that run it, e.g. q for quit
+	hidden  bool     // Left off the Commands page and completion, e.g. flatten!
+	args    argRange
+	intArgs []int // Argument positions (1-based) that must be whole numbers
+	run     commandHandler
+}
+
 type PnLDataPoint struct {
 	Time time.Time
 	PnL  float64
 }
 
+// StrategyState is the Strategy tab's view of one engine strategy instance
 type StrategyState struct {
-	Name        string
-	Params      []execution.StrategyParam
-	Instance    execution.Strategy
-	Symbol      string
+	Instance    *app.StrategyInstance
+	Params      []execution.StrategyParam // Parameter definitions; values come from the instance
 	Description string
 
-	Runtime *StrategyRuntime
+	// Rolling GetMetrics samples per metric, one per tick while running
+	metricHistory map[string][]float64
 }
 
+// maxMetricSamples bounds each metric's history (about two minutes of ticks)
+const maxMetricSamples = 120
+
 // connMsg indicates connection success/failure
 type connMsg struct {
 	err error
 }
 
+// connMsgSuccess reports that the engine connected; managers are read from it
 type connMsgSuccess struct {
-	config            *config.Config
-	tokenManager      *auth.TokenManager
-	orderManager      *execution.OrderManager
-	mdClient          *tradovate.TradovateWebSocketClient
-	mdSubscriber      *tradovate.DataSubscriber
-	tradingClient     *tradovate.TradovateWebSocketClient
-	tradingSubscriber *tradovate.DataSubscriber
-	portfolioTracker  *portfolio.PortfolioTracker
+	config *config.Config
 }
 
-type editorFinishedMsg struct {
-	err        error
-	nextAction string // "connect" or "none"
+// accountsMsg carries the account list for :accounts
+type accountsMsg struct {
+	accounts []tradovate.APIAccount
+	err      error
+}
+
+// contractMsg carries the result of :contract <root>
+type contractMsg struct {
+	root     string
+	contract string
+	err      error
+}
+
+// findMsg carries the result of :find <text>
+type findMsg struct {
+	text    string
+	matches []contracts.ContractMatch
+	err     error
+}
+
+// reauthMsg carries the result of :reauth
+type reauthMsg struct {
+	err error
+}
+
+// backtestMsg carries the result of a :backtest run
+type backtestMsg struct {
+	report *backtest.Report
+	err    error
+}
+
+// shutdownDoneMsg reports that the shutdown sequence finished or timed out
+type shutdownDoneMsg struct {
+	timedOut bool
 }
 
-// Bar aggregator - collects quotes into minute bars
-type BarAggregator struct {
-	currentMinute string
-	open          float64
-	high          float64
-	low           float64
-	close         float64
-	firstTick     bool
+// pendingClose is a :close order waiting for its outcome
+type pendingClose struct {
+	symbol   string
+	filledAt time.Time // When the order was first seen filled, zero until then
 }
 
-type LastBar struct {
-	Timestamp string
-	Close     float64
+type editorFinishedMsg struct {
+	err        error
+	nextAction string // "connect" or "none"
 }
 
 type model struct {
 	activeTab            Tab
 	mode                 mode
 	tradingMode          TradingMode
+	demoData             bool // Connect to the synthetic market instead of Tradovate
 	commandInput         string
 	commandHistory       []string
 	historyIndex         int
+	completionBase       string // Input before the first Tab, while cycling completions
+	completionIndex      int
 	searchInput          string
 	statusMsg            string
 	width                int
@@ -116,22 +166,46 @@ type model struct {
 	logScrollOffset      int
 	orderLogScrollOffset int
 	stratLogScrollOffset int
+	logSelect            *logSelection // Visual selection in a log panel, nil when not selecting
+	truncateLogs         map[Tab]bool  // Tabs whose log panel truncates long messages instead of wrapping them
+	shuttingDown         bool
 
 	// Editor
 	configEditor textarea.Model
 	isLogView    bool
 	editorTitle  string
 
+	// Problems of the config's last failed validation, listed under the config editor
+	configProblems []config.FieldError
+
 	// Logger
 	mainLogger     *logger.Logger
 	orderLogger    *logger.Logger
 	strategyLogger *logger.Logger
 
 	// Data
-	positions  []PositionRow
-	orders     []OrderRow
-	commands   []Command
-	pnlHistory []PnLDataPoint
+	positions []PositionRow
+	orders    []OrderRow
+
+	selectedPosition int                     // Cursor row on the Positions tab
+	workingOrders    []OrderRow              // Order Mgmt table: pending, submitted and partially filled orders
+	selectedOrder    string                  // ID of the highlighted working order, kept across refreshes
+	confirmCancelID  string                  // Working order waiting for y/n to cancel
+	confirmAction    string                  // "flatten", "kill" or "override" waiting for y/n, its plan shown in the content area
+	confirmPlan      app.KillPlan            // What confirmAction would send when it was shown
+	pendingCloses    map[string]pendingClose // :close order ID -> its close, until the fill is confirmed
+	commands         []Command
+	pnlHistory       []PnLDataPoint
+
+	// Trading schedule state, updated each tick
+	inSession bool
+
+	// Daily loss limit state from the engine's risk supervisor, updated each tick
+	riskState risk.SupervisorState
+
+	// Position loss alerts still active, and the last one flashed, updated each tick
+	positionAlerts []portfolio.PositionAlert
+	alertSeq       int
 
 	// Connection status
 	connected        bool
@@ -139,22 +213,35 @@ type model struct {
 	unrealizedPnL    float64
 	realizedPnL      float64
 	dailyrealizedPnL float64
+	dailyFees        float64 // Of today's closed trades
+	sessionFees      float64 // Of trades closed this session
 
 	// Config
 	configPath    string
-	strategyName  string
 	currentSymbol string
 
 	// Strategy Management
 	availableStrategies []string
-	selectedStrategy    string
-	currentStrategy     *StrategyState
-	strategyParams      map[string]string
-
-	// Managers
-	tm *auth.TokenManager
-	om *execution.OrderManager
-	pt *portfolio.PortfolioTracker
+	strategyCatalog     []execution.StrategyInfo // Registered strategies with their default params
+	catalogOpen         bool                     // :strategies is shown in the content area
+	catalogScroll       int
+	findResults         []contracts.ContractMatch // Last :find, shown while findOpen
+	findText            string
+	findOpen            bool
+	findScroll          int
+	strategies          map[string]*StrategyState // Keyed by engine instance ID
+	selectedInstance    string                    // Instance shown on the Strategy tab
+
+	// Managers, owned by the engine and copied here on connect
+	engine *app.Engine
+	tm     *auth.TokenManager
+	om     *execution.OrderManager
+	pt     *portfolio.PortfolioTracker
+	ts     *execution.TrailingStopManager
+	broker execution.Broker // Where :buy and :sell send orders: om, or a fake in tests
+
+	// Where :set and :start save the shown instance for :strategy restore
+	strategyStatePath string
 
 	// Market Data & Auth
 	marketDataClient                 *tradovate.TradovateWebSocketClient
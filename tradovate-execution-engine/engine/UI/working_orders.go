@@ -0,0 +1,275 @@
+package UI
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/models"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxWorkingOrderRows is how many working orders the Order Mgmt table shows at once
+const maxWorkingOrderRows = 8
+
+// isWorkingStatus reports whether an order may still fill or be cancelled,
+// suspended bracket legs included
+func isWorkingStatus(status models.OrderStatus) bool {
+	switch status {
+	case models.StatusPending, models.StatusQueued, models.StatusSubmitted, models.StatusSuspended, models.StatusPartiallyFilled:
+		return true
+	}
+	return false
+}
+
+// refreshWorkingOrders rebuilds the working order table, oldest first. The
+// selected order stays selected while it works; when it leaves the table the
+// row that took its place is selected instead.
+func (m model) refreshWorkingOrders(orders []*models.Order) model {
+	prevIndex := m.selectedWorkingIndex()
+
+	m.om.Mu.RLock()
+	var rows []OrderRow
+	for _, o := range orders {
+		if !isWorkingStatus(o.Status) {
+			continue
+		}
+		price := o.Price
+		if o.Type == models.TypeStop {
+			price = o.StopPrice
+		}
+		rows = append(rows, OrderRow{
+			ID:       o.ID,
+			Symbol:   o.Symbol,
+			Side:     string(o.Side),
+			Quantity: o.Quantity,
+			Price:    price,
+			Status:   string(o.Status),
+			Time:     o.SubmittedAt,
+			External: o.IsExternal(),
+		})
+	}
+	m.om.Mu.RUnlock()
+
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].Time.Equal(rows[j].Time) {
+			return rows[i].Time.Before(rows[j].Time)
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	m.workingOrders = rows
+
+	if m.selectedWorkingIndex() < 0 {
+		m.selectedOrder = ""
+		if len(rows) > 0 {
+			m.selectedOrder = rows[min(max(prevIndex, 0), len(rows)-1)].ID
+		}
+	}
+
+	// The order finished while its cancel was being confirmed
+	if m.confirmCancelID != "" && m.workingOrderRow(m.confirmCancelID) == nil {
+		m.statusMsg = fmt.Sprintf("Order %s is no longer working", m.confirmCancelID)
+		m.confirmCancelID = ""
+	}
+	return m
+}
+
+// selectedWorkingIndex returns the table row of the selected order, or -1
+func (m model) selectedWorkingIndex() int {
+	for i, row := range m.workingOrders {
+		if row.ID == m.selectedOrder {
+			return i
+		}
+	}
+	return -1
+}
+
+// workingOrderRow returns the table row for an order ID, nil if it is not working
+func (m model) workingOrderRow(id string) *OrderRow {
+	for i := range m.workingOrders {
+		if m.workingOrders[i].ID == id {
+			return &m.workingOrders[i]
+		}
+	}
+	return nil
+}
+
+// moveOrderSelection moves the table cursor by step rows, stopping at either end
+func (m model) moveOrderSelection(step int) model {
+	if len(m.workingOrders) == 0 {
+		return m
+	}
+	index := min(max(m.selectedWorkingIndex()+step, 0), len(m.workingOrders)-1)
+	m.selectedOrder = m.workingOrders[index].ID
+	return m
+}
+
+// requestCancel asks to confirm cancelling the selected order. Visual mode is
+// read only, so nothing is asked there.
+func (m model) requestCancel() model {
+	if m.tradingMode != ModeLive {
+		m.statusMsg = errorStyle.Render("Cannot cancel orders in Visual mode")
+		return m
+	}
+	row := m.workingOrderRow(m.selectedOrder)
+	if row == nil {
+		m.statusMsg = "No working order selected"
+		return m
+	}
+	m.confirmCancelID = row.ID
+	m.statusMsg = errorStyle.Render(fmt.Sprintf("Cancel %s %d %s @ %.2f (ID: %s)? y/n",
+		row.Side, row.Quantity, row.Symbol, row.Price, row.ID))
+	return m
+}
+
+// handleCancelConfirm answers the cancel prompt: y cancels, any other key does not
+func (m model) handleCancelConfirm(key string) model {
+	id := m.confirmCancelID
+	m.confirmCancelID = ""
+	if key != "y" && key != "Y" {
+		m.statusMsg = "Cancel aborted"
+		return m
+	}
+	if !m.connected || m.om == nil || m.tradingMode != ModeLive {
+		m.statusMsg = errorStyle.Render("Cannot cancel orders in Visual mode or while disconnected")
+		return m
+	}
+
+	if err := m.om.CancelOrder(id); err != nil {
+		m.statusMsg = errorStyle.Render("Cancel failed: " + err.Error())
+		m.orderLogger.Errorf("CANCEL %s failed: %v", id, err)
+		return m
+	}
+	m.statusMsg = successStyle.Render("Cancelled order " + id)
+	m.orderLogger.Printf("CANCEL %s - cancelled from the Order Mgmt tab", id)
+	return m
+}
+
+// workingOrderIDs lists the IDs of working orders, as the table shows them
+func (m model) workingOrderIDs() []string {
+	ids := make([]string, 0, len(m.workingOrders))
+	for _, row := range m.workingOrders {
+		ids = append(ids, row.ID)
+	}
+	return ids
+}
+
+// cancelOrderByID cancels the working order with ID id for :cancel. The last
+// characters of an ID are enough when they match one order, so the ID can be
+// copied from the table, which shortens long ones.
+func (m model) cancelOrderByID(id string) model {
+	var matches []string
+	for _, row := range m.workingOrders {
+		if row.ID == id {
+			matches = []string{row.ID}
+			break
+		}
+		if strings.HasSuffix(row.ID, id) {
+			matches = append(matches, row.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		m.statusMsg = errorStyle.Render(fmt.Sprintf("No working order %s", id))
+		return m
+	case 1:
+	default:
+		m.statusMsg = errorStyle.Render(fmt.Sprintf("%s matches %d working orders: %s", id, len(matches), strings.Join(matches, ", ")))
+		return m
+	}
+
+	id = matches[0]
+	if err := m.om.CancelOrder(id); err != nil {
+		m.statusMsg = errorStyle.Render("Cancel failed: " + err.Error())
+		m.orderLogger.Errorf("CANCEL %s failed: %v", id, err)
+		return m
+	}
+	m.statusMsg = successStyle.Render("Cancelled order " + id)
+	m.orderLogger.Printf("CANCEL %s - cancelled with :cancel", id)
+	return m
+}
+
+// renderWorkingOrders draws the working order table for a panel of the given
+// width, scrolled to keep the selected row in view
+func (m model) renderWorkingOrders(width int) string {
+	var b strings.Builder
+	// Suspended legs are listed with the working orders but counted apart
+	suspended := 0
+	for _, row := range m.workingOrders {
+		if row.Status == string(models.StatusSuspended) {
+			suspended++
+		}
+	}
+	count := fmt.Sprintf("%d", len(m.workingOrders)-suspended)
+	if suspended > 0 {
+		count += fmt.Sprintf(", %d suspended", suspended)
+	}
+	b.WriteString(fmt.Sprintf("═══ WORKING ORDERS (%s) ═══\n", count))
+
+	hint := "j/k select, c cancel"
+	if m.tradingMode != ModeLive {
+		hint = "j/k select, cancel disabled in Visual mode"
+	}
+	b.WriteString(disabledStyle.Render(hint) + "\n")
+
+	if len(m.workingOrders) == 0 {
+		b.WriteString(disabledStyle.Render("No working orders") + "\n")
+		return lipgloss.NewStyle().Width(width).Render(b.String())
+	}
+
+	b.WriteString(fmt.Sprintf("  %-12s %-8s %-4s %4s %10s %-16s %6s\n", "ID", "Symbol", "Side", "Qty", "Price", "Status", "Age"))
+
+	start := 0
+	if selected := m.selectedWorkingIndex(); selected >= maxWorkingOrderRows {
+		start = selected - maxWorkingOrderRows + 1
+	}
+	end := min(start+maxWorkingOrderRows, len(m.workingOrders))
+
+	now := time.Now()
+	for _, row := range m.workingOrders[start:end] {
+		cursor := "  "
+		if row.ID == m.selectedOrder {
+			cursor = "> "
+		}
+		id := row.ID
+		if len(id) > 12 {
+			id = id[len(id)-12:]
+			// Keep the tag that marks orders placed outside the engine
+			if row.External {
+				id = "EXT-" + id[4:]
+			}
+		}
+		price := "MKT"
+		if row.Price > 0 {
+			price = fmt.Sprintf("%.2f", row.Price)
+		}
+		line := fmt.Sprintf("%s%-12s %-8s %-4s %4d %10s %s %6s",
+			cursor, id, row.Symbol, row.Side, row.Quantity, price,
+			orderStatusStyle(models.OrderStatus(row.Status)).Render(fmt.Sprintf("%-16s", row.Status)),
+			orderAge(row.Time, now))
+		if row.ID == m.confirmCancelID {
+			line = errorStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	if end < len(m.workingOrders) || start > 0 {
+		b.WriteString(disabledStyle.Render(fmt.Sprintf("  rows %d-%d of %d", start+1, end, len(m.workingOrders))) + "\n")
+	}
+	return lipgloss.NewStyle().Width(width).Render(b.String())
+}
+
+// orderAge formats how long an order has been working, e.g. 45s, 3m12s, 2h05m
+func orderAge(submitted, now time.Time) string {
+	if submitted.IsZero() {
+		return "-"
+	}
+	age := now.Sub(submitted)
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(age.Minutes()), int(age.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(age.Hours()), int(age.Minutes())%60)
+}
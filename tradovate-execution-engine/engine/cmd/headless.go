@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// runHeadless connects (to the synthetic market when demoData is set), starts
+// the configured strategy and runs until SIGINT or SIGTERM, printing all three
+// logs to stdout. SIGUSR1 engages the kill switch where the platform has it.
+// It returns the process exit code.
+func runHeadless(demoData bool) int {
+	mainLog := logger.NewLogger(500, logger.LevelInfo)
+	orderLog := logger.NewLogger(500, logger.LevelInfo)
+	strategyLog := logger.NewLogger(500, logger.LevelInfo)
+
+	stopPrinting := printLogs(map[string]*logger.Logger{
+		"MAIN ": mainLog,
+		"ORDER": orderLog,
+		"STRAT": strategyLog,
+	})
+	defer stopPrinting()
+
+	cfg, err := config.LoadOrCreateConfig(mainLog)
+	if err != nil {
+		mainLog.Errorf("Config load error: %v", err)
+		return 1
+	}
+	if cfg.Headless.Strategy == "" {
+		mainLog.Error("No strategy configured: set headless.strategy in config")
+		return 1
+	}
+	if !demoData && cfg.Tradovate.Environment.IsLive() && !cfg.Headless.SkipLiveConfirm {
+		mainLog.Error("Live environment: set headless.skipLiveConfirm in config to trade live without :confirm-live")
+		return 1
+	}
+
+	engine := app.NewEngine(mainLog, orderLog, strategyLog)
+	engine.AddEventHandler(func(ev app.Event) {
+		switch ev.Kind {
+		case app.EventSessionCutoff, app.EventDailyLossLimit, app.EventKillSwitch, app.EventLossStreak, app.EventOrderThrottle,
+			app.EventMaintenance, app.EventDataStale, app.EventNewsLockout, app.EventPositionDrift, app.EventTokenRenewal:
+			mainLog.Warn(ev.Message)
+		case app.EventStrategyStatus:
+			// A failed strategy stays stopped; it is never restarted automatically
+			if ev.Status == app.StrategyError {
+				mainLog.Errorf("Strategy %s failed and stays stopped until the engine restarts: %s", ev.StrategyID, ev.Message)
+			}
+		}
+	})
+
+	connect := engine.Connect
+	if demoData {
+		connect = engine.ConnectDemo
+	}
+	if err := connect(cfg); err != nil {
+		var invalid *config.ValidationError
+		if errors.As(err, &invalid) {
+			mainLog.Errorf("Fix %s in %s and restart", strings.Join(invalid.Keys(), ", "), config.GetConfigPath())
+			return 1
+		}
+		mainLog.Errorf("Connection error: %v", err)
+		return 1
+	}
+	if engine.LiveConfirmPending() {
+		mainLog.Warn("Live trading confirmed by headless.skipLiveConfirm")
+		if err := engine.ConfirmLive(); err != nil {
+			mainLog.Errorf("Live confirmation failed: %v", err)
+		}
+	}
+
+	inst, err := engine.AddStrategy(cfg.Headless.Strategy, cfg.Headless.Params)
+	if err != nil {
+		mainLog.Errorf("Failed to load strategy: %v", err)
+		engine.Disconnect()
+		return 1
+	}
+	if err := engine.StartStrategy(inst.ID); err != nil {
+		mainLog.Errorf("Cannot start strategy: %v", err)
+		engine.Disconnect()
+		return 1
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	// The kill switch signal; trading stays locked until the process restarts
+	kill := make(chan os.Signal, 1)
+	if len(killSignals) > 0 {
+		signal.Notify(kill, killSignals...)
+		defer signal.Stop(kill)
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			engine.CheckLossStreak()
+			engine.CheckSchedule(now, true)
+			engine.CheckNews(now, true)
+			engine.CheckPositionDrift(now)
+			engine.CheckOrderQueue(now)
+			if engine.CheckMaintenance(now) {
+				// Failures are logged and retried on a later tick
+				_ = engine.Reconnect()
+			}
+
+		case <-kill:
+			mainLog.Error(">>> KILL SIGNAL RECEIVED, ENGAGING KILL SWITCH <<<")
+			if err := engine.KillSwitch(); err != nil {
+				mainLog.Errorf("Kill switch: %v", err)
+			}
+
+		case sig := <-signals:
+			mainLog.Infof(">>> %s RECEIVED, SHUTTING DOWN... <<<", sig)
+			if err := engine.Shutdown(true); err != nil {
+				mainLog.Warn("Shutdown timed out, exiting anyway")
+				return 1
+			}
+			mainLog.Info(">>> SHUTDOWN COMPLETE <<<")
+			return 0
+		}
+	}
+}
+
+// printLogs tails each logger to stdout with its tag until the returned func is called
+func printLogs(logs map[string]*logger.Logger) (stop func()) {
+	var wg sync.WaitGroup
+	var closers []func()
+
+	for tag, l := range logs {
+		ch := make(chan logger.LogEntry, 256)
+		unsubscribe := l.Subscribe(ch)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range ch {
+				fmt.Printf("%s [%s] %-5s %s\n", e.Timestamp.Format("15:04:05"), tag, e.Level, e.Message)
+			}
+		}()
+		closers = append(closers, func() {
+			// The logger no longer sends once unsubscribed, so the channel can be closed
+			unsubscribe()
+			close(ch)
+		})
+	}
+
+	return func() {
+		for _, c := range closers {
+			c()
+		}
+		wg.Wait()
+	}
+}
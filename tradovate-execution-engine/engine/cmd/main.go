@@ -1,8 +1,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"tradovate-execution-engine/engine/UI"
+	"tradovate-execution-engine/engine/internal/audit"
 	_ "tradovate-execution-engine/engine/strategies"
 	"tradovate-execution-engine/engine/tests"
 
@@ -10,12 +13,39 @@ import (
 )
 
 func main() {
+	headless := flag.Bool("headless", false, "run the strategy in config.headless without the TUI")
+	demoData := flag.Bool("demo-data", false, "trade a synthetic market offline instead of connecting to Tradovate")
+	verifyAudit := flag.String("verify-audit", "", "check the hash chain of an audit file and exit")
+	flag.Parse()
+
+	if *verifyAudit != "" {
+		os.Exit(runVerifyAudit(*verifyAudit))
+	}
+
+	if *headless {
+		os.Exit(runHeadless(*demoData))
+	}
 
 	tests.RunAllTests()
 
-	p := tea.NewProgram(UI.InitialModel(), tea.WithAltScreen())
+	model := UI.InitialModel()
+	if *demoData {
+		model = model.WithDemoData()
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v\n", err)
 	}
 
 }
+
+// runVerifyAudit checks an audit file's chain and returns the exit code
+func runVerifyAudit(path string) int {
+	n, err := audit.Verify(path)
+	if err != nil {
+		fmt.Printf("%s: %v (%d entries intact before it)\n", path, err, n)
+		return 1
+	}
+	fmt.Printf("%s: %d entries, chain intact\n", path, n)
+	return 0
+}
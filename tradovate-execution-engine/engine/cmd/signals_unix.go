@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// killSignals engage the kill switch in headless mode
+var killSignals = []os.Signal{syscall.SIGUSR1}
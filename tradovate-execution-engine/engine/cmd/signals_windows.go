@@ -0,0 +1,9 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// killSignals engage the kill switch in headless mode. Windows has no user
+// signals, so there is none.
+var killSignals []os.Signal
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"tradovate-execution-engine/engine/internal/contracts"
 	"tradovate-execution-engine/engine/internal/logger"
 )
 
@@ -19,28 +22,55 @@ const (
 	mdDemoWSUrl = "wss://md-demo.tradovateapi.com/v1/websocket"
 )
 
-// GetHTTPBaseURL returns the HTTP API base URL for the given environment
-func GetHTTPBaseURL(environment string) string {
-	if environment == "live" {
-		return liveUrl
+// DefaultMetricsAddr is where the metrics listener binds when metrics.addr is empty
+const DefaultMetricsAddr = "127.0.0.1:9400"
+
+// DefaultAdminAddr is where the admin API binds when admin.addr is empty
+const DefaultAdminAddr = "127.0.0.1:9401"
+
+// ParseEnvironment returns the environment named s, which must be exactly
+// "live" or "demo"
+func ParseEnvironment(s string) (Environment, error) {
+	switch env := Environment(s); env {
+	case EnvironmentLive, EnvironmentDemo:
+		return env, nil
 	}
-	return demoUrl
+	return "", fmt.Errorf("environment must be \"live\" or \"demo\", got %q", s)
 }
 
-// GetWSBaseURL returns the Market Data WebSocket API base URL for the given environment
-func GetMDWSBaseURL(environment string) string {
-	if environment == "live" {
-		return mdLiveWSUrl
-	}
-	return mdDemoWSUrl
+// IsLive reports whether env is the live environment
+func (env Environment) IsLive() bool {
+	return env == EnvironmentLive
+}
+
+// GetHTTPBaseURL returns the HTTP API base URL for the given environment, or ""
+// for one ParseEnvironment refuses
+func GetHTTPBaseURL(environment Environment) string {
+	return environmentURL(environment, liveUrl, demoUrl)
+}
+
+// GetMDWSBaseURL returns the Market Data WebSocket API base URL for the given
+// environment, or "" for one ParseEnvironment refuses
+func GetMDWSBaseURL(environment Environment) string {
+	return environmentURL(environment, mdLiveWSUrl, mdDemoWSUrl)
+}
+
+// GetWSBaseURL returns the WebSocket API base URL for the given environment, or
+// "" for one ParseEnvironment refuses
+func GetWSBaseURL(environment Environment) string {
+	return environmentURL(environment, baseLiveWSUrl, baseDemoWSUrl)
 }
 
-// GetWSBaseURL returns the WebSocket API base URL for the given environment
-func GetWSBaseURL(environment string) string {
-	if environment == "live" {
-		return baseLiveWSUrl
+// environmentURL picks live or demo. Anything else gets no URL, so a bad
+// environment fails to connect instead of quietly reaching demo.
+func environmentURL(environment Environment, live, demo string) string {
+	switch environment {
+	case EnvironmentLive:
+		return live
+	case EnvironmentDemo:
+		return demo
 	}
-	return baseDemoWSUrl
+	return ""
 }
 
 // GetConfigPath returns the absolute path to the config file
@@ -69,15 +99,255 @@ func LoadOrCreateConfig(logger *logger.Logger) (*Config, error) {
 		return nil, fmt.Errorf("Failed to read config file: %w", err)
 	}
 
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Config loaded successfully from %s", configPath)
+	return config, nil
+}
+
+// ParseConfig decodes and validates a config file's contents
+func ParseConfig(data []byte) (*Config, error) {
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("Failed to parse config file: %w", err)
 	}
-
-	logger.Infof("Config loaded successfully from %s", configPath)
+	if err := config.Risk.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid risk config: %w", err)
+	}
+	if err := config.Fees.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid fee config: %w", err)
+	}
+	if err := config.Slippage.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid slippage config: %w", err)
+	}
+	if err := config.Logging.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid logging config: %w", err)
+	}
 	return &config, nil
 }
 
+// Validate checks what connecting to Tradovate needs before any request is
+// sent: every credential set and not left at its template placeholder, the
+// environment live or demo, and risk limits that allow trading. It returns a
+// *ValidationError listing every problem, nil when there are none.
+func (c *Config) Validate() error {
+	var problems []FieldError
+	t := c.Tradovate
+	for _, f := range []struct{ key, value string }{
+		{"appId", t.AppID}, {"appVersion", t.AppVersion}, {"chl", t.Chl}, {"cid", t.Cid},
+		{"deviceId", t.DeviceID}, {"username", t.Username}, {"password", t.Password}, {"sec", t.Sec},
+	} {
+		value := strings.TrimSpace(f.value)
+		switch {
+		case value == "":
+			problems = append(problems, FieldError{"tradovate." + f.key, "is empty"})
+		case isPlaceholder(value):
+			problems = append(problems, FieldError{"tradovate." + f.key, fmt.Sprintf("is still the template placeholder %q", value)})
+		}
+	}
+	if _, err := ParseEnvironment(string(t.Environment)); err != nil {
+		problems = append(problems, FieldError{"tradovate.environment", fmt.Sprintf("must be \"live\" or \"demo\", got %q", t.Environment)})
+	}
+	if c.Risk.MaxContracts < 1 {
+		problems = append(problems, FieldError{"risk.maxContracts", fmt.Sprintf("must be at least 1, got %d", c.Risk.MaxContracts)})
+	}
+	if c.Risk.DailyLossLimit <= 0 {
+		problems = append(problems, FieldError{"risk.dailyLossLimit", fmt.Sprintf("must be above 0, got %.2f", c.Risk.DailyLossLimit)})
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// isPlaceholder reports whether value is one of CreateDefaultConfig's
+// "your_..._here" template values
+func isPlaceholder(value string) bool {
+	value = strings.ToLower(value)
+	return strings.HasPrefix(value, "your_") && strings.HasSuffix(value, "_here")
+}
+
+func (e FieldError) Error() string {
+	return e.Key + " " + e.Message
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.Error()
+	}
+	return fmt.Sprintf("%d config problem(s): %s", len(e.Problems), strings.Join(msgs, "; "))
+}
+
+// Keys returns the key of each problem, in order
+func (e *ValidationError) Keys() []string {
+	keys := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		keys[i] = p.Key
+	}
+	return keys
+}
+
+// Validate checks that every level given is one the logger knows
+func (l LoggingConfig) Validate() error {
+	for target, level := range l.Levels() {
+		if level == "" {
+			continue
+		}
+		if _, err := logger.ParseLevel(level); err != nil {
+			return fmt.Errorf("%s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// Levels returns the configured level of each log target, keyed by target name
+func (l LoggingConfig) Levels() map[string]string {
+	return map[string]string{"main": l.Main, "order": l.Order, "strategy": l.Strategy}
+}
+
+// Validate checks the risk section: keys of the per-symbol overrides must be
+// product roots, each root listed once whatever its case, and limits must not
+// be negative
+func (r RiskConfig) Validate() error {
+	if r.MinBalanceWarning < 0 {
+		return fmt.Errorf("minBalanceWarning must not be negative")
+	}
+	if r.MaxConsecutiveLosses < 0 || r.LossStreakCooldownMinutes < 0 {
+		return fmt.Errorf("maxConsecutiveLosses and lossStreakCooldownMinutes must not be negative")
+	}
+	if r.PositionLossWarning < 0 || r.PositionLossCritical < 0 {
+		return fmt.Errorf("positionLossWarning and positionLossCritical are dollar amounts of loss and must not be negative")
+	}
+	if r.PositionLossWarning > 0 && r.PositionLossCritical > 0 && r.PositionLossCritical <= r.PositionLossWarning {
+		return fmt.Errorf("positionLossCritical ($%.2f) must be above positionLossWarning ($%.2f)", r.PositionLossCritical, r.PositionLossWarning)
+	}
+	seen := make(map[string]string, len(r.Symbols))
+	for key, limits := range r.Symbols {
+		root := strings.ToUpper(strings.TrimSpace(key))
+		switch {
+		case root == "":
+			return fmt.Errorf("symbols: empty product root")
+		case contracts.IsContract(root):
+			return fmt.Errorf("symbols: %q is a contract, use its product root", key)
+		case seen[root] != "":
+			return fmt.Errorf("symbols: %q and %q are the same product", seen[root], key)
+		case limits.MaxContracts < 0 || limits.MaxOrderQty < 0 || limits.DailyLossLimit < 0:
+			return fmt.Errorf("symbols.%s: limits must not be negative", key)
+		case limits.MaxContracts > 0 && limits.MaxOrderQty > limits.MaxContracts:
+			return fmt.Errorf("symbols.%s: maxOrderQty (%d) is above maxContracts (%d)", key, limits.MaxOrderQty, limits.MaxContracts)
+		}
+		seen[root] = key
+	}
+	return nil
+}
+
+// Validate checks the fee section: fees must not be negative and per-product
+// keys must be product roots listed once whatever their case
+func (f FeeConfig) Validate() error {
+	if f.Commission < 0 || f.ExchangeFee < 0 {
+		return fmt.Errorf("fees must not be negative")
+	}
+	seen := make(map[string]string, len(f.Symbols))
+	for key, fees := range f.Symbols {
+		root := strings.ToUpper(strings.TrimSpace(key))
+		switch {
+		case root == "":
+			return fmt.Errorf("symbols: empty product root")
+		case contracts.IsContract(root):
+			return fmt.Errorf("symbols: %q is a contract, use its product root", key)
+		case seen[root] != "":
+			return fmt.Errorf("symbols: %q and %q are the same product", seen[root], key)
+		case fees.Commission < 0 || fees.ExchangeFee < 0:
+			return fmt.Errorf("symbols.%s: fees must not be negative", key)
+		}
+		seen[root] = key
+	}
+	return nil
+}
+
+// PerContract returns the commission plus exchange fees for one contract on
+// one side of a trade in symbol, matched to Symbols by product root
+func (f FeeConfig) PerContract(symbol string) float64 {
+	if len(f.Symbols) > 0 && symbol != "" {
+		root := contracts.Root(symbol)
+		for key, fees := range f.Symbols {
+			if strings.EqualFold(strings.TrimSpace(key), root) {
+				return fees.Commission + fees.ExchangeFee
+			}
+		}
+	}
+	return f.Commission + f.ExchangeFee
+}
+
+// RoundTrip returns the fees for opening and closing qty contracts of symbol
+func (f FeeConfig) RoundTrip(symbol string, qty int) float64 {
+	if qty < 0 {
+		qty = -qty
+	}
+	return 2 * f.PerContract(symbol) * float64(qty)
+}
+
+// Validate checks the slippage models: a known model and no negative ticks,
+// with Symbols keyed by distinct product roots
+func (s SlippageConfig) Validate() error {
+	if err := validSlippage(s.Model, s.Ticks); err != nil {
+		return err
+	}
+	seen := make(map[string]string, len(s.Symbols))
+	for key, product := range s.Symbols {
+		root := strings.ToUpper(strings.TrimSpace(key))
+		switch {
+		case root == "":
+			return fmt.Errorf("symbols: empty product root")
+		case contracts.IsContract(root):
+			return fmt.Errorf("symbols: %q is a contract, use its product root", key)
+		case seen[root] != "":
+			return fmt.Errorf("symbols: %q and %q are the same product", seen[root], key)
+		}
+		if err := validSlippage(product.Model, product.Ticks); err != nil {
+			return fmt.Errorf("symbols.%s: %w", key, err)
+		}
+		seen[root] = key
+	}
+	return nil
+}
+
+// validSlippage checks one slippage model
+func validSlippage(model string, ticks int) error {
+	switch model {
+	case "", SlippageNone, SlippageFixed, SlippageSpread, SlippageRandom:
+	default:
+		return fmt.Errorf("unknown slippage model %q (use none, fixed, spread or random)", model)
+	}
+	if ticks < 0 {
+		return fmt.Errorf("slippage ticks must not be negative")
+	}
+	return nil
+}
+
+// For returns the slippage model of symbol: its product's own, else the
+// default. An empty model is none.
+func (s SlippageConfig) For(symbol string) ProductSlippage {
+	model := ProductSlippage{Model: s.Model, Ticks: s.Ticks, LimitAtPrice: s.LimitAtPrice}
+	if len(s.Symbols) > 0 && symbol != "" {
+		root := contracts.Root(symbol)
+		for key, product := range s.Symbols {
+			if strings.EqualFold(strings.TrimSpace(key), root) {
+				model = product
+				break
+			}
+		}
+	}
+	if model.Model == "" {
+		model.Model = SlippageNone
+	}
+	return model
+}
+
 // GetProjectRoot searches for go.mod to identify the project root and returns its absolute path
 func GetProjectRoot() string {
 	dir, err := os.Getwd()
@@ -139,13 +409,158 @@ func CreateDefaultConfig(path string) error {
 			Password:    "your_password_here",
 			Sec:         "your_security_token_here",
 			Enc:         true,
+
+			MaxRequestRetries:   3,
+			StaleTimeoutSeconds: 10,
+			RolloverDays:        8,
 		},
 		Risk: RiskConfig{
 			MaxContracts:     1,
 			DailyLossLimit:   500.0,
 			EnableRiskChecks: true,
+			MaxWorkingOrders: 10,
+			MaxOrderQty:      5,
+
+			PositionLossWarning:  100,
+			PositionLossCritical: 200,
+
+			MaxStrategyOrdersPerMinute: 4,
+			MinOrderIntervalSeconds:    10,
+			MaxThrottleViolations:      3,
+
+			TradingDayRoll:     "17:00",
+			TradingDayTimezone: "America/Chicago",
+
+			CancelOrdersOnExit:     true,
+			FlattenOnExit:          false,
+			ShutdownTimeoutSeconds: 10,
+		},
+		Fees: FeeConfig{
+			Commission:  0.25,
+			ExchangeFee: 0.35,
+		},
+		Slippage: SlippageConfig{
+			Model: SlippageSpread,
+			Ticks: 1,
+		},
+		Schedule: ScheduleConfig{
+			Enabled:   false,
+			Start:     "09:30",
+			End:       "16:00",
+			Timezone:  "America/New_York",
+			FlattenAt: "15:55",
+		},
+		Maintenance: MaintenanceConfig{
+			Windows: map[string][]string{
+				"demo": {"16:00-17:00"},
+				"live": {"16:00-17:00"},
+			},
+			Timezone:          "America/Chicago",
+			StaleQuoteSeconds: 120,
+		},
+		News: NewsConfig{
+			Enabled:     false,
+			LeadMinutes: 5,
+			Timezone:    "America/New_York",
+			Events:      []NewsEvent{},
+		},
+		Headless: HeadlessConfig{
+			Strategy: "ma_crossover",
+			Params:   map[string]string{"symbol": "MES"},
+		},
+		Metrics: MetricsConfig{
+			Enabled: false,
+			Addr:    DefaultMetricsAddr,
+		},
+		Admin: AdminConfig{
+			Enabled: false,
+			Addr:    DefaultAdminAddr,
+		},
+		Audit: AuditConfig{
+			Enabled: true,
+		},
+		Logging: LoggingConfig{
+			Main:     "info",
+			Order:    "info",
+			Strategy: "info",
 		},
 	}
 
 	return SaveConfig(path, defaultConfig)
 }
+
+// RiskChanges returns a human-readable "field: old -> new" line for every Risk
+// setting that differs between the two configs
+func RiskChanges(oldCfg, newCfg *Config) []string {
+	return diffFields(reflect.ValueOf(oldCfg.Risk), reflect.ValueOf(newCfg.Risk), false)
+}
+
+// FeeChanges returns a "field: old -> new" line for every Fees setting that
+// differs between the two configs
+func FeeChanges(oldCfg, newCfg *Config) []string {
+	return diffFields(reflect.ValueOf(oldCfg.Fees), reflect.ValueOf(newCfg.Fees), false)
+}
+
+// SlippageChanges returns a "field: old -> new" line for every Slippage setting
+// that differs between the two configs
+func SlippageChanges(oldCfg, newCfg *Config) []string {
+	return diffFields(reflect.ValueOf(oldCfg.Slippage), reflect.ValueOf(newCfg.Slippage), false)
+}
+
+// ScheduleChanges returns a "field: old -> new" line for every Schedule setting
+// that differs between the two configs
+func ScheduleChanges(oldCfg, newCfg *Config) []string {
+	return diffFields(reflect.ValueOf(oldCfg.Schedule), reflect.ValueOf(newCfg.Schedule), false)
+}
+
+// MaintenanceChanges returns a "field: old -> new" line for every Maintenance
+// setting that differs between the two configs
+func MaintenanceChanges(oldCfg, newCfg *Config) []string {
+	return diffFields(reflect.ValueOf(oldCfg.Maintenance), reflect.ValueOf(newCfg.Maintenance), false)
+}
+
+// NewsChanges returns a "field: old -> new" line for every News setting that
+// differs between the two configs
+func NewsChanges(oldCfg, newCfg *Config) []string {
+	return diffFields(reflect.ValueOf(oldCfg.News), reflect.ValueOf(newCfg.News), false)
+}
+
+// LoggingChanges returns a "field: old -> new" line for every Logging setting
+// that differs between the two configs
+func LoggingChanges(oldCfg, newCfg *Config) []string {
+	return diffFields(reflect.ValueOf(oldCfg.Logging), reflect.ValueOf(newCfg.Logging), false)
+}
+
+// ReconnectRequiredChanges returns the Tradovate settings that differ between the
+// two configs. These cannot be hot-applied and only take effect after a reconnect.
+// Values are omitted since most of these fields are credentials.
+func ReconnectRequiredChanges(oldCfg, newCfg *Config) []string {
+	return diffFields(reflect.ValueOf(oldCfg.Tradovate), reflect.ValueOf(newCfg.Tradovate), true)
+}
+
+// diffFields compares two structs of the same type field by field, naming each
+// changed field by its json tag
+func diffFields(oldVal, newVal reflect.Value, namesOnly bool) []string {
+	var changes []string
+	t := oldVal.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		o := oldVal.Field(i).Interface()
+		n := newVal.Field(i).Interface()
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" && tag != "-" {
+			name, _, _ = strings.Cut(tag, ",")
+		}
+
+		if namesOnly {
+			changes = append(changes, name)
+		} else {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, o, n))
+		}
+	}
+	return changes
+}
@@ -6,22 +6,69 @@ package config
 
 // Config holds all configuration settings
 type Config struct {
-	Tradovate TradovateConfig `json:"tradovate"`
-	Risk      RiskConfig      `json:"risk"`
+	Tradovate   TradovateConfig   `json:"tradovate"`
+	Risk        RiskConfig        `json:"risk"`
+	Fees        FeeConfig         `json:"fees"`
+	Slippage    SlippageConfig    `json:"slippage"`
+	Schedule    ScheduleConfig    `json:"schedule"`
+	Maintenance MaintenanceConfig `json:"maintenance"`
+	News        NewsConfig        `json:"news"`
+	Headless    HeadlessConfig    `json:"headless"`
+	Metrics     MetricsConfig     `json:"metrics"`
+	Admin       AdminConfig       `json:"admin"`
+	Recording   RecordingConfig   `json:"recording"`
+	Audit       AuditConfig       `json:"audit"`
+	Logging     LoggingConfig     `json:"logging"`
+}
+
+// Environment is the Tradovate environment a config connects to
+type Environment string
+
+const (
+	EnvironmentDemo Environment = "demo"
+	EnvironmentLive Environment = "live"
+)
+
+// FieldError is a problem with one config key, named by its JSON path, e.g.
+// "tradovate.password"
+type FieldError struct {
+	Key     string
+	Message string
+}
+
+// ValidationError lists every problem Validate found in a config
+type ValidationError struct {
+	Problems []FieldError
 }
 
 // TradovateConfig holds Tradovate-specific credentials
 type TradovateConfig struct {
-	AppID       string `json:"appId"`
-	AppVersion  string `json:"appVersion"`
-	Chl         string `json:"chl"`
-	Cid         string `json:"cid"`
-	DeviceID    string `json:"deviceId"`
-	Environment string `json:"environment"`
-	Username    string `json:"username"`
-	Password    string `json:"password"`
-	Sec         string `json:"sec"`
-	Enc         bool   `json:"enc"`
+	AppID       string      `json:"appId"`
+	AppVersion  string      `json:"appVersion"`
+	Chl         string      `json:"chl"`
+	Cid         string      `json:"cid"`
+	DeviceID    string      `json:"deviceId"`
+	Environment Environment `json:"environment"`
+	Username    string      `json:"username"`
+	Password    string      `json:"password"`
+	Sec         string      `json:"sec"`
+	Enc         bool        `json:"enc"`
+
+	// Account to trade when the login has several; set either (AccountID wins). Empty uses the first account.
+	AccountSpec string `json:"accountSpec"`
+	AccountID   int    `json:"accountId"`
+
+	// RolloverDays is how many days before expiry a root symbol (e.g. "MES") resolves to the next contract
+	RolloverDays int `json:"rolloverDays"`
+
+	// StaleTimeoutSeconds is how long a WebSocket may go without any frame before it is treated as disconnected
+	StaleTimeoutSeconds int `json:"staleTimeoutSeconds"`
+
+	// MaxRequestRetries is how many times a rate limited REST request is retried
+	MaxRequestRetries int `json:"maxRequestRetries"`
+
+	// CacheTokens persists the session to external/auth so a restart renews it instead of logging in again
+	CacheTokens bool `json:"cacheTokens"`
 }
 
 // RiskConfig holds risk management and order configuration
@@ -29,4 +76,199 @@ type RiskConfig struct {
 	MaxContracts     int     `json:"maxContracts"`
 	DailyLossLimit   float64 `json:"dailyLossLimit"`
 	EnableRiskChecks bool    `json:"enableRiskChecks"`
+	MaxWorkingOrders int     `json:"maxWorkingOrders"` // Resting orders allowed at once (0 = unlimited)
+	MaxOrderQty      int     `json:"maxOrderQty"`      // Largest single order (0 = unlimited)
+
+	// Check the daily loss limits against PnL after fees instead of gross PnL
+	DailyLossNet bool `json:"dailyLossNet"`
+
+	// Logged as a warning when the account's cash balance falls below it (0 = off)
+	MinBalanceWarning float64 `json:"minBalanceWarning"`
+
+	// Alert when one open position's unrealized loss reaches these many dollars
+	// (0 = off), once per crossing. PositionAlertBell also rings the terminal bell.
+	PositionLossWarning  float64 `json:"positionLossWarning"`
+	PositionLossCritical float64 `json:"positionLossCritical"`
+	PositionAlertBell    bool    `json:"positionAlertBell"`
+
+	// Value open positions at the bid/offer midpoint instead of the side they
+	// would close at (the bid for longs, the offer for shorts)
+	MarkToMidpoint bool `json:"markToMidpoint"`
+
+	// Loss streak circuit breaker: after MaxConsecutiveLosses losing trades in a
+	// row (0 = off) strategies are stopped and their orders refused for
+	// LossStreakCooldownMinutes (0 = until :arm). Manual orders still go through.
+	MaxConsecutiveLosses      int  `json:"maxConsecutiveLosses"`
+	LossStreakCooldownMinutes int  `json:"lossStreakCooldownMinutes"`
+	LossStreakFlatten         bool `json:"lossStreakFlatten"` // Also flatten every position when it trips
+
+	// Strategy order throttle, per strategy instance: at most
+	// MaxStrategyOrdersPerMinute orders in any rolling minute, and
+	// MinOrderIntervalSeconds between two of them. After MaxThrottleViolations
+	// refused orders the strategy is disabled until it is started again. Zero
+	// uses the default, a negative value turns that limit off.
+	MaxStrategyOrdersPerMinute int `json:"maxStrategyOrdersPerMinute"`
+	MinOrderIntervalSeconds    int `json:"minOrderIntervalSeconds"`
+	MaxThrottleViolations      int `json:"maxThrottleViolations"`
+
+	// Symbols overrides the limits above for a product root (e.g. "CL"). Fields
+	// left at zero use the global value.
+	Symbols map[string]SymbolRiskLimits `json:"symbols,omitempty"`
+
+	// Position reconciliation: every PositionCheckSeconds (0 = 30, negative =
+	// off) and after each connect, running strategies' positions are checked
+	// against the broker's. A strategy that drifted takes the broker's position
+	// when AutoSyncPositions is set, and is otherwise paused until :resync.
+	PositionCheckSeconds int  `json:"positionCheckSeconds"`
+	AutoSyncPositions    bool `json:"autoSyncPositions"`
+
+	// A strategy order that cannot reach Tradovate (no connection, a 5xx or a
+	// rate limit penalty) is queued and sent once it can, unless its signal is
+	// older than OrderQueueSeconds by then (0 = 30, negative = never queue)
+	OrderQueueSeconds int `json:"orderQueueSeconds"`
+
+	// Trade date boundary for the daily loss limit; empty uses 17:00 America/Chicago
+	TradingDayRoll     string `json:"tradingDayRoll"`     // "HH:MM"
+	TradingDayTimezone string `json:"tradingDayTimezone"` // IANA name
+
+	// Shutdown behaviour
+	CancelOrdersOnExit     bool `json:"cancelOrdersOnExit"`
+	FlattenOnExit          bool `json:"flattenOnExit"`
+	ShutdownTimeoutSeconds int  `json:"shutdownTimeoutSeconds"`
+}
+
+// SymbolRiskLimits are the risk limits for one product root. DailyLossLimit
+// applies to that product's PnL and is checked alongside the account-wide one.
+type SymbolRiskLimits struct {
+	MaxContracts   int     `json:"maxContracts,omitempty"`
+	MaxOrderQty    int     `json:"maxOrderQty,omitempty"`
+	DailyLossLimit float64 `json:"dailyLossLimit,omitempty"`
+}
+
+// FeeConfig is the cost of trading per contract per side, so a round trip pays
+// it twice. A product listed in Symbols uses its own fees instead of these.
+type FeeConfig struct {
+	Commission  float64 `json:"commission"`  // Broker commission
+	ExchangeFee float64 `json:"exchangeFee"` // Exchange, clearing and NFA fees
+
+	Symbols map[string]ProductFees `json:"symbols,omitempty"` // Keyed by product root, e.g. "MES"
+}
+
+// ProductFees are the per contract, per side fees of one product root
+type ProductFees struct {
+	Commission  float64 `json:"commission"`
+	ExchangeFee float64 `json:"exchangeFee"`
+}
+
+// Slippage models for simulated fills
+const (
+	SlippageNone   = "none"   // Market orders fill at the last trade
+	SlippageFixed  = "fixed"  // Ticks worse than the last trade
+	SlippageSpread = "spread" // Buys at the offer, sells at the bid; Ticks worse than the last trade without a quote
+	SlippageRandom = "random" // Between 0 and Ticks worse than the last trade, drawn evenly
+)
+
+// SlippageConfig is how simulated fills, in the demo and in backtests, are moved
+// against the order. A product listed in Symbols uses its own model instead.
+type SlippageConfig struct {
+	Model        string `json:"model"`        // none (default), fixed, spread or random
+	Ticks        int    `json:"ticks"`        // Fixed and random: ticks, or the most ticks, a market fill is moved
+	LimitAtPrice bool   `json:"limitAtPrice"` // A limit order fills when the market trades at its price, not only through it
+
+	Symbols map[string]ProductSlippage `json:"symbols,omitempty"` // Keyed by product root, e.g. "MES"
+}
+
+// ProductSlippage is the slippage model of one product root
+type ProductSlippage struct {
+	Model        string `json:"model"`
+	Ticks        int    `json:"ticks"`
+	LimitAtPrice bool   `json:"limitAtPrice"`
+}
+
+// ScheduleConfig restricts trading to a daily session window
+type ScheduleConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Start     string `json:"start"`     // "HH:MM" in Timezone
+	End       string `json:"end"`       // "HH:MM"; earlier than Start for overnight sessions
+	Timezone  string `json:"timezone"`  // IANA name, e.g. "America/New_York"
+	FlattenAt string `json:"flattenAt"` // Optional "HH:MM" to flatten and stop the strategy
+}
+
+// MaintenanceConfig sets the daily windows Tradovate takes each environment
+// down in, and how long quotes may stop before a strategy is paused
+type MaintenanceConfig struct {
+	Windows  map[string][]string `json:"windows"`  // Environment ("demo", "live") -> daily "HH:MM-HH:MM" windows
+	Timezone string              `json:"timezone"` // IANA name; empty is America/Chicago
+
+	// StaleQuoteSeconds without a quote outside a window pauses a strategy until
+	// quotes resume (0 = 120, negative = off)
+	StaleQuoteSeconds int `json:"staleQuoteSeconds"`
+}
+
+// NewsConfig flattens and pauses strategies around scheduled economic
+// releases, such as CPI or FOMC
+type NewsConfig struct {
+	Enabled      bool        `json:"enabled"`
+	LeadMinutes  int         `json:"leadMinutes"`  // Flatten and pause this many minutes before each event starts
+	Timezone     string      `json:"timezone"`     // IANA name the event times are in; empty is America/New_York
+	Events       []NewsEvent `json:"events"`       // Windows to stay flat through
+	CalendarFile string      `json:"calendarFile"` // Optional JSON list of more events, relative to the config directory
+}
+
+// NewsEvent is one window to stay flat through
+type NewsEvent struct {
+	Name  string `json:"name"`  // e.g. "CPI"
+	Start string `json:"start"` // "YYYY-MM-DD HH:MM" in NewsConfig.Timezone
+	End   string `json:"end"`   // Same format, after Start
+}
+
+// HeadlessConfig selects the strategy run by --headless
+type HeadlessConfig struct {
+	Strategy string            `json:"strategy"`         // Registered strategy name, e.g. "ma_crossover"
+	Params   map[string]string `json:"params,omitempty"` // Parameter overrides applied before start
+
+	// SkipLiveConfirm acknowledges live trading up front, since headless mode has
+	// no :confirm-live. Headless refuses to connect to live without it.
+	SkipLiveConfirm bool `json:"skipLiveConfirm"`
+}
+
+// MetricsConfig exposes engine health and trading stats for Prometheus
+type MetricsConfig struct {
+	Enabled bool   `json:"enabled"`
+	Addr    string `json:"addr"` // Listen address, e.g. "127.0.0.1:9400" or ":9400" for every interface
+}
+
+// AdminConfig serves read-only engine state as JSON for dashboards and scripts
+type AdminConfig struct {
+	Enabled bool   `json:"enabled"`
+	Addr    string `json:"addr"` // Listen address; empty uses 127.0.0.1:9401
+
+	// Allow an address other than loopback. The API shows orders, positions and
+	// P&L without authentication, so only set this behind a firewall.
+	AllowRemote bool `json:"allowRemote"`
+}
+
+// RecordingConfig saves every WebSocket frame so an incident can be replayed
+type RecordingConfig struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"` // Empty uses external/recordings
+
+	// Metrics writes each running strategy's metrics to external/metrics on every
+	// bar from the start; :record on/off toggles it
+	Metrics bool `json:"metrics"`
+}
+
+// AuditConfig keeps a tamper-evident record of orders, flattens, the kill switch
+// and config reloads
+type AuditConfig struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"` // Empty uses external/audit
+}
+
+// LoggingConfig sets the lowest level each log keeps: "debug", "info", "warn"
+// or "error". Empty keeps info.
+type LoggingConfig struct {
+	Main     string `json:"main"`
+	Order    string `json:"order"`
+	Strategy string `json:"strategy"`
 }
@@ -0,0 +1,81 @@
+package indicators
+
+import "sync"
+
+// MaxMin represents the highest and lowest of the last period prices using a
+// circular ring buffer, e.g. the upper and lower lines of a Donchian channel
+type MaxMin struct {
+	mu     sync.RWMutex
+	period int
+
+	// Circular buffer for input prices
+	prices     []float64
+	priceIdx   int
+	priceCount int
+
+	// Highest and lowest of the buffer, zero until it holds a full period
+	high float64
+	low  float64
+}
+
+// NewMaxMin creates a new rolling max/min over period prices
+func NewMaxMin(period int) *MaxMin {
+	return &MaxMin{
+		period: period,
+		prices: make([]float64, period),
+	}
+}
+
+// Update adds a new price and returns the highest and lowest of the last
+// period prices, both zero until there is a full period
+func (m *MaxMin) Update(price float64) (high, low float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prices[m.priceIdx] = price
+	m.priceIdx = (m.priceIdx + 1) % m.period
+	if m.priceCount < m.period {
+		m.priceCount++
+	}
+	if m.priceCount < m.period {
+		return 0, 0
+	}
+
+	// A scan of the window; periods are bar counts, so this stays cheap
+	m.high, m.low = m.prices[0], m.prices[0]
+	for _, p := range m.prices[1:] {
+		m.high = max(m.high, p)
+		m.low = min(m.low, p)
+	}
+	return m.high, m.low
+}
+
+// High returns the highest of the last period prices, zero until full
+func (m *MaxMin) High() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.high
+}
+
+// Low returns the lowest of the last period prices, zero until full
+func (m *MaxMin) Low() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.low
+}
+
+// IsFull reports whether a full period of prices has been seen
+func (m *MaxMin) IsFull() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.priceCount == m.period
+}
+
+// Reset clears the buffer
+func (m *MaxMin) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.priceIdx = 0
+	m.priceCount = 0
+	m.high = 0
+	m.low = 0
+}
@@ -109,6 +109,21 @@ func (s *SMA) Update(price float64) float64 {
 	return smaValue
 }
 
+// Preview returns the SMA as it would be if price were the next input, without
+// storing it. Used on each tick for the bar still forming. Zero until there
+// would be a full period.
+func (s *SMA) Preview(price float64) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sum := s.runningSum + price
+	if s.priceCount == s.period {
+		sum -= s.prices[s.priceIdx]
+	} else if s.priceCount+1 < s.period {
+		return 0
+	}
+	return sum / float64(s.period)
+}
+
 // CurrentValue returns the most recent SMA value
 func (s *SMA) CurrentValue() float64 {
 	s.mu.RLock()
@@ -0,0 +1,339 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// adminServer is the HTTP listener of the admin API
+type adminServer struct {
+	srv  *http.Server
+	addr string
+	done chan struct{}
+}
+
+// AdminOrder is an order as /orders shows it
+type AdminOrder struct {
+	ID           string    `json:"id"`
+	ExternalID   string    `json:"externalId,omitempty"`
+	Symbol       string    `json:"symbol"`
+	Side         string    `json:"side"`
+	Type         string    `json:"type"`
+	Quantity     int       `json:"quantity"`
+	FilledQty    int       `json:"filledQty"`
+	AvgFillPrice float64   `json:"avgFillPrice,omitempty"`
+	Price        float64   `json:"price,omitempty"`
+	StopPrice    float64   `json:"stopPrice,omitempty"`
+	TimeInForce  string    `json:"timeInForce,omitempty"`
+	Status       string    `json:"status"`
+	Origin       string    `json:"origin"`
+	RejectReason string    `json:"rejectReason,omitempty"`
+	SubmittedAt  time.Time `json:"submittedAt"`
+}
+
+// AdminPosition is an open position as /positions shows it
+type AdminPosition struct {
+	Symbol        string             `json:"symbol"`
+	NetPos        int                `json:"netPos"`
+	AvgPrice      float64            `json:"avgPrice"`
+	MarkPrice     float64            `json:"markPrice"`
+	Mark          portfolio.MarkType `json:"mark,omitempty"`
+	UnrealizedPnL float64            `json:"unrealizedPnL"`
+}
+
+// AdminPnL is /pnl: today's P&L in dollars
+type AdminPnL struct {
+	Connected          bool    `json:"connected"`
+	Total              float64 `json:"total"` // Unrealized plus realized
+	Realized           float64 `json:"realized"`
+	Unrealized         float64 `json:"unrealized"`
+	SessionRealized    float64 `json:"sessionRealized"`
+	SessionStart       string  `json:"sessionStart,omitempty"`
+	KillSwitchEngaged  bool    `json:"killSwitchEngaged"`
+	DailyLossLimit     float64 `json:"dailyLossLimit,omitempty"`
+	RemainingLossLimit float64 `json:"remainingLossLimit,omitempty"` // Dollars of loss left before the limit
+}
+
+// AdminStrategy is a strategy instance as /strategy shows it
+type AdminStrategy struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Symbol  string            `json:"symbol"`
+	Status  string            `json:"status"`
+	Ready   bool              `json:"ready"`
+	Stale   bool              `json:"stale"`
+	Failure string            `json:"failure,omitempty"`
+	Params  map[string]string `json:"params"`
+}
+
+// AdminConnection is one WebSocket as /health shows it
+type AdminConnection struct {
+	Connected   bool      `json:"connected"`
+	Stale       bool      `json:"stale"`
+	LastMessage time.Time `json:"lastMessage,omitempty"`
+}
+
+// AdminHealth is /health
+type AdminHealth struct {
+	Connected          bool             `json:"connected"`
+	Demo               bool             `json:"demo"`
+	InMaintenance      bool             `json:"inMaintenance"`
+	MarketData         *AdminConnection `json:"marketData,omitempty"` // Nil on demo data
+	Trading            *AdminConnection `json:"trading,omitempty"`
+	TokenExpiresInSecs float64          `json:"tokenExpiresInSeconds"`
+}
+
+// startAdmin opens the admin API if it is enabled and not already running.
+// Addresses other than loopback are refused unless AllowRemote is set. A
+// listener that cannot start is logged and left off; trading goes on.
+func (e *Engine) startAdmin(cfg config.AdminConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.adminServer != nil {
+		return
+	}
+
+	addr := cfg.Addr
+	if addr == "" {
+		addr = config.DefaultAdminAddr
+	}
+	if !cfg.AllowRemote && !isLoopback(addr) {
+		e.mainLog.Errorf("Admin API disabled: %s is not a loopback address (set admin.allowRemote to serve it)", addr)
+		return
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		e.mainLog.Errorf("Admin API disabled: %v", err)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders", e.adminHandler(func() interface{} { return e.adminOrders() }))
+	mux.HandleFunc("/positions", e.adminHandler(func() interface{} { return e.adminPositions() }))
+	mux.HandleFunc("/pnl", e.adminHandler(func() interface{} { return e.adminPnL() }))
+	mux.HandleFunc("/strategy", e.adminHandler(func() interface{} { return e.adminStrategies() }))
+	mux.HandleFunc("/health", e.adminHandler(func() interface{} { return e.adminHealth() }))
+
+	s := &adminServer{
+		srv:  &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+		addr: ln.Addr().String(),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.mainLog.Errorf("Admin API stopped: %v", err)
+		}
+	}()
+	e.adminServer = s
+	e.mainLog.Infof("Admin API available at http://%s (read-only)", s.addr)
+}
+
+// stopAdmin closes the admin API, if it is running
+func (e *Engine) stopAdmin() {
+	e.mu.Lock()
+	s := e.adminServer
+	e.adminServer = nil
+	e.mu.Unlock()
+	if s == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), metricsCloseTimeout)
+	defer cancel()
+	if err := s.srv.Shutdown(ctx); err != nil {
+		e.mainLog.Warnf("Admin API: %v", err)
+	}
+	<-s.done
+	e.mainLog.Info("Admin API closed")
+}
+
+// AdminAddr returns the address the admin API is bound to, or "" when it is off
+func (e *Engine) AdminAddr() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.adminServer == nil {
+		return ""
+	}
+	return e.adminServer.addr
+}
+
+// isLoopback reports whether addr's host only listens on this machine
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// adminHandler serves what get returns as JSON. The API is read-only, so
+// anything but GET is refused.
+func (e *Engine) adminHandler(get func() interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "read-only API", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(get()); err != nil {
+			e.mainLog.Debugf("Admin API %s: %v", r.URL.Path, err)
+		}
+	}
+}
+
+// adminOrders lists every order of the connection, oldest first
+func (e *Engine) adminOrders() []AdminOrder {
+	orders := []AdminOrder{}
+	om := e.OrderManager()
+	if om == nil {
+		return orders
+	}
+
+	all := om.GetAllOrders()
+	om.Mu.RLock()
+	for _, o := range all {
+		orders = append(orders, AdminOrder{
+			ID:           o.ID,
+			ExternalID:   o.ExternalID,
+			Symbol:       o.Symbol,
+			Side:         string(o.Side),
+			Type:         string(o.Type),
+			Quantity:     o.Quantity,
+			FilledQty:    o.FilledQty(),
+			AvgFillPrice: o.AvgFillPrice(),
+			Price:        o.Price,
+			StopPrice:    o.StopPrice,
+			TimeInForce:  string(o.TimeInForce),
+			Status:       string(o.Status),
+			Origin:       originOrManual(o),
+			RejectReason: o.RejectReason,
+			SubmittedAt:  o.SubmittedAt,
+		})
+	}
+	om.Mu.RUnlock()
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		if !orders[i].SubmittedAt.Equal(orders[j].SubmittedAt) {
+			return orders[i].SubmittedAt.Before(orders[j].SubmittedAt)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders
+}
+
+// originOrManual is the order's origin, manual when it was not recorded
+func originOrManual(o *models.Order) string {
+	if o.Origin == "" {
+		return models.OriginManual
+	}
+	return o.Origin
+}
+
+// adminPositions lists the open positions by symbol
+func (e *Engine) adminPositions() []AdminPosition {
+	positions := []AdminPosition{}
+	pt := e.Portfolio()
+	if pt == nil {
+		return positions
+	}
+	for _, entry := range pt.GetPLSummary() {
+		if entry.NetPos == 0 {
+			continue
+		}
+		positions = append(positions, AdminPosition{
+			Symbol:        entry.Name,
+			NetPos:        entry.NetPos,
+			AvgPrice:      entry.BuyPrice,
+			MarkPrice:     entry.LastPrice,
+			Mark:          entry.Mark,
+			UnrealizedPnL: entry.PL,
+		})
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Symbol < positions[j].Symbol })
+	return positions
+}
+
+// adminPnL is today's P&L of the connection, zero when disconnected
+func (e *Engine) adminPnL() AdminPnL {
+	pnl := AdminPnL{Connected: e.IsConnected(), KillSwitchEngaged: e.KillSwitchEngaged()}
+	pt := e.Portfolio()
+	if pt == nil {
+		return pnl
+	}
+	pnl.Unrealized = pt.GetTotalPL()
+	pnl.Realized = pt.GetRealizedPnL()
+	pnl.Total = pnl.Unrealized + pnl.Realized
+	pnl.SessionRealized = pt.GetSessionRealizedPnL()
+	if start := e.SessionStart(); !start.IsZero() {
+		pnl.SessionStart = start.UTC().Format(time.RFC3339)
+	}
+	if cfg := e.Config(); cfg != nil && cfg.Risk.DailyLossLimit > 0 {
+		pnl.DailyLossLimit = cfg.Risk.DailyLossLimit
+		pnl.RemainingLossLimit = max(cfg.Risk.DailyLossLimit+pnl.Total, 0)
+	}
+	return pnl
+}
+
+// adminStrategies lists the strategy instances in the order they were added
+func (e *Engine) adminStrategies() []AdminStrategy {
+	strategies := []AdminStrategy{}
+	for _, inst := range e.Strategies() {
+		params := inst.Params()
+		symbol := inst.Symbol()
+		if symbol == "" {
+			symbol = params["symbol"] // Not started yet
+		}
+		strategies = append(strategies, AdminStrategy{
+			ID:      inst.ID,
+			Name:    inst.Name,
+			Symbol:  symbol,
+			Status:  inst.Runtime.Status().String(),
+			Ready:   inst.Runtime.IsReady(),
+			Stale:   inst.Runtime.IsStale(),
+			Failure: inst.Runtime.Failure(),
+			Params:  params,
+		})
+	}
+	return strategies
+}
+
+// adminHealth reports the connections and the access token
+func (e *Engine) adminHealth() AdminHealth {
+	e.mu.RLock()
+	demo := e.closeDemo != nil
+	e.mu.RUnlock()
+	health := AdminHealth{Connected: e.IsConnected(), Demo: demo, InMaintenance: e.InMaintenance()}
+	health.MarketData = adminConnection(e.MarketDataClient())
+	health.Trading = adminConnection(e.TradingClient())
+	if tm := e.TokenManager(); tm != nil {
+		health.TokenExpiresInSecs = tm.ExpiresIn().Seconds()
+	}
+	return health
+}
+
+// adminConnection describes a WebSocket, nil without one
+func adminConnection(c *tradovate.TradovateWebSocketClient) *AdminConnection {
+	if c == nil {
+		return nil
+	}
+	return &AdminConnection{Connected: c.IsConnected(), Stale: c.IsStale(), LastMessage: c.LastMessageAt()}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/audit"
+)
+
+// startAudit opens the audit trail if it is enabled and not already open. A
+// trail that cannot be opened is logged and left off; trading goes on.
+func (e *Engine) startAudit(cfg config.AuditConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.auditTrail != nil {
+		return
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		dir = audit.Dir()
+	}
+	trail, err := audit.NewTrail(dir, audit.DefaultBuffer)
+	if err != nil {
+		e.mainLog.Errorf("Audit trail disabled: %v", err)
+		return
+	}
+	e.auditTrail = trail
+	e.mainLog.Infof("Audit trail: %s", trail.Path())
+}
+
+// stopAudit writes what is left of the audit trail and closes it
+func (e *Engine) stopAudit() {
+	e.mu.Lock()
+	trail := e.auditTrail
+	e.auditTrail = nil
+	e.mu.Unlock()
+	if trail == nil {
+		return
+	}
+
+	if err := trail.Close(); err != nil {
+		e.mainLog.Errorf("Audit trail incomplete: %v", err)
+	}
+	if dropped := trail.Dropped(); dropped > 0 {
+		e.mainLog.Warnf("Audit trail closed: %d entries written, %d dropped", trail.Written(), dropped)
+		return
+	}
+	e.mainLog.Infof("Audit trail closed: %d entries written", trail.Written())
+}
+
+// AuditTrail returns the open audit trail, nil when auditing is off
+func (e *Engine) AuditTrail() *audit.Trail {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.auditTrail
+}
+
+// configChanges lists every hot-applied setting that differs between the two
+// configs as "section.field: old -> new", and the Tradovate settings by name
+// only since most of them are credentials
+func configChanges(oldCfg, newCfg *config.Config) []string {
+	sections := []struct {
+		name string
+		diff func(oldCfg, newCfg *config.Config) []string
+	}{
+		{"risk", config.RiskChanges},
+		{"fees", config.FeeChanges},
+		{"slippage", config.SlippageChanges},
+		{"schedule", config.ScheduleChanges},
+		{"maintenance", config.MaintenanceChanges},
+		{"news", config.NewsChanges},
+		{"logging", config.LoggingChanges},
+		{"tradovate", config.ReconnectRequiredChanges},
+	}
+	var changes []string
+	for _, section := range sections {
+		for _, change := range section.diff(oldCfg, newCfg) {
+			changes = append(changes, section.name+"."+change)
+		}
+	}
+	return changes
+}
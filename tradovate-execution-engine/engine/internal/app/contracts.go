@@ -0,0 +1,51 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"tradovate-execution-engine/engine/internal/contracts"
+)
+
+// ContractSearch returns up to limit Tradovate contracts whose names start
+// with text, or contracts.DefaultSearchLimit for a limit of 0
+func (e *Engine) ContractSearch(text string, limit int) ([]contracts.ContractMatch, error) {
+	e.mu.RLock()
+	catalog, connected := e.catalog, e.connected
+	e.mu.RUnlock()
+	if !connected {
+		return nil, errors.New("not connected")
+	}
+	if catalog == nil {
+		return nil, errors.New("contract search needs a Tradovate connection, not demo data")
+	}
+	return catalog.Search(text, limit)
+}
+
+// ValidateSymbol checks that Tradovate knows symbol, a contract like MESH6 or a
+// product root like MES. A symbol it does not know gives an error wrapping
+// contracts.ErrUnknownSymbol. A lookup that fails lets the symbol through,
+// since the order or subscription then reports its own error; so does every
+// symbol on demo data.
+func (e *Engine) ValidateSymbol(symbol string) error {
+	e.mu.RLock()
+	catalog, resolver := e.catalog, e.resolver
+	e.mu.RUnlock()
+	if catalog == nil || resolver == nil {
+		return nil
+	}
+
+	var err error
+	if contracts.IsContract(symbol) {
+		err = catalog.Validate(symbol)
+	} else {
+		_, err = resolver.Resolve(symbol)
+	}
+	if errors.Is(err, contracts.ErrUnknownSymbol) {
+		return fmt.Errorf("%w - try :find %s", err, contracts.Root(symbol))
+	}
+	if err != nil {
+		e.mainLog.Warnf("Could not check symbol %s: %v", symbol, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/marketdata/mock"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/schedule"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// The demo account
+const (
+	demoUserID    = 1
+	demoAccountID = 1
+	demoBalance   = 50000.0
+)
+
+// ConnectDemo connects to a synthetic market instead of Tradovate, so the UI
+// and strategies can be run offline without credentials. Quotes and charts
+// come from a mock.Feed, market orders fill at its last trade through the
+// simulator, and each fill reaches the portfolio from a mock.Account the way
+// Tradovate would report it. Other order types are rejected.
+func (e *Engine) ConnectDemo(cfg *config.Config) error {
+	return e.connectDemo(cfg, mock.DefaultConfig())
+}
+
+// connectDemo is ConnectDemo on a market built from mcfg
+func (e *Engine) connectDemo(cfg *config.Config, mcfg mock.Config) error {
+	e.startMetrics(cfg.Metrics)
+	e.startAdmin(cfg.Admin)
+	e.startAudit(cfg.Audit)
+	if err := e.ApplyLogging(cfg.Logging); err != nil {
+		e.mainLog.Errorf("Log levels not applied: %v", err)
+	}
+	sessionStart := time.Now().UTC()
+	e.mainLog.Warn("DEMO DATA - prices are synthetic and no orders reach Tradovate")
+
+	market := mock.NewMarket(mcfg)
+	feed := mock.NewFeed(market)
+	account := mock.NewAccount(market, demoUserID, demoAccountID, demoBalance)
+
+	sim := execution.NewSimulatedExecutor()
+	// Slippage is counted in the contract's ticks
+	market.OnInstrument(func(inst mock.Instrument) {
+		sim.SetTickSize(inst.Symbol, inst.TickSize)
+	})
+	om := execution.NewSimulatedOrderManager(sim, cfg, e.orderLog)
+	om.SetAuditTrail(e.AuditTrail())
+	if e.KillSwitchEngaged() {
+		om.EngageKillSwitch()
+		e.orderLog.Warn("KILL SWITCH - still engaged, orders refused until :arm")
+	}
+	sched, err := schedule.NewTradingSchedule(cfg.Schedule)
+	if err != nil {
+		return fmt.Errorf("schedule error: %w", err)
+	}
+	om.SetSchedule(sched)
+
+	mdSubscriber := tradovate.NewDataSubscriptionManager(feed)
+	mdSubscriber.SetLogger(e.strategyLog)
+	tradingSubscriber := tradovate.NewDataSubscriptionManager(account)
+	tradingSubscriber.SetLogger(e.mainLog)
+	feed.SetMessageHandler(mdSubscriber.HandleEvent)
+	feed.SetResponseHandler(mdSubscriber.HandleResponse)
+	feed.SetRequestErrorHandler(mdSubscriber.HandleRequestError)
+	account.SetMessageHandler(tradingSubscriber.HandleEvent)
+	mdSubscriber.Connect()
+	tradingSubscriber.Connect()
+
+	// Orders fill from the synthetic book and last trade, less the configured slippage
+	mdSubscriber.AddQuoteHandler(func(quote marketdata.Quote) {
+		symbol, ok := market.Symbol(quote.ContractID)
+		if !ok {
+			return
+		}
+		if bid, ask, hasBook := quote.BestBidOffer(); hasBook {
+			sim.SetQuote(symbol, bid.Price, ask.Price)
+		}
+		if trade, hasTrade := quote.LastTrade(); hasTrade {
+			sim.SetMarket(symbol, trade.Price, quote.Timestamp)
+		}
+	})
+
+	tracker := portfolio.NewPortfolioTracker(tradingSubscriber, mdSubscriber, demoUserID, demoAccountID, e.mainLog)
+	tracker.SetSessionAnchor(e.sessionAnchor(demoAccountID, sessionStart))
+	tradingSubscriber.OnFillUpdate = tracker.RecordFill
+	tracker.SetBalanceWarning(cfg.Risk.MinBalanceWarning)
+	tracker.SetPositionLossAlerts(cfg.Risk.PositionLossWarning, cfg.Risk.PositionLossCritical)
+	tracker.SetFees(cfg.Fees)
+	tracker.SetMarkToMidpoint(cfg.Risk.MarkToMidpoint)
+	if err := tracker.Start("demo"); err != nil {
+		feed.Close()
+		account.Close()
+		return fmt.Errorf("Failed to start PortfolioTracker: %w", err)
+	}
+	om.SetPortfolioTracker(tracker)
+
+	// Each simulated fill is reported back as Tradovate would, under an order ID
+	// that ties the trade to the order's origin
+	om.AddOrderListener(func(order models.Order) {
+		if order.Status != models.StatusFilled {
+			return
+		}
+		for _, fill := range order.Fills {
+			orderID := account.Fill(order.Symbol, string(order.Side), fill.Quantity, fill.Price, fill.Timestamp)
+			om.AssignExternalID(order.ID, strconv.Itoa(orderID))
+		}
+	})
+
+	trailingStops := execution.NewTrailingStopManager(om, tracker, mdSubscriber, e.orderLog)
+
+	riskSupervisor := e.startRiskSupervisor(om)
+	e.watchOrderThrottle(om)
+
+	e.mu.Lock()
+	e.cfg = cfg
+	e.tm = nil
+	e.catalog = nil
+	e.om = om
+	e.riskSupervisor = riskSupervisor
+	e.mdSubscriber = mdSubscriber
+	e.tradingSubscriber = tradingSubscriber
+	e.pt = tracker
+	e.ts = trailingStops
+	e.maintenance = nil // The synthetic feed has no maintenance windows
+	e.reconnectDue = time.Time{}
+	e.resume = nil
+	e.driftCheckDue = time.Now().Add(driftSettle) // Once the user sync is in
+	e.driftSeen = nil
+	e.connected = true
+	e.closeDemo = func() {
+		feed.Close()
+		account.Close()
+	}
+	e.mu.Unlock()
+
+	e.mainLog.Info(">>> CONNECTED TO DEMO MARKET DATA <<<")
+	e.applyNews(cfg)
+	if cfg.Recording.Metrics && !e.MetricsRecording() {
+		e.SetMetricsRecording(true)
+	}
+	e.emit(Event{Kind: EventConnected, Message: "Connected to demo market data"})
+	return nil
+}
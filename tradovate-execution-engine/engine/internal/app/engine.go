@@ -0,0 +1,922 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/audit"
+	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/contracts"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/schedule"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// NewEngine creates a disconnected engine logging to the given loggers
+func NewEngine(mainLog, orderLog, strategyLog *logger.Logger) *Engine {
+	return &Engine{
+		mainLog:     mainLog,
+		orderLog:    orderLog,
+		strategyLog: strategyLog,
+		instances:   make(map[string]*StrategyInstance),
+		inSession:   true,
+	}
+}
+
+// String returns the status in lower case, e.g. "running"
+func (s StrategyStatus) String() string {
+	switch s {
+	case StrategyDisabled:
+		return "disabled"
+	case StrategyStarting:
+		return "starting"
+	case StrategyRunning:
+		return "running"
+	case StrategyStopping:
+		return "stopping"
+	case StrategyStopped:
+		return "stopped"
+	case StrategyError:
+		return "error"
+	}
+	return fmt.Sprintf("StrategyStatus(%d)", int32(s))
+}
+
+// SetStatus sets the strategy status
+func (r *StrategyRuntime) SetStatus(s StrategyStatus) {
+	r.status.Store(int32(s))
+}
+
+// Status returns the strategy status
+func (r *StrategyRuntime) Status() StrategyStatus {
+	return StrategyStatus(r.status.Load())
+}
+
+// IsLive reports whether historical bars are done and the strategy sees live data
+func (r *StrategyRuntime) IsLive() bool {
+	return r.live.Load()
+}
+
+// IsReady reports whether the strategy is live and its indicators are primed,
+// so it may trade when the session is open
+func (r *StrategyRuntime) IsReady() bool {
+	return r.ready.Load()
+}
+
+// IsThrottled reports whether the order throttle disabled the strategy
+func (r *StrategyRuntime) IsThrottled() bool {
+	return r.throttled.Load()
+}
+
+// DroppedQuotes returns how many quotes were dropped because the strategy's
+// OnQuote fell behind the feed
+func (r *StrategyRuntime) DroppedQuotes() int64 {
+	return r.droppedQuotes.Load()
+}
+
+// Failure returns why the strategy is in StrategyError, empty in any other status
+func (r *StrategyRuntime) Failure() string {
+	if f := r.failure.Load(); f != nil {
+		return *f
+	}
+	return ""
+}
+
+// AddEventHandler registers a callback for engine status changes. Handlers run on
+// the goroutine that caused the change.
+func (e *Engine) AddEventHandler(handler func(Event)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers = append(e.handlers, handler)
+}
+
+// emit delivers an event to every handler outside the lock
+func (e *Engine) emit(ev Event) {
+	ev.Time = time.Now()
+	e.mu.RLock()
+	handlers := e.handlers
+	e.mu.RUnlock()
+	for _, h := range handlers {
+		h(ev)
+	}
+}
+
+// Connect authenticates, opens both WebSockets and starts order and portfolio
+// tracking. A config that fails Validate is refused before logging in, each
+// problem logged, with an error wrapping the *config.ValidationError.
+func (e *Engine) Connect(cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		var invalid *config.ValidationError
+		if errors.As(err, &invalid) {
+			for _, p := range invalid.Problems {
+				e.mainLog.Errorf("Config: %s", p)
+			}
+		}
+		return fmt.Errorf("invalid config, not logging in: %w", err)
+	}
+	e.startMetrics(cfg.Metrics)
+	e.startAdmin(cfg.Admin)
+	e.startAudit(cfg.Audit)
+	if err := e.ApplyLogging(cfg.Logging); err != nil {
+		e.mainLog.Errorf("Log levels not applied: %v", err)
+	}
+
+	tm := auth.NewTokenManager(cfg)
+	tm.SetLogger(e.mainLog)
+
+	e.mainLog.Info("Attempting Authentication...")
+	if err := tm.Login(); err != nil {
+		e.mainLog.Errorf("Authentication failed: %v", err)
+		return fmt.Errorf("auth error: %w", err)
+	}
+
+	sessionStart := time.Now().UTC()
+	e.mainLog.Infof("Session Start Time: %s", sessionStart)
+	e.mainLog.Info("Authentication Successful")
+
+	if err := tm.SetPreferredAccount(cfg.Tradovate.AccountID, cfg.Tradovate.AccountSpec); err != nil {
+		e.mainLog.Errorf("Account selection failed: %v", err)
+		return fmt.Errorf("account error: %w", err)
+	}
+	accountID, _ := tm.GetAccountID()
+	e.mainLog.Infof("Trading account: %s (%d)", tm.GetAccountSpec(), accountID)
+
+	om := execution.NewOrderManager(tm, cfg, e.orderLog)
+	om.SetAuditTrail(e.AuditTrail())
+	if e.KillSwitchEngaged() {
+		om.EngageKillSwitch()
+		e.orderLog.Warn("KILL SWITCH - still engaged, orders refused until :arm")
+	}
+	if cfg.Tradovate.Environment.IsLive() && !e.LiveConfirmed() {
+		om.RequireLiveConfirm()
+		e.orderLog.Warn("LIVE ACCOUNT - orders refused until live trading is confirmed (:confirm-live)")
+	}
+	resolver := contracts.NewResolver(tm, cfg.Tradovate.RolloverDays, e.strategyLog)
+	om.SetSymbolResolver(resolver)
+	catalog := contracts.NewCatalog(tm, e.mainLog)
+	om.SetContractCatalog(catalog)
+	om.SetQuoteSource(e)
+
+	sched, err := schedule.NewTradingSchedule(cfg.Schedule)
+	if err != nil {
+		return fmt.Errorf("schedule error: %w", err)
+	}
+	om.SetSchedule(sched)
+	if sched.Enabled() {
+		e.mainLog.Infof("Trading schedule: %s", sched)
+	}
+	maintenance, err := schedule.NewMaintenanceWindows(cfg.Maintenance, cfg.Tradovate.Environment)
+	if err != nil {
+		return fmt.Errorf("maintenance error: %w", err)
+	}
+	if maintenance.Enabled() {
+		e.mainLog.Infof("Maintenance windows: %s", maintenance)
+	}
+
+	accessToken, err := tm.GetAccessToken()
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+	e.mainLog.Debug("Auth token aquired")
+
+	mdToken, err := tm.GetMDAccessToken()
+	if err != nil {
+		return fmt.Errorf("failed to get MD token: %w", err)
+	}
+	e.mainLog.Debug("Market data token aquired")
+
+	mdClient := tradovate.NewTradovateWebSocketClient(mdToken, cfg.Tradovate.Environment, "md")
+	mdClient.SetLogger(e.mainLog)
+
+	tradingClient := tradovate.NewTradovateWebSocketClient(accessToken, cfg.Tradovate.Environment, "")
+	tradingClient.SetLogger(e.mainLog)
+
+	staleTimeout := time.Duration(cfg.Tradovate.StaleTimeoutSeconds) * time.Second
+	mdClient.SetStaleTimeout(staleTimeout)
+	tradingClient.SetStaleTimeout(staleTimeout)
+	mdClient.SetSendErrorHandler(func(err error) { e.sendFailed("Market data", err) })
+	tradingClient.SetSendErrorHandler(func(err error) { e.sendFailed("Trading", err) })
+	mdClient.SetStaleHandler(func() {
+		if e.InMaintenance() {
+			return
+		}
+		e.strategyLog.Warn("Market data stale - bars will not update until data resumes (reconnect to recover)")
+	})
+
+	if cfg.Recording.Enabled {
+		e.startRecording(cfg.Recording, mdClient, tradingClient)
+	}
+
+	mdSubscriber := tradovate.NewDataSubscriptionManager(mdClient)
+	mdSubscriber.SetLogger(e.strategyLog)
+
+	tradingSubscriber := tradovate.NewDataSubscriptionManager(tradingClient)
+	tradingSubscriber.SetLogger(e.mainLog)
+
+	if err := mdSubscriber.Connect(); err != nil {
+		return fmt.Errorf("Error connecting market data client: %w", err)
+	}
+	e.mainLog.Info("Market Data WebSocket connected")
+
+	if err := tradingSubscriber.Connect(); err != nil {
+		return fmt.Errorf("Error connecting trading client: %w", err)
+	}
+	e.mainLog.Info("Trading WebSocket connected")
+
+	mdClient.SetMessageHandler(mdSubscriber.HandleEvent)
+	mdClient.SetResponseHandler(mdSubscriber.HandleResponse)
+	mdClient.SetRequestErrorHandler(mdSubscriber.HandleRequestError)
+	tradingClient.SetMessageHandler(tradingSubscriber.HandleEvent)
+	tradingClient.SetResponseHandler(tradingSubscriber.HandleResponse)
+	tradingClient.SetRequestErrorHandler(tradingSubscriber.HandleRequestError)
+	e.mainLog.Debug("Message Handlers Set")
+
+	tracker := portfolio.NewPortfolioTracker(tradingSubscriber, mdSubscriber, tm.GetUserID(), accountID, e.mainLog)
+	tracker.SetSessionAnchor(e.sessionAnchor(accountID, sessionStart))
+
+	tradingSubscriber.OnOrderUpdate = func(data json.RawMessage) { e.handleOrderUpdate(om, sessionStart, data) }
+	tradingSubscriber.OnFillUpdate = func(data json.RawMessage) {
+		// Every fill goes to the trade ledger; only this session's reach the order manager
+		tracker.RecordFill(data)
+		e.handleFillUpdate(om, sessionStart, data)
+	}
+	tradingSubscriber.OnExecutionReport = func(data json.RawMessage) { e.handleExecutionReport(om, sessionStart, data) }
+	e.mainLog.Debug("OnOrderUpdate Set")
+
+	tracker.SetContractCatalog(catalog)
+	tracker.SetBalanceWarning(cfg.Risk.MinBalanceWarning)
+	tracker.SetPositionLossAlerts(cfg.Risk.PositionLossWarning, cfg.Risk.PositionLossCritical)
+	tracker.SetFees(cfg.Fees)
+	tracker.SetMarkToMidpoint(cfg.Risk.MarkToMidpoint)
+	if err := tracker.Start(cfg.Tradovate.Environment); err != nil {
+		return fmt.Errorf("Failed to start PortfolioTracker: %w", err)
+	}
+	om.SetPortfolioTracker(tracker)
+
+	trailingStops := execution.NewTrailingStopManager(om, tracker, mdSubscriber, e.orderLog)
+
+	tm.StartTokenRefreshMonitor(func() {
+		e.mainLog.Debug("Reconnection complete after token refresh")
+		e.tokenRenewed(tm)
+	}, func(err error) { e.tokenRenewalFailed(tm, err) })
+
+	riskSupervisor := e.startRiskSupervisor(om)
+	e.watchOrderThrottle(om)
+
+	e.mu.Lock()
+	e.cfg = cfg
+	e.tm = tm
+	e.om = om
+	e.riskSupervisor = riskSupervisor
+	e.mdClient = mdClient
+	e.tradingClient = tradingClient
+	e.mdSubscriber = mdSubscriber
+	e.tradingSubscriber = tradingSubscriber
+	e.pt = tracker
+	e.ts = trailingStops
+	e.resolver = resolver
+	e.catalog = catalog
+	e.maintenance = maintenance
+	e.reconnectDue = time.Time{}
+	e.resume = nil
+	e.driftCheckDue = time.Now().Add(driftSettle) // Once the user sync is in
+	e.driftSeen = nil
+	e.renewalErr = nil
+	e.renewalReported = false
+	e.connected = true
+	e.mu.Unlock()
+
+	e.mainLog.Info(">>> CONNECTION SUCCESSFUL <<<")
+	e.applyNews(cfg)
+	if cfg.Recording.Metrics && !e.MetricsRecording() {
+		e.SetMetricsRecording(true)
+	}
+	e.emit(Event{Kind: EventConnected, Message: "Connected to Tradovate"})
+	return nil
+}
+
+// handleOrderUpdate applies an order event from this session and logs it
+func (e *Engine) handleOrderUpdate(om *execution.OrderManager, sessionStart time.Time, data json.RawMessage) {
+	order, err := tradovate.DecodeOrderEvent(data)
+	if err != nil {
+		e.orderLog.Warnf("Failed to parse order update: %v", err)
+		return
+	}
+
+	// Snapshots always carry a timestamp; a live update without one is current
+	orderTime := order.Timestamp
+	if !order.HasTimestamp {
+		e.orderLog.Debugf("Order update for %d has no timestamp, using the receive time", order.ID)
+		orderTime = time.Now().UTC()
+	}
+	if orderTime.Before(sessionStart) {
+		return
+	}
+
+	om.HandleOrderEvent(data)
+
+	ts := orderTime.Format("03:04:05 PM")
+	switch order.OrdStatus {
+	case "PendingNew":
+		e.orderLog.Infof("[%s UTC] ORDER PENDING  | ID=%d | %s %s", ts, order.ID, order.Action, order.OrderType)
+	case "Filled":
+		e.orderLog.Infof("[%s UTC] ORDER FILLED   | ID=%d | %s %s%s", ts, order.ID, order.Action, order.OrderType, fillSummary(om, order))
+	case "Rejected":
+		reason := "no reason given"
+		if order.RejectReason != "" || order.Text != "" {
+			reason = tradovate.DescribeRejection(order.RejectReason, order.Text)
+		}
+		e.orderLog.Errorf("[%s UTC] ORDER REJECTED | ID=%d | %s %s | %s", ts, order.ID, order.Action, order.OrderType, reason)
+	case "Working":
+		e.orderLog.Infof("[%s UTC] ORDER WORKING  | ID=%d | %s %s%s", ts, order.ID, order.Action, order.OrderType, fillSummary(om, order))
+	case "Canceled":
+		e.orderLog.Infof("[%s UTC] ORDER CANCELED | ID=%d | %s %s", ts, order.ID, order.Action, order.OrderType)
+	case "Expired":
+		e.orderLog.Infof("[%s UTC] ORDER EXPIRED  | ID=%d | %s %s%s", ts, order.ID, order.Action, order.OrderType, fillSummary(om, order))
+	case "Suspended":
+		e.orderLog.Infof("[%s UTC] ORDER SUSPENDED | ID=%d | %s %s | waiting on its parent order", ts, order.ID, order.Action, order.OrderType)
+	default:
+		e.orderLog.Warnf("[%s UTC] UNKNOWN ORDER STATUS | ID=%d | %s %s", ts, order.ID, order.Action, order.OrderType)
+	}
+}
+
+// fillSummary describes how much of order has filled and at what average
+// price, as " | 2 filled @ 5010.25". The event's cumQty and avgPx are used when
+// it has them, otherwise the fills recorded so far; "" if nothing has filled.
+func fillSummary(om *execution.OrderManager, order tradovate.OrderEvent) string {
+	recorded := models.Order{Fills: om.GetFillsByExternalID(strconv.Itoa(order.ID))}
+	qty, avg := recorded.FilledQty(), recorded.AvgFillPrice()
+	if order.HasCumQty {
+		qty = order.CumQty
+	}
+	if order.HasAvgPrice && order.AvgPrice > 0 {
+		avg = order.AvgPrice
+	}
+	switch {
+	case qty <= 0:
+		return ""
+	case avg <= 0:
+		return fmt.Sprintf(" | %d filled", qty)
+	}
+	return fmt.Sprintf(" | %d filled @ %.2f", qty, avg)
+}
+
+// handleFillUpdate applies a fill from this session and logs it
+func (e *Engine) handleFillUpdate(om *execution.OrderManager, sessionStart time.Time, data json.RawMessage) {
+	fill, err := tradovate.DecodeFillEvent(data)
+	if err != nil {
+		e.orderLog.Warnf("Failed to parse fill: %v", err)
+		return
+	}
+
+	fillTime := fill.Timestamp
+	if !fill.HasTimestamp {
+		fillTime = time.Now().UTC()
+	}
+	if fillTime.Before(sessionStart) {
+		return
+	}
+
+	om.HandleFillEvent(data)
+	e.orderLog.Infof("[%s UTC] FILL           | ID=%d | %d @ %.2f",
+		fillTime.Format("03:04:05 PM"), fill.OrderID, fill.Qty, fill.Price)
+}
+
+// handleExecutionReport applies an execution report from this session
+func (e *Engine) handleExecutionReport(om *execution.OrderManager, sessionStart time.Time, data json.RawMessage) {
+	report, err := tradovate.DecodeExecutionReport(data)
+	if err != nil {
+		e.orderLog.Warnf("Failed to parse execution report: %v", err)
+		return
+	}
+	if report.HasTimestamp && report.Timestamp.Before(sessionStart) {
+		return
+	}
+
+	om.HandleExecutionReport(data)
+	if report.ExecType == "Trade" {
+		e.orderLog.Debugf("EXECUTION | ID=%d | %s %d @ %.2f (%d filled, avg %.2f)",
+			report.OrderID, report.Action, report.LastQty, report.LastPx, report.CumQty, report.AvgPx)
+	}
+}
+
+// Disconnect stops every strategy and closes every connection. Working orders are left alone.
+func (e *Engine) Disconnect() {
+	e.StopAllStrategies()
+
+	e.mu.Lock()
+	tm, ts, pt, rs := e.tm, e.ts, e.pt, e.riskSupervisor
+	md, mdClient, tradingClient := e.mdSubscriber, e.mdClient, e.tradingClient
+	closeDemo := e.closeDemo
+	wasConnected := e.connected
+	e.connected = false
+	e.inMaintenance = false
+	e.inNewsLockout = false
+	e.reconnectDue = time.Time{}
+	e.resume = nil
+	e.closeDemo = nil
+	e.riskSupervisor = nil
+	e.tm = nil
+	e.renewalErr = nil
+	e.catalog = nil
+	e.ts = nil
+	if pt != nil {
+		e.session = pt.SessionAnchor()
+	}
+	e.pt = nil
+	e.mdClient = nil
+	e.tradingClient = nil
+	e.mu.Unlock()
+
+	if rs != nil {
+		rs.Stop()
+	}
+	if tm != nil {
+		tm.StopTokenRefreshMonitor()
+	}
+	if ts != nil {
+		for _, stop := range ts.GetActive() {
+			e.mainLog.Warnf("Trailing stop for %s no longer managed; stop order remains working", stop.Symbol)
+		}
+	}
+	if pt != nil {
+		_ = pt.Stop()
+	}
+	if md != nil {
+		_ = md.UnsubscribeAll()
+	}
+	if mdClient != nil {
+		_ = mdClient.Disconnect()
+	}
+	if tradingClient != nil {
+		_ = tradingClient.Disconnect()
+	}
+	if closeDemo != nil {
+		closeDemo()
+	}
+
+	if wasConnected {
+		e.emit(Event{Kind: EventDisconnected, Message: "Disconnected from Tradovate"})
+	}
+}
+
+// ApplyConfig hot-applies a reloaded config: risk limits and slippage go to the
+// order manager; the balance warning, position loss alerts, fees and midpoint
+// marking to the portfolio; log levels are set when the logging section
+// changed; a live connection takes the new maintenance windows; and the news
+// calendar is reread. Connection settings still need a reconnect.
+func (e *Engine) ApplyConfig(cfg *config.Config) {
+	e.mu.Lock()
+	prev := e.cfg
+	e.cfg = cfg
+	om, pt := e.om, e.pt
+	live := e.maintenance != nil
+	e.mu.Unlock()
+
+	if prev != nil {
+		if changes := configChanges(prev, cfg); len(changes) > 0 {
+			e.AuditTrail().Record(audit.ActorManual, audit.ActionConfigReload, map[string]interface{}{"changes": changes})
+		}
+	}
+	if prev == nil || prev.Logging != cfg.Logging {
+		if err := e.ApplyLogging(cfg.Logging); err != nil {
+			e.mainLog.Errorf("Log levels not applied: %v", err)
+		}
+	}
+
+	if om != nil {
+		om.ApplyConfig(cfg)
+	}
+	if pt != nil {
+		pt.SetBalanceWarning(cfg.Risk.MinBalanceWarning)
+		pt.SetPositionLossAlerts(cfg.Risk.PositionLossWarning, cfg.Risk.PositionLossCritical)
+		pt.SetFees(cfg.Fees)
+		pt.SetMarkToMidpoint(cfg.Risk.MarkToMidpoint)
+	}
+	if live {
+		e.applyMaintenance(cfg)
+	}
+	e.applyNews(cfg)
+}
+
+// DefaultShutdownTimeout applies when risk.shutdownTimeoutSeconds is unset
+const DefaultShutdownTimeout = 10 * time.Second
+
+// ErrShutdownTimeout is returned by Shutdown when the sequence outlasts its timeout
+var ErrShutdownTimeout = errors.New("shutdown timed out")
+
+// Shutdown stops every strategy, cancels working orders and flattens as the risk
+// config asks, then disconnects and closes the metrics listener, admin API and
+// audit trail. allowFlatten false skips flattenOnExit. It returns
+// ErrShutdownTimeout, leaving the sequence to finish in the background, once
+// risk.shutdownTimeoutSeconds has passed.
+func (e *Engine) Shutdown(allowFlatten bool) error {
+	timeout := DefaultShutdownTimeout
+	if cfg := e.Config(); cfg != nil && cfg.Risk.ShutdownTimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.Risk.ShutdownTimeoutSeconds) * time.Second
+	}
+
+	done := make(chan struct{})
+	go func() {
+		e.shutdown(allowFlatten)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return ErrShutdownTimeout
+	}
+}
+
+// shutdown is the sequence Shutdown runs
+func (e *Engine) shutdown(allowFlatten bool) {
+	defer e.stopAudit()
+	defer e.stopAdmin()
+	defer e.stopMetrics()
+	e.StopAllStrategies()
+	if !e.IsConnected() {
+		return
+	}
+
+	om := e.OrderManager()
+	risk := e.Config().Risk
+
+	if risk.CancelOrdersOnExit {
+		n, err := om.CancelWorkingOrders()
+		if err != nil {
+			e.mainLog.Errorf("Shutdown: %v", err)
+		}
+		e.mainLog.Infof("Shutdown: cancelled %d working orders", n)
+	}
+
+	if risk.FlattenOnExit {
+		if !allowFlatten {
+			e.mainLog.Warn("Shutdown: flattenOnExit skipped in Visual mode")
+		} else if err := om.FlattenPositions(); err != nil {
+			e.mainLog.Errorf("Shutdown: flatten failed: %v", err)
+		} else {
+			e.orderLog.Info("FLATTEN - All positions closed on exit")
+		}
+	}
+
+	e.Disconnect()
+}
+
+// Flatten stops every strategy, cancels every working order and closes every
+// open position. PreviewFlatten shows what it would send.
+func (e *Engine) Flatten() error {
+	if !e.IsConnected() {
+		return errors.New("not connected")
+	}
+	e.StopAllStrategies()
+
+	om := e.OrderManager()
+	// A resting stop left behind could open a new position once flat
+	if n, err := om.CancelWorkingOrders(); err != nil {
+		e.orderLog.Errorf("FLATTEN - %v", err)
+	} else if n > 0 {
+		e.orderLog.Infof("FLATTEN - cancelled %d working orders", n)
+	}
+	if err := om.FlattenPositionsBy(audit.ActorManual); err != nil {
+		return err
+	}
+	e.mainLog.Info("All positions flattened")
+	e.orderLog.Info("FLATTEN - All positions closed")
+	return nil
+}
+
+// PreviewFlatten returns the orders Flatten would cancel and send right now,
+// without sending anything
+func (e *Engine) PreviewFlatten() (execution.FlattenPlan, error) {
+	if !e.IsConnected() {
+		return execution.FlattenPlan{}, errors.New("not connected")
+	}
+	return e.OrderManager().PreviewFlatten()
+}
+
+// handleChart passes a run's chart updates to its warm-up. The subscriber only
+// passes charts for the run's symbol, which other chart subscriptions on the
+// symbol share, so the warm-up is held to the run's own chart IDs once the
+// subscription is confirmed.
+func (e *Engine) handleChart(inst *StrategyInstance, run *strategyRun, md *tradovate.DataSubscriber, update marketdata.ChartUpdate) {
+	if !run.chartPinned {
+		if ids := md.ChartIDs(run.chartParams); len(ids) > 0 {
+			run.warmup.SetChartIDs(ids...)
+			run.chartPinned = true
+		}
+	}
+	for _, chart := range update.Charts {
+		inst.Log.Debugf("Chart ID: %d | Bars: %d | EOH: %v", chart.ID, len(chart.Bars), chart.EOH)
+	}
+	run.warmup.OnChart(update)
+}
+
+// goLive runs once a run's history has been fed: live bars start. Trading waits
+// for strategyReady.
+func (e *Engine) goLive(inst *StrategyInstance, run *strategyRun) {
+	inst.Log.Debugf("End of historical data after %d bars - now receiving live updates", run.warmup.Fed())
+	// The stale quote timeout runs from here
+	inst.Runtime.lastQuote.Store(time.Now().UnixNano())
+	inst.Runtime.live.Store(true)
+	if err := execution.StartLifecycle(run.strategy); err != nil {
+		inst.Log.Warnf("Strategy lifecycle: %v", err)
+	}
+	if r, ok := run.strategy.(execution.ReadinessReporter); ok && !r.IsReady() {
+		fed, need := run.warmup.Progress()
+		inst.Log.Warnf("Warm-up has %d of %d bars - live trading waits until the strategy is primed", fed, need)
+	}
+}
+
+// strategyReady runs once a live run's strategy is primed: it is enabled if
+// the session is open
+func (e *Engine) strategyReady(inst *StrategyInstance, run *strategyRun) {
+	inst.Runtime.ready.Store(true)
+	fed, _ := run.warmup.Progress()
+	inst.Log.Debugf("Strategy primed after %d bars", fed)
+	if e.tradingHeld(inst) {
+		return
+	}
+
+	if s, ok := run.strategy.(interface{ SetEnabled(bool) }); ok {
+		sched := e.OrderManager().GetSchedule()
+		if !sched.InSession(time.Now()) {
+			inst.Log.Infof("Outside trading hours (%s) - strategy will be enabled when the session opens", sched)
+			return
+		}
+		s.SetEnabled(true)
+		inst.Log.Info("Strategy enabled for LIVE trading")
+	}
+}
+
+// handleQuote builds live bars from trades once a run's history has loaded,
+// queues the quote for QuoteHandler strategies and passes trade prices to
+// strategies that watch intrabar prices. The subscriber only passes quotes for
+// the run's contract when its ID is known.
+func (e *Engine) handleQuote(inst *StrategyInstance, run *strategyRun, quote marketdata.Quote) {
+	if inst.ContractID() == 0 {
+		// The ID lookup failed; match the contract name learned from user sync
+		pt := e.Portfolio()
+		if pt == nil {
+			return
+		}
+		if name, ok := pt.GetContractName(quote.ContractID); !ok || name != inst.Symbol() {
+			return
+		}
+	}
+	inst.Runtime.lastQuote.Store(time.Now().UnixNano())
+	if !run.warmup.Live() {
+		return
+	}
+	if run.mode != execution.UpdateTicksOnly {
+		run.barBuilder.OnQuote(quote)
+	}
+	if run.quotes != nil && run.quotes.Offer(quote) {
+		// Warn on the first drop and then every thousand, not on every quote
+		if n := inst.Runtime.droppedQuotes.Add(1); n == 1 || n%1000 == 0 {
+			inst.Log.Warnf("Strategy is falling behind the quote feed: %d quotes dropped", n)
+		}
+	}
+
+	s, ok := run.strategy.(interface{ OnPrice(float64) error })
+	trade, hasTrade := quote.LastTrade()
+	if ok && hasTrade {
+		if err := execution.CallStrategy("OnPrice", func() error { return s.OnPrice(trade.Price) }); err != nil {
+			go e.failStrategy(inst, run, err)
+		}
+	}
+}
+
+// StrategyChartParams is the chart subscription a running strategy uses for symbol
+func StrategyChartParams(symbol string, spec marketdata.BarSpec) marketdata.HistoricalDataParams {
+	return marketdata.HistoricalDataParams{
+		Symbol:           symbol,
+		ChartDescription: spec.ChartDescription(),
+		TimeRange: marketdata.TimeRange{
+			AsMuchAsElements: execution.DefaultWarmupBars,
+		},
+	}
+}
+
+// StrategyBarSpec reads the bar_type and bar_size parameters a strategy may
+// expose. Strategies without them trade 1-minute bars.
+func StrategyBarSpec(strat execution.Strategy) (marketdata.BarSpec, error) {
+	barType, size := "", 1
+	for _, p := range strat.GetParams() {
+		switch p.Name {
+		case "bar_type":
+			barType = p.Value
+		case "bar_size":
+			n, err := strconv.Atoi(p.Value)
+			if err != nil {
+				return marketdata.BarSpec{}, fmt.Errorf("invalid bar_size: %w", err)
+			}
+			size = n
+		}
+	}
+	return marketdata.ParseBarSpec(barType, size)
+}
+
+// TotalPnL returns unrealized plus today's realized PnL, or 0 when disconnected
+func (e *Engine) TotalPnL() float64 {
+	pt := e.Portfolio()
+	if pt == nil {
+		return 0
+	}
+	return pt.GetTotalPL() + pt.GetRealizedPnL()
+}
+
+// HasOpenPositions reports whether any tracked position is not flat
+func (e *Engine) HasOpenPositions() bool {
+	pt := e.Portfolio()
+	if pt == nil {
+		return false
+	}
+	for _, entry := range pt.GetPLSummary() {
+		if entry.NetPos != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckLossStreak feeds newly closed trades to the loss streak breaker and,
+// when that trips it, stops every strategy and flattens if Risk.LossStreakFlatten
+// is set. Returns whether the breaker tripped on this call.
+func (e *Engine) CheckLossStreak() bool {
+	om := e.OrderManager()
+	if om == nil || e.Portfolio() == nil {
+		return false
+	}
+	rm := om.GetRiskManager()
+	if !rm.UpdateLossStreak() {
+		return false
+	}
+
+	losses := rm.GetLossStreak()
+	e.mainLog.Errorf("%d losing trades in a row! Stopping all strategies.", losses)
+	e.StopAllStrategies()
+	if rm.GetConfig().Risk.LossStreakFlatten && e.HasOpenPositions() {
+		e.mainLog.Error("Loss streak breaker tripped! Flattening all positions.")
+		om.FlattenPositions()
+	}
+	e.emit(Event{Kind: EventLossStreak, Message: fmt.Sprintf("Loss streak breaker tripped after %d losing trades", losses)})
+	return true
+}
+
+// CheckSchedule enables or disables running strategies as the session opens
+// and closes, and at the cutoff stops them and (when flatten is set) flattens.
+// Call it periodically; it returns whether trading is in session and whether
+// the cutoff fired on this call.
+func (e *Engine) CheckSchedule(now time.Time, flatten bool) (inSession, cutoff bool) {
+	om := e.OrderManager()
+	if om == nil {
+		return true, false
+	}
+	sched := om.GetSchedule()
+	inSession = sched.InSession(now)
+
+	e.mu.Lock()
+	prev := e.lastScheduleTick
+	e.lastScheduleTick = now
+	wasInSession := e.inSession
+	e.inSession = inSession || !sched.Enabled()
+	e.mu.Unlock()
+
+	if !sched.Enabled() {
+		return true, false
+	}
+
+	if sched.FlattenDue(prev, now) {
+		cutoff = true
+		e.mainLog.Warnf("Schedule flatten time reached (%s)", sched)
+		if e.AnyRunning() {
+			e.strategyLog.Warn("Schedule flatten time reached - stopping all strategies")
+			e.StopAllStrategies()
+		}
+		if flatten && e.HasOpenPositions() {
+			if err := om.FlattenPositions(); err != nil {
+				e.mainLog.Errorf("Schedule flatten failed: %v", err)
+			}
+		}
+		e.emit(Event{Kind: EventSessionCutoff, Message: "Session cutoff - strategy stopped and positions flattened"})
+	}
+
+	if prev.IsZero() || inSession == wasInSession {
+		return inSession, cutoff
+	}
+
+	if inSession {
+		e.mainLog.Infof("Trading session open (%s)", sched)
+		e.emit(Event{Kind: EventSessionOpen, Message: sched.String()})
+	} else {
+		e.mainLog.Infof("Trading session closed (%s)", sched)
+		e.emit(Event{Kind: EventSessionClosed, Message: sched.String()})
+	}
+
+	for _, inst := range e.Strategies() {
+		if inst.Runtime.Status() != StrategyRunning || !inst.Runtime.IsReady() || e.tradingHeld(inst) {
+			continue
+		}
+		if s, ok := inst.Strategy().(interface{ SetEnabled(bool) }); ok {
+			s.SetEnabled(inSession)
+			if inSession {
+				inst.Log.Info("Session open - strategy enabled")
+			} else {
+				inst.Log.Info("Session closed - strategy disabled")
+			}
+		}
+	}
+	return inSession, cutoff
+}
+
+// IsConnected reports whether Connect succeeded and Disconnect has not been called
+func (e *Engine) IsConnected() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.connected
+}
+
+// Config returns the config passed to Connect
+func (e *Engine) Config() *config.Config {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.cfg
+}
+
+// TokenManager returns the token manager of the current connection, or nil
+// when disconnected or on demo data. Each Connect authenticates a new one.
+func (e *Engine) TokenManager() *auth.TokenManager {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.tm
+}
+
+// OrderManager returns the order manager, or nil before Connect
+func (e *Engine) OrderManager() *execution.OrderManager {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.om
+}
+
+// Portfolio returns the portfolio tracker, or nil when disconnected
+func (e *Engine) Portfolio() *portfolio.PortfolioTracker {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.pt
+}
+
+// AccountBalance returns the account's latest cash balance and margin. ok is
+// false while disconnected or before Tradovate has sent a cash balance.
+func (e *Engine) AccountBalance() (balance portfolio.AccountBalance, ok bool) {
+	pt := e.Portfolio()
+	if pt == nil {
+		return portfolio.AccountBalance{}, false
+	}
+	balance = pt.GetAccountBalance()
+	return balance, !balance.UpdatedAt.IsZero()
+}
+
+// TrailingStops returns the trailing stop manager, or nil when disconnected
+func (e *Engine) TrailingStops() *execution.TrailingStopManager {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.ts
+}
+
+// MarketData returns the market data subscriber
+func (e *Engine) MarketData() *tradovate.DataSubscriber {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.mdSubscriber
+}
+
+// Trading returns the trading (user data) subscriber
+func (e *Engine) Trading() *tradovate.DataSubscriber {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.tradingSubscriber
+}
+
+// MarketDataClient returns the market data WebSocket client
+func (e *Engine) MarketDataClient() *tradovate.TradovateWebSocketClient {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.mdClient
+}
+
+// TradingClient returns the trading WebSocket client
+func (e *Engine) TradingClient() *tradovate.TradovateWebSocketClient {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.tradingClient
+}
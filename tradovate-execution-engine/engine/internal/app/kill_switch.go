@@ -0,0 +1,143 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/audit"
+)
+
+// KillSwitch locks out new orders, stops every strategy, cancels every working
+// order and trailing stop and flattens every position. The lock is taken first
+// so a strategy acting while it is stopped cannot open anything, and it stays
+// on, across reconnects, until Arm. Each step is logged to the order log; a
+// failed step is logged and the rest still run.
+func (e *Engine) KillSwitch() error {
+	started := time.Now()
+	e.mu.Lock()
+	e.killed = true
+	om, ts, connected := e.om, e.ts, e.connected
+	e.mu.Unlock()
+
+	step := func(format string, args ...interface{}) {
+		e.orderLog.Warnf("KILL SWITCH +%dms - %s", time.Since(started).Milliseconds(), fmt.Sprintf(format, args...))
+	}
+	e.orderLog.Errorf("KILL SWITCH ENGAGED at %s", started.Format("2006-01-02 15:04:05.000"))
+	e.AuditTrail().Record(audit.ActorManual, audit.ActionKillSwitch, map[string]interface{}{"connected": connected})
+
+	if om != nil {
+		om.EngageKillSwitch()
+	}
+	step("new orders locked out")
+
+	step("stopped %d strategies", e.StopAllStrategies())
+
+	if !connected || om == nil {
+		step("not connected, no orders to cancel or positions to flatten")
+		e.emit(Event{Kind: EventKillSwitch, Message: "Kill switch engaged (not connected)"})
+		return nil
+	}
+
+	var errs []error
+	if ts != nil {
+		active := ts.GetActive()
+		for _, stop := range active {
+			if err := ts.Stop(stop.Symbol); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		step("stopped %d trailing stops", len(active))
+	}
+
+	cancelled, err := om.CancelWorkingOrders()
+	if err != nil {
+		step("cancel failed: %v", err)
+		errs = append(errs, err)
+	}
+	step("cancelled %d working orders", cancelled)
+
+	if err := om.FlattenPositionsBy(audit.ActorManual); err != nil {
+		step("flatten failed: %v", err)
+		errs = append(errs, err)
+	} else {
+		step("flatten orders sent for every open position")
+	}
+
+	e.orderLog.Errorf("KILL SWITCH COMPLETE in %dms - trading locked until :arm", time.Since(started).Milliseconds())
+	e.mainLog.Error("Kill switch engaged: strategies stopped, orders cancelled, positions flattened")
+	e.emit(Event{Kind: EventKillSwitch, Message: fmt.Sprintf("Kill switch engaged: %d orders cancelled, positions flattened", cancelled)})
+	return errors.Join(errs...)
+}
+
+// PreviewKillSwitch returns what KillSwitch would stop, cancel and send right
+// now, without doing any of it. The orders are left out when not connected.
+func (e *Engine) PreviewKillSwitch() (KillPlan, error) {
+	var plan KillPlan
+	for _, inst := range e.Strategies() {
+		if status := inst.Runtime.Status(); status == StrategyRunning || status == StrategyStarting {
+			plan.Strategies = append(plan.Strategies, inst.ID)
+		}
+	}
+
+	e.mu.RLock()
+	om, ts, connected := e.om, e.ts, e.connected
+	e.mu.RUnlock()
+	if !connected || om == nil {
+		return plan, nil
+	}
+	if ts != nil {
+		for _, stop := range ts.GetActive() {
+			plan.TrailingStops = append(plan.TrailingStops, stop.Symbol)
+		}
+		sort.Strings(plan.TrailingStops)
+	}
+
+	orders, err := om.PreviewFlatten()
+	if err != nil {
+		return plan, err
+	}
+	plan.FlattenPlan = orders
+	return plan, nil
+}
+
+// Arm re-enables trading after KillSwitch, and releases the loss streak
+// breaker if it is tripped. Stopped strategies stay stopped.
+func (e *Engine) Arm() error {
+	e.mu.Lock()
+	killed := e.killed
+	e.killed = false
+	om := e.om
+	e.mu.Unlock()
+
+	breaker := false
+	if om != nil {
+		if tripped, _ := om.GetRiskManager().LossBreaker(); tripped {
+			breaker = om.GetRiskManager().ResetLossBreaker(":arm")
+		}
+	}
+	if !killed && !breaker {
+		return errors.New("kill switch is not engaged and the loss streak breaker is not tripped")
+	}
+
+	e.AuditTrail().Record(audit.ActorManual, audit.ActionArm, map[string]interface{}{"killSwitch": killed, "lossBreaker": breaker})
+	if killed {
+		if om != nil {
+			om.Arm()
+		}
+		e.orderLog.Warnf("KILL SWITCH RELEASED at %s - orders accepted again", time.Now().Format("2006-01-02 15:04:05.000"))
+	}
+	if breaker {
+		e.orderLog.Warnf("LOSS STREAK BREAKER RELEASED at %s - strategy orders accepted again", time.Now().Format("2006-01-02 15:04:05.000"))
+	}
+	e.emit(Event{Kind: EventArmed, Message: "Trading re-armed"})
+	return nil
+}
+
+// KillSwitchEngaged reports whether KillSwitch has run without a later Arm
+func (e *Engine) KillSwitchEngaged() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.killed
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"errors"
+	"time"
+)
+
+// IsLiveAccount reports whether the engine is connected to the live Tradovate
+// environment. Demo data never is, whatever the config names.
+func (e *Engine) IsLiveAccount() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.connected && e.tm != nil && e.cfg != nil && e.cfg.Tradovate.Environment.IsLive()
+}
+
+// LiveConfirmed reports whether ConfirmLive has run
+func (e *Engine) LiveConfirmed() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.liveConfirmed
+}
+
+// LiveConfirmPending reports whether a live connection refuses orders until
+// ConfirmLive
+func (e *Engine) LiveConfirmPending() bool {
+	om := e.OrderManager()
+	return om != nil && om.LiveConfirmPending()
+}
+
+// ConfirmLive acknowledges live trading so the live account accepts orders.
+// The acknowledgment lasts until the engine exits, across reconnects.
+func (e *Engine) ConfirmLive() error {
+	if !e.IsLiveAccount() {
+		return errors.New("not connected to a live account")
+	}
+	e.mu.Lock()
+	confirmed := e.liveConfirmed
+	e.liveConfirmed = true
+	om := e.om
+	e.mu.Unlock()
+	if confirmed {
+		return errors.New("live trading is already confirmed")
+	}
+
+	om.ConfirmLive()
+	e.orderLog.Warnf("LIVE TRADING CONFIRMED at %s - orders go to the live account", time.Now().Format("2006-01-02 15:04:05.000"))
+	return nil
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// LogTargets are the logs whose level can be set, as named in config and :loglevel
+var LogTargets = []string{"main", "order", "strategy"}
+
+// SetLogLevel changes the lowest level a log keeps. The strategy target also
+// covers every instance's own log.
+func (e *Engine) SetLogLevel(target string, level logger.LogLevel) error {
+	switch target {
+	case "main":
+		e.mainLog.SetMinLevel(level)
+	case "order":
+		e.orderLog.SetMinLevel(level)
+	case "strategy":
+		e.strategyLog.SetMinLevel(level)
+		for _, inst := range e.Strategies() {
+			inst.Log.SetMinLevel(level)
+		}
+	default:
+		return fmt.Errorf("unknown log target %q (use main, order or strategy)", target)
+	}
+	return nil
+}
+
+// LogLevel returns the lowest level a log keeps
+func (e *Engine) LogLevel(target string) (logger.LogLevel, error) {
+	switch target {
+	case "main":
+		return e.mainLog.MinLevel(), nil
+	case "order":
+		return e.orderLog.MinLevel(), nil
+	case "strategy":
+		return e.strategyLog.MinLevel(), nil
+	}
+	return "", fmt.Errorf("unknown log target %q (use main, order or strategy)", target)
+}
+
+// ApplyLogging sets each log to its configured level. Targets left empty keep
+// their current level.
+func (e *Engine) ApplyLogging(cfg config.LoggingConfig) error {
+	for _, target := range LogTargets {
+		name := cfg.Levels()[target]
+		if name == "" {
+			continue
+		}
+		level, err := logger.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("logging.%s: %w", target, err)
+		}
+		e.SetLogLevel(target, level)
+	}
+	return nil
+}
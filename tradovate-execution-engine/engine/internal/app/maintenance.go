@@ -0,0 +1,196 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/schedule"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// DefaultStaleQuoteTimeout is how long a live strategy may go without a quote
+// when Maintenance.StaleQuoteSeconds is zero
+const DefaultStaleQuoteTimeout = 2 * time.Minute
+
+// reconnectRetryInterval spaces the reconnect attempts after a maintenance window
+const reconnectRetryInterval = 30 * time.Second
+
+// InMaintenance reports whether the connected environment is in one of its
+// maintenance windows
+func (e *Engine) InMaintenance() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.inMaintenance
+}
+
+// IsStale reports whether the strategy is paused because its quotes stopped
+func (r *StrategyRuntime) IsStale() bool {
+	return r.stale.Load()
+}
+
+// tradingHeld reports whether something other than the trading schedule keeps
+// inst disabled: the order throttle, a maintenance window, a news lockout,
+// stale quotes or a position drift
+func (e *Engine) tradingHeld(inst *StrategyInstance) bool {
+	return inst.Runtime.IsThrottled() || inst.Runtime.IsStale() || inst.Runtime.IsDrifted() || e.InMaintenance() || e.InNewsLockout()
+}
+
+// applyMaintenance takes the maintenance windows of a reloaded config
+func (e *Engine) applyMaintenance(cfg *config.Config) {
+	windows, err := schedule.NewMaintenanceWindows(cfg.Maintenance, cfg.Tradovate.Environment)
+	if err != nil {
+		e.mainLog.Errorf("Maintenance windows not applied: %v", err)
+		return
+	}
+	e.mu.Lock()
+	e.maintenance = windows
+	e.mu.Unlock()
+}
+
+// CheckMaintenance pauses running strategies while the connected environment
+// is in a maintenance window and, outside one, pauses each strategy whose
+// quotes stopped until they resume. Call it periodically; it returns true once
+// a window has ended or a connection failed to send and Reconnect should be
+// called, and again every reconnectRetryInterval until Reconnect succeeds.
+func (e *Engine) CheckMaintenance(now time.Time) (reconnect bool) {
+	e.mu.Lock()
+	windows, connected := e.maintenance, e.connected
+	active := connected && windows.Active(now)
+	began, ended := active && !e.inMaintenance, !active && e.inMaintenance
+	e.inMaintenance = active
+	if ended {
+		e.reconnectDue = now
+	}
+	reconnect = !active && !e.reconnectDue.IsZero() && !now.Before(e.reconnectDue)
+	if reconnect {
+		e.reconnectDue = now.Add(reconnectRetryInterval)
+	}
+	mdClient, tradingClient := e.mdClient, e.tradingClient
+	e.mu.Unlock()
+
+	// Dropped connections are expected until the window ends
+	if began || ended {
+		for _, c := range []*tradovate.TradovateWebSocketClient{mdClient, tradingClient} {
+			if c != nil {
+				c.SetExpectDown(began)
+			}
+		}
+	}
+
+	switch {
+	case began:
+		e.mainLog.Warnf("Tradovate maintenance window (%s) - strategies paused until it ends", windows)
+		for _, inst := range e.Strategies() {
+			if inst.Runtime.Status() != StrategyRunning {
+				continue
+			}
+			inst.Runtime.stale.Store(true)
+			if s, ok := inst.Strategy().(interface{ SetEnabled(bool) }); ok {
+				s.SetEnabled(false)
+			}
+			inst.Log.Warn("Maintenance window - strategy paused, market data stale until reconnected")
+		}
+		e.emit(Event{Kind: EventMaintenance, Message: fmt.Sprintf("Maintenance window began (%s) - strategies paused", windows)})
+	case ended:
+		e.mainLog.Info("Maintenance window over - reconnecting")
+		e.emit(Event{Kind: EventMaintenance, Message: "Maintenance window over - reconnecting"})
+	case connected && !active:
+		e.checkStaleQuotes(now)
+	}
+	return reconnect
+}
+
+// checkStaleQuotes pauses each live strategy that has had no quote within the
+// stale quote timeout and enables it again once quotes resume
+func (e *Engine) checkStaleQuotes(now time.Time) {
+	timeout := DefaultStaleQuoteTimeout
+	if cfg := e.Config(); cfg != nil && cfg.Maintenance.StaleQuoteSeconds != 0 {
+		timeout = time.Duration(cfg.Maintenance.StaleQuoteSeconds) * time.Second
+	}
+	if timeout < 0 {
+		return
+	}
+
+	for _, inst := range e.Strategies() {
+		rt := inst.Runtime
+		last := rt.lastQuote.Load()
+		if rt.Status() != StrategyRunning || !rt.IsLive() || last == 0 {
+			continue
+		}
+		silent := now.Sub(time.Unix(0, last))
+		s, canEnable := inst.Strategy().(interface{ SetEnabled(bool) })
+
+		if silent >= timeout && !rt.IsStale() {
+			rt.stale.Store(true)
+			if canEnable {
+				s.SetEnabled(false)
+			}
+			inst.Log.Errorf("No quotes for %s in %s - strategy paused until data resumes", inst.Symbol(), silent.Round(time.Second))
+			e.emit(Event{Kind: EventDataStale, StrategyID: inst.ID, Message: fmt.Sprintf("%s paused: no quotes for %s", inst.ID, inst.Symbol())})
+		} else if silent < timeout && rt.IsStale() {
+			rt.stale.Store(false)
+			inst.Log.Infof("Quotes for %s resumed", inst.Symbol())
+			if canEnable && rt.IsReady() && !e.tradingHeld(inst) && e.OrderManager().GetSchedule().InSession(now) {
+				s.SetEnabled(true)
+				inst.Log.Info("Strategy enabled for LIVE trading")
+			}
+			e.emit(Event{Kind: EventDataStale, StrategyID: inst.ID, Message: fmt.Sprintf("%s resumed: quotes for %s are back", inst.ID, inst.Symbol())})
+		}
+	}
+}
+
+// sendFailed has the next CheckMaintenance reconnect after a connection could
+// not write a frame. Failures inside a maintenance window wait for its end.
+func (e *Engine) sendFailed(connection string, err error) {
+	e.mu.Lock()
+	due := e.connected && !e.inMaintenance && e.reconnectDue.IsZero()
+	if due {
+		e.reconnectDue = time.Now()
+	}
+	e.mu.Unlock()
+	if due {
+		e.mainLog.Errorf("%s connection failed to send (%v) - reconnecting", connection, err)
+	}
+}
+
+// Reconnect drops the connection and connects again with the same config and
+// data source, then restarts the strategies that were running so they warm up
+// from fresh history. Strategies that could not be restarted because the
+// connect failed are restarted by the next Reconnect that succeeds.
+func (e *Engine) Reconnect() error {
+	e.mu.RLock()
+	cfg, demo, resume := e.cfg, e.closeDemo != nil, e.resume
+	e.mu.RUnlock()
+	if cfg == nil {
+		return errors.New("never connected")
+	}
+
+	for _, inst := range e.Strategies() {
+		if s := inst.Runtime.Status(); s == StrategyRunning || s == StrategyStarting {
+			resume = append(resume, inst.ID)
+		}
+	}
+	e.Disconnect()
+
+	connect := e.Connect
+	if demo {
+		connect = e.ConnectDemo
+	}
+	if err := connect(cfg); err != nil {
+		e.mu.Lock()
+		e.resume = resume
+		e.reconnectDue = time.Now().Add(reconnectRetryInterval)
+		e.mu.Unlock()
+		e.mainLog.Errorf("Reconnect failed, retrying in %s: %v", reconnectRetryInterval, err)
+		return err
+	}
+
+	for _, id := range resume {
+		if err := e.StartStrategy(id); err != nil {
+			e.mainLog.Errorf("Strategy %s not restarted after reconnect: %v", id, err)
+		}
+	}
+	return nil
+}
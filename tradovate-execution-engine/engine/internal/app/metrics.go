@@ -0,0 +1,94 @@
+package app
+
+import (
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/metrics"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// metricsCloseTimeout bounds how long Shutdown waits for scrapes in progress
+const metricsCloseTimeout = 2 * time.Second
+
+// startMetrics opens the metrics listener if it is enabled and not already
+// running. A listener that cannot bind is logged and left off; trading goes on.
+func (e *Engine) startMetrics(cfg config.MetricsConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.metricsServer != nil {
+		return
+	}
+
+	addr := cfg.Addr
+	if addr == "" {
+		addr = config.DefaultMetricsAddr
+	}
+	srv, err := metrics.Serve(addr, metrics.Default)
+	if err != nil {
+		e.mainLog.Errorf("Metrics disabled: %v", err)
+		return
+	}
+	e.metricsServer = srv
+	e.registerEngineGauges()
+	e.mainLog.Infof("Metrics available at http://%s/metrics", srv.Addr())
+}
+
+// registerEngineGauges adds the gauges read from whichever session is current
+func (e *Engine) registerEngineGauges() {
+	subscriptions := func(get func() *tradovate.DataSubscriber) func() float64 {
+		return func() float64 {
+			if s := get(); s != nil {
+				return float64(len(s.GetActiveSubscriptions()))
+			}
+			return 0
+		}
+	}
+	metrics.Default.GaugeFunc("engine_active_subscriptions", "Active market data and user sync subscriptions",
+		subscriptions(e.MarketData), "connection", "md")
+	metrics.Default.GaugeFunc("engine_active_subscriptions", "Active market data and user sync subscriptions",
+		subscriptions(e.Trading), "connection", "trading")
+
+	metrics.Default.GaugeFunc("engine_md_clock_skew_seconds", "Market data timestamps minus local receive time, smoothed", func() float64 {
+		if s := e.MarketData(); s != nil {
+			return s.FeedClock().Skew().Seconds()
+		}
+		return 0
+	})
+
+	metrics.Default.GaugeFunc("engine_token_expiry_seconds", "Seconds until the access token expires (0 when logged out)", func() float64 {
+		if tm := e.TokenManager(); tm != nil {
+			return tm.ExpiresIn().Seconds()
+		}
+		return 0
+	})
+}
+
+// stopMetrics closes the metrics listener, if one is running
+func (e *Engine) stopMetrics() {
+	e.mu.Lock()
+	srv := e.metricsServer
+	e.metricsServer = nil
+	e.mu.Unlock()
+	if srv == nil {
+		return
+	}
+
+	if err := srv.Close(metricsCloseTimeout); err != nil {
+		e.mainLog.Warnf("Metrics listener: %v", err)
+	}
+	e.mainLog.Info("Metrics listener closed")
+}
+
+// MetricsAddr returns the address the metrics listener is bound to, or "" when it is off
+func (e *Engine) MetricsAddr() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.metricsServer == nil {
+		return ""
+	}
+	return e.metricsServer.Addr()
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/schedule"
+)
+
+// applyNews takes the news calendar of cfg. A calendar that cannot be read is
+// logged and the previous one kept.
+func (e *Engine) applyNews(cfg *config.Config) {
+	calendar, err := schedule.NewNewsCalendar(cfg.News, filepath.Dir(config.GetConfigPath()))
+	if err != nil {
+		e.mainLog.Errorf("News calendar not applied: %v", err)
+		return
+	}
+	e.mu.Lock()
+	e.news = calendar
+	e.mu.Unlock()
+
+	if calendar.Enabled() {
+		e.mainLog.Infof("News calendar: %s", calendar)
+		if next, ok := calendar.Next(time.Now()); ok {
+			e.mainLog.Infof("Next news lockout: %s on %s", next, next.Start.Format("2006-01-02"))
+		}
+	}
+}
+
+// NewsLockout returns the news window trading is locked out for, if any
+func (e *Engine) NewsLockout() (schedule.NewsWindow, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.newsLockout, e.inNewsLockout
+}
+
+// InNewsLockout reports whether strategies are paused for a news window
+func (e *Engine) InNewsLockout() bool {
+	_, held := e.NewsLockout()
+	return held
+}
+
+// CheckNews flattens and pauses running strategies when a news lockout begins
+// and enables them again once it ends. flatten false pauses without cancelling
+// orders or closing positions. Call it periodically; it reports whether a
+// lockout began or ended on this call.
+func (e *Engine) CheckNews(now time.Time, flatten bool) (began, ended bool) {
+	e.mu.Lock()
+	window, active := e.news.Active(now)
+	active = active && e.connected && !now.Before(e.newsOverride)
+	began, ended = active && !e.inNewsLockout, !active && e.inNewsLockout
+	previous := e.newsLockout
+	e.inNewsLockout = active
+	if active {
+		e.newsLockout = window
+	}
+	connected := e.connected
+	e.mu.Unlock()
+
+	switch {
+	case began:
+		e.beginNewsLockout(window, flatten)
+	case ended && connected:
+		e.endNewsLockout(now, fmt.Sprintf("News lockout over (%s)", previous.Name))
+	}
+	return began, ended
+}
+
+// beginNewsLockout pauses running strategies and, when flatten is set, cancels
+// working orders and closes every position
+func (e *Engine) beginNewsLockout(window schedule.NewsWindow, flatten bool) {
+	until := window.End.Format("15:04")
+	e.mainLog.Warnf("News lockout: %s - strategies paused until %s", window, until)
+	for _, inst := range e.Strategies() {
+		if inst.Runtime.Status() != StrategyRunning {
+			continue
+		}
+		if s, ok := inst.Strategy().(interface{ SetEnabled(bool) }); ok {
+			s.SetEnabled(false)
+		}
+		inst.Log.Warnf("News lockout (%s) - strategy paused until %s", window.Name, until)
+	}
+
+	if om := e.OrderManager(); flatten && om != nil {
+		if n, err := om.CancelWorkingOrders(); err != nil {
+			e.orderLog.Errorf("NEWS LOCKOUT - %v", err)
+		} else if n > 0 {
+			e.orderLog.Infof("NEWS LOCKOUT - cancelled %d working orders", n)
+		}
+		if e.HasOpenPositions() {
+			if err := om.FlattenPositions(); err != nil {
+				e.mainLog.Errorf("News lockout flatten failed: %v", err)
+			} else {
+				e.orderLog.Infof("NEWS LOCKOUT - positions closed before %s", window.Name)
+			}
+		}
+	}
+	e.emit(Event{Kind: EventNewsLockout, Message: fmt.Sprintf("News lockout for %s until %s - strategies paused", window.Name, until)})
+}
+
+// endNewsLockout enables the running strategies that nothing else holds
+func (e *Engine) endNewsLockout(now time.Time, message string) {
+	om := e.OrderManager()
+	if om == nil {
+		return
+	}
+	e.mainLog.Info(message + " - strategies resume")
+	sched := om.GetSchedule()
+	for _, inst := range e.Strategies() {
+		if inst.Runtime.Status() != StrategyRunning || !inst.Runtime.IsReady() || e.tradingHeld(inst) || !sched.InSession(now) {
+			continue
+		}
+		if s, ok := inst.Strategy().(interface{ SetEnabled(bool) }); ok {
+			s.SetEnabled(true)
+			inst.Log.Info("News lockout over - strategy enabled")
+		}
+	}
+	e.emit(Event{Kind: EventNewsLockout, Message: message})
+}
+
+// OverrideNewsLockout lifts the news lockout in force until its window ends, so
+// strategies trade through it. Positions already closed stay closed.
+func (e *Engine) OverrideNewsLockout() (schedule.NewsWindow, error) {
+	e.mu.Lock()
+	window, held := e.newsLockout, e.inNewsLockout
+	if held {
+		e.newsOverride = window.End
+		e.inNewsLockout = false
+	}
+	e.mu.Unlock()
+	if !held {
+		return schedule.NewsWindow{}, errors.New("no news lockout in force")
+	}
+
+	e.mainLog.Warnf("News lockout for %s overridden by the user - trading resumes before %s", window.Name, window.End.Format("15:04"))
+	e.endNewsLockout(time.Now(), fmt.Sprintf("News lockout for %s overridden", window.Name))
+	return window, nil
+}
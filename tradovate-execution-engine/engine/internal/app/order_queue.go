@@ -0,0 +1,25 @@
+package app
+
+import "time"
+
+// CheckOrderQueue sends the strategy orders queued while Tradovate could not
+// be reached, once the trading connection is up, and drops those whose signal
+// is older than Risk.OrderQueueSeconds. Call it periodically. It returns how
+// many orders were dropped.
+func (e *Engine) CheckOrderQueue(now time.Time) int {
+	e.mu.RLock()
+	om, client, up := e.om, e.tradingClient, e.connected && !e.inMaintenance
+	e.mu.RUnlock()
+	if om == nil {
+		return 0
+	}
+	if !up || (client != nil && !client.IsConnected()) {
+		return om.DropExpiredOrders(now)
+	}
+
+	sent, dropped := om.RetryQueuedOrders(now)
+	if sent > 0 {
+		e.orderLog.Infof("Sent %d queued order(s) now Tradovate can be reached", sent)
+	}
+	return dropped
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/execution"
+)
+
+// DefaultPositionCheckInterval spaces the position reconciliation passes when
+// Risk.PositionCheckSeconds is zero
+const DefaultPositionCheckInterval = 30 * time.Second
+
+// driftSettle is how long a drift must last before it is acted on, so a fill
+// the broker reports before or after the order update is not taken for one. A
+// pass after connecting waits as long for the user sync.
+const driftSettle = 5 * time.Second
+
+// String describes the drift for the logs
+func (d PositionDrift) String() string {
+	return fmt.Sprintf("%s on %s: strategy %+d, orders %+d, broker %+d", d.StrategyID, d.Symbol, d.Strategy, d.Orders, d.Broker)
+}
+
+// IsDrifted reports whether the strategy is paused because its position drifted
+// from the broker's
+func (r *StrategyRuntime) IsDrifted() bool {
+	return r.drifted.Load()
+}
+
+// CheckPositionDrift compares the position each started strategy declares, and
+// the order manager's view, with the broker's NetPos for its symbol. A drift
+// found on one pass is checked again driftSettle later and, if it is still the
+// same, the strategy takes the broker's position when Risk.AutoSyncPositions
+// is set or is paused until ResyncPosition otherwise. Call it periodically; a
+// pass runs every Risk.PositionCheckSeconds and once shortly after each
+// connect. It returns the drifts acted on by this call.
+func (e *Engine) CheckPositionDrift(now time.Time) []PositionDrift {
+	cfg := e.Config()
+	interval := DefaultPositionCheckInterval
+	if cfg != nil && cfg.Risk.PositionCheckSeconds != 0 {
+		interval = time.Duration(cfg.Risk.PositionCheckSeconds) * time.Second
+	}
+	if cfg == nil || interval < 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	om, pt, seen := e.om, e.pt, e.driftSeen
+	due := e.connected && !e.inMaintenance && om != nil && pt != nil && !now.Before(e.driftCheckDue)
+	if due {
+		e.driftCheckDue = now.Add(interval)
+	}
+	e.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	broker := pt.GetPLSummary()
+	found := make(map[string]PositionDrift)
+	var acted []PositionDrift
+	for _, inst := range e.Strategies() {
+		status := inst.Runtime.Status()
+		if (status != StrategyRunning && status != StrategyStarting) || inst.Runtime.IsDrifted() {
+			continue
+		}
+		syncer, ok := inst.Strategy().(execution.PositionSyncer)
+		if !ok {
+			continue
+		}
+		held, pending := syncer.StrategyPosition()
+		if pending {
+			continue
+		}
+		symbol := inst.Symbol()
+		d := PositionDrift{StrategyID: inst.ID, Symbol: symbol, Strategy: held, Orders: om.GetPosition(symbol).NetPos, Broker: broker[symbol].NetPos}
+		if d.Strategy == d.Broker && d.Orders == d.Broker {
+			continue
+		}
+		if prev, ok := seen[inst.ID]; !ok || prev != d {
+			found[inst.ID] = d
+			inst.Log.Debugf("Position drift suspected (%s), checking again in %s", d, driftSettle)
+			continue
+		}
+		if d.Strategy == d.Broker {
+			// Only the order manager disagrees; the strategy trades the right size
+			e.orderLog.Warnf("Position drift: %s - the order manager disagrees with the broker", d)
+			continue
+		}
+		e.resolveDrift(inst, syncer, d, cfg.Risk.AutoSyncPositions)
+		acted = append(acted, d)
+	}
+
+	e.mu.Lock()
+	e.driftSeen = found
+	if len(found) > 0 && e.driftCheckDue.After(now.Add(driftSettle)) {
+		e.driftCheckDue = now.Add(driftSettle)
+	}
+	e.mu.Unlock()
+	return acted
+}
+
+// resolveDrift has the strategy take the broker's position when autoSync is
+// set and it can hold it, and pauses the strategy otherwise
+func (e *Engine) resolveDrift(inst *StrategyInstance, syncer execution.PositionSyncer, d PositionDrift, autoSync bool) {
+	e.mainLog.Warnf("Position drift: %s", d)
+	if autoSync {
+		syncer.SyncPosition(d.Broker)
+		if held, _ := syncer.StrategyPosition(); held == d.Broker {
+			e.emit(Event{Kind: EventPositionDrift, StrategyID: inst.ID, Message: fmt.Sprintf("%s position synced to the broker's %+d (was %+d)", inst.ID, d.Broker, d.Strategy)})
+			return
+		}
+		inst.Log.Errorf("Position drift - the strategy cannot hold %+d contracts", d.Broker)
+	}
+
+	inst.Runtime.drifted.Store(true)
+	if s, ok := inst.Strategy().(interface{ SetEnabled(bool) }); ok {
+		s.SetEnabled(false)
+	}
+	inst.Log.Errorf("Position drift (strategy %+d, broker %+d) - strategy paused until :resync %s", d.Strategy, d.Broker, inst.ID)
+	e.emit(Event{Kind: EventPositionDrift, StrategyID: inst.ID, Message: fmt.Sprintf("%s paused: position %+d, broker has %+d", inst.ID, d.Strategy, d.Broker)})
+}
+
+// ResyncPosition is the operator's confirmation for a strategy paused by a
+// position drift: the strategy takes the broker's current position and is
+// enabled again unless something else holds it. It fails, leaving the strategy
+// paused, when the strategy cannot hold that position, e.g. a size other than
+// its quantity; close or resize the position by hand first. It returns the
+// position taken.
+func (e *Engine) ResyncPosition(id string) (int, error) {
+	inst, err := e.instance(id)
+	if err != nil {
+		return 0, err
+	}
+	if !inst.Runtime.IsDrifted() {
+		return 0, fmt.Errorf("strategy %s is not paused for a position drift", id)
+	}
+	e.mu.RLock()
+	pt, om := e.pt, e.om
+	e.mu.RUnlock()
+	if pt == nil || om == nil {
+		return 0, errors.New("must be connected to resync positions")
+	}
+	syncer, ok := inst.Strategy().(execution.PositionSyncer)
+	if !ok {
+		return 0, fmt.Errorf("strategy %s does not track a position", id)
+	}
+
+	netPos := pt.GetPLSummary()[inst.Symbol()].NetPos
+	syncer.SyncPosition(netPos)
+	if held, _ := syncer.StrategyPosition(); held != netPos {
+		return 0, fmt.Errorf("strategy %s cannot hold %+d contracts of %s", id, netPos, inst.Symbol())
+	}
+	inst.Runtime.drifted.Store(false)
+	inst.Log.Warnf("Position resynced by the user at %+d", netPos)
+	e.emit(Event{Kind: EventPositionDrift, StrategyID: inst.ID, Message: fmt.Sprintf("%s resynced at %+d", inst.ID, netPos)})
+
+	if inst.Runtime.Status() == StrategyRunning && inst.Runtime.IsReady() && !e.tradingHeld(inst) && om.GetSchedule().InSession(time.Now()) {
+		if s, ok := inst.Strategy().(interface{ SetEnabled(bool) }); ok {
+			s.SetEnabled(true)
+			inst.Log.Info("Strategy enabled for LIVE trading")
+		}
+	}
+	return netPos, nil
+}
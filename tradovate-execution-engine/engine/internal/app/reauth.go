@@ -0,0 +1,133 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/auth"
+)
+
+// TokenWarning is how long before the access token expires the UI counts down
+const TokenWarning = 10 * time.Minute
+
+// TokenExpiry returns the time left before the session's access token
+// expires, negative once it has. ok is false without a Tradovate session.
+func (e *Engine) TokenExpiry() (left time.Duration, ok bool) {
+	tm := e.TokenManager()
+	if tm == nil {
+		return 0, false
+	}
+	return tm.TimeToExpiry()
+}
+
+// TokenRenewalError returns why the session's token could not be renewed, nil
+// while renewals succeed
+func (e *Engine) TokenRenewalError() error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.renewalErr
+}
+
+// CheckTokenRenewal returns a renewal failure the first time it is seen, so it
+// is reported once until a renewal or Reauthenticate clears it
+func (e *Engine) CheckTokenRenewal() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.renewalErr == nil || e.renewalReported {
+		return nil
+	}
+	e.renewalReported = true
+	return e.renewalErr
+}
+
+// tokenRenewalFailed records a failed renewal of tm, the session's token manager
+func (e *Engine) tokenRenewalFailed(tm *auth.TokenManager, err error) {
+	e.mu.Lock()
+	if e.tm != tm {
+		e.mu.Unlock()
+		return
+	}
+	first := e.renewalErr == nil
+	e.renewalErr = err
+	e.mu.Unlock()
+
+	if !first {
+		return
+	}
+	msg := "Access token could not be renewed - :reauth to log in again"
+	if left, ok := tm.TimeToExpiry(); ok {
+		msg = fmt.Sprintf("Access token could not be renewed and expires in %s - :reauth to log in again", left.Round(time.Second))
+	}
+	e.mainLog.Error(msg)
+	e.emit(Event{Kind: EventTokenRenewal, Message: msg})
+}
+
+// tokenRenewed clears a renewal failure once tm renews its token
+func (e *Engine) tokenRenewed(tm *auth.TokenManager) {
+	e.mu.Lock()
+	recovered := e.tm == tm && e.renewalErr != nil
+	if recovered {
+		e.renewalErr = nil
+		e.renewalReported = false
+	}
+	e.mu.Unlock()
+
+	if recovered {
+		e.mainLog.Info("Access token renewed again")
+	}
+}
+
+// Reauthenticate logs in to Tradovate again with the configured credentials
+// and authorizes both WebSockets with the new session, keeping their
+// subscriptions. A login as another user is refused: the session and its
+// connections are left as they were and a clean reconnect is needed.
+func (e *Engine) Reauthenticate() error {
+	e.mu.RLock()
+	tm, mdClient, tradingClient := e.tm, e.mdClient, e.tradingClient
+	e.mu.RUnlock()
+	if tm == nil {
+		return fmt.Errorf("not connected to Tradovate")
+	}
+
+	if err := tm.Reauthenticate(); err != nil {
+		if errors.Is(err, auth.ErrUserChanged) {
+			e.mainLog.Errorf("Re-authentication refused, %v - disconnect and connect again", err)
+			return fmt.Errorf("%w - disconnect and connect again", err)
+		}
+		e.mainLog.Errorf("Re-authentication failed: %v", err)
+		return fmt.Errorf("re-authentication failed: %w", err)
+	}
+
+	accessToken, err := tm.GetAccessToken()
+	if err != nil {
+		return err
+	}
+	mdToken, err := tm.GetMDAccessToken()
+	if err != nil {
+		return err
+	}
+	if mdClient != nil {
+		if err := mdClient.Reauthorize(mdToken); err != nil {
+			e.mainLog.Errorf("Market data WebSocket not re-authorized: %v", err)
+			return fmt.Errorf("market data WebSocket not re-authorized: %w", err)
+		}
+	}
+	if tradingClient != nil {
+		if err := tradingClient.Reauthorize(accessToken); err != nil {
+			e.mainLog.Errorf("Trading WebSocket not re-authorized: %v", err)
+			return fmt.Errorf("trading WebSocket not re-authorized: %w", err)
+		}
+	}
+
+	e.mu.Lock()
+	if e.tm == tm {
+		e.renewalErr = nil
+		e.renewalReported = false
+	}
+	e.mu.Unlock()
+	if left, ok := tm.TimeToExpiry(); ok {
+		e.mainLog.Infof("Re-authenticated, WebSockets authorized with the new session (expires in %s)", left.Round(time.Minute))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// startRecording gives each connection its own recording. A recording that cannot
+// be opened is logged and skipped; the session goes on unrecorded.
+func (e *Engine) startRecording(cfg config.RecordingConfig, mdClient, tradingClient *tradovate.TradovateWebSocketClient) {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = tradovate.RecordingDir()
+	}
+	for name, client := range map[string]*tradovate.TradovateWebSocketClient{"md": mdClient, "trading": tradingClient} {
+		rec, err := tradovate.NewRecorder(dir, name)
+		if err != nil {
+			e.mainLog.Errorf("Recording disabled for %s connection: %v", name, err)
+			continue
+		}
+		client.SetRecorder(rec)
+		e.mainLog.Infof("Recording %s WebSocket frames to %s", name, rec.Path())
+	}
+}
+
+// MetricsRecording reports whether running strategies write their metrics to CSV
+func (e *Engine) MetricsRecording() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.recordingMetrics
+}
+
+// SetMetricsRecording turns the metrics CSVs of running strategies on or off.
+// Each run writes external/metrics/<name>-<id>_<date>.csv, a row per live bar;
+// turning recording off flushes and closes every file.
+func (e *Engine) SetMetricsRecording(on bool) {
+	e.mu.Lock()
+	e.recordingMetrics = on
+	e.mu.Unlock()
+
+	if on {
+		e.mainLog.Infof("Recording strategy metrics to %s", execution.MetricsDir())
+		return
+	}
+	for _, inst := range e.Strategies() {
+		inst.mu.RLock()
+		run := inst.run
+		inst.mu.RUnlock()
+		if run != nil {
+			e.closeMetrics(inst, run)
+		}
+	}
+	e.mainLog.Info("Strategy metrics recording stopped")
+}
+
+// recordMetrics appends the strategy's metrics after a live bar, opening the
+// run's CSV on its first row
+func (e *Engine) recordMetrics(inst *StrategyInstance, run *strategyRun, bar marketdata.Bar) {
+	if !e.MetricsRecording() {
+		return
+	}
+	row := execution.MetricsRow{Timestamp: bar.Timestamp, Metrics: run.strategy.GetMetrics()}
+	if s, ok := run.strategy.(execution.SignalReporter); ok {
+		row.Signal = s.LastSignal()
+	}
+	if pt := e.Portfolio(); pt != nil {
+		row.Position = pt.GetPLSummary()[inst.Symbol()].NetPos
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if run.metricsFailed {
+		return
+	}
+	if run.metrics == nil {
+		rec, err := execution.NewMetricsRecorder(execution.MetricsDir(), inst.Name+"-"+inst.ID, time.Now())
+		if err != nil {
+			run.metricsFailed = true
+			inst.Log.Errorf("Metrics recording disabled: %v", err)
+			return
+		}
+		run.metrics = rec
+		inst.Log.Infof("Recording metrics to %s", rec.Path())
+	}
+	if err := run.metrics.Record(row); err != nil {
+		run.metricsFailed = true
+		inst.Log.Errorf("Metrics recording stopped: %v", err)
+	}
+}
+
+// closeMetrics flushes and closes the run's metrics CSV, if it has one
+func (e *Engine) closeMetrics(inst *StrategyInstance, run *strategyRun) {
+	inst.mu.Lock()
+	rec := run.metrics
+	run.metrics = nil
+	inst.mu.Unlock()
+	if rec == nil {
+		return
+	}
+	if err := rec.Close(); err != nil {
+		inst.Log.Errorf("Metrics %s incomplete: %v", rec.Path(), err)
+		return
+	}
+	inst.Log.Infof("Metrics saved: %d rows in %s", rec.Rows(), rec.Path())
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/risk"
+)
+
+// riskCheckInterval is how often the risk supervisor compares the daily PnL
+// with the loss limit
+const riskCheckInterval = 250 * time.Millisecond
+
+// startRiskSupervisor enforces the daily loss limit of om's risk manager for
+// the connection, stopping this engine's strategies and flattening through om
+func (e *Engine) startRiskSupervisor(om *execution.OrderManager) *risk.RiskSupervisor {
+	rs := risk.NewRiskSupervisor(om.GetRiskManager(), om, e, e.mainLog)
+	rs.AddStateHandler(func(state risk.SupervisorState) {
+		if state.Tripped {
+			e.emit(Event{Kind: EventDailyLossLimit, Message: fmt.Sprintf("Daily loss limit reached ($%.2f): %d strategies stopped, positions flattened",
+				state.DailyPnL, state.Stopped)})
+		}
+	})
+	rs.Start(riskCheckInterval)
+	return rs
+}
+
+// watchOrderThrottle disables a strategy whose orders om's risk manager refused
+// too often for coming too fast. It stays disabled until it is restarted.
+func (e *Engine) watchOrderThrottle(om *execution.OrderManager) {
+	om.GetRiskManager().AddThrottleHandler(func(origin string, violations int) {
+		inst := e.Strategy(origin)
+		if inst == nil {
+			return
+		}
+		inst.Runtime.throttled.Store(true)
+		if s, ok := inst.Strategy().(interface{ SetEnabled(bool) }); ok {
+			s.SetEnabled(false)
+		}
+		inst.Log.Errorf("!!! ORDER THROTTLE: %d orders refused for exceeding the order rate - strategy DISABLED, restart it to trade again !!!", violations)
+		e.emit(Event{Kind: EventOrderThrottle, StrategyID: origin,
+			Message: fmt.Sprintf("Strategy %s disabled after %d orders refused by the order throttle", origin, violations)})
+	})
+}
+
+// RiskState returns the daily loss state of the connection, zero when disconnected
+func (e *Engine) RiskState() risk.SupervisorState {
+	e.mu.RLock()
+	rs := e.riskSupervisor
+	e.mu.RUnlock()
+	if rs == nil {
+		return risk.SupervisorState{}
+	}
+	return rs.State()
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"time"
+
+	"tradovate-execution-engine/engine/internal/portfolio"
+)
+
+// SessionStart returns when the current session began: the first connection of
+// the trade date to this account, or when the trade date last rolled
+func (e *Engine) SessionStart() time.Time {
+	e.mu.RLock()
+	pt, session := e.pt, e.session
+	e.mu.RUnlock()
+	if pt != nil {
+		return pt.SessionAnchor().Start
+	}
+	return session.Start
+}
+
+// sessionAnchor returns the session a connection to accountID made at
+// connectedAt continues: the last connection's when it was to the same
+// account, otherwise a new one. A reconnect mid-day keeps the session realized
+// PnL; the tracker starts a new session once a later trade date is reported.
+func (e *Engine) sessionAnchor(accountID int, connectedAt time.Time) portfolio.SessionAnchor {
+	e.mu.RLock()
+	last := e.session
+	e.mu.RUnlock()
+	if last.Start.IsZero() || last.AccountID != accountID {
+		return portfolio.SessionAnchor{Start: connectedAt, AccountID: accountID}
+	}
+	if last.Anchored {
+		e.mainLog.Infof("Continuing session from %s - session realized PnL counts from $%.2f",
+			last.Start.Local().Format("15:04:05"), last.RealizedPnL)
+	}
+	return last
+}
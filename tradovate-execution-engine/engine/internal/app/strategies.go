@@ -0,0 +1,592 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+const (
+	// instanceLogSize is the number of entries kept in each instance's own log
+	instanceLogSize = 500
+
+	// warmUpTimeout bounds the whole paged history load before a strategy starts
+	warmUpTimeout = 2 * time.Minute
+)
+
+// AddStrategy creates a registered strategy with its default parameters,
+// applies any overrides and adds it as a new stopped instance. Instance IDs
+// are assigned in sequence starting at "1".
+func (e *Engine) AddStrategy(name string, params map[string]string) (*StrategyInstance, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := strconv.Itoa(e.nextInstanceID + 1)
+	log := logger.NewChildLogger(e.strategyLog, instanceLogSize, "["+id+"] ")
+
+	strat, err := execution.CreateStrategy(name, log)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, p := range strat.GetParams() {
+		values[p.Name] = p.Value
+	}
+	for k, v := range params {
+		if _, ok := values[k]; !ok {
+			return nil, fmt.Errorf("unknown parameter: %s", k)
+		}
+		values[k] = v
+	}
+	if err := execution.ValidateParams(strat, values); err != nil {
+		return nil, err
+	}
+
+	inst := &StrategyInstance{
+		ID:       id,
+		Name:     name,
+		Log:      log,
+		Runtime:  &StrategyRuntime{},
+		strategy: strat,
+		params:   values,
+	}
+	e.nextInstanceID++
+	e.instances[id] = inst
+	e.instanceOrder = append(e.instanceOrder, id)
+
+	log.Printf("Loaded strategy: %s", strat.Name())
+	return inst, nil
+}
+
+// CloneStrategy adds a new stopped instance of the strategy of id, with its
+// staged parameters and symbol in place of its own. The clone is created from
+// the registry like any other instance, so it shares no indicators, orders or
+// market data handlers with the original.
+func (e *Engine) CloneStrategy(id, symbol string) (*StrategyInstance, error) {
+	inst, err := e.instance(id)
+	if err != nil {
+		return nil, err
+	}
+	if symbol == "" {
+		return nil, errors.New("symbol required")
+	}
+	if _, ok := execution.FindParam(inst.strategy, "symbol"); !ok {
+		return nil, fmt.Errorf("strategy %s has no symbol parameter", id)
+	}
+	if err := e.ValidateSymbol(symbol); err != nil {
+		return nil, err
+	}
+
+	params := inst.Params()
+	params["symbol"] = symbol
+	clone, err := e.AddStrategy(inst.Name, params)
+	if err != nil {
+		return nil, fmt.Errorf("cannot clone strategy %s: %w", id, err)
+	}
+	clone.Log.Printf("Cloned from strategy %s for %s", id, symbol)
+	return clone, nil
+}
+
+// RemoveStrategy drops a stopped instance
+func (e *Engine) RemoveStrategy(id string) error {
+	inst, err := e.instance(id)
+	if err != nil {
+		return err
+	}
+	if status := inst.Runtime.Status(); status == StrategyRunning || status == StrategyStarting {
+		return fmt.Errorf("strategy %s is running, stop it first", id)
+	}
+
+	e.mu.Lock()
+	delete(e.instances, id)
+	for i, other := range e.instanceOrder {
+		if other == id {
+			e.instanceOrder = append(e.instanceOrder[:i], e.instanceOrder[i+1:]...)
+			break
+		}
+	}
+	e.mu.Unlock()
+
+	e.strategyLog.Printf("Removed strategy %s (%s)", id, inst.Name)
+	return nil
+}
+
+// Strategies returns every instance in the order they were added
+func (e *Engine) Strategies() []*StrategyInstance {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	list := make([]*StrategyInstance, 0, len(e.instanceOrder))
+	for _, id := range e.instanceOrder {
+		list = append(list, e.instances[id])
+	}
+	return list
+}
+
+// Strategy returns the instance with id, or nil
+func (e *Engine) Strategy(id string) *StrategyInstance {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.instances[id]
+}
+
+// instance looks up id, failing with a user-facing error
+func (e *Engine) instance(id string) (*StrategyInstance, error) {
+	if inst := e.Strategy(id); inst != nil {
+		return inst, nil
+	}
+	return nil, fmt.Errorf("no strategy with ID %s", id)
+}
+
+// SetParam stages a parameter value for the instance's next StartStrategy. The
+// value is checked against the param's schema now; constraints between params
+// are left to StartStrategy so they can be changed one at a time.
+func (e *Engine) SetParam(id, name, value string) error {
+	inst, err := e.instance(id)
+	if err != nil {
+		return err
+	}
+	if inst.Runtime.Status() == StrategyRunning {
+		return errors.New("cannot change parameters while strategy is running, stop it first")
+	}
+
+	param, ok := execution.FindParam(inst.strategy, name)
+	if !ok {
+		return fmt.Errorf("unknown parameter: %s", name)
+	}
+	if err := param.Validate(value); err != nil {
+		return err
+	}
+
+	inst.mu.Lock()
+	inst.params[name] = value
+	inst.mu.Unlock()
+
+	inst.Log.Printf("Parameter set: %s = %s", name, value)
+	return nil
+}
+
+// StartStrategy applies the instance's staged parameters, initialises it and
+// subscribes its quote and chart feeds. Two running instances may not trade
+// the same contract, since they would share one position.
+func (e *Engine) StartStrategy(id string) error {
+	inst, err := e.instance(id)
+	if err != nil {
+		return err
+	}
+
+	e.mu.RLock()
+	om, md, connected, resolver := e.om, e.mdSubscriber, e.connected, e.resolver
+	e.mu.RUnlock()
+
+	if om != nil && om.GetRiskManager().IsDailyLossExceeded() {
+		inst.Log.Error("Cannot start strategy: daily loss limit exceeded")
+		return errors.New("daily loss limit exceeded")
+	}
+	if status := inst.Runtime.Status(); status == StrategyRunning || status == StrategyStarting {
+		return fmt.Errorf("strategy %s is already running", id)
+	}
+	// A failed strategy is never restarted until someone has looked at it
+	if inst.Runtime.Status() == StrategyError {
+		return fmt.Errorf("strategy %s failed (%s), reset it before starting again", id, inst.Runtime.Failure())
+	}
+	if !connected {
+		return errors.New("must be connected to start strategy")
+	}
+
+	strat := inst.strategy
+	params := inst.Params()
+	if err := execution.ValidateParams(strat, params); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	for k, v := range params {
+		if err := strat.SetParam(k, v); err != nil {
+			return fmt.Errorf("failed to set param %s: %w", k, err)
+		}
+	}
+	if err := strat.Init(om); err != nil {
+		return fmt.Errorf("failed to initialize strategy: %w", err)
+	}
+
+	// Init may have resolved a product root to a concrete contract
+	symbol := params["symbol"]
+	for _, p := range strat.GetParams() {
+		if p.Name == "symbol" {
+			symbol = p.Value
+		}
+	}
+	if other := e.runningOn(symbol, id); other != nil {
+		strat.Reset()
+		return fmt.Errorf("strategy %s is already trading %s", other.ID, symbol)
+	}
+	// A mistyped contract is refused before anything is subscribed
+	if err := e.ValidateSymbol(symbol); err != nil {
+		strat.Reset()
+		return err
+	}
+	spec, err := StrategyBarSpec(strat)
+	if err != nil {
+		strat.Reset()
+		return err
+	}
+	// Orders on the symbol are this instance's until it stops
+	om.SetSymbolOrigin(symbol, id)
+	if p, ok := strat.(execution.EntryPolicyProvider); ok {
+		om.SetEntryPolicy(id, p.EntryPolicy())
+	}
+
+	run := &strategyRun{strategy: strat, chartParams: StrategyChartParams(symbol, spec), mode: execution.UpdateModeFor(strat)}
+	run.warmup = execution.NewStrategyWarmup(strat, func() { e.goLive(inst, run) })
+	run.warmup.SetReadyHandler(func() { e.strategyReady(inst, run) })
+	run.warmup.SetErrorHandler(func(err error) { go e.failStrategy(inst, run, err) })
+	if handler, ok := strat.(execution.QuoteHandler); ok && run.mode != execution.UpdateOnBarClose {
+		run.quotes = execution.NewQuoteQueue(handler, execution.DefaultQuoteQueueSize, func(err error) {
+			go e.failStrategy(inst, run, err)
+		})
+	}
+	run.barBuilder = marketdata.NewBarBuilderFor(spec, func(bar marketdata.Bar) {
+		fed := run.warmup.Fed()
+		run.warmup.OnLiveBar(bar)
+		if run.warmup.Fed() > fed {
+			e.recordMetrics(inst, run, bar)
+		}
+	})
+	run.barBuilder.SetGrace(marketdata.DefaultBarGrace)
+
+	inst.mu.Lock()
+	inst.symbol = symbol
+	inst.contractID = 0
+	inst.run = run
+	inst.mu.Unlock()
+
+	inst.Runtime.live.Store(false)
+	inst.Runtime.ready.Store(false)
+	inst.Runtime.throttled.Store(false)
+	inst.Runtime.stale.Store(false)
+	inst.Runtime.drifted.Store(false)
+	inst.Runtime.lastQuote.Store(0)
+	om.GetRiskManager().ResetOrderThrottle(id)
+	inst.Runtime.droppedQuotes.Store(0)
+	e.setStatus(inst, StrategyStarting)
+	inst.Log.Infof(">>> STRATEGY STARTED on %s (%s bars) <<<", symbol, spec)
+	if run.quotes != nil {
+		inst.Log.Infof("Live data: %s", run.mode)
+	}
+
+	go func() {
+		// Quotes for every subscribed contract share one feed; the ID tells them apart
+		contractID := 0
+		if resolver != nil {
+			if id, err := resolver.ContractID(symbol); err != nil {
+				inst.Log.Warnf("Contract ID lookup failed, matching quotes by name: %v", err)
+			} else {
+				contractID = id
+			}
+		}
+
+		// History beyond what the chart subscription brings is loaded first, in pages
+		if bars := execution.WarmupBarsFor(strat); bars > run.chartParams.TimeRange.AsMuchAsElements {
+			if err := e.warmUp(inst, run, md, bars); err != nil {
+				e.failStrategy(inst, run, err)
+				return
+			}
+		}
+
+		// Register before subscribing so no historical bar is missed
+		inst.mu.Lock()
+		inst.contractID = contractID
+		run.chartHandler = md.AddChartHandlerFor(symbol, func(update marketdata.ChartUpdate) {
+			e.handleChart(inst, run, md, update)
+		})
+		run.chartErrors = md.AddChartErrorHandlerFor(symbol, func(err *tradovate.ChartError) {
+			go e.failStrategy(inst, run, err)
+		})
+		run.quoteHandler = md.AddQuoteHandlerFor(contractID, func(quote marketdata.Quote) {
+			e.handleQuote(inst, run, quote)
+		})
+		// Live bars are built from quotes, so a refused subscription ends the run too
+		run.quoteErrors = md.AddRequestErrorHandlerFor(symbol, func(err *tradovate.WSError) {
+			go e.failStrategy(inst, run, err)
+		})
+		inst.mu.Unlock()
+
+		if err := md.SubscribeQuote(symbol); err != nil {
+			inst.Log.Errorf("Failed to subscribe to quotes: %v", err)
+		} else {
+			// The run holds this reference until endRun, or gives it straight
+			// back if it already ended
+			inst.mu.Lock()
+			run.quoteSymbol = symbol
+			ended := inst.run != run
+			if ended {
+				run.quoteSymbol = ""
+			}
+			inst.mu.Unlock()
+			if ended {
+				md.UnsubscribeQuote(symbol)
+			}
+		}
+		if err := md.SubscribeChart(run.chartParams); err != nil {
+			inst.Log.Errorf("Failed to get chart: %v", err)
+		}
+
+		// The chart may already have been refused
+		inst.mu.RLock()
+		current := inst.run == run
+		inst.mu.RUnlock()
+		if current {
+			e.setStatus(inst, StrategyRunning)
+		}
+
+		inst.Log.Debug("mdsubs: ", md.GetActiveSubscriptions())
+	}()
+	return nil
+}
+
+// warmUp loads bars of history in pages and feeds them to the run's strategy.
+// A request Tradovate refused is returned, since the chart subscription would
+// be refused too; after any other failure the strategy warms up from the chart
+// subscription's bars and live data instead.
+func (e *Engine) warmUp(inst *StrategyInstance, run *strategyRun, md *tradovate.DataSubscriber, bars int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), warmUpTimeout)
+	defer cancel()
+
+	inst.Log.Infof("Loading %d bars of history for warm-up", bars)
+	history, err := md.LoadHistory(ctx, tradovate.HistoryRequest{Params: run.chartParams, Bars: bars})
+	var chartErr *tradovate.ChartError
+	if errors.As(err, &chartErr) {
+		return chartErr
+	}
+	if err != nil {
+		inst.Log.Warnf("Warm-up history not loaded, continuing with the chart's bars: %v", err)
+		return nil
+	}
+	if len(history) == 0 {
+		return nil
+	}
+	run.warmup.Preload(history)
+	if err := run.warmup.Err(); err != nil {
+		return err
+	}
+	inst.Log.Infof("Warm-up loaded %d bars (%s to %s)", len(history), history[0].Timestamp, history[len(history)-1].Timestamp)
+	return nil
+}
+
+// failStrategy ends a run whose market data Tradovate refused or whose strategy
+// returned an error or panicked in a callback. The strategy is disabled and
+// the instance left in StrategyError with err as its Failure until
+// ResetStrategy. Later failures of the same run are ignored.
+func (e *Engine) failStrategy(inst *StrategyInstance, run *strategyRun, err error) {
+	inst.mu.Lock()
+	current := inst.run == run
+	if current {
+		inst.run = nil
+	}
+	inst.mu.Unlock()
+	if !current {
+		return
+	}
+
+	if s, ok := run.strategy.(interface{ SetEnabled(bool) }); ok {
+		s.SetEnabled(false)
+	}
+	inst.Log.Errorf(">>> STRATEGY FAILED: %v <<<", err)
+	var p *execution.StrategyPanic
+	if errors.As(err, &p) {
+		inst.Log.Errorf("%s stack trace:\n%s", p.Callback, p.Stack)
+	}
+	e.endRun(inst, run)
+
+	failure := err.Error()
+	inst.Runtime.failure.Store(&failure)
+	inst.Runtime.SetStatus(StrategyError)
+	e.emit(Event{Kind: EventStrategyStatus, Status: StrategyError, StrategyID: inst.ID, Message: failure})
+}
+
+// ResetStrategy clears the failure of an instance in StrategyError, leaving it
+// stopped so it can be started again
+func (e *Engine) ResetStrategy(id string) error {
+	inst, err := e.instance(id)
+	if err != nil {
+		return err
+	}
+	if inst.Runtime.Status() != StrategyError {
+		return fmt.Errorf("strategy %s has not failed", id)
+	}
+	inst.Runtime.failure.Store(nil)
+	e.setStatus(inst, StrategyStopped)
+	inst.Log.Info(">>> STRATEGY RESET - start it again when ready <<<")
+	return nil
+}
+
+// StopStrategy removes the instance's market data handlers, cancels its chart
+// feed and resets it so it can be started again
+func (e *Engine) StopStrategy(id string) error {
+	inst, err := e.instance(id)
+	if err != nil {
+		return err
+	}
+	if inst.Runtime.Status() != StrategyRunning {
+		return fmt.Errorf("strategy %s is not running", id)
+	}
+	e.setStatus(inst, StrategyStopping)
+
+	inst.mu.Lock()
+	run := inst.run
+	inst.run = nil
+	inst.mu.Unlock()
+
+	e.endRun(inst, run)
+	e.setStatus(inst, StrategyStopped)
+
+	inst.Log.Info(">>> STRATEGY STOPPED <<<")
+	return nil
+}
+
+// endRun removes a run's market data handlers, cancels its chart feed,
+// releases its quote subscription and resets the strategy so it can be started again. run may be nil.
+func (e *Engine) endRun(inst *StrategyInstance, run *strategyRun) {
+	if md := e.MarketData(); md != nil && run != nil {
+		md.RemoveChartHandler(run.chartHandler)
+		md.RemoveChartErrorHandler(run.chartErrors)
+		md.RemoveQuoteHandler(run.quoteHandler)
+		md.RemoveRequestErrorHandler(run.quoteErrors)
+		if err := md.UnsubscribeChart(run.chartParams); err != nil {
+			inst.Log.Errorf("Failed to unsubscribe chart: %v", err)
+		}
+		inst.mu.Lock()
+		quoteSymbol := run.quoteSymbol
+		run.quoteSymbol = ""
+		inst.mu.Unlock()
+		if quoteSymbol != "" {
+			if err := md.UnsubscribeQuote(quoteSymbol); err != nil {
+				inst.Log.Errorf("Failed to unsubscribe quotes: %v", err)
+			}
+		}
+	}
+	if run != nil && run.quotes != nil {
+		run.quotes.Stop()
+	}
+	if om := e.OrderManager(); om != nil {
+		om.ClearSymbolOrigin(inst.Symbol(), inst.ID)
+		om.ClearEntryPolicy(inst.ID)
+		if n := om.CancelQueuedOrders(inst.ID); n > 0 {
+			inst.Log.Warnf("Cancelled %d queued order(s) that were never sent", n)
+		}
+	}
+
+	if run != nil {
+		e.closeMetrics(inst, run)
+		if err := execution.StopLifecycle(run.strategy); err != nil {
+			inst.Log.Warnf("Strategy lifecycle: %v", err)
+		}
+	}
+	// Reset strategy instance state so it can be re-initialized
+	inst.strategy.Reset()
+}
+
+// StopAllStrategies stops every running instance and returns how many it stopped
+func (e *Engine) StopAllStrategies() int {
+	stopped := 0
+	for _, inst := range e.Strategies() {
+		if inst.Runtime.Status() == StrategyRunning && e.StopStrategy(inst.ID) == nil {
+			stopped++
+		}
+	}
+	return stopped
+}
+
+// AnyRunning reports whether at least one instance is running
+func (e *Engine) AnyRunning() bool {
+	for _, inst := range e.Strategies() {
+		if inst.Runtime.Status() == StrategyRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// runningOn returns another started instance trading symbol, or nil
+func (e *Engine) runningOn(symbol, exceptID string) *StrategyInstance {
+	for _, inst := range e.Strategies() {
+		if inst.ID != exceptID && inst.currentRun() != nil && inst.Symbol() == symbol {
+			return inst
+		}
+	}
+	return nil
+}
+
+// BestBidAsk returns the latest bid and ask of symbol while a strategy runs on
+// it, for the order manager to price limit entries
+func (e *Engine) BestBidAsk(symbol string) (bid, ask float64, ok bool) {
+	inst, md := e.runningOn(symbol, ""), e.MarketData()
+	if inst == nil || md == nil || inst.ContractID() == 0 {
+		return 0, 0, false
+	}
+	quote, ok := md.LastQuote(inst.ContractID())
+	if !ok {
+		return 0, 0, false
+	}
+	best, offer, ok := quote.BestBidOffer()
+	return best.Price, offer.Price, ok
+}
+
+// setStatus updates the instance's runtime and reports the change
+func (e *Engine) setStatus(inst *StrategyInstance, s StrategyStatus) {
+	inst.Runtime.SetStatus(s)
+	e.emit(Event{Kind: EventStrategyStatus, Status: s, StrategyID: inst.ID})
+}
+
+// Strategy returns the instance's strategy
+func (inst *StrategyInstance) Strategy() execution.Strategy {
+	return inst.strategy
+}
+
+// Params returns a copy of the staged parameters
+func (inst *StrategyInstance) Params() map[string]string {
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+	params := make(map[string]string, len(inst.params))
+	for k, v := range inst.params {
+		params[k] = v
+	}
+	return params
+}
+
+// Symbol returns the contract the instance was last started on
+func (inst *StrategyInstance) Symbol() string {
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+	return inst.symbol
+}
+
+// ContractID returns the Tradovate ID of Symbol, or 0 if it is not known
+func (inst *StrategyInstance) ContractID() int {
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+	return inst.contractID
+}
+
+// WarmupProgress returns how many bars the running strategy has been fed and
+// how many its warm-up asked for, both 0 when the instance is not started
+func (inst *StrategyInstance) WarmupProgress() (fed, need int) {
+	run := inst.currentRun()
+	if run == nil {
+		return 0, 0
+	}
+	return run.warmup.Progress()
+}
+
+// currentRun returns the active run, or nil if the instance is not started
+func (inst *StrategyInstance) currentRun() *strategyRun {
+	inst.mu.RLock()
+	defer inst.mu.RUnlock()
+	return inst.run
+}
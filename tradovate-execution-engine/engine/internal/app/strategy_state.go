@@ -0,0 +1,112 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+)
+
+// SavedStrategy is the last strategy configuration used, as SaveStrategy
+// writes it for RestoreStrategy to load in a later session
+type SavedStrategy struct {
+	Name    string            `json:"name"`
+	Symbol  string            `json:"symbol,omitempty"`
+	Params  map[string]string `json:"params"`
+	SavedAt time.Time         `json:"savedAt"`
+}
+
+// StrategyStatePath returns the file the last strategy configuration is saved
+// to under external/state
+func StrategyStatePath() string {
+	return filepath.Join(config.GetProjectRoot(), "external", "state", "strategy.json")
+}
+
+// SaveStrategy writes the name, symbol and staged parameters of an instance to
+// path, replacing whatever configuration was saved before
+func (e *Engine) SaveStrategy(id, path string) error {
+	inst, err := e.instance(id)
+	if err != nil {
+		return err
+	}
+	saved := SavedStrategy{
+		Name:    inst.Name,
+		Symbol:  inst.Symbol(),
+		Params:  inst.Params(),
+		SavedAt: time.Now().UTC(),
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	// Written aside and renamed so a crash mid-write leaves the old file intact
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write strategy state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadSavedStrategy reads the configuration SaveStrategy wrote to path
+func LoadSavedStrategy(path string) (SavedStrategy, error) {
+	var saved SavedStrategy
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return saved, err
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return saved, fmt.Errorf("unreadable strategy state %s: %w", path, err)
+	}
+	if saved.Name == "" {
+		return saved, fmt.Errorf("strategy state %s names no strategy", path)
+	}
+	return saved, nil
+}
+
+// RestoreStrategy adds an instance of the strategy saved at path with its saved
+// parameters. Each value is checked against the strategy's current schema;
+// parameters it no longer has, or values it no longer accepts, are dropped with
+// a warning and keep their defaults. It returns the names of the dropped
+// parameters.
+func (e *Engine) RestoreStrategy(path string) (*StrategyInstance, []string, error) {
+	saved, err := LoadSavedStrategy(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	params := saved.Params
+	if params == nil {
+		params = make(map[string]string)
+	}
+	if _, ok := params["symbol"]; !ok && saved.Symbol != "" {
+		params["symbol"] = saved.Symbol
+	}
+
+	inst, err := e.AddStrategy(saved.Name, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var dropped []string
+	for _, name := range names {
+		if err := e.SetParam(inst.ID, name, params[name]); err != nil {
+			inst.Log.Warnf("Dropping saved parameter %s=%s: %v", name, params[name], err)
+			dropped = append(dropped, name)
+		}
+	}
+	inst.Log.Printf("Restored %s from %s", saved.Name, saved.SavedAt.Local().Format("2006-01-02 15:04"))
+	return inst, dropped, nil
+}
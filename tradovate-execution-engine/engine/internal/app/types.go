@@ -0,0 +1,213 @@
+package app
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/audit"
+	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/contracts"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/metrics"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/risk"
+	"tradovate-execution-engine/engine/internal/schedule"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+//
+// STRATEGY STATUS
+//
+
+// StrategyStatus is the lifecycle state of a strategy instance
+type StrategyStatus int32
+
+const (
+	StrategyDisabled StrategyStatus = iota
+	StrategyStarting
+	StrategyRunning
+	StrategyStopping
+	StrategyStopped
+	StrategyError
+)
+
+// StrategyRuntime is the run state shared between the engine and its consumers
+type StrategyRuntime struct {
+	status atomic.Int32
+	live   atomic.Bool // Historical bars are done; live bars are being fed
+	ready  atomic.Bool // Live and primed; the strategy may trade when in session
+
+	throttled atomic.Bool  // Disabled by the order throttle until restarted
+	stale     atomic.Bool  // Disabled until quotes for its symbol resume
+	drifted   atomic.Bool  // Disabled for a position drift until ResyncPosition
+	lastQuote atomic.Int64 // Unix nanos of the last quote for its contract
+
+	droppedQuotes atomic.Int64 // Quotes a QuoteHandler strategy fell too far behind to see
+
+	failure atomic.Pointer[string] // Why the strategy is in StrategyError, until ResetStrategy
+}
+
+// KillPlan is what KillSwitch would do right now, from PreviewKillSwitch
+type KillPlan struct {
+	Strategies    []string // IDs of the running instances that would be stopped
+	TrailingStops []string // Symbols whose trailing stop would be removed
+	execution.FlattenPlan
+}
+
+//
+// EVENTS
+//
+
+// EventKind identifies what an Event reports
+type EventKind int
+
+const (
+	EventConnected EventKind = iota
+	EventDisconnected
+	EventStrategyStatus // Status holds the new strategy status
+	EventSessionOpen
+	EventSessionClosed
+	EventSessionCutoff
+	EventDailyLossLimit // Daily loss limit breached; strategies stopped and positions flattened
+	EventKillSwitch     // Kill switch engaged; Message summarises what it did
+	EventArmed          // Trading re-enabled after the kill switch or loss streak breaker
+	EventLossStreak     // Loss streak breaker tripped; Message says how many trades
+	EventOrderThrottle  // A strategy was disabled for sending orders too fast
+	EventMaintenance    // A maintenance window began or ended; Message says which
+	EventDataStale      // A strategy was paused because its quotes stopped, or resumed
+	EventNewsLockout    // A news lockout began, ended or was overridden; Message says which
+	EventPositionDrift  // A strategy's position drifted from the broker's and was synced or paused
+	EventTokenRenewal   // The access token could not be renewed; Message says when it expires
+)
+
+// Event is a status change delivered to handlers registered with AddEventHandler
+type Event struct {
+	Kind       EventKind
+	Status     StrategyStatus
+	StrategyID string // Instance the status change belongs to
+	Message    string
+	Time       time.Time
+}
+
+// PositionDrift is a strategy whose position disagrees with the broker's, as
+// CheckPositionDrift found it. Positions are net contracts, negative when short.
+type PositionDrift struct {
+	StrategyID string
+	Symbol     string
+	Strategy   int // By the strategy's own count
+	Orders     int // The order manager's view: the simulator's when paper trading
+	Broker     int // NetPos from the broker's user sync
+}
+
+//
+// ENGINE
+//
+
+// Engine owns the Tradovate connection, order and portfolio managers and the
+// lifecycle of every strategy instance. The TUI and headless mode both drive it.
+type Engine struct {
+	mu sync.RWMutex
+
+	mainLog     *logger.Logger
+	orderLog    *logger.Logger
+	strategyLog *logger.Logger
+
+	cfg               *config.Config
+	tm                *auth.TokenManager
+	om                *execution.OrderManager
+	mdClient          *tradovate.TradovateWebSocketClient
+	tradingClient     *tradovate.TradovateWebSocketClient
+	mdSubscriber      *tradovate.DataSubscriber
+	tradingSubscriber *tradovate.DataSubscriber
+	pt                *portfolio.PortfolioTracker
+	ts                *execution.TrailingStopManager
+	resolver          *contracts.Resolver
+	catalog           *contracts.Catalog   // Nil on demo data
+	riskSupervisor    *risk.RiskSupervisor // Enforces the daily loss limit of the connection
+	connected         bool
+	killed            bool   // Kill switch engaged; carried over to the order manager of each new connection
+	liveConfirmed     bool   // Live trading acknowledged with ConfirmLive; asked for once per run
+	recordingMetrics  bool   // Running strategies write their metrics to CSV on each live bar
+	closeDemo         func() // Stops the synthetic feeds of ConnectDemo (nil when live)
+
+	// Where session realized PnL counts from, kept when disconnected and carried
+	// over to the tracker of each new connection to the same account
+	session portfolio.SessionAnchor
+
+	instances      map[string]*StrategyInstance
+	instanceOrder  []string // Instance IDs in the order they were added
+	nextInstanceID int
+
+	// Trading schedule state, updated by CheckSchedule
+	inSession        bool
+	lastScheduleTick time.Time
+
+	// Maintenance state, updated by CheckMaintenance
+	maintenance   *schedule.MaintenanceWindows // Windows of the connected environment
+	inMaintenance bool
+	reconnectDue  time.Time // Next reconnect attempt after a window ends or a send fails; zero when none is due
+	resume        []string  // Instances Reconnect restarts once connected again
+
+	// News lockout state, updated by CheckNews
+	news          *schedule.NewsCalendar
+	newsLockout   schedule.NewsWindow // Window in force while inNewsLockout
+	inNewsLockout bool
+	newsOverride  time.Time // Lockouts are ignored before this; set by OverrideNewsLockout
+
+	// Position reconciliation state, updated by CheckPositionDrift
+	driftCheckDue time.Time                // Next reconciliation pass
+	driftSeen     map[string]PositionDrift // Drifts found on the last pass by instance ID, acted on if seen again
+
+	// Token renewal state, set by the refresh monitor and cleared by a renewal or Reauthenticate
+	renewalErr      error // Last renewal failure of the connected session
+	renewalReported bool  // CheckTokenRenewal has returned renewalErr
+
+	// Metrics listener, admin API and audit trail, started by the first Connect and closed by Shutdown
+	metricsServer *metrics.Server
+	adminServer   *adminServer
+	auditTrail    *audit.Trail
+
+	handlers []func(Event)
+}
+
+// StrategyInstance is one loaded strategy with its own log, parameters and run
+// state. Instances on different symbols run side by side.
+type StrategyInstance struct {
+	ID      string
+	Name    string         // Registry name
+	Log     *logger.Logger // Instance log; entries also go to the strategy log
+	Runtime *StrategyRuntime
+
+	strategy execution.Strategy
+
+	mu         sync.RWMutex
+	params     map[string]string // Applied to the strategy on start
+	symbol     string            // Contract the running strategy trades
+	contractID int               // Tradovate ID of symbol, 0 if the lookup failed
+	run        *strategyRun      // nil unless started
+}
+
+// strategyRun is the bar feed state of one start of a strategy instance
+type strategyRun struct {
+	strategy    execution.Strategy
+	warmup      *execution.StrategyWarmup // Orders history and live bars into the strategy
+	barBuilder  *marketdata.BarBuilder
+	chartParams marketdata.HistoricalDataParams
+	chartPinned bool // The warm-up only takes the chart IDs of this run's subscription; set on the chart handler
+	mode        execution.UpdateMode
+	quotes      *execution.QuoteQueue // Quote delivery for QuoteHandler strategies; nil for bars only
+	quoteSymbol string                // Quote subscription the run holds, released by endRun; guarded by the instance's mu
+
+	// Metrics CSV of this run, opened on the first bar recorded; guarded by the instance's mu
+	metrics       *execution.MetricsRecorder
+	metricsFailed bool // The CSV could not be opened; not retried this run
+
+	// Market data handlers registered for this run, removed on stop
+	chartHandler tradovate.HandlerID
+	chartErrors  tradovate.HandlerID
+	quoteHandler tradovate.HandlerID
+	quoteErrors  tradovate.HandlerID
+}
@@ -0,0 +1,234 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+)
+
+// flushInterval is how often buffered lines are written to the file
+const flushInterval = time.Second
+
+// Dir returns the default directory for audit files, external/audit
+func Dir() string {
+	return filepath.Join(config.GetProjectRoot(), "external", "audit")
+}
+
+// FileName returns the name of the audit file for day, e.g. audit-2026-01-05.jsonl
+func FileName(day time.Time) string {
+	return fmt.Sprintf("audit-%s.jsonl", day.UTC().Format("2006-01-02"))
+}
+
+// NewTrail creates dir if needed and starts writing to it. Up to buffer entries
+// may wait to be written; beyond that Record drops them and counts them.
+func NewTrail(dir string, buffer int) (*Trail, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	t := &Trail{
+		dir:     dir,
+		entries: make(chan Entry, buffer),
+		done:    make(chan struct{}),
+	}
+	go t.run()
+	return t, nil
+}
+
+// Record queues an entry stamped with the current time. It never blocks: an
+// entry that does not fit in the buffer is dropped and counted, and the count
+// is written to the file ahead of the next entry. A nil or closed trail records
+// nothing.
+func (t *Trail) Record(actor, action string, details map[string]interface{}) {
+	if t == nil {
+		return
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.entries <- Entry{Time: time.Now().UTC(), Actor: actor, Action: action, Details: details}:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many entries were lost because the buffer was full
+func (t *Trail) Dropped() int64 {
+	return t.dropped.Load()
+}
+
+// Written returns how many lines have been written, drop notices included
+func (t *Trail) Written() int64 {
+	return t.written.Load()
+}
+
+// Path returns the file today's entries go to
+func (t *Trail) Path() string {
+	return filepath.Join(t.dir, FileName(time.Now()))
+}
+
+// Close writes every queued entry, flushes and closes the file. It returns the
+// first error seen while writing, if any.
+func (t *Trail) Close() error {
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.entries)
+	}
+	t.mu.Unlock()
+	<-t.done
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.err
+}
+
+// run writes entries as they arrive and flushes every flushInterval, so a
+// crash loses at most the last second
+func (t *Trail) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var reported int64
+	noteDropped := func() {
+		if dropped := t.dropped.Load(); dropped > reported {
+			t.write(Entry{Time: time.Now().UTC(), Actor: ActorSystem, Action: ActionDropped,
+				Details: map[string]interface{}{"dropped": dropped - reported, "total": dropped}})
+			reported = dropped
+		}
+	}
+
+	for {
+		select {
+		case entry, ok := <-t.entries:
+			if !ok {
+				noteDropped()
+				t.closeFile()
+				return
+			}
+			noteDropped()
+			t.write(entry)
+		case <-ticker.C:
+			noteDropped()
+			if t.w != nil {
+				t.fail(t.w.Flush())
+			}
+		}
+	}
+}
+
+// write appends entry to its day's file, chained to the line before it
+func (t *Trail) write(entry Entry) {
+	if t.failed() {
+		return
+	}
+	day := entry.Time.Format("2006-01-02")
+	if day != t.day || t.file == nil {
+		t.closeFile()
+		if err := t.openDay(entry.Time); err != nil {
+			t.fail(err)
+			return
+		}
+		t.day = day
+	}
+
+	entry.Prev = t.prev
+	line, err := json.Marshal(entry)
+	if err != nil {
+		t.fail(fmt.Errorf("failed to encode audit entry: %w", err))
+		return
+	}
+	if _, err := t.w.Write(append(line, '\n')); err != nil {
+		t.fail(err)
+		return
+	}
+	t.prev = lineHash(line)
+	t.written.Add(1)
+}
+
+// openDay opens the file for day for appending, carrying on the chain from its
+// last line if it already has entries
+func (t *Trail) openDay(day time.Time) error {
+	path := filepath.Join(t.dir, FileName(day))
+	prev, err := lastLineHash(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	t.file = file
+	t.w = bufio.NewWriter(file)
+	t.prev = prev
+	return nil
+}
+
+// closeFile flushes and closes the current file, if one is open
+func (t *Trail) closeFile() {
+	if t.file == nil {
+		return
+	}
+	t.fail(t.w.Flush())
+	t.fail(t.file.Close())
+	t.file = nil
+	t.w = nil
+}
+
+// fail keeps the first write error
+func (t *Trail) fail(err error) {
+	if err == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *Trail) failed() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.err != nil
+}
+
+// lastLineHash returns the hash of the last line of the file at path, or ""
+// when it does not exist or is empty
+func lastLineHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read audit file: %w", err)
+	}
+	data = bytes.TrimRight(data, "\n")
+	if len(data) == 0 {
+		return "", nil
+	}
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return lineHash(data), nil
+}
+
+// lineHash is the hex SHA-256 of a line without its newline
+func lineHash(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Actors besides strategy instance IDs
+const (
+	ActorManual = "manual" // The user, from the UI, a command or a signal
+	ActorSystem = "system" // The engine itself: risk actions, the schedule, shutdown
+)
+
+// Actions recorded besides order_<status>, e.g. order_filled or order_canceled
+const (
+	ActionOrderCreated  = "order_created"
+	ActionOrderModified = "order_modified"
+	ActionFlatten       = "flatten"
+	ActionKillSwitch    = "kill_switch"
+	ActionArm           = "arm"
+	ActionConfigReload  = "config_reload"
+	ActionDropped       = "audit_dropped" // Entries were lost because the buffer was full
+)
+
+// DefaultBuffer is how many entries may wait to be written before new ones are dropped
+const DefaultBuffer = 4096
+
+// ErrChainBroken is returned by Verify when a line does not carry the hash of the one before it
+var ErrChainBroken = errors.New("audit chain broken")
+
+// Entry is one line of an audit file
+type Entry struct {
+	Time    time.Time              `json:"time"`
+	Actor   string                 `json:"actor"` // A strategy instance ID, ActorManual or ActorSystem
+	Action  string                 `json:"action"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	Prev    string                 `json:"prev"` // Hex SHA-256 of the previous line; empty on a file's first line
+}
+
+// Trail appends entries to a file per UTC day, audit-2026-01-05.jsonl, from a
+// goroutine of its own so Record never waits on the disk
+type Trail struct {
+	dir     string
+	entries chan Entry
+	done    chan struct{}
+	dropped atomic.Int64
+	written atomic.Int64
+
+	mu     sync.RWMutex
+	closed bool
+	err    error // First write error; entries after it are not written
+
+	// Owned by the writer goroutine
+	day  string
+	file *os.File
+	w    *bufio.Writer
+	prev string
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// maxLineSize bounds one line of an audit file, for config reloads with many changes
+const maxLineSize = 1 << 20
+
+// Verify checks that every line of the audit file at path is a valid entry
+// carrying the hash of the line before it, the first carrying none, and
+// returns how many entries it holds. An edited, inserted or deleted line
+// fails with an error wrapping ErrChainBroken that names the first bad line.
+func Verify(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	count := 0
+	prev := ""
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		count++
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return count - 1, fmt.Errorf("%w: line %d is not an entry: %v", ErrChainBroken, count, err)
+		}
+		if entry.Prev != prev {
+			return count - 1, fmt.Errorf("%w: line %d does not follow line %d", ErrChainBroken, count, count-1)
+		}
+		prev = lineHash(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return count, fmt.Errorf("failed to read audit file: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// NewRateLimiter creates a limiter allowing bursts of capacity requests per group,
+// refilled at refillRate requests per second
+func NewRateLimiter(capacity int, refillRate float64) *RateLimiter {
+	return &RateLimiter{
+		buckets:    make(map[string]*tokenBucket),
+		capacity:   float64(capacity),
+		refillRate: refillRate,
+	}
+}
+
+// Reserve takes a token from the group's bucket and returns how long the caller must
+// wait before sending. A zero duration means the request can go out immediately.
+func (rl *RateLimiter) Reserve(group string, now time.Time) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	b, ok := rl.buckets[group]
+	if !ok {
+		b = &tokenBucket{tokens: rl.capacity, lastRefill: now}
+		rl.buckets[group] = b
+	}
+
+	// Refill based on elapsed time
+	if elapsed := now.Sub(b.lastRefill).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * rl.refillRate
+		if b.tokens > rl.capacity {
+			b.tokens = rl.capacity
+		}
+		b.lastRefill = now
+	}
+
+	// Tokens may go negative; the debt is what later callers wait for
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / rl.refillRate * float64(time.Second))
+}
+
+// Wait blocks until a request to the group may be sent
+func (rl *RateLimiter) Wait(group string) {
+	if wait := rl.Reserve(group, time.Now()); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// EndpointGroup returns the rate limit group for an endpoint, e.g. "/v1/order/placeorder" -> "order"
+func EndpointGroup(endpoint string) string {
+	path := strings.TrimPrefix(endpoint, "/")
+	path = strings.TrimPrefix(path, "v1/")
+	group, _, _ := strings.Cut(path, "/")
+	return group
+}
+
+// ParsePenalty extracts a p-ticket penalty from a response body
+func ParsePenalty(body []byte) (Penalty, bool) {
+	var p Penalty
+	if err := json.Unmarshal(body, &p); err != nil {
+		return Penalty{}, false
+	}
+	return p, p.Ticket != "" || p.Time > 0
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+)
+
+// TokenCachePath returns the token cache file for environment under
+// external/auth. Live and demo have a file each, so sessions on both do not
+// overwrite each other's cache.
+func TokenCachePath(environment config.Environment) string {
+	name := "token_cache_demo.json"
+	if environment.IsLive() {
+		name = "token_cache_live.json"
+	}
+	return filepath.Join(config.GetProjectRoot(), "external", "auth", name)
+}
+
+// EnableTokenCache persists tokens to path after each login or renewal. An empty
+// path disables caching.
+func (tm *TokenManager) EnableTokenCache(path string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.cachePath = path
+}
+
+// Login restores a cached session when caching is enabled and the cache still
+// matches the configured credentials, renewing it instead of creating a new
+// session. It falls back to Authenticate otherwise.
+func (tm *TokenManager) Login() error {
+	tm.mu.RLock()
+	path := tm.cachePath
+	tm.mu.RUnlock()
+
+	if path != "" && tm.restoreCachedSession() {
+		err := tm.RenewAccessToken()
+		if err == nil {
+			tm.logInfo("Reused cached session")
+			return nil
+		}
+		tm.logWarnf("Cached session could not be renewed: %v", err)
+		tm.InvalidateTokenCache()
+	}
+
+	return tm.Authenticate()
+}
+
+// restoreCachedSession loads the cache into memory if it is unexpired and was
+// created by the current credentials. A stale or mismatched cache is removed.
+func (tm *TokenManager) restoreCachedSession() bool {
+	tm.mu.RLock()
+	path := tm.cachePath
+	fingerprint := credentialFingerprint(tm.credentials)
+	deviceID, _ := tm.credentials["deviceId"].(string)
+	tm.mu.RUnlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	var cache tokenCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		tm.logWarnf("Discarding unreadable token cache: %v", err)
+		tm.InvalidateTokenCache()
+		return false
+	}
+
+	switch {
+	case cache.Fingerprint != fingerprint || cache.DeviceID != deviceID:
+		tm.logInfo("Credentials changed since the token cache was written, logging in again")
+		tm.InvalidateTokenCache()
+		return false
+	case cache.AccessToken == "" || !time.Now().Before(cache.ExpirationTime):
+		tm.InvalidateTokenCache()
+		return false
+	}
+
+	tm.mu.Lock()
+	tm.accessToken = cache.AccessToken
+	tm.mdAccessToken = cache.MDAccessToken
+	tm.expirationTime = cache.ExpirationTime
+	tm.userID = cache.UserID
+	tm.username = cache.Name
+	tm.mu.Unlock()
+	return true
+}
+
+// saveTokenCache writes the current session to the cache file, readable only by the owner
+func (tm *TokenManager) saveTokenCache() {
+	tm.mu.RLock()
+	path := tm.cachePath
+	deviceID, _ := tm.credentials["deviceId"].(string)
+	cache := tokenCache{
+		AccessToken:    tm.accessToken,
+		MDAccessToken:  tm.mdAccessToken,
+		ExpirationTime: tm.expirationTime,
+		UserID:         tm.userID,
+		Name:           tm.username,
+		DeviceID:       deviceID,
+		Fingerprint:    credentialFingerprint(tm.credentials),
+	}
+	tm.mu.RUnlock()
+
+	if path == "" {
+		return
+	}
+	if err := writeTokenCache(path, cache); err != nil {
+		tm.logWarnf("Failed to write token cache: %v", err)
+	}
+}
+
+func writeTokenCache(path string, cache tokenCache) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create token cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0600)
+}
+
+// InvalidateTokenCache deletes the cache file so the next Login authenticates
+func (tm *TokenManager) InvalidateTokenCache() {
+	tm.mu.RLock()
+	path := tm.cachePath
+	tm.mu.RUnlock()
+
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		tm.logWarnf("Failed to remove token cache: %v", err)
+	}
+}
+
+// credentialFingerprint hashes everything that identifies a session's owner, so
+// a cache from another user, password, app or environment is never reused
+func credentialFingerprint(credentials map[string]interface{}) string {
+	h := sha256.New()
+	for _, key := range []string{"environment", "name", "password", "appId", "appVersion", "cid", "sec", "deviceId"} {
+		fmt.Fprintf(h, "%s=%v\n", key, credentials[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (tm *TokenManager) logInfo(msg string) {
+	tm.mu.RLock()
+	log := tm.log
+	tm.mu.RUnlock()
+	if log != nil {
+		log.Info(msg)
+	}
+}
+
+func (tm *TokenManager) logWarnf(format string, args ...interface{}) {
+	tm.mu.RLock()
+	log := tm.log
+	tm.mu.RUnlock()
+	if log != nil {
+		log.Warnf(format, args...)
+	}
+}
@@ -3,9 +3,12 @@ package auth
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,18 +18,46 @@ import (
 )
 
 var (
-	// Global token manager instance
-	globalTokenManager *TokenManager
-	once               sync.Once
+	// Most recent manager from NewTokenManager, for GetTokenManager
+	defaultMu           sync.Mutex
+	defaultTokenManager *TokenManager
 )
 
-// NewTokenManager returns the singleton token manager instance
+const (
+	// Per endpoint group request budget
+	rateLimitBurst     = 10
+	rateLimitPerSecond = 5.0
+
+	defaultMaxRetries = 3
+	// Used when a 429 carries no p-time or Retry-After
+	defaultPenaltyWait = time.Second
+
+	// Wait between renewal attempts once one has failed
+	renewRetryInterval = 30 * time.Second
+)
+
+// ErrUserChanged is returned by Reauthenticate when the new session belongs to
+// another user than the connected one
+var ErrUserChanged = errors.New("re-authentication logged in as a different user")
+
+// NewTokenManager creates a token manager for the credentials and environment in
+// config. Each call returns an independent manager with its own tokens, rate
+// limiter and refresh monitor, so connections to different environments can
+// run side by side.
 func NewTokenManager(config *config.Config) *TokenManager {
-	once.Do(func() {
-		globalTokenManager = &TokenManager{}
-	})
+	tm := &TokenManager{
+		limiter: NewRateLimiter(rateLimitBurst, rateLimitPerSecond),
+	}
+
+	tm.SetMaxRetries(config.Tradovate.MaxRequestRetries)
 
-	globalTokenManager.SetCredentials(
+	cachePath := ""
+	if config.Tradovate.CacheTokens {
+		cachePath = TokenCachePath(config.Tradovate.Environment)
+	}
+	tm.EnableTokenCache(cachePath)
+
+	tm.SetCredentials(
 		config.Tradovate.AppID,
 		config.Tradovate.AppVersion,
 		config.Tradovate.Chl,
@@ -39,13 +70,42 @@ func NewTokenManager(config *config.Config) *TokenManager {
 		config.Tradovate.Enc,
 	)
 
-	return globalTokenManager
+	defaultMu.Lock()
+	defaultTokenManager = tm
+	defaultMu.Unlock()
+	return tm
 }
 
-// ResetTokenManagerForTest resets the singleton for testing purposes
+// GetTokenManager returns the manager most recently created by NewTokenManager,
+// or nil if there is none.
+//
+// Deprecated: keep the *TokenManager returned by NewTokenManager and pass it to
+// whatever needs it. GetTokenManager will be removed in the next release.
+func GetTokenManager() *TokenManager {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return defaultTokenManager
+}
+
+// ResetTokenManagerForTest clears the manager returned by GetTokenManager.
+//
+// Deprecated: NewTokenManager no longer returns a shared instance, so there is
+// nothing to reset. It will be removed with GetTokenManager.
 func ResetTokenManagerForTest() {
-	globalTokenManager = nil
-	once = sync.Once{}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultTokenManager = nil
+}
+
+// SetSessionForTest points the token manager at baseURL with an already valid
+// access token and account, skipping authentication
+func (tm *TokenManager) SetSessionForTest(baseURL, accessToken string, accountID int) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.baseURL = baseURL
+	tm.accessToken = accessToken
+	tm.expirationTime = time.Now().Add(time.Hour)
+	tm.accountID = accountID
 }
 
 // SetLogger sets the logger for the TokenManager
@@ -55,8 +115,18 @@ func (tm *TokenManager) SetLogger(l *logger.Logger) {
 	tm.log = l
 }
 
+// SetMaxRetries sets how many times a rate limited request is retried (<= 0 uses the default)
+func (tm *TokenManager) SetMaxRetries(n int) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if n <= 0 {
+		n = defaultMaxRetries
+	}
+	tm.maxRetries = n
+}
+
 // SetCredentials stores the authentication credentials
-func (tm *TokenManager) SetCredentials(appID, appVersion, chl, cid, deviceID, environment, name, password, sec string, enc bool) {
+func (tm *TokenManager) SetCredentials(appID, appVersion, chl, cid, deviceID string, environment config.Environment, name, password, sec string, enc bool) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -78,25 +148,55 @@ func (tm *TokenManager) SetCredentials(appID, appVersion, chl, cid, deviceID, en
 
 // Authenticate performs authentication and stores tokens
 func (tm *TokenManager) Authenticate() error {
+	authResp, err := tm.requestSession()
+	if err != nil {
+		return err
+	}
+	tm.storeSession(authResp)
+	return nil
+}
+
+// Reauthenticate logs in again with the stored credentials, creating a new
+// session for a connected user whose token could not be renewed. A session for
+// any other user is discarded with ErrUserChanged, leaving the current one in place.
+func (tm *TokenManager) Reauthenticate() error {
+	tm.mu.RLock()
+	userID := tm.userID
+	tm.mu.RUnlock()
+
+	authResp, err := tm.requestSession()
+	if err != nil {
+		return err
+	}
+	if userID != 0 && authResp.UserID != userID {
+		return fmt.Errorf("%w: user %d, connected as %d", ErrUserChanged, authResp.UserID, userID)
+	}
+	tm.storeSession(authResp)
+	return nil
+}
+
+// requestSession asks Tradovate for a new session with the stored credentials
+func (tm *TokenManager) requestSession() (tradovate.APIAuthResponse, error) {
+	var authResp tradovate.APIAuthResponse
 	tm.mu.RLock()
 	credentials := tm.credentials
 	baseURL := tm.baseURL
 	tm.mu.RUnlock()
 
 	if credentials == nil {
-		return fmt.Errorf("Credentials not set. Call SetCredentials first")
+		return authResp, fmt.Errorf("Credentials not set. Call SetCredentials first")
 	}
 
 	// Marshal credentials to JSON
 	jsonData, err := json.Marshal(credentials)
 	if err != nil {
-		return fmt.Errorf("Error marshaling credentials: %w", err)
+		return authResp, fmt.Errorf("Error marshaling credentials: %w", err)
 	}
 
 	// Create the request
 	req, err := http.NewRequest("POST", baseURL+"/v1/auth/accesstokenrequest", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("Error creating request: %w", err)
+		return authResp, fmt.Errorf("Error creating request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -106,7 +206,7 @@ func (tm *TokenManager) Authenticate() error {
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("Error making request: %w", err)
+		return authResp, fmt.Errorf("Error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -114,20 +214,22 @@ func (tm *TokenManager) Authenticate() error {
 	respBody, err := io.ReadAll(resp.Body)
 
 	if err != nil {
-		return fmt.Errorf("Error reading response: %w", err)
+		return authResp, fmt.Errorf("Error reading response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return parseAuthError(resp.StatusCode, respBody)
+		return authResp, parseAuthError(resp.StatusCode, respBody)
 	}
 
 	// Parse response
-	var authResp tradovate.APIAuthResponse
 	if err := json.Unmarshal(respBody, &authResp); err != nil {
-		return fmt.Errorf("Error parsing response: %w", err)
+		return authResp, fmt.Errorf("Error parsing response: %w", err)
 	}
+	return authResp, nil
+}
 
-	// Store tokens
+// storeSession keeps a new session's tokens and user, and caches them
+func (tm *TokenManager) storeSession(authResp tradovate.APIAuthResponse) {
 	tm.mu.Lock()
 	tm.accessToken = authResp.AccessToken
 	tm.mdAccessToken = authResp.MDAccessToken
@@ -136,7 +238,7 @@ func (tm *TokenManager) Authenticate() error {
 	tm.username = authResp.Name
 	tm.mu.Unlock()
 
-	return nil
+	tm.saveTokenCache()
 }
 
 // parseAuthError parses HTTP errors from Tradovate
@@ -216,7 +318,8 @@ func (tm *TokenManager) GetUsername() string {
 	return tm.username
 }
 
-// GetAccountID returns the cached account ID, fetching it if necessary
+// GetAccountID returns the selected account ID, selecting the first account if
+// SetPreferredAccount has not been called
 func (tm *TokenManager) GetAccountID() (int, error) {
 	tm.mu.RLock()
 	if tm.accountID != 0 {
@@ -225,43 +328,108 @@ func (tm *TokenManager) GetAccountID() (int, error) {
 	}
 	tm.mu.RUnlock()
 
-	// Ensure we have a token
+	if err := tm.SetPreferredAccount(0, ""); err != nil {
+		return 0, err
+	}
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.accountID, nil
+}
+
+// GetAccountSpec returns the selected account's name (the accountSpec for orders)
+func (tm *TokenManager) GetAccountSpec() string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.accountName
+}
+
+// ListAccounts returns every account available to the logged in user
+func (tm *TokenManager) ListAccounts() ([]tradovate.APIAccount, error) {
+	var accounts []tradovate.APIAccount
+	if err := tm.GetJSON("/v1/account/list", &accounts); err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// GetJSON makes an authenticated GET request and decodes the JSON response into out
+func (tm *TokenManager) GetJSON(endpoint string, out interface{}) error {
 	token, err := tm.GetAccessToken()
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	// Fetch accounts
-	resp, err := tm.MakeAuthenticatedRequest("GET", "/v1/account/list", nil, token)
+	resp, err := tm.MakeAuthenticatedRequest("GET", endpoint, nil, token)
 	if err != nil {
-		return 0, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("failed to list accounts: %s", string(body))
+		return fmt.Errorf("%s returned %d: %s", endpoint, resp.StatusCode, string(body))
 	}
 
-	var accounts []tradovate.APIAccount
-	if err := json.NewDecoder(resp.Body).Decode(&accounts); err != nil {
-		return 0, fmt.Errorf("failed to decode account list: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", endpoint, err)
+	}
+	return nil
+}
+
+// SetPreferredAccount selects the account to trade by ID or name. With neither set
+// the first account is used. It fails if the configured account is not on the login.
+func (tm *TokenManager) SetPreferredAccount(accountID int, accountSpec string) error {
+	accounts, err := tm.ListAccounts()
+	if err != nil {
+		return err
 	}
 
-	if len(accounts) == 0 {
-		return 0, fmt.Errorf("no accounts found")
+	account, err := SelectAccount(accounts, accountID, accountSpec)
+	if err != nil {
+		return err
 	}
 
-	// Cache the first account ID
 	tm.mu.Lock()
-	tm.accountID = accounts[0].ID
+	tm.accountID = account.ID
+	tm.accountName = account.Name
+	log := tm.log
 	tm.mu.Unlock()
 
-	if tm.log != nil {
-		tm.log.Debugf("Using Account ID: %d (%s)", accounts[0].ID, accounts[0].Name)
+	if log != nil {
+		if accountID == 0 && accountSpec == "" && len(accounts) > 1 {
+			log.Warnf("%d accounts on this login and none configured; using %s (%d). Set tradovate.accountSpec to choose",
+				len(accounts), account.Name, account.ID)
+		}
+		log.Debugf("Using Account ID: %d (%s)", account.ID, account.Name)
 	}
+	return nil
+}
 
-	return accounts[0].ID, nil
+// SelectAccount picks the configured account from the list
+func SelectAccount(accounts []tradovate.APIAccount, accountID int, accountSpec string) (tradovate.APIAccount, error) {
+	if len(accounts) == 0 {
+		return tradovate.APIAccount{}, fmt.Errorf("no accounts found")
+	}
+	if accountID == 0 && accountSpec == "" {
+		return accounts[0], nil
+	}
+
+	for _, a := range accounts {
+		if (accountID != 0 && a.ID == accountID) || (accountID == 0 && strings.EqualFold(a.Name, accountSpec)) {
+			return a, nil
+		}
+	}
+
+	available := make([]string, len(accounts))
+	for i, a := range accounts {
+		available[i] = fmt.Sprintf("%s (%d)", a.Name, a.ID)
+	}
+	want := accountSpec
+	if accountID != 0 {
+		want = strconv.Itoa(accountID)
+	}
+	return tradovate.APIAccount{}, fmt.Errorf("configured account %s not found; available: %s", want, strings.Join(available, ", "))
 }
 
 // IsAuthenticated checks if there's a valid access token
@@ -271,6 +439,27 @@ func (tm *TokenManager) IsAuthenticated() bool {
 	return tm.accessToken != "" && time.Now().Before(tm.expirationTime)
 }
 
+// ExpiresIn returns the time left before the access token expires, 0 once it has
+func (tm *TokenManager) ExpiresIn() time.Duration {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if tm.accessToken == "" {
+		return 0
+	}
+	return max(time.Until(tm.expirationTime), 0)
+}
+
+// TimeToExpiry returns the time left before the access token expires,
+// negative once it has. ok is false without a token.
+func (tm *TokenManager) TimeToExpiry() (left time.Duration, ok bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if tm.accessToken == "" {
+		return 0, false
+	}
+	return time.Until(tm.expirationTime), true
+}
+
 // GetBaseURL returns the base API URL
 func (tm *TokenManager) GetBaseURL() string {
 	tm.mu.RLock()
@@ -278,14 +467,68 @@ func (tm *TokenManager) GetBaseURL() string {
 	return tm.baseURL
 }
 
-// MakeAuthenticatedRequest makes an HTTP request with authentication
+// MakeAuthenticatedRequest makes an HTTP request with authentication. Requests are
+// rate limited per endpoint group, and rate limited responses (429 or a p-ticket) are
+// retried transparently after the penalty time up to the configured retry limit.
 func (tm *TokenManager) MakeAuthenticatedRequest(method, endpoint string, body interface{}, token string) (*http.Response, error) {
-	var reqBody io.Reader
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("Error marshaling request body: %w", err)
 		}
+	}
+
+	tm.mu.Lock()
+	if tm.limiter == nil {
+		tm.limiter = NewRateLimiter(rateLimitBurst, rateLimitPerSecond)
+	}
+	limiter := tm.limiter
+	maxRetries := tm.maxRetries
+	tm.mu.Unlock()
+
+	group := EndpointGroup(endpoint)
+
+	for attempt := 0; ; attempt++ {
+		tm.waitForPenalty()
+		limiter.Wait(group)
+
+		resp, err := tm.doRequest(method, endpoint, jsonData, token)
+		if err != nil {
+			return nil, err
+		}
+
+		penalty, limited, err := checkRateLimited(resp)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			// The session is no longer valid, so a restart must not reuse it
+			tm.InvalidateTokenCache()
+		}
+		if !limited {
+			return resp, nil
+		}
+
+		wait := tm.startPenalty(penalty, endpoint)
+		if penalty.Captcha {
+			return nil, fmt.Errorf("rate limited on %s: captcha required, try again in %v", endpoint, wait)
+		}
+		if attempt >= maxRetries {
+			return nil, fmt.Errorf("rate limited on %s: giving up after %d retries", endpoint, attempt)
+		}
+
+		if penalty.Ticket != "" {
+			jsonData = withPenaltyTicket(jsonData, penalty.Ticket)
+		}
+	}
+}
+
+// doRequest sends a single authenticated request
+func (tm *TokenManager) doRequest(method, endpoint string, jsonData []byte, token string) (*http.Response, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
 		reqBody = bytes.NewBuffer(jsonData)
 	}
 
@@ -303,6 +546,86 @@ func (tm *TokenManager) MakeAuthenticatedRequest(method, endpoint string, body i
 	return client.Do(req)
 }
 
+// checkRateLimited reports whether a response is a rate limit penalty. Non penalty
+// responses are returned to the caller with their body intact.
+func checkRateLimited(resp *http.Response) (Penalty, bool, error) {
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return Penalty{}, false, fmt.Errorf("Error reading response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	penalty, hasPenalty := ParsePenalty(respBody)
+	if resp.StatusCode != http.StatusTooManyRequests && !hasPenalty {
+		return Penalty{}, false, nil
+	}
+
+	if penalty.Time <= 0 {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			penalty.Time = float64(secs)
+		} else {
+			penalty.Time = defaultPenaltyWait.Seconds()
+		}
+	}
+	return penalty, true, nil
+}
+
+// withPenaltyTicket adds the p-ticket to a JSON object body so the retry is accepted
+func withPenaltyTicket(jsonData []byte, ticket string) []byte {
+	fields := make(map[string]interface{})
+	if len(jsonData) > 0 {
+		if err := json.Unmarshal(jsonData, &fields); err != nil {
+			return jsonData
+		}
+	}
+	fields["p-ticket"] = ticket
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return jsonData
+	}
+	return data
+}
+
+// startPenalty records a penalty window and returns its length
+func (tm *TokenManager) startPenalty(p Penalty, endpoint string) time.Duration {
+	wait := time.Duration(p.Time * float64(time.Second))
+
+	tm.mu.Lock()
+	if until := time.Now().Add(wait); until.After(tm.penaltyUntil) {
+		tm.penaltyUntil = until
+	}
+	log := tm.log
+	tm.mu.Unlock()
+
+	if log != nil {
+		log.Warnf("Rate limit penalty on %s: waiting %v before retrying", endpoint, wait)
+	}
+	return wait
+}
+
+// waitForPenalty blocks until any active penalty window has passed
+func (tm *TokenManager) waitForPenalty() {
+	if remaining := tm.PenaltyRemaining(); remaining > 0 {
+		time.Sleep(remaining)
+	}
+}
+
+// IsPenaltyActive reports whether Tradovate has us in a rate limit penalty window
+func (tm *TokenManager) IsPenaltyActive() bool {
+	return tm.PenaltyRemaining() > 0
+}
+
+// PenaltyRemaining returns the time left in the current penalty window
+func (tm *TokenManager) PenaltyRemaining() time.Duration {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	if remaining := time.Until(tm.penaltyUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
 // RenewAccessToken renews the current access token without creating a new session
 // This should be used instead of Authenticate() for long-running applications
 func (tm *TokenManager) RenewAccessToken() error {
@@ -340,6 +663,9 @@ func (tm *TokenManager) RenewAccessToken() error {
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusUnauthorized {
+			tm.InvalidateTokenCache()
+		}
 		return fmt.Errorf("Token renewal failed with status %d: %s", resp.StatusCode, string(respBody))
 	}
 
@@ -352,9 +678,14 @@ func (tm *TokenManager) RenewAccessToken() error {
 	// Update tokens in memory (WebSockets stay connected!)
 	tm.mu.Lock()
 	tm.accessToken = renewResp.AccessToken
+	if renewResp.MDAccessToken != "" {
+		tm.mdAccessToken = renewResp.MDAccessToken
+	}
 	tm.expirationTime = renewResp.ExpirationTime
 	tm.mu.Unlock()
 
+	tm.saveTokenCache()
+
 	// Log success
 	tm.mu.RLock()
 	if tm.log != nil {
@@ -365,8 +696,10 @@ func (tm *TokenManager) RenewAccessToken() error {
 	return nil
 }
 
-// StartTokenRefreshMonitor starts a background goroutine that refreshes tokens before expiration
-func (tm *TokenManager) StartTokenRefreshMonitor(refreshCallback func()) {
+// StartTokenRefreshMonitor starts a background goroutine that refreshes tokens
+// before expiration. refreshCallback is called after each renewal and
+// failureCallback after each failed one, which is retried every 30 seconds.
+func (tm *TokenManager) StartTokenRefreshMonitor(refreshCallback func(), failureCallback func(error)) {
 	tm.mu.Lock()
 	if tm.monitorStopChan != nil {
 		close(tm.monitorStopChan)
@@ -376,22 +709,28 @@ func (tm *TokenManager) StartTokenRefreshMonitor(refreshCallback func()) {
 	tm.mu.Unlock()
 
 	go func() {
+		failed := false
 		for {
 			// Calculate time until token expires
 			tm.mu.RLock()
-			timeUntilExpiry := time.Until(tm.expirationTime)
+			expiresAt := tm.expirationTime
 			tm.mu.RUnlock()
+			timeUntilExpiry := time.Until(expiresAt)
 
 			// Refresh 5 minutes before expiration (safer than 10 min before)
 			refreshTime := timeUntilExpiry - (5 * time.Minute)
 
-			// If already expired or expiring soon, refresh immediately
+			// If already expired or expiring soon, refresh immediately, or
+			// after a pause when the last attempt failed
 			if refreshTime <= 0 {
 				refreshTime = 1 * time.Second
+				if failed {
+					refreshTime = renewRetryInterval
+				}
 			}
 
 			tm.log.Debugf("Token refresh scheduled in %v (expires at %v)",
-				refreshTime, tm.expirationTime.Format("3:04 PM"))
+				refreshTime, expiresAt.Format("3:04 PM"))
 
 			// Wait until refresh time or stop signal
 			select {
@@ -407,8 +746,13 @@ func (tm *TokenManager) StartTokenRefreshMonitor(refreshCallback func()) {
 			}
 
 			if err := tm.RenewAccessToken(); err != nil {
+				failed = true
 				tm.log.Errorf("Failed to renew access token: %v", err)
+				if failureCallback != nil {
+					failureCallback(err)
+				}
 			} else {
+				failed = false
 				if tm.log != nil {
 					tm.log.Debug("Tokens refreshed successfully")
 				}
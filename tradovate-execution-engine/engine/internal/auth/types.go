@@ -19,10 +19,52 @@ type TokenManager struct {
 	expirationTime  time.Time
 	userID          int
 	accountID       int
+	accountName     string
 	username        string
 	credentials     map[string]interface{}
 	baseURL         string
 	log             *logger.Logger
 	config          config.Config
 	monitorStopChan chan struct{}
+	limiter         *RateLimiter
+	maxRetries      int       // Retries allowed for a rate limited request
+	penaltyUntil    time.Time // Tradovate penalty window (p-time) end
+	cachePath       string    // Token cache file (empty = caching disabled)
+}
+
+// tokenCache is the session persisted between restarts. It is only reused when the
+// credentials and device ID that created it are unchanged.
+type tokenCache struct {
+	AccessToken    string    `json:"accessToken"`
+	MDAccessToken  string    `json:"mdAccessToken"`
+	ExpirationTime time.Time `json:"expirationTime"`
+	UserID         int       `json:"userId"`
+	Name           string    `json:"name"`
+	DeviceID       string    `json:"deviceId"`
+	Fingerprint    string    `json:"fingerprint"` // Hash of the credentials and environment
+}
+
+//
+// RATE LIMITER
+//
+
+// tokenBucket tracks available request tokens for one endpoint group
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// RateLimiter is a token bucket limiter keyed by endpoint group ("order", "account", ...)
+type RateLimiter struct {
+	mu         sync.Mutex
+	buckets    map[string]*tokenBucket
+	capacity   float64 // Burst size
+	refillRate float64 // Tokens per second
+}
+
+// Penalty is the p-ticket/p-time pair Tradovate returns when a client is rate limited
+type Penalty struct {
+	Ticket  string  `json:"p-ticket"`
+	Time    float64 `json:"p-time"` // Seconds to wait before retrying
+	Captcha bool    `json:"p-captcha"`
 }
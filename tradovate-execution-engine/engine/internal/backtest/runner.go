@@ -0,0 +1,181 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// Run feeds the configured bars through a fresh strategy instance, filling its
+// orders in the simulator, and returns the resulting report
+func Run(cfg Config, log *logger.Logger) (*Report, error) {
+	if len(cfg.Bars) == 0 {
+		return nil, fmt.Errorf("no bars to backtest")
+	}
+	if log == nil {
+		log = logger.NewLogger(100, logger.LevelWarn)
+	}
+
+	strategy, err := execution.CreateStrategy(cfg.Strategy, log)
+	if err != nil {
+		return nil, err
+	}
+
+	barStrategy, ok := strategy.(interface {
+		OnBar(string, float64) error
+	})
+	if !ok {
+		return nil, fmt.Errorf("strategy %s does not process bars", cfg.Strategy)
+	}
+
+	// Historical bars are fetched as one minute bars only
+	if barType := cfg.Params["bar_type"]; barType != "" && marketdata.BarType(barType) != marketdata.BarTypeMinute {
+		return nil, fmt.Errorf("backtests only support minute bars, not %s bars", barType)
+	}
+
+	if err := execution.ValidateParams(strategy, cfg.Params); err != nil {
+		return nil, err
+	}
+	for name, value := range cfg.Params {
+		if err := strategy.SetParam(name, value); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", name, err)
+		}
+	}
+	if cfg.Symbol != "" {
+		// Keep the strategy and the simulator on the same symbol
+		if err := strategy.SetParam("symbol", cfg.Symbol); err != nil {
+			return nil, fmt.Errorf("failed to set symbol: %w", err)
+		}
+	}
+
+	sim := execution.NewSimulatedExecutor()
+	if cfg.ValuePerPoint > 0 {
+		sim.SetValuePerPoint(cfg.Symbol, cfg.ValuePerPoint)
+	}
+	if cfg.TickSize > 0 {
+		sim.SetTickSize(cfg.Symbol, cfg.TickSize)
+	}
+	sim.SetFees(cfg.Symbol, cfg.Fees.PerContract(cfg.Symbol))
+
+	om := execution.NewSimulatedOrderManager(sim, &config.Config{Risk: cfg.Risk, Slippage: cfg.Slippage}, log)
+	if err := strategy.Init(om); err != nil {
+		return nil, fmt.Errorf("failed to initialize strategy: %w", err)
+	}
+	if s, ok := strategy.(interface{ SetEnabled(bool) }); ok {
+		s.SetEnabled(true)
+	}
+	if err := execution.StartLifecycle(strategy); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Strategy:    cfg.Strategy,
+		Symbol:      cfg.Symbol,
+		Bars:        len(cfg.Bars),
+		EquityCurve: make([]EquityPoint, 0, len(cfg.Bars)),
+	}
+
+	var peak float64
+	for _, bar := range cfg.Bars {
+		sim.SetMarket(cfg.Symbol, bar.Close, bar.Timestamp)
+
+		if err := barStrategy.OnBar(bar.Timestamp, bar.Close); err != nil {
+			log.Warnf("Bar %s: %v", bar.Timestamp, err)
+		}
+
+		pos := sim.GetPosition(cfg.Symbol)
+		equity := pos.RealizedPnL - pos.Fees + sim.GetUnrealizedPnL(cfg.Symbol)
+		report.EquityCurve = append(report.EquityCurve, EquityPoint{Timestamp: bar.Timestamp, Equity: equity})
+
+		if equity > peak {
+			peak = equity
+		}
+		if dd := peak - equity; dd > report.MaxDrawdown {
+			report.MaxDrawdown = dd
+		}
+	}
+
+	if err := execution.StopLifecycle(strategy); err != nil {
+		log.Warnf("Strategy lifecycle: %v", err)
+	}
+
+	report.Trades = sim.GetTrades()
+	report.NumTrades = len(report.Trades)
+	for _, trade := range report.Trades {
+		if trade.NetPnL > 0 {
+			report.Wins++
+		} else {
+			report.Losses++
+		}
+	}
+	if report.NumTrades > 0 {
+		report.WinRate = float64(report.Wins) / float64(report.NumTrades) * 100
+	}
+	report.TotalPnL = report.EquityCurve[len(report.EquityCurve)-1].Equity
+	report.Fees = sim.GetPosition(cfg.Symbol).Fees
+	report.GrossPnL = report.TotalPnL + report.Fees
+
+	vpp := cfg.ValuePerPoint
+	if vpp <= 0 {
+		vpp = 1
+	}
+	report.Fills = sim.GetFills()
+	for _, fill := range report.Fills {
+		report.Slippage += fill.Slippage * float64(fill.Quantity) * vpp
+	}
+
+	return report, nil
+}
+
+// FetchBars requests one minute bars for a symbol over [from, to] through md/getchart,
+// in as many pages as the range needs, giving up after timeout
+func FetchBars(md *tradovate.DataSubscriber, symbol string, from, to time.Time, timeout time.Duration) ([]marketdata.Bar, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("end of range must be after start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	history, err := md.LoadHistory(ctx, tradovate.HistoryRequest{
+		Params: marketdata.HistoricalDataParams{
+			Symbol: symbol,
+			ChartDescription: marketdata.ChartDesc{
+				UnderlyingType:  "MinuteBar",
+				ElementSize:     1,
+				ElementSizeUnit: "UnderlyingUnits",
+			},
+			TimeRange: marketdata.TimeRange{ClosestTimestamp: to.UTC().Format(time.RFC3339)},
+		},
+		Bars: int(to.Sub(from).Minutes()) + 1,
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out waiting for historical bars")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bars := make([]marketdata.Bar, 0, len(history))
+	for _, bar := range history {
+		ts, err := marketdata.ParseFeedTime(bar.Timestamp)
+		if err != nil || ts.Before(from) || ts.After(to) {
+			continue
+		}
+		bars = append(bars, bar)
+	}
+	return bars, nil
+}
+
+// Summary returns a one line description of the report
+func (r *Report) Summary() string {
+	return fmt.Sprintf("%s %s | Bars: %d | Trades: %d | Win Rate: %.1f%% | PnL: $%.2f (gross $%.2f, fees $%.2f, slippage $%.2f) | Max DD: $%.2f",
+		r.Strategy, r.Symbol, r.Bars, r.NumTrades, r.WinRate, r.TotalPnL, r.GrossPnL, r.Fees, r.Slippage, r.MaxDrawdown)
+}
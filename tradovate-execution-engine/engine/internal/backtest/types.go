@@ -0,0 +1,49 @@
+package backtest
+
+import (
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+//
+// BACKTEST
+//
+
+// Config describes a single backtest run
+type Config struct {
+	Strategy      string            // Registered strategy name, e.g. "ma_crossover"
+	Params        map[string]string // Strategy parameters applied before Init
+	Symbol        string
+	ValuePerPoint float64 // Dollar value of a one point move (defaults to 1)
+	TickSize      float64 // Minimum price increment, for tick based exits (0 = unknown)
+	Bars          []marketdata.Bar
+	Risk          config.RiskConfig
+	Fees          config.FeeConfig      // Charged on every simulated fill
+	Slippage      config.SlippageConfig // How simulated fills are moved against the orders, as in paper trading
+}
+
+// EquityPoint is the account equity (realized + open PnL, less fees paid) at the close of a bar
+type EquityPoint struct {
+	Timestamp string
+	Equity    float64
+}
+
+// Report summarises the result of a backtest
+type Report struct {
+	Strategy    string
+	Symbol      string
+	Bars        int
+	TotalPnL    float64 // Net of fees
+	GrossPnL    float64
+	Fees        float64
+	Slippage    float64 // Dollars the slippage model cost, already in the PnL
+	MaxDrawdown float64
+	WinRate     float64 // Percentage of closed trades with positive net PnL
+	NumTrades   int
+	Wins        int
+	Losses      int
+	Trades      []execution.SimTrade
+	Fills       []execution.SimFill // Each with its slippage model and slippage
+	EquityCurve []EquityPoint
+}
@@ -0,0 +1,192 @@
+package contracts
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// catalogRetryInterval is how long a failed lookup is remembered before retrying
+const catalogRetryInterval = time.Minute
+
+// NewCatalog creates an empty catalog backed by api
+func NewCatalog(api JSONGetter, log *logger.Logger) *Catalog {
+	return &Catalog{
+		api:    api,
+		log:    log,
+		specs:  make(map[string]ContractSpec),
+		byID:   make(map[int]string),
+		failed: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Spec returns the spec of a concrete contract such as MESH6, fetching it on
+// first use
+func (c *Catalog) Spec(symbol string) (ContractSpec, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if spec, ok, err := c.cached(symbol); ok || err != nil {
+		return spec, err
+	}
+
+	var contract Contract
+	err := c.api.GetJSON("/v1/contract/find?name="+url.QueryEscape(symbol), &contract)
+	if err == nil && contract.ID == 0 {
+		err = fmt.Errorf("contract %s not found", symbol)
+	}
+	if err != nil {
+		return ContractSpec{}, c.fail(symbol, fmt.Errorf("failed to look up contract %s: %w", symbol, err))
+	}
+	return c.complete(symbol, contract)
+}
+
+// SpecByID returns the spec of the contract with a Tradovate ID, as found in
+// positions and fills
+func (c *Catalog) SpecByID(contractID int) (ContractSpec, error) {
+	key := "#" + strconv.Itoa(contractID)
+	c.mu.Lock()
+	symbol, known := c.byID[contractID]
+	c.mu.Unlock()
+	if known {
+		return c.Spec(symbol)
+	}
+	if _, ok, err := c.cached(key); ok || err != nil {
+		return ContractSpec{}, err
+	}
+
+	var contract Contract
+	err := c.api.GetJSON("/v1/contract/item?id="+strconv.Itoa(contractID), &contract)
+	if err == nil && contract.Name == "" {
+		err = fmt.Errorf("contract %d not found", contractID)
+	}
+	if err != nil {
+		return ContractSpec{}, c.fail(key, fmt.Errorf("failed to look up contract %d: %w", contractID, err))
+	}
+	return c.complete(strings.ToUpper(contract.Name), contract)
+}
+
+// complete fetches the contract's maturity and product and caches the result
+func (c *Catalog) complete(symbol string, contract Contract) (ContractSpec, error) {
+	spec := ContractSpec{Symbol: symbol, ContractID: contract.ID}
+
+	match := contractPattern.FindStringSubmatch(symbol)
+	if match == nil {
+		return ContractSpec{}, c.fail(symbol, fmt.Errorf("%s is not a contract symbol", symbol))
+	}
+	spec.Product = match[1]
+
+	if contract.ContractMaturityID != 0 {
+		var maturity ContractMaturity
+		if err := c.api.GetJSON("/v1/contractMaturity/item?id="+strconv.Itoa(contract.ContractMaturityID), &maturity); err == nil {
+			spec.Expiration, _ = time.Parse(time.RFC3339, maturity.ExpirationDate)
+		} else if c.log != nil {
+			// Expiry is informational; pricing only needs the product
+			c.log.Debugf("Maturity lookup for %s failed: %v", symbol, err)
+		}
+	}
+
+	var product Product
+	err := c.api.GetJSON("/v1/product/find?name="+url.QueryEscape(spec.Product), &product)
+	if err == nil && (product.TickSize <= 0 || product.ValuePerPoint <= 0) {
+		err = fmt.Errorf("product %s has no tick size or value per point", spec.Product)
+	}
+	if err != nil {
+		return ContractSpec{}, c.fail(symbol, fmt.Errorf("failed to look up product %s: %w", spec.Product, err))
+	}
+	spec.TickSize = product.TickSize
+	spec.ValuePerPoint = product.ValuePerPoint
+
+	c.mu.Lock()
+	c.specs[symbol] = spec
+	if spec.ContractID != 0 {
+		c.byID[spec.ContractID] = symbol
+	}
+	delete(c.failed, symbol)
+	c.mu.Unlock()
+
+	if c.log != nil {
+		c.log.Debugf("Contract %s: tick %g, $%g per point", symbol, spec.TickSize, spec.ValuePerPoint)
+	}
+	return spec, nil
+}
+
+// cached returns a cached spec, or an error if key failed within the retry interval
+func (c *Catalog) cached(key string) (ContractSpec, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if spec, ok := c.specs[key]; ok {
+		return spec, true, nil
+	}
+	if at, ok := c.failed[key]; ok && c.now().Sub(at) < catalogRetryInterval {
+		return ContractSpec{}, false, fmt.Errorf("lookup of %s failed recently, retrying after %v",
+			strings.TrimPrefix(key, "#"), catalogRetryInterval)
+	}
+	return ContractSpec{}, false, nil
+}
+
+// fail remembers that key could not be looked up and returns err
+func (c *Catalog) fail(key string, err error) error {
+	c.mu.Lock()
+	c.failed[key] = c.now()
+	c.mu.Unlock()
+	if c.log != nil {
+		c.log.Warnf("Contract catalog: %v", err)
+	}
+	return err
+}
+
+// TickSize returns the contract's tick size, or 0 if it cannot be looked up
+func (c *Catalog) TickSize(symbol string) float64 {
+	spec, err := c.Spec(symbol)
+	if err != nil {
+		return 0
+	}
+	return spec.TickSize
+}
+
+// ValuePerPoint returns the dollar value of a one point move, or 0 if it cannot be looked up
+func (c *Catalog) ValuePerPoint(symbol string) float64 {
+	spec, err := c.Spec(symbol)
+	if err != nil {
+		return 0
+	}
+	return spec.ValuePerPoint
+}
+
+// RoundToTick rounds price to the nearest tick of the contract
+func (c *Catalog) RoundToTick(symbol string, price float64) (float64, error) {
+	spec, err := c.Spec(symbol)
+	if err != nil {
+		return price, err
+	}
+	return RoundPrice(price, spec.TickSize), nil
+}
+
+// TicksToPrice converts a distance in ticks to a price distance for the contract
+func (c *Catalog) TicksToPrice(symbol string, ticks int) (float64, error) {
+	spec, err := c.Spec(symbol)
+	if err != nil {
+		return 0, err
+	}
+	return RoundPrice(float64(ticks)*spec.TickSize, spec.TickSize), nil
+}
+
+// RoundPrice rounds price to the nearest multiple of tick. The result is cleaned
+// of float noise so 0.25 ticks give 5010.25, not 5010.250000000001.
+func RoundPrice(price, tick float64) float64 {
+	if tick <= 0 {
+		return price
+	}
+	rounded := math.Round(price/tick) * tick
+	return math.Round(rounded*1e9) / 1e9
+}
+
+// OnTick reports whether price is a multiple of tick, allowing for float
+// error. Any price is on an unknown (zero) tick.
+func OnTick(price, tick float64) bool {
+	return tick <= 0 || math.Abs(RoundPrice(price, tick)-price) < 1e-9
+}
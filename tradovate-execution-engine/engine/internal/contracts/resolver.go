@@ -0,0 +1,177 @@
+package contracts
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// contractPattern matches a concrete futures symbol: root, month code, year digits
+var contractPattern = regexp.MustCompile(`^([A-Z0-9]+?)([FGHJKMNQUVXZ])(\d{1,2})$`)
+
+// NewResolver creates a resolver that rolls rolloverDays before expiry
+func NewResolver(api JSONGetter, rolloverDays int, log *logger.Logger) *Resolver {
+	if rolloverDays < 0 {
+		rolloverDays = 0
+	}
+	return &Resolver{
+		api:          api,
+		log:          log,
+		rolloverDays: rolloverDays,
+		cache:        make(map[string]string),
+		overrides:    make(map[string]string),
+		now:          time.Now,
+	}
+}
+
+// IsContract reports whether symbol is already a concrete contract (e.g. MESH6)
+func IsContract(symbol string) bool {
+	return contractPattern.MatchString(strings.ToUpper(symbol))
+}
+
+// Root returns the product root of symbol: MESH6 gives MES, and a symbol that
+// is not a concrete contract is returned as it is, upper-cased
+func Root(symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if match := contractPattern.FindStringSubmatch(symbol); match != nil {
+		return match[1]
+	}
+	return symbol
+}
+
+// Resolve returns the contract to trade for symbol. Concrete contracts are
+// returned unchanged; roots use the override, the session cache or the API.
+func (r *Resolver) Resolve(symbol string) (string, error) {
+	root := strings.ToUpper(strings.TrimSpace(symbol))
+	if root == "" {
+		return "", fmt.Errorf("empty symbol")
+	}
+	if IsContract(root) {
+		return root, nil
+	}
+
+	r.mu.Lock()
+	if contract, ok := r.overrides[root]; ok {
+		r.mu.Unlock()
+		return contract, nil
+	}
+	if contract, ok := r.cache[root]; ok {
+		r.mu.Unlock()
+		return contract, nil
+	}
+	r.mu.Unlock()
+
+	contract, err := r.lookup(root)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.cache[root] = contract
+	r.mu.Unlock()
+
+	if r.log != nil {
+		r.log.Infof("Resolved %s to front-month contract %s", root, contract)
+	}
+	return contract, nil
+}
+
+// SetOverride pins root to a specific contract for the rest of the session
+func (r *Resolver) SetOverride(root, contract string) error {
+	root, contract = strings.ToUpper(root), strings.ToUpper(contract)
+	if !IsContract(contract) {
+		return fmt.Errorf("%s is not a contract symbol (e.g. %sM6)", contract, root)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides[root] = contract
+	return nil
+}
+
+// ClearOverride returns root to automatic resolution and drops its cached result
+func (r *Resolver) ClearOverride(root string) {
+	root = strings.ToUpper(root)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.overrides, root)
+	delete(r.cache, root)
+}
+
+// ContractID returns the Tradovate ID of a concrete contract, used to match
+// quotes to the contract they belong to
+func (r *Resolver) ContractID(contract string) (int, error) {
+	var found Contract
+	if err := r.api.GetJSON("/v1/contract/find?name="+url.QueryEscape(strings.ToUpper(contract)), &found); err != nil {
+		return 0, fmt.Errorf("failed to look up contract %s: %w", contract, err)
+	}
+	if found.ID == 0 {
+		return 0, fmt.Errorf("contract %s not found", contract)
+	}
+	return found.ID, nil
+}
+
+// lookup queries Tradovate for the root's contracts and their maturities
+func (r *Resolver) lookup(root string) (string, error) {
+	var candidates []Contract
+	if err := r.api.GetJSON("/v1/contract/suggest?t="+url.QueryEscape(root)+"&l=20", &candidates); err != nil {
+		return "", fmt.Errorf("failed to look up contracts for %s: %w", root, err)
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if c.ContractMaturityID != 0 {
+			ids = append(ids, strconv.Itoa(c.ContractMaturityID))
+		}
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("%w: no contracts found for %s", ErrUnknownSymbol, root)
+	}
+
+	var maturities []ContractMaturity
+	if err := r.api.GetJSON("/v1/contractMaturity/items?ids="+strings.Join(ids, ","), &maturities); err != nil {
+		return "", fmt.Errorf("failed to look up maturities for %s: %w", root, err)
+	}
+
+	return FrontMonth(root, candidates, maturities, r.now(), r.rolloverDays)
+}
+
+// FrontMonth picks the nearest contract for root that expires more than
+// rolloverDays after now
+func FrontMonth(root string, candidates []Contract, maturities []ContractMaturity, now time.Time, rolloverDays int) (string, error) {
+	expirations := make(map[int]time.Time, len(maturities))
+	for _, m := range maturities {
+		if t, err := time.Parse(time.RFC3339, m.ExpirationDate); err == nil {
+			expirations[m.ID] = t
+		}
+	}
+
+	type dated struct {
+		name    string
+		expires time.Time
+	}
+	var live []dated
+	cutoff := now.AddDate(0, 0, rolloverDays)
+	for _, c := range candidates {
+		// Suggest matches by prefix, so other products and spreads can be in the list
+		match := contractPattern.FindStringSubmatch(c.Name)
+		if match == nil || match[1] != root {
+			continue
+		}
+		expires, ok := expirations[c.ContractMaturityID]
+		if !ok || !expires.After(cutoff) {
+			continue
+		}
+		live = append(live, dated{name: c.Name, expires: expires})
+	}
+	if len(live) == 0 {
+		return "", fmt.Errorf("no contract for %s expires more than %d days out", root, rolloverDays)
+	}
+
+	sort.Slice(live, func(i, j int) bool { return live[i].expires.Before(live[j].expires) })
+	return live[0].name, nil
+}
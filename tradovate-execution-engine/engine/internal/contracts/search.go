@@ -0,0 +1,111 @@
+package contracts
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSearchLimit is the number of matches Search returns for a limit of 0
+const DefaultSearchLimit = 20
+
+// Search returns up to limit contracts whose names start with text, with their
+// product description and expiration. Descriptions and expirations are
+// informational, so a failure looking them up leaves them empty.
+func (c *Catalog) Search(text string, limit int) ([]ContractMatch, error) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	if text == "" {
+		return nil, fmt.Errorf("empty search")
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	var found []Contract
+	if err := c.api.GetJSON("/v1/contract/suggest?t="+url.QueryEscape(text)+"&l="+strconv.Itoa(limit), &found); err != nil {
+		return nil, fmt.Errorf("failed to search contracts for %s: %w", text, err)
+	}
+	if len(found) > limit {
+		found = found[:limit]
+	}
+
+	var ids []string
+	for _, f := range found {
+		if f.ContractMaturityID != 0 {
+			ids = append(ids, strconv.Itoa(f.ContractMaturityID))
+		}
+	}
+	maturities := make(map[int]ContractMaturity)
+	if len(ids) > 0 {
+		var items []ContractMaturity
+		if err := c.api.GetJSON("/v1/contractMaturity/items?ids="+strings.Join(ids, ","), &items); err != nil {
+			c.debugf("Maturity lookup for search %s failed: %v", text, err)
+		}
+		for _, m := range items {
+			maturities[m.ID] = m
+		}
+	}
+
+	ids = ids[:0]
+	seen := make(map[int]bool)
+	for _, m := range maturities {
+		if m.ProductID != 0 && !seen[m.ProductID] {
+			seen[m.ProductID] = true
+			ids = append(ids, strconv.Itoa(m.ProductID))
+		}
+	}
+	products := make(map[int]Product)
+	if len(ids) > 0 {
+		var items []Product
+		if err := c.api.GetJSON("/v1/product/items?ids="+strings.Join(ids, ","), &items); err != nil {
+			c.debugf("Product lookup for search %s failed: %v", text, err)
+		}
+		for _, p := range items {
+			products[p.ID] = p
+		}
+	}
+
+	matches := make([]ContractMatch, 0, len(found))
+	for _, f := range found {
+		match := ContractMatch{ID: f.ID, Name: strings.ToUpper(f.Name)}
+		if m, ok := maturities[f.ContractMaturityID]; ok {
+			match.Expiration, _ = time.Parse(time.RFC3339, m.ExpirationDate)
+			match.Description = products[m.ProductID].Description
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// Validate checks that Tradovate lists a contract named symbol, returning an
+// error wrapping ErrUnknownSymbol if it does not. Contracts already in the
+// catalog pass without a lookup.
+func (c *Catalog) Validate(symbol string) error {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	c.mu.Lock()
+	_, known := c.specs[symbol]
+	c.mu.Unlock()
+	if known {
+		return nil
+	}
+
+	var found []Contract
+	if err := c.api.GetJSON("/v1/contract/suggest?t="+url.QueryEscape(symbol)+"&l="+strconv.Itoa(DefaultSearchLimit), &found); err != nil {
+		return fmt.Errorf("failed to look up contract %s: %w", symbol, err)
+	}
+	for _, f := range found {
+		if strings.EqualFold(f.Name, symbol) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %s", ErrUnknownSymbol, symbol)
+}
+
+// debugf logs to the catalog's logger, if it has one
+func (c *Catalog) debugf(format string, args ...interface{}) {
+	if c.log != nil {
+		c.log.Debugf(format, args...)
+	}
+}
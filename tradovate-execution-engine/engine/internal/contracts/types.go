@@ -0,0 +1,91 @@
+package contracts
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+//
+// CONTRACT RESOLVER
+//
+
+// JSONGetter performs authenticated GET requests against the Tradovate REST API
+type JSONGetter interface {
+	GetJSON(endpoint string, out interface{}) error
+}
+
+// ErrUnknownSymbol is wrapped by lookups that found no contract for a symbol,
+// as opposed to lookups that failed
+var ErrUnknownSymbol = errors.New("unknown symbol")
+
+// Contract is a Tradovate contract as returned by contract/suggest
+type Contract struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	ContractMaturityID int    `json:"contractMaturityId"`
+}
+
+// ContractMaturity holds the expiration of a contract
+type ContractMaturity struct {
+	ID             int    `json:"id"`
+	ProductID      int    `json:"productId"`
+	ExpirationDate string `json:"expirationDate"`
+}
+
+// Resolver maps product roots like "MES" to the current front-month contract.
+// Results are cached for the session; overrides take precedence.
+type Resolver struct {
+	mu           sync.Mutex
+	api          JSONGetter
+	log          *logger.Logger
+	rolloverDays int               // Roll to the next contract this many days before expiry
+	cache        map[string]string // root -> resolved contract
+	overrides    map[string]string // root -> contract set by the user
+	now          func() time.Time
+}
+
+//
+// CONTRACT CATALOG
+//
+
+// Product is a Tradovate product (e.g. MES) as returned by product/find
+type Product struct {
+	ID            int     `json:"id"`
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	TickSize      float64 `json:"tickSize"`
+	ValuePerPoint float64 `json:"valuePerPoint"`
+}
+
+// ContractSpec is what the engine needs to price and value a contract
+type ContractSpec struct {
+	Symbol        string
+	ContractID    int
+	Product       string
+	TickSize      float64
+	ValuePerPoint float64
+	Expiration    time.Time // Zero if Tradovate did not report one
+}
+
+// ContractMatch is a contract found by Catalog.Search
+type ContractMatch struct {
+	ID          int
+	Name        string
+	Description string    // Product description, e.g. "Micro E-mini S&P 500"
+	Expiration  time.Time // Zero if Tradovate did not report one
+}
+
+// Catalog looks up contract specs on demand and caches them for the session.
+// A failed lookup is not retried until catalogRetryInterval has passed, so
+// callers on a hot path (every quote) do not hammer the API.
+type Catalog struct {
+	mu     sync.Mutex
+	api    JSONGetter
+	log    *logger.Logger
+	specs  map[string]ContractSpec // Keyed by contract symbol
+	byID   map[int]string          // Contract ID -> symbol
+	failed map[string]time.Time    // Symbol (or "#id") -> when its lookup last failed
+	now    func() time.Time
+}
@@ -0,0 +1,77 @@
+package execution
+
+import (
+	"fmt"
+	"strings"
+
+	"tradovate-execution-engine/engine/internal/audit"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// SetAuditTrail records every order created, modified or changing status, and
+// every flatten, to trail; nil stops recording
+func (om *OrderManager) SetAuditTrail(trail *audit.Trail) {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	om.audit = trail
+}
+
+// auditTrail returns the trail set with SetAuditTrail, nil if none
+func (om *OrderManager) auditTrail() *audit.Trail {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	return om.audit
+}
+
+// auditOrder records action on a copy of order, attributed to its origin
+func auditOrder(trail *audit.Trail, order models.Order, action string) {
+	if trail == nil {
+		return
+	}
+	actor := order.Origin
+	if actor == "" {
+		actor = audit.ActorSystem
+	}
+	details := map[string]interface{}{
+		"orderId":  order.ID,
+		"symbol":   order.Symbol,
+		"side":     order.Side,
+		"type":     order.Type,
+		"quantity": order.Quantity,
+		"status":   order.Status,
+	}
+	if order.ExternalID != "" {
+		details["externalId"] = order.ExternalID
+	}
+	if order.Price != 0 {
+		details["price"] = order.Price
+	}
+	if order.StopPrice != 0 {
+		details["stopPrice"] = order.StopPrice
+	}
+	if order.RejectReason != "" {
+		details["reason"] = order.RejectReason
+	}
+	if filled := order.FilledQty(); filled > 0 {
+		details["filledQty"] = filled
+		details["avgPrice"] = order.AvgFillPrice()
+	}
+	trail.Record(actor, action, details)
+}
+
+// statusAction is the audit action of an order reaching status, e.g. order_filled
+func statusAction(status models.OrderStatus) string {
+	return "order_" + strings.ToLower(string(status))
+}
+
+// auditFlatten records a flatten by actor and the closing orders it sends
+func auditFlatten(trail *audit.Trail, actor string, closing []FlattenClose) {
+	if trail == nil {
+		return
+	}
+	positions := make([]string, 0, len(closing))
+	for _, c := range closing {
+		positions = append(positions, fmt.Sprintf("%s %d %s", c.Side, c.Quantity, c.Symbol))
+	}
+	trail.Record(actor, audit.ActionFlatten, map[string]interface{}{"orders": positions})
+}
@@ -0,0 +1,264 @@
+package execution
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/contracts"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// DefaultEntryTimeout is how long a limit entry rests before its fallback
+const DefaultEntryTimeout = 30 * time.Second
+
+// DefaultEntryPolicy enters at market
+func DefaultEntryPolicy() EntryPolicy {
+	return EntryPolicy{Type: EntryMarket, Timeout: DefaultEntryTimeout, Fallback: EntryFallbackCancel}
+}
+
+// ParseEntryType reads an entry_type value: market, limit_join or
+// limit_through_N_ticks, returning N for the last
+func ParseEntryType(value string) (EntryType, int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case string(EntryMarket):
+		return EntryMarket, 0, nil
+	case string(EntryLimitJoin):
+		return EntryLimitJoin, 0, nil
+	}
+	if n, ok := strings.CutPrefix(value, "limit_through_"); ok {
+		n = strings.TrimSuffix(strings.TrimSuffix(n, "_ticks"), "_tick")
+		if ticks, err := strconv.Atoi(n); err == nil && ticks > 0 {
+			return EntryLimitThrough, ticks, nil
+		}
+	}
+	return "", 0, fmt.Errorf("entry_type must be market, limit_join or limit_through_N_ticks (N >= 1), got %q", value)
+}
+
+// TypeString is the policy's entry_type value, e.g. limit_through_2_ticks
+func (p EntryPolicy) TypeString() string {
+	if p.Type == EntryLimitThrough {
+		return fmt.Sprintf("limit_through_%d_ticks", p.ThroughTicks)
+	}
+	if p.Type == "" {
+		return string(EntryMarket)
+	}
+	return string(p.Type)
+}
+
+// Params describes the entry_type, entry_timeout and entry_fallback params of
+// a strategy that takes an EntryPolicy
+func (p EntryPolicy) Params() []StrategyParam {
+	return []StrategyParam{
+		{
+			Name:        "entry_type",
+			Type:        "string",
+			Value:       p.TypeString(),
+			Description: "Entries at market, as a limit at the bid/ask (limit_join) or N ticks through it (limit_through_N_ticks)",
+		},
+		{
+			Name:        "entry_timeout",
+			Type:        "int",
+			Value:       strconv.Itoa(int(p.Timeout / time.Second)),
+			Description: "Seconds a limit entry may rest unfilled before entry_fallback",
+			Min:         Bound(1),
+		},
+		{
+			Name:        "entry_fallback",
+			Type:        "string",
+			Value:       string(p.Fallback),
+			Description: "What an unfilled limit entry does at entry_timeout: cancel, or convert to market",
+			Options:     []string{string(EntryFallbackCancel), string(EntryFallbackMarket)},
+		},
+	}
+}
+
+// SetParam sets one of the params Params describes. It reports false for any
+// other name so the strategy can handle it.
+func (p *EntryPolicy) SetParam(name, value string) (bool, error) {
+	switch name {
+	case "entry_type":
+		t, ticks, err := ParseEntryType(value)
+		if err != nil {
+			return true, err
+		}
+		p.Type, p.ThroughTicks = t, ticks
+	case "entry_timeout":
+		secs, err := strconv.Atoi(value)
+		if err != nil {
+			return true, fmt.Errorf("invalid entry_timeout: %w", err)
+		}
+		if secs <= 0 {
+			return true, fmt.Errorf("entry_timeout must be positive")
+		}
+		p.Timeout = time.Duration(secs) * time.Second
+	case "entry_fallback":
+		switch EntryFallback(strings.ToLower(value)) {
+		case EntryFallbackCancel, EntryFallbackMarket:
+			p.Fallback = EntryFallback(strings.ToLower(value))
+		default:
+			return true, fmt.Errorf("entry_fallback must be cancel or market, got %q", value)
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// SetEntryPolicy places the entries of origin, a strategy instance, by policy
+// until ClearEntryPolicy
+func (om *OrderManager) SetEntryPolicy(origin string, policy EntryPolicy) {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	if om.entryPolicies == nil {
+		om.entryPolicies = make(map[string]EntryPolicy)
+	}
+	om.entryPolicies[origin] = policy
+}
+
+// ClearEntryPolicy returns origin's entries to market orders
+func (om *OrderManager) ClearEntryPolicy(origin string) {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	delete(om.entryPolicies, origin)
+}
+
+// SetQuoteSource sets where limit entries get the bid and ask from
+func (om *OrderManager) SetQuoteSource(q QuoteSource) {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	om.quotes = q
+}
+
+// entryLimit turns a market order of origin into a limit entry when its entry
+// policy asks for one, setting opts' type and price. It reports whether it did.
+// Orders that only reduce the position stay at market, and so does any entry
+// it cannot price: without a quote or a tick size, or when paper trading.
+func (om *OrderManager) entryLimit(symbol string, side models.OrderSide, quantity int, origin string, opts *OrderOptions) (EntryPolicy, bool) {
+	if opts.Type != models.TypeMarket {
+		return EntryPolicy{}, false
+	}
+	om.Mu.RLock()
+	policy, ok := om.entryPolicies[origin]
+	quotes, sim := om.quotes, om.simulator
+	om.Mu.RUnlock()
+	if !ok || policy.Type == EntryMarket || policy.Type == "" {
+		return policy, false
+	}
+
+	if pos := om.currentPosition(symbol); pos != nil && pos.NetPos != 0 {
+		held := pos.NetPos
+		if held < 0 {
+			held = -held
+		}
+		if reducing := (pos.NetPos > 0) == (side == models.SideSell); reducing && quantity <= held {
+			return policy, false
+		}
+	}
+	if sim != nil {
+		om.log.Debugf("Paper trading fills at market; %s entry on %s goes at market", policy.TypeString(), symbol)
+		return policy, false
+	}
+
+	var bid, ask float64
+	if quotes != nil {
+		bid, ask, ok = quotes.BestBidAsk(symbol)
+	}
+	tick := om.GetTickSize(symbol)
+	if quotes == nil || !ok || bid <= 0 || ask <= 0 || tick <= 0 {
+		om.log.Warnf("No bid/ask or tick size for %s - %s entry goes at market", symbol, policy.TypeString())
+		return policy, false
+	}
+
+	// Join the side we trade on; through moves toward the other side
+	price, through := bid, float64(policy.ThroughTicks)*tick
+	if side == models.SideSell {
+		price, through = ask, -through
+	}
+	if policy.Type == EntryLimitThrough {
+		price += through
+	}
+	opts.Type = models.TypeLimit
+	opts.Price = contracts.RoundPrice(price, tick)
+	om.log.Infof("%s entry: %s %d %s limit %.2f (bid %.2f / ask %.2f)", policy.TypeString(), side, quantity, symbol, opts.Price, bid, ask)
+	return policy, true
+}
+
+// watchEntry applies the policy's fallback to a limit entry still working once
+// its timeout has passed
+func (om *OrderManager) watchEntry(orderID string, policy EntryPolicy) {
+	timeout := policy.Timeout
+	if timeout <= 0 {
+		timeout = DefaultEntryTimeout
+	}
+	time.AfterFunc(timeout, func() { om.expireEntry(orderID, policy, timeout) })
+}
+
+// expireEntry converts a limit entry that outlived its timeout to market or
+// cancels it. One that has partly filled is converted either way, so the
+// strategy ends up with the whole position its fill reconciliation expects.
+func (om *OrderManager) expireEntry(orderID string, policy EntryPolicy, timeout time.Duration) {
+	om.Mu.RLock()
+	order, ok := om.orders[orderID]
+	var status models.OrderStatus
+	var filled, quantity int
+	if ok {
+		status, filled, quantity = order.Status, order.FilledQty(), order.Quantity
+	}
+	om.Mu.RUnlock()
+	if !ok || (status != models.StatusSubmitted && status != models.StatusPartiallyFilled) {
+		return
+	}
+
+	if policy.Fallback == EntryFallbackMarket || filled > 0 {
+		om.log.Warnf("Limit entry %s unfilled after %s (%d/%d filled) - converting to market", orderID, timeout, filled, quantity)
+		err := om.convertToMarket(orderID)
+		if err == nil {
+			return
+		}
+		om.log.Errorf("Limit entry %s not converted to market, cancelling: %v", orderID, err)
+	} else {
+		om.log.Warnf("Limit entry %s unfilled after %s - cancelling", orderID, timeout)
+	}
+	if err := om.CancelOrder(orderID); err != nil {
+		om.log.Errorf("Failed to cancel expired limit entry %s: %v", orderID, err)
+	}
+}
+
+// convertToMarket modifies a working limit order into a market order for the
+// same quantity
+func (om *OrderManager) convertToMarket(orderID string) error {
+	om.Mu.RLock()
+	order, exists := om.orders[orderID]
+	var externalID string
+	var quantity int
+	if exists {
+		externalID, quantity = order.ExternalID, order.Quantity
+	}
+	om.Mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("order not found: %s", orderID)
+	}
+	if externalID == "" {
+		return fmt.Errorf("order %s has no external ID yet", orderID)
+	}
+
+	request := map[string]interface{}{
+		"orderId":     externalID,
+		"orderQty":    quantity,
+		"orderType":   string(models.TypeMarket),
+		"isAutomated": true,
+	}
+	if err := om.sendOrderCommand("/v1/order/modifyorder", request); err != nil {
+		return fmt.Errorf("failed to convert order %s to market: %w", orderID, err)
+	}
+
+	om.Mu.Lock()
+	order.Type = models.TypeMarket
+	order.Price = 0
+	om.Mu.Unlock()
+	om.log.Infof("Order %s converted to market", orderID)
+	return nil
+}
@@ -0,0 +1,131 @@
+package execution
+
+import (
+	"strconv"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// externalOrder is what an order or fill event says about an exchange order
+// the engine has no local order for. Zero fields were not in the event.
+type externalOrder struct {
+	contractID int
+	action     string
+	orderType  string
+	qty        int
+	price      float64
+	stopPrice  float64
+}
+
+// externalFromOrderEvent takes what an order event says about its order
+func externalFromOrderEvent(event tradovate.OrderEvent) externalOrder {
+	ext := externalOrder{contractID: event.ContractID, action: event.Action, orderType: event.OrderType}
+	if event.HasQty {
+		ext.qty = event.Qty
+	}
+	if event.HasPrice {
+		ext.price = event.Price
+	}
+	if event.HasStopPrice {
+		ext.stopPrice = event.StopPrice
+	}
+	return ext
+}
+
+// trackExternal returns the local ID of the order for exchange order ID
+// externalID, creating an external order for it if there is none, and whether
+// it did. Orders placed from another platform are tracked like our own from
+// then on; if it turns out to be our own order in flight, AssignExternalID
+// adopts it. Callers must hold om.Mu.
+func (om *OrderManager) trackExternal(externalID string, ext externalOrder) (string, bool) {
+	if orderID, ok := om.externalIDs[externalID]; ok {
+		if order, ok := om.orders[orderID]; ok && order.IsExternal() {
+			ext.fillIn(order)
+		}
+		return orderID, false
+	}
+
+	symbol := strconv.Itoa(ext.contractID)
+	if om.portfolioTracker != nil {
+		if name, ok := om.portfolioTracker.GetContractName(ext.contractID); ok {
+			symbol = name
+		}
+	}
+	order := &models.Order{
+		ID:          "EXT-" + externalID,
+		Symbol:      symbol,
+		Side:        models.OrderSide(ext.action),
+		Type:        models.OrderType(ext.orderType),
+		Quantity:    ext.qty,
+		Price:       ext.price,
+		StopPrice:   ext.stopPrice,
+		Status:      models.StatusSubmitted,
+		SubmittedAt: time.Now(),
+		ExternalID:  externalID,
+		Origin:      models.OriginExternal,
+	}
+	// Seen only in a fill so far: taken for a market order, which never counts
+	// as working, until an order event says otherwise
+	if order.Type == "" {
+		order.Type = models.TypeMarket
+	}
+	om.orders[order.ID] = order
+	om.externalIDs[externalID] = order.ID
+	return order.ID, true
+}
+
+// fillIn copies to an external order the details a later event adds, such as
+// the type and size from an order event after the order was first seen in a fill
+func (ext externalOrder) fillIn(order *models.Order) {
+	if ext.orderType != "" {
+		order.Type = models.OrderType(ext.orderType)
+	}
+	if ext.qty > 0 {
+		order.Quantity = ext.qty
+	}
+	if ext.price != 0 {
+		order.Price = ext.price
+	}
+	if ext.stopPrice != 0 {
+		order.StopPrice = ext.stopPrice
+	}
+}
+
+// adoptExternal removes the external order created for externalID before
+// placeorder returned it for localID, and returns its fills and its final
+// status (empty while it is still working or suspended) for AssignExternalID
+// to replay.
+// Callers must hold om.Mu.
+func (om *OrderManager) adoptExternal(externalID, localID string) ([]models.Fill, models.OrderStatus, string) {
+	extID, ok := om.externalIDs[externalID]
+	if !ok || extID == localID {
+		return nil, "", ""
+	}
+	ext, ok := om.orders[extID]
+	if !ok || !ext.IsExternal() {
+		return nil, "", ""
+	}
+	delete(om.orders, extID)
+
+	switch ext.Status {
+	case models.StatusFilled, models.StatusCanceled, models.StatusExpired, models.StatusRejected:
+		return ext.Fills, ext.Status, ext.RejectReason
+	}
+	return ext.Fills, "", ""
+}
+
+// ExternalOrderCount returns how many orders placed outside the engine have been
+// seen in order and fill events this session
+func (om *OrderManager) ExternalOrderCount() int {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	count := 0
+	for _, order := range om.orders {
+		if order.IsExternal() {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,64 @@
+package execution
+
+import (
+	"fmt"
+	"sort"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// PreviewFlatten returns the orders CancelWorkingOrders would cancel and the
+// market orders FlattenPositions would send, without sending anything
+func (om *OrderManager) PreviewFlatten() (FlattenPlan, error) {
+	if om.portfolioTracker == nil {
+		return FlattenPlan{}, fmt.Errorf("portfolio tracker not initialized")
+	}
+	return FlattenPlan{Cancels: om.cancellableOrders(), Closes: om.closingOrders()}, nil
+}
+
+// Empty reports whether the plan would send nothing
+func (p FlattenPlan) Empty() bool {
+	return len(p.Cancels) == 0 && len(p.Closes) == 0
+}
+
+// cancellableOrders returns copies of the submitted resting orders and suspended
+// legs, the ones CancelWorkingOrders cancels, by symbol then ID
+func (om *OrderManager) cancellableOrders() []models.Order {
+	om.Mu.RLock()
+	var working []models.Order
+	for _, order := range om.orders {
+		resting := order.Status == models.StatusSubmitted || order.Status == models.StatusSuspended ||
+			order.Status == models.StatusPartiallyFilled
+		if resting && order.Type != models.TypeMarket && order.ExternalID != "" {
+			snapshot := *order
+			snapshot.Fills = append([]models.Fill(nil), order.Fills...)
+			working = append(working, snapshot)
+		}
+	}
+	om.Mu.RUnlock()
+
+	sort.Slice(working, func(i, j int) bool {
+		if working[i].Symbol != working[j].Symbol {
+			return working[i].Symbol < working[j].Symbol
+		}
+		return working[i].ID < working[j].ID
+	})
+	return working
+}
+
+// closingOrders returns the market order that closes each open position, by
+// symbol. Callers must check the portfolio tracker is set.
+func (om *OrderManager) closingOrders() []FlattenClose {
+	var closes []FlattenClose
+	for symbol, pos := range om.portfolioTracker.GetPLSummary() {
+		if pos.NetPos == 0 {
+			continue
+		}
+		side := models.SideSell
+		if pos.NetPos < 0 {
+			side = models.SideBuy
+		}
+		closes = append(closes, FlattenClose{Symbol: symbol, NetPos: pos.NetPos, Side: side, Quantity: models.Abs(pos.NetPos)})
+	}
+	sort.Slice(closes, func(i, j int) bool { return closes[i].Symbol < closes[j].Symbol })
+	return closes
+}
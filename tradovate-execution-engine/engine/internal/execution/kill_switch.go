@@ -0,0 +1,60 @@
+package execution
+
+// EngageKillSwitch makes SubmitOrder, SubmitMarketOrder and SubmitStopOrder
+// fail with ErrKillSwitch until Arm is called. Flatten orders still go through
+// so positions can be closed.
+func (om *OrderManager) EngageKillSwitch() {
+	om.Mu.Lock()
+	om.killed = true
+	om.Mu.Unlock()
+}
+
+// Arm accepts orders again after EngageKillSwitch
+func (om *OrderManager) Arm() {
+	om.Mu.Lock()
+	om.killed = false
+	om.Mu.Unlock()
+}
+
+// KillSwitchEngaged reports whether new orders are being refused
+func (om *OrderManager) KillSwitchEngaged() bool {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	return om.killed
+}
+
+// RequireLiveConfirm makes SubmitOrder, SubmitMarketOrder and SubmitStopOrder
+// fail with ErrLiveNotConfirmed until ConfirmLive is called. Flatten orders
+// still go through.
+func (om *OrderManager) RequireLiveConfirm() {
+	om.Mu.Lock()
+	om.liveLocked = true
+	om.Mu.Unlock()
+}
+
+// ConfirmLive accepts orders after RequireLiveConfirm
+func (om *OrderManager) ConfirmLive() {
+	om.Mu.Lock()
+	om.liveLocked = false
+	om.Mu.Unlock()
+}
+
+// LiveConfirmPending reports whether orders wait for ConfirmLive
+func (om *OrderManager) LiveConfirmPending() bool {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	return om.liveLocked
+}
+
+// orderLockout returns why new orders are refused, nil when they are not
+func (om *OrderManager) orderLockout() error {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	switch {
+	case om.killed:
+		return ErrKillSwitch
+	case om.liveLocked:
+		return ErrLiveNotConfirmed
+	}
+	return nil
+}
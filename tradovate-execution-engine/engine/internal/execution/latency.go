@@ -0,0 +1,99 @@
+package execution
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// maxLatencySamples bounds how many durations are kept per stage
+const maxLatencySamples = 1000
+
+// recordLatency stamps a live order's fill time, logs how long each stage took and
+// adds the durations to the session stats. Durations come from the monotonic
+// clock readings time.Now keeps, so a wall clock step cannot skew them.
+// Callers must hold om.Mu.
+func (om *OrderManager) recordLatency(order *models.Order) {
+	if om.simulator != nil || !order.FilledAt.IsZero() {
+		return
+	}
+	order.FilledAt = time.Now()
+	if order.SentAt.IsZero() {
+		return
+	}
+
+	var parts []string
+	add := func(label string, samples *[]time.Duration, from, to time.Time) {
+		if from.IsZero() || to.Before(from) {
+			return
+		}
+		d := to.Sub(from)
+		*samples = append(*samples, d)
+		if len(*samples) > maxLatencySamples {
+			*samples = (*samples)[1:]
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", label, formatLatency(d)))
+	}
+	add("signal→submit", &om.latency.signalToSubmit, order.SignalAt, order.SentAt)
+	add("submit→ack", &om.latency.submitToAck, order.SentAt, order.AckedAt)
+	// Stages whose events were applied out of order are left out
+	add("ack→fill", &om.latency.ackToFill, order.AckedAt, order.FilledAt)
+	add("total", &om.latency.signalToFill, order.SignalAt, order.FilledAt)
+
+	om.log.Infof("Order %s latency: %s", order.ID, strings.Join(parts, ", "))
+}
+
+// GetExecutionStats returns p50/p95/max latency of each stage for this
+// session's filled live orders
+func (om *OrderManager) GetExecutionStats() ExecutionStats {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	return ExecutionStats{
+		SignalToSubmit: summarizeLatency(om.latency.signalToSubmit),
+		SubmitToAck:    summarizeLatency(om.latency.submitToAck),
+		AckToFill:      summarizeLatency(om.latency.ackToFill),
+		SignalToFill:   summarizeLatency(om.latency.signalToFill),
+	}
+}
+
+// summarizeLatency computes nearest-rank percentiles of samples
+func summarizeLatency(samples []time.Duration) LatencyStats {
+	if len(samples) == 0 {
+		return LatencyStats{}
+	}
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := func(p float64) time.Duration {
+		i := int(float64(len(sorted))*p+0.999999) - 1
+		return sorted[max(0, min(i, len(sorted)-1))]
+	}
+	return LatencyStats{
+		Count: len(sorted),
+		P50:   rank(0.50),
+		P95:   rank(0.95),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// String formats the stats as "p50 12ms  p95 40ms  max 85ms (n=20)"
+func (s LatencyStats) String() string {
+	if s.Count == 0 {
+		return "no samples"
+	}
+	return fmt.Sprintf("p50 %s  p95 %s  max %s (n=%d)",
+		formatLatency(s.P50), formatLatency(s.P95), formatLatency(s.Max), s.Count)
+}
+
+// formatLatency rounds a duration to a readable precision
+func formatLatency(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
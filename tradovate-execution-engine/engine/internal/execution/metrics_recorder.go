@@ -0,0 +1,252 @@
+package execution
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+)
+
+const (
+	// metricsFlushInterval is how often buffered rows are written to the file
+	metricsFlushInterval = 10 * time.Second
+	// metricsFlushSize writes buffered rows sooner once this many bytes wait
+	metricsFlushSize = 4096
+)
+
+// MetricsDir returns the default directory for metrics CSVs, external/metrics
+func MetricsDir() string {
+	return filepath.Join(config.GetProjectRoot(), "external", "metrics")
+}
+
+// NewMetricsRecorder creates dir if needed and opens dir/<name>_<date>.csv for
+// appending. A file already there from earlier that day keeps its header and
+// rows; its metric columns are carried on.
+func NewMetricsRecorder(dir, name string, date time.Time) (*MetricsRecorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	r := &MetricsRecorder{path: filepath.Join(dir, fmt.Sprintf("%s_%s.csv", name, date.Format("2006-01-02"))), lastFlush: time.Now()}
+
+	header, _, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	if header != nil {
+		r.columns, r.header = header[1:len(header)-2], true
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Path returns the file the recorder writes to
+func (r *MetricsRecorder) Path() string {
+	return r.path
+}
+
+// Rows returns how many rows have been recorded
+func (r *MetricsRecorder) Rows() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rows
+}
+
+// Record buffers a row. The header is taken from the metrics of the first row,
+// sorted by name; a metric first seen later adds a column, rewriting the file
+// with the rows so far left blank in it.
+func (r *MetricsRecorder) Record(row MetricsRow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return errors.New("metrics recorder is closed")
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	var added []string
+	for name := range row.Metrics {
+		if !contains(r.columns, name) {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	switch {
+	case !r.header:
+		r.columns = append(r.columns, added...)
+		r.writeRow(r.headerRow())
+		r.header = true
+	case len(added) > 0:
+		if err := r.addColumns(added); err != nil {
+			r.err = err
+			return err
+		}
+	}
+
+	record := []string{row.Timestamp}
+	for _, name := range r.columns {
+		if v, ok := row.Metrics[name]; ok {
+			record = append(record, strconv.FormatFloat(v, 'f', -1, 64))
+		} else {
+			record = append(record, "")
+		}
+	}
+	r.writeRow(append(record, strconv.Itoa(row.Position), row.Signal))
+	r.rows++
+
+	if r.pending.Len() >= metricsFlushSize || time.Since(r.lastFlush) >= metricsFlushInterval {
+		return r.flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered rows to the file
+func (r *MetricsRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flush()
+}
+
+// Close flushes the buffered rows and closes the file. It returns the first
+// error seen while recording, if any.
+func (r *MetricsRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return r.err
+	}
+	r.flush()
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	r.file = nil
+	return r.err
+}
+
+// headerRow is timestamp, the metric columns, position and last_signal
+func (r *MetricsRecorder) headerRow() []string {
+	return append(append([]string{"timestamp"}, r.columns...), "position", "last_signal")
+}
+
+// writeRow buffers one CSV row. Callers must hold r.mu.
+func (r *MetricsRecorder) writeRow(record []string) {
+	w := csv.NewWriter(&r.pending)
+	w.Write(record)
+	w.Flush()
+}
+
+// flush writes the buffered rows. Callers must hold r.mu.
+func (r *MetricsRecorder) flush() error {
+	r.lastFlush = time.Now()
+	if r.err != nil || r.file == nil || r.pending.Len() == 0 {
+		return r.err
+	}
+	if _, err := r.file.Write(r.pending.Bytes()); err != nil {
+		r.err = fmt.Errorf("failed to write metrics: %w", err)
+	}
+	r.pending.Reset()
+	return r.err
+}
+
+// addColumns rewrites the file with the metric columns added and reopens it.
+// Callers must hold r.mu.
+func (r *MetricsRecorder) addColumns(added []string) error {
+	if err := r.flush(); err != nil {
+		return err
+	}
+	header, records, err := r.read()
+	if err != nil {
+		return err
+	}
+	r.columns = append(r.columns, added...)
+
+	next := r.headerRow()
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+	w.Write(next)
+	for _, old := range records {
+		record := make([]string, len(next))
+		for i, name := range next {
+			if j, ok := index[name]; ok && j < len(old) {
+				record[i] = old[j]
+			}
+		}
+		w.Write(record)
+	}
+	w.Flush()
+
+	r.file.Close()
+	r.file = nil
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, out.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to rewrite metrics: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to rewrite metrics: %w", err)
+	}
+	return r.open()
+}
+
+// read returns the header and rows already in the file, nil when it is missing
+// or empty
+func (r *MetricsRecorder) read() (header []string, records [][]string, err error) {
+	f, err := os.Open(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	cr.FieldsPerRecord = -1
+	header, err = cr.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read metrics %s: %w", r.path, err)
+	}
+	if n := len(header); n < 3 || header[0] != "timestamp" || header[n-2] != "position" || header[n-1] != "last_signal" {
+		return nil, nil, fmt.Errorf("%s is not a metrics CSV", r.path)
+	}
+	records, err = cr.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read metrics %s: %w", r.path, err)
+	}
+	return header, records, nil
+}
+
+// open opens the file for appending. Callers must hold r.mu or own r.
+func (r *MetricsRecorder) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open metrics: %w", err)
+	}
+	r.file = file
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
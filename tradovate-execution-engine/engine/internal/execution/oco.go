@@ -0,0 +1,250 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// SubmitOCOPair rests a buy stop at buyStop and a sell stop at sellStop, each
+// for qty, and links them so a fill on either cancels the other. Both legs go
+// through the usual order checks; if the second is refused the first is
+// cancelled. Returns the pair ID.
+func (om *OrderManager) SubmitOCOPair(symbol string, qty int, buyStop, sellStop float64) (string, error) {
+	if buyStop <= sellStop {
+		return "", fmt.Errorf("buy stop %.2f must be above sell stop %.2f", buyStop, sellStop)
+	}
+
+	buy, err := om.SubmitOrder(symbol, models.SideBuy, qty, OrderOptions{Type: models.TypeStop, StopPrice: buyStop})
+	if err != nil {
+		return "", fmt.Errorf("OCO buy stop: %w", err)
+	}
+	sell, err := om.SubmitOrder(symbol, models.SideSell, qty, OrderOptions{Type: models.TypeStop, StopPrice: sellStop})
+	if err != nil {
+		if cancelErr := om.CancelOrder(buy.ID); cancelErr != nil {
+			om.log.Errorf("OCO: buy stop %s left working after the sell stop failed: %v", buy.ID, cancelErr)
+		}
+		return "", fmt.Errorf("OCO sell stop: %w", err)
+	}
+
+	om.Mu.Lock()
+	om.orderIDCounter++
+	pair := &OCOPair{
+		ID:          fmt.Sprintf("OCO-%s-%d-%d", symbol, time.Now().Unix(), om.orderIDCounter),
+		Symbol:      symbol,
+		Quantity:    qty,
+		BuyOrderID:  buy.ID,
+		SellOrderID: sell.ID,
+		State:       OCOWorking,
+	}
+	om.linkOCOPair(pair)
+
+	// Either leg may have filled or been cancelled before the link existed
+	var early []models.Order
+	for _, id := range []string{buy.ID, sell.ID} {
+		if o := om.orders[id]; o != nil && o.Status != models.StatusPending && o.Status != models.StatusSubmitted {
+			early = append(early, *o)
+		}
+	}
+	om.Mu.Unlock()
+
+	om.log.Infof("OCO %s: buy stop %s @ %.2f, sell stop %s @ %.2f, %d %s",
+		pair.ID, buy.ID, buyStop, sell.ID, sellStop, qty, symbol)
+	for _, o := range early {
+		om.onOCOLegUpdate(o)
+	}
+	return pair.ID, nil
+}
+
+// RestoreOCOPair relinks a pair saved by an earlier session. Both legs must
+// already be known to the order manager.
+func (om *OrderManager) RestoreOCOPair(pair OCOPair) error {
+	om.Mu.Lock()
+	for _, id := range []string{pair.BuyOrderID, pair.SellOrderID} {
+		if _, ok := om.orders[id]; !ok {
+			om.Mu.Unlock()
+			return fmt.Errorf("OCO %s: leg %s not found", pair.ID, id)
+		}
+	}
+	restored := pair
+	om.linkOCOPair(&restored)
+	buy, sell := *om.orders[pair.BuyOrderID], *om.orders[pair.SellOrderID]
+	om.Mu.Unlock()
+
+	// Catch up on anything that happened to the legs while the link was down
+	om.onOCOLegUpdate(buy)
+	om.onOCOLegUpdate(sell)
+	return nil
+}
+
+// linkOCOPair registers a pair and, the first time, the listener that links
+// its legs. Callers must hold om.Mu.
+func (om *OrderManager) linkOCOPair(pair *OCOPair) {
+	if !om.ocoListening {
+		om.ocoListening = true
+		om.listeners = append(om.listeners, om.onOCOLegUpdate)
+	}
+	if om.ocoPairs == nil {
+		om.ocoPairs = make(map[string]*OCOPair)
+		om.ocoLegs = make(map[string]string)
+	}
+	om.ocoPairs[pair.ID] = pair
+	om.ocoLegs[pair.BuyOrderID] = pair.ID
+	om.ocoLegs[pair.SellOrderID] = pair.ID
+}
+
+// CancelOCOPair cancels whichever legs of a pair are still working
+func (om *OrderManager) CancelOCOPair(pairID string) error {
+	om.Mu.Lock()
+	pair, ok := om.ocoPairs[pairID]
+	if !ok {
+		om.Mu.Unlock()
+		return fmt.Errorf("OCO pair not found: %s", pairID)
+	}
+	if pair.State == OCOWorking {
+		pair.State = OCOCanceled
+	}
+	legs := []string{pair.BuyOrderID, pair.SellOrderID}
+	om.Mu.Unlock()
+
+	om.log.Infof("OCO %s: cancelling both legs", pairID)
+	return om.cancelLegs(legs...)
+}
+
+// GetOCOPair returns a copy of a pair
+func (om *OrderManager) GetOCOPair(pairID string) (OCOPair, bool) {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	pair, ok := om.ocoPairs[pairID]
+	if !ok {
+		return OCOPair{}, false
+	}
+	return *pair, true
+}
+
+// GetOCOPairs returns a copy of every pair this session
+func (om *OrderManager) GetOCOPairs() []OCOPair {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	pairs := make([]OCOPair, 0, len(om.ocoPairs))
+	for _, pair := range om.ocoPairs {
+		pairs = append(pairs, *pair)
+	}
+	return pairs
+}
+
+// onOCOLegUpdate is the order listener that keeps pairs linked. The first fill
+// on a leg cancels its sibling. A fill on the sibling after that means both
+// stops triggered before the cancel landed, so whatever is still working is
+// cancelled and the pair's net position is flattened.
+func (om *OrderManager) onOCOLegUpdate(order models.Order) {
+	om.Mu.Lock()
+	pairID, linked := om.ocoLegs[order.ID]
+	pair := om.ocoPairs[pairID]
+	if !linked || pair == nil {
+		om.Mu.Unlock()
+		return
+	}
+	sibling := pair.BuyOrderID
+	if order.ID == pair.BuyOrderID {
+		sibling = pair.SellOrderID
+	}
+
+	filled := order.Status == models.StatusFilled || order.Status == models.StatusPartiallyFilled
+	var action func()
+	switch {
+	case filled && pair.State == OCOWorking:
+		pair.State = OCOTriggered
+		pair.FilledLeg = order.ID
+		action = func() {
+			om.log.Infof("OCO %s: %s filled, cancelling %s", pair.ID, order.ID, sibling)
+			if err := om.cancelLegs(sibling); err != nil {
+				om.log.Errorf("OCO %s: %v", pair.ID, err)
+			}
+		}
+	case filled && pair.State == OCOTriggered && order.ID != pair.FilledLeg:
+		pair.State = OCOBothFills
+		action = func() { om.unwindBothFills(pair.ID) }
+	case (order.Status == models.StatusCanceled || order.Status == models.StatusExpired ||
+		order.Status == models.StatusRejected || order.Status == models.StatusFailed) && pair.State == OCOWorking:
+		// A leg ended without filling, so the other no longer has a partner
+		pair.State = OCOCanceled
+		action = func() {
+			om.log.Warnf("OCO %s: %s is %s, cancelling %s", pair.ID, order.ID, order.Status, sibling)
+			if err := om.cancelLegs(sibling); err != nil {
+				om.log.Errorf("OCO %s: %v", pair.ID, err)
+			}
+		}
+	}
+	om.Mu.Unlock()
+
+	if action != nil {
+		action()
+	}
+}
+
+// checkOCOLateFill catches a fill on a leg that was already cancelled locally:
+// the sibling's cancel was accepted but the exchange had filled it first
+func (om *OrderManager) checkOCOLateFill(orderID string) {
+	om.Mu.Lock()
+	pair := om.ocoPairs[om.ocoLegs[orderID]]
+	if pair == nil || pair.State != OCOTriggered || pair.FilledLeg == orderID {
+		om.Mu.Unlock()
+		return
+	}
+	pair.State = OCOBothFills
+	om.Mu.Unlock()
+	om.unwindBothFills(pair.ID)
+}
+
+// unwindBothFills handles both legs of a pair filling: working remainders are
+// cancelled and the difference between the two legs' fills is flattened
+func (om *OrderManager) unwindBothFills(pairID string) {
+	om.Mu.RLock()
+	pair := *om.ocoPairs[pairID]
+	buy, sell := om.orders[pair.BuyOrderID], om.orders[pair.SellOrderID]
+	net := buy.FilledQty() - sell.FilledQty()
+	om.Mu.RUnlock()
+
+	om.log.Errorf("OCO %s: BOTH LEGS FILLED (bought %d, sold %d) - the cancel did not land in time", pairID, buy.FilledQty(), sell.FilledQty())
+	if err := om.cancelLegs(pair.BuyOrderID, pair.SellOrderID); err != nil {
+		om.log.Errorf("OCO %s: %v", pairID, err)
+	}
+	if net == 0 {
+		om.log.Errorf("OCO %s: the fills offset, no position left from the pair", pairID)
+		return
+	}
+
+	side := models.SideSell
+	if net < 0 {
+		side = models.SideBuy
+	}
+	qty := models.Abs(net)
+	if _, err := om.Flatten(pair.Symbol, side, qty); err != nil {
+		om.log.Errorf("OCO %s: FLATTEN %s %d %s FAILED: %v", pairID, side, qty, pair.Symbol, err)
+		return
+	}
+	om.log.Errorf("OCO %s: flattening %s %d %s", pairID, side, qty, pair.Symbol)
+}
+
+// cancelLegs cancels the given orders that are still working
+func (om *OrderManager) cancelLegs(orderIDs ...string) error {
+	var errs []error
+	for _, id := range orderIDs {
+		order, ok := om.GetOrder(id)
+		if !ok {
+			continue
+		}
+		om.Mu.RLock()
+		status := order.Status
+		om.Mu.RUnlock()
+		if status != models.StatusPending && status != models.StatusSubmitted && status != models.StatusSuspended && status != models.StatusPartiallyFilled {
+			continue
+		}
+		if err := om.CancelOrder(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,17 +2,30 @@ package execution
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"strconv"
 	"time"
 
 	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/audit"
 	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/contracts"
 	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/metrics"
 	"tradovate-execution-engine/engine/internal/models"
 	"tradovate-execution-engine/engine/internal/portfolio"
 	"tradovate-execution-engine/engine/internal/risk"
+	"tradovate-execution-engine/engine/internal/schedule"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// Live order counts for the metrics endpoint; simulated orders are not counted
+var (
+	ordersSubmitted = metrics.Default.Counter("engine_orders_submitted_total", "Orders accepted by Tradovate")
+	ordersFilled    = metrics.Default.Counter("engine_orders_filled_total", "Orders completely filled")
+	ordersRejected  = metrics.Default.Counter("engine_orders_rejected_total", "Orders rejected by risk checks or Tradovate")
 )
 
 // NewOrderManager creates a new order manager
@@ -24,14 +37,86 @@ func NewOrderManager(tm *auth.TokenManager, config *config.Config, log *logger.L
 		config:         config,
 		log:            log,
 		orderIDCounter: 0,
+		externalIDs:    make(map[string]string),
+	}
+}
+
+// NewSimulatedOrderManager creates an order manager that fills orders through the
+// simulator instead of Tradovate (paper trading and backtests)
+func NewSimulatedOrderManager(sim *SimulatedExecutor, config *config.Config, log *logger.Logger) *OrderManager {
+	om := &OrderManager{
+		orders:         make(map[string]*models.Order),
+		riskManager:    risk.NewRiskManager(config, log),
+		config:         config,
+		log:            log,
+		orderIDCounter: 0,
+		simulator:      sim,
+		externalIDs:    make(map[string]string),
+	}
+	if config != nil {
+		sim.SetSlippage(config.Slippage)
 	}
+	// Resting limit orders fill later, as the market reaches them
+	sim.SetFillHandler(om.applyFill)
+	return om
 }
 
-// SetPortfolioTracker sets the portfolio tracker for the order manager
+// SetPortfolioTracker sets the portfolio tracker for the order manager. The
+// tracker's trades are attributed to the origins of this manager's orders.
 func (om *OrderManager) SetPortfolioTracker(pt *portfolio.PortfolioTracker) {
 	om.Mu.Lock()
 	defer om.Mu.Unlock()
 	om.portfolioTracker = pt
+	om.riskManager.SetPortfolio(pt)
+	if pt != nil {
+		pt.SetOriginLookup(om.OriginOf)
+	}
+}
+
+// SetSymbolResolver sets the resolver used by ResolveSymbol
+func (om *OrderManager) SetSymbolResolver(r *contracts.Resolver) {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	om.symbolResolver = r
+}
+
+// GetSymbolResolver returns the symbol resolver, or nil if none is set
+func (om *OrderManager) GetSymbolResolver() *contracts.Resolver {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	return om.symbolResolver
+}
+
+// SetContractCatalog sets the catalog consulted for tick sizes the portfolio
+// tracker does not know
+func (om *OrderManager) SetContractCatalog(c *contracts.Catalog) {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	om.catalog = c
+}
+
+// SetSchedule sets the trading session window enforced on new orders
+func (om *OrderManager) SetSchedule(s *schedule.TradingSchedule) {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	om.schedule = s
+}
+
+// GetSchedule returns the trading schedule, or nil if none is set
+func (om *OrderManager) GetSchedule() *schedule.TradingSchedule {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	return om.schedule
+}
+
+// ResolveSymbol turns a product root into the contract to trade. Without a
+// resolver the symbol is returned unchanged.
+func (om *OrderManager) ResolveSymbol(symbol string) (string, error) {
+	resolver := om.GetSymbolResolver()
+	if resolver == nil {
+		return symbol, nil
+	}
+	return resolver.Resolve(symbol)
 }
 
 // SubmitMarketOrder submits a market order
@@ -51,25 +136,55 @@ func (om *OrderManager) Flatten(symbol string, side models.OrderSide, quantity i
 		Price:       0, // Market order
 		Status:      models.StatusPending,
 		SubmittedAt: time.Now(),
+		Origin:      om.originFor(symbol),
 	}
 
 	om.orders[orderID] = order
+	created, trail := *order, om.audit
 	om.Mu.Unlock()
 
 	om.log.Debugf("Created market order: %s %s %d %s", orderID, side, quantity, symbol)
+	auditOrder(trail, created, audit.ActionOrderCreated)
 
 	// Submit order
 	if err := om.submitOrderToExchange(order); err != nil {
-		om.updateOrderStatus(orderID, models.StatusFailed, err.Error())
+		om.updateOrderStatus(orderID, submitFailureStatus(err), err.Error())
 		return order, err
 	}
 
-	om.updateOrderStatus(orderID, models.StatusSubmitted, "")
+	om.updateOrderStatus(orderID, om.acceptedStatus(order), "")
 	return order, nil
 }
 
 // SubmitMarketOrder submits a market order
 func (om *OrderManager) SubmitMarketOrder(symbol string, side models.OrderSide, quantity int) (*models.Order, error) {
+	return om.SubmitOrder(symbol, side, quantity, OrderOptions{})
+}
+
+// SubmitLimitOrder submits a Day limit order
+func (om *OrderManager) SubmitLimitOrder(symbol string, side models.OrderSide, quantity int, price float64) (*models.Order, error) {
+	return om.SubmitOrder(symbol, side, quantity, OrderOptions{Type: models.TypeLimit, Price: price})
+}
+
+// SubmitOrder submits an order of any supported type and time in force. Prices
+// are validated against the last known price before the risk checks run.
+func (om *OrderManager) SubmitOrder(symbol string, side models.OrderSide, quantity int, opts OrderOptions) (*models.Order, error) {
+	if opts.Type == "" {
+		opts.Type = models.TypeMarket
+	}
+	if err := om.orderLockout(); err != nil {
+		return nil, err
+	}
+
+	// A strategy's entry may go as a limit order under its entry policy
+	origin := opts.Origin
+	if origin == "" {
+		om.Mu.RLock()
+		origin = om.originFor(symbol)
+		om.Mu.RUnlock()
+	}
+	policy, limitEntry := om.entryLimit(symbol, side, quantity, origin, &opts)
+
 	om.Mu.Lock()
 
 	// Generate order ID
@@ -80,54 +195,103 @@ func (om *OrderManager) SubmitMarketOrder(symbol string, side models.OrderSide,
 		ID:          orderID,
 		Symbol:      symbol,
 		Side:        side,
-		Type:        models.TypeMarket,
+		Type:        opts.Type,
 		Quantity:    quantity,
-		Price:       0, // Market order
+		Price:       opts.Price,
+		StopPrice:   opts.StopPrice,
+		TimeInForce: opts.TimeInForce,
 		Status:      models.StatusPending,
 		SubmittedAt: time.Now(),
+		SignalAt:    opts.SignalAt,
+		Origin:      opts.Origin,
+	}
+	if order.SignalAt.IsZero() {
+		order.SignalAt = order.SubmittedAt
+	}
+	if order.Origin == "" {
+		order.Origin = om.originFor(symbol)
 	}
 
 	om.orders[orderID] = order
+	created, trail := *order, om.audit
 	om.Mu.Unlock()
 
-	om.log.Infof("Created market order: %s %s %d %s", orderID, side, quantity, symbol)
+	om.log.Infof("Created %s order: %s %s %d %s", order.Describe(), orderID, side, quantity, symbol)
+	auditOrder(trail, created, audit.ActionOrderCreated)
 
-	// Check risk before submitting
-	var currentPosition *portfolio.PLEntry
-	if om.portfolioTracker != nil {
-		summary := om.portfolioTracker.GetPLSummary()
-		if pos, ok := summary[symbol]; ok {
-			currentPosition = &pos
-		}
+	return order, om.place(order, queuedOrder{orderID: orderID, policy: policy, limitEntry: limitEntry}, false)
+}
+
+// place checks an order and sends it to the exchange. A strategy order that
+// could not reach Tradovate is queued instead and nil returned; retry is set
+// when the order is being sent from the queue.
+func (om *OrderManager) place(order *models.Order, q queuedOrder, retry bool) error {
+	om.roundToTick(order)
+	if err := order.Validate(om.lastPrice(order.Symbol)); err != nil {
+		om.updateOrderStatus(order.ID, models.StatusRejected, err.Error())
+		return fmt.Errorf("invalid order: %w", err)
+	}
+
+	// Flatten orders bypass the schedule so positions can always be closed
+	if err := om.GetSchedule().CheckOrder(time.Now()); err != nil {
+		om.updateOrderStatus(order.ID, models.StatusRejected, err.Error())
+		return err
 	}
 
-	if err := om.riskManager.CheckOrderRisk(order, currentPosition); err != nil {
-		om.updateOrderStatus(orderID, models.StatusRejected, err.Error())
-		return order, fmt.Errorf("risk check failed: %w", err)
+	// Check risk before submitting; a queued order is checked again when it is sent
+	checkRisk := om.riskManager.CheckOrderRisk
+	if retry {
+		checkRisk = om.riskManager.RecheckOrderRisk
+	}
+	// Suspended legs work as soon as their parent fills, so they count toward the limit
+	resting := om.WorkingOrderCount() + om.SuspendedOrderCount()
+	if err := checkRisk(order, om.currentPosition(order.Symbol), resting); err != nil {
+		om.updateOrderStatus(order.ID, models.StatusRejected, err.Error())
+		return fmt.Errorf("risk check failed: %w", err)
 	}
 
 	// Submit order
 	if err := om.submitOrderToExchange(order); err != nil {
-		om.updateOrderStatus(orderID, models.StatusFailed, err.Error())
-		return order, err
+		if om.hold(order, q, err, retry) {
+			return nil
+		}
+		om.updateOrderStatus(order.ID, submitFailureStatus(err), err.Error())
+		return err
 	}
 
-	om.updateOrderStatus(orderID, models.StatusSubmitted, "")
-	return order, nil
+	om.updateOrderStatus(order.ID, om.acceptedStatus(order), "")
+	if q.limitEntry {
+		om.watchEntry(order.ID, q.policy)
+	}
+	return nil
 }
 
 // submitOrderToExchange submits order to the exchange (Tradovate API)
 func (om *OrderManager) submitOrderToExchange(order *models.Order) error {
 	om.log.Infof("Submitting order %s to exchange...", order.ID)
 
+	if om.simulator != nil {
+		if err := om.simulator.Execute(order); err != nil {
+			return err
+		}
+		om.AssignExternalID(order.ID, order.ExternalID)
+		return nil
+	}
+
 	// Check if authenticated
 	if !om.tokenManager.IsAuthenticated() {
-		return fmt.Errorf("not authenticated")
+		return &unsentError{errors.New("not authenticated")}
+	}
+
+	// Refuse rather than wait out a penalty window; a strategy order is queued instead
+	if om.tokenManager.IsPenaltyActive() {
+		return &unsentError{fmt.Errorf("rate limit penalty active for another %v, order not sent",
+			om.tokenManager.PenaltyRemaining().Round(time.Second))}
 	}
 
 	token, err := om.tokenManager.GetAccessToken()
 	if err != nil {
-		return fmt.Errorf("failed to get access token: %w", err)
+		return &unsentError{fmt.Errorf("failed to get access token: %w", err)}
 	}
 
 	accountID, err := om.tokenManager.GetAccountID()
@@ -136,7 +300,7 @@ func (om *OrderManager) submitOrderToExchange(order *models.Order) error {
 	}
 
 	orderRequest := map[string]interface{}{
-		"accountSpec": om.tokenManager.GetUsername(),
+		"accountSpec": om.tokenManager.GetAccountSpec(),
 		"accountId":   accountID,
 		"action":      string(order.Side),
 		"symbol":      order.Symbol,
@@ -145,10 +309,17 @@ func (om *OrderManager) submitOrderToExchange(order *models.Order) error {
 		"isAutomated": true,
 	}
 
-	if order.Type == models.TypeLimit {
+	if order.Type == models.TypeLimit || order.Type == models.TypeStopLimit {
 		orderRequest["price"] = order.Price
 	}
+	if order.Type == models.TypeStop || order.Type == models.TypeStopLimit {
+		orderRequest["stopPrice"] = order.StopPrice
+	}
+	if order.TimeInForce != "" {
+		orderRequest["timeInForce"] = string(order.TimeInForce)
+	}
 
+	om.stamp(&order.SentAt)
 	resp, err := om.tokenManager.MakeAuthenticatedRequest(
 		"POST",
 		"/v1/order/placeorder",
@@ -156,44 +327,627 @@ func (om *OrderManager) submitOrderToExchange(order *models.Order) error {
 		token,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to submit order: %w", err)
+		err = fmt.Errorf("failed to submit order: %w", err)
+		if om.tokenManager.IsPenaltyActive() || isDialError(err) {
+			return &unsentError{err}
+		}
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("order submission failed: %s", string(body))
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	om.stamp(&order.AckedAt)
+	// Rejections can arrive on a 200 with failureReason set and no orderId
+	if oe := tradovate.ParseOrderError(resp.StatusCode, body); oe != nil {
+		return oe
 	}
 
 	// Parse response to get external order ID
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	externalID := ""
 	if orderId, ok := result["orderId"].(float64); ok {
-		order.ExternalID = fmt.Sprintf("%.0f", orderId)
+		externalID = fmt.Sprintf("%.0f", orderId)
 	} else if orderIdStr, ok := result["orderId"].(string); ok {
-		order.ExternalID = orderIdStr
+		externalID = orderIdStr
 	}
 
-	om.log.Infof("Order %s submitted successfully (External ID: %s)", order.ID, order.ExternalID)
+	om.log.Infof("Order %s submitted successfully (External ID: %s)", order.ID, externalID)
+	ordersSubmitted.Inc()
+	om.AssignExternalID(order.ID, externalID)
 	return nil
 }
 
-// updateOrderStatus updates an order's status
-func (om *OrderManager) updateOrderStatus(orderID string, status models.OrderStatus, reason string) {
+// stamp sets an order timestamp to now under the lock the UI reads orders with
+func (om *OrderManager) stamp(t *time.Time) {
+	om.Mu.Lock()
+	*t = time.Now()
+	om.Mu.Unlock()
+}
+
+// AssignExternalID links a local order to its exchange order ID. If exchange
+// events for that ID arrived before the link was made, the external order they
+// created is folded into the local order and its fills and outcome applied.
+func (om *OrderManager) AssignExternalID(orderID, externalID string) {
+	if externalID == "" {
+		return
+	}
+
+	om.Mu.Lock()
+	order, exists := om.orders[orderID]
+	if !exists {
+		om.Mu.Unlock()
+		return
+	}
+	fills, status, reason := om.adoptExternal(externalID, orderID)
+	order.ExternalID = externalID
+	om.externalIDs[externalID] = orderID
+	om.Mu.Unlock()
+
+	// The exchange can report fills and the outcome before the HTTP response arrives
+	if len(fills) == 0 && status == "" {
+		return
+	}
+	om.log.Debugf("Applying %d early fill(s) and status %q for order %s (External ID: %s)",
+		len(fills), status, orderID, externalID)
+	for _, fill := range fills {
+		om.applyFill(orderID, fill)
+	}
+	if status != "" {
+		om.updateOrderStatus(orderID, status, reason)
+	}
+}
+
+// acceptedStatus is the status of an order the exchange accepted. Simulated
+// orders the simulator filled on arrival are Filled; a resting limit is not.
+func (om *OrderManager) acceptedStatus(order *models.Order) models.OrderStatus {
+	if om.simulator == nil {
+		return models.StatusSubmitted
+	}
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	if order.Quantity > 0 && order.FilledQty() >= order.Quantity {
+		return models.StatusFilled
+	}
+	return models.StatusSubmitted
+}
+
+// AddOrderListener registers a callback for every order status change
+func (om *OrderManager) AddOrderListener(listener func(models.Order)) {
 	om.Mu.Lock()
 	defer om.Mu.Unlock()
+	om.listeners = append(om.listeners, listener)
+}
+
+// HandleOrderEvent applies a Tradovate order entity (user/syncrequest or props)
+// to the matching local order. Orders the engine did not place are tracked as
+// external orders.
+func (om *OrderManager) HandleOrderEvent(data json.RawMessage) {
+	event, err := tradovate.DecodeOrderEvent(data)
+	if err != nil {
+		om.log.Warnf("Ignoring order event: %v", err)
+		return
+	}
+
+	var status models.OrderStatus
+	switch event.OrdStatus {
+	case "Filled":
+		status = models.StatusFilled
+	case "Rejected":
+		status = models.StatusRejected
+	case "Canceled":
+		status = models.StatusCanceled
+	case "Expired":
+		status = models.StatusExpired
+	case "Suspended":
+		status = models.StatusSuspended
+	case "Working":
+		// Only moves a suspended leg whose parent filled; see updateOrderStatus
+		status = models.StatusSubmitted
+	}
+	// PendingNew etc. leave status empty: they don't change local state
+
+	externalID := strconv.Itoa(event.ID)
+
+	reason := ""
+	if status == models.StatusRejected {
+		reason = "rejected by exchange"
+		if event.RejectReason != "" || event.Text != "" {
+			reason = tradovate.DescribeRejection(event.RejectReason, event.Text)
+		}
+	}
+
+	om.Mu.Lock()
+	orderID, created := om.trackExternal(externalID, externalFromOrderEvent(event))
+	om.Mu.Unlock()
+	if created {
+		om.log.Infof("Tracking external order %s (%s %s, %s)", orderID, event.Action, event.OrderType, event.OrdStatus)
+	}
+
+	if status != "" {
+		om.updateOrderStatus(orderID, status, reason)
+	}
+}
+
+// HandleFillEvent applies a Tradovate fill entity to the matching local order.
+// A fill for an order the engine did not place is tracked as an external order.
+func (om *OrderManager) HandleFillEvent(data json.RawMessage) {
+	event, err := tradovate.DecodeFillEvent(data)
+	if err != nil {
+		om.log.Warnf("Ignoring fill event: %v", err)
+		return
+	}
+
+	ts := event.Timestamp
+	if !event.HasTimestamp {
+		ts = time.Now()
+	}
+	fill := models.Fill{
+		Quantity:  event.Qty,
+		Price:     event.Price,
+		Timestamp: ts,
+	}
+	if event.ID != 0 {
+		fill.ID = strconv.Itoa(event.ID)
+	}
+
+	externalID := strconv.Itoa(event.OrderID)
+
+	om.Mu.Lock()
+	orderID, created := om.trackExternal(externalID, externalOrder{contractID: event.ContractID, action: event.Action})
+	om.Mu.Unlock()
+	if created {
+		om.log.Infof("Tracking external order %s from a fill (%s %d @ %.2f)", orderID, event.Action, fill.Quantity, fill.Price)
+	}
+
+	om.applyFill(orderID, fill)
+}
+
+// HandleExecutionReport applies the order status a Tradovate execution report
+// carries. A Trade report marks a working order partially filled before its fill
+// entity arrives; the fill itself is only ever recorded from the fill entity, so
+// the report and the fill never count the same quantity twice.
+func (om *OrderManager) HandleExecutionReport(data json.RawMessage) {
+	report, err := tradovate.DecodeExecutionReport(data)
+	if err != nil {
+		om.log.Warnf("Ignoring execution report: %v", err)
+		return
+	}
+
+	var status models.OrderStatus
+	reason := ""
+	switch report.OrdStatus {
+	case "PartiallyFilled":
+		status = models.StatusPartiallyFilled
+	case "Filled":
+		status = models.StatusFilled
+	case "Rejected":
+		status = models.StatusRejected
+		reason = "rejected by exchange"
+		if report.RejectReason != "" || report.Text != "" {
+			reason = tradovate.DescribeRejection(report.RejectReason, report.Text)
+		}
+	case "Canceled":
+		status = models.StatusCanceled
+	case "Expired":
+		status = models.StatusExpired
+	case "Suspended":
+		status = models.StatusSuspended
+	}
+
+	om.Mu.Lock()
+	orderID, created := om.trackExternal(strconv.Itoa(report.OrderID), externalOrder{contractID: report.ContractID, action: report.Action})
+	// Reports can overtake each other; a partial fill never reopens a finished order
+	if order := om.orders[orderID]; status == models.StatusPartiallyFilled && order != nil &&
+		order.Status != models.StatusPending && order.Status != models.StatusSubmitted {
+		status = ""
+	}
+	om.Mu.Unlock()
+	if created {
+		om.log.Infof("Tracking external order %s from an execution report (%s %s)", orderID, report.Action, report.ExecType)
+	}
+
+	if status != "" {
+		om.updateOrderStatus(orderID, status, reason)
+	}
+}
+
+// applyFill records a fill on an order and moves it to partially filled or filled
+// once the cumulative quantity reaches the order quantity. Repeated fill IDs are
+// ignored. An external order of unknown size waits for its Filled event.
+func (om *OrderManager) applyFill(orderID string, fill models.Fill) {
+	om.Mu.Lock()
+	order, exists := om.orders[orderID]
+	if !exists {
+		om.Mu.Unlock()
+		return
+	}
+	for _, f := range order.Fills {
+		if fill.ID != "" && f.ID == fill.ID {
+			om.Mu.Unlock()
+			return
+		}
+	}
+	order.Fills = append(order.Fills, fill)
+
+	filled := order.FilledQty()
+	om.log.Infof("Order %s fill: %d @ %.2f (%d/%d, avg %.2f)",
+		orderID, fill.Quantity, fill.Price, filled, order.Quantity, order.AvgFillPrice())
+
+	status := models.StatusPartiallyFilled
+	if order.Quantity > 0 && filled >= order.Quantity {
+		status = models.StatusFilled
+	}
+	// A late fill record doesn't reopen a finished order
+	switch order.Status {
+	case models.StatusFilled, models.StatusCanceled, models.StatusExpired, models.StatusRejected, models.StatusFailed:
+		om.Mu.Unlock()
+		om.checkOCOLateFill(orderID)
+		return
+	}
+	if order.Status == status {
+		om.Mu.Unlock()
+		return
+	}
+	order.Status = status
+	om.log.Infof("Order %s status: %s", orderID, status)
+	om.countStatus(order, status)
+	if status == models.StatusFilled {
+		om.recordLatency(order)
+	}
+	snapshot := *order
+	snapshot.Fills = append([]models.Fill(nil), order.Fills...)
+	listeners, trail := om.listeners, om.audit
+	om.Mu.Unlock()
+
+	auditOrder(trail, snapshot, statusAction(status))
+	for _, listener := range listeners {
+		listener(snapshot)
+	}
+}
+
+// SubmitStopOrder submits a stop market order triggered at stopPrice
+func (om *OrderManager) SubmitStopOrder(symbol string, side models.OrderSide, quantity int, stopPrice float64) (*models.Order, error) {
+	if err := om.orderLockout(); err != nil {
+		return nil, err
+	}
+	om.Mu.Lock()
+
+	// Generate order ID
+	om.orderIDCounter++
+	orderID := fmt.Sprintf("STP-%s-%d-%d", symbol, time.Now().Unix(), om.orderIDCounter)
+
+	order := &models.Order{
+		ID:          orderID,
+		Symbol:      symbol,
+		Side:        side,
+		Type:        models.TypeStop,
+		Quantity:    quantity,
+		StopPrice:   stopPrice,
+		Status:      models.StatusPending,
+		SubmittedAt: time.Now(),
+	}
+
+	om.orders[orderID] = order
+	created, trail := *order, om.audit
+	om.Mu.Unlock()
+
+	om.log.Infof("Created stop order: %s %s %d %s @ %.2f", orderID, side, quantity, symbol, stopPrice)
+	auditOrder(trail, created, audit.ActionOrderCreated)
+	om.roundToTick(order)
+
+	// Protective stops only reduce exposure, so like flatten orders they skip
+	// the risk checks (a tripped loss limit must not block a protective stop)
+	if err := om.submitOrderToExchange(order); err != nil {
+		om.updateOrderStatus(orderID, submitFailureStatus(err), err.Error())
+		return order, err
+	}
+
+	om.updateOrderStatus(orderID, om.acceptedStatus(order), "")
+	return order, nil
+}
+
+// ModifyOrder changes the quantity and price of a working order.
+// For stop orders price is the new stop price; for stop-limit orders it is the
+// new limit price and the stop is left where it is.
+func (om *OrderManager) ModifyOrder(orderID string, quantity int, price float64) error {
+	om.Mu.RLock()
+	order, exists := om.orders[orderID]
+	om.Mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("order not found: %s", orderID)
+	}
+	// A queued order never reached the exchange
+	if om.settleQueued(orderID, models.StatusCanceled, "cancelled before it was sent") {
+		return nil
+	}
+	if om.simulator != nil {
+		// Only a resting limit order can be changed in the simulator
+		if order.Type != models.TypeLimit || !om.simulator.Modify(orderID, quantity, price) {
+			return fmt.Errorf("order %s is not resting in the simulator", orderID)
+		}
+		om.Mu.Lock()
+		order.Quantity = quantity
+		order.Price = price
+		modified, trail := *order, om.audit
+		om.Mu.Unlock()
+		om.log.Infof("Order %s modified: qty=%d price=%.2f", orderID, quantity, price)
+		auditOrder(trail, modified, audit.ActionOrderModified)
+		return nil
+	}
+	if order.ExternalID == "" {
+		return fmt.Errorf("order %s has no external ID yet", orderID)
+	}
+
+	request := map[string]interface{}{
+		"orderId":     order.ExternalID,
+		"orderQty":    quantity,
+		"orderType":   string(order.Type),
+		"isAutomated": true,
+	}
+	switch order.Type {
+	case models.TypeLimit:
+		request["price"] = price
+	case models.TypeStop:
+		request["stopPrice"] = price
+	case models.TypeStopLimit:
+		request["price"] = price
+		request["stopPrice"] = order.StopPrice
+	}
+
+	if err := om.sendOrderCommand("/v1/order/modifyorder", request); err != nil {
+		return fmt.Errorf("failed to modify order %s: %w", orderID, err)
+	}
+
+	om.Mu.Lock()
+	order.Quantity = quantity
+	if order.Type == models.TypeStop {
+		order.StopPrice = price
+	} else {
+		order.Price = price
+	}
+	modified, trail := *order, om.audit
+	om.Mu.Unlock()
+
+	om.log.Infof("Order %s modified: qty=%d price=%.2f", orderID, quantity, price)
+	auditOrder(trail, modified, audit.ActionOrderModified)
+	return nil
+}
+
+// CancelOrder cancels a working order
+func (om *OrderManager) CancelOrder(orderID string) error {
+	om.Mu.RLock()
+	order, exists := om.orders[orderID]
+	om.Mu.RUnlock()
 
-	if order, exists := om.orders[orderID]; exists {
-		order.Status = status
-		if reason != "" {
-			order.RejectReason = reason
-			om.log.Warnf("Order %s status: %s - %s", orderID, status, reason)
-		} else {
-			om.log.Infof("Order %s status: %s", orderID, status)
+	if !exists {
+		return fmt.Errorf("order not found: %s", orderID)
+	}
+	// A queued order never reached the exchange
+	if om.settleQueued(orderID, models.StatusCanceled, "cancelled before it was sent") {
+		return nil
+	}
+	if om.simulator != nil {
+		if !om.simulator.Cancel(orderID) {
+			return fmt.Errorf("order %s is not resting in the simulator", orderID)
 		}
+		om.updateOrderStatus(orderID, models.StatusCanceled, "")
+		return nil
+	}
+	if order.ExternalID == "" {
+		return fmt.Errorf("order %s has no external ID yet", orderID)
+	}
+
+	request := map[string]interface{}{
+		"orderId":     order.ExternalID,
+		"isAutomated": true,
+	}
+
+	if err := om.sendOrderCommand("/v1/order/cancelorder", request); err != nil {
+		return fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+	}
+
+	om.updateOrderStatus(orderID, models.StatusCanceled, "")
+	return nil
+}
+
+// CancelWorkingOrders cancels every submitted resting (non-market) order, every
+// suspended leg and every queued order, and returns how many were cancelled. Failures are logged
+// and the remaining orders still tried.
+func (om *OrderManager) CancelWorkingOrders() (int, error) {
+	queued := om.CancelQueuedOrders("")
+	var working []string
+	for _, order := range om.cancellableOrders() {
+		working = append(working, order.ID)
+	}
+
+	cancelled := 0
+	var lastErr error
+	for _, id := range working {
+		if err := om.CancelOrder(id); err != nil {
+			om.log.Errorf("Failed to cancel order %s: %v", id, err)
+			lastErr = err
+			continue
+		}
+		cancelled++
+	}
+
+	if lastErr != nil {
+		return cancelled + queued, fmt.Errorf("%d of %d orders could not be cancelled: %w", len(working)-cancelled, len(working), lastErr)
+	}
+	return cancelled + queued, nil
+}
+
+// WorkingOrderCount returns the number of resting (non-market) orders that are
+// pending or working at the exchange. Suspended legs are not working yet; see
+// SuspendedOrderCount.
+func (om *OrderManager) WorkingOrderCount() int {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+
+	count := 0
+	for _, order := range om.orders {
+		if order.Type == models.TypeMarket {
+			continue
+		}
+		switch order.Status {
+		case models.StatusPending, models.StatusSubmitted, models.StatusPartiallyFilled:
+			count++
+		}
+	}
+	return count
+}
+
+// SuspendedOrderCount returns the number of bracket legs waiting on their parent
+// order. Each starts working when its parent fills.
+func (om *OrderManager) SuspendedOrderCount() int {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+
+	count := 0
+	for _, order := range om.orders {
+		if order.Status == models.StatusSuspended {
+			count++
+		}
+	}
+	return count
+}
+
+// sendOrderCommand posts an order command (modify, cancel) to the exchange
+func (om *OrderManager) sendOrderCommand(endpoint string, request map[string]interface{}) error {
+	if !om.tokenManager.IsAuthenticated() {
+		return fmt.Errorf("not authenticated")
+	}
+
+	token, err := om.tokenManager.GetAccessToken()
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	resp, err := om.tokenManager.MakeAuthenticatedRequest("POST", endpoint, request, token)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if oe := tradovate.ParseOrderError(resp.StatusCode, body); oe != nil {
+		return oe
+	}
+	return nil
+}
+
+// submitFailureStatus is the status of an order submitOrderToExchange could not
+// place: Rejected when Tradovate refused it, Failed when the request failed
+func submitFailureStatus(err error) models.OrderStatus {
+	var oe *tradovate.OrderError
+	if errors.As(err, &oe) && oe.IsRejection() {
+		return models.StatusRejected
+	}
+	return models.StatusFailed
+}
+
+// GetFills returns a copy of the fills recorded against an order
+func (om *OrderManager) GetFills(orderID string) []models.Fill {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	order, ok := om.orders[orderID]
+	if !ok {
+		return nil
+	}
+	return append([]models.Fill(nil), order.Fills...)
+}
+
+// GetFillsByExternalID returns a copy of the fills recorded against the order
+// with Tradovate order ID externalID
+func (om *OrderManager) GetFillsByExternalID(externalID string) []models.Fill {
+	om.Mu.RLock()
+	orderID, ok := om.externalIDs[externalID]
+	om.Mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return om.GetFills(orderID)
+}
+
+// GetOrder returns an order by its local ID
+func (om *OrderManager) GetOrder(orderID string) (*models.Order, bool) {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	order, ok := om.orders[orderID]
+	return order, ok
+}
+
+// OrderSnapshot returns a copy of an order taken under the lock, so it can be
+// read while the order is still changing
+func (om *OrderManager) OrderSnapshot(orderID string) (models.Order, bool) {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	order, ok := om.orders[orderID]
+	if !ok {
+		return models.Order{}, false
+	}
+	snapshot := *order
+	snapshot.Fills = append([]models.Fill(nil), order.Fills...)
+	return snapshot, true
+}
+
+// updateOrderStatus updates an order's status
+func (om *OrderManager) updateOrderStatus(orderID string, status models.OrderStatus, reason string) {
+	om.Mu.Lock()
+	order, exists := om.orders[orderID]
+	if !exists || order.Status == status {
+		om.Mu.Unlock()
+		return
+	}
+	// An exchange event may already have finished the order before submit
+	// returned, and only a leg that has not worked yet can wait on its parent
+	switch {
+	case status == models.StatusSubmitted && order.Status != models.StatusPending && order.Status != models.StatusSuspended,
+		status == models.StatusSuspended && order.Status != models.StatusPending && order.Status != models.StatusSubmitted:
+		om.Mu.Unlock()
+		return
+	}
+
+	order.Status = status
+	om.countStatus(order, status)
+	if status == models.StatusFilled {
+		om.recordLatency(order)
+	}
+	if reason != "" {
+		order.RejectReason = reason
+		om.log.Warnf("Order %s status: %s - %s", orderID, status, reason)
+	} else {
+		om.log.Infof("Order %s status: %s", orderID, status)
+	}
+	snapshot := *order
+	listeners, trail := om.listeners, om.audit
+	om.Mu.Unlock()
+
+	auditOrder(trail, snapshot, statusAction(status))
+	// Notify outside the lock so listeners can call back into the order manager
+	for _, listener := range listeners {
+		listener(snapshot)
+	}
+}
+
+// countStatus records a live order of ours reaching Filled or Rejected
+func (om *OrderManager) countStatus(order *models.Order, status models.OrderStatus) {
+	if om.simulator != nil || order.IsExternal() {
+		return
+	}
+	switch status {
+	case models.StatusFilled:
+		ordersFilled.Inc()
+	case models.StatusRejected:
+		ordersRejected.Inc()
 	}
 }
 
@@ -212,50 +966,172 @@ func (om *OrderManager) GetAllOrders() []*models.Order {
 
 // GetPosition returns the current position for the main symbol
 func (om *OrderManager) GetPosition(symbol string) portfolio.PLEntry {
+	if pos := om.currentPosition(symbol); pos != nil {
+		return *pos
+	}
+	return portfolio.PLEntry{Name: symbol}
+}
+
+// GetTickSize returns the tick size for symbol from the portfolio tracker, the
+// simulator when paper trading, or the contract catalog. It returns 0 if it is
+// not known.
+func (om *OrderManager) GetTickSize(symbol string) float64 {
+	om.Mu.RLock()
+	pt, sim, catalog := om.portfolioTracker, om.simulator, om.catalog
+	om.Mu.RUnlock()
+
+	if pt != nil {
+		if tick := pt.GetTickSize(symbol); tick > 0 {
+			return tick
+		}
+	}
+	if sim != nil {
+		if tick := sim.GetTickSize(symbol); tick > 0 {
+			return tick
+		}
+	}
+	if catalog != nil {
+		return catalog.TickSize(symbol)
+	}
+	return 0
+}
+
+// roundToTick moves an order's limit and stop prices onto the contract's tick
+// grid, since Tradovate rejects prices between ticks. Prices are left alone when
+// the tick size is not known.
+func (om *OrderManager) roundToTick(order *models.Order) {
+	if order.Price == 0 && order.StopPrice == 0 {
+		return
+	}
+	tick := om.GetTickSize(order.Symbol)
+	if tick <= 0 {
+		return
+	}
+
+	om.Mu.Lock()
+	price, stop := order.Price, order.StopPrice
+	order.Price = contracts.RoundPrice(order.Price, tick)
+	order.StopPrice = contracts.RoundPrice(order.StopPrice, tick)
+	changed := order.Price != price || order.StopPrice != stop
+	om.Mu.Unlock()
+
+	if changed {
+		om.log.Infof("Order %s prices rounded to tick %g: limit %.2f -> %.2f, stop %.2f -> %.2f",
+			order.ID, tick, price, order.Price, stop, order.StopPrice)
+	}
+}
+
+// lastPrice returns the latest price known for symbol from the simulator or the
+// portfolio tracker, or 0 if there is none
+func (om *OrderManager) lastPrice(symbol string) float64 {
+	om.Mu.RLock()
+	pt, sim := om.portfolioTracker, om.simulator
+	om.Mu.RUnlock()
+
+	if sim != nil {
+		return sim.GetPrice(symbol)
+	}
+	if pt != nil {
+		if pos, ok := pt.GetPLSummary()[symbol]; ok {
+			return pos.LastPrice
+		}
+	}
+	return 0
+}
+
+// currentPosition looks up a position from the simulator or the portfolio tracker,
+// returning nil when there is none
+func (om *OrderManager) currentPosition(symbol string) *portfolio.PLEntry {
+	if om.simulator != nil {
+		pos := om.simulator.GetPosition(symbol)
+		return &portfolio.PLEntry{
+			Name:     symbol,
+			NetPos:   pos.NetPos,
+			BuyPrice: pos.AvgPrice,
+			PL:       om.simulator.GetUnrealizedPnL(symbol),
+		}
+	}
 	if om.portfolioTracker != nil {
 		summary := om.portfolioTracker.GetPLSummary()
 		if pos, ok := summary[symbol]; ok {
-			return pos
+			return &pos
 		}
 	}
-	return portfolio.PLEntry{Name: symbol}
+	return nil
 }
 
 // Reset resets the order manager
 func (om *OrderManager) Reset() {
 	om.Mu.Lock()
 	om.orders = make(map[string]*models.Order)
+	om.externalIDs = make(map[string]string)
 	om.orderIDCounter = 0
+	om.latency = latencySamples{}
+	om.queue = nil
 	om.Mu.Unlock()
 
 	om.log.Debug("Order manager reset")
 }
 
-// FlattenPositions closes all open positions
+// FlattenPositions closes all open positions, recorded to the audit trail as
+// the engine's own doing
 func (om *OrderManager) FlattenPositions() error {
+	return om.FlattenPositionsBy(audit.ActorSystem)
+}
+
+// FlattenPositionsBy closes all open positions, recorded to the audit trail as
+// actor's doing
+func (om *OrderManager) FlattenPositionsBy(actor string) error {
 	if om.portfolioTracker == nil {
 		return fmt.Errorf("portfolio tracker not initialized")
 	}
 
-	summary := om.portfolioTracker.GetPLSummary()
-
-	for symbol, pos := range summary {
-		if pos.NetPos != 0 {
-			side := models.SideSell
-			if pos.NetPos < 0 {
-				side = models.SideBuy
-			}
-			qty := models.Abs(pos.NetPos)
-			om.log.Infof("Flattening position for %s: %s %d", symbol, side, qty)
-			if _, err := om.Flatten(symbol, side, qty); err != nil {
-				om.log.Errorf("Failed to flatten position for %s: %v", symbol, err)
-			}
+	closing := om.closingOrders()
+	auditFlatten(om.auditTrail(), actor, closing)
+	for _, c := range closing {
+		om.log.Infof("Flattening position for %s: %s %d", c.Symbol, c.Side, c.Quantity)
+		if _, err := om.Flatten(c.Symbol, c.Side, c.Quantity); err != nil {
+			om.log.Errorf("Failed to flatten position for %s: %v", c.Symbol, err)
 		}
 	}
 	return nil
 }
 
+// ApplyConfig hot-swaps the config used by the order manager and its risk manager
+func (om *OrderManager) ApplyConfig(cfg *config.Config) {
+	om.Mu.Lock()
+	om.config = cfg
+	om.Mu.Unlock()
+
+	om.riskManager.UpdateConfig(cfg)
+	if om.simulator != nil && cfg != nil {
+		om.simulator.SetSlippage(cfg.Slippage)
+	}
+}
+
 // GetRiskManager returns the risk manager
 func (om *OrderManager) GetRiskManager() *risk.RiskManager {
 	return om.riskManager
 }
+
+// GetDailyPnL returns the trade date's PnL the daily loss limit is checked against
+func (om *OrderManager) GetDailyPnL() float64 {
+	return om.riskManager.GetDailyPnL()
+}
+
+// MaxContracts returns the account-wide contract limit and whether the risk
+// checks enforce it
+func (om *OrderManager) MaxContracts() (limit int, enforced bool) {
+	cfg := om.riskManager.GetConfig()
+	if cfg == nil {
+		return 0, false
+	}
+	return cfg.Risk.MaxContracts, cfg.Risk.EnableRiskChecks
+}
+
+// MaxOrderQty returns the single order limit for symbol and whether the risk
+// checks enforce it
+func (om *OrderManager) MaxOrderQty(symbol string) (limit int, enforced bool) {
+	limit = om.riskManager.MaxOrderQty(symbol)
+	return limit, limit > 0
+}
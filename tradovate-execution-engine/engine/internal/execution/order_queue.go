@@ -0,0 +1,206 @@
+package execution
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// DefaultOrderQueueAge is how long after its signal a queued strategy order is
+// still sent when Risk.OrderQueueSeconds is zero
+const DefaultOrderQueueAge = 30 * time.Second
+
+func (e *unsentError) Error() string { return e.err.Error() }
+func (e *unsentError) Unwrap() error { return e.err }
+
+// isUnsent reports whether a submit failure left the order unsent for a reason
+// expected to clear. A 5xx or a rate limit response counts; a rejection, or a
+// request that may have reached Tradovate before it failed, does not.
+func isUnsent(err error) bool {
+	var unsent *unsentError
+	if errors.As(err, &unsent) {
+		return true
+	}
+	var oe *tradovate.OrderError
+	if errors.As(err, &oe) && !oe.IsRejection() {
+		return oe.PenaltyTicket != "" || oe.PenaltyTime > 0 || oe.StatusCode >= 500
+	}
+	return false
+}
+
+// isDialError reports whether a request failed before it was sent, because no
+// connection could be made
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return (errors.As(err, &opErr) && opErr.Op == "dial") || errors.As(err, &dnsErr)
+}
+
+// queueAge is how long after its signal a queued order is still sent, 0 when
+// orders are not queued
+func (om *OrderManager) queueAge() time.Duration {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	if om.config == nil || om.config.Risk.OrderQueueSeconds == 0 {
+		return DefaultOrderQueueAge
+	}
+	return max(time.Duration(om.config.Risk.OrderQueueSeconds)*time.Second, 0)
+}
+
+// hold queues a strategy order that could not reach Tradovate, reporting
+// false, to fail it instead, for any other order or failure or once its
+// signal is too old. requeue is set for an order taken from the queue, which
+// RetryQueuedOrders puts back itself; it goes back to Queued quietly, as
+// listeners and the audit trail were told it was queued the first time.
+func (om *OrderManager) hold(order *models.Order, q queuedOrder, err error, requeue bool) bool {
+	maxAge := om.queueAge()
+	if !order.IsStrategy() || !isUnsent(err) || maxAge == 0 || time.Since(order.SignalAt) > maxAge {
+		return false
+	}
+	if requeue {
+		om.Mu.Lock()
+		order.Status = models.StatusQueued
+		order.RejectReason = err.Error()
+		om.Mu.Unlock()
+		return true
+	}
+	om.updateOrderStatus(order.ID, models.StatusQueued, err.Error())
+
+	om.Mu.Lock()
+	om.queue = append(om.queue, q)
+	om.Mu.Unlock()
+	om.log.Warnf("Order %s queued until Tradovate can be reached, for up to %s after its signal", order.ID, maxAge)
+	return true
+}
+
+// claimQueued takes a queued order out of the queue's hands, so exactly one of
+// a retry, a cancel or the expiry acts on it. It returns nil if the order is
+// no longer queued.
+func (om *OrderManager) claimQueued(orderID string) *models.Order {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	order, ok := om.orders[orderID]
+	if !ok || order.Status != models.StatusQueued {
+		return nil
+	}
+	order.Status = models.StatusPending
+	return order
+}
+
+// settleQueued ends a queued order with status, reporting false if it is not queued
+func (om *OrderManager) settleQueued(orderID string, status models.OrderStatus, reason string) bool {
+	if om.claimQueued(orderID) == nil {
+		return false
+	}
+	om.updateOrderStatus(orderID, status, reason)
+	return true
+}
+
+// queuedOrders returns the queue, dropping entries whose order left it
+func (om *OrderManager) queuedOrders() []queuedOrder {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	kept := om.queue[:0]
+	for _, q := range om.queue {
+		if order, ok := om.orders[q.orderID]; ok && order.Status == models.StatusQueued {
+			kept = append(kept, q)
+		}
+	}
+	om.queue = kept
+	return append([]queuedOrder(nil), kept...)
+}
+
+// QueuedOrderCount returns how many strategy orders wait for Tradovate
+func (om *OrderManager) QueuedOrderCount() int {
+	return len(om.queuedOrders())
+}
+
+// DropExpiredOrders fails every queued order whose signal is older than
+// Risk.OrderQueueSeconds at now, since the market has moved on from it, and
+// returns how many it dropped
+func (om *OrderManager) DropExpiredOrders(now time.Time) int {
+	maxAge := om.queueAge()
+	dropped := 0
+	for _, q := range om.queuedOrders() {
+		order, ok := om.GetOrder(q.orderID)
+		if !ok {
+			continue
+		}
+		age := now.Sub(order.SignalAt)
+		if age <= maxAge {
+			continue
+		}
+		if om.settleQueued(q.orderID, models.StatusFailed, "queued too long, not sent") {
+			om.log.Errorf(">>> QUEUED ORDER DROPPED | %s | %s %d %s | signal %s ago, the market has moved <<<",
+				q.orderID, order.Side, order.Quantity, order.Symbol, age.Round(time.Second))
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// RetryQueuedOrders drops the expired queued orders, then sends the rest,
+// oldest first, through the same checks as a new order. The kill switch, the
+// schedule and the risk checks are applied as they stand now. A pass stops at
+// the first order that still cannot reach Tradovate. It returns how many orders
+// left the queue to be sent, accepted or not, and how many were dropped.
+func (om *OrderManager) RetryQueuedOrders(now time.Time) (sent, dropped int) {
+	dropped = om.DropExpiredOrders(now)
+
+	om.Mu.Lock()
+	pending := om.queue
+	om.queue = nil
+	om.Mu.Unlock()
+
+	var kept []queuedOrder
+	for i, q := range pending {
+		order := om.claimQueued(q.orderID)
+		if order == nil {
+			continue
+		}
+		om.log.Infof("Sending queued order %s", q.orderID)
+		if err := om.orderLockout(); err != nil {
+			om.updateOrderStatus(q.orderID, models.StatusRejected, err.Error())
+			sent++
+			continue
+		}
+		// A failure is recorded on the order, which the strategy is listening for
+		_ = om.place(order, q, true)
+		if om.statusOf(order) == models.StatusQueued {
+			kept = append(kept, pending[i:]...)
+			break
+		}
+		sent++
+	}
+
+	om.Mu.Lock()
+	om.queue = append(kept, om.queue...)
+	om.Mu.Unlock()
+	return sent, dropped
+}
+
+// CancelQueuedOrders cancels the queued orders of origin, or every queued order
+// when origin is empty, and returns how many it cancelled
+func (om *OrderManager) CancelQueuedOrders(origin string) int {
+	cancelled := 0
+	for _, q := range om.queuedOrders() {
+		order, ok := om.GetOrder(q.orderID)
+		if !ok || (origin != "" && order.Origin != origin) {
+			continue
+		}
+		if om.settleQueued(q.orderID, models.StatusCanceled, "cancelled before it was sent") {
+			cancelled++
+		}
+	}
+	return cancelled
+}
+
+// statusOf reads an order's status under the lock
+func (om *OrderManager) statusOf(order *models.Order) models.OrderStatus {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	return order.Status
+}
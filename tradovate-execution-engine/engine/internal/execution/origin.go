@@ -0,0 +1,49 @@
+package execution
+
+import (
+	"strconv"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// SetSymbolOrigin attributes orders on symbol that name no origin to a strategy
+// instance while it runs. Running instances never share a contract, so the
+// symbol is enough to tell whose order it is.
+func (om *OrderManager) SetSymbolOrigin(symbol, origin string) {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	if om.symbolOrigins == nil {
+		om.symbolOrigins = make(map[string]string)
+	}
+	om.symbolOrigins[symbol] = origin
+}
+
+// ClearSymbolOrigin ends SetSymbolOrigin for symbol, if origin still holds it
+func (om *OrderManager) ClearSymbolOrigin(symbol, origin string) {
+	om.Mu.Lock()
+	defer om.Mu.Unlock()
+	if om.symbolOrigins[symbol] == origin {
+		delete(om.symbolOrigins, symbol)
+	}
+}
+
+// originFor returns the origin of a new order on symbol that names none.
+// Callers must hold om.Mu.
+func (om *OrderManager) originFor(symbol string) string {
+	if origin, ok := om.symbolOrigins[symbol]; ok {
+		return origin
+	}
+	return models.OriginManual
+}
+
+// OriginOf returns who placed the order with Tradovate order ID orderID: the
+// origin of the local order, or models.OriginExternal if it is not one of ours
+func (om *OrderManager) OriginOf(orderID int) string {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	if localID, ok := om.externalIDs[strconv.Itoa(orderID)]; ok {
+		if order, ok := om.orders[localID]; ok && order.Origin != "" {
+			return order.Origin
+		}
+	}
+	return models.OriginExternal
+}
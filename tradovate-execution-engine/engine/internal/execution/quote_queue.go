@@ -0,0 +1,99 @@
+package execution
+
+import "tradovate-execution-engine/engine/internal/marketdata"
+
+// DefaultQuoteQueueSize is how many quotes a strategy may fall behind by
+// before the oldest are dropped
+const DefaultQuoteQueueSize = 256
+
+// String names the mode as the strategy logs show it
+func (m UpdateMode) String() string {
+	switch m {
+	case UpdateOnEachTick:
+		return "quotes and bars"
+	case UpdateTicksOnly:
+		return "quotes only"
+	}
+	return "bars"
+}
+
+// UpdateModeFor returns how strategy takes live data: UpdateOnBarClose unless
+// it is a QuoteHandler, then its UpdateMode if it declares one
+func UpdateModeFor(strategy Strategy) UpdateMode {
+	if _, ok := strategy.(QuoteHandler); !ok {
+		return UpdateOnBarClose
+	}
+	if p, ok := strategy.(UpdateModeProvider); ok {
+		return p.UpdateMode()
+	}
+	return UpdateOnEachTick
+}
+
+// NewQuoteQueue starts delivering quotes to handler, holding at most size of
+// them. onError, if set, is given each error OnQuote returns, and a
+// *StrategyPanic for each panic.
+func NewQuoteQueue(handler QuoteHandler, size int, onError func(error)) *QuoteQueue {
+	if size <= 0 {
+		size = DefaultQuoteQueueSize
+	}
+	q := &QuoteQueue{
+		handler:  handler,
+		onError:  onError,
+		quotes:   make(chan marketdata.Quote, size),
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Offer queues a quote without blocking. It reports whether an older quote was
+// dropped to make room. Quotes offered after Stop are ignored.
+func (q *QuoteQueue) Offer(quote marketdata.Quote) bool {
+	dropped := false
+	for {
+		select {
+		case <-q.done:
+			return false
+		default:
+		}
+		select {
+		case q.quotes <- quote:
+			return dropped
+		default:
+		}
+		select {
+		case <-q.quotes:
+			q.dropped.Add(1)
+			dropped = true
+		default:
+		}
+	}
+}
+
+// Dropped returns how many quotes were dropped because the handler fell behind
+func (q *QuoteQueue) Dropped() int64 {
+	return q.dropped.Load()
+}
+
+// Stop ends delivery and waits for a quote being handled to finish. Quotes
+// still queued are discarded.
+func (q *QuoteQueue) Stop() {
+	q.stopOnce.Do(func() { close(q.done) })
+	<-q.finished
+}
+
+func (q *QuoteQueue) run() {
+	defer close(q.finished)
+	for {
+		select {
+		case <-q.done:
+			return
+		case quote := <-q.quotes:
+			err := CallStrategy("OnQuote", func() error { return q.handler.OnQuote(quote) })
+			if err != nil && q.onError != nil {
+				q.onError(err)
+			}
+		}
+	}
+}
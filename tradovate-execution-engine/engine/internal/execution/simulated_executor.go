@@ -0,0 +1,387 @@
+package execution
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// NewSimulatedExecutor creates an empty simulator
+func NewSimulatedExecutor() *SimulatedExecutor {
+	return &SimulatedExecutor{
+		prices:        make(map[string]float64),
+		timestamps:    make(map[string]string),
+		valuePerPoint: make(map[string]float64),
+		tickSizes:     make(map[string]float64),
+		fees:          make(map[string]float64),
+		bids:          make(map[string]float64),
+		asks:          make(map[string]float64),
+		rng:           rand.New(rand.NewSource(1)),
+		positions:     make(map[string]*SimPosition),
+	}
+}
+
+// SetSlippage sets how fills are moved against the orders they fill (default none)
+func (se *SimulatedExecutor) SetSlippage(cfg config.SlippageConfig) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.slippage = cfg
+}
+
+// SetFillHandler sets the callback told of each fill to a resting limit order.
+// It is called outside the simulator's lock.
+func (se *SimulatedExecutor) SetFillHandler(fn func(orderID string, fill models.Fill)) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.onFill = fn
+}
+
+// SetQuote sets the best bid and ask for a symbol, which the spread model fills
+// market orders at. A zero side is unknown.
+func (se *SimulatedExecutor) SetQuote(symbol string, bid, ask float64) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.bids[symbol] = bid
+	se.asks[symbol] = ask
+}
+
+// SetValuePerPoint sets the dollar value of a one point move for a symbol (default 1)
+func (se *SimulatedExecutor) SetValuePerPoint(symbol string, vpp float64) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.valuePerPoint[symbol] = vpp
+}
+
+// SetTickSize sets the minimum price increment for a symbol
+func (se *SimulatedExecutor) SetTickSize(symbol string, tickSize float64) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.tickSizes[symbol] = tickSize
+}
+
+// SetFees sets the commission and exchange fees charged per contract on each
+// fill in symbol (default 0)
+func (se *SimulatedExecutor) SetFees(symbol string, perContract float64) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	se.fees[symbol] = perContract
+}
+
+// GetTickSize returns the tick size set for a symbol, or 0 if none was set
+func (se *SimulatedExecutor) GetTickSize(symbol string) float64 {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	return se.tickSizes[symbol]
+}
+
+// SetMarket updates the last price and time for a symbol. Market orders fill
+// from this price, and resting limit orders the trade reaches fill at their limit.
+func (se *SimulatedExecutor) SetMarket(symbol string, price float64, timestamp string) {
+	se.mu.Lock()
+	se.prices[symbol] = price
+	se.timestamps[symbol] = timestamp
+
+	type restingFill struct {
+		orderID string
+		fill    models.Fill
+	}
+	var filled []restingFill
+	kept := se.resting[:0]
+	for _, order := range se.resting {
+		model := se.slippage.For(symbol)
+		if order.symbol != symbol || !limitReached(order.side, price, order.price, model.LimitAtPrice) {
+			kept = append(kept, order)
+			continue
+		}
+		fill := se.fill(order.id, order.externalID, symbol, order.side, order.quantity, order.price, 0, model.Model)
+		filled = append(filled, restingFill{orderID: order.id, fill: fill})
+	}
+	se.resting = kept
+	onFill := se.onFill
+	se.mu.Unlock()
+
+	if onFill == nil {
+		return
+	}
+	for _, f := range filled {
+		onFill(f.orderID, f.fill)
+	}
+}
+
+// GetPrice returns the last price set for symbol, or 0
+func (se *SimulatedExecutor) GetPrice(symbol string) float64 {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	return se.prices[symbol]
+}
+
+// Execute fills a market order against the current market, moved against it
+// by the symbol's slippage model. A limit order fills the same way, no worse
+// than its limit, if the market is already at or through it, and otherwise
+// rests until SetMarket trades through it.
+func (se *SimulatedExecutor) Execute(order *models.Order) error {
+	switch order.Type {
+	case models.TypeMarket:
+	case models.TypeLimit:
+		if order.Price <= 0 {
+			return fmt.Errorf("limit order needs a price")
+		}
+	default:
+		return fmt.Errorf("simulator only supports market and limit orders (got %s)", order.Type)
+	}
+
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	last, ok := se.prices[order.Symbol]
+	if !ok || last == 0 {
+		return fmt.Errorf("no market price for %s", order.Symbol)
+	}
+
+	model := se.slippage.For(order.Symbol)
+	price := se.slippedPrice(order.Symbol, order.Side, last, model)
+	se.orderSeq++
+	order.ExternalID = fmt.Sprintf("SIM-%d", se.orderSeq)
+
+	if order.Type == models.TypeLimit {
+		marketable := !worse(order.Side, price, order.Price)
+		if !marketable && !limitReached(order.Side, last, order.Price, model.LimitAtPrice) {
+			se.resting = append(se.resting, simOrder{
+				id:         order.ID,
+				externalID: order.ExternalID,
+				symbol:     order.Symbol,
+				side:       order.Side,
+				quantity:   order.Quantity,
+				price:      order.Price,
+			})
+			return nil
+		}
+		if !marketable {
+			price = order.Price
+		}
+	}
+
+	slippage := price - last
+	if order.Side == models.SideSell {
+		slippage = -slippage
+	}
+	fill := se.fill(order.ID, order.ExternalID, order.Symbol, order.Side, order.Quantity, price, slippage, model.Model)
+	order.Fills = append(order.Fills, fill)
+	return nil
+}
+
+// Cancel takes a resting limit order out of the simulator, reporting false if
+// it is not resting
+func (se *SimulatedExecutor) Cancel(orderID string) bool {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	for i, order := range se.resting {
+		if order.id == orderID {
+			se.resting = append(se.resting[:i], se.resting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Modify changes the quantity and limit price of a resting limit order,
+// reporting false if it is not resting
+func (se *SimulatedExecutor) Modify(orderID string, quantity int, price float64) bool {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	for i := range se.resting {
+		if se.resting[i].id == orderID {
+			se.resting[i].quantity = quantity
+			se.resting[i].price = price
+			return true
+		}
+	}
+	return false
+}
+
+// slippedPrice is the price a market order on side fills at with the last trade
+// at last. Caller must hold se.mu.
+func (se *SimulatedExecutor) slippedPrice(symbol string, side models.OrderSide, last float64, model config.ProductSlippage) float64 {
+	ticks := 0
+	switch model.Model {
+	case config.SlippageFixed:
+		ticks = model.Ticks
+	case config.SlippageRandom:
+		ticks = se.rng.Intn(model.Ticks + 1)
+	case config.SlippageSpread:
+		// Cross the spread, or Ticks past the last trade without a quote
+		if side == models.SideBuy && se.asks[symbol] > 0 {
+			return se.asks[symbol]
+		}
+		if side == models.SideSell && se.bids[symbol] > 0 {
+			return se.bids[symbol]
+		}
+		ticks = model.Ticks
+	}
+	move := float64(ticks) * se.tickSizes[symbol]
+	if side == models.SideSell {
+		move = -move
+	}
+	return last + move
+}
+
+// fill books a fill to the position and the fill log. Caller must hold se.mu.
+func (se *SimulatedExecutor) fill(orderID, externalID, symbol string, side models.OrderSide, quantity int, price, slippage float64, model string) models.Fill {
+	signedQty := quantity
+	if side == models.SideSell {
+		signedQty = -signedQty
+	}
+	se.applyFill(symbol, signedQty, price)
+	se.fills = append(se.fills, SimFill{
+		OrderID:   orderID,
+		Symbol:    symbol,
+		Side:      side,
+		Quantity:  quantity,
+		Price:     price,
+		Fees:      se.fees[symbol] * float64(quantity),
+		Timestamp: se.timestamps[symbol],
+		Model:     model,
+		Slippage:  slippage,
+	})
+
+	filledAt, err := time.Parse(time.RFC3339Nano, se.timestamps[symbol])
+	if err != nil {
+		filledAt = time.Now()
+	}
+	return models.Fill{
+		ID:            externalID,
+		Quantity:      quantity,
+		Price:         price,
+		Timestamp:     filledAt,
+		SlippageModel: model,
+		Slippage:      slippage,
+	}
+}
+
+// limitReached reports whether a trade at price reaches a limit order on side:
+// through the limit, or at it too when atPrice is set
+func limitReached(side models.OrderSide, price, limit float64, atPrice bool) bool {
+	if price == limit {
+		return atPrice
+	}
+	return !worse(side, price, limit)
+}
+
+// worse reports whether price is worse than limit for an order on side
+func worse(side models.OrderSide, price, limit float64) bool {
+	if side == models.SideBuy {
+		return price > limit
+	}
+	return price < limit
+}
+
+// applyFill updates the position and records closed trades. Caller must hold se.mu.
+func (se *SimulatedExecutor) applyFill(symbol string, signedQty int, price float64) {
+	pos, ok := se.positions[symbol]
+	if !ok {
+		pos = &SimPosition{}
+		se.positions[symbol] = pos
+	}
+
+	vpp := se.valuePerPoint[symbol]
+	if vpp == 0 {
+		vpp = 1
+	}
+	feePerContract := se.fees[symbol]
+	pos.Fees += feePerContract * float64(models.Abs(signedQty))
+
+	// Closing (part of) the existing position
+	if pos.NetPos != 0 && (pos.NetPos > 0) != (signedQty > 0) {
+		closeQty := models.Abs(signedQty)
+		if closeQty > models.Abs(pos.NetPos) {
+			closeQty = models.Abs(pos.NetPos)
+		}
+
+		direction := 1.0
+		entrySide := models.SideBuy
+		if pos.NetPos < 0 {
+			direction = -1.0
+			entrySide = models.SideSell
+		}
+
+		pnl := (price - pos.AvgPrice) * direction * vpp * float64(closeQty)
+		fees := 2 * feePerContract * float64(closeQty)
+		pos.RealizedPnL += pnl
+		se.trades = append(se.trades, SimTrade{
+			Symbol:     symbol,
+			Side:       entrySide,
+			Quantity:   closeQty,
+			EntryPrice: pos.AvgPrice,
+			ExitPrice:  price,
+			ExitTime:   se.timestamps[symbol],
+			PnL:        pnl,
+			Fees:       fees,
+			NetPnL:     pnl - fees,
+		})
+
+		if pos.NetPos > 0 {
+			pos.NetPos -= closeQty
+			signedQty += closeQty
+		} else {
+			pos.NetPos += closeQty
+			signedQty -= closeQty
+		}
+		if pos.NetPos == 0 {
+			pos.AvgPrice = 0
+		}
+	}
+
+	// Opening or adding to a position
+	if signedQty != 0 {
+		newPos := pos.NetPos + signedQty
+		pos.AvgPrice = (pos.AvgPrice*float64(models.Abs(pos.NetPos)) + price*float64(models.Abs(signedQty))) / float64(models.Abs(newPos))
+		pos.NetPos = newPos
+	}
+}
+
+// GetPosition returns the simulated position for a symbol
+func (se *SimulatedExecutor) GetPosition(symbol string) SimPosition {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	if pos, ok := se.positions[symbol]; ok {
+		return *pos
+	}
+	return SimPosition{}
+}
+
+// GetUnrealizedPnL marks a symbol's open position to the last price
+func (se *SimulatedExecutor) GetUnrealizedPnL(symbol string) float64 {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	pos, ok := se.positions[symbol]
+	if !ok || pos.NetPos == 0 {
+		return 0
+	}
+	vpp := se.valuePerPoint[symbol]
+	if vpp == 0 {
+		vpp = 1
+	}
+	return (se.prices[symbol] - pos.AvgPrice) * vpp * float64(pos.NetPos)
+}
+
+// GetFills returns a copy of all simulated fills
+func (se *SimulatedExecutor) GetFills() []SimFill {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	fills := make([]SimFill, len(se.fills))
+	copy(fills, se.fills)
+	return fills
+}
+
+// GetTrades returns a copy of all closed trades in order
+func (se *SimulatedExecutor) GetTrades() []SimTrade {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	trades := make([]SimTrade, len(se.trades))
+	copy(trades, se.trades)
+	return trades
+}
@@ -0,0 +1,24 @@
+package execution
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// CallStrategy runs a strategy callback, returning the error it returned,
+// prefixed with callback, or the panic it raised as a *StrategyPanic
+func CallStrategy(callback string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &StrategyPanic{Callback: callback, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	if err := fn(); err != nil {
+		return fmt.Errorf("%s: %w", callback, err)
+	}
+	return nil
+}
+
+func (p *StrategyPanic) Error() string {
+	return fmt.Sprintf("%s panicked: %v", p.Callback, p.Value)
+}
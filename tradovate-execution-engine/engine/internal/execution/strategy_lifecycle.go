@@ -0,0 +1,139 @@
+package execution
+
+import "fmt"
+
+// String returns the state's name, e.g. "running"
+func (s LifecycleState) String() string {
+	switch s {
+	case LifecycleCreated:
+		return "created"
+	case LifecycleConfigured:
+		return "configured"
+	case LifecycleInitialized:
+		return "initialized"
+	case LifecycleRunning:
+		return "running"
+	case LifecycleStopped:
+		return "stopped"
+	}
+	return fmt.Sprintf("LifecycleState(%d)", int32(s))
+}
+
+// Lifecycle returns the guard itself, so embedding strategies are LifecycleGuarded
+func (l *StrategyLifecycle) Lifecycle() *StrategyLifecycle {
+	return l
+}
+
+// LifecycleState returns the current state
+func (l *StrategyLifecycle) LifecycleState() LifecycleState {
+	return LifecycleState(l.state.Load())
+}
+
+// IsInitialized reports whether Init has succeeded since the last Reset
+func (l *StrategyLifecycle) IsInitialized() bool {
+	switch l.LifecycleState() {
+	case LifecycleInitialized, LifecycleRunning, LifecycleStopped:
+		return true
+	}
+	return false
+}
+
+// CheckInitialized returns an error unless Init has succeeded since the last
+// Reset, for the strategy's bar and quote callbacks
+func (l *StrategyLifecycle) CheckInitialized() error {
+	if !l.IsInitialized() {
+		return fmt.Errorf("strategy not initialized")
+	}
+	return nil
+}
+
+// BeginConfigure is called before a param is set. It moves a created strategy
+// to configured and refuses once Init has succeeded.
+func (l *StrategyLifecycle) BeginConfigure() error {
+	if err := l.transition(LifecycleConfigured, LifecycleCreated, LifecycleConfigured); err != nil {
+		return fmt.Errorf("cannot modify parameters while the strategy is %s, reset it first", l.LifecycleState())
+	}
+	return nil
+}
+
+// BeginInit is called at the top of Init and refuses a strategy that is
+// already initialized
+func (l *StrategyLifecycle) BeginInit() error {
+	switch state := l.LifecycleState(); state {
+	case LifecycleCreated, LifecycleConfigured:
+		return nil
+	default:
+		return fmt.Errorf("strategy already initialized (%s), reset it before initializing again", state)
+	}
+}
+
+// EndInit is called once Init has succeeded
+func (l *StrategyLifecycle) EndInit() error {
+	if err := l.transition(LifecycleInitialized, LifecycleCreated, LifecycleConfigured); err != nil {
+		return fmt.Errorf("strategy changed to %s during Init", l.LifecycleState())
+	}
+	return nil
+}
+
+// MarkRunning moves an initialized or stopped strategy to running
+func (l *StrategyLifecycle) MarkRunning() error {
+	if err := l.transition(LifecycleRunning, LifecycleInitialized, LifecycleStopped); err != nil {
+		switch state := l.LifecycleState(); state {
+		case LifecycleRunning:
+			return fmt.Errorf("strategy is already running")
+		default:
+			return fmt.Errorf("cannot run a strategy that is %s, initialize it first", state)
+		}
+	}
+	return nil
+}
+
+// MarkStopped moves an initialized or running strategy to stopped. Stopping a
+// stopped strategy does nothing.
+func (l *StrategyLifecycle) MarkStopped() error {
+	if l.transition(LifecycleStopped, LifecycleInitialized, LifecycleRunning, LifecycleStopped) != nil {
+		return fmt.Errorf("cannot stop a strategy that is %s", l.LifecycleState())
+	}
+	return nil
+}
+
+// ResetLifecycle returns the strategy to configured from any state, keeping
+// its params, so it can be configured and initialized again
+func (l *StrategyLifecycle) ResetLifecycle() {
+	l.state.Store(int32(LifecycleConfigured))
+}
+
+// transition moves to state to if the current state is one of from
+func (l *StrategyLifecycle) transition(to LifecycleState, from ...LifecycleState) error {
+	for {
+		current := l.LifecycleState()
+		allowed := false
+		for _, f := range from {
+			allowed = allowed || current == f
+		}
+		if !allowed {
+			return fmt.Errorf("cannot go from %s to %s", current, to)
+		}
+		if l.state.CompareAndSwap(int32(current), int32(to)) {
+			return nil
+		}
+	}
+}
+
+// StartLifecycle marks a LifecycleGuarded strategy running. Other strategies
+// are not tracked and always succeed.
+func StartLifecycle(s Strategy) error {
+	if g, ok := s.(LifecycleGuarded); ok {
+		return g.Lifecycle().MarkRunning()
+	}
+	return nil
+}
+
+// StopLifecycle marks a LifecycleGuarded strategy stopped. Other strategies
+// are not tracked and always succeed.
+func StopLifecycle(s Strategy) error {
+	if g, ok := s.(LifecycleGuarded); ok {
+		return g.Lifecycle().MarkStopped()
+	}
+	return nil
+}
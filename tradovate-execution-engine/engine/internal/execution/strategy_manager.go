@@ -2,6 +2,7 @@ package execution
 
 import (
 	"fmt"
+	"sort"
 	"tradovate-execution-engine/engine/internal/logger"
 )
 
@@ -24,7 +25,37 @@ func GetAvailableStrategies() []string {
 	return names
 }
 
-// CreateStrategy instantiates a strategy by name
+// DescribeAll lists every registered strategy by registry name, with its
+// description and default params. Each is read from a transient instance that
+// is never initialized, so nothing is subscribed and no orders can be sent.
+func DescribeAll() []StrategyInfo {
+	globalRegistry.mu.RLock()
+	factories := make(map[string]func(*logger.Logger) Strategy, len(globalRegistry.strategies))
+	for name, factory := range globalRegistry.strategies {
+		factories[name] = factory
+	}
+	globalRegistry.mu.RUnlock()
+
+	// Whatever the transient instances log goes nowhere
+	scratch := logger.NewLogger(1, logger.LevelError)
+	infos := make([]StrategyInfo, 0, len(factories))
+	for key, factory := range factories {
+		strategy := factory(scratch)
+		infos = append(infos, StrategyInfo{
+			Key:         key,
+			Name:        strategy.Name(),
+			Description: strategy.Description(),
+			Params:      strategy.GetParams(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos
+}
+
+// CreateStrategy instantiates a strategy by name. The logger is always injected,
+// whether or not the factory used it. A LifecycleGuarded strategy comes back
+// configured with its defaults; a factory that hands out one already
+// initialized is refused.
 func CreateStrategy(name string, logger *logger.Logger) (Strategy, error) {
 	globalRegistry.mu.RLock()
 	factory, exists := globalRegistry.strategies[name]
@@ -33,5 +64,16 @@ func CreateStrategy(name string, logger *logger.Logger) (Strategy, error) {
 	if !exists {
 		return nil, fmt.Errorf("strategy not found: %s", name)
 	}
-	return factory(logger), nil
+	strategy := factory(logger)
+	if g, ok := strategy.(LifecycleGuarded); ok {
+		lc := g.Lifecycle()
+		if state := lc.LifecycleState(); state != LifecycleCreated {
+			return nil, fmt.Errorf("strategy %s: factory returned a strategy that is already %s", name, state)
+		}
+		if err := lc.BeginConfigure(); err != nil {
+			return nil, fmt.Errorf("strategy %s: %w", name, err)
+		}
+	}
+	strategy.SetLogger(logger)
+	return strategy, nil
 }
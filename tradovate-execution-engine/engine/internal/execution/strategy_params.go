@@ -0,0 +1,101 @@
+package execution
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Bound returns a pointer to v, for StrategyParam.Min and Max
+func Bound(v float64) *float64 {
+	return &v
+}
+
+// Validate checks value against the param's type, range and options
+func (p StrategyParam) Validate(value string) error {
+	var num float64
+	switch p.Type {
+	case "int":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number, got %q", p.Name, value)
+		}
+		num = float64(n)
+	case "float":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, got %q", p.Name, value)
+		}
+		num = f
+	}
+
+	if p.Type == "int" || p.Type == "float" {
+		if (p.Min != nil && num < *p.Min) || (p.Max != nil && num > *p.Max) {
+			return fmt.Errorf("%s must be %s, got %s", p.Name, p.Range(), value)
+		}
+	}
+	if len(p.Options) > 0 {
+		for _, opt := range p.Options {
+			if strings.EqualFold(opt, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s, got %q", p.Name, p.Range(), value)
+	}
+	return nil
+}
+
+// Range describes the allowed values, e.g. "1..500", ">= 0" or "minute|volume|tick".
+// It is "" for a param with no constraints.
+func (p StrategyParam) Range() string {
+	formatBound := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	switch {
+	case len(p.Options) > 0:
+		return strings.Join(p.Options, "|")
+	case p.Min != nil && p.Max != nil:
+		return formatBound(*p.Min) + ".." + formatBound(*p.Max)
+	case p.Min != nil:
+		return ">= " + formatBound(*p.Min)
+	case p.Max != nil:
+		return "<= " + formatBound(*p.Max)
+	}
+	return ""
+}
+
+// FindParam returns the param called name from the strategy's schema
+func FindParam(s Strategy, name string) (StrategyParam, bool) {
+	for _, p := range s.GetParams() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return StrategyParam{}, false
+}
+
+// ValidateParams checks a full set of values against the strategy's schema and
+// then its own cross-field constraints. Params missing from values are taken at
+// their current value.
+func ValidateParams(s Strategy, values map[string]string) error {
+	merged := make(map[string]string)
+	for _, p := range s.GetParams() {
+		merged[p.Name] = p.Value
+	}
+	for name, value := range values {
+		if _, ok := merged[name]; !ok {
+			return fmt.Errorf("unknown parameter: %s", name)
+		}
+		merged[name] = value
+	}
+
+	for _, p := range s.GetParams() {
+		if err := p.Validate(merged[p.Name]); err != nil {
+			return err
+		}
+	}
+	if v, ok := s.(ParamValidator); ok {
+		return v.ValidateParams(merged)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package execution
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// DefaultWarmupBars is the history given to a strategy that says nothing about
+// how much it needs
+const DefaultWarmupBars = 25
+
+// WarmupBarsFor returns how many bars of history warm a strategy up: its
+// WarmupBars if it is a WarmupProvider, else one more than its largest int
+// param named *_length or *_period, else DefaultWarmupBars
+func WarmupBarsFor(s Strategy) int {
+	if w, ok := s.(WarmupProvider); ok {
+		return w.WarmupBars()
+	}
+	longest := 0
+	for _, p := range s.GetParams() {
+		if p.Type != "int" || !(strings.HasSuffix(p.Name, "_length") || strings.HasSuffix(p.Name, "_period")) {
+			continue
+		}
+		if n, err := strconv.Atoi(p.Value); err == nil {
+			longest = max(longest, n)
+		}
+	}
+	if longest == 0 {
+		return DefaultWarmupBars
+	}
+	return longest + 1
+}
+
+// NewStrategyWarmup creates a warm-up for strategy. onLive is called once the
+// history has been fed, before any live bar. Callbacks run outside the
+// warm-up's lock, so they may call Fed and Progress.
+func NewStrategyWarmup(strategy Strategy, onLive func()) *StrategyWarmup {
+	return &StrategyWarmup{
+		strategy: strategy,
+		need:     WarmupBarsFor(strategy),
+		onLive:   onLive,
+		history:  make(map[time.Time]marketdata.Bar),
+	}
+}
+
+// SetReadyHandler sets the callback for the strategy being ready to trade: live,
+// and primed if it is a ReadinessReporter. It is called once, right after
+// onLive when the history was enough, or after the live bar that completes it.
+func (w *StrategyWarmup) SetReadyHandler(handler func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onReady = handler
+}
+
+// SetErrorHandler sets the callback for OnBar returning an error or panicking,
+// given the error or a *StrategyPanic. It is called once, for the first
+// failure; no bar is fed to the strategy after it.
+func (w *StrategyWarmup) SetErrorHandler(handler func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = handler
+}
+
+// Err returns the first OnBar failure, nil while the strategy has not failed
+func (w *StrategyWarmup) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.failed
+}
+
+// SetChartIDs limits the warm-up to the charts with ids, the realtime and
+// historical IDs of the strategy's own subscription. Bars and the end of
+// history on any other chart are ignored from then on.
+func (w *StrategyWarmup) SetChartIDs(ids ...int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.chartIDs = make(map[int]bool, len(ids))
+	for _, id := range ids {
+		w.chartIDs[id] = true
+	}
+}
+
+// Preload feeds history loaded ahead of the chart subscription, such as the
+// pages of LoadHistory. The chart's own bars then continue from it.
+func (w *StrategyWarmup) Preload(bars []marketdata.Bar) {
+	w.mu.Lock()
+	for _, tb := range sortBars(bars) {
+		w.offer(tb)
+	}
+	failed := w.takeFailure()
+	w.mu.Unlock()
+
+	failed()
+}
+
+// OnChart takes a chart update from the strategy's subscription. Bars are
+// collected until the end of history, whether it comes in its own chart object
+// or on the one carrying the last bars, whose bars are always taken first.
+// Charts other than those set with SetChartIDs are skipped. The collected bars
+// are then fed oldest first and the strategy goes live. Until the first live
+// bar, later chart bars keep the newest history bar up to date.
+func (w *StrategyWarmup) OnChart(update marketdata.ChartUpdate) {
+	w.mu.Lock()
+	wentLive := w.collect(update)
+	ready := w.checkReady()
+	failed := w.takeFailure()
+	w.mu.Unlock()
+
+	if failed() {
+		return
+	}
+	if wentLive && w.onLive != nil {
+		w.onLive()
+	}
+	if ready != nil {
+		ready()
+	}
+}
+
+// collect does the work of OnChart and reports whether this update ended the
+// history. Callers must hold w.mu.
+func (w *StrategyWarmup) collect(update marketdata.ChartUpdate) bool {
+	if w.handedOn {
+		return false
+	}
+
+	wentLive := false
+	for _, chart := range update.Charts {
+		if w.chartIDs != nil && !w.chartIDs[chart.ID] {
+			continue
+		}
+		if w.live {
+			for _, tb := range sortBars(chart.Bars) {
+				w.offer(tb)
+			}
+			continue
+		}
+
+		for _, bar := range chart.Bars {
+			if at, err := marketdata.ParseFeedTime(bar.Timestamp); err == nil {
+				w.history[at] = bar // A repeat of the same bar replaces the earlier copy
+			}
+		}
+		if !chart.EOH {
+			continue
+		}
+
+		collected := make([]timedBar, 0, len(w.history))
+		for at, bar := range w.history {
+			collected = append(collected, timedBar{at, bar})
+		}
+		sort.Slice(collected, func(i, j int) bool { return collected[i].at.Before(collected[j].at) })
+		for _, tb := range collected {
+			w.offer(tb)
+		}
+		w.history = nil
+		w.live = true
+		wentLive = true
+	}
+	return wentLive
+}
+
+// OnLiveBar takes a bar closed by the live bar builder. The held history bar
+// is fed first unless the live bar is the same one, complete; bars no newer
+// than the last fed are dropped.
+func (w *StrategyWarmup) OnLiveBar(bar marketdata.Bar) {
+	w.mu.Lock()
+	if !w.live {
+		w.mu.Unlock()
+		return
+	}
+	at, err := marketdata.ParseFeedTime(bar.Timestamp)
+	if err != nil || (!w.last.IsZero() && !at.After(w.last)) {
+		w.mu.Unlock()
+		return
+	}
+	if w.held != nil && w.held.at.Before(at) {
+		w.feed(*w.held)
+	}
+	w.held = nil
+	w.handedOn = true
+	w.feed(timedBar{at, bar})
+	ready := w.checkReady()
+	failed := w.takeFailure()
+	w.mu.Unlock()
+
+	if failed() {
+		return
+	}
+	if ready != nil {
+		ready()
+	}
+}
+
+// Live reports whether the end of history has been seen
+func (w *StrategyWarmup) Live() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.live
+}
+
+// Fed returns how many bars the strategy has been given
+func (w *StrategyWarmup) Fed() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fed
+}
+
+// Ready reports whether the strategy is live and primed
+func (w *StrategyWarmup) Ready() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ready
+}
+
+// Progress returns how many bars the strategy has been given and how many it
+// asked for
+func (w *StrategyWarmup) Progress() (fed, need int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fed, w.need
+}
+
+// checkReady marks the warm-up ready the first time the strategy is live and
+// primed, returning the handler to call once w.mu is released (nil if none is
+// due). Callers must hold w.mu.
+func (w *StrategyWarmup) checkReady() func() {
+	if w.ready || !w.live || w.failed != nil {
+		return nil
+	}
+	if r, ok := w.strategy.(ReadinessReporter); ok && !r.IsReady() {
+		return nil
+	}
+	w.ready = true
+	return w.onReady
+}
+
+// offer places a history bar, taken oldest first. A newer bar than the held one
+// closes it, so the held bar is fed and the new one held; the same time replaces
+// it, and older bars are already covered. Callers must hold w.mu.
+func (w *StrategyWarmup) offer(tb timedBar) {
+	switch {
+	case !w.last.IsZero() && !tb.at.After(w.last):
+	case w.held == nil:
+		w.held = &tb
+	case tb.at.Equal(w.held.at):
+		w.held.bar = tb.bar
+	case tb.at.After(w.held.at):
+		w.feed(*w.held)
+		w.held = &tb
+	}
+}
+
+// takeFailure returns a func that passes a failure not yet reported to the
+// error handler and reports whether the strategy has failed. Call it once w.mu
+// is released. Callers must hold w.mu.
+func (w *StrategyWarmup) takeFailure() func() bool {
+	err, handler, failed := w.pending, w.onError, w.failed != nil
+	w.pending = nil
+	return func() bool {
+		if err != nil && handler != nil {
+			handler(err)
+		}
+		return failed
+	}
+}
+
+// feed passes a bar to the strategy, unless OnBar has already failed. Callers
+// must hold w.mu.
+func (w *StrategyWarmup) feed(tb timedBar) {
+	if w.failed != nil {
+		return
+	}
+	w.last = tb.at
+	w.fed++
+	s, ok := w.strategy.(interface {
+		OnBar(string, float64) error
+	})
+	if !ok {
+		return
+	}
+	if err := CallStrategy("OnBar", func() error { return s.OnBar(tb.bar.Timestamp, tb.bar.Close) }); err != nil {
+		w.failed, w.pending = err, err
+	}
+}
+
+// sortBars parses bar times and sorts the bars oldest first. Bars with unreadable
+// timestamps are dropped.
+func sortBars(bars []marketdata.Bar) []timedBar {
+	sorted := make([]timedBar, 0, len(bars))
+	for _, bar := range bars {
+		if at, err := marketdata.ParseFeedTime(bar.Timestamp); err == nil {
+			sorted = append(sorted, timedBar{at, bar})
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].at.Before(sorted[j].at) })
+	return sorted
+}
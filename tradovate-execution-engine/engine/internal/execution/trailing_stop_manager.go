@@ -0,0 +1,322 @@
+package execution
+
+import (
+	"fmt"
+	"math"
+
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// NewTrailingStopManager creates a trailing stop manager
+func NewTrailingStopManager(om *OrderManager, pt *portfolio.PortfolioTracker, md *tradovate.DataSubscriber, log *logger.Logger) *TrailingStopManager {
+	return &TrailingStopManager{
+		om:    om,
+		pt:    pt,
+		md:    md,
+		log:   log,
+		stops: make(map[string]*TrailingStop),
+	}
+}
+
+// Start begins trailing a stop the given number of ticks behind price for an
+// open position, moving it only once it would improve by minStepTicks. A
+// minStepTicks below 1 moves it on every tick.
+func (tsm *TrailingStopManager) Start(symbol string, ticks, minStepTicks int) error {
+	if ticks <= 0 {
+		return fmt.Errorf("ticks must be positive")
+	}
+	minStepTicks = max(minStepTicks, 1)
+
+	pos := tsm.om.GetPosition(symbol)
+	if pos.NetPos == 0 {
+		return fmt.Errorf("no open position for %s", symbol)
+	}
+
+	tickSize := tsm.pt.GetTickSize(symbol)
+	if tickSize <= 0 {
+		return fmt.Errorf("tick size unknown for %s", symbol)
+	}
+
+	// Each active stop holds one quote subscription until it is removed
+	if err := tsm.md.SubscribeQuote(symbol); err != nil {
+		return fmt.Errorf("failed to subscribe to quotes for %s: %w", symbol, err)
+	}
+
+	tsm.mu.Lock()
+	if _, exists := tsm.stops[symbol]; exists {
+		tsm.mu.Unlock()
+		tsm.release(symbol)
+		return fmt.Errorf("trailing stop already active for %s", symbol)
+	}
+	ts := &TrailingStop{
+		Symbol:       symbol,
+		Ticks:        ticks,
+		MinStepTicks: minStepTicks,
+		TickSize:     tickSize,
+	}
+	tsm.stops[symbol] = ts
+
+	if !tsm.handlerRegistered {
+		tsm.md.AddQuoteHandler(tsm.handleQuote)
+		tsm.handlerRegistered = true
+	}
+
+	// Place the initial stop from the last known price if we have one
+	var action trailAction
+	if pos.LastPrice > 0 {
+		action = tsm.plan(ts, pos.LastPrice)
+	}
+	tsm.mu.Unlock()
+	tsm.run(ts, action)
+
+	tsm.log.Infof("TRAIL STARTED | %s | %d ticks, min step %d (tick size %.4f)", symbol, ticks, minStepTicks, tickSize)
+	return nil
+}
+
+// Stop stops trailing the symbol, cancels its stop order and releases its quotes
+func (tsm *TrailingStopManager) Stop(symbol string) error {
+	tsm.mu.Lock()
+	ts, exists := tsm.stops[symbol]
+	if !exists {
+		tsm.mu.Unlock()
+		return fmt.Errorf("no trailing stop active for %s", symbol)
+	}
+	stop := ts.StopOrder
+	ts.clear()
+	delete(tsm.stops, symbol)
+	tsm.mu.Unlock()
+
+	tsm.cancelStop(symbol, stop)
+	tsm.release(symbol)
+	tsm.log.Infof("TRAIL STOPPED | %s", symbol)
+	return nil
+}
+
+// GetActive returns a copy of all active trailing stops
+func (tsm *TrailingStopManager) GetActive() []TrailingStop {
+	tsm.mu.Lock()
+	defer tsm.mu.Unlock()
+
+	active := make([]TrailingStop, 0, len(tsm.stops))
+	for _, ts := range tsm.stops {
+		active = append(active, *ts)
+	}
+	return active
+}
+
+// handleQuote moves stops as trade prices arrive. The broker is called after
+// tsm.mu is released, so a slow request only holds up this symbol's stop.
+func (tsm *TrailingStopManager) handleQuote(quote marketdata.Quote) {
+	trade, ok := quote.LastTrade()
+	if !ok {
+		return
+	}
+
+	symbol, ok := tsm.pt.GetContractName(quote.ContractID)
+	if !ok {
+		return
+	}
+
+	tsm.mu.Lock()
+	ts, exists := tsm.stops[symbol]
+	if !exists {
+		tsm.mu.Unlock()
+		return
+	}
+	action := tsm.plan(ts, trade.Price)
+	tsm.mu.Unlock()
+	tsm.run(ts, action)
+}
+
+// trailAction is the broker work plan decided on for one stop
+type trailAction struct {
+	cancel *models.Order // Stop to cancel first
+	remove bool          // The stop was removed, so its quotes are released
+	place  bool          // Submit a new stop at stopPrice
+	modify *models.Order // Stop to move to stopPrice
+	side   models.OrderSide
+	qty    int
+	netPos int
+	price  float64 // Trade price the action follows
+	stop   float64
+	from   float64 // Stop level being moved from
+}
+
+// plan works out what the stop should do at price and marks it busy when
+// there is broker work to do. Caller must hold tsm.mu.
+func (tsm *TrailingStopManager) plan(ts *TrailingStop, price float64) trailAction {
+	// The last action has not come back from the broker yet
+	if ts.busy {
+		return trailAction{}
+	}
+	// A stop that filled or the broker took down is gone, so it is not cancelled
+	// and a new one is placed while the position is open
+	if ts.StopOrder != nil {
+		switch status := tsm.stopStatus(ts.StopOrder.ID); status {
+		case models.StatusFilled, models.StatusCanceled, models.StatusRejected, models.StatusExpired, models.StatusFailed:
+			if status == models.StatusFilled {
+				tsm.log.Infof("TRAIL | %s | stop %s filled", ts.Symbol, ts.StopOrder.ID)
+			} else {
+				tsm.log.Warnf("TRAIL | %s | stop %s is %s, placing a new one", ts.Symbol, ts.StopOrder.ID, status)
+			}
+			ts.clear()
+		}
+	}
+
+	netPos := tsm.om.GetPosition(ts.Symbol).NetPos
+	action := trailAction{price: price, netPos: netPos}
+
+	// Position closed: the stop is no longer needed
+	if netPos == 0 {
+		action.cancel = ts.StopOrder
+		action.remove = true
+		ts.clear()
+		delete(tsm.stops, ts.Symbol)
+		tsm.log.Infof("TRAIL REMOVED | %s | position is flat", ts.Symbol)
+		return action
+	}
+
+	// Position flipped: the old stop protects the wrong side
+	if ts.NetPos != 0 && (netPos > 0) != (ts.NetPos > 0) {
+		tsm.log.Infof("TRAIL RESET | %s | position flipped %d -> %d", ts.Symbol, ts.NetPos, netPos)
+		action.cancel = ts.StopOrder
+		ts.clear()
+	}
+
+	offset := float64(ts.Ticks) * ts.TickSize
+	action.side = models.SideSell
+	action.stop = roundToTick(price-offset, ts.TickSize)
+	if netPos < 0 {
+		action.side = models.SideBuy
+		action.stop = roundToTick(price+offset, ts.TickSize)
+	}
+	action.qty = models.Abs(netPos)
+
+	if ts.StopOrder == nil {
+		action.place = true
+		ts.busy = true
+		return action
+	}
+
+	// Never widen: only move the stop in the position's favour
+	improvement := action.stop - ts.StopPrice
+	if netPos < 0 {
+		improvement = ts.StopPrice - action.stop
+	}
+	minStep := float64(ts.MinStepTicks) * ts.TickSize
+	if improvement < minStep-ts.TickSize/2 {
+		action.stop = ts.StopPrice
+	}
+	if action.stop == ts.StopPrice && netPos == ts.NetPos {
+		return action
+	}
+	action.modify = ts.StopOrder
+	action.from = ts.StopPrice
+	ts.busy = true
+	return action
+}
+
+// run makes the broker calls action needs, then records the result on ts. It
+// must be called without tsm.mu held.
+func (tsm *TrailingStopManager) run(ts *TrailingStop, action trailAction) {
+	tsm.cancelStop(ts.Symbol, action.cancel)
+	if action.remove {
+		tsm.release(ts.Symbol)
+	}
+
+	switch {
+	case action.place:
+		order, err := tsm.om.SubmitStopOrder(ts.Symbol, action.side, action.qty, action.stop)
+		if err != nil {
+			tsm.log.Errorf("TRAIL | %s | failed to place stop @ %.2f: %v", ts.Symbol, action.stop, err)
+			tsm.done(ts)
+			return
+		}
+		tsm.mu.Lock()
+		ts.busy = false
+		// Stopped while the order was on its way
+		if tsm.stops[ts.Symbol] != ts {
+			tsm.mu.Unlock()
+			tsm.cancelStop(ts.Symbol, order)
+			return
+		}
+		ts.StopOrder = order
+		ts.StopPrice = action.stop
+		ts.NetPos = action.netPos
+		tsm.mu.Unlock()
+		tsm.log.Infof("TRAIL PLACED | %s | %s %d stop @ %.2f (price %.2f)", ts.Symbol, action.side, action.qty, action.stop, action.price)
+
+	case action.modify != nil:
+		if err := tsm.om.ModifyOrder(action.modify.ID, action.qty, action.stop); err != nil {
+			tsm.log.Errorf("TRAIL | %s | failed to move stop %.2f -> %.2f: %v", ts.Symbol, action.from, action.stop, err)
+			tsm.done(ts)
+			return
+		}
+		tsm.mu.Lock()
+		ts.busy = false
+		if ts.StopOrder == action.modify {
+			ts.StopPrice = action.stop
+			ts.NetPos = action.netPos
+		}
+		tsm.mu.Unlock()
+		tsm.log.Infof("TRAIL MOVED | %s | stop %.2f -> %.2f | qty %d (price %.2f)", ts.Symbol, action.from, action.stop, action.qty, action.price)
+	}
+}
+
+// done clears ts's busy mark after a broker call that changed nothing
+func (tsm *TrailingStopManager) done(ts *TrailingStop) {
+	tsm.mu.Lock()
+	ts.busy = false
+	tsm.mu.Unlock()
+}
+
+// cancelStop cancels a stop order, if any. It must be called without tsm.mu held.
+func (tsm *TrailingStopManager) cancelStop(symbol string, stop *models.Order) {
+	if stop == nil {
+		return
+	}
+	if err := tsm.om.CancelOrder(stop.ID); err != nil {
+		// The stop may already have filled, which is what flattened the position
+		tsm.log.Debugf("TRAIL | %s | cancel of stop %s failed: %v", symbol, stop.ID, err)
+	} else {
+		tsm.log.Infof("TRAIL | %s | stop %s canceled", symbol, stop.ID)
+	}
+}
+
+// release gives back the quote subscription a removed stop held. It must be
+// called without tsm.mu held.
+func (tsm *TrailingStopManager) release(symbol string) {
+	if err := tsm.md.UnsubscribeQuote(symbol); err != nil {
+		tsm.log.Debugf("TRAIL | %s | failed to unsubscribe from quotes: %v", symbol, err)
+	}
+}
+
+// stopStatus returns the status of a stop order as the order manager has it
+func (tsm *TrailingStopManager) stopStatus(orderID string) models.OrderStatus {
+	order, ok := tsm.om.GetOrder(orderID)
+	if !ok {
+		return ""
+	}
+	tsm.om.Mu.RLock()
+	defer tsm.om.Mu.RUnlock()
+	return order.Status
+}
+
+// clear forgets the stop order. Caller must hold tsm.mu.
+func (ts *TrailingStop) clear() {
+	ts.StopOrder = nil
+	ts.StopPrice = 0
+	ts.NetPos = 0
+}
+
+// roundToTick rounds a price to the nearest tick increment
+func roundToTick(price, tickSize float64) float64 {
+	if tickSize <= 0 {
+		return price
+	}
+	return math.Round(price/tickSize) * tickSize
+}
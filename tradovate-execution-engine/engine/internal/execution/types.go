@@ -1,13 +1,24 @@
 package execution
 
 import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/audit"
 	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/contracts"
 	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
 	"tradovate-execution-engine/engine/internal/models"
 	"tradovate-execution-engine/engine/internal/portfolio"
 	"tradovate-execution-engine/engine/internal/risk"
+	"tradovate-execution-engine/engine/internal/schedule"
+	"tradovate-execution-engine/engine/internal/tradovate"
 )
 
 //
@@ -24,6 +35,199 @@ type OrderManager struct {
 	config           *config.Config
 	log              *logger.Logger
 	orderIDCounter   int
+	simulator        *SimulatedExecutor // When set, orders fill locally instead of at Tradovate
+	listeners        []func(models.Order)
+	externalIDs      map[string]string         // Exchange order ID -> local order ID
+	symbolResolver   *contracts.Resolver       // Resolves roots like "MES" to the front month (nil = symbols used as given)
+	catalog          *contracts.Catalog        // Tick sizes for contracts the portfolio has not synced (nil = none)
+	schedule         *schedule.TradingSchedule // Session window for new orders (nil = always open)
+	latency          latencySamples            // Stage durations of filled live orders
+	killed           bool                      // Kill switch engaged: new orders are refused until Arm
+	liveLocked       bool                      // Live trading not yet acknowledged: new orders are refused until ConfirmLive
+	ocoPairs         map[string]*OCOPair       // Pair ID -> one-cancels-other pair
+	ocoLegs          map[string]string         // Leg order ID -> pair ID
+	ocoListening     bool                      // The order listener that links pair legs is registered
+	symbolOrigins    map[string]string         // Symbol -> instance ID of the strategy running on it
+	entryPolicies    map[string]EntryPolicy    // Origin -> how its entries are placed (none = market)
+	quotes           QuoteSource               // Bid and ask for limit entries (nil = entries go at market)
+	queue            []queuedOrder             // Strategy orders waiting for Tradovate, oldest first
+	audit            *audit.Trail              // Order state changes and flattens are recorded here (nil = none)
+}
+
+// queuedOrder is a strategy order that could not be sent, with how to place it
+// once it can be
+type queuedOrder struct {
+	orderID    string
+	policy     EntryPolicy
+	limitEntry bool
+}
+
+// unsentError is a submit failure that left the order unsent for a reason
+// expected to clear: no session, a rate limit penalty or no connection
+type unsentError struct {
+	err error
+}
+
+// OCOState is how far a one-cancels-other pair has got
+type OCOState string
+
+const (
+	OCOWorking   OCOState = "WORKING"     // Both legs resting
+	OCOTriggered OCOState = "TRIGGERED"   // One leg filled and the other was cancelled
+	OCOCanceled  OCOState = "CANCELED"    // Both legs cancelled before either filled
+	OCOBothFills OCOState = "BOTH_FILLED" // Both legs filled before the cancel landed
+)
+
+// OCOPair is a buy stop and a sell stop where a fill on either cancels the
+// other. The order manager emulates the link locally; the JSON form is what
+// order persistence stores to relink the legs after a restart.
+type OCOPair struct {
+	ID          string   `json:"id"`
+	Symbol      string   `json:"symbol"`
+	Quantity    int      `json:"quantity"`
+	BuyOrderID  string   `json:"buyOrderId"`
+	SellOrderID string   `json:"sellOrderId"`
+	State       OCOState `json:"state"`
+	FilledLeg   string   `json:"filledLeg,omitempty"` // Order ID of the leg that filled first
+}
+
+// ErrKillSwitch is returned for orders submitted while the kill switch is engaged
+var ErrKillSwitch = errors.New("kill switch engaged")
+
+// ErrLiveNotConfirmed is returned for orders submitted to a live account before
+// live trading was acknowledged
+var ErrLiveNotConfirmed = errors.New("live trading not confirmed, use :confirm-live")
+
+// OrderOptions describes an order beyond its symbol, side and quantity. The zero
+// value is a Day market order.
+type OrderOptions struct {
+	Type        models.OrderType   // Empty means market
+	Price       float64            // Limit price (limit and stop-limit)
+	StopPrice   float64            // Trigger price (stop and stop-limit)
+	TimeInForce models.TimeInForce // Empty means Day
+	SignalAt    time.Time          // When the strategy decided to trade, for latency stats
+	Origin      string             // Empty means the strategy running on the symbol, else manual
+}
+
+// LatencyStats summarises one stage of the execution path
+type LatencyStats struct {
+	Count int
+	P50   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+// ExecutionStats is the latency of each stage for this session's filled live orders
+type ExecutionStats struct {
+	SignalToSubmit LatencyStats // Strategy decision to the placeorder request
+	SubmitToAck    LatencyStats // placeorder request to its response
+	AckToFill      LatencyStats // placeorder response to the fill event
+	SignalToFill   LatencyStats // End to end
+}
+
+// latencySamples keeps the most recent durations of each stage
+type latencySamples struct {
+	signalToSubmit []time.Duration
+	submitToAck    []time.Duration
+	ackToFill      []time.Duration
+	signalToFill   []time.Duration
+}
+
+//
+// SIMULATED EXECUTOR
+//
+
+// SimPosition is a position held in the simulator
+type SimPosition struct {
+	NetPos      int
+	AvgPrice    float64
+	RealizedPnL float64 // Gross of fees
+	Fees        float64 // Paid on every fill so far, including the open position's entry
+}
+
+// SimFill records a single simulated fill
+type SimFill struct {
+	OrderID   string
+	Symbol    string
+	Side      models.OrderSide
+	Quantity  int
+	Price     float64
+	Fees      float64
+	Timestamp string
+	Model     string  // Slippage model the fill was priced with
+	Slippage  float64 // Points per contract the fill was moved against the order
+}
+
+// simOrder is a limit order resting in the simulator until the market reaches it
+type simOrder struct {
+	id         string
+	externalID string
+	symbol     string
+	side       models.OrderSide
+	quantity   int
+	price      float64
+}
+
+// SimTrade is a closed round trip (or the closed part of one)
+type SimTrade struct {
+	Symbol     string
+	Side       models.OrderSide // Side of the entry
+	Quantity   int
+	EntryPrice float64
+	ExitPrice  float64
+	ExitTime   string
+	PnL        float64 // Gross
+	Fees       float64 // Round trip fees of the closed quantity
+	NetPnL     float64
+}
+
+// SimulatedExecutor fills market orders at the last known price. It is used for
+// paper trading and by the backtest harness so both share one fill model.
+type SimulatedExecutor struct {
+	mu            sync.Mutex
+	prices        map[string]float64
+	timestamps    map[string]string
+	valuePerPoint map[string]float64
+	tickSizes     map[string]float64
+	fees          map[string]float64 // Per contract per side
+	bids          map[string]float64
+	asks          map[string]float64
+	slippage      config.SlippageConfig
+	rng           *rand.Rand // Seeded, so a backtest slips the same way every run
+	positions     map[string]*SimPosition
+	resting       []simOrder
+	orderSeq      int
+	onFill        func(orderID string, fill models.Fill) // Told of fills to resting orders
+	fills         []SimFill
+	trades        []SimTrade
+}
+
+//
+// TRAILING STOP MANAGER
+//
+
+// TrailingStop tracks the protective stop maintained for one symbol
+type TrailingStop struct {
+	Symbol       string
+	Ticks        int     // Distance from price in ticks
+	MinStepTicks int     // Minimum improvement in ticks before the stop is moved
+	TickSize     float64 // Price increment of the contract
+	StopPrice    float64 // Current stop level (0 until placed)
+	NetPos       int     // Position size the stop currently protects
+	StopOrder    *models.Order
+
+	busy bool // A place or modify is with the broker
+}
+
+// TrailingStopManager maintains stop orders that follow price for open positions
+type TrailingStopManager struct {
+	mu                sync.Mutex
+	om                *OrderManager
+	pt                *portfolio.PortfolioTracker
+	md                *tradovate.DataSubscriber
+	log               *logger.Logger
+	stops             map[string]*TrailingStop // Keyed by symbol
+	handlerRegistered bool
 }
 
 //
@@ -36,6 +240,144 @@ type StrategyParam struct {
 	Type        string // "int", "float", "string"
 	Value       string
 	Description string
+	Min         *float64 // Lowest allowed value of a numeric param (nil = unbounded)
+	Max         *float64 // Highest allowed value of a numeric param (nil = unbounded)
+	Options     []string // Allowed values, if the param is one of a fixed set
+}
+
+// ParamValidator is implemented by strategies with constraints between params,
+// such as a fast length that must be below the slow length. ValidateParams is
+// given every param's value and is checked before a strategy is added, started
+// or backtested.
+type ParamValidator interface {
+	ValidateParams(params map[string]string) error
+}
+
+// WarmupProvider is implemented by strategies that need a number of bars before
+// their first signal, such as the length of a moving average. The engine loads
+// that much history before live data starts, paging past Tradovate's
+// per-request limit when it has to.
+type WarmupProvider interface {
+	WarmupBars() int
+}
+
+// ReadinessReporter is implemented by strategies that can tell when their
+// indicators are primed. Live trading is not enabled until IsReady is true,
+// however much history was loaded. IsReady is called between bars.
+type ReadinessReporter interface {
+	IsReady() bool
+}
+
+// SignalReporter is implemented by strategies that can name the last signal
+// they generated, e.g. "Long". The metrics recorder writes it with each bar.
+type SignalReporter interface {
+	LastSignal() string
+}
+
+// PositionSyncer is implemented by strategies that keep their own position.
+// The engine compares StrategyPosition, in net contracts (negative when short),
+// with the broker's and skips the check while pending says an order is in
+// flight. SyncPosition takes the broker's net position when the two drifted
+// apart, e.g. after a fill that landed while disconnected.
+type PositionSyncer interface {
+	StrategyPosition() (netPos int, pending bool)
+	SyncPosition(netPos int)
+}
+
+// QuoteHandler is implemented by strategies that act on every quote for their
+// symbol, not only on bars. Quotes arrive once warm-up is done, on a goroutine
+// of their own; UpdateModeProvider says whether bars still come as well.
+type QuoteHandler interface {
+	OnQuote(quote marketdata.Quote) error
+}
+
+// UpdateMode is how a running strategy takes live data
+type UpdateMode int
+
+const (
+	UpdateOnBarClose UpdateMode = iota // Closed bars only
+	UpdateOnEachTick                   // Quotes as they arrive, and closed bars
+	UpdateTicksOnly                    // Quotes only; bars are used for warm-up
+)
+
+// UpdateModeProvider is implemented by QuoteHandlers that choose their
+// UpdateMode, e.g. from a parameter. Without it a QuoteHandler gets
+// UpdateOnEachTick.
+type UpdateModeProvider interface {
+	UpdateMode() UpdateMode
+}
+
+//
+// ENTRY POLICY
+//
+
+// EntryType is how a strategy's entry signal becomes an order
+type EntryType string
+
+const (
+	EntryMarket       EntryType = "market"
+	EntryLimitJoin    EntryType = "limit_join"    // Limit at the bid to buy, the ask to sell
+	EntryLimitThrough EntryType = "limit_through" // Limit ThroughTicks past the join price, written limit_through_N_ticks
+)
+
+// EntryFallback is what becomes of a limit entry still unfilled at its timeout
+type EntryFallback string
+
+const (
+	EntryFallbackCancel EntryFallback = "cancel"
+	EntryFallbackMarket EntryFallback = "market"
+)
+
+// EntryPolicy turns the market orders a strategy submits to open, add to or
+// reverse a position into limit orders priced off the latest quote. Orders that
+// only reduce the position still go at market.
+type EntryPolicy struct {
+	Type         EntryType
+	ThroughTicks int           // Ticks past the join price, for EntryLimitThrough
+	Timeout      time.Duration // How long a limit entry may rest unfilled
+	Fallback     EntryFallback
+}
+
+// EntryPolicyProvider is implemented by strategies whose entries follow an
+// EntryPolicy, usually set from the params EntryPolicy.Params describes. The
+// engine reads it when the strategy starts.
+type EntryPolicyProvider interface {
+	EntryPolicy() EntryPolicy
+}
+
+// QuoteSource gives the latest best bid and ask of a symbol
+type QuoteSource interface {
+	BestBidAsk(symbol string) (bid, ask float64, ok bool)
+}
+
+// OrderSubmitter is the part of the order manager a strategy trades through
+type OrderSubmitter interface {
+	SubmitOrder(symbol string, side models.OrderSide, quantity int, opts OrderOptions) (*models.Order, error)
+	SubmitMarketOrder(symbol string, side models.OrderSide, quantity int) (*models.Order, error)
+	SubmitLimitOrder(symbol string, side models.OrderSide, quantity int, price float64) (*models.Order, error)
+	CancelOrder(orderID string) error
+	GetOrder(orderID string) (*models.Order, bool)
+	OrderSnapshot(orderID string) (models.Order, bool)
+	AddOrderListener(listener func(models.Order))
+}
+
+// PortfolioSource is the account's positions and PnL as a strategy sees them
+type PortfolioSource interface {
+	GetPosition(symbol string) portfolio.PLEntry
+	GetDailyPnL() float64
+}
+
+// Broker is what a strategy is given at Init: where it sends orders, the
+// account it trades and the contract details it sizes orders with.
+// *OrderManager is the live and simulated Broker; testsupport.FakeBroker
+// records orders for strategy tests.
+type Broker interface {
+	OrderSubmitter
+	PortfolioSource
+	ResolveSymbol(symbol string) (string, error)
+	GetTickSize(symbol string) float64
+	MaxContracts() (limit int, enforced bool)
+	MaxOrderQty(symbol string) (limit int, enforced bool)
 }
 
 // Strategy interface defines the required methods for any trading strategy
@@ -44,9 +386,129 @@ type Strategy interface {
 	Description() string
 	GetParams() []StrategyParam
 	SetParam(name, value string) error
-	Init(om *OrderManager) error
+	Init(broker Broker) error
 	GetMetrics() map[string]float64
 	Reset()
+	SetLogger(l *logger.Logger) // Routes strategy output to the Strategy Log
+}
+
+// StrategyInfo describes a registered strategy as DescribeAll reads it
+type StrategyInfo struct {
+	Key         string // Registry name, as :strategy add takes it
+	Name        string
+	Description string
+	Params      []StrategyParam // With their default values
+}
+
+//
+// FLATTEN PLAN
+//
+
+// FlattenPlan is what CancelWorkingOrders and FlattenPositions would send right
+// now, as worked out by PreviewFlatten without sending anything
+type FlattenPlan struct {
+	Cancels []models.Order // Working orders that would be cancelled, by symbol then ID
+	Closes  []FlattenClose // One market order per symbol with a position, by symbol
+}
+
+// FlattenClose is the market order that would close one symbol's position
+type FlattenClose struct {
+	Symbol   string
+	NetPos   int
+	Side     models.OrderSide
+	Quantity int
+}
+
+//
+// QUOTE QUEUE
+//
+
+// QuoteQueue delivers quotes to a QuoteHandler from its own goroutine, so a
+// slow strategy cannot hold up the WebSocket reader. When the queue is full
+// the oldest quote is dropped to make room.
+type QuoteQueue struct {
+	handler  QuoteHandler
+	onError  func(error)
+	quotes   chan marketdata.Quote
+	done     chan struct{}
+	finished chan struct{}
+	stopOnce sync.Once
+	dropped  atomic.Int64
+}
+
+//
+// STRATEGY CALLBACKS
+//
+
+// StrategyPanic is a panic recovered from a strategy callback by CallStrategy
+type StrategyPanic struct {
+	Callback string // e.g. "OnBar"
+	Value    interface{}
+	Stack    []byte // Stack trace of the panicking goroutine
+}
+
+//
+// STRATEGY LIFECYCLE
+//
+
+// LifecycleState is where a strategy is in its lifecycle:
+// Created -> Configured -> Initialized -> Running <-> Stopped, and back to
+// Configured from any of them on Reset
+type LifecycleState int32
+
+const (
+	LifecycleCreated     LifecycleState = iota // Built by its factory, params at their defaults
+	LifecycleConfigured                        // Params may be set; Init may be called
+	LifecycleInitialized                       // Init succeeded; warming up on history
+	LifecycleRunning                           // Live on the engine or in a backtest
+	LifecycleStopped                           // Taken off live data; Reset before configuring again
+)
+
+// StrategyLifecycle is embedded by strategies to validate their lifecycle
+// transitions. Its zero value is LifecycleCreated.
+type StrategyLifecycle struct {
+	state atomic.Int32
+}
+
+// LifecycleGuarded is implemented by strategies that embed StrategyLifecycle.
+// CreateStrategy, the engine and the backtester drive its transitions.
+type LifecycleGuarded interface {
+	Lifecycle() *StrategyLifecycle
+}
+
+//
+// STRATEGY WARMUP
+//
+
+// StrategyWarmup feeds a strategy its history and then live bars, each bar
+// once and in timestamp order. Chart bars are collected until the end of
+// history, and the newest is held back since it may still be forming; the
+// live stream takes over from it with its first bar.
+type StrategyWarmup struct {
+	mu       sync.Mutex
+	strategy Strategy
+	need     int    // Bars the strategy asked for, from WarmupBarsFor
+	onLive   func() // Called once, when the history has been fed
+	onReady  func() // Called once, when the strategy is live and primed
+
+	chartIDs map[int]bool                 // Charts the strategy's bars arrive on; nil takes every chart
+	history  map[time.Time]marketdata.Bar // Chart bars before the end of history, by time
+	held     *timedBar                    // Newest history bar, fed once it is known to be closed
+	last     time.Time                    // Time of the last bar fed
+	live     bool                         // End of history seen
+	handedOn bool                         // First live bar fed; chart bars are ignored from then on
+	ready    bool                         // onReady has been called
+	fed      int
+
+	onError func(error) // Called once, for the first bar OnBar failed or panicked on
+	failed  error       // First OnBar failure; no bar is fed after it
+	pending error       // failed, until it has been passed to onError
+}
+
+// timedBar is a bar with its parsed timestamp
+type timedBar struct {
+	at  time.Time
+	bar marketdata.Bar
 }
 
 // StrategyRegistry maintains a list of available strategies
@@ -58,3 +520,29 @@ type StrategyRegistry struct {
 var globalRegistry = &StrategyRegistry{
 	strategies: make(map[string]func(*logger.Logger) Strategy),
 }
+
+//
+// METRICS RECORDER
+//
+
+// MetricsRecorder appends a strategy's GetMetrics values to a CSV file, one row
+// per bar. Rows are buffered and written whole, so the file never ends mid-row.
+type MetricsRecorder struct {
+	mu        sync.Mutex
+	path      string
+	file      *os.File
+	columns   []string     // Metric names in header order
+	header    bool         // The file has a header row
+	pending   bytes.Buffer // Complete rows not yet written
+	lastFlush time.Time
+	rows      int
+	err       error // First write error; nothing is recorded after it
+}
+
+// MetricsRow is one bar's line in a metrics CSV
+type MetricsRow struct {
+	Timestamp string             // Bar time as the feed gives it
+	Metrics   map[string]float64 // GetMetrics after the bar
+	Position  int                // Net position of the strategy's contract
+	Signal    string             // LastSignal, "" if the strategy has none
+}
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -21,15 +23,53 @@ func NewLogger(maxSize int, minLevel LogLevel) *Logger {
 	}
 }
 
+// NewChildLogger creates a logger with its own buffer whose entries are also
+// written to parent, prefixed with prefix. It starts at the parent's level.
+func NewChildLogger(parent *Logger, maxSize int, prefix string) *Logger {
+	parent.mu.RLock()
+	level := parent.minLevel
+	parent.mu.RUnlock()
+
+	l := NewLogger(maxSize, level)
+	l.parent = parent
+	l.prefix = prefix
+	return l
+}
+
+// ParseLevel reads a level name such as "debug" or "WARN", in any case.
+// "warning" is accepted for warn.
+func ParseLevel(name string) (LogLevel, error) {
+	level := LogLevel(strings.ToUpper(strings.TrimSpace(name)))
+	if level == "WARNING" {
+		level = LevelWarn
+	}
+	if _, ok := levelPriority[level]; !ok {
+		return "", fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+	}
+	return level, nil
+}
+
 // log is the internal logging method
 func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
-	if levelPriority[level] < levelPriority[l.minLevel] {
+	l.mu.RLock()
+	minLevel := l.minLevel
+	l.mu.RUnlock()
+	if levelPriority[level] < levelPriority[minLevel] {
 		return
 	}
+	message := fmt.Sprintf(format, args...)
+	l.append(level, message)
+
+	if l.parent != nil {
+		l.parent.log(level, "%s%s", l.prefix, message)
+	}
+}
+
+// append stores an entry and hands it to subscribers
+func (l *Logger) append(level LogLevel, message string) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	message := fmt.Sprintf(format, args...)
 	entry := LogEntry{
 		Timestamp: time.Now(),
 		Level:     level,
@@ -40,18 +80,55 @@ func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
 
 	// Keep only the last maxSize entries
 	if len(l.entries) > l.maxSize {
+		l.first += len(l.entries) - l.maxSize
 		l.entries = l.entries[len(l.entries)-l.maxSize:]
 	}
+
+	// Never block logging on a slow subscriber
+	for _, ch := range l.subscribers {
+		select {
+		case ch <- entry:
+		default:
+			l.dropped++
+		}
+	}
+}
+
+// Subscribe delivers every new entry to ch until the returned function is called.
+// Entries are dropped for a subscriber whose channel is full, so give it a buffer.
+func (l *Logger) Subscribe(ch chan LogEntry) (unsubscribe func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.subscribers == nil {
+		l.subscribers = make(map[int]chan LogEntry)
+	}
+	id := l.nextSubID
+	l.nextSubID++
+	l.subscribers[id] = ch
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subscribers, id)
+	}
+}
+
+// DroppedCount returns how many entries were dropped for subscribers that fell behind
+func (l *Logger) DroppedCount() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.dropped
 }
 
 // Print logs an info message (fmt.Print style)
 func (l *Logger) Print(args ...interface{}) {
-	l.log(LevelInfo, fmt.Sprint(args...))
+	l.log(LevelInfo, "%s", fmt.Sprint(args...))
 }
 
 // Println logs an info message (fmt.Println style)
 func (l *Logger) Println(args ...interface{}) {
-	l.log(LevelInfo, fmt.Sprint(args...))
+	l.log(LevelInfo, "%s", fmt.Sprint(args...))
 }
 
 // Printf logs an info message (fmt.Printf style)
@@ -61,7 +138,7 @@ func (l *Logger) Printf(format string, args ...interface{}) {
 
 // Info logs an informational message
 func (l *Logger) Info(args ...interface{}) {
-	l.log(LevelInfo, fmt.Sprint(args...))
+	l.log(LevelInfo, "%s", fmt.Sprint(args...))
 }
 
 // Infof logs an informational message with formatting
@@ -71,7 +148,7 @@ func (l *Logger) Infof(format string, args ...interface{}) {
 
 // Error logs an error message
 func (l *Logger) Error(args ...interface{}) {
-	l.log(LevelError, fmt.Sprint(args...))
+	l.log(LevelError, "%s", fmt.Sprint(args...))
 }
 
 // Errorf logs an error message with formatting
@@ -81,7 +158,7 @@ func (l *Logger) Errorf(format string, args ...interface{}) {
 
 // Warn logs a warning message
 func (l *Logger) Warn(args ...interface{}) {
-	l.log(LevelWarn, fmt.Sprint(args...))
+	l.log(LevelWarn, "%s", fmt.Sprint(args...))
 }
 
 // Warnf logs a warning message with formatting
@@ -91,7 +168,7 @@ func (l *Logger) Warnf(format string, args ...interface{}) {
 
 // Debug logs a debug message
 func (l *Logger) Debug(args ...interface{}) {
-	l.log(LevelDebug, fmt.Sprint(args...))
+	l.log(LevelDebug, "%s", fmt.Sprint(args...))
 }
 
 // Debugf logs a debug message with formatting
@@ -99,12 +176,21 @@ func (l *Logger) Debugf(format string, args ...interface{}) {
 	l.log(LevelDebug, format, args...)
 }
 
-func (l *Logger) SetLevel(level LogLevel) {
+// SetMinLevel changes the lowest level kept from now on. Entries already
+// stored are not affected. Safe to call while other goroutines log.
+func (l *Logger) SetMinLevel(level LogLevel) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.minLevel = level
 }
 
+// MinLevel returns the lowest level kept
+func (l *Logger) MinLevel() LogLevel {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.minLevel
+}
+
 // GetEntries returns a copy of all log entries
 func (l *Logger) GetEntries() []LogEntry {
 	l.mu.RLock()
@@ -120,9 +206,19 @@ func (l *Logger) GetEntries() []LogEntry {
 func (l *Logger) Clear() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	l.first += len(l.entries)
 	l.entries = make([]LogEntry, 0)
 }
 
+// FirstIndex returns the position of the oldest kept entry among every entry
+// ever logged. FirstIndex()+i names entry i of GetEntries however many entries
+// are trimmed or cleared later.
+func (l *Logger) FirstIndex() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.first
+}
+
 // Count returns the number of log entries
 func (l *Logger) Count() int {
 	l.mu.RLock()
@@ -137,10 +233,40 @@ func (l *Logger) ExportToString() string {
 
 	var result string
 	for _, entry := range l.entries {
-		result += fmt.Sprintf("[%s] %-5s %s\n",
-			entry.Timestamp.Format("2006-01-02 15:04:05"),
-			entry.Level,
-			entry.Message)
+		result += FormatEntry(entry) + "\n"
 	}
 	return result
 }
+
+// EntryRange returns the kept entries from position from to position to
+// inclusive, positions as FirstIndex counts them, in either order. The range is
+// clamped to the entries still kept.
+func (l *Logger) EntryRange(from, to int) []LogEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if from > to {
+		from, to = to, from
+	}
+	start := max(from-l.first, 0)
+	end := min(to-l.first+1, len(l.entries))
+	if start >= end {
+		return nil
+	}
+	entries := make([]LogEntry, end-start)
+	copy(entries, l.entries[start:end])
+	return entries
+}
+
+// FormatEntry returns an entry as one line of ExportToString
+func FormatEntry(entry LogEntry) string {
+	return fmt.Sprintf("[%s] %-5s %s", entry.Timestamp.Format("2006-01-02 15:04:05"), entry.Level, entry.Message)
+}
+
+// ExportToJSON returns all logs as a JSON array of {timestamp, level, message}
+func (l *Logger) ExportToJSON() ([]byte, error) {
+	entries := l.GetEntries()
+	for i := range entries {
+		entries[i].Timestamp = entries[i].Timestamp.UTC()
+	}
+	return json.MarshalIndent(entries, "", "  ")
+}
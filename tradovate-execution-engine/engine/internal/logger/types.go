@@ -14,9 +14,9 @@ type LogLevel string
 
 // LogEntry represents a single log entry
 type LogEntry struct {
-	Timestamp time.Time
-	Level     LogLevel
-	Message   string
+	Timestamp time.Time `json:"timestamp"`
+	Level     LogLevel  `json:"level"`
+	Message   string    `json:"message"`
 }
 
 // Logger handles all logging throughout the application
@@ -25,6 +25,16 @@ type Logger struct {
 	entries  []LogEntry
 	maxSize  int
 	minLevel LogLevel
+	first    int // Entries trimmed or cleared before entries[0]
+
+	// Subscribers tail new entries; a full channel drops the entry for that subscriber
+	subscribers map[int]chan LogEntry
+	nextSubID   int
+	dropped     int
+
+	// Child loggers also write every entry to their parent
+	parent *Logger
+	prefix string
 }
 
 var levelPriority = map[LogLevel]int{
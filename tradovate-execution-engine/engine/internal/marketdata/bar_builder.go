@@ -0,0 +1,283 @@
+package marketdata
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultBarGrace is how long live time bars wait for late trades before closing
+const DefaultBarGrace = time.Second
+
+// ParseBarSpec validates a bar type ("minute", "volume" or "tick"; empty means
+// minute) and size
+func ParseBarSpec(barType string, size int) (BarSpec, error) {
+	t := BarType(strings.ToLower(strings.TrimSpace(barType)))
+	if t == "" {
+		t = BarTypeMinute
+	}
+	switch t {
+	case BarTypeMinute, BarTypeVolume, BarTypeTick:
+	default:
+		return BarSpec{}, fmt.Errorf("unknown bar type %q (use minute, volume or tick)", barType)
+	}
+	if size <= 0 {
+		return BarSpec{}, fmt.Errorf("bar size must be positive")
+	}
+	return BarSpec{Type: t, Size: size}, nil
+}
+
+// ChartDescription is the md/getchart description for the spec, so historical
+// bars match the ones built live
+func (s BarSpec) ChartDescription() ChartDesc {
+	switch s.Type {
+	case BarTypeVolume:
+		return ChartDesc{UnderlyingType: "Tick", ElementSize: s.Size, ElementSizeUnit: "Volume"}
+	case BarTypeTick:
+		return ChartDesc{UnderlyingType: "Tick", ElementSize: s.Size, ElementSizeUnit: "UnderlyingUnits"}
+	default:
+		return ChartDesc{UnderlyingType: "MinuteBar", ElementSize: s.Size, ElementSizeUnit: "UnderlyingUnits"}
+	}
+}
+
+// String describes the spec, e.g. "1000 volume"
+func (s BarSpec) String() string {
+	return fmt.Sprintf("%d %s", s.Size, s.Type)
+}
+
+// NewBarBuilder creates a builder that calls onBar with each completed bar
+func NewBarBuilder(interval time.Duration, onBar func(Bar)) *BarBuilder {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &BarBuilder{
+		spec:     BarSpec{Type: BarTypeMinute, Size: int(interval / time.Minute)},
+		interval: interval,
+		onBar:    onBar,
+	}
+}
+
+// NewBarBuilderFor creates a builder for spec. Volume and tick bars close when
+// the threshold is reached rather than at an interval boundary.
+func NewBarBuilderFor(spec BarSpec, onBar func(Bar)) *BarBuilder {
+	if spec.Type == BarTypeMinute || spec.Size <= 0 {
+		return NewBarBuilder(time.Duration(spec.Size)*time.Minute, onBar)
+	}
+	return &BarBuilder{spec: spec, onBar: onBar}
+}
+
+// OnQuote feeds the quote's Trade entry into the builder. The quote's Time is
+// used when set, otherwise its Timestamp is parsed; quotes with neither are
+// ignored. Quotes without a trade still advance the feed clock for time bars,
+// so a bar held for late trades is released in a quiet market.
+func (b *BarBuilder) OnQuote(q Quote) {
+	ts := q.Time
+	if ts.IsZero() {
+		parsed, err := ParseFeedTime(q.Timestamp)
+		if err != nil {
+			return
+		}
+		ts = parsed
+	}
+	trade, ok := q.LastTrade()
+	if !ok {
+		if b.interval > 0 {
+			b.Advance(ts)
+		}
+		return
+	}
+	if b.interval > 0 {
+		b.AddTrade(trade.Price, ts)
+		return
+	}
+	if size := b.tradedSince(q, trade); size > 0 {
+		b.AddSizedTrade(trade.Price, size, ts)
+	}
+}
+
+// SetGrace sets how long a time bar stays open to late trades after the next
+// one opens, measured on the feed's clock. It is capped at half the interval.
+// With no grace, which is the default, a bar closes on the first later trade.
+func (b *BarBuilder) SetGrace(grace time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.grace = min(max(grace, 0), b.interval/2)
+}
+
+// tradedSince returns the contracts traded since the previous quote. Quotes
+// repeat the last trade on every bid or offer change, so the increase in
+// TotalTradeVolume is used when the quote carries it.
+func (b *BarBuilder) tradedSince(q Quote, trade Entry) float64 {
+	total, ok := q.TotalVolume()
+	if !ok {
+		return trade.Size
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	prev := b.totalVolume
+	b.totalVolume = total
+	// The first snapshot and a session reset only set the baseline
+	if prev == 0 || total <= prev {
+		return 0
+	}
+	return total - prev
+}
+
+// AddSizedTrade adds size contracts traded at price to a volume or tick bar. A
+// bar closes once it holds spec.Size contracts (volume) or trades (tick); the
+// rest of a trade that crosses the threshold opens the next bar, so one large
+// trade can close several bars. Bars closed by the same trade are stamped a
+// nanosecond apart so consumers that skip repeated timestamps see each one.
+func (b *BarBuilder) AddSizedTrade(price, size float64, ts time.Time) {
+	units := size
+	if b.spec.Type == BarTypeTick {
+		units = 1
+	}
+	if units <= 0 || b.spec.Size <= 0 {
+		return
+	}
+	ts = ts.UTC()
+	target := float64(b.spec.Size)
+
+	var completed []Bar
+	b.mu.Lock()
+	for opened := 0; units > 0; {
+		if b.start.IsZero() {
+			b.startBar(ts.Add(time.Duration(opened)), price, ts)
+			b.current.Timestamp = b.start.Format(time.RFC3339Nano)
+			b.count = 0
+			opened++
+		} else {
+			b.current.High = max(b.current.High, price)
+			b.current.Low = min(b.current.Low, price)
+			b.current.Close = price
+		}
+
+		take := min(units, target-b.count)
+		b.count += take
+		units -= take
+		if b.count >= target {
+			completed = append(completed, b.current)
+			b.start = time.Time{}
+		}
+	}
+	onBar := b.onBar
+	b.mu.Unlock()
+
+	if onBar == nil {
+		return
+	}
+	for _, bar := range completed {
+		onBar(bar)
+	}
+}
+
+// AddTrade adds one trade. A trade in a later interval closes the current bar;
+// intervals without trades produce no bars. The closed bar is held until the
+// feed clock passes its end by the grace window, and trades stamped inside it
+// that arrive meanwhile still count. Older trades are dropped, and late trades
+// inside a bar update the range but not the close. The first bar is not
+// emitted unless its first trade opened the interval, since trades before the
+// subscription are missing from it.
+func (b *BarBuilder) AddTrade(price float64, ts time.Time) {
+	ts = ts.UTC()
+	barStart := ts.Truncate(b.interval)
+
+	var completed []Bar
+	b.mu.Lock()
+
+	switch {
+	case b.start.IsZero():
+		b.startBar(barStart, price, ts)
+		b.partial = !ts.Equal(barStart)
+	case b.held != nil && barStart.Equal(b.held.start):
+		addToBar(&b.held.bar, &b.held.lastTrade, price, ts)
+	case barStart.Before(b.start):
+	case barStart.Equal(b.start):
+		addToBar(&b.current, &b.lastTrade, price, ts)
+	default:
+		if b.held != nil && b.held.emit {
+			completed = append(completed, b.held.bar)
+		}
+		b.held = &heldBar{bar: b.current, start: b.start, lastTrade: b.lastTrade, emit: !b.partial}
+		b.startBar(barStart, price, ts)
+		b.partial = false
+	}
+	completed = append(completed, b.release(ts)...)
+	onBar := b.onBar
+	b.mu.Unlock()
+
+	if onBar == nil {
+		return
+	}
+	for _, bar := range completed {
+		onBar(bar)
+	}
+}
+
+// Advance moves the feed clock to ts without a trade, emitting the held bar
+// once its grace window has passed
+func (b *BarBuilder) Advance(ts time.Time) {
+	b.mu.Lock()
+	completed := b.release(ts.UTC())
+	onBar := b.onBar
+	b.mu.Unlock()
+
+	if onBar == nil {
+		return
+	}
+	for _, bar := range completed {
+		onBar(bar)
+	}
+}
+
+// release returns the held bar if the feed clock at now has passed its end plus
+// the grace window. Callers must hold b.mu.
+func (b *BarBuilder) release(now time.Time) []Bar {
+	if b.held == nil || now.Before(b.held.start.Add(b.interval+b.grace)) {
+		return nil
+	}
+	held := b.held
+	b.held = nil
+	if !held.emit {
+		return nil
+	}
+	return []Bar{held.bar}
+}
+
+// addToBar widens bar's range to price, and moves its close when ts is not
+// older than the latest trade already in it
+func addToBar(bar *Bar, lastTrade *time.Time, price float64, ts time.Time) {
+	bar.High = max(bar.High, price)
+	bar.Low = min(bar.Low, price)
+	if !ts.Before(*lastTrade) {
+		bar.Close = price
+		*lastTrade = ts
+	}
+}
+
+// Reset discards the bar in progress, e.g. after resubscribing
+func (b *BarBuilder) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current = Bar{}
+	b.start = time.Time{}
+	b.lastTrade = time.Time{}
+	b.partial = false
+	b.held = nil
+	b.count = 0
+	b.totalVolume = 0
+}
+
+func (b *BarBuilder) startBar(start time.Time, price float64, ts time.Time) {
+	b.start = start
+	b.lastTrade = ts
+	b.current = Bar{
+		Timestamp: start.Format(time.RFC3339),
+		Open:      price,
+		High:      price,
+		Low:       price,
+		Close:     price,
+	}
+}
@@ -0,0 +1,123 @@
+package marketdata
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// SkewWarning is how far the feed clock may drift from the local one before
+// it is logged
+const SkewWarning = 2 * time.Second
+
+// feedTimeLayouts are the timestamp forms seen on the feed. Quotes carry
+// RFC 3339 with or without fractional seconds; some payloads drop the zone,
+// which is UTC.
+var feedTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// ParseFeedTime parses a market data timestamp in any of the forms the feed
+// sends. The result is in UTC.
+func ParseFeedTime(ts string) (time.Time, error) {
+	ts = strings.TrimSpace(ts)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, ts); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised timestamp %q", ts)
+}
+
+// NewFeedClock creates a clock that reads the local time from now, or from
+// time.Now if now is nil
+func NewFeedClock(now func() time.Time) *FeedClock {
+	if now == nil {
+		now = time.Now
+	}
+	return &FeedClock{now: now}
+}
+
+// SetLogger sets where parse failures and skew warnings are logged
+func (c *FeedClock) SetLogger(l *logger.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.log = l
+}
+
+// Time parses a feed timestamp and records how far it is from the receive
+// time. A timestamp that cannot be parsed is replaced by the receive time,
+// shifted by the skew seen so far so it lines up with the feed's clock.
+func (c *FeedClock) Time(ts string) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	received := c.now().UTC()
+
+	t, err := ParseFeedTime(ts)
+	if err != nil {
+		c.failures++
+		// The first failure and every hundredth, so a broken feed does not flood the log
+		if c.log != nil && (c.failures == 1 || c.failures%100 == 0) {
+			c.log.Warnf("Using receive time for quote: %v (%d so far)", err, c.failures)
+		}
+		return received.Add(c.skew)
+	}
+
+	reading := t.Sub(received)
+	if c.samples == 0 {
+		c.skew = reading
+	} else {
+		c.skew += (reading - c.skew) / 10
+	}
+	c.samples++
+	if abs(reading) > abs(c.maxSkew) {
+		c.maxSkew = reading
+	}
+
+	switch over := abs(c.skew) > SkewWarning; {
+	case over && !c.skewed:
+		c.skewed = true
+		if c.log != nil {
+			c.log.Warnf("Market data clock is %v from local time", c.skew.Round(time.Millisecond))
+		}
+	case !over && c.skewed:
+		c.skewed = false
+		if c.log != nil {
+			c.log.Infof("Market data clock back within %v of local time", SkewWarning)
+		}
+	}
+	return t
+}
+
+// Skew returns the smoothed difference between feed and local time. Positive
+// means the feed is ahead. Network delay is included, so a slow link reads as
+// the feed running behind.
+func (c *FeedClock) Skew() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.skew
+}
+
+// Stats returns the clock's current readings
+func (c *FeedClock) Stats() FeedClockStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return FeedClockStats{
+		Skew:          c.skew,
+		MaxSkew:       c.maxSkew,
+		Samples:       c.samples,
+		ParseFailures: c.failures,
+	}
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,257 @@
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/schedule"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// NewAccount creates a disconnected account holding balance in cash. Contracts
+// the market creates after the account has synced are announced to it.
+func NewAccount(market *Market, userID, accountID int, balance float64) *Account {
+	tradingDay, _ := schedule.NewTradingDay("", "")
+	a := &Account{
+		market:     market,
+		out:        newDispatcher(market.Config().Latency),
+		userID:     userID,
+		accountID:  accountID,
+		balance:    balance,
+		tradingDay: tradingDay,
+		positions:  make(map[int]*position),
+	}
+	market.OnInstrument(a.announce)
+	return a
+}
+
+// SetMessageHandler sets the callback for user sync and props events
+func (a *Account) SetMessageHandler(handler func(eventType string, data json.RawMessage)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onEvent = handler
+}
+
+// Connect opens the account
+func (a *Account) Connect() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.connected = true
+	return nil
+}
+
+// IsConnected reports whether the account is open
+func (a *Account) IsConnected() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.connected
+}
+
+// Close drops undelivered events; the account keeps its state
+func (a *Account) Close() error {
+	a.mu.Lock()
+	a.connected = false
+	a.mu.Unlock()
+	a.out.close()
+	return nil
+}
+
+// Send answers user/syncrequest with the account's state. Other requests are
+// accepted and ignored.
+func (a *Account) Send(url string, body interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.connected {
+		return errors.New("mock account not connected")
+	}
+	if url == marketdata.EventUser {
+		a.synced = true
+		a.post(marketdata.EventUser, a.syncLocked(a.market.Instruments()))
+	}
+	return nil
+}
+
+// Fill records an execution of qty contracts of symbol at price, action "Buy"
+// or "Sell", and reports it with the position it leaves, the fill pairs it
+// closes and the new cash balance. It returns the order ID the fill was given.
+func (a *Account) Fill(symbol, action string, qty int, price float64, at time.Time) int {
+	inst := a.market.Instrument(symbol)
+	if at.IsZero() {
+		at = a.market.now()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nextOrder++
+	a.nextFill++
+	fill := tradovate.APIFill{
+		ID:         a.nextFill,
+		OrderID:    a.nextOrder,
+		ContractID: inst.ContractID,
+		Timestamp:  at.UTC().Format(timestampLayout),
+		Action:     action,
+		Qty:        qty,
+		Price:      price,
+	}
+	a.fills = append(a.fills, fill)
+	a.props(marketdata.EventFill, fill)
+
+	pos, ok := a.positions[inst.ContractID]
+	if !ok {
+		pos = &position{api: tradovate.APIPosition{
+			ID:         len(a.positions) + 1,
+			AccountID:  a.accountID,
+			ContractID: inst.ContractID,
+		}}
+		a.positions[inst.ContractID] = pos
+	}
+
+	signed := qty
+	if action == "Sell" {
+		signed = -qty
+		pos.api.Sold += qty
+		pos.api.SoldValue += float64(qty) * price
+	} else {
+		pos.api.Bought += qty
+		pos.api.BoughtValue += float64(qty) * price
+	}
+	pos.api.NetPos += signed
+
+	// Close open lots oldest first; what is left opens a new lot
+	var pairs []tradovate.APIFillPair
+	for len(pos.lots) > 0 && signed != 0 && (pos.lots[0].qty > 0) != (signed > 0) {
+		open := &pos.lots[0]
+		closed := min(abs(open.qty), abs(signed))
+		pair := tradovate.APIFillPair{PositionID: pos.api.ID, Qty: closed}
+		if open.qty > 0 {
+			pair.BuyFillID, pair.BuyPrice = open.fillID, open.price
+			pair.SellFillID, pair.SellPrice = fill.ID, price
+			open.qty -= closed
+			signed += closed
+		} else {
+			pair.SellFillID, pair.SellPrice = open.fillID, open.price
+			pair.BuyFillID, pair.BuyPrice = fill.ID, price
+			open.qty += closed
+			signed -= closed
+		}
+		a.nextPair++
+		pair.ID = a.nextPair
+		pairs = append(pairs, pair)
+		a.realized += (pair.SellPrice - pair.BuyPrice) * float64(closed) * inst.ValuePerPoint
+		if open.qty == 0 {
+			pos.lots = pos.lots[1:]
+		}
+	}
+	if signed != 0 {
+		pos.lots = append(pos.lots, lot{fillID: fill.ID, qty: signed, price: price})
+	}
+	pos.api.NetPrice = pos.netPrice()
+
+	a.props(marketdata.EventPosition, pos.api)
+	for _, pair := range pairs {
+		a.fillPairs = append(a.fillPairs, pair)
+		a.props(marketdata.EventFillPair, pair)
+	}
+	if len(pairs) > 0 {
+		a.props(marketdata.EventCashBalance, a.cashBalance())
+	}
+	return fill.OrderID
+}
+
+// RealizedPnL returns the PnL of every closed fill pair, in dollars
+func (a *Account) RealizedPnL() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.realized
+}
+
+// announce sends a sync with a contract created after the account synced, so
+// its name and product are known before its first quote or fill
+func (a *Account) announce(inst Instrument) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.synced {
+		a.post(marketdata.EventUser, a.syncLocked([]Instrument{inst}))
+	}
+}
+
+// syncLocked builds a user/syncrequest payload with instruments and the
+// account's positions, cash balance, fills and fill pairs. Caller must hold a.mu.
+func (a *Account) syncLocked(instruments []Instrument) map[string]interface{} {
+	contracts := make([]tradovate.APIContract, 0, len(instruments))
+	products := make([]tradovate.APIProduct, 0, len(instruments))
+	for _, inst := range instruments {
+		contracts = append(contracts, tradovate.APIContract{ID: inst.ContractID, Name: inst.Symbol})
+		products = append(products, tradovate.APIProduct{Name: inst.Product, ValuePerPoint: inst.ValuePerPoint, TickSize: inst.TickSize})
+	}
+	positions := make([]tradovate.APIPosition, 0, len(a.positions))
+	for _, pos := range a.positions {
+		positions = append(positions, pos.api)
+	}
+	return map[string]interface{}{
+		"users":        []map[string]int{{"id": a.userID}},
+		"accounts":     []map[string]interface{}{{"id": a.accountID, "name": "DEMO", "active": true}},
+		"contracts":    contracts,
+		"products":     products,
+		"positions":    positions,
+		"cashBalances": []tradovate.APICashBalance{a.cashBalance()},
+		"fills":        a.fills,
+		"fillPairs":    a.fillPairs,
+	}
+}
+
+// cashBalance is the account's balance for the current trade date. Caller must hold a.mu.
+func (a *Account) cashBalance() tradovate.APICashBalance {
+	date := a.tradingDay.TradeDate(a.market.now())
+	return tradovate.APICashBalance{
+		AccountID:   a.accountID,
+		TradeDate:   tradovate.APITradeDate{Year: date.Year(), Month: int(date.Month()), Day: date.Day()},
+		Amount:      a.balance + a.realized,
+		AmountSOD:   a.balance,
+		RealizedPnL: a.realized,
+	}
+}
+
+// props posts entity as a props event of entityType. Caller must hold a.mu.
+func (a *Account) props(entityType string, entity interface{}) {
+	a.post(marketdata.EventProps, map[string]interface{}{
+		"entityType": entityType,
+		"eventType":  "Updated",
+		"entity":     entity,
+	})
+}
+
+// post delivers payload to the message handler as eventType. Caller must hold
+// a.mu, so events are queued in the order the account changed.
+func (a *Account) post(eventType string, payload interface{}) {
+	data := mustJSON(payload)
+	handler := a.onEvent
+	a.out.post(func() {
+		if handler != nil {
+			handler(eventType, data)
+		}
+	})
+}
+
+// netPrice is the average price of the open lots, 0 when flat
+func (p *position) netPrice() float64 {
+	qty, value := 0, 0.0
+	for _, l := range p.lots {
+		qty += abs(l.qty)
+		value += float64(abs(l.qty)) * l.price
+	}
+	if qty == 0 {
+		return 0
+	}
+	return math.Round(value/float64(qty)*1e8) / 1e8
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,49 @@
+package mock
+
+import "time"
+
+// dispatchQueueSize bounds the deliveries waiting on a dispatcher; posting
+// blocks once it is full, as a slow reader would stall a socket
+const dispatchQueueSize = 4096
+
+// newDispatcher starts a dispatcher delivering latency after each post
+func newDispatcher(latency time.Duration) *dispatcher {
+	d := &dispatcher{
+		queue:   make(chan delivery, dispatchQueueSize),
+		latency: latency,
+		done:    make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// post queues fn. It is dropped once the dispatcher is closed.
+func (d *dispatcher) post(fn func()) {
+	select {
+	case d.queue <- delivery{at: time.Now().Add(d.latency), fn: fn}:
+	case <-d.done:
+	}
+}
+
+// close stops delivering; queued callbacks are dropped
+func (d *dispatcher) close() {
+	d.closeOnce.Do(func() { close(d.done) })
+}
+
+func (d *dispatcher) run() {
+	for {
+		select {
+		case <-d.done:
+			return
+		case next := <-d.queue:
+			if wait := time.Until(next.at); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-d.done:
+					return
+				}
+			}
+			next.fn()
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// NewFeed creates a disconnected feed on market
+func NewFeed(market *Market) *Feed {
+	return &Feed{
+		market:  market,
+		out:     newDispatcher(market.Config().Latency),
+		streams: make(map[string]chan struct{}),
+	}
+}
+
+// SetMessageHandler sets the callback for quote and chart events
+func (f *Feed) SetMessageHandler(handler func(eventType string, data json.RawMessage)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onEvent = handler
+}
+
+// SetResponseHandler sets the callback for responses to requests
+func (f *Feed) SetResponseHandler(handler func(requestID int, url string, data json.RawMessage)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onResponse = handler
+}
+
+// SetRequestErrorHandler sets the callback for refused requests
+func (f *Feed) SetRequestErrorHandler(handler func(requestID int, url string, status int, text string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onRequestError = handler
+}
+
+// Connect opens the feed
+func (f *Feed) Connect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = true
+	return nil
+}
+
+// IsConnected reports whether the feed is open
+func (f *Feed) IsConnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connected
+}
+
+// Close stops every quote stream and drops undelivered events
+func (f *Feed) Close() error {
+	f.mu.Lock()
+	f.connected = false
+	for symbol, stop := range f.streams {
+		close(stop)
+		delete(f.streams, symbol)
+	}
+	f.mu.Unlock()
+	f.out.close()
+	return nil
+}
+
+// Send handles a request without returning its ID
+func (f *Feed) Send(url string, body interface{}) error {
+	_, err := f.SendRequest(url, body)
+	return err
+}
+
+// SendRequest handles a request and returns its ID. The response and any
+// events it causes are delivered later, as they would be over the network.
+func (f *Feed) SendRequest(url string, body interface{}) (int, error) {
+	f.mu.Lock()
+	if !f.connected {
+		f.mu.Unlock()
+		return 0, errors.New("mock feed not connected")
+	}
+	f.nextRequest++
+	requestID := f.nextRequest
+	f.mu.Unlock()
+
+	switch url {
+	case "md/subscribequote":
+		symbols, err := f.requestSymbols(body)
+		if err != nil {
+			f.refuse(requestID, url, err.Error())
+			return requestID, nil
+		}
+		for _, symbol := range symbols {
+			f.startQuotes(symbol)
+		}
+	case "md/unsubscribequote":
+		if symbols, err := f.requestSymbols(body); err == nil {
+			for _, symbol := range symbols {
+				f.stopQuotes(symbol)
+			}
+		}
+	case "md/getchart":
+		f.getChart(requestID, body)
+		return requestID, nil
+	}
+	f.respond(requestID, url, json.RawMessage(`{}`))
+	return requestID, nil
+}
+
+// getChart answers md/getchart with chart IDs, then the requested bars and the
+// end of history on the historical ID
+func (f *Feed) getChart(requestID int, body interface{}) {
+	var params marketdata.HistoricalDataParams
+	if err := remarshal(body, &params); err != nil {
+		f.refuse(requestID, "md/getchart", err.Error())
+		return
+	}
+	symbol, _ := params.Symbol.(string)
+	if symbol == "" {
+		f.refuse(requestID, "md/getchart", fmt.Sprintf("Unknown symbol: %v", params.Symbol))
+		return
+	}
+
+	var end time.Time
+	if ts := params.TimeRange.ClosestTimestamp; ts != "" {
+		parsed, err := marketdata.ParseFeedTime(ts)
+		if err != nil {
+			f.refuse(requestID, "md/getchart", "Invalid closestTimestamp: "+ts)
+			return
+		}
+		end = parsed
+	}
+	count := params.TimeRange.AsMuchAsElements
+	if count <= 0 {
+		count = 100
+	}
+	bars := f.market.chartBars(symbol, params.ChartDescription, end, count)
+
+	f.mu.Lock()
+	historicalID, realtimeID := f.nextChart+1, f.nextChart+2
+	f.nextChart += 2
+	f.mu.Unlock()
+
+	f.respond(requestID, "md/getchart", mustJSON(marketdata.ChartSubscriptionResponse{
+		HistoricalID: historicalID,
+		RealtimeID:   realtimeID,
+	}))
+	type chart struct {
+		ID   int        `json:"id"`
+		TD   int        `json:"td"`
+		Bars []chartBar `json:"bars,omitempty"`
+		EOH  bool       `json:"eoh,omitempty"`
+	}
+	td := tradeDateNumber(f.market.now())
+	f.event(marketdata.EventChart, map[string][]chart{"charts": {{ID: historicalID, TD: td, Bars: bars}}})
+	f.event(marketdata.EventChart, map[string][]chart{"charts": {{ID: historicalID, EOH: true}}})
+}
+
+// startQuotes streams quotes for symbol every Config.Interval until stopQuotes
+func (f *Feed) startQuotes(symbol string) {
+	f.mu.Lock()
+	if _, running := f.streams[symbol]; running {
+		f.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	f.streams[symbol] = stop
+	f.mu.Unlock()
+
+	f.market.Instrument(symbol)
+	go func() {
+		ticker := time.NewTicker(f.market.Config().Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				quote := f.market.Next(symbol)
+				f.event(marketdata.EventMarketData, marketdata.QuoteData{Quotes: []marketdata.Quote{quote}})
+			}
+		}
+	}()
+}
+
+// stopQuotes ends the quote stream for symbol
+func (f *Feed) stopQuotes(symbol string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if stop, ok := f.streams[symbol]; ok {
+		close(stop)
+		delete(f.streams, symbol)
+	}
+}
+
+// respond delivers a response to the response handler
+func (f *Feed) respond(requestID int, url string, data json.RawMessage) {
+	f.out.post(func() {
+		f.mu.Lock()
+		handler := f.onResponse
+		f.mu.Unlock()
+		if handler != nil {
+			handler(requestID, url, data)
+		}
+	})
+}
+
+// refuse delivers a refused request to the request error handler
+func (f *Feed) refuse(requestID int, url, text string) {
+	f.out.post(func() {
+		f.mu.Lock()
+		handler := f.onRequestError
+		f.mu.Unlock()
+		if handler != nil {
+			handler(requestID, url, 404, text)
+		}
+	})
+}
+
+// event delivers payload to the message handler as eventType
+func (f *Feed) event(eventType string, payload interface{}) {
+	data := mustJSON(payload)
+	f.out.post(func() {
+		f.mu.Lock()
+		handler := f.onEvent
+		f.mu.Unlock()
+		if handler != nil {
+			handler(eventType, data)
+		}
+	})
+}
+
+// requestSymbol reads the symbol of a request, given by name or by the ID of
+// a contract the market has created
+func (f *Feed) requestSymbol(body interface{}) (string, error) {
+	var params struct {
+		Symbol interface{} `json:"symbol"`
+	}
+	if err := remarshal(body, &params); err != nil {
+		return "", err
+	}
+	return f.symbolName(params.Symbol)
+}
+
+// requestSymbols reads the symbols of a quote (un)subscription, which may be
+// one symbol or a list
+func (f *Feed) requestSymbols(body interface{}) ([]string, error) {
+	var params struct {
+		Symbol interface{} `json:"symbol"`
+	}
+	if err := remarshal(body, &params); err != nil {
+		return nil, err
+	}
+	list, ok := params.Symbol.([]interface{})
+	if !ok {
+		list = []interface{}{params.Symbol}
+	}
+	symbols := make([]string, 0, len(list))
+	for _, v := range list {
+		symbol, err := f.symbolName(v)
+		if err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, nil
+}
+
+// symbolName resolves a decoded symbol: a name, or a contract ID
+func (f *Feed) symbolName(v interface{}) (string, error) {
+	switch s := v.(type) {
+	case string:
+		if s != "" {
+			return s, nil
+		}
+	case float64:
+		if symbol, ok := f.market.Symbol(int(s)); ok {
+			return symbol, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown symbol: %v", v)
+}
+
+// remarshal decodes body into out through JSON, the way it would be sent
+func remarshal(body interface{}, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// mustJSON encodes a payload built from plain structs and maps
+func mustJSON(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("mock: %v", err))
+	}
+	return data
+}
+
+// tradeDateNumber is a date as md/getchart's td field writes it, e.g. 20260105
+func tradeDateNumber(t time.Time) int {
+	y, m, d := t.UTC().Date()
+	return y*10000 + int(m)*100 + d
+}
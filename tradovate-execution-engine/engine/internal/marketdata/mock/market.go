@@ -0,0 +1,335 @@
+package mock
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/contracts"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// timestampLayout is how Tradovate stamps quotes and bars
+const timestampLayout = "2006-01-02T15:04:05.000Z"
+
+// firstContractID is the ID of the first contract a Market creates
+const firstContractID = 3000001
+
+// products are the specs of common CME products; others trade like defaultProduct
+var products = map[string]productSpec{
+	"ES":  {tickSize: 0.25, valuePerPoint: 50, price: 5000},
+	"MES": {tickSize: 0.25, valuePerPoint: 5, price: 5000},
+	"NQ":  {tickSize: 0.25, valuePerPoint: 20, price: 18000},
+	"MNQ": {tickSize: 0.25, valuePerPoint: 2, price: 18000},
+	"YM":  {tickSize: 1, valuePerPoint: 5, price: 39000},
+	"MYM": {tickSize: 1, valuePerPoint: 0.5, price: 39000},
+	"RTY": {tickSize: 0.1, valuePerPoint: 50, price: 2000},
+	"M2K": {tickSize: 0.1, valuePerPoint: 5, price: 2000},
+	"CL":  {tickSize: 0.01, valuePerPoint: 1000, price: 75},
+	"MCL": {tickSize: 0.01, valuePerPoint: 100, price: 75},
+	"GC":  {tickSize: 0.1, valuePerPoint: 100, price: 2000},
+	"MGC": {tickSize: 0.1, valuePerPoint: 10, price: 2000},
+}
+
+var defaultProduct = productSpec{tickSize: 0.25, valuePerPoint: 5, price: 100}
+
+// DefaultConfig is a calm market quoting four times a second: about two ticks
+// of movement a minute, one tick wide
+func DefaultConfig() Config {
+	return Config{
+		Volatility:  0.5,
+		SpreadTicks: 1,
+		Interval:    250 * time.Millisecond,
+		Latency:     10 * time.Millisecond,
+	}
+}
+
+// NewMarket creates an empty market
+func NewMarket(cfg Config) *Market {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultConfig().Interval
+	}
+	if cfg.SpreadTicks < 1 {
+		cfg.SpreadTicks = 1
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Market{
+		cfg:         cfg,
+		rng:         rand.New(rand.NewSource(seed)),
+		now:         time.Now,
+		instruments: make(map[string]*instrument),
+		byID:        make(map[int]*instrument),
+		nextID:      firstContractID,
+	}
+}
+
+// SetClock replaces the time source stamped on quotes and bars (tests)
+func (m *Market) SetClock(now func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+// Config returns the market's settings
+func (m *Market) Config() Config {
+	return m.cfg
+}
+
+// Instrument returns the contract for symbol, creating it on first use
+func (m *Market) Instrument(symbol string) Instrument {
+	m.mu.Lock()
+	inst, created := m.instrument(symbol)
+	listeners := m.onNew
+	m.mu.Unlock()
+
+	if created {
+		for _, fn := range listeners {
+			fn(inst.Instrument)
+		}
+	}
+	return inst.Instrument
+}
+
+// Instruments returns every contract created so far, by symbol
+func (m *Market) Instruments() []Instrument {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Instrument, 0, len(m.instruments))
+	for _, inst := range m.instruments {
+		list = append(list, inst.Instrument)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Symbol < list[j].Symbol })
+	return list
+}
+
+// Symbol returns the symbol of a contract ID the market created
+func (m *Market) Symbol(contractID int) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inst, ok := m.byID[contractID]
+	if !ok {
+		return "", false
+	}
+	return inst.Symbol, true
+}
+
+// Price returns the last trade price of symbol
+func (m *Market) Price(symbol string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	inst, _ := m.instrument(symbol)
+	return inst.price
+}
+
+// OnInstrument registers a callback for each contract created from now on
+func (m *Market) OnInstrument(fn func(Instrument)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onNew = append(m.onNew, fn)
+}
+
+// instrument looks up symbol, creating it if needed. Caller must hold m.mu.
+func (m *Market) instrument(symbol string) (*instrument, bool) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if inst, ok := m.instruments[symbol]; ok {
+		return inst, false
+	}
+
+	root := contracts.Root(symbol)
+	spec, ok := products[root]
+	if !ok {
+		spec = defaultProduct
+	}
+	// Start within a percent of the product's usual price so runs differ
+	price := roundToTick(spec.price*(1+(m.rng.Float64()-0.5)/50), spec.tickSize)
+	inst := &instrument{
+		Instrument: Instrument{
+			Symbol:        symbol,
+			ContractID:    m.nextID,
+			Product:       root,
+			TickSize:      spec.tickSize,
+			ValuePerPoint: spec.valuePerPoint,
+		},
+		price:   price,
+		open:    price,
+		high:    price,
+		low:     price,
+		history: make(map[string][]chartBar),
+	}
+	m.nextID++
+	m.instruments[symbol] = inst
+	m.byID[inst.ContractID] = inst
+	return inst, true
+}
+
+// Next moves symbol's price one step and returns the quote for it: the best
+// bid and offer around the trade, the trade and the session's totals
+func (m *Market) Next(symbol string) marketdata.Quote {
+	m.mu.Lock()
+	inst, created := m.instrument(symbol)
+	tick := inst.TickSize
+
+	ticks := math.Round(m.rng.NormFloat64() * m.cfg.Volatility)
+	inst.price = math.Max(roundToTick(inst.price+ticks*tick, tick), tick)
+	inst.high = math.Max(inst.high, inst.price)
+	inst.low = math.Min(inst.low, inst.price)
+	size := float64(1 + m.rng.Intn(5))
+	inst.volume += size
+
+	bid := roundToTick(inst.price-float64(m.cfg.SpreadTicks/2)*tick, tick)
+	offer := roundToTick(bid+float64(m.cfg.SpreadTicks)*tick, tick)
+	quote := marketdata.Quote{
+		Timestamp:  m.now().UTC().Format(timestampLayout),
+		ContractID: inst.ContractID,
+		Entries: map[string]marketdata.Entry{
+			"Bid":              {Price: bid, Size: float64(5 + m.rng.Intn(50))},
+			"Offer":            {Price: offer, Size: float64(5 + m.rng.Intn(50))},
+			"Trade":            {Price: inst.price, Size: size},
+			"TotalTradeVolume": {Size: inst.volume},
+			"OpeningPrice":     {Price: inst.open},
+			"HighPrice":        {Price: inst.high},
+			"LowPrice":         {Price: inst.low},
+		},
+	}
+	listeners := m.onNew
+	m.mu.Unlock()
+
+	if created {
+		for _, fn := range listeners {
+			fn(inst.Instrument)
+		}
+	}
+	return quote
+}
+
+// History returns up to count bars of symbol described by desc, oldest first,
+// the newest starting at or before end (zero = now). The newest bar closes at
+// the current price, and the same bars come back for overlapping requests so
+// pages stitch together. Tick and volume charts are served as 1 minute bars.
+func (m *Market) History(symbol string, desc marketdata.ChartDesc, end time.Time, count int) []marketdata.Bar {
+	bars := m.chartBars(symbol, desc, end, count)
+	out := make([]marketdata.Bar, len(bars))
+	for i, b := range bars {
+		out[i] = marketdata.Bar{Timestamp: b.Timestamp, Open: b.Open, High: b.High, Low: b.Low, Close: b.Close}
+	}
+	return out
+}
+
+// chartBars is History in the shape md/getchart sends
+func (m *Market) chartBars(symbol string, desc marketdata.ChartDesc, end time.Time, count int) []chartBar {
+	if count <= 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inst, _ := m.instrument(symbol)
+	interval := barInterval(desc)
+	now := m.now().UTC()
+	if end.IsZero() || end.After(now) {
+		end = now
+	}
+	end = end.UTC().Truncate(interval)
+
+	key := fmt.Sprintf("%s/%d/%s", desc.UnderlyingType, desc.ElementSize, desc.ElementSizeUnit)
+	sigma := m.barSigma(interval)
+	bars := inst.history[key]
+
+	// Bring the bars up to now, the newest closing at the current price
+	current := now.Truncate(interval)
+	if len(bars) == 0 {
+		bars = []chartBar{m.bar(current, inst.price, inst.TickSize, sigma)}
+	}
+	last, _ := time.Parse(timestampLayout, bars[len(bars)-1].Timestamp)
+	for at := last.Add(interval); !at.After(current); at = at.Add(interval) {
+		close := inst.price
+		if at.Before(current) {
+			prev := bars[len(bars)-1].Close
+			close = math.Max(roundToTick(prev+math.Round(m.rng.NormFloat64()*sigma)*inst.TickSize, inst.TickSize), inst.TickSize)
+		}
+		bars = append(bars, m.bar(at, close, inst.TickSize, sigma))
+	}
+	newest := &bars[len(bars)-1]
+	newest.Close = inst.price
+	newest.High = math.Max(newest.High, inst.price)
+	newest.Low = math.Min(newest.Low, inst.price)
+
+	// Walk back from the oldest bar until the request is covered
+	first, _ := time.Parse(timestampLayout, bars[0].Timestamp)
+	want := end.Add(-time.Duration(count-1) * interval)
+	var older []chartBar
+	for at := first.Add(-interval); !at.Before(want); at = at.Add(-interval) {
+		next := bars[0]
+		if len(older) > 0 {
+			next = older[len(older)-1]
+		}
+		older = append(older, m.bar(at, next.Open, inst.TickSize, sigma))
+	}
+	for i, j := 0, len(older)-1; i < j; i, j = i+1, j-1 {
+		older[i], older[j] = older[j], older[i]
+	}
+	bars = append(older, bars...)
+	inst.history[key] = bars
+
+	var page []chartBar
+	for _, b := range bars {
+		if at, _ := time.Parse(timestampLayout, b.Timestamp); !at.After(end) {
+			page = append(page, b)
+		}
+	}
+	if len(page) > count {
+		page = page[len(page)-count:]
+	}
+	return page
+}
+
+// barSigma is the standard deviation, in ticks, of a bar's move: the moves
+// of the quotes that would have made it
+func (m *Market) barSigma(interval time.Duration) float64 {
+	steps := math.Max(float64(interval)/float64(m.cfg.Interval), 1)
+	return math.Max(m.cfg.Volatility*math.Sqrt(steps), 1)
+}
+
+// bar generates a bar starting at start that closes at close. Caller must hold m.mu.
+func (m *Market) bar(start time.Time, close, tick, sigma float64) chartBar {
+	open := math.Max(roundToTick(close-math.Round(m.rng.NormFloat64()*sigma)*tick, tick), tick)
+	wick := func() float64 { return math.Round(math.Abs(m.rng.NormFloat64())*sigma/2) * tick }
+
+	up, down := float64(m.rng.Intn(200)), float64(m.rng.Intn(200))
+	return chartBar{
+		Timestamp:   start.Format(timestampLayout),
+		Open:        open,
+		High:        roundToTick(math.Max(open, close)+wick(), tick),
+		Low:         math.Max(roundToTick(math.Min(open, close)-wick(), tick), tick),
+		Close:       close,
+		UpVolume:    up,
+		DownVolume:  down,
+		UpTicks:     math.Ceil(up / 3),
+		DownTicks:   math.Ceil(down / 3),
+		BidVolume:   down,
+		OfferVolume: up,
+	}
+}
+
+// barInterval is the time each bar of desc covers
+func barInterval(desc marketdata.ChartDesc) time.Duration {
+	size := time.Duration(max(desc.ElementSize, 1))
+	switch desc.UnderlyingType {
+	case "MinuteBar":
+		return size * time.Minute
+	case "DailyBar":
+		return size * 24 * time.Hour
+	}
+	return time.Minute
+}
+
+// roundToTick rounds price to the nearest multiple of tick
+func roundToTick(price, tick float64) float64 {
+	return math.Round(math.Round(price/tick)*tick*1e8) / 1e8
+}
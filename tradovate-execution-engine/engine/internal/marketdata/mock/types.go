@@ -0,0 +1,158 @@
+package mock
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sync"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/schedule"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+//
+// MARKET
+//
+
+// Config shapes the synthetic market
+type Config struct {
+	Volatility  float64       // Standard deviation of each quote's price move, in ticks
+	SpreadTicks int           // Offer minus bid, in ticks
+	Interval    time.Duration // Time between quotes on each subscribed symbol
+	Latency     time.Duration // Delay before each response or event is delivered
+	Seed        int64         // Random source; 0 seeds from the clock
+}
+
+// Instrument is one synthetic contract
+type Instrument struct {
+	Symbol        string
+	ContractID    int
+	Product       string // Product root, e.g. MES
+	TickSize      float64
+	ValuePerPoint float64
+}
+
+// productSpec is what a product trades like: its tick, value per point and a
+// starting price in the right neighbourhood
+type productSpec struct {
+	tickSize      float64
+	valuePerPoint float64
+	price         float64
+}
+
+// instrument is an Instrument with its random walk and generated history
+type instrument struct {
+	Instrument
+	price     float64 // Last trade
+	open      float64 // First trade of the session
+	high, low float64
+	volume    float64               // Contracts traded since the session opened
+	history   map[string][]chartBar // Generated bars per chart description, oldest first
+}
+
+// Market is a set of synthetic contracts whose prices follow a random walk.
+// Contracts are created the first time they are asked for, so any symbol
+// trades; known product roots get their real tick size and value per point.
+type Market struct {
+	mu          sync.Mutex
+	cfg         Config
+	rng         *rand.Rand
+	now         func() time.Time
+	instruments map[string]*instrument
+	byID        map[int]*instrument
+	nextID      int
+	onNew       []func(Instrument)
+}
+
+// chartBar is a bar as md/getchart sends it
+type chartBar struct {
+	Timestamp   string  `json:"timestamp"`
+	Open        float64 `json:"open"`
+	High        float64 `json:"high"`
+	Low         float64 `json:"low"`
+	Close       float64 `json:"close"`
+	UpVolume    float64 `json:"upVolume"`
+	DownVolume  float64 `json:"downVolume"`
+	UpTicks     float64 `json:"upTicks"`
+	DownTicks   float64 `json:"downTicks"`
+	BidVolume   float64 `json:"bidVolume"`
+	OfferVolume float64 `json:"offerVolume"`
+}
+
+//
+// FEED
+//
+
+// Feed is an offline market data connection backed by a Market. It implements
+// marketdata.WebSocketSender and RequestSender, so a DataSubscriber can be
+// built on it in place of a Tradovate client: md/subscribequote streams
+// quotes, md/getchart answers with historical bars and an end of history, and
+// responses and events reach the handlers set on it after Config.Latency.
+type Feed struct {
+	mu             sync.Mutex
+	market         *Market
+	out            *dispatcher
+	onEvent        func(eventType string, data json.RawMessage)
+	onResponse     func(requestID int, url string, data json.RawMessage)
+	onRequestError func(requestID int, url string, status int, text string)
+	connected      bool
+	nextRequest    int
+	nextChart      int
+	streams        map[string]chan struct{} // Symbol -> closed to stop its quotes
+}
+
+//
+// ACCOUNT
+//
+
+// Account is an offline trading connection: it answers user/syncrequest with
+// the Market's contracts and its own positions and cash balance, and reports
+// each Fill as the fill, position, fill pair and cash balance props events
+// Tradovate would send. It implements marketdata.WebSocketSender.
+type Account struct {
+	mu         sync.Mutex
+	market     *Market
+	out        *dispatcher
+	onEvent    func(eventType string, data json.RawMessage)
+	connected  bool
+	synced     bool // user/syncrequest received; later contracts are announced
+	userID     int
+	accountID  int
+	balance    float64 // Cash at the start of the session
+	realized   float64
+	tradingDay *schedule.TradingDay
+	nextFill   int
+	nextPair   int
+	nextOrder  int
+	positions  map[int]*position // Contract ID -> position
+	fills      []tradovate.APIFill
+	fillPairs  []tradovate.APIFillPair
+}
+
+// position is an account's position in one contract with its open fills
+type position struct {
+	api  tradovate.APIPosition
+	lots []lot // Open fills, oldest first, all on the side of NetPos
+}
+
+// lot is the still open part of a fill
+type lot struct {
+	fillID int
+	qty    int
+	price  float64
+}
+
+// dispatcher delivers callbacks in order on its own goroutine, each no sooner
+// than latency after it was posted
+type dispatcher struct {
+	queue     chan delivery
+	latency   time.Duration
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// delivery is one queued callback
+type delivery struct {
+	at time.Time
+	fn func()
+}
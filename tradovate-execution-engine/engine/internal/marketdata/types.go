@@ -1,6 +1,12 @@
 package marketdata
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/logger"
+)
 
 //
 // MARKETDATA
@@ -13,22 +19,74 @@ type WebSocketSender interface {
 	Connect() error
 }
 
+// RequestSender is implemented by senders that expose the request ID of each
+// message, so responses can be correlated back to the request that caused them
+type RequestSender interface {
+	SendRequest(url string, body interface{}) (int, error)
+}
+
+// ChartSubscriptionResponse is the reply to md/getchart
+type ChartSubscriptionResponse struct {
+	HistoricalID int `json:"historicalId"`
+	RealtimeID   int `json:"realtimeId"`
+
+	// Set instead of the IDs when the request was refused, e.g. for an unknown symbol
+	ErrorText string `json:"errorText,omitempty"`
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
 // Market data structures
 type Quote struct {
 	Timestamp  string           `json:"timestamp"`
 	ContractID int              `json:"contractId"`
 	Entries    map[string]Entry `json:"entries"`
+
+	// Time is Timestamp as parsed by the subscriber's FeedClock, or the receive
+	// time if it could not be parsed. Zero when the quote did not come through one.
+	Time time.Time `json:"-"`
 }
 
+// Entry is one named value of a quote. Either field is 0 when Tradovate left
+// it out, e.g. the size of SettlementPrice or the price of TotalTradeVolume.
 type Entry struct {
 	Price float64 `json:"price,omitempty"`
 	Size  float64 `json:"size,omitempty"`
 }
 
+// Quote entry names as Tradovate sends them
+const (
+	EntryBid              = "Bid"
+	EntryOffer            = "Offer"
+	EntryImpliedBid       = "ImpliedBid"   // Bid implied by spread orders
+	EntryImpliedOffer     = "ImpliedOffer" // Offer implied by spread orders
+	EntryTrade            = "Trade"
+	EntrySettlement       = "SettlementPrice"
+	EntryTotalTradeVolume = "TotalTradeVolume"
+)
+
 type QuoteData struct {
 	Quotes []Quote `json:"quotes"`
 }
 
+// DOM is a depth-of-market snapshot for one contract. Bids are ordered best
+// (highest) first and offers best (lowest) first.
+type DOM struct {
+	ContractID int        `json:"contractId"`
+	Timestamp  string     `json:"timestamp"`
+	Bids       []DOMLevel `json:"bids"`
+	Offers     []DOMLevel `json:"offers"`
+}
+
+// DOMLevel is one price level of the book
+type DOMLevel struct {
+	Price float64 `json:"price"`
+	Size  float64 `json:"size"`
+}
+
+type DOMData struct {
+	Doms []DOM `json:"doms"`
+}
+
 // Chart/Tick data structures
 type ChartUpdate struct {
 	Charts []Chart `json:"charts"`
@@ -43,6 +101,9 @@ type Chart struct {
 
 type Bar struct {
 	Timestamp string  `json:"timestamp"`
+	Open      float64 `json:"open,omitempty"`
+	High      float64 `json:"high,omitempty"`
+	Low       float64 `json:"low,omitempty"`
 	Close     float64 `json:"close"`
 }
 
@@ -64,24 +125,213 @@ type TimeRange struct {
 	AsMuchAsElements int    `json:"asMuchAsElements,omitempty"`
 }
 
+//
+// BAR BUILDER
+//
+
+// BarType is what closes a bar: elapsed time, traded volume or trade count
+type BarType string
+
+const (
+	BarTypeMinute BarType = "minute"
+	BarTypeVolume BarType = "volume"
+	BarTypeTick   BarType = "tick"
+)
+
+// BarSpec defines the bars a strategy trades on, e.g. 1 minute or 1000 volume
+type BarSpec struct {
+	Type BarType
+	Size int // Minutes, contracts or trades per bar
+}
+
+// BarBuilder aggregates trade prices from quotes into fixed interval bars, or
+// into bars of a fixed volume or trade count
+type BarBuilder struct {
+	mu        sync.Mutex
+	spec      BarSpec
+	interval  time.Duration
+	onBar     func(Bar)
+	current   Bar
+	start     time.Time // Start of the bar being built (zero until the first trade)
+	lastTrade time.Time // Latest trade time seen in the current bar
+	partial   bool      // Current bar began mid-interval and missed earlier trades
+	grace     time.Duration
+	held      *heldBar // Previous bar, still taking late trades until the grace window passes
+
+	// Volume and tick bars
+	count       float64 // Contracts or trades in the current bar
+	totalVolume float64 // Last TotalTradeVolume seen, to size the trades in each quote
+}
+
+// heldBar is a closed time bar waiting out the grace window, so trades stamped
+// before its end that arrive after the next bar opened still count
+type heldBar struct {
+	bar       Bar
+	start     time.Time
+	lastTrade time.Time
+	emit      bool
+}
+
+//
+// FEED CLOCK
+//
+
+// FeedClock parses the timestamps on market data and measures how far the
+// feed's clock is from the local one
+type FeedClock struct {
+	mu       sync.Mutex
+	log      *logger.Logger
+	now      func() time.Time
+	skew     time.Duration // Smoothed feed time minus receive time
+	maxSkew  time.Duration // Largest single reading, either way
+	samples  int
+	failures int  // Timestamps that could not be parsed
+	skewed   bool // Skew is beyond SkewWarning and has been logged
+}
+
+// FeedClockStats is a snapshot of a FeedClock
+type FeedClockStats struct {
+	Skew          time.Duration
+	MaxSkew       time.Duration
+	Samples       int
+	ParseFailures int
+}
+
 // Event types
 const (
-	EventMarketData  = "md"
-	EventChart       = "chart"
-	EventUser        = "user/syncrequest"
-	EventOrder       = "order"
-	EventPosition    = "position"
-	EventCashBalance = "cashBalance"
-	EventProps       = "props"
+	EventMarketData      = "md"
+	EventChart           = "chart"
+	EventUser            = "user/syncrequest"
+	EventOrder           = "order"
+	EventFill            = "fill"
+	EventExecutionReport = "executionReport"
+	EventFillPair        = "fillPair"
+	EventPosition        = "position"
+	EventCashBalance     = "cashBalance"
+	EventMargin          = "marginSnapshot"
+	EventProps           = "props"
 )
 
-// Helper function to parse quote data from raw JSON
+// ParseQuoteData parses the quotes of an "md" event. An entry that is not a
+// price and size object, such as a null or a quoted number, is dropped rather
+// than failing the quote, and fields an entry leaves out are 0.
 func ParseQuoteData(data json.RawMessage) (*QuoteData, error) {
-	var quoteData QuoteData
-	if err := json.Unmarshal(data, &quoteData); err != nil {
+	var raw struct {
+		Quotes []struct {
+			Timestamp  string                     `json:"timestamp"`
+			ContractID int                        `json:"contractId"`
+			Entries    map[string]json.RawMessage `json:"entries"`
+		} `json:"quotes"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
 		return nil, err
 	}
-	return &quoteData, nil
+
+	quoteData := &QuoteData{Quotes: make([]Quote, 0, len(raw.Quotes))}
+	for _, rq := range raw.Quotes {
+		quote := Quote{Timestamp: rq.Timestamp, ContractID: rq.ContractID, Entries: make(map[string]Entry, len(rq.Entries))}
+		for name, value := range rq.Entries {
+			var entry *Entry
+			if err := json.Unmarshal(value, &entry); err != nil || entry == nil {
+				continue
+			}
+			quote.Entries[name] = *entry
+		}
+		quoteData.Quotes = append(quoteData.Quotes, quote)
+	}
+	return quoteData, nil
+}
+
+// entry returns the named entry if the quote carries a price for it
+func (q Quote) entry(name string) (Entry, bool) {
+	e, ok := q.Entries[name]
+	return e, ok && e.Price != 0
+}
+
+// BestBid returns the higher of the bid and the implied bid, with their sizes
+// added when both are at the same price
+func (q Quote) BestBid() (Entry, bool) {
+	return q.best(EntryBid, EntryImpliedBid, func(a, b float64) bool { return a > b })
+}
+
+// BestAsk returns the lower of the offer and the implied offer, with their
+// sizes added when both are at the same price
+func (q Quote) BestAsk() (Entry, bool) {
+	return q.best(EntryOffer, EntryImpliedOffer, func(a, b float64) bool { return a < b })
+}
+
+// best picks between an outright and an implied side of the book
+func (q Quote) best(outright, implied string, better func(a, b float64) bool) (Entry, bool) {
+	o, hasOutright := q.entry(outright)
+	i, hasImplied := q.entry(implied)
+	switch {
+	case !hasImplied:
+		return o, hasOutright
+	case !hasOutright || better(i.Price, o.Price):
+		return i, true
+	case i.Price == o.Price:
+		return Entry{Price: o.Price, Size: o.Size + i.Size}, true
+	default:
+		return o, true
+	}
+}
+
+// BestBidOffer returns the best bid and ask, ok only when the quote has both
+func (q Quote) BestBidOffer() (bid, ask Entry, ok bool) {
+	bid, hasBid := q.BestBid()
+	ask, hasAsk := q.BestAsk()
+	return bid, ask, hasBid && hasAsk
+}
+
+// Spread returns the best ask less the best bid, ok only when the quote has both
+func (q Quote) Spread() (float64, bool) {
+	bid, ask, ok := q.BestBidOffer()
+	if !ok {
+		return 0, false
+	}
+	return ask.Price - bid.Price, true
+}
+
+// LastTrade returns the price and size of the latest trade
+func (q Quote) LastTrade() (Entry, bool) {
+	return q.entry(EntryTrade)
+}
+
+// Settlement returns the settlement price, which is all a quote may carry
+// before the open
+func (q Quote) Settlement() (Entry, bool) {
+	return q.entry(EntrySettlement)
+}
+
+// TotalVolume returns the contracts traded this session, from TotalTradeVolume
+func (q Quote) TotalVolume() (float64, bool) {
+	e, ok := q.Entries[EntryTotalTradeVolume]
+	return e.Size, ok && e.Size > 0
+}
+
+// Helper function to parse depth-of-market data from raw JSON
+func ParseDOMData(data json.RawMessage) (*DOMData, error) {
+	var domData DOMData
+	if err := json.Unmarshal(data, &domData); err != nil {
+		return nil, err
+	}
+	return &domData, nil
+}
+
+// BestBid returns the top bid level, if the book has any bids
+func (d DOM) BestBid() (DOMLevel, bool) {
+	if len(d.Bids) == 0 {
+		return DOMLevel{}, false
+	}
+	return d.Bids[0], true
+}
+
+// BestOffer returns the top offer level, if the book has any offers
+func (d DOM) BestOffer() (DOMLevel, bool) {
+	if len(d.Offers) == 0 {
+		return DOMLevel{}, false
+	}
+	return d.Offers[0], true
 }
 
 // Helper function to parse chart data from raw JSON
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the registry the engine's packages record into
+var Default = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter returns the counter for name and labels (key, value pairs), creating
+// it on first use. Callers on hot paths may keep the result.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.family(name, help, "counter")
+	key := renderLabels(labels)
+	s, ok := f.series[key]
+	if !ok || s.counter == nil {
+		s = &series{labels: key, counter: &Counter{}}
+		f.series[key] = s
+	}
+	return s.counter
+}
+
+// GaugeFunc registers a gauge whose value is read from fn each time the
+// registry is rendered, replacing any earlier gauge with the same labels
+func (r *Registry) GaugeFunc(name, help string, fn func() float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.family(name, help, "gauge")
+	key := renderLabels(labels)
+	f.series[key] = &series{labels: key, gauge: fn}
+}
+
+// Unregister drops every series named name
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.families, name)
+}
+
+// family returns the family for name, creating it if needed. Callers must hold r.mu.
+func (r *Registry) family(name, help, kind string) *family {
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{name: name, help: help, kind: kind, series: make(map[string]*series)}
+		r.families[name] = f
+	}
+	return f
+}
+
+// WriteText writes every metric in the Prometheus text format, sorted by name
+// and then labels so scrapes are stable
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := make([]family, 0, len(r.families))
+	for _, f := range r.families {
+		copied := *f
+		copied.series = make(map[string]*series, len(f.series))
+		for k, s := range f.series {
+			copied.series[k] = s
+		}
+		families = append(families, copied)
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	var sb strings.Builder
+	for _, f := range families {
+		if len(f.series) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&sb, "# TYPE %s %s\n", f.name, f.kind)
+
+		keys := make([]string, 0, len(f.series))
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		// Gauges are read outside the registry lock since they may take other locks
+		for _, k := range keys {
+			s := f.series[k]
+			var value float64
+			if s.counter != nil {
+				value = float64(s.counter.Value())
+			} else {
+				value = s.gauge()
+			}
+			fmt.Fprintf(&sb, "%s%s %s\n", f.name, s.labels, formatValue(value))
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Handler serves the registry in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Value returns the current count
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+// renderLabels formats key, value pairs as `{key="value",...}`, sorted by key.
+// A trailing key without a value is ignored.
+func renderLabels(labels []string) string {
+	if len(labels) < 2 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+"="+strconv.Quote(labels[i+1]))
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatValue renders a sample value, using the exposition format's spellings
+// for infinities and NaN
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Serve starts an HTTP listener on addr serving r on /metrics. It returns once
+// the address is bound, so a port already in use is reported here.
+func Serve(addr string, r *Registry) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics listener: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.Handler())
+
+	s := &Server{
+		srv:  &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+		addr: ln.Addr().String(),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		s.serveErr = s.srv.Serve(ln)
+	}()
+	return s, nil
+}
+
+// Addr returns the bound address, e.g. "127.0.0.1:9400"
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+// Close stops accepting scrapes, waits up to timeout for ones in progress and
+// returns once the listener goroutine has exited
+func (s *Server) Close(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := s.srv.Shutdown(ctx)
+	<-s.done
+	if err == nil && !errors.Is(s.serveErr, http.ErrServerClosed) {
+		// The listener broke before Close was called
+		return s.serveErr
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+//
+// REGISTRY
+//
+
+// Registry holds named counters and gauges and renders them in the Prometheus
+// text exposition format
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// family is every series sharing one metric name
+type family struct {
+	name   string
+	help   string
+	kind   string             // "counter" or "gauge"
+	series map[string]*series // Keyed by rendered labels, e.g. `{connection="md"}`
+}
+
+// series is one labelled value: a counter, or a gauge read when rendered
+type series struct {
+	labels  string
+	counter *Counter
+	gauge   func() float64
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	value atomic.Uint64
+}
+
+//
+// SERVER
+//
+
+// Server serves a registry on /metrics until Close
+type Server struct {
+	srv  *http.Server
+	addr string
+	done chan struct{}
+
+	serveErr error // Set when the listener goroutine exits
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -8,12 +11,16 @@ import (
 type OrderStatus string
 
 const (
-	StatusPending   OrderStatus = "PENDING"
-	StatusSubmitted OrderStatus = "SUBMITTED"
-	StatusFilled    OrderStatus = "FILLED"
-	StatusRejected  OrderStatus = "REJECTED"
-	StatusCanceled  OrderStatus = "CANCELED"
-	StatusFailed    OrderStatus = "FAILED"
+	StatusPending         OrderStatus = "PENDING"
+	StatusQueued          OrderStatus = "QUEUED" // Strategy order held until Tradovate can be reached again
+	StatusSubmitted       OrderStatus = "SUBMITTED"
+	StatusSuspended       OrderStatus = "SUSPENDED" // Bracket leg Tradovate holds until its parent fills
+	StatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	StatusFilled          OrderStatus = "FILLED"
+	StatusRejected        OrderStatus = "REJECTED"
+	StatusCanceled        OrderStatus = "CANCELED"
+	StatusExpired         OrderStatus = "EXPIRED" // Its time in force ran out unfilled (GTD or the end of the session)
+	StatusFailed          OrderStatus = "FAILED"
 )
 
 // OrderSide represents buy or sell
@@ -28,9 +35,25 @@ const (
 type OrderType string
 
 const (
-	TypeMarket OrderType = "Market"
-	TypeLimit  OrderType = "Limit"
-	TypeStop   OrderType = "Stop"
+	TypeMarket    OrderType = "Market"
+	TypeLimit     OrderType = "Limit"
+	TypeStop      OrderType = "Stop"
+	TypeStopLimit OrderType = "StopLimit"
+)
+
+// TimeInForce controls how long an order may work before it is canceled
+type TimeInForce string
+
+const (
+	TIFDay TimeInForce = "Day" // Canceled at the end of the session (Tradovate's default)
+	TIFGTC TimeInForce = "GTC" // Works until filled or canceled
+	TIFIOC TimeInForce = "IOC" // Fills what it can immediately, the rest is canceled
+)
+
+// Order origins besides strategy instance IDs
+const (
+	OriginManual   = "manual"   // Placed from the UI or without a running strategy on the symbol
+	OriginExternal = "external" // Placed outside this engine, seen only in exchange events
 )
 
 // Order represents a trading order
@@ -38,11 +61,140 @@ type Order struct {
 	ID           string      // Unique order identifier
 	Symbol       string      // Trading symbol
 	Side         OrderSide   // Buy or Sell
-	Type         OrderType   // Market, Limit, Stop
+	Type         OrderType   // Market, Limit, Stop, StopLimit
 	Quantity     int         // Number of contracts
-	Price        float64     // Order price (0 for market orders)
+	Price        float64     // Limit price (limit and stop-limit orders only)
+	StopPrice    float64     // Stop trigger price (stop and stop-limit orders only)
+	TimeInForce  TimeInForce // Empty means Day
 	Status       OrderStatus // Current order status
 	SubmittedAt  time.Time   // When order was submitted
+	SignalAt     time.Time   // When the strategy decided to trade (SubmittedAt if it did not say)
+	SentAt       time.Time   // Just before the placeorder request
+	AckedAt      time.Time   // When the placeorder response arrived
+	FilledAt     time.Time   // When the order was seen to be fully filled, by our clock
 	RejectReason string      // Reason for rejection if applicable
 	ExternalID   string      // External order ID from broker
+	Fills        []Fill      // Executions against this order, in arrival order
+	Origin       string      // Who placed it: a strategy instance ID, OriginManual or OriginExternal
+}
+
+// Fill is a single execution against an order
+type Fill struct {
+	ID        string    // Exchange fill ID
+	Quantity  int       // Contracts filled
+	Price     float64   // Execution price
+	Timestamp time.Time // When the fill happened
+
+	SlippageModel string  // Simulated fills: the slippage model the price came from
+	Slippage      float64 // Simulated fills: points the price was moved against the order
+}
+
+// IsExternal reports whether the order was placed outside this engine
+func (o *Order) IsExternal() bool {
+	return o.Origin == OriginExternal
+}
+
+// IsStrategy reports whether a strategy instance placed the order
+func (o *Order) IsStrategy() bool {
+	return o.Origin != "" && o.Origin != OriginManual && o.Origin != OriginExternal
+}
+
+// FilledQty returns the cumulative filled quantity
+func (o *Order) FilledQty() int {
+	qty := 0
+	for _, f := range o.Fills {
+		qty += f.Quantity
+	}
+	return qty
+}
+
+// AvgFillPrice returns the volume-weighted average fill price, or 0 with no fills
+func (o *Order) AvgFillPrice() float64 {
+	qty := 0
+	notional := 0.0
+	for _, f := range o.Fills {
+		qty += f.Quantity
+		notional += float64(f.Quantity) * f.Price
+	}
+	if qty == 0 {
+		return 0
+	}
+	return notional / float64(qty)
+}
+
+// Describe names the order's type, and its time in force unless that is Day
+func (o *Order) Describe() string {
+	desc := strings.ToLower(string(o.Type))
+	if o.Type == TypeStopLimit {
+		desc = "stop-limit"
+	}
+	if o.TimeInForce != "" && o.TimeInForce != TIFDay {
+		desc += " " + string(o.TimeInForce)
+	}
+	return desc
+}
+
+// ParseTimeInForce accepts day, gtc or ioc in any case
+func ParseTimeInForce(s string) (TimeInForce, error) {
+	for _, tif := range []TimeInForce{TIFDay, TIFGTC, TIFIOC} {
+		if strings.EqualFold(s, string(tif)) {
+			return tif, nil
+		}
+	}
+	return "", fmt.Errorf("unknown time in force %q (use day, gtc or ioc)", s)
+}
+
+// Validate checks the order's prices against its type and time in force. When
+// marketPrice is known (non-zero) stop and stop-limit prices must be on the side
+// of it that makes them trigger later: above for buys, below for sells.
+func (o *Order) Validate(marketPrice float64) error {
+	if o.Quantity <= 0 {
+		return fmt.Errorf("quantity must be positive (got %d)", o.Quantity)
+	}
+	switch o.TimeInForce {
+	case "", TIFDay, TIFGTC, TIFIOC:
+	default:
+		return fmt.Errorf("unsupported time in force %q", o.TimeInForce)
+	}
+
+	switch o.Type {
+	case TypeMarket:
+		if o.TimeInForce == TIFIOC {
+			return errors.New("IOC is redundant for a market order, which fills immediately")
+		}
+		return nil
+	case TypeLimit:
+		if o.Price <= 0 {
+			return errors.New("limit order requires a limit price")
+		}
+		return nil
+	case TypeStop:
+		if o.StopPrice <= 0 {
+			return errors.New("stop order requires a stop price")
+		}
+		return o.checkTriggerSide("stop", o.StopPrice, marketPrice)
+	case TypeStopLimit:
+		if o.StopPrice <= 0 || o.Price <= 0 {
+			return errors.New("stop-limit order requires both a stop price and a limit price")
+		}
+		if err := o.checkTriggerSide("stop", o.StopPrice, marketPrice); err != nil {
+			return err
+		}
+		return o.checkTriggerSide("limit", o.Price, marketPrice)
+	}
+	return fmt.Errorf("unsupported order type %q", o.Type)
+}
+
+// checkTriggerSide rejects a resting price that is already through the market
+func (o *Order) checkTriggerSide(name string, price, marketPrice float64) error {
+	if marketPrice <= 0 {
+		return nil
+	}
+	if o.Side == SideBuy && price <= marketPrice {
+		return fmt.Errorf("buy %s price %.2f must be above the market (%.2f)", name, price, marketPrice)
+	}
+	if o.Side == SideSell && price >= marketPrice {
+		return fmt.Errorf("sell %s price %.2f must be below the market (%.2f)", name, price, marketPrice)
+	}
+	return nil
 }
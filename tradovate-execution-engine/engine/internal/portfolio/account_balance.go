@@ -0,0 +1,94 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"time"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// SetBalanceWarning sets the cash balance below which a warning is logged, 0 to
+// turn the warning off
+func (pt *PortfolioTracker) SetBalanceWarning(threshold float64) {
+	pt.mu.Lock()
+	pt.minBalance = threshold
+	pt.lowBalance = false
+	pt.mu.Unlock()
+	pt.checkBalance()
+}
+
+// GetAccountBalance returns the account's latest cash balance and margin
+func (pt *PortfolioTracker) GetAccountBalance() AccountBalance {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.balance
+}
+
+// GetAvailableMargin returns the cash balance plus open PnL less the initial
+// margin of open positions. ok is false until both a cash balance and a
+// margin snapshot have arrived.
+func (pt *PortfolioTracker) GetAvailableMargin() (available float64, ok bool) {
+	b := pt.GetAccountBalance()
+	if b.UpdatedAt.IsZero() || !b.HasMargin {
+		return 0, false
+	}
+	return b.Amount + pt.GetTotalPL() - b.InitialMargin, true
+}
+
+// DayChange is the change in cash balance since the start of the trade date
+func (b AccountBalance) DayChange() float64 {
+	return b.Amount - b.AmountSOD
+}
+
+// recordCashBalance keeps a cash balance's amounts and PnL for the tracked account
+func (pt *PortfolioTracker) recordCashBalance(cb tradovate.APICashBalance) {
+	pt.plTracker.SetRealizedPnL(cb.RealizedPnL, cb.TradeDate)
+
+	pt.mu.Lock()
+	pt.balance.Amount = cb.Amount
+	pt.balance.AmountSOD = cb.AmountSOD
+	pt.balance.WeekRealizedPnL = cb.WeekRealizedPnL
+	pt.balance.TradeDate = cb.TradeDate
+	pt.balance.UpdatedAt = time.Now()
+	pt.mu.Unlock()
+	pt.checkBalance()
+}
+
+// handleMarginSnapshot processes margin snapshots from user sync and props events
+func (pt *PortfolioTracker) handleMarginSnapshot(data json.RawMessage) {
+	var snap tradovate.APIMarginSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		pt.log.Warnf("Failed to unmarshal margin snapshot: %v", err)
+		return
+	}
+	if !pt.isTrackedAccount(snap.ID) {
+		return
+	}
+
+	pt.mu.Lock()
+	pt.balance.HasMargin = true
+	pt.balance.InitialMargin = snap.InitialMargin
+	pt.balance.MaintenanceMargin = snap.MaintenanceMargin
+	pt.balance.TotalUsedMargin = snap.TotalUsedMargin
+	pt.balance.AutoLiqLevel = snap.AutoLiqLevel
+	pt.mu.Unlock()
+	pt.log.Debugf("Margin Snapshot: initial $%.2f, maintenance $%.2f", snap.InitialMargin, snap.MaintenanceMargin)
+}
+
+// checkBalance warns once when the cash balance falls below the threshold, and
+// again only after it has recovered
+func (pt *PortfolioTracker) checkBalance() {
+	pt.mu.Lock()
+	threshold, amount := pt.minBalance, pt.balance.Amount
+	known := !pt.balance.UpdatedAt.IsZero()
+	low := threshold > 0 && known && amount < threshold
+	changed := low != pt.lowBalance
+	pt.lowBalance = low
+	pt.mu.Unlock()
+
+	switch {
+	case changed && low:
+		pt.log.Warnf("Cash balance $%.2f is below the $%.2f warning level", amount, threshold)
+	case changed:
+		pt.log.Infof("Cash balance $%.2f is back above $%.2f", amount, threshold)
+	}
+}
@@ -3,9 +3,13 @@ package portfolio
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/contracts"
 	"tradovate-execution-engine/engine/internal/logger"
 	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/metrics"
 	"tradovate-execution-engine/engine/internal/models"
 	"tradovate-execution-engine/engine/internal/tradovate"
 )
@@ -18,8 +22,8 @@ func NewPLTracker(log *logger.Logger) *PLTracker {
 	}
 }
 
-// Update updates or creates a PnL entry
-func (t *PLTracker) Update(name string, pl float64, netPos int, buyPrice, lastPrice float64) {
+// Update updates or creates a PnL entry; mark is the quote price lastPrice is
+func (t *PLTracker) Update(name string, pl float64, netPos int, buyPrice, lastPrice float64, mark MarkType) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -28,6 +32,7 @@ func (t *PLTracker) Update(name string, pl float64, netPos int, buyPrice, lastPr
 		entry.NetPos = netPos
 		entry.BuyPrice = buyPrice
 		entry.LastPrice = lastPrice
+		entry.Mark = mark
 	} else {
 		t.entries[name] = &PLEntry{
 			Name:      name,
@@ -35,6 +40,7 @@ func (t *PLTracker) Update(name string, pl float64, netPos int, buyPrice, lastPr
 			NetPos:    netPos,
 			BuyPrice:  buyPrice,
 			LastPrice: lastPrice,
+			Mark:      mark,
 		}
 	}
 }
@@ -75,8 +81,8 @@ func (t *PLTracker) PrintSummary() {
 			if entry.NetPos < 0 {
 				direction = "SHORT"
 			}
-			t.log.Debugf("%-10s | %5s %3d | Buy: $%8.2f | Last: $%8.2f | PnL: $%9.2f",
-				name, direction, models.Abs(entry.NetPos), entry.BuyPrice, entry.LastPrice, entry.PL)
+			t.log.Debugf("%-10s | %5s %3d | Buy: $%8.2f | Last: $%8.2f (%s) | PnL: $%9.2f",
+				name, direction, models.Abs(entry.NetPos), entry.BuyPrice, entry.LastPrice, entry.Mark, entry.PL)
 		}
 		t.log.Debug("=====================================================")
 		t.log.Debugf("TOTAL PnL: $%.2f", t.GetTotal())
@@ -84,15 +90,56 @@ func (t *PLTracker) PrintSummary() {
 	}
 }
 
-// SetRealizedPnL sets the realized PnL
-func (t *PLTracker) SetRealizedPnL(pnl float64) {
+// SetRealizedPnL sets the realized PnL reported for a trade date. The first one
+// anchors the session, and so does the first one of a later trade date.
+func (t *PLTracker) SetRealizedPnL(pnl float64, tradeDate tradovate.APITradeDate) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	if !t.hasInitialRealized {
-		t.initialRealizedPnL = pnl
-		t.hasInitialRealized = true
+	now := time.Now()
+	switch {
+	case !t.anchor.Anchored:
+		t.anchor.TradeDate, t.anchor.RealizedPnL, t.anchor.Anchored = tradeDate, pnl, true
+		if t.anchor.Start.IsZero() {
+			t.anchor.Start = now.UTC()
+		}
+	case t.anchor.rolled(tradeDate, now):
+		if t.log != nil {
+			t.log.Infof("New trade date %d-%02d-%02d - session realized PnL counts from $%.2f",
+				tradeDate.Year, tradeDate.Month, tradeDate.Day, pnl)
+		}
+		t.anchor = SessionAnchor{Start: now.UTC(), AccountID: t.anchor.AccountID, TradeDate: tradeDate, RealizedPnL: pnl, Anchored: true}
+	case t.anchor.TradeDate == (tradovate.APITradeDate{}):
+		// Same day: the anchor's date is learned once Tradovate sends one
+		t.anchor.TradeDate = tradeDate
 	}
 	t.realizedPnL = pnl
+	t.realizedTradeDate = tradeDate
+}
+
+// rolled reports whether realized PnL for tradeDate belongs to a later trade
+// date than the anchor's. Without trade dates the calendar day is compared.
+func (a SessionAnchor) rolled(tradeDate tradovate.APITradeDate, now time.Time) bool {
+	if a.TradeDate != (tradovate.APITradeDate{}) && tradeDate != (tradovate.APITradeDate{}) {
+		return a.TradeDate != tradeDate
+	}
+	y1, m1, d1 := a.Start.Local().Date()
+	y2, m2, d2 := now.Local().Date()
+	return y1 != y2 || m1 != m2 || d1 != d2
+}
+
+// SetSessionAnchor carries a session over from an earlier tracker. Its realized
+// PnL stays the base until a cash balance for a later trade date arrives.
+func (t *PLTracker) SetSessionAnchor(anchor SessionAnchor) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.anchor = anchor
+}
+
+// SessionAnchor returns where session realized PnL counts from
+func (t *PLTracker) SessionAnchor() SessionAnchor {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.anchor
 }
 
 // GetRealizedPnL returns the realized PnL
@@ -102,15 +149,32 @@ func (t *PLTracker) GetRealizedPnL() float64 {
 	return t.realizedPnL
 }
 
+// GetRealizedTradeDate returns the trade date of the last cash balance (zero if it carried none)
+func (t *PLTracker) GetRealizedTradeDate() tradovate.APITradeDate {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.realizedTradeDate
+}
+
+// GetInitialRealizedPnL returns the realized PnL reported at session start
+func (t *PLTracker) GetInitialRealizedPnL() float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.anchor.RealizedPnL
+}
+
 // GetSessionRealizedPnL returns the realized PnL since session start
 func (t *PLTracker) GetSessionRealizedPnL() float64 {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	return t.realizedPnL - t.initialRealizedPnL
+	if !t.anchor.Anchored {
+		return 0
+	}
+	return t.realizedPnL - t.anchor.RealizedPnL
 }
 
 // NewPortfolioTracker creates a new portfolio tracker using existing clients
-func NewPortfolioTracker(authClient, mdClient *tradovate.DataSubscriber, userID int, log *logger.Logger) *PortfolioTracker {
+func NewPortfolioTracker(authClient, mdClient *tradovate.DataSubscriber, userID, accountID int, log *logger.Logger) *PortfolioTracker {
 	return &PortfolioTracker{
 		tradingSubsciptionManager: authClient,
 		mdSubsciptionManager:      mdClient,
@@ -119,12 +183,18 @@ func NewPortfolioTracker(authClient, mdClient *tradovate.DataSubscriber, userID
 		positions:                 make(map[int]*tradovate.APIPosition),
 		contracts:                 make(map[int]string),
 		products:                  make(map[string]float64),
+		tickSizes:                 make(map[string]float64),
+		fills:                     make(map[int]tradovate.APIFill),
+		fillPairs:                 make(map[int]tradovate.APIFillPair),
+		positionContracts:         make(map[int]int),
+		quoteSubs:                 make(map[string]bool),
 		userID:                    userID,
+		accountID:                 accountID,
 	}
 }
 
 // Start initializes the portfolio tracker
-func (pt *PortfolioTracker) Start(environment string) error {
+func (pt *PortfolioTracker) Start(environment config.Environment) error {
 	pt.mu.Lock()
 	if pt.running {
 		pt.mu.Unlock()
@@ -151,13 +221,33 @@ func (pt *PortfolioTracker) Start(environment string) error {
 		pt.handleCashBalanceUpdate(data)
 	}
 
+	pt.tradingSubsciptionManager.OnMarginSnapshot = func(data json.RawMessage) {
+		pt.handleMarginSnapshot(data)
+	}
+
+	pt.tradingSubsciptionManager.OnFillPairUpdate = func(data json.RawMessage) {
+		pt.handleFillPair(data)
+	}
+
 	pt.mdSubsciptionManager.AddQuoteHandler(pt.handleQuoteUpdate)
 
 	// Subscribe to user sync
-	if err := pt.tradingSubsciptionManager.SubscribeUserSyncRequests([]int{pt.userID}); err != nil {
+	var accounts []int
+	if pt.accountID != 0 {
+		accounts = []int{pt.accountID}
+	}
+	if err := pt.tradingSubsciptionManager.SubscribeUserSyncRequests([]int{pt.userID}, accounts); err != nil {
 		return fmt.Errorf("failed to subscribe to user sync: %w", err)
 	}
 
+	metrics.Default.GaugeFunc("engine_total_pnl", "Unrealized plus today's realized PnL in dollars", func() float64 {
+		return pt.GetTotalPL() + pt.GetRealizedPnL()
+	})
+	metrics.Default.GaugeFunc("engine_daily_realized_pnl", "Realized PnL for the current trade date in dollars", pt.GetRealizedPnL)
+	metrics.Default.GaugeFunc("engine_cash_balance", "Account cash balance in dollars", func() float64 {
+		return pt.GetAccountBalance().Amount
+	})
+
 	pt.log.Info("Portfolio tracker started")
 	return nil
 
@@ -171,9 +261,15 @@ func (pt *PortfolioTracker) handlePositionUpdate(data json.RawMessage) {
 		pt.log.Warnf("Failed to unmarshal position update: %v", err)
 		return
 	}
+	if !pt.isTrackedAccount(pos.AccountID) {
+		return
+	}
 
 	pt.mu.Lock()
 	pt.positions[pos.ContractID] = &pos
+	if pos.ID != 0 {
+		pt.positionContracts[pos.ID] = pos.ContractID
+	}
 	contractName, hasContract := pt.contracts[pos.ContractID]
 	pt.mu.Unlock()
 
@@ -183,18 +279,25 @@ func (pt *PortfolioTracker) handlePositionUpdate(data json.RawMessage) {
 			pt.log.Debugf("Position update for %s: NetPos=%d, Bought Price=%.2d -> Subscribing",
 				contractName, pos.NetPos, pos.Bought)
 
-			if err := pt.mdSubsciptionManager.SubscribeQuote(contractName); err != nil {
+			if err := pt.subscribeQuotes([]string{contractName}); err != nil {
 				pt.log.Warnf("Failed to subscribe to quotes for %s: %v", contractName, err)
 			}
 
 			return
 		}
 		// Reset PnL in tracker for this symbol
-		pt.plTracker.Update(contractName, 0, 0, 0, 0)
+		pt.plTracker.Update(contractName, 0, 0, 0, 0, "")
+		pt.clearPositionAlert(contractName)
 
 	}
 }
 
+// isTrackedAccount reports whether an entity belongs to the selected account.
+// Entities without an account ID are kept.
+func (pt *PortfolioTracker) isTrackedAccount(accountID int) bool {
+	return pt.accountID == 0 || accountID == 0 || accountID == pt.accountID
+}
+
 // handleCashBalanceUpdate processes real-time Cash Balance updates
 func (pt *PortfolioTracker) handleCashBalanceUpdate(data json.RawMessage) {
 	var cb tradovate.APICashBalance
@@ -202,8 +305,11 @@ func (pt *PortfolioTracker) handleCashBalanceUpdate(data json.RawMessage) {
 		pt.log.Warnf("Failed to unmarshal cash balance: %v", err)
 		return
 	}
-	pt.plTracker.SetRealizedPnL(cb.RealizedPnL)
-	pt.log.Debugf("Cash Balance Update: Realized PnL = %.2f", cb.RealizedPnL)
+	if !pt.isTrackedAccount(cb.AccountID) {
+		return
+	}
+	pt.recordCashBalance(cb)
+	pt.log.Debugf("Cash Balance Update: Balance = %.2f, Realized PnL = %.2f", cb.Amount, cb.RealizedPnL)
 }
 
 // handleUserSync processes the initial user sync response
@@ -230,24 +336,34 @@ func (pt *PortfolioTracker) handleUserSync(data json.RawMessage) {
 	}
 	for _, product := range syncResp.Products {
 		pt.products[product.Name] = product.ValuePerPoint
+		if product.TickSize > 0 {
+			pt.tickSizes[product.Name] = product.TickSize
+		}
 	}
 
 	// Set up the unified quote handler once
 	pt.mu.Unlock()
 
-	// Process cash balances to get initial realized PnL
+	// Process cash balances to get the initial balance and realized PnL
 	for _, cbRaw := range syncResp.CashBalances {
 		var cb tradovate.APICashBalance
-		if err := json.Unmarshal(cbRaw, &cb); err == nil {
-			pt.plTracker.SetRealizedPnL(cb.RealizedPnL)
+		if err := json.Unmarshal(cbRaw, &cb); err == nil && pt.isTrackedAccount(cb.AccountID) {
+			pt.recordCashBalance(cb)
 		}
 	}
 
 	// Process each position
+	var open []string
 	for _, pos := range syncResp.Positions {
+		if !pt.isTrackedAccount(pos.AccountID) {
+			continue
+		}
 		p := pos // Local copy
 		pt.mu.Lock()
 		pt.positions[pos.ContractID] = &p
+		if pos.ID != 0 {
+			pt.positionContracts[pos.ID] = pos.ContractID
+		}
 		pt.mu.Unlock()
 
 		// Find contract name
@@ -263,11 +379,43 @@ func (pt *PortfolioTracker) handleUserSync(data json.RawMessage) {
 			if pos.NetPos != 0 {
 				pt.log.Debugf("Found active position: %s (ID: %d) - NetPos: %d -> Subscribing",
 					contractName, pos.ContractID, pos.NetPos)
-				// Subscribe to market data
-				pt.mdSubsciptionManager.SubscribeQuote(contractName)
+				open = append(open, contractName)
 			}
 		}
 	}
+
+	// Market data for every open position in one request
+	if err := pt.subscribeQuotes(open); err != nil {
+		pt.log.Warnf("Failed to subscribe to quotes for %v: %v", open, err)
+	}
+}
+
+// subscribeQuotes takes the tracker's quote subscription on each symbol it does
+// not already hold one on. The tracker keeps one reference per symbol until
+// Stop, however many position updates mention it.
+func (pt *PortfolioTracker) subscribeQuotes(symbols []string) error {
+	pt.mu.Lock()
+	var fresh []string
+	for _, symbol := range symbols {
+		if !pt.quoteSubs[symbol] {
+			pt.quoteSubs[symbol] = true
+			fresh = append(fresh, symbol)
+		}
+	}
+	pt.mu.Unlock()
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	err := pt.mdSubsciptionManager.SubscribeQuotes(fresh)
+	if err != nil {
+		pt.mu.Lock()
+		for _, symbol := range fresh {
+			delete(pt.quoteSubs, symbol)
+		}
+		pt.mu.Unlock()
+	}
+	return err
 }
 
 // handleQuoteUpdate processes incoming quote updates and calculates PnL
@@ -277,30 +425,27 @@ func (pt *PortfolioTracker) handleQuoteUpdate(quote marketdata.Quote) {
 	contractName, hasContract := pt.contracts[quote.ContractID]
 	pt.mu.Unlock()
 
-	if !hasPos || !hasContract {
+	if !hasPos {
 		return
 	}
+	if !hasContract {
+		if contractName, hasContract = pt.lookupContract(quote.ContractID); !hasContract {
+			return
+		}
+	}
 
-	// Get the trade price
-	trade, ok := quote.Entries["Trade"]
+	pt.mu.Lock()
+	mid := pt.markToMid
+	pt.mu.Unlock()
+
+	// Price the position where it would close
+	price, mark, ok := markPrice(quote, pos.NetPos, mid)
 	if !ok {
 		return
 	}
 
-	price := trade.Price
-
 	// Find value per point
-	var vpp float64
-	pt.mu.Lock()
-	for pName, val := range pt.products {
-		if len(pName) > 0 && len(contractName) >= len(pName) &&
-			contractName[:len(pName)] == pName {
-			vpp = val
-			break
-		}
-	}
-	pt.mu.Unlock()
-
+	vpp := pt.GetValuePerPoint(contractName)
 	if vpp == 0 {
 		return
 	}
@@ -315,7 +460,37 @@ func (pt *PortfolioTracker) handleQuoteUpdate(quote marketdata.Quote) {
 	pl := (price - buyPrice) * vpp * float64(pos.NetPos)
 
 	// Update tracker
-	pt.plTracker.Update(contractName, pl, pos.NetPos, buyPrice, price)
+	pt.plTracker.Update(contractName, pl, pos.NetPos, buyPrice, price, mark)
+	pt.checkPositionAlert(contractName, pl)
+}
+
+// SetMarkToMidpoint values open positions at the bid/offer midpoint when mid is
+// set, otherwise at the bid for longs and the offer for shorts
+func (pt *PortfolioTracker) SetMarkToMidpoint(mid bool) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.markToMid = mid
+}
+
+// markPrice picks the quote price a position of netPos is valued at: the bid
+// for a long and the offer for a short, or their midpoint when mid is set. It
+// falls back to the last trade when the side it needs is missing.
+func markPrice(quote marketdata.Quote, netPos int, mid bool) (float64, MarkType, bool) {
+	bid, hasBid := quote.BestBid()
+	offer, hasOffer := quote.BestAsk()
+
+	switch {
+	case mid && hasBid && hasOffer:
+		return (bid.Price + offer.Price) / 2, MarkMid, true
+	case !mid && netPos > 0 && hasBid:
+		return bid.Price, MarkBid, true
+	case !mid && netPos < 0 && hasOffer:
+		return offer.Price, MarkOffer, true
+	}
+	if trade, ok := quote.LastTrade(); ok {
+		return trade.Price, MarkTrade, true
+	}
+	return 0, "", false
 }
 
 // Stop disconnects all WebSocket connections
@@ -335,12 +510,91 @@ func (pt *PortfolioTracker) Stop() error {
 			pt.log.Warnf("Error unsubscribing: %v", err)
 		}
 	}
+	pt.quoteSubs = make(map[string]bool)
+
+	// Disconnected PnL would only be the last value seen
+	metrics.Default.Unregister("engine_total_pnl")
+	metrics.Default.Unregister("engine_daily_realized_pnl")
 
 	pt.running = false
 	pt.log.Info("Portfolio tracker stopped")
 	return nil
 }
 
+// GetContractName returns the contract symbol for a contract ID learned from user sync
+func (pt *PortfolioTracker) GetContractName(contractID int) (string, bool) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	name, ok := pt.contracts[contractID]
+	return name, ok
+}
+
+// SetContractCatalog sets the catalog used for contracts and products that user
+// sync did not include, such as one traded before any position in it was held
+func (pt *PortfolioTracker) SetContractCatalog(c *contracts.Catalog) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.catalog = c
+}
+
+// lookupContract names a contract user sync did not include, using the catalog,
+// and remembers it
+func (pt *PortfolioTracker) lookupContract(contractID int) (string, bool) {
+	pt.mu.Lock()
+	catalog := pt.catalog
+	pt.mu.Unlock()
+	if catalog == nil {
+		return "", false
+	}
+
+	spec, err := catalog.SpecByID(contractID)
+	if err != nil {
+		return "", false
+	}
+	pt.mu.Lock()
+	pt.contracts[contractID] = spec.Symbol
+	pt.mu.Unlock()
+	return spec.Symbol, true
+}
+
+// GetTickSize returns the tick size for a contract symbol by matching its product root,
+// then from the catalog, or 0 if neither knows it
+func (pt *PortfolioTracker) GetTickSize(symbol string) float64 {
+	pt.mu.Lock()
+	for pName, tick := range pt.tickSizes {
+		if len(pName) > 0 && len(symbol) >= len(pName) && symbol[:len(pName)] == pName {
+			pt.mu.Unlock()
+			return tick
+		}
+	}
+	catalog := pt.catalog
+	pt.mu.Unlock()
+
+	if catalog != nil {
+		return catalog.TickSize(symbol)
+	}
+	return 0
+}
+
+// GetValuePerPoint returns the dollar value of a one point move for a contract symbol
+// by matching its product root, then from the catalog, or 0 if neither knows it
+func (pt *PortfolioTracker) GetValuePerPoint(symbol string) float64 {
+	pt.mu.Lock()
+	for pName, vpp := range pt.products {
+		if len(pName) > 0 && len(symbol) >= len(pName) && symbol[:len(pName)] == pName {
+			pt.mu.Unlock()
+			return vpp
+		}
+	}
+	catalog := pt.catalog
+	pt.mu.Unlock()
+
+	if catalog != nil {
+		return catalog.ValuePerPoint(symbol)
+	}
+	return 0
+}
+
 // GetPLSummary returns the current PnL summary
 func (pt *PortfolioTracker) GetPLSummary() map[string]PLEntry {
 	return pt.plTracker.GetEntries()
@@ -351,6 +605,11 @@ func (pt *PortfolioTracker) GetRealizedPnL() float64 {
 	return pt.plTracker.GetRealizedPnL()
 }
 
+// GetRealizedTradeDate returns the trade date Tradovate reported with the realized PnL
+func (pt *PortfolioTracker) GetRealizedTradeDate() tradovate.APITradeDate {
+	return pt.plTracker.GetRealizedTradeDate()
+}
+
 // GetTotalPL returns the total PnL
 func (pt *PortfolioTracker) GetTotalPL() float64 {
 	return pt.plTracker.GetTotal()
@@ -361,6 +620,17 @@ func (pt *PortfolioTracker) GetSessionRealizedPnL() float64 {
 	return pt.plTracker.GetSessionRealizedPnL()
 }
 
+// SetSessionAnchor carries the session of an earlier connection over, so its
+// realized PnL keeps counting from the same base
+func (pt *PortfolioTracker) SetSessionAnchor(anchor SessionAnchor) {
+	pt.plTracker.SetSessionAnchor(anchor)
+}
+
+// SessionAnchor returns where session realized PnL counts from
+func (pt *PortfolioTracker) SessionAnchor() SessionAnchor {
+	return pt.plTracker.SessionAnchor()
+}
+
 // PrintSummary prints the current PnL summary
 func (pt *PortfolioTracker) PrintSummary() {
 	pt.plTracker.PrintSummary()
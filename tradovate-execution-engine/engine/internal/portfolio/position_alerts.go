@@ -0,0 +1,131 @@
+package portfolio
+
+import (
+	"sort"
+	"time"
+)
+
+// maxPositionAlerts bounds the alert feed
+const maxPositionAlerts = 50
+
+// String names the level, e.g. "CRITICAL"
+func (l AlertLevel) String() string {
+	switch l {
+	case AlertWarning:
+		return "WARNING"
+	case AlertCritical:
+		return "CRITICAL"
+	}
+	return "NONE"
+}
+
+// SetPositionLossAlerts sets the unrealized losses, in dollars, at which a
+// single position raises a warning and a critical alert, 0 to turn a level off.
+// When they change, positions already past a level alert again on their next quote.
+func (pt *PortfolioTracker) SetPositionLossAlerts(warning, critical float64) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if warning == pt.lossWarning && critical == pt.lossCritical {
+		return
+	}
+	pt.lossWarning = warning
+	pt.lossCritical = critical
+	pt.alertLevels = nil
+}
+
+// ActivePositionAlerts returns the latest alert of each open position whose
+// loss is still past a threshold, by symbol
+func (pt *PortfolioTracker) ActivePositionAlerts() []PositionAlert {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	active := make([]PositionAlert, 0, len(pt.alertLevels))
+	for _, a := range pt.alertLevels {
+		active = append(active, a)
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Symbol < active[j].Symbol })
+	return active
+}
+
+// PositionAlertsSince returns the alerts raised after the one numbered seq,
+// oldest first. Pass 0 for every alert still in the feed.
+func (pt *PortfolioTracker) PositionAlertsSince(seq int) []PositionAlert {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	var since []PositionAlert
+	for _, a := range pt.alerts {
+		if a.Seq > seq {
+			since = append(since, a)
+		}
+	}
+	return since
+}
+
+// lossLevel returns the highest level pl has crossed and its threshold.
+// Caller must hold the lock.
+func (pt *PortfolioTracker) lossLevel(pl float64) (AlertLevel, float64) {
+	switch {
+	case pt.lossCritical > 0 && pl <= -pt.lossCritical:
+		return AlertCritical, pt.lossCritical
+	case pt.lossWarning > 0 && pl <= -pt.lossWarning:
+		return AlertWarning, pt.lossWarning
+	}
+	return AlertNone, 0
+}
+
+// checkPositionAlert raises an alert when the unrealized PnL of symbol's
+// position crosses a higher level than it has already, and lowers the level
+// once it recovers, so each crossing alerts once
+func (pt *PortfolioTracker) checkPositionAlert(symbol string, pl float64) {
+	pt.mu.Lock()
+	level, threshold := pt.lossLevel(pl)
+	prev := pt.alertLevels[symbol].Level
+	if level == prev {
+		pt.mu.Unlock()
+		return
+	}
+	if level == AlertNone {
+		delete(pt.alertLevels, symbol)
+		pt.mu.Unlock()
+		pt.log.Infof("%s unrealized PnL $%.2f recovered past the alert levels", symbol, pl)
+		return
+	}
+
+	alert := PositionAlert{Symbol: symbol, Level: level, PnL: pl, Threshold: threshold, Time: time.Now()}
+	if pt.alertLevels == nil {
+		pt.alertLevels = make(map[string]PositionAlert)
+	}
+	if level < prev {
+		// Recovered from critical to warning: keep the warning without alerting again
+		critical := pt.alertLevels[symbol].Threshold
+		alert.Seq = pt.alertLevels[symbol].Seq
+		pt.alertLevels[symbol] = alert
+		pt.mu.Unlock()
+		pt.log.Infof("%s unrealized PnL $%.2f recovered above the -$%.2f critical level", symbol, pl, critical)
+		return
+	}
+	pt.alertSeq++
+	alert.Seq = pt.alertSeq
+	pt.alertLevels[symbol] = alert
+	pt.alerts = append(pt.alerts, alert)
+	if len(pt.alerts) > maxPositionAlerts {
+		pt.alerts = append(pt.alerts[:0], pt.alerts[len(pt.alerts)-maxPositionAlerts:]...)
+	}
+	pt.mu.Unlock()
+
+	if level == AlertCritical {
+		pt.log.Errorf("POSITION ALERT CRITICAL: %s unrealized PnL $%.2f is past -$%.2f", symbol, pl, threshold)
+	} else {
+		pt.log.Warnf("POSITION ALERT: %s unrealized PnL $%.2f is past -$%.2f", symbol, pl, threshold)
+	}
+}
+
+// clearPositionAlert drops the alert of a position that closed
+func (pt *PortfolioTracker) clearPositionAlert(symbol string) {
+	pt.mu.Lock()
+	_, active := pt.alertLevels[symbol]
+	delete(pt.alertLevels, symbol)
+	pt.mu.Unlock()
+	if active {
+		pt.log.Infof("%s position closed - alert cleared", symbol)
+	}
+}
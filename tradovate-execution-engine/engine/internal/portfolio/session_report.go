@@ -0,0 +1,181 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// Snapshot captures the current session state for a report
+func (pt *PortfolioTracker) Snapshot(sessionStart time.Time) SessionSnapshot {
+	snap := SessionSnapshot{
+		SessionStart:        sessionStart,
+		StartingRealizedPnL: pt.plTracker.GetInitialRealizedPnL(),
+		EndingRealizedPnL:   pt.plTracker.GetRealizedPnL(),
+		Positions:           pt.plTracker.GetEntries(),
+		RealizedBySymbol:    make(map[string]float64),
+		Trades:              pt.GetClosedTrades(),
+	}
+
+	// Realized points per symbol; the open part is valued at its entry price so the rest is closed
+	points := make(map[string]float64)
+	pt.mu.Lock()
+	for contractID, pos := range pt.positions {
+		if name, ok := pt.contracts[contractID]; ok {
+			points[name] = pos.SoldValue - pos.BoughtValue + float64(pos.NetPos)*pos.NetPrice
+		}
+	}
+	pt.mu.Unlock()
+
+	for name, p := range points {
+		snap.RealizedBySymbol[name] = p * pt.GetValuePerPoint(name)
+	}
+	return snap
+}
+
+// BuildSessionReport aggregates a snapshot, the session's orders and the PnL history
+func BuildSessionReport(snap SessionSnapshot, orders []*models.Order, history []PnLSample) *SessionReport {
+	report := &SessionReport{
+		SessionStart:        snap.SessionStart,
+		GeneratedAt:         time.Now().UTC(),
+		StartingRealizedPnL: snap.StartingRealizedPnL,
+		EndingRealizedPnL:   snap.EndingRealizedPnL,
+		SessionRealizedPnL:  snap.EndingRealizedPnL - snap.StartingRealizedPnL,
+		PnLHistory:          history,
+	}
+	// Only round trips closed during the session
+	for _, t := range snap.Trades {
+		if t.ExitTime.IsZero() || !t.ExitTime.Before(snap.SessionStart) {
+			report.Trades = append(report.Trades, t)
+			report.Fees += t.Fees
+		}
+	}
+	report.SessionNetPnL = report.SessionRealizedPnL - report.Fees
+
+	symbols := make(map[string]*SymbolReport)
+	get := func(name string) *SymbolReport {
+		if r, ok := symbols[name]; ok {
+			return r
+		}
+		r := &SymbolReport{Symbol: name}
+		symbols[name] = r
+		return r
+	}
+	for name, pnl := range snap.RealizedBySymbol {
+		get(name).RealizedPnL = pnl
+	}
+	for name, entry := range snap.Positions {
+		if entry.NetPos == 0 {
+			continue
+		}
+		r := get(name)
+		r.NetPos = entry.NetPos
+		r.AvgPrice = entry.BuyPrice
+		r.LastPrice = entry.LastPrice
+		r.Mark = entry.Mark
+		r.UnrealizedPnL = entry.PL
+		r.Open = true
+		report.UnrealizedPnL += entry.PL
+	}
+	for _, r := range symbols {
+		report.Symbols = append(report.Symbols, *r)
+	}
+	sort.Slice(report.Symbols, func(i, j int) bool { return report.Symbols[i].Symbol < report.Symbols[j].Symbol })
+
+	report.Origins = OriginBreakdown(report.Trades, orders, snap.ExternalOrders)
+
+	for _, o := range orders {
+		report.Orders++
+		report.Executions += len(o.Fills)
+		switch o.Status {
+		case models.StatusFilled:
+			report.Fills++
+		case models.StatusPartiallyFilled:
+			report.PartialFills++
+		case models.StatusRejected:
+			report.Rejects++
+		}
+	}
+	return report
+}
+
+// Text renders the report for reading
+func (r *SessionReport) Text() string {
+	var b strings.Builder
+	b.WriteString("==================== SESSION REPORT ====================\n")
+	fmt.Fprintf(&b, "Session Start:     %s\n", r.SessionStart.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Generated:         %s\n", r.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Realized PnL:      $%.2f -> $%.2f (session: $%.2f)\n",
+		r.StartingRealizedPnL, r.EndingRealizedPnL, r.SessionRealizedPnL)
+	fmt.Fprintf(&b, "Fees:              $%.2f (session net: $%.2f)\n", r.Fees, r.SessionNetPnL)
+	fmt.Fprintf(&b, "Unrealized PnL:    $%.2f\n", r.UnrealizedPnL)
+	fmt.Fprintf(&b, "Orders:            %d (fills: %d, partial: %d, rejects: %d)\n", r.Orders, r.Fills, r.PartialFills, r.Rejects)
+	fmt.Fprintf(&b, "Executions:        %d\n", r.Executions)
+
+	b.WriteString("\n-------------------- SYMBOLS --------------------\n")
+	if len(r.Symbols) == 0 {
+		b.WriteString("No positions traded\n")
+	}
+	for _, s := range r.Symbols {
+		state := "CLOSED"
+		if s.Open {
+			state = fmt.Sprintf("OPEN %d @ %.2f", s.NetPos, s.AvgPrice)
+			if s.Mark != "" {
+				state += fmt.Sprintf(", marked %.2f (%s)", s.LastPrice, s.Mark)
+			}
+		}
+		fmt.Fprintf(&b, "%-10s | Realized: $%9.2f | Unrealized: $%9.2f | %s\n",
+			s.Symbol, s.RealizedPnL, s.UnrealizedPnL, state)
+	}
+
+	b.WriteString("\n-------------------- BY ORIGIN --------------------\n")
+	if len(r.Origins) == 0 {
+		b.WriteString("No orders or trades\n")
+	}
+	for _, o := range r.Origins {
+		b.WriteString(o.String() + "\n")
+	}
+
+	b.WriteString("\n-------------------- TRADES --------------------\n")
+	if len(r.Trades) == 0 {
+		b.WriteString("No closed trades\n")
+	}
+	for _, t := range r.Trades {
+		b.WriteString(t.String() + "\n")
+	}
+
+	b.WriteString("\n-------------------- PnL HISTORY --------------------\n")
+	for _, p := range r.PnLHistory {
+		fmt.Fprintf(&b, "%s  $%.2f\n", p.Time.Format("15:04:05"), p.PnL)
+	}
+	b.WriteString("=========================================================\n")
+	return b.String()
+}
+
+// WriteFiles writes the report as .txt and .json into dir, named by session start
+func (r *SessionReport) WriteFiles(dir string) (txtPath, jsonPath string, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+	base := filepath.Join(dir, "session_report_"+r.SessionStart.Format("2006-01-02_150405"))
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	txtPath, jsonPath = base+".txt", base+".json"
+	if err := os.WriteFile(txtPath, []byte(r.Text()), 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(jsonPath, data, 0644); err != nil {
+		return "", "", err
+	}
+	return txtPath, jsonPath, nil
+}
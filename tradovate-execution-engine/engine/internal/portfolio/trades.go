@@ -0,0 +1,269 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RecordFill keeps a fill so the fill pair that later closes a trade can be given
+// its times, side and contract. The trading subscriber's fill events are shared
+// with the order manager, so the engine forwards them here.
+func (pt *PortfolioTracker) RecordFill(data json.RawMessage) {
+	ev, err := tradovate.DecodeFillEvent(data)
+	if err != nil || ev.ID == 0 {
+		return
+	}
+	fill := tradovate.APIFill{
+		ID:         ev.ID,
+		OrderID:    ev.OrderID,
+		ContractID: ev.ContractID,
+		Action:     ev.Action,
+		Qty:        ev.Qty,
+		Price:      ev.Price,
+	}
+	if ev.HasTimestamp {
+		fill.Timestamp = ev.Timestamp.Format(time.RFC3339Nano)
+	}
+	pt.mu.Lock()
+	pt.fills[fill.ID] = fill
+	pt.mu.Unlock()
+}
+
+// handleFillPair adds or updates a trade in the ledger
+func (pt *PortfolioTracker) handleFillPair(data json.RawMessage) {
+	var pair tradovate.APIFillPair
+	if err := json.Unmarshal(data, &pair); err != nil {
+		pt.log.Warnf("Failed to unmarshal fill pair: %v", err)
+		return
+	}
+	if pair.ID == 0 {
+		return
+	}
+	pt.mu.Lock()
+	pt.fillPairs[pair.ID] = pair
+	pt.mu.Unlock()
+}
+
+// GetClosedTrades returns every closed trade seen today, oldest exit first. Trades
+// are built when asked for, so a fill that arrives after its pair is still used.
+func (pt *PortfolioTracker) GetClosedTrades() []ClosedTrade {
+	pt.mu.Lock()
+	trades := make([]ClosedTrade, 0, len(pt.fillPairs))
+	entryOrders := make([]int, 0, len(pt.fillPairs))
+	for _, pair := range pt.fillPairs {
+		t, entryOrder := pt.closedTrade(pair)
+		trades = append(trades, t)
+		entryOrders = append(entryOrders, entryOrder)
+	}
+	originOf := pt.originOf
+	fees := pt.fees
+	pt.mu.Unlock()
+
+	for i := range trades {
+		if vpp := pt.GetValuePerPoint(trades[i].Symbol); vpp > 0 {
+			trades[i].PnL = trades[i].Points * float64(trades[i].Qty) * vpp
+		}
+		trades[i].Fees = fees.RoundTrip(trades[i].Symbol, trades[i].Qty)
+		trades[i].NetPnL = trades[i].PnL - trades[i].Fees
+		// Looked up outside pt.mu since the order manager takes its own lock
+		if originOf != nil {
+			trades[i].Origin = originOf(entryOrders[i])
+		}
+	}
+	sort.Slice(trades, func(i, j int) bool {
+		if !trades[i].ExitTime.Equal(trades[j].ExitTime) {
+			return trades[i].ExitTime.Before(trades[j].ExitTime)
+		}
+		return trades[i].ID < trades[j].ID
+	})
+	return trades
+}
+
+// closedTrade builds a trade from a pair and whichever of its fills are known,
+// with the Tradovate order ID of the opening fill (0 if it was not seen). The
+// earlier fill opened the trade; without both times the buy is assumed to have.
+// Callers must hold pt.mu.
+func (pt *PortfolioTracker) closedTrade(pair tradovate.APIFillPair) (ClosedTrade, int) {
+	buy, hasBuy := pt.fills[pair.BuyFillID]
+	sell, hasSell := pt.fills[pair.SellFillID]
+	buyTime, sellTime := fillTime(buy), fillTime(sell)
+
+	t := ClosedTrade{
+		ID:     pair.ID,
+		Qty:    pair.Qty,
+		Points: pair.SellPrice - pair.BuyPrice,
+	}
+
+	contractID := pt.positionContracts[pair.PositionID]
+	if hasBuy {
+		contractID = buy.ContractID
+	} else if hasSell {
+		contractID = sell.ContractID
+	}
+	t.Symbol = pt.contracts[contractID]
+	if t.Symbol == "" && contractID != 0 {
+		t.Symbol = fmt.Sprintf("contract %d", contractID)
+	}
+
+	if !buyTime.IsZero() && !sellTime.IsZero() && sellTime.Before(buyTime) {
+		t.Side = "Short"
+		t.EntryPrice, t.ExitPrice = pair.SellPrice, pair.BuyPrice
+		t.EntryTime, t.ExitTime = sellTime, buyTime
+		return t, sell.OrderID
+	}
+	t.Side = "Long"
+	t.EntryPrice, t.ExitPrice = pair.BuyPrice, pair.SellPrice
+	t.EntryTime, t.ExitTime = buyTime, sellTime
+	return t, buy.OrderID
+}
+
+// SetOriginLookup sets how a Tradovate order ID is turned into the origin of a
+// trade it opened. The order manager sets it when given the tracker.
+func (pt *PortfolioTracker) SetOriginLookup(originOf func(orderID int) string) {
+	pt.mu.Lock()
+	pt.originOf = originOf
+	pt.mu.Unlock()
+}
+
+// SetFees sets the fee model charged on closed trades and open positions
+func (pt *PortfolioTracker) SetFees(fees config.FeeConfig) {
+	pt.mu.Lock()
+	pt.fees = fees
+	pt.mu.Unlock()
+}
+
+// RoundTripFees returns the fees for opening and closing qty contracts of symbol
+func (pt *PortfolioTracker) RoundTripFees(symbol string, qty int) float64 {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.fees.RoundTrip(symbol, qty)
+}
+
+// GetFeesSince returns the fees of trades closed at or after since. A trade
+// whose exit was not seen is counted.
+func (pt *PortfolioTracker) GetFeesSince(since time.Time) float64 {
+	var fees float64
+	for _, t := range pt.GetClosedTrades() {
+		if t.ExitTime.IsZero() || !t.ExitTime.Before(since) {
+			fees += t.Fees
+		}
+	}
+	return fees
+}
+
+// GetOriginStats returns the realized result of one origin's closed trades
+func (pt *PortfolioTracker) GetOriginStats(origin string) OriginStats {
+	for _, stats := range OriginBreakdown(pt.GetClosedTrades(), nil, 0) {
+		if stats.Origin == origin {
+			return stats
+		}
+	}
+	return OriginStats{Origin: origin}
+}
+
+// OriginBreakdown groups orders and closed trades by origin. Strategies come
+// first by instance ID, then manual and external; trades without an origin are
+// left out. externalOrders counts orders the engine did not place that are not
+// among orders; the order manager tracks its external orders with the rest.
+func OriginBreakdown(trades []ClosedTrade, orders []*models.Order, externalOrders int) []OriginStats {
+	byOrigin := make(map[string]*OriginStats)
+	get := func(origin string) *OriginStats {
+		if s, ok := byOrigin[origin]; ok {
+			return s
+		}
+		s := &OriginStats{Origin: origin}
+		byOrigin[origin] = s
+		return s
+	}
+	for _, o := range orders {
+		if o.Origin != "" {
+			get(o.Origin).Orders++
+		}
+	}
+	if externalOrders > 0 {
+		get(models.OriginExternal).Orders += externalOrders
+	}
+	for _, t := range trades {
+		if t.Origin == "" {
+			continue
+		}
+		s := get(t.Origin)
+		s.Trades++
+		net := t.PnL - t.Fees
+		s.RealizedPnL += t.PnL
+		s.Fees += t.Fees
+		s.NetPnL += net
+		if net > 0 {
+			s.Wins++
+		}
+	}
+
+	stats := make([]OriginStats, 0, len(byOrigin))
+	for _, s := range byOrigin {
+		if s.Trades > 0 {
+			s.WinRate = float64(s.Wins) / float64(s.Trades) * 100
+		}
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		ri, rj := originRank(stats[i].Origin), originRank(stats[j].Origin)
+		if ri != rj {
+			return ri < rj
+		}
+		if len(stats[i].Origin) != len(stats[j].Origin) {
+			return len(stats[i].Origin) < len(stats[j].Origin) // Instance "2" before "10"
+		}
+		return stats[i].Origin < stats[j].Origin
+	})
+	return stats
+}
+
+// originRank orders strategies before manual and manual before external
+func originRank(origin string) int {
+	switch origin {
+	case models.OriginManual:
+		return 1
+	case models.OriginExternal:
+		return 2
+	}
+	return 0
+}
+
+// String formats the stats as one report line
+func (s OriginStats) String() string {
+	name := s.Origin
+	if originRank(name) == 0 {
+		name = "strategy " + name
+	}
+	return fmt.Sprintf("%-12s | Orders: %3d | Trades: %3d | Win rate: %5.1f%% | Realized: $%9.2f | Net: $%9.2f",
+		name, s.Orders, s.Trades, s.WinRate, s.RealizedPnL, s.NetPnL)
+}
+
+// fillTime parses a fill's timestamp, zero if it is missing or malformed
+func fillTime(fill tradovate.APIFill) time.Time {
+	ts, err := time.Parse(time.RFC3339Nano, fill.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return ts.UTC()
+}
+
+// String formats the trade as one report line
+func (t ClosedTrade) String() string {
+	exit := "--:--:--"
+	if !t.ExitTime.IsZero() {
+		exit = t.ExitTime.Format("15:04:05")
+	}
+	line := fmt.Sprintf("%s %-10s %-5s %3d  %.2f -> %.2f  %+.2f pts  $%.2f  net $%.2f",
+		exit, t.Symbol, t.Side, t.Qty, t.EntryPrice, t.ExitPrice, t.Points, t.PnL, t.NetPnL)
+	if t.Origin != "" {
+		line += "  " + t.Origin
+	}
+	return line
+}
@@ -2,6 +2,9 @@ package portfolio
 
 import (
 	"sync"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/contracts"
 	"tradovate-execution-engine/engine/internal/logger"
 	"tradovate-execution-engine/engine/internal/tradovate"
 )
@@ -12,17 +15,38 @@ type PLEntry struct {
 	NetPos    int
 	BuyPrice  float64
 	LastPrice float64
+	Mark      MarkType // Which quote price LastPrice is
 }
 
+// MarkType is the quote price an open position is valued at
+type MarkType string
+
+const (
+	MarkBid   MarkType = "bid"   // Longs, at the price they would sell at
+	MarkOffer MarkType = "offer" // Shorts, at the price they would buy back at
+	MarkMid   MarkType = "mid"   // Midpoint of the bid and offer, with Risk.MarkToMidpoint
+	MarkTrade MarkType = "trade" // Last trade, when the quote has no bid or offer
+)
+
 // PLTracker manages profit & loss tracking
 type PLTracker struct {
 	entries map[string]*PLEntry
 	mu      sync.RWMutex
 	log     *logger.Logger
 
-	realizedPnL        float64 // Today's closed trade P&L
-	initialRealizedPnL float64 // P&L at start of session
-	hasInitialRealized bool
+	realizedPnL       float64                // Today's closed trade P&L
+	realizedTradeDate tradovate.APITradeDate // Trade date Tradovate reported it for
+	anchor            SessionAnchor          // Where session realized P&L counts from
+}
+
+// SessionAnchor is where a session's realized PnL counts from. It outlives the
+// tracker, so a reconnect on the same trade date can carry the session over.
+type SessionAnchor struct {
+	Start       time.Time
+	AccountID   int
+	TradeDate   tradovate.APITradeDate // Of RealizedPnL (zero if Tradovate sent none)
+	RealizedPnL float64                // Daily realized PnL when the session began
+	Anchored    bool                   // RealizedPnL is set, from the first cash balance
 }
 
 // PortfolioTracker manages the entire portfolio tracking system
@@ -36,7 +60,146 @@ type PortfolioTracker struct {
 
 	// State tracking
 	userID    int
+	accountID int // Only this account's positions and balances are tracked (0 = all)
 	positions map[int]*tradovate.APIPosition
 	contracts map[int]string
 	products  map[string]float64
+	tickSizes map[string]float64
+	catalog   *contracts.Catalog // Fallback for contracts and products user sync did not include
+	quoteSubs map[string]bool    // Symbols the tracker holds a quote subscription on
+	markToMid bool               // Value positions at the bid/offer midpoint
+
+	// Trade ledger: fills and the fill pairs Tradovate matched from them
+	fills             map[int]tradovate.APIFill
+	fillPairs         map[int]tradovate.APIFillPair
+	positionContracts map[int]int              // Position ID -> contract ID
+	originOf          func(orderID int) string // Who placed a Tradovate order (nil = trades are not attributed)
+	fees              config.FeeConfig         // Charged on each closed trade for its net PnL
+
+	// Account cash and margin, and the low balance warning
+	balance    AccountBalance
+	minBalance float64 // Warn below this cash balance (0 = off)
+	lowBalance bool    // Warned, until the balance recovers
+
+	// Unrealized loss alerts per open position
+	lossWarning  float64                  // Dollars of loss that raise a warning (0 = off)
+	lossCritical float64                  // Dollars of loss that raise a critical alert (0 = off)
+	alertLevels  map[string]PositionAlert // Latest crossing per symbol, while above AlertNone
+	alerts       []PositionAlert          // Recent crossings, oldest first, at most maxPositionAlerts
+	alertSeq     int
+}
+
+// AlertLevel is how far a position's unrealized loss has gone past the alert thresholds
+type AlertLevel int
+
+const (
+	AlertNone AlertLevel = iota
+	AlertWarning
+	AlertCritical
+)
+
+// PositionAlert is a position's unrealized loss crossing an alert threshold
+type PositionAlert struct {
+	Seq       int // Increases with each alert, for PositionAlertsSince
+	Symbol    string
+	Level     AlertLevel
+	PnL       float64 // Unrealized PnL when the threshold was crossed
+	Threshold float64 // Loss in dollars that was crossed
+	Time      time.Time
+}
+
+// AccountBalance is the account's cash and margin as Tradovate last reported them
+type AccountBalance struct {
+	Amount          float64 // Cash balance
+	AmountSOD       float64 // Cash balance at the start of the trade date
+	WeekRealizedPnL float64
+	TradeDate       tradovate.APITradeDate
+	UpdatedAt       time.Time // Zero until a cash balance has arrived
+
+	// From the latest margin snapshot, if Tradovate has sent one
+	HasMargin         bool
+	InitialMargin     float64
+	MaintenanceMargin float64
+	TotalUsedMargin   float64
+	AutoLiqLevel      float64
+}
+
+// ClosedTrade is one round trip, built from a Tradovate fill pair
+type ClosedTrade struct {
+	ID         int       `json:"id"` // Fill pair ID
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"` // "Long" when the buy opened the trade, "Short" when the sell did
+	Qty        int       `json:"qty"`
+	EntryPrice float64   `json:"entryPrice"`
+	ExitPrice  float64   `json:"exitPrice"`
+	Points     float64   `json:"points"` // Per contract, positive for a winner
+	PnL        float64   `json:"pnl"`    // Gross dollars; 0 when the product's value per point is unknown
+	Fees       float64   `json:"fees"`   // Round trip commission and exchange fees
+	NetPnL     float64   `json:"netPnl"` // PnL less Fees
+	EntryTime  time.Time `json:"entryTime"`
+	ExitTime   time.Time `json:"exitTime"`         // Zero when the closing fill was not seen
+	Origin     string    `json:"origin,omitempty"` // Who placed the opening order: a strategy instance ID, "manual" or "external"
+}
+
+// OriginStats is the result of one order origin: a strategy instance ID,
+// "manual" or "external"
+type OriginStats struct {
+	Origin      string  `json:"origin"`
+	Orders      int     `json:"orders"`
+	Trades      int     `json:"trades"`
+	Wins        int     `json:"wins"`
+	WinRate     float64 `json:"winRate"` // Percent of trades with a positive net PnL
+	RealizedPnL float64 `json:"realizedPnL"`
+	Fees        float64 `json:"fees"`
+	NetPnL      float64 `json:"netPnL"`
+}
+
+// PnLSample is one point of the session PnL history
+type PnLSample struct {
+	Time time.Time `json:"time"`
+	PnL  float64   `json:"pnl"`
+}
+
+// SessionSnapshot is the portfolio state a SessionReport is built from
+type SessionSnapshot struct {
+	SessionStart        time.Time
+	StartingRealizedPnL float64
+	EndingRealizedPnL   float64
+	Positions           map[string]PLEntry
+	RealizedBySymbol    map[string]float64
+	Trades              []ClosedTrade
+	ExternalOrders      int // Orders the engine did not place that are not in the report's orders
+}
+
+// SymbolReport is the session result for one contract
+type SymbolReport struct {
+	Symbol        string   `json:"symbol"`
+	RealizedPnL   float64  `json:"realizedPnL"`
+	UnrealizedPnL float64  `json:"unrealizedPnL"`
+	NetPos        int      `json:"netPos"`
+	AvgPrice      float64  `json:"avgPrice,omitempty"`
+	LastPrice     float64  `json:"lastPrice,omitempty"`
+	Mark          MarkType `json:"mark,omitempty"` // Which quote price LastPrice is
+	Open          bool     `json:"open"`           // Position was still open when the report was made
+}
+
+// SessionReport summarises a trading session
+type SessionReport struct {
+	SessionStart        time.Time      `json:"sessionStart"`
+	GeneratedAt         time.Time      `json:"generatedAt"`
+	StartingRealizedPnL float64        `json:"startingRealizedPnL"`
+	EndingRealizedPnL   float64        `json:"endingRealizedPnL"`
+	SessionRealizedPnL  float64        `json:"sessionRealizedPnL"`
+	Fees                float64        `json:"fees"`          // Of the session's closed trades
+	SessionNetPnL       float64        `json:"sessionNetPnL"` // Session realized PnL less Fees
+	UnrealizedPnL       float64        `json:"unrealizedPnL"`
+	Symbols             []SymbolReport `json:"symbols"`
+	Orders              int            `json:"orders"`
+	Fills               int            `json:"fills"`
+	PartialFills        int            `json:"partialFills"`
+	Executions          int            `json:"executions"` // Individual fill records across all orders
+	Rejects             int            `json:"rejects"`
+	Trades              []ClosedTrade  `json:"trades"`
+	Origins             []OriginStats  `json:"origins"` // Strategies by instance ID, then manual and external
+	PnLHistory          []PnLSample    `json:"pnlHistory"`
 }
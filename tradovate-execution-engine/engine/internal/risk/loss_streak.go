@@ -0,0 +1,121 @@
+package risk
+
+import (
+	"fmt"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// UpdateLossStreak counts the closed trades it has not seen yet, oldest exit
+// first: a loss after fees extends the streak, a win resets it and a scratch
+// leaves it as is. Trades closed before the current trade date are skipped.
+// It returns true when this call tripped the breaker, which then stays on for
+// Risk.LossStreakCooldownMinutes, or until ResetLossBreaker when that is 0.
+func (rm *RiskManager) UpdateLossStreak() (tripped bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.rollTradeDate()
+	rm.lossBreakerTripped()
+	if rm.portfolio == nil {
+		return false
+	}
+	if rm.streakSeen == nil {
+		rm.streakSeen = make(map[int]bool)
+	}
+
+	for _, trade := range rm.portfolio.GetClosedTrades() {
+		if rm.streakSeen[trade.ID] {
+			continue
+		}
+		rm.streakSeen[trade.ID] = true
+		if !rm.closedToday(trade) {
+			continue
+		}
+
+		// Net of fees even when gross PnL is what the daily limit checks
+		switch net := trade.PnL - trade.Fees; {
+		case net < 0:
+			rm.lossStreak++
+			rm.log.Warnf("Losing trade on %s ($%.2f): %d in a row", trade.Symbol, net, rm.lossStreak)
+		case net > 0:
+			if rm.lossStreak > 0 {
+				rm.log.Infof("Winning trade on %s ($%.2f): loss streak of %d reset", trade.Symbol, net, rm.lossStreak)
+			}
+			rm.lossStreak = 0
+		}
+	}
+
+	limit := rm.config.Risk.MaxConsecutiveLosses
+	if limit <= 0 || rm.breakerOn || rm.lossStreak < limit {
+		return false
+	}
+	rm.breakerOn = true
+	rm.breakerUntil = time.Time{}
+	if minutes := rm.config.Risk.LossStreakCooldownMinutes; minutes > 0 {
+		rm.breakerUntil = rm.now().Add(time.Duration(minutes) * time.Minute)
+	}
+	rm.log.Errorf("Loss streak breaker tripped: %d losing trades in a row, strategy orders blocked %s",
+		rm.lossStreak, rm.breakerRemaining())
+	return true
+}
+
+// ResetLossBreaker releases the loss streak breaker and clears the streak. It
+// returns whether the breaker was tripped.
+func (rm *RiskManager) ResetLossBreaker(reason string) bool {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	wasOn := rm.breakerOn
+	rm.resetLossBreaker(reason)
+	return wasOn
+}
+
+// GetLossStreak returns the number of losing trades in a row on the current
+// trade date
+func (rm *RiskManager) GetLossStreak() int {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.lossStreak
+}
+
+// LossBreaker reports whether the loss streak breaker is blocking strategy
+// orders, and when its cooldown ends (zero when it waits for a reset)
+func (rm *RiskManager) LossBreaker() (tripped bool, until time.Time) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.lossBreakerTripped(), rm.breakerUntil
+}
+
+// lossBreakerTripped reports whether the breaker is on, first resetting it if
+// its cooldown is over. Caller must hold the write lock.
+func (rm *RiskManager) lossBreakerTripped() bool {
+	if rm.breakerOn && !rm.breakerUntil.IsZero() && !rm.now().Before(rm.breakerUntil) {
+		rm.resetLossBreaker("cooldown over")
+	}
+	return rm.breakerOn
+}
+
+// resetLossBreaker clears the breaker and the streak, logging it if the
+// breaker was on. Caller must hold the write lock.
+func (rm *RiskManager) resetLossBreaker(reason string) {
+	if rm.breakerOn {
+		rm.log.Warnf("Loss streak breaker reset (%s): strategy orders accepted again", reason)
+	}
+	rm.breakerOn = false
+	rm.breakerUntil = time.Time{}
+	rm.lossStreak = 0
+}
+
+// breakerRemaining describes how long the breaker stays on. Caller must hold the lock.
+func (rm *RiskManager) breakerRemaining() string {
+	if rm.breakerUntil.IsZero() {
+		return "until :arm"
+	}
+	return fmt.Sprintf("until %s", rm.breakerUntil.Format("15:04:05"))
+}
+
+// isStrategyOrigin reports whether an order origin names a strategy instance
+// rather than a manual or external order
+func isStrategyOrigin(origin string) bool {
+	return origin != "" && origin != models.OriginManual && origin != models.OriginExternal
+}
@@ -2,44 +2,111 @@ package risk
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/contracts"
 	"tradovate-execution-engine/engine/internal/logger"
 	"tradovate-execution-engine/engine/internal/models"
 	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/schedule"
 )
 
 // NewRiskManager creates a new risk manager
 func NewRiskManager(config *config.Config, log *logger.Logger) *RiskManager {
+	tradingDay := newTradingDay(config, log)
 	return &RiskManager{
-		config:        config,
-		dailyPnL:      0,
-		dailyPnLReset: time.Now(),
-		tradeCount:    0,
-		log:           log,
+		config:     config,
+		dailyPnL:   0,
+		tradingDay: tradingDay,
+		tradeDate:  tradingDay.TradeDate(time.Now()),
+		tradeCount: 0,
+		now:        time.Now,
+		log:        log,
 	}
 }
 
-// CheckOrderRisk validates if an order passes risk checks
-func (rm *RiskManager) CheckOrderRisk(order *models.Order, currentPosition *portfolio.PLEntry) error {
+// newTradingDay parses the configured trade date roll, falling back to the CME
+// default when it is invalid
+func newTradingDay(cfg *config.Config, log *logger.Logger) *schedule.TradingDay {
+	td, err := schedule.NewTradingDay(cfg.Risk.TradingDayTimezone, cfg.Risk.TradingDayRoll)
+	if err != nil {
+		log.Warnf("%v; using %s %s", err, schedule.DefaultTradingDayRoll, schedule.DefaultTradingDayTimezone)
+		td, _ = schedule.NewTradingDay("", "")
+	}
+	return td
+}
+
+// CheckOrderRisk validates if an order passes risk checks. workingOrders is the
+// number of resting orders already at the exchange. Limits come from the
+// order's product root in Risk.Symbols when it has one, else the global values,
+// and rejections say which was hit.
+func (rm *RiskManager) CheckOrderRisk(order *models.Order, currentPosition *portfolio.PLEntry, workingOrders int) error {
+	return rm.checkOrderRisk(order, currentPosition, workingOrders, true)
+}
+
+// RecheckOrderRisk runs CheckOrderRisk again for an order that passed it and
+// was held back, such as a queued strategy order. The order throttle counted
+// it the first time, so it is neither checked nor counted again.
+func (rm *RiskManager) RecheckOrderRisk(order *models.Order, currentPosition *portfolio.PLEntry, workingOrders int) error {
+	return rm.checkOrderRisk(order, currentPosition, workingOrders, false)
+}
+
+// checkOrderRisk is CheckOrderRisk, applying the order throttle when throttle is set
+func (rm *RiskManager) checkOrderRisk(order *models.Order, currentPosition *portfolio.PLEntry, workingOrders int, throttle bool) error {
+	// Deferred before the unlock so throttle handlers run without the lock
+	var notify func()
+	defer func() {
+		if notify != nil {
+			notify()
+		}
+	}()
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
 	if !rm.config.Risk.EnableRiskChecks {
 		return nil
 	}
+	limits := rm.limitsFor(order.Symbol)
 
-	// Reset daily PnL if it's a new day
-	if time.Since(rm.dailyPnLReset) > 24*time.Hour {
-		rm.resetDailyPnL()
+	// Strategies are benched while the loss streak breaker is tripped
+	if rm.lossBreakerTripped() && isStrategyOrigin(order.Origin) {
+		rm.log.Errorf("Loss streak breaker tripped, refusing %s order", order.Origin)
+		return fmt.Errorf("%w: %d losing trades in a row, strategy orders blocked %s",
+			ErrLossStreak, rm.lossStreak, rm.breakerRemaining())
+	}
+	if throttle && isStrategyOrigin(order.Origin) {
+		var err error
+		if notify, err = rm.checkThrottle(order.Origin); err != nil {
+			return err
+		}
 	}
 
 	// Check daily loss limit
-	if rm.dailyPnL <= -rm.config.Risk.DailyLossLimit {
+	if dailyPnL := rm.currentDailyPnL(); dailyPnL <= -rm.config.Risk.DailyLossLimit {
 		rm.log.Error("Daily loss limit reached")
-		return fmt.Errorf("daily loss limit of $%.2f reached (current: $%.2f)",
-			rm.config.Risk.DailyLossLimit, rm.dailyPnL)
+		return fmt.Errorf("%w (global) of $%.2f reached (current: $%.2f)",
+			ErrDailyLossLimit, rm.config.Risk.DailyLossLimit, dailyPnL)
+	}
+	if limits.dailyLoss > 0 && rm.portfolio != nil {
+		if symbolPnL := rm.symbolPnL(limits.scope); symbolPnL <= -limits.dailyLoss {
+			rm.log.Errorf("%s daily loss limit reached", limits.scope)
+			return fmt.Errorf("%w (%s) of $%.2f reached (current: $%.2f)",
+				ErrDailyLossLimit, limits.scope, limits.dailyLoss, symbolPnL)
+		}
+	}
+
+	// Check single order size
+	if limit := limits.maxOrderQty; limit > 0 && order.Quantity > limit {
+		rm.log.Errorf("Order quantity %d exceeds %s max order quantity %d", order.Quantity, limits.scope, limit)
+		return fmt.Errorf("%w: %d exceeds %s limit of %d", ErrMaxOrderQty, order.Quantity, limits.scope, limit)
+	}
+
+	// Check working order count
+	if limit := rm.config.Risk.MaxWorkingOrders; limit > 0 && workingOrders >= limit {
+		rm.log.Errorf("Working orders at limit: %d/%d", workingOrders, limit)
+		return fmt.Errorf("%w: %d working, limit %d", ErrMaxWorkingOrders, workingOrders, limit)
 	}
 
 	// Check max contracts
@@ -53,45 +120,177 @@ func (rm *RiskManager) CheckOrderRisk(order *models.Order, currentPosition *port
 	if order.Side == models.SideBuy {
 		// Current position + this new buy order
 		potentialMaxLong := currentQty + order.Quantity
-		if potentialMaxLong > rm.config.Risk.MaxContracts {
-			rm.log.Errorf("Order would exceed max contracts limit: %d (Potential Long: %d)", rm.config.Risk.MaxContracts, potentialMaxLong)
-			return fmt.Errorf("order would exceed max contracts limit of %d", rm.config.Risk.MaxContracts)
+		if potentialMaxLong > limits.maxContracts {
+			rm.log.Errorf("Order would exceed %s max contracts limit: %d (Potential Long: %d)", limits.scope, limits.maxContracts, potentialMaxLong)
+			return fmt.Errorf("%w: order would exceed %s limit of %d", ErrMaxContracts, limits.scope, limits.maxContracts)
 		}
 	} else { // SideSell
 		// Current position - this new sell order
 		// Note: order.Quantity are positive, so we subtract them
 		potentialMaxShort := currentQty - order.Quantity
-		if potentialMaxShort < -rm.config.Risk.MaxContracts {
-			rm.log.Errorf("Order would exceed max contracts limit: %d (Potential Short: %d)", rm.config.Risk.MaxContracts, potentialMaxShort)
-			return fmt.Errorf("order would exceed max contracts limit of %d", rm.config.Risk.MaxContracts)
+		if potentialMaxShort < -limits.maxContracts {
+			rm.log.Errorf("Order would exceed %s max contracts limit: %d (Potential Short: %d)", limits.scope, limits.maxContracts, potentialMaxShort)
+			return fmt.Errorf("%w: order would exceed %s limit of %d", ErrMaxContracts, limits.scope, limits.maxContracts)
 		}
 	}
 
+	if throttle && isStrategyOrigin(order.Origin) {
+		rm.recordStrategyOrder(order.Origin)
+	}
 	rm.log.Debugf("Risk check passed for order: %s %d %s", order.Side, order.Quantity, order.Symbol)
 	return nil
 }
 
-// IsDailyLossExceeded checks if the daily loss limit has been met
-func (rm *RiskManager) IsDailyLossExceeded(currentTotalPnL float64) bool {
-	rm.mu.RLock()
-	defer rm.mu.RUnlock()
-	return currentTotalPnL <= -rm.config.Risk.DailyLossLimit
+// limitsFor resolves the limits for symbol. Fields of a product's override left
+// at zero fall back to the global value. Caller must hold the lock.
+func (rm *RiskManager) limitsFor(symbol string) orderLimits {
+	global := orderLimits{
+		scope:        "global",
+		maxContracts: rm.config.Risk.MaxContracts,
+		maxOrderQty:  rm.config.Risk.MaxOrderQty,
+	}
+	if len(rm.config.Risk.Symbols) == 0 || symbol == "" {
+		return global
+	}
+
+	root := contracts.Root(symbol)
+	for key, override := range rm.config.Risk.Symbols {
+		if !strings.EqualFold(strings.TrimSpace(key), root) {
+			continue
+		}
+		limits := global
+		limits.scope = root
+		if override.MaxContracts > 0 {
+			limits.maxContracts = override.MaxContracts
+		}
+		if override.MaxOrderQty > 0 {
+			limits.maxOrderQty = override.MaxOrderQty
+		}
+		limits.dailyLoss = override.DailyLossLimit
+		return limits
+	}
+	return global
+}
+
+// symbolPnL is the trade date's PnL for one product root: its closed trades
+// plus the open PnL of its positions, after fees when DailyLossNet is set.
+// Caller must hold the lock and have a portfolio.
+func (rm *RiskManager) symbolPnL(root string) float64 {
+	var pnl float64
+	for _, trade := range rm.portfolio.GetClosedTrades() {
+		if contracts.Root(trade.Symbol) != root || !rm.closedToday(trade) {
+			continue
+		}
+		pnl += trade.PnL
+		if rm.config.Risk.DailyLossNet {
+			pnl -= trade.Fees
+		}
+	}
+	for name, entry := range rm.portfolio.GetPLSummary() {
+		if contracts.Root(name) == root {
+			pnl += entry.PL
+		}
+	}
+	return pnl
+}
+
+// tradeDateFees sums the fees of trades closed on the current trade date.
+// Caller must hold the lock and have a portfolio.
+func (rm *RiskManager) tradeDateFees() float64 {
+	var fees float64
+	for _, trade := range rm.portfolio.GetClosedTrades() {
+		if rm.closedToday(trade) {
+			fees += trade.Fees
+		}
+	}
+	return fees
+}
+
+// closedToday reports whether a trade closed on the current trade date. A trade
+// whose exit was not seen is counted rather than risk missing a loss.
+func (rm *RiskManager) closedToday(trade portfolio.ClosedTrade) bool {
+	return trade.ExitTime.IsZero() || rm.tradingDay.TradeDate(trade.ExitTime).Equal(rm.tradeDate)
+}
+
+// IsDailyLossExceeded checks if the daily loss limit has been met for the current
+// trade date. It uses the same value as the pre-order check.
+func (rm *RiskManager) IsDailyLossExceeded() bool {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.currentDailyPnL() <= -rm.config.Risk.DailyLossLimit
 }
 
-// UpdatePnL updates the daily PnL
+// currentDailyPnL rolls the trade date if needed and returns its PnL: Tradovate's
+// realized PnL plus open PnL when a portfolio is attached, less the fees of the
+// day's closed trades when DailyLossNet is set, else the local total. Caller
+// must hold the write lock.
+func (rm *RiskManager) currentDailyPnL() float64 {
+	rm.rollTradeDate()
+	if rm.portfolio == nil {
+		return rm.dailyPnL
+	}
+
+	realized := rm.portfolio.GetRealizedPnL()
+	// A balance reported for an earlier trade date has not been reset by Tradovate yet
+	if d := rm.portfolio.GetRealizedTradeDate(); d.Year != 0 {
+		reported := time.Date(d.Year, time.Month(d.Month), d.Day, 0, 0, 0, 0, time.UTC)
+		if reported.Before(rm.tradeDate) {
+			realized = 0
+		}
+	}
+	if rm.config.Risk.DailyLossNet {
+		realized -= rm.tradeDateFees()
+	}
+	return realized + rm.portfolio.GetTotalPL()
+}
+
+// rollTradeDate resets the daily counters once the trade date has changed.
+// Caller must hold the write lock.
+func (rm *RiskManager) rollTradeDate() {
+	if date := rm.tradingDay.TradeDate(rm.now()); !date.Equal(rm.tradeDate) {
+		rm.resetDailyPnL(date)
+	}
+}
+
+// SetPortfolio makes the daily PnL track the portfolio's cash balance realized
+// PnL and open PnL instead of the local total
+func (rm *RiskManager) SetPortfolio(pt *portfolio.PortfolioTracker) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.portfolio = pt
+}
+
+// SetClock replaces the time source used for trade date rolls (tests and backtests)
+func (rm *RiskManager) SetClock(now func() time.Time) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.now = now
+	rm.tradeDate = rm.tradingDay.TradeDate(now())
+}
+
+// GetTradeDate returns the current trade date as midnight UTC
+func (rm *RiskManager) GetTradeDate() time.Time {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.rollTradeDate()
+	return rm.tradeDate
+}
+
+// UpdatePnL adds to the locally accumulated daily PnL
 func (rm *RiskManager) UpdatePnL(pnl float64) {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
+	rm.rollTradeDate()
 	rm.dailyPnL += pnl
 	rm.log.Debugf("Daily PnL updated: $%.2f (change: $%.2f)", rm.dailyPnL, pnl)
 }
 
-// GetDailyPnL returns the current daily PnL
+// GetDailyPnL returns the current trade date's PnL
 func (rm *RiskManager) GetDailyPnL() float64 {
-	rm.mu.RLock()
-	defer rm.mu.RUnlock()
-	return rm.dailyPnL
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.currentDailyPnL()
 }
 
 // SetDailyPnL sets the daily PnL (e.g. from API sync)
@@ -105,21 +304,53 @@ func (rm *RiskManager) SetDailyPnL(pnl float64) {
 func (rm *RiskManager) IncrementTradeCount() {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
+	rm.rollTradeDate()
 	rm.tradeCount++
 	rm.log.Debugf("Trade count: %d", rm.tradeCount)
 }
 
 // GetTradeCount returns the daily trade count
 func (rm *RiskManager) GetTradeCount() int {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.rollTradeDate()
+	return rm.tradeCount
+}
+
+// UpdateConfig swaps the config used for risk checks. Only the Risk section is
+// expected to differ; credentials are applied by reconnecting.
+func (rm *RiskManager) UpdateConfig(cfg *config.Config) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if cfg.Risk.TradingDayRoll != rm.config.Risk.TradingDayRoll || cfg.Risk.TradingDayTimezone != rm.config.Risk.TradingDayTimezone {
+		rm.tradingDay = newTradingDay(cfg, rm.log)
+		rm.log.Infof("Trade date now rolls at %s", rm.tradingDay)
+	}
+	rm.config = cfg
+}
+
+// MaxOrderQty returns the largest single order the risk checks allow for
+// symbol, 0 when order size is not limited or the checks are off
+func (rm *RiskManager) MaxOrderQty(symbol string) int {
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
-	return rm.tradeCount
+	if !rm.config.Risk.EnableRiskChecks {
+		return 0
+	}
+	return rm.limitsFor(symbol).maxOrderQty
+}
+
+// GetConfig returns the config currently used for risk checks
+func (rm *RiskManager) GetConfig() *config.Config {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.config
 }
 
-// resetDailyPnL resets daily statistics (called at day start)
-func (rm *RiskManager) resetDailyPnL() {
+// resetDailyPnL resets daily statistics (called at the trade date roll)
+func (rm *RiskManager) resetDailyPnL(tradeDate time.Time) {
 	rm.dailyPnL = 0
 	rm.tradeCount = 0
-	rm.dailyPnLReset = time.Now()
-	rm.log.Info("Daily PnL and trade count reset")
+	rm.tradeDate = tradeDate
+	rm.log.Infof("Trade date %s: daily PnL and trade count reset", tradeDate.Format("2006-01-02"))
 }
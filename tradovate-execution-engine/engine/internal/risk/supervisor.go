@@ -0,0 +1,162 @@
+package risk
+
+import (
+	"sync"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// PositionFlattener closes every open position
+type PositionFlattener interface {
+	FlattenPositions() error
+}
+
+// StrategyStopper stops every running strategy and returns how many it stopped
+type StrategyStopper interface {
+	StopAllStrategies() int
+}
+
+// SupervisorState is the daily loss state as the RiskSupervisor last saw it
+type SupervisorState struct {
+	DailyPnL   float64   // Current trade date's PnL, as the pre-order check sees it
+	Limit      float64   // Risk.DailyLossLimit
+	Tripped    bool      // The limit is breached and the supervisor has acted on it
+	TrippedAt  time.Time // When the current breach tripped (zero when not tripped)
+	Stopped    int       // Strategies stopped when it tripped
+	FlattenErr error     // Why the flatten failed when it tripped, nil if it did not
+}
+
+// RiskSupervisor enforces the daily loss limit on its own goroutine. When the
+// limit is breached it stops every strategy and flattens every position, once
+// per breach: it acts again only after the breach has cleared, by the PnL
+// recovering or the trade date rolling. Handlers hear each trip and clear.
+type RiskSupervisor struct {
+	rm        *RiskManager
+	flattener PositionFlattener
+	stopper   StrategyStopper
+	log       *logger.Logger
+
+	mu       sync.Mutex
+	state    SupervisorState
+	tripping bool // A check is acting on a new breach
+	handlers []func(SupervisorState)
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewRiskSupervisor creates a supervisor for rm's daily loss limit that acts
+// through flattener and stopper. Call Start to run it.
+func NewRiskSupervisor(rm *RiskManager, flattener PositionFlattener, stopper StrategyStopper, log *logger.Logger) *RiskSupervisor {
+	return &RiskSupervisor{rm: rm, flattener: flattener, stopper: stopper, log: log}
+}
+
+// AddStateHandler registers a callback for each trip and clear. Handlers run on
+// the goroutine that ran the check.
+func (s *RiskSupervisor) AddStateHandler(handler func(SupervisorState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// Start checks the limit every interval until Stop. Starting a running
+// supervisor does nothing.
+func (s *RiskSupervisor) Start(interval time.Duration) {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	s.stop, s.done = stop, done
+	s.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.Check()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the goroutine started by Start and waits for a check in progress
+func (s *RiskSupervisor) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// State returns what the last check saw
+func (s *RiskSupervisor) State() SupervisorState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// Check compares the daily PnL with the limit now, trips when a new breach
+// starts and clears when it ends. It returns the resulting state.
+func (s *RiskSupervisor) Check() SupervisorState {
+	exceeded := s.rm.IsDailyLossExceeded()
+	pnl := s.rm.GetDailyPnL()
+	limit := s.rm.GetConfig().Risk.DailyLossLimit
+
+	s.mu.Lock()
+	s.state.DailyPnL, s.state.Limit = pnl, limit
+	switch {
+	case exceeded && !s.state.Tripped && !s.tripping:
+		// Claimed under the lock so a concurrent check cannot act on the same breach
+		s.tripping = true
+		s.mu.Unlock()
+		s.trip(pnl, limit)
+	case !exceeded && s.state.Tripped:
+		s.state = SupervisorState{DailyPnL: pnl, Limit: limit}
+		s.mu.Unlock()
+		s.log.Infof("Daily PnL $%.2f is back inside the $%.2f loss limit", pnl, limit)
+		s.publish()
+	default:
+		s.mu.Unlock()
+	}
+	return s.State()
+}
+
+// trip stops every strategy, then flattens so none can reopen a position
+func (s *RiskSupervisor) trip(pnl, limit float64) {
+	s.log.Errorf("Daily loss limit exceeded ($%.2f, limit $%.2f)! Stopping all strategies and flattening.", pnl, limit)
+	stopped := s.stopper.StopAllStrategies()
+	err := s.flattener.FlattenPositions()
+	if err != nil {
+		s.log.Errorf("Daily loss flatten failed: %v", err)
+	}
+
+	s.mu.Lock()
+	s.tripping = false
+	s.state.Tripped = true
+	s.state.TrippedAt = time.Now()
+	s.state.Stopped = stopped
+	s.state.FlattenErr = err
+	s.mu.Unlock()
+	s.publish()
+}
+
+// publish delivers the current state to every handler
+func (s *RiskSupervisor) publish() {
+	s.mu.Lock()
+	state, handlers := s.state, s.handlers
+	s.mu.Unlock()
+	for _, h := range handlers {
+		h(state)
+	}
+}
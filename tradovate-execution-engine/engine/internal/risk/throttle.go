@@ -0,0 +1,113 @@
+package risk
+
+import (
+	"fmt"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/metrics"
+)
+
+// Throttle limits used when the Risk config leaves them at zero
+const (
+	DefaultMaxStrategyOrdersPerMinute = 4
+	DefaultMinOrderInterval           = 10 * time.Second
+	DefaultMaxThrottleViolations      = 3
+)
+
+// AddThrottleHandler registers a callback for a strategy origin reaching
+// Risk.MaxThrottleViolations refused orders. It runs once per origin until
+// ResetOrderThrottle, on the goroutine that submitted the order, after the risk
+// manager's lock is released.
+func (rm *RiskManager) AddThrottleHandler(handler func(origin string, violations int)) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.throttleHandlers = append(rm.throttleHandlers, handler)
+}
+
+// ResetOrderThrottle forgets the order history and violations of origin, e.g.
+// when its strategy is started again
+func (rm *RiskManager) ResetOrderThrottle(origin string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	delete(rm.throttles, origin)
+}
+
+// ThrottleViolations returns how many orders of origin the throttle refused
+// since it was last reset
+func (rm *RiskManager) ThrottleViolations(origin string) int {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	if t, ok := rm.throttles[origin]; ok {
+		return t.violations
+	}
+	return 0
+}
+
+// checkThrottle refuses a strategy order that comes too soon after the last one
+// or too many within a minute. When the refusal reaches the violation limit it
+// also returns notify, which calls the throttle handlers and must be called once
+// the lock is released. Caller must hold the write lock.
+func (rm *RiskManager) checkThrottle(origin string) (notify func(), err error) {
+	now := rm.now()
+	t := rm.throttles[origin]
+	if t == nil {
+		return nil, nil
+	}
+	recent := t.sent[:0]
+	for _, at := range t.sent {
+		if now.Sub(at) < time.Minute {
+			recent = append(recent, at)
+		}
+	}
+	t.sent = recent
+
+	perMinute := throttleLimit(rm.config.Risk.MaxStrategyOrdersPerMinute, DefaultMaxStrategyOrdersPerMinute)
+	interval := time.Duration(throttleLimit(rm.config.Risk.MinOrderIntervalSeconds, int(DefaultMinOrderInterval/time.Second))) * time.Second
+	switch {
+	case perMinute > 0 && len(t.sent) >= perMinute:
+		err = fmt.Errorf("%w: %d orders in the last minute, limit %d", ErrOrderThrottle, len(t.sent), perMinute)
+	case interval > 0 && len(t.sent) > 0 && now.Sub(t.sent[len(t.sent)-1]) < interval:
+		err = fmt.Errorf("%w: %s since the last order, minimum %s", ErrOrderThrottle,
+			now.Sub(t.sent[len(t.sent)-1]).Round(time.Second), interval)
+	default:
+		return nil, nil
+	}
+
+	t.violations++
+	metrics.Default.Counter("engine_order_throttle_violations_total",
+		"Strategy orders refused for exceeding the order rate limits", "origin", origin).Inc()
+	rm.log.Errorf("Order throttle refused %s order (%d violations): %v", origin, t.violations, err)
+
+	limit := throttleLimit(rm.config.Risk.MaxThrottleViolations, DefaultMaxThrottleViolations)
+	if limit <= 0 || t.violations != limit {
+		return nil, err
+	}
+	handlers, violations := rm.throttleHandlers, t.violations
+	return func() {
+		for _, h := range handlers {
+			h(origin, violations)
+		}
+	}, err
+}
+
+// recordStrategyOrder notes an order of origin that passed the risk checks.
+// Caller must hold the write lock.
+func (rm *RiskManager) recordStrategyOrder(origin string) {
+	if rm.throttles == nil {
+		rm.throttles = make(map[string]*originThrottle)
+	}
+	t := rm.throttles[origin]
+	if t == nil {
+		t = &originThrottle{}
+		rm.throttles[origin] = t
+	}
+	t.sent = append(t.sent, rm.now())
+}
+
+// throttleLimit reads a throttle setting: zero is the default, negative is off
+func throttleLimit(configured, def int) int {
+	if configured == 0 {
+		return def
+	}
+	return configured
+}
@@ -1,19 +1,60 @@
 package risk
 
 import (
+	"errors"
 	"sync"
 	"time"
 	"tradovate-execution-engine/engine/config"
 
 	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/schedule"
 )
 
 // RiskManager handles risk checks and limits
 type RiskManager struct {
-	mu            sync.RWMutex
-	config        *config.Config
-	dailyPnL      float64
-	dailyPnLReset time.Time
-	tradeCount    int
-	log           *logger.Logger
+	mu         sync.RWMutex
+	config     *config.Config
+	dailyPnL   float64 // Locally accumulated PnL, used when no portfolio is attached
+	tradeDate  time.Time
+	tradingDay *schedule.TradingDay
+	tradeCount int
+	portfolio  *portfolio.PortfolioTracker // Source of realized and unrealized PnL (nil = dailyPnL)
+	now        func() time.Time
+	log        *logger.Logger
+
+	// Loss streak circuit breaker, fed by the portfolio's closed trades
+	lossStreak   int
+	streakSeen   map[int]bool // Closed trade IDs already counted
+	breakerOn    bool
+	breakerUntil time.Time // End of the cooldown (zero = until ResetLossBreaker)
+
+	// Strategy order throttle, by order origin
+	throttles        map[string]*originThrottle
+	throttleHandlers []func(origin string, violations int)
+}
+
+// originThrottle is the recent order history of one strategy origin
+type originThrottle struct {
+	sent       []time.Time // Orders that passed the risk checks in the last minute
+	violations int         // Orders refused for coming too fast since the last reset
 }
+
+// orderLimits are the limits that apply to one order and where they came from
+type orderLimits struct {
+	scope        string // "global", or the product root of a Risk.Symbols override
+	maxContracts int
+	maxOrderQty  int
+	dailyLoss    float64 // Product daily loss limit (0 = none)
+}
+
+// Risk rejections. CheckOrderRisk wraps one of these so the reason is both
+// readable in the order log and testable with errors.Is.
+var (
+	ErrDailyLossLimit   = errors.New("risk: daily loss limit")
+	ErrMaxContracts     = errors.New("risk: max contracts")
+	ErrMaxWorkingOrders = errors.New("risk: too many working orders")
+	ErrMaxOrderQty      = errors.New("risk: max order quantity")
+	ErrLossStreak       = errors.New("risk: consecutive loss limit")
+	ErrOrderThrottle    = errors.New("risk: strategy order rate")
+)
@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+)
+
+// DefaultMaintenanceTimezone is used when the maintenance config names none
+const DefaultMaintenanceTimezone = "America/Chicago"
+
+// NewMaintenanceWindows parses the maintenance windows of environment. An
+// environment without windows is never in maintenance.
+func NewMaintenanceWindows(cfg config.MaintenanceConfig, environment config.Environment) (*MaintenanceWindows, error) {
+	specs := cfg.Windows[string(environment)]
+	if len(specs) == 0 {
+		return &MaintenanceWindows{}, nil
+	}
+
+	tz := cfg.Timezone
+	if tz == "" {
+		tz = DefaultMaintenanceTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance timezone %q: %w", cfg.Timezone, err)
+	}
+
+	m := &MaintenanceWindows{loc: loc}
+	for _, spec := range specs {
+		from, to, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, fmt.Errorf("maintenance window %q is not HH:MM-HH:MM", spec)
+		}
+		start, err := parseClock(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window start: %w", err)
+		}
+		end, err := parseClock(strings.TrimSpace(to))
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window end: %w", err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("maintenance window %q is empty", spec)
+		}
+		m.windows = append(m.windows, clockWindow{start: start, end: end})
+	}
+	m.label = fmt.Sprintf("%s %s", strings.Join(specs, ", "), tz)
+	return m, nil
+}
+
+// Enabled reports whether there are any windows
+func (m *MaintenanceWindows) Enabled() bool {
+	return m != nil && len(m.windows) > 0
+}
+
+// String lists the windows, e.g. "16:00-17:00 America/Chicago"
+func (m *MaintenanceWindows) String() string {
+	if !m.Enabled() {
+		return "none"
+	}
+	return m.label
+}
+
+// Active reports whether t falls inside a window
+func (m *MaintenanceWindows) Active(t time.Time) bool {
+	if !m.Enabled() {
+		return false
+	}
+	offset := sinceMidnight(t.In(m.loc))
+	for _, w := range m.windows {
+		if w.start < w.end && offset >= w.start && offset < w.end {
+			return true
+		}
+		if w.start > w.end && (offset >= w.start || offset < w.end) {
+			return true
+		}
+	}
+	return false
+}
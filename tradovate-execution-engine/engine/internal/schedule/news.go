@@ -0,0 +1,134 @@
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+)
+
+// DefaultNewsTimezone is used when the news config names none
+const DefaultNewsTimezone = "America/New_York"
+
+// newsTimeLayout is how news event times are written
+const newsTimeLayout = "2006-01-02 15:04"
+
+// NewNewsCalendar parses the events of cfg and of its calendar file, which is
+// looked up in dir unless its path is absolute. A disabled config has no
+// windows.
+func NewNewsCalendar(cfg config.NewsConfig, dir string) (*NewsCalendar, error) {
+	if !cfg.Enabled {
+		return &NewsCalendar{}, nil
+	}
+	if cfg.LeadMinutes < 0 {
+		return nil, fmt.Errorf("news leadMinutes cannot be negative, got %d", cfg.LeadMinutes)
+	}
+
+	tz := cfg.Timezone
+	if tz == "" {
+		tz = DefaultNewsTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid news timezone %q: %w", cfg.Timezone, err)
+	}
+
+	events := cfg.Events
+	if cfg.CalendarFile != "" {
+		path := cfg.CalendarFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("news calendar: %w", err)
+		}
+		var fromFile []config.NewsEvent
+		if err := json.Unmarshal(data, &fromFile); err != nil {
+			return nil, fmt.Errorf("news calendar %s: %w", path, err)
+		}
+		events = append(append([]config.NewsEvent(nil), events...), fromFile...)
+	}
+
+	c := &NewsCalendar{lead: time.Duration(cfg.LeadMinutes) * time.Minute, loc: loc}
+	for _, ev := range events {
+		start, err := time.ParseInLocation(newsTimeLayout, ev.Start, loc)
+		if err != nil {
+			return nil, fmt.Errorf("news event %q start %q is not YYYY-MM-DD HH:MM", ev.Name, ev.Start)
+		}
+		end, err := time.ParseInLocation(newsTimeLayout, ev.End, loc)
+		if err != nil {
+			return nil, fmt.Errorf("news event %q end %q is not YYYY-MM-DD HH:MM", ev.Name, ev.End)
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("news event %q ends at or before it starts", ev.Name)
+		}
+		c.windows = append(c.windows, NewsWindow{Name: ev.Name, Lock: start.Add(-c.lead), Start: start, End: end})
+	}
+	sort.SliceStable(c.windows, func(i, j int) bool { return c.windows[i].Lock.Before(c.windows[j].Lock) })
+	return c, nil
+}
+
+// Enabled reports whether there are any windows
+func (c *NewsCalendar) Enabled() bool {
+	return c != nil && len(c.windows) > 0
+}
+
+// String summarises the calendar, e.g. "3 events, locked 5m before each, America/New_York"
+func (c *NewsCalendar) String() string {
+	if !c.Enabled() {
+		return "none"
+	}
+	return fmt.Sprintf("%d events, locked %s before each, %s", len(c.windows), c.lead, c.loc)
+}
+
+// Active returns the lockout t falls in. Overlapping lockouts are joined into
+// one, named after the first and lasting until the last ends.
+func (c *NewsCalendar) Active(t time.Time) (NewsWindow, bool) {
+	var active NewsWindow
+	found := false
+	for _, w := range c.windowsFrom(t) {
+		switch {
+		case !found && !t.Before(w.Lock) && t.Before(w.End):
+			active, found = w, true
+		case found && !w.Lock.After(active.End):
+			if w.End.After(active.End) {
+				active.End = w.End
+			}
+		}
+	}
+	return active, found
+}
+
+// Next returns the first lockout that has not begun by t
+func (c *NewsCalendar) Next(t time.Time) (NewsWindow, bool) {
+	for _, w := range c.windowsFrom(t) {
+		if w.Lock.After(t) {
+			return w, true
+		}
+	}
+	return NewsWindow{}, false
+}
+
+// windowsFrom returns the windows that have not ended by t
+func (c *NewsCalendar) windowsFrom(t time.Time) []NewsWindow {
+	if !c.Enabled() {
+		return nil
+	}
+	var open []NewsWindow
+	for _, w := range c.windows {
+		if t.Before(w.End) {
+			open = append(open, w)
+		}
+	}
+	return open
+}
+
+// String describes the window, e.g. "CPI 08:30-08:45 (locked from 08:25)"
+func (w NewsWindow) String() string {
+	return fmt.Sprintf("%s %s-%s (locked from %s)", w.Name, w.Start.Format("15:04"), w.End.Format("15:04"), w.Lock.Format("15:04"))
+}
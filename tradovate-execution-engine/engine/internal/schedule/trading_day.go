@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultTradingDayTimezone = "America/Chicago"
+	DefaultTradingDayRoll     = "17:00"
+)
+
+// NewTradingDay builds the trade date calendar for an exchange timezone and roll
+// time. Empty values use the CME defaults (17:00 America/Chicago).
+func NewTradingDay(timezone, roll string) (*TradingDay, error) {
+	if timezone == "" {
+		timezone = DefaultTradingDayTimezone
+	}
+	if roll == "" {
+		roll = DefaultTradingDayRoll
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trading day timezone %q: %w", timezone, err)
+	}
+	offset, err := parseClock(roll)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trading day roll: %w", err)
+	}
+	return &TradingDay{
+		loc:   loc,
+		roll:  offset,
+		label: fmt.Sprintf("%s %s", roll, timezone),
+	}, nil
+}
+
+// TradeDate returns the trade date t belongs to, as midnight UTC of that date.
+// Time at or after the roll belongs to the next calendar day, so Sunday 17:00 CT
+// starts Monday's trade date.
+func (d *TradingDay) TradeDate(t time.Time) time.Time {
+	local := t.In(d.loc)
+	if sinceMidnight(local) >= d.roll {
+		local = local.AddDate(0, 0, 1)
+	}
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// NextRoll returns the first roll strictly after t
+func (d *TradingDay) NextRoll(t time.Time) time.Time {
+	local := t.In(d.loc)
+	hour, minute := int(d.roll/time.Hour), int(d.roll%time.Hour/time.Minute)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, d.loc)
+	if !next.After(t) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, hour, minute, 0, 0, d.loc)
+	}
+	return next
+}
+
+// String describes the roll, e.g. "17:00 America/Chicago"
+func (d *TradingDay) String() string {
+	return d.label
+}
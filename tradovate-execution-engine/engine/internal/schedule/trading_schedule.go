@@ -0,0 +1,116 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // Timezones are available even without a system zoneinfo database
+	"tradovate-execution-engine/engine/config"
+)
+
+// NewTradingSchedule parses the schedule config. A disabled schedule is always in session.
+func NewTradingSchedule(cfg config.ScheduleConfig) (*TradingSchedule, error) {
+	if !cfg.Enabled {
+		return &TradingSchedule{}, nil
+	}
+
+	tz := cfg.Timezone
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule timezone %q: %w", cfg.Timezone, err)
+	}
+
+	start, err := parseClock(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule start: %w", err)
+	}
+	end, err := parseClock(cfg.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule end: %w", err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("schedule start and end are both %s", cfg.Start)
+	}
+
+	s := &TradingSchedule{
+		enabled: true,
+		start:   start,
+		end:     end,
+		loc:     loc,
+		label:   fmt.Sprintf("%s-%s %s", cfg.Start, cfg.End, tz),
+	}
+	if cfg.FlattenAt != "" {
+		if s.flattenAt, err = parseClock(cfg.FlattenAt); err != nil {
+			return nil, fmt.Errorf("invalid schedule flattenAt: %w", err)
+		}
+		s.hasFlatten = true
+	}
+	return s, nil
+}
+
+// parseClock parses "HH:MM" into an offset from midnight
+func parseClock(v string) (time.Duration, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", v)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Enabled reports whether the schedule restricts trading
+func (s *TradingSchedule) Enabled() bool {
+	return s != nil && s.enabled
+}
+
+// String describes the window, e.g. "09:30-16:00 America/New_York"
+func (s *TradingSchedule) String() string {
+	if !s.Enabled() {
+		return "always"
+	}
+	return s.label
+}
+
+// InSession reports whether t falls inside the window. Windows whose end is
+// before their start run overnight.
+func (s *TradingSchedule) InSession(t time.Time) bool {
+	if !s.Enabled() {
+		return true
+	}
+	offset := sinceMidnight(t.In(s.loc))
+	if s.start < s.end {
+		return offset >= s.start && offset < s.end
+	}
+	return offset >= s.start || offset < s.end
+}
+
+// FlattenDue reports whether the flatten time passed in (prev, now]
+func (s *TradingSchedule) FlattenDue(prev, now time.Time) bool {
+	if !s.Enabled() || !s.hasFlatten || prev.IsZero() || !now.After(prev) {
+		return false
+	}
+	local := now.In(s.loc)
+	hour, minute := int(s.flattenAt/time.Hour), int(s.flattenAt%time.Hour/time.Minute)
+	// Check today's and yesterday's cutoff so a gap spanning midnight is not missed
+	for _, daysAgo := range []int{0, 1} {
+		day := local.AddDate(0, 0, -daysAgo)
+		cutoff := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, s.loc)
+		if cutoff.After(prev) && !cutoff.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOrder returns an error when an order at t is outside the window
+func (s *TradingSchedule) CheckOrder(t time.Time) error {
+	if s.InSession(t) {
+		return nil
+	}
+	return fmt.Errorf("schedule: outside trading hours (%s)", s.label)
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
@@ -0,0 +1,67 @@
+package schedule
+
+import "time"
+
+//
+// TRADING SCHEDULE
+//
+
+// TradingSchedule is a daily session window in a fixed timezone
+type TradingSchedule struct {
+	enabled    bool
+	start      time.Duration // Offset from local midnight
+	end        time.Duration
+	flattenAt  time.Duration
+	hasFlatten bool
+	loc        *time.Location
+	label      string // e.g. "09:30-16:00 America/New_York"
+}
+
+//
+// TRADING DAY
+//
+
+// TradingDay maps instants to exchange trade dates, which start at a daily roll
+type TradingDay struct {
+	loc   *time.Location
+	roll  time.Duration // Offset from local midnight
+	label string        // e.g. "17:00 America/Chicago"
+}
+
+//
+// MAINTENANCE WINDOWS
+//
+
+// MaintenanceWindows are the daily windows in which Tradovate takes an
+// environment down
+type MaintenanceWindows struct {
+	windows []clockWindow
+	loc     *time.Location
+	label   string // e.g. "16:00-17:00 America/Chicago"
+}
+
+// clockWindow is a daily window as offsets from local midnight. One whose end
+// is before its start runs over midnight.
+type clockWindow struct {
+	start, end time.Duration
+}
+
+//
+// NEWS CALENDAR
+//
+
+// NewsCalendar is the list of economic releases to stay flat through
+type NewsCalendar struct {
+	windows []NewsWindow // By Lock time
+	lead    time.Duration
+	loc     *time.Location
+}
+
+// NewsWindow is one release as the engine locks out trading for it: from Lock,
+// the lead time before Start, until End
+type NewsWindow struct {
+	Name  string
+	Lock  time.Time
+	Start time.Time
+	End   time.Time
+}
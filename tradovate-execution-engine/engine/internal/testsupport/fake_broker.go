@@ -0,0 +1,326 @@
+package testsupport
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+)
+
+// FakeBroker is a recording execution.Broker for strategy tests. Orders it is
+// given rest as Submitted until Fill or Reject, or fill at once at the price
+// set with SetPrice after SetAutoFill. Nothing is sent anywhere.
+type FakeBroker struct {
+	mu           sync.Mutex
+	orders       []*models.Order
+	nextID       int
+	byID         map[string]*models.Order
+	cancelled    []string
+	listeners    []func(models.Order)
+	positions    map[string]portfolio.PLEntry
+	tickSizes    map[string]float64
+	dailyPnL     float64
+	maxContracts int
+	maxOrderQty  int
+	submitErr    error
+	autoFill     bool
+	price        float64
+}
+
+// NewFakeBroker creates a broker with no limit on contracts and a tick size of 0.25
+func NewFakeBroker() *FakeBroker {
+	return &FakeBroker{
+		byID:      make(map[string]*models.Order),
+		positions: make(map[string]portfolio.PLEntry),
+		tickSizes: make(map[string]float64),
+	}
+}
+
+// SubmitOrder records the order and returns it Submitted, or Filled when auto fill is on
+func (f *FakeBroker) SubmitOrder(symbol string, side models.OrderSide, quantity int, opts execution.OrderOptions) (*models.Order, error) {
+	f.mu.Lock()
+	if f.submitErr != nil {
+		err := f.submitErr
+		f.mu.Unlock()
+		return nil, err
+	}
+	orderType := opts.Type
+	if orderType == "" {
+		orderType = models.TypeMarket
+	}
+	f.nextID++
+	order := &models.Order{
+		ID:          fmt.Sprintf("fake-%d", f.nextID),
+		Symbol:      symbol,
+		Side:        side,
+		Type:        orderType,
+		Quantity:    quantity,
+		Price:       opts.Price,
+		StopPrice:   opts.StopPrice,
+		TimeInForce: opts.TimeInForce,
+		Status:      models.StatusSubmitted,
+		SubmittedAt: time.Now(),
+		SignalAt:    opts.SignalAt,
+		Origin:      opts.Origin,
+	}
+	f.orders = append(f.orders, order)
+	f.byID[order.ID] = order
+	autoFill, price := f.autoFill, f.price
+	f.mu.Unlock()
+
+	if autoFill {
+		f.Fill(order.ID, price)
+	}
+	return f.snapshot(order.ID), nil
+}
+
+// SubmitMarketOrder records a market order
+func (f *FakeBroker) SubmitMarketOrder(symbol string, side models.OrderSide, quantity int) (*models.Order, error) {
+	return f.SubmitOrder(symbol, side, quantity, execution.OrderOptions{})
+}
+
+// SubmitLimitOrder records a limit order at price
+func (f *FakeBroker) SubmitLimitOrder(symbol string, side models.OrderSide, quantity int, price float64) (*models.Order, error) {
+	return f.SubmitOrder(symbol, side, quantity, execution.OrderOptions{Type: models.TypeLimit, Price: price})
+}
+
+// CancelOrder records the cancel and cancels a resting order
+func (f *FakeBroker) CancelOrder(orderID string) error {
+	f.mu.Lock()
+	order, ok := f.byID[orderID]
+	if !ok {
+		f.mu.Unlock()
+		return fmt.Errorf("order %s not found", orderID)
+	}
+	f.cancelled = append(f.cancelled, orderID)
+	f.mu.Unlock()
+	return f.settle(order.ID, models.StatusCanceled, "", nil)
+}
+
+// GetOrder returns a copy of an order
+func (f *FakeBroker) GetOrder(orderID string) (*models.Order, bool) {
+	order := f.snapshot(orderID)
+	return order, order != nil
+}
+
+// OrderSnapshot returns a copy of an order
+func (f *FakeBroker) OrderSnapshot(orderID string) (models.Order, bool) {
+	order := f.snapshot(orderID)
+	if order == nil {
+		return models.Order{}, false
+	}
+	return *order, true
+}
+
+// AddOrderListener registers a listener for the orders' status changes
+func (f *FakeBroker) AddOrderListener(listener func(models.Order)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listeners = append(f.listeners, listener)
+}
+
+// GetPosition returns the position set with SetPosition
+func (f *FakeBroker) GetPosition(symbol string) portfolio.PLEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.positions[symbol]
+}
+
+// GetDailyPnL returns the PnL set with SetDailyPnL
+func (f *FakeBroker) GetDailyPnL() float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.dailyPnL
+}
+
+// ResolveSymbol returns the symbol unchanged
+func (f *FakeBroker) ResolveSymbol(symbol string) (string, error) {
+	return symbol, nil
+}
+
+// GetTickSize returns the tick size set for symbol, 0.25 if none was
+func (f *FakeBroker) GetTickSize(symbol string) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if tick, ok := f.tickSizes[symbol]; ok {
+		return tick
+	}
+	return 0.25
+}
+
+// MaxContracts returns the limit set with SetMaxContracts, enforced when above 0
+func (f *FakeBroker) MaxContracts() (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.maxContracts, f.maxContracts > 0
+}
+
+// MaxOrderQty returns the limit set with SetMaxOrderQty, enforced when above 0
+func (f *FakeBroker) MaxOrderQty(symbol string) (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.maxOrderQty, f.maxOrderQty > 0
+}
+
+// SetPosition sets what GetPosition returns for entry.Name
+func (f *FakeBroker) SetPosition(entry portfolio.PLEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.positions[entry.Name] = entry
+}
+
+// SetDailyPnL sets what GetDailyPnL returns
+func (f *FakeBroker) SetDailyPnL(pnl float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dailyPnL = pnl
+}
+
+// SetTickSize sets the tick size of symbol; 0 makes it unknown
+func (f *FakeBroker) SetTickSize(symbol string, tick float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tickSizes[symbol] = tick
+}
+
+// SetMaxContracts enforces a limit on contracts per order; 0 lifts it
+func (f *FakeBroker) SetMaxContracts(limit int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maxContracts = limit
+}
+
+// SetMaxOrderQty enforces a limit on the size of one order; 0 lifts it
+func (f *FakeBroker) SetMaxOrderQty(limit int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maxOrderQty = limit
+}
+
+// FailSubmits makes every submit fail with err, unrecorded, until called with nil
+func (f *FakeBroker) FailSubmits(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submitErr = err
+}
+
+// SetAutoFill fills each order at the last SetPrice as it is submitted
+func (f *FakeBroker) SetAutoFill(auto bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.autoFill = auto
+}
+
+// SetPrice sets the price auto filled orders fill at
+func (f *FakeBroker) SetPrice(price float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.price = price
+}
+
+// Fill fills a resting order in full at price and tells the listeners
+func (f *FakeBroker) Fill(orderID string, price float64) error {
+	f.mu.Lock()
+	order, ok := f.byID[orderID]
+	var fill *models.Fill
+	if ok {
+		fill = &models.Fill{ID: orderID + "-fill", Quantity: order.Quantity, Price: price, Timestamp: time.Now()}
+	}
+	f.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("order %s not found", orderID)
+	}
+	return f.settle(orderID, models.StatusFilled, "", fill)
+}
+
+// Reject rejects a resting order with reason and tells the listeners
+func (f *FakeBroker) Reject(orderID, reason string) error {
+	return f.settle(orderID, models.StatusRejected, reason, nil)
+}
+
+// Expire ends a resting order as its time in force running out would
+func (f *FakeBroker) Expire(orderID string) error {
+	return f.settle(orderID, models.StatusExpired, "", nil)
+}
+
+// settle ends a resting order with status, then tells the listeners outside the lock
+func (f *FakeBroker) settle(orderID string, status models.OrderStatus, reason string, fill *models.Fill) error {
+	f.mu.Lock()
+	order, ok := f.byID[orderID]
+	if !ok {
+		f.mu.Unlock()
+		return fmt.Errorf("order %s not found", orderID)
+	}
+	if order.Status != models.StatusSubmitted {
+		f.mu.Unlock()
+		return fmt.Errorf("order %s is %s", orderID, order.Status)
+	}
+	order.Status = status
+	order.RejectReason = reason
+	if fill != nil {
+		order.Fills = append(order.Fills, *fill)
+		order.FilledAt = fill.Timestamp
+	}
+	updated := *order
+	updated.Fills = append([]models.Fill(nil), order.Fills...)
+	listeners := append([]func(models.Order){}, f.listeners...)
+	f.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(updated)
+	}
+	return nil
+}
+
+// snapshot returns a copy of an order, nil if it is unknown
+func (f *FakeBroker) snapshot(orderID string) *models.Order {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.byID[orderID]
+	if !ok {
+		return nil
+	}
+	copied := *order
+	copied.Fills = append([]models.Fill(nil), order.Fills...)
+	return &copied
+}
+
+// Orders returns copies of every order submitted so far, oldest first
+func (f *FakeBroker) Orders() []models.Order {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	orders := make([]models.Order, 0, len(f.orders))
+	for _, order := range f.orders {
+		copied := *order
+		copied.Fills = append([]models.Fill(nil), order.Fills...)
+		orders = append(orders, copied)
+	}
+	return orders
+}
+
+// LastOrder returns the most recent order, false if there are none
+func (f *FakeBroker) LastOrder() (models.Order, bool) {
+	orders := f.Orders()
+	if len(orders) == 0 {
+		return models.Order{}, false
+	}
+	return orders[len(orders)-1], true
+}
+
+// Cancelled returns the IDs of every order cancelled so far, oldest first
+func (f *FakeBroker) Cancelled() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.cancelled...)
+}
+
+// Clear forgets the orders submitted and cancelled so far; order IDs keep counting
+func (f *FakeBroker) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancelled = nil
+	f.orders = nil
+}
@@ -0,0 +1,290 @@
+package testsupport
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// Sent is one message sent through a FakeSender
+type Sent struct {
+	RequestID int
+	URL       string
+	Body      interface{}
+}
+
+// JSON returns the body as the WebSocket would carry it
+func (s Sent) JSON() string {
+	data, err := json.Marshal(s.Body)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Decode unmarshals the body as the WebSocket would carry it into v
+func (s Sent) Decode(v interface{}) error {
+	data, err := json.Marshal(s.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Reply is how a FakeSender answers a request: a response with Data, or a
+// refusal when Status is an error status
+type Reply struct {
+	Status int // 0 or 200 answer with Data; anything else refuses with Text
+	Text   string
+	Data   json.RawMessage
+}
+
+// Confirm answers a request with an empty response, as Tradovate confirms a subscription
+func Confirm() Reply {
+	return Reply{Data: json.RawMessage(`{}`)}
+}
+
+// Respond answers a request with data
+func Respond(data string) Reply {
+	return Reply{Data: json.RawMessage(data)}
+}
+
+// Refuse answers a request with an error status and Tradovate's reason
+func Refuse(status int, text string) Reply {
+	return Reply{Status: status, Text: text}
+}
+
+// pendingReply is a reply not yet delivered
+type pendingReply struct {
+	requestID int
+	url       string
+	reply     Reply
+}
+
+// FakeSender is a scripted marketdata.WebSocketSender and RequestSender. It
+// records every message, hands out request IDs from 1 and answers requests as
+// scripted with Answer. Answers are queued until Deliver, or delivered from
+// their own goroutine after SetAutoDeliver, as the WebSocket reader would.
+type FakeSender struct {
+	mu        sync.Mutex
+	sent      []Sent
+	nextID    int
+	connected bool
+	sendErr   error
+	auto      bool
+	script    map[string]func(Sent) Reply
+	pending   []pendingReply
+
+	onEvent        func(eventType string, data json.RawMessage)
+	onResponse     func(requestID int, url string, data json.RawMessage)
+	onRequestError func(requestID int, url string, status int, text string)
+}
+
+// NewFakeSender creates a connected sender that answers nothing
+func NewFakeSender() *FakeSender {
+	return &FakeSender{
+		connected: true,
+		script:    make(map[string]func(Sent) Reply),
+	}
+}
+
+// Receiver takes what a FakeSender delivers, as a tradovate.DataSubscriber does
+type Receiver interface {
+	HandleEvent(eventType string, data json.RawMessage)
+	HandleResponse(requestID int, url string, data json.RawMessage)
+	HandleRequestError(requestID int, url string, status int, text string)
+}
+
+// Attach delivers events, responses and refusals to r
+func (f *FakeSender) Attach(r Receiver) {
+	f.SetMessageHandler(r.HandleEvent)
+	f.SetResponseHandler(r.HandleResponse)
+	f.SetRequestErrorHandler(r.HandleRequestError)
+}
+
+// SetMessageHandler sets the callback for events
+func (f *FakeSender) SetMessageHandler(handler func(eventType string, data json.RawMessage)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onEvent = handler
+}
+
+// SetResponseHandler sets the callback for responses to requests
+func (f *FakeSender) SetResponseHandler(handler func(requestID int, url string, data json.RawMessage)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onResponse = handler
+}
+
+// SetRequestErrorHandler sets the callback for refused requests
+func (f *FakeSender) SetRequestErrorHandler(handler func(requestID int, url string, status int, text string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onRequestError = handler
+}
+
+// Answer scripts the reply to each request sent to url. A nil answer leaves
+// them unanswered.
+func (f *FakeSender) Answer(url string, answer func(Sent) Reply) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if answer == nil {
+		delete(f.script, url)
+		return
+	}
+	f.script[url] = answer
+}
+
+// AnswerAll scripts the same reply to every request sent to url
+func (f *FakeSender) AnswerAll(url string, reply Reply) {
+	f.Answer(url, func(Sent) Reply { return reply })
+}
+
+// SetAutoDeliver delivers each answer from its own goroutine as soon as the
+// request is sent, for calls that wait on their response
+func (f *FakeSender) SetAutoDeliver(auto bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auto = auto
+}
+
+// FailSends makes every send fail with err until called with nil
+func (f *FakeSender) FailSends(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sendErr = err
+}
+
+// SetConnected sets what IsConnected reports
+func (f *FakeSender) SetConnected(connected bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = connected
+}
+
+// IsConnected reports whether the sender is connected
+func (f *FakeSender) IsConnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connected
+}
+
+// Connect connects the sender
+func (f *FakeSender) Connect() error {
+	f.SetConnected(true)
+	return nil
+}
+
+// Send records a message without returning its request ID
+func (f *FakeSender) Send(url string, body interface{}) error {
+	_, err := f.SendRequest(url, body)
+	return err
+}
+
+// SendRequest records a message and returns its request ID, queueing the
+// scripted answer. A failed send is not recorded.
+func (f *FakeSender) SendRequest(url string, body interface{}) (int, error) {
+	f.mu.Lock()
+	if f.sendErr != nil {
+		err := f.sendErr
+		f.mu.Unlock()
+		return 0, err
+	}
+	if !f.connected {
+		f.mu.Unlock()
+		return 0, errors.New("fake sender not connected")
+	}
+	f.nextID++
+	msg := Sent{RequestID: f.nextID, URL: url, Body: body}
+	f.sent = append(f.sent, msg)
+	answer, auto := f.script[url], f.auto
+	f.mu.Unlock()
+
+	if answer == nil {
+		return msg.RequestID, nil
+	}
+	// Answered after the send returns, as the reader would, never inside it
+	p := pendingReply{requestID: msg.RequestID, url: url, reply: answer(msg)}
+	if auto {
+		go f.deliver(p)
+	} else {
+		f.mu.Lock()
+		f.pending = append(f.pending, p)
+		f.mu.Unlock()
+	}
+	return msg.RequestID, nil
+}
+
+// Deliver delivers the queued answers in the order their requests were sent
+// and returns how many there were
+func (f *FakeSender) Deliver() int {
+	f.mu.Lock()
+	pending := f.pending
+	f.pending = nil
+	f.mu.Unlock()
+	for _, p := range pending {
+		f.deliver(p)
+	}
+	return len(pending)
+}
+
+// deliver hands one answer to the response or request error handler
+func (f *FakeSender) deliver(p pendingReply) {
+	f.mu.Lock()
+	onResponse, onRequestError := f.onResponse, f.onRequestError
+	f.mu.Unlock()
+
+	if p.reply.Status != 0 && p.reply.Status != 200 {
+		if onRequestError != nil {
+			onRequestError(p.requestID, p.url, p.reply.Status, p.reply.Text)
+		}
+		return
+	}
+	if onResponse != nil {
+		onResponse(p.requestID, p.url, p.reply.Data)
+	}
+}
+
+// Event delivers an event to the message handler, as if Tradovate pushed it
+func (f *FakeSender) Event(eventType string, data string) {
+	f.mu.Lock()
+	onEvent := f.onEvent
+	f.mu.Unlock()
+	if onEvent != nil {
+		onEvent(eventType, json.RawMessage(data))
+	}
+}
+
+// Sent returns every message sent so far, oldest first
+func (f *FakeSender) Sent() []Sent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Sent(nil), f.sent...)
+}
+
+// SentTo returns the messages sent to url, oldest first
+func (f *FakeSender) SentTo(url string) []Sent {
+	var matched []Sent
+	for _, msg := range f.Sent() {
+		if msg.URL == url {
+			matched = append(matched, msg)
+		}
+	}
+	return matched
+}
+
+// URLs returns the URL of every message sent so far, oldest first
+func (f *FakeSender) URLs() []string {
+	var urls []string
+	for _, msg := range f.Sent() {
+		urls = append(urls, msg.URL)
+	}
+	return urls
+}
+
+// Clear forgets the messages sent so far; request IDs keep counting
+func (f *FakeSender) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = nil
+}
@@ -0,0 +1,166 @@
+package tradovate
+
+import (
+	"context"
+	"fmt"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// Error returns a message naming the symbol and Tradovate's reason
+func (e *ChartError) Error() string {
+	text := e.Text
+	if text == "" {
+		text = fmt.Sprintf("status %d", e.Status)
+	}
+	return fmt.Sprintf("historical data request failed for %s: %s", e.Symbol, text)
+}
+
+// Unwrap returns the refusal as a *WSError, so every refused request can be
+// matched with errors.As
+func (e *ChartError) Unwrap() error {
+	return &WSError{RequestID: e.RequestID, URL: chartEndpoint, Status: e.Status, StatusText: e.Text, Symbols: []string{e.Symbol}}
+}
+
+// GetChart requests chart data (historical and/or live) without waiting for the
+// response. Its bars reach the chart handlers registered for every symbol; a
+// refusal is logged and passed to the chart error handlers.
+// Note: This is NOT a subscription, it's a one-time data request
+func (s *DataSubscriber) GetChart(params marketdata.HistoricalDataParams) error {
+	_, err := s.sendChartRequest(params)
+	return err
+}
+
+// GetChartAndWait requests chart data like GetChart and waits for Tradovate to
+// accept or refuse it. A refusal is returned as a *ChartError; the bars still
+// arrive through the chart handlers afterwards. It gives up when ctx is done.
+func (s *DataSubscriber) GetChartAndWait(ctx context.Context, params marketdata.HistoricalDataParams) error {
+	req, err := s.sendChartRequest(params)
+	if err != nil {
+		return err
+	}
+	if req == nil {
+		return fmt.Errorf("waiting for a chart needs a connection that tracks request IDs")
+	}
+
+	select {
+	case err := <-req.done:
+		return err
+	case <-ctx.Done():
+		s.mu.Lock()
+		for id, pending := range s.pendingGets {
+			if pending == req {
+				delete(s.pendingGets, id)
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// sendChartRequest sends an md/getchart and, when the connection returns
+// request IDs, tracks it until its response. The returned request is nil when
+// it cannot be tracked.
+func (s *DataSubscriber) sendChartRequest(params marketdata.HistoricalDataParams) (*chartRequest, error) {
+	rs, ok := s.client.(marketdata.RequestSender)
+	if !ok {
+		if err := s.client.Send(chartEndpoint, params); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	req := &chartRequest{symbol: fmt.Sprint(params.Symbol), done: make(chan error, 1)}
+	// Held across the send so the response cannot be handled before the request is pending
+	s.mu.Lock()
+	requestID, err := rs.SendRequest(chartEndpoint, params)
+	if err == nil {
+		s.pendingGets[requestID] = req
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.log != nil {
+		s.log.Debugf("Requested chart data for %v (request %d)", params.Symbol, requestID)
+	}
+	return req, nil
+}
+
+// failChart ends the history page, GetChart or chart subscription waiting on
+// requestID with Tradovate's reason. A refused subscription is dropped so a
+// later SubscribeChart asks again.
+func (s *DataSubscriber) failChart(requestID, status int, text string) {
+	chartErr := &ChartError{RequestID: requestID, Status: status, Text: text}
+
+	s.mu.Lock()
+	if page, ok := s.pendingHistory[requestID]; ok {
+		delete(s.pendingHistory, requestID)
+		s.mu.Unlock()
+		chartErr.Symbol = page.symbol
+		page.fail(chartErr)
+		return
+	}
+
+	var req *chartRequest
+	switch {
+	case s.pendingGets[requestID] != nil:
+		req = s.pendingGets[requestID]
+		delete(s.pendingGets, requestID)
+		chartErr.Symbol = req.symbol
+	case s.pendingCharts[requestID] != "":
+		key := s.pendingCharts[requestID]
+		delete(s.pendingCharts, requestID)
+		if info, ok := s.subscriptions[key]; ok {
+			chartErr.Symbol = fmt.Sprint(info.Params["symbol"])
+			delete(s.subscriptions, key)
+		}
+	default:
+		s.mu.Unlock()
+		if s.log != nil {
+			s.log.Errorf("Chart request %d failed: status %d - %s", requestID, status, text)
+		}
+		return
+	}
+	handlers := s.chartErrors
+	s.mu.Unlock()
+
+	if s.log != nil {
+		s.log.Errorf("%v", chartErr)
+	}
+	if req != nil {
+		req.done <- chartErr
+	}
+	for _, h := range handlers {
+		if !h.removed.Load() && (h.symbol == "" || h.symbol == chartErr.Symbol) {
+			h.fn(chartErr)
+		}
+	}
+}
+
+// AddChartErrorHandlerFor adds a callback for chart subscriptions and GetChart
+// requests for symbol that Tradovate refused. An empty symbol receives every
+// refusal. History pages report theirs from LoadHistory instead.
+func (s *DataSubscriber) AddChartErrorHandlerFor(symbol string, handler func(*ChartError)) HandlerID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextHandlerID++
+	s.chartErrors = append(s.chartErrors, &chartErrorHandler{id: s.nextHandlerID, symbol: symbol, fn: handler})
+	return s.nextHandlerID
+}
+
+// RemoveChartErrorHandler removes a chart error callback. It is not called
+// again once this returns.
+func (s *DataSubscriber) RemoveChartErrorHandler(id HandlerID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := make([]*chartErrorHandler, 0, len(s.chartErrors))
+	for _, h := range s.chartErrors {
+		if h.id == id {
+			h.removed.Store(true)
+			continue
+		}
+		kept = append(kept, h)
+	}
+	s.chartErrors = kept
+}
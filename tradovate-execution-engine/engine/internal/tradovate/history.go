@@ -0,0 +1,238 @@
+package tradovate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+const (
+	// HistoryPageSize is the most bars asked of one md/getchart. Tradovate
+	// returns only a few hundred per request whatever asMuchAsElements says.
+	HistoryPageSize = 500
+
+	// DefaultHistoryStallTimeout is how long a page may go without a packet
+	// before the load is abandoned
+	DefaultHistoryStallTimeout = 15 * time.Second
+)
+
+// LoadHistory fetches req.Bars bars with successive md/getchart requests, each
+// ending at the oldest bar of the one before. Pages are stitched oldest first
+// with the bar where they overlap kept once, and loading stops early at the
+// start of the available data. Each page's chart is cancelled at its end of
+// history so no live stream is left open. The load is abandoned when ctx is
+// done or a page stalls.
+func (s *DataSubscriber) LoadHistory(ctx context.Context, req HistoryRequest) ([]marketdata.Bar, error) {
+	rs, ok := s.client.(marketdata.RequestSender)
+	if !ok {
+		return nil, errors.New("paginated history needs a connection that tracks request IDs")
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = HistoryPageSize
+	}
+	stall := req.StallTimeout
+	if stall <= 0 {
+		stall = DefaultHistoryStallTimeout
+	}
+
+	var (
+		bars    []marketdata.Bar // Oldest first
+		oldest  time.Time
+		closest = req.Params.TimeRange.ClosestTimestamp
+	)
+	for page := 1; len(bars) < req.Bars; page++ {
+		want := req.Bars - len(bars)
+		if page > 1 {
+			want++ // The overlap bar comes back again
+		}
+		params := req.Params
+		params.TimeRange = marketdata.TimeRange{ClosestTimestamp: closest, AsMuchAsElements: min(want, pageSize)}
+
+		received, err := s.fetchHistoryPage(ctx, rs, params, stall)
+		if err != nil {
+			return nil, fmt.Errorf("history page %d for %v: %w", page, req.Params.Symbol, err)
+		}
+		older, first := barsBefore(received, oldest)
+		bars = append(older, bars...)
+		s.logInfof("History for %v: %d of %d bars after page %d", req.Params.Symbol, len(bars), req.Bars, page)
+
+		if len(older) == 0 {
+			s.logInfof("History for %v: start of available data reached", req.Params.Symbol)
+			break
+		}
+		oldest, closest = first, older[0].Timestamp
+	}
+
+	if len(bars) > req.Bars {
+		bars = bars[len(bars)-req.Bars:]
+	}
+	return bars, nil
+}
+
+// fetchHistoryPage sends one md/getchart and collects its bars until the end
+// of history marker
+func (s *DataSubscriber) fetchHistoryPage(ctx context.Context, rs marketdata.RequestSender, params marketdata.HistoricalDataParams, stall time.Duration) ([]marketdata.Bar, error) {
+	page := &historyPage{
+		symbol:   fmt.Sprint(params.Symbol),
+		ids:      make(map[int]bool),
+		eoh:      make(chan struct{}),
+		progress: make(chan struct{}, 1),
+	}
+	handler := s.AddChartHandler(page.add)
+	defer s.RemoveChartHandler(handler)
+
+	// Held across the send so the response cannot be handled before the page is pending
+	s.mu.Lock()
+	requestID, err := rs.SendRequest(chartEndpoint, params)
+	if err == nil {
+		s.pendingHistory[requestID] = page
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pendingHistory, requestID)
+		s.mu.Unlock()
+		if chartID := page.finish(); chartID != 0 {
+			if err := s.cancelChart(chartID); err != nil && s.log != nil {
+				s.log.Errorf("Error cancelling history chart %d: %v", chartID, err)
+			}
+		}
+	}()
+
+	timer := time.NewTimer(stall)
+	defer timer.Stop()
+	for {
+		select {
+		case <-page.eoh:
+			return page.result()
+		case <-page.progress:
+			timer.Reset(stall)
+		case <-timer.C:
+			return nil, fmt.Errorf("feed stalled: nothing received for %v", stall)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// barsBefore sorts a page oldest first and keeps the bars older than oldest,
+// or every bar when oldest is zero, along with the time of the first one kept.
+// Bars with unreadable timestamps are dropped, and of bars with the same time
+// the last received wins.
+func barsBefore(page []marketdata.Bar, oldest time.Time) ([]marketdata.Bar, time.Time) {
+	type timedBar struct {
+		at  time.Time
+		bar marketdata.Bar
+	}
+	byTime := make(map[time.Time]marketdata.Bar, len(page))
+	for _, bar := range page {
+		at, err := marketdata.ParseFeedTime(bar.Timestamp)
+		if err != nil || (!oldest.IsZero() && !at.Before(oldest)) {
+			continue
+		}
+		byTime[at] = bar
+	}
+
+	sorted := make([]timedBar, 0, len(byTime))
+	for at, bar := range byTime {
+		sorted = append(sorted, timedBar{at, bar})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].at.Before(sorted[j].at) })
+
+	bars := make([]marketdata.Bar, len(sorted))
+	for i, tb := range sorted {
+		bars[i] = tb.bar
+	}
+	if len(sorted) == 0 {
+		return bars, time.Time{}
+	}
+	return bars, sorted[0].at
+}
+
+// logInfof logs to the subscriber's logger, if it has one
+func (s *DataSubscriber) logInfof(format string, args ...interface{}) {
+	if s.log != nil {
+		s.log.Infof(format, args...)
+	}
+}
+
+// confirm records the chart IDs from the page's md/getchart response
+func (p *historyPage) confirm(resp marketdata.ChartSubscriptionResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, id := range []int{resp.HistoricalID, resp.RealtimeID} {
+		if id != 0 {
+			p.ids[id] = true
+		}
+	}
+	p.realtimeID = resp.RealtimeID
+	p.signal()
+}
+
+// add collects the bars of charts belonging to the page
+func (p *historyPage) add(update marketdata.ChartUpdate) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return
+	}
+	for _, chart := range update.Charts {
+		if !p.ids[chart.ID] {
+			continue
+		}
+		p.bars = append(p.bars, chart.Bars...)
+		if chart.EOH {
+			p.done = true
+			close(p.eoh)
+			return
+		}
+	}
+	p.signal()
+}
+
+// fail ends the page with the reason its request was refused
+func (p *historyPage) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return
+	}
+	p.done = true
+	p.err = err
+	close(p.eoh)
+}
+
+// result returns the page's bars, or why it failed
+func (p *historyPage) result() ([]marketdata.Bar, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.bars, nil
+}
+
+// finish stops the page taking packets and returns its realtime chart ID, 0 if
+// the response never arrived
+func (p *historyPage) finish() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done = true
+	return p.realtimeID
+}
+
+// signal wakes the page's waiter without blocking. Callers must hold p.mu.
+func (p *historyPage) signal() {
+	select {
+	case p.progress <- struct{}{}:
+	default:
+	}
+}
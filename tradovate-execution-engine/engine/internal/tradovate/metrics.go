@@ -0,0 +1,24 @@
+package tradovate
+
+import "tradovate-execution-engine/engine/internal/metrics"
+
+// wsConnects counts WebSocket connections opened, by connection ("md" or "trading")
+func wsConnects(connection string) *metrics.Counter {
+	return metrics.Default.Counter("engine_ws_connects_total", "WebSocket connections opened", "connection", connection)
+}
+
+// wsDisconnects counts WebSocket connections closed, by us or the server
+func wsDisconnects(connection string) *metrics.Counter {
+	return metrics.Default.Counter("engine_ws_disconnects_total", "WebSocket connections closed", "connection", connection)
+}
+
+// wsMessages counts WebSocket messages received, by event type
+func wsMessages(event string) *metrics.Counter {
+	return metrics.Default.Counter("engine_ws_messages_received_total", "WebSocket messages received", "event", event)
+}
+
+// wsSendErrors counts frames that could not be sent, by connection and reason
+// ("queue_full" or "write")
+func wsSendErrors(connection, reason string) *metrics.Counter {
+	return metrics.Default.Counter("engine_ws_send_errors_total", "WebSocket frames that could not be sent", "connection", connection, "reason", reason)
+}
@@ -0,0 +1,147 @@
+package tradovate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rejectionMessages are readable descriptions of Tradovate failureReason and
+// rejectReason codes
+var rejectionMessages = map[string]string{
+	"InsufficientMargin":              "insufficient margin",
+	"InsufficientFunds":               "insufficient funds",
+	"AccountClosed":                   "account is closed",
+	"TradingLocked":                   "trading is locked on this account",
+	"LiquidationOnly":                 "account is liquidation only, orders may only reduce positions",
+	"LiquidationOnlyBeforeExpiration": "contract is liquidation only before expiration",
+	"MaxPosLimitReached":              "position limit reached for this contract",
+	"MaxTotalPosLimitReached":         "total position limit reached",
+	"MaxOrderQtyLimitReached":         "order quantity exceeds the account limit",
+	"MaxPosLimitMisconfigured":        "position limit is misconfigured on this account",
+	"SessionClosed":                   "market is closed",
+	"TooLate":                         "too late, the market is closing",
+	"NoQuote":                         "no quote available for this contract",
+	"NotEnoughLiquidity":              "not enough liquidity",
+	"InvalidContract":                 "invalid contract",
+	"InvalidPrice":                    "invalid price",
+	"BackMonthProhibited":             "back-month contracts are not allowed",
+	"RiskCheckTimeout":                "risk check timed out",
+	"AnotherCommandPending":           "another command for this order is pending",
+	"ExecutionProviderUnavailable":    "execution provider unavailable",
+	"Unauthorized":                    "not authorized to trade this account",
+	"Unsupported":                     "order type not supported",
+}
+
+// marginAmountPattern finds the required amount in margin rejection text, e.g.
+// "Required margin: 1234.00" or "requires $1,234"
+var marginAmountPattern = regexp.MustCompile(`(?i)(?:required|requires|need(?:ed|s)?)[^0-9$]{0,20}\$?([0-9][0-9,]*(?:\.[0-9]+)?)`)
+
+// ParseOrderError reads a placeorder (or order command) response. It returns nil
+// when the request succeeded. A 200 response can still carry a failureReason.
+func ParseOrderError(statusCode int, body []byte) *OrderError {
+	var fields struct {
+		FailureReason string  `json:"failureReason"`
+		FailureText   string  `json:"failureText"`
+		ErrorText     string  `json:"errorText"`
+		PenaltyTicket string  `json:"p-ticket"`
+		PenaltyTime   float64 `json:"p-time"`
+	}
+	parsed := json.Unmarshal(body, &fields) == nil
+
+	if statusCode == http.StatusOK && (!parsed || fields.FailureReason == "" || fields.FailureReason == "Success") && fields.PenaltyTicket == "" {
+		return nil
+	}
+
+	oe := &OrderError{StatusCode: statusCode}
+	if !parsed {
+		oe.Body = strings.TrimSpace(string(body))
+		return oe
+	}
+	oe.Reason = fields.FailureReason
+	oe.Text = fields.FailureText
+	if oe.Text == "" {
+		oe.Text = fields.ErrorText
+	}
+	oe.PenaltyTicket = fields.PenaltyTicket
+	oe.PenaltyTime = fields.PenaltyTime
+	if oe.Reason == "" && oe.Text == "" && oe.PenaltyTicket == "" {
+		oe.Body = strings.TrimSpace(string(body))
+	}
+	return oe
+}
+
+// Error describes the refusal, e.g. "insufficient margin (need $1,234)"
+func (e *OrderError) Error() string {
+	switch {
+	case e.PenaltyTicket != "" || e.PenaltyTime > 0:
+		return fmt.Sprintf("rate limited by Tradovate, retry in %.0fs", e.PenaltyTime)
+	case e.Reason != "" || e.Text != "":
+		return DescribeRejection(e.Reason, e.Text)
+	case e.Body != "":
+		return fmt.Sprintf("order request failed (HTTP %d): %s", e.StatusCode, e.Body)
+	default:
+		return fmt.Sprintf("order request failed (HTTP %d)", e.StatusCode)
+	}
+}
+
+// IsRejection reports whether Tradovate refused the order itself, as opposed to
+// the request failing
+func (e *OrderError) IsRejection() bool {
+	return e.Reason != ""
+}
+
+// DescribeRejection turns a Tradovate reject code and its detail text into a
+// readable reason. Unknown codes are shown as-is with the text appended.
+func DescribeRejection(code, text string) string {
+	text = strings.TrimSpace(text)
+	message, known := rejectionMessages[code]
+	if !known {
+		message = code
+	}
+	if message == "" {
+		return text
+	}
+
+	if code == "InsufficientMargin" || code == "InsufficientFunds" {
+		if m := marginAmountPattern.FindStringSubmatch(text); m != nil {
+			if amount, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64); err == nil {
+				return fmt.Sprintf("%s (need %s)", message, formatDollars(amount))
+			}
+		}
+	}
+	if text != "" && !strings.EqualFold(text, code) {
+		return fmt.Sprintf("%s (%s)", message, text)
+	}
+	return message
+}
+
+// formatDollars formats an amount with thousands separators, e.g. $1,234 or $1,234.50
+func formatDollars(amount float64) string {
+	whole := int64(math.Abs(amount))
+	cents := int64(math.Round((math.Abs(amount) - float64(whole)) * 100))
+	if cents == 100 {
+		whole, cents = whole+1, 0
+	}
+
+	digits := strconv.FormatInt(whole, 10)
+	var b strings.Builder
+	if amount < 0 {
+		b.WriteByte('-')
+	}
+	b.WriteByte('$')
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if cents != 0 {
+		fmt.Fprintf(&b, ".%02d", cents)
+	}
+	return b.String()
+}
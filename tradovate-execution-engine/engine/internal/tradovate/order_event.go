@@ -0,0 +1,257 @@
+package tradovate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// DecodeOrderEvent reads an order entity from a user/syncrequest snapshot, a
+// props entity or a whole props event. IDs, quantities and prices may be JSON
+// numbers or strings, and timestamps RFC 3339 in any resolution or epoch
+// seconds or milliseconds. Optional fields that are missing or unreadable are
+// left unset rather than zero; only the order ID is required.
+func DecodeOrderEvent(data json.RawMessage) (OrderEvent, error) {
+	var raw struct {
+		ID           flexNumber `json:"id"`
+		OrderID      flexNumber `json:"orderId"`
+		AccountID    flexNumber `json:"accountId"`
+		ContractID   flexNumber `json:"contractId"`
+		Action       string     `json:"action"`
+		OrderType    string     `json:"orderType"`
+		OrdStatus    string     `json:"ordStatus"`
+		RejectReason string     `json:"rejectReason"`
+		Text         string     `json:"text"`
+		Timestamp    flexTime   `json:"timestamp"`
+		Qty          flexNumber `json:"qty"`
+		OrderQty     flexNumber `json:"orderQty"`
+		Price        flexNumber `json:"price"`
+		StopPrice    flexNumber `json:"stopPrice"`
+		CumQty       flexNumber `json:"cumQty"`
+		AvgPx        flexNumber `json:"avgPx"`
+		AvgPrice     flexNumber `json:"avgPrice"`
+	}
+	if err := json.Unmarshal(unwrapEntity(data, "order"), &raw); err != nil {
+		return OrderEvent{}, fmt.Errorf("malformed order event: %w", err)
+	}
+
+	// Order versions and reports carry their own id next to the order's
+	idField := raw.ID
+	if raw.OrderID.set {
+		idField = raw.OrderID
+	}
+	id, err := idField.Int()
+	if err != nil || id <= 0 {
+		return OrderEvent{}, errors.New("order event has no order ID")
+	}
+
+	ev := OrderEvent{
+		ID:           id,
+		Action:       raw.Action,
+		OrderType:    raw.OrderType,
+		OrdStatus:    raw.OrdStatus,
+		RejectReason: raw.RejectReason,
+		Text:         raw.Text,
+		Timestamp:    raw.Timestamp.value,
+		HasTimestamp: raw.Timestamp.set,
+	}
+	ev.AccountID, _ = raw.AccountID.Int()
+	ev.ContractID, _ = raw.ContractID.Int()
+
+	qty := raw.Qty
+	if !qty.set {
+		qty = raw.OrderQty
+	}
+	if n, err := qty.Int(); err == nil {
+		ev.Qty, ev.HasQty = n, true
+	}
+	if p, err := raw.Price.Float(); err == nil {
+		ev.Price, ev.HasPrice = p, true
+	}
+	if p, err := raw.StopPrice.Float(); err == nil {
+		ev.StopPrice, ev.HasStopPrice = p, true
+	}
+	if n, err := raw.CumQty.Int(); err == nil {
+		ev.CumQty, ev.HasCumQty = n, true
+	}
+	avg := raw.AvgPx
+	if !avg.set {
+		avg = raw.AvgPrice
+	}
+	if p, err := avg.Float(); err == nil {
+		ev.AvgPrice, ev.HasAvgPrice = p, true
+	}
+	return ev, nil
+}
+
+// DecodeFillEvent reads a fill entity with the same tolerance as
+// DecodeOrderEvent. A fill without its order ID, a positive quantity or a
+// price is an error, so no zero-valued fill is ever applied.
+func DecodeFillEvent(data json.RawMessage) (FillEvent, error) {
+	var raw struct {
+		ID         flexNumber `json:"id"`
+		OrderID    flexNumber `json:"orderId"`
+		ContractID flexNumber `json:"contractId"`
+		Action     string     `json:"action"`
+		Timestamp  flexTime   `json:"timestamp"`
+		Qty        flexNumber `json:"qty"`
+		Price      flexNumber `json:"price"`
+	}
+	if err := json.Unmarshal(unwrapEntity(data, "fill"), &raw); err != nil {
+		return FillEvent{}, fmt.Errorf("malformed fill event: %w", err)
+	}
+
+	ev := FillEvent{Action: raw.Action, Timestamp: raw.Timestamp.value, HasTimestamp: raw.Timestamp.set}
+	var err error
+	if ev.OrderID, err = raw.OrderID.Int(); err != nil || ev.OrderID <= 0 {
+		return FillEvent{}, errors.New("fill event has no order ID")
+	}
+	if ev.Qty, err = raw.Qty.Int(); err != nil || ev.Qty <= 0 {
+		return FillEvent{}, fmt.Errorf("fill for order %d has no quantity", ev.OrderID)
+	}
+	if ev.Price, err = raw.Price.Float(); err != nil {
+		return FillEvent{}, fmt.Errorf("fill for order %d has no price", ev.OrderID)
+	}
+	ev.ID, _ = raw.ID.Int()
+	ev.ContractID, _ = raw.ContractID.Int()
+	return ev, nil
+}
+
+// DecodeExecutionReport reads an executionReport entity with the same tolerance
+// as DecodeOrderEvent. Only the order ID is required.
+func DecodeExecutionReport(data json.RawMessage) (ExecutionReport, error) {
+	var raw struct {
+		ID           flexNumber `json:"id"`
+		OrderID      flexNumber `json:"orderId"`
+		ContractID   flexNumber `json:"contractId"`
+		ExecType     string     `json:"execType"`
+		OrdStatus    string     `json:"ordStatus"`
+		Action       string     `json:"action"`
+		CumQty       flexNumber `json:"cumQty"`
+		AvgPx        flexNumber `json:"avgPx"`
+		LastQty      flexNumber `json:"lastQty"`
+		LastPx       flexNumber `json:"lastPx"`
+		RejectReason string     `json:"rejectReason"`
+		Text         string     `json:"text"`
+		Timestamp    flexTime   `json:"timestamp"`
+	}
+	if err := json.Unmarshal(unwrapEntity(data, "executionReport"), &raw); err != nil {
+		return ExecutionReport{}, fmt.Errorf("malformed execution report: %w", err)
+	}
+
+	report := ExecutionReport{
+		ExecType:     raw.ExecType,
+		OrdStatus:    raw.OrdStatus,
+		Action:       raw.Action,
+		RejectReason: raw.RejectReason,
+		Text:         raw.Text,
+		Timestamp:    raw.Timestamp.value,
+		HasTimestamp: raw.Timestamp.set,
+	}
+	var err error
+	if report.OrderID, err = raw.OrderID.Int(); err != nil || report.OrderID <= 0 {
+		return ExecutionReport{}, errors.New("execution report has no order ID")
+	}
+	report.ID, _ = raw.ID.Int()
+	report.ContractID, _ = raw.ContractID.Int()
+	report.CumQty, _ = raw.CumQty.Int()
+	report.AvgPx, _ = raw.AvgPx.Float()
+	report.LastQty, _ = raw.LastQty.Int()
+	report.LastPx, _ = raw.LastPx.Float()
+	return report, nil
+}
+
+// unwrapEntity returns the entity inside a whole props event or a {"order": ...}
+// style wrapper, or data itself
+func unwrapEntity(data json.RawMessage, name string) json.RawMessage {
+	var wrapper map[string]json.RawMessage
+	if json.Unmarshal(data, &wrapper) != nil {
+		return data
+	}
+	for _, key := range []string{"entity", name} {
+		if inner, ok := wrapper[key]; ok && bytes.HasPrefix(bytes.TrimSpace(inner), []byte("{")) {
+			return inner
+		}
+	}
+	return data
+}
+
+// flexNumber is a JSON number that may also arrive as a string. Null and empty
+// strings leave it unset.
+type flexNumber struct {
+	text string
+	set  bool
+}
+
+func (n *flexNumber) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = strings.TrimSpace(unquoted)
+	}
+	if text != "" {
+		n.text, n.set = text, true
+	}
+	return nil
+}
+
+// Float returns the value, failing if it is unset or not a number
+func (n flexNumber) Float() (float64, error) {
+	if !n.set {
+		return 0, errors.New("missing")
+	}
+	f, err := strconv.ParseFloat(n.text, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("not a number: %q", n.text)
+	}
+	return f, nil
+}
+
+// Int returns the value as a whole number; 2.0 is accepted, 2.5 is not
+func (n flexNumber) Int() (int, error) {
+	f, err := n.Float()
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("not a whole number: %q", n.text)
+	}
+	return int(f), nil
+}
+
+// flexTime is a timestamp as an RFC 3339 string in any resolution, with or
+// without a zone, or as epoch seconds or milliseconds. Unreadable values leave
+// it unset.
+type flexTime struct {
+	value time.Time
+	set   bool
+}
+
+func (t *flexTime) UnmarshalJSON(data []byte) error {
+	var n flexNumber
+	n.UnmarshalJSON(data)
+	if !n.set {
+		return nil
+	}
+	if epoch, err := n.Float(); err == nil {
+		if epoch > 1e12 {
+			t.value = time.UnixMilli(int64(epoch)).UTC()
+		} else {
+			t.value = time.Unix(int64(epoch), 0).UTC()
+		}
+		t.set = true
+		return nil
+	}
+	if parsed, err := marketdata.ParseFeedTime(n.text); err == nil {
+		t.value, t.set = parsed, true
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package tradovate
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// terminalOrderStatuses are statuses after which an order cannot transition again
+var terminalOrderStatuses = map[string]bool{
+	"Filled":   true,
+	"Canceled": true,
+	"Rejected": true,
+	"Expired":  true,
+}
+
+// finishedOrderMemory is how many finished orders keep their last state, so a
+// late event for one is still held back
+const finishedOrderMemory = 1000
+
+// NewOrderEventRouter creates an empty order event router
+func NewOrderEventRouter() *OrderEventRouter {
+	return &OrderEventRouter{
+		seen:      make(map[int]map[string]struct{}),
+		lastState: make(map[int]orderEventState),
+	}
+}
+
+// Route decides whether an order event should be delivered to OnOrderUpdate.
+// Events that cannot be parsed are always delivered so nothing is silently lost.
+func (r *OrderEventRouter) Route(data json.RawMessage) OrderEventVerdict {
+	event, err := DecodeOrderEvent(data)
+	if err != nil {
+		return OrderEventDeliver
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Keyed on the parsed time so the same instant in another resolution matches
+	stamp := ""
+	if event.HasTimestamp {
+		stamp = event.Timestamp.Format(time.RFC3339Nano)
+	}
+	key := event.OrdStatus + "|" + stamp
+	last, known := r.lastState[event.ID]
+	if _, ok := r.seen[event.ID][key]; ok || known && last.key() == key {
+		r.duplicates++
+		return OrderEventDuplicate
+	}
+	if known && isOutOfOrder(last, event) {
+		r.outOfOrder++
+		return OrderEventOutOfOrder
+	}
+
+	state := orderEventState{status: event.OrdStatus, timestamp: event.Timestamp, hasTime: event.HasTimestamp}
+	r.lastState[event.ID] = state
+	if terminalOrderStatuses[event.OrdStatus] {
+		r.finish(event.ID)
+		return OrderEventDeliver
+	}
+	if r.seen[event.ID] == nil {
+		r.seen[event.ID] = make(map[string]struct{})
+	}
+	r.seen[event.ID][key] = struct{}{}
+	return OrderEventDeliver
+}
+
+// finish drops the seen states of an order that can no longer change, keeping
+// only its last state until finishedOrderMemory later orders have finished
+func (r *OrderEventRouter) finish(id int) {
+	delete(r.seen, id)
+	for _, done := range r.finished {
+		if done == id {
+			return
+		}
+	}
+	r.finished = append(r.finished, id)
+	if len(r.finished) > finishedOrderMemory {
+		delete(r.lastState, r.finished[0])
+		r.finished = r.finished[1:]
+	}
+}
+
+// key is the seen key of the state, its status and timestamp
+func (s orderEventState) key() string {
+	if !s.hasTime {
+		return s.status + "|"
+	}
+	return s.status + "|" + s.timestamp.Format(time.RFC3339Nano)
+}
+
+// isOutOfOrder reports whether an event is older than the last delivered state,
+// e.g. a Working arriving after Filled
+func isOutOfOrder(last orderEventState, event OrderEvent) bool {
+	if terminalOrderStatuses[last.status] && !terminalOrderStatuses[event.OrdStatus] {
+		return true
+	}
+	if !last.hasTime || !event.HasTimestamp {
+		return false
+	}
+	return event.Timestamp.Before(last.timestamp)
+}
+
+// Stats returns the number of suppressed duplicate and out-of-order events
+func (r *OrderEventRouter) Stats() (duplicates, outOfOrder int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.duplicates, r.outOfOrder
+}
+
+// Reset clears all tracked order state and counters
+func (r *OrderEventRouter) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen = make(map[int]map[string]struct{})
+	r.lastState = make(map[int]orderEventState)
+	r.finished = nil
+	r.duplicates = 0
+	r.outOfOrder = 0
+}
@@ -0,0 +1,137 @@
+package tradovate
+
+// SubscribeQuote subscribes to real-time quotes for a symbol, given by name or
+// contract ID, or for a []string of names in one request. Each symbol is
+// reference counted on its own however it was subscribed, so it can be
+// released with UnsubscribeQuote one at a time.
+func (s *DataSubscriber) SubscribeQuote(symbol interface{}) error {
+	return s.subscribeQuotes(quoteSymbols(symbol))
+}
+
+// SubscribeQuotes subscribes to quotes for every symbol, sending one
+// md/subscribequote for those not already streaming
+func (s *DataSubscriber) SubscribeQuotes(symbols []string) error {
+	return s.subscribeQuotes(quoteSymbols(symbols))
+}
+
+// UnsubscribeQuote releases one reference to the quotes of a symbol, or of each
+// symbol of a []string, stopping the streams no longer referenced
+func (s *DataSubscriber) UnsubscribeQuote(symbol interface{}) error {
+	return s.unsubscribeQuotes(quoteSymbols(symbol))
+}
+
+// UnsubscribeQuotes releases one reference to the quotes of every symbol,
+// stopping those no longer referenced in one md/unsubscribequote
+func (s *DataSubscriber) UnsubscribeQuotes(symbols []string) error {
+	return s.unsubscribeQuotes(quoteSymbols(symbols))
+}
+
+// subscribeQuotes adds a reference to each symbol and starts the streams of
+// the new ones in a single request
+func (s *DataSubscriber) subscribeQuotes(symbols []interface{}) error {
+	var fresh []interface{}
+	for _, symbol := range symbols {
+		if _, exists := s.isSubscribed(quoteEndpoint, quoteParams(symbol)); exists {
+			s.addSubscription(quoteEndpoint, quoteParams(symbol), 0)
+			continue
+		}
+		fresh = append(fresh, symbol)
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	// Tracked before sending so a refusal can drop them
+	keys := make([]string, 0, len(fresh))
+	for _, symbol := range fresh {
+		keys = append(keys, s.addSubscription(quoteEndpoint, quoteParams(symbol), 0))
+	}
+	if err := s.sendSubscription(quoteEndpoint, quoteRequest(fresh), keys); err != nil {
+		for _, key := range keys {
+			s.removeSubscription(key)
+		}
+		return err
+	}
+
+	if s.log != nil {
+		s.log.Debugf("Subscribed to %s for %v", quoteEndpoint, fresh)
+	}
+	return nil
+}
+
+// unsubscribeQuotes drops a reference to each symbol and stops the streams that
+// have none left in a single request
+func (s *DataSubscriber) unsubscribeQuotes(symbols []interface{}) error {
+	var release []interface{}
+	for _, symbol := range symbols {
+		key, exists := s.isSubscribed(quoteEndpoint, quoteParams(symbol))
+		if !exists {
+			if s.log != nil {
+				s.log.Debugf("Not subscribed to quotes for %v", symbol)
+			}
+			continue
+		}
+		if removed, info := s.removeSubscription(key); !removed {
+			if s.log != nil {
+				s.log.Debugf("Quotes for %v still have %d references", symbol, info.RefCount)
+			}
+			continue
+		}
+		release = append(release, symbol)
+	}
+	if len(release) == 0 {
+		return nil
+	}
+
+	if err := s.client.Send(quoteUnsubEndpoint, quoteRequest(release)); err != nil {
+		return err
+	}
+
+	if s.log != nil {
+		s.log.Debugf("Unsubscribed from quotes for %v", release)
+	}
+	return nil
+}
+
+// quoteSymbols lists the symbols of a quote subscription, without repeats. A
+// []string or []interface{} is a batch; anything else is one symbol.
+func quoteSymbols(symbol interface{}) []interface{} {
+	var all []interface{}
+	switch v := symbol.(type) {
+	case []string:
+		for _, name := range v {
+			all = append(all, name)
+		}
+	case []interface{}:
+		all = v
+	default:
+		all = []interface{}{symbol}
+	}
+
+	symbols := make([]interface{}, 0, len(all))
+	seen := make(map[interface{}]bool, len(all))
+	for _, sym := range all {
+		if seen[sym] {
+			continue
+		}
+		seen[sym] = true
+		symbols = append(symbols, sym)
+	}
+	return symbols
+}
+
+// quoteParams identifies the subscription of one symbol
+func quoteParams(symbol interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"symbol": symbol,
+	}
+}
+
+// quoteRequest is the body of a quote (un)subscription: the symbol on its own,
+// or the list when there are several
+func quoteRequest(symbols []interface{}) map[string]interface{} {
+	if len(symbols) == 1 {
+		return quoteParams(symbols[0])
+	}
+	return quoteParams(symbols)
+}
@@ -0,0 +1,112 @@
+package tradovate
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tradovate-execution-engine/engine/config"
+)
+
+// Frame directions in a recording
+const (
+	FrameIn  = "in"
+	FrameOut = "out"
+)
+
+// recordingFlushInterval is how often buffered frames are written to the file
+const recordingFlushInterval = time.Second
+
+// redactedToken replaces the access token in a recorded authorize frame
+const redactedToken = "<redacted>"
+
+// RecordingDir returns the default directory for recordings, external/recordings
+func RecordingDir() string {
+	return filepath.Join(config.GetProjectRoot(), "external", "recordings")
+}
+
+// NewRecorder creates dir if needed and opens a new recording in it named after
+// the connection and the current time, e.g. md-20260105-150405.jsonl.gz
+func NewRecorder(dir, name string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.jsonl.gz", name, time.Now().Format("20060102-150405")))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	gz := gzip.NewWriter(file)
+	return &Recorder{path: path, file: file, gz: gz, enc: json.NewEncoder(gz)}, nil
+}
+
+// Path returns the file the recorder writes to
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Frames returns how many frames have been written
+func (r *Recorder) Frames() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frames
+}
+
+// Record appends a frame seen in direction dir. The access token in an outbound
+// authorize frame is redacted so recordings can be shared.
+func (r *Recorder) Record(dir, frame string) {
+	if dir == FrameOut && strings.HasPrefix(frame, "authorize\n") {
+		frame = redactAuthorize(frame)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.enc == nil || r.err != nil {
+		return
+	}
+	now := time.Now().UTC()
+	if err := r.enc.Encode(RecordedFrame{Time: now, Dir: dir, Frame: frame}); err != nil {
+		r.err = err
+		return
+	}
+	r.frames++
+
+	// Flush now and then so a crash loses at most the last second
+	if now.Sub(r.lastFlush) >= recordingFlushInterval {
+		if err := r.gz.Flush(); err != nil {
+			r.err = err
+		}
+		r.lastFlush = now
+	}
+}
+
+// Close flushes the recording and closes its file. It returns the first error
+// seen while recording, if any.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.enc == nil {
+		return r.err
+	}
+	r.enc = nil
+	if err := r.gz.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// redactAuthorize keeps the url and request ID of "authorize\n<id>\n\n<token>"
+// and drops the token
+func redactAuthorize(frame string) string {
+	parts := strings.SplitN(frame, "\n", 4)
+	if len(parts) < 4 {
+		return "authorize\n" + redactedToken
+	}
+	return strings.Join(parts[:3], "\n") + "\n" + redactedToken
+}
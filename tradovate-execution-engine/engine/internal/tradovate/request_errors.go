@@ -0,0 +1,110 @@
+package tradovate
+
+import (
+	"fmt"
+
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// Error returns a message naming the request and Tradovate's reason
+func (e *WSError) Error() string {
+	text := e.StatusText
+	if text == "" {
+		text = "no reason given"
+	}
+	if len(e.Symbols) > 0 {
+		return fmt.Sprintf("%s request %d for %v failed: status %d - %s", e.URL, e.RequestID, e.Symbols, e.Status, text)
+	}
+	return fmt.Sprintf("%s request %d failed: status %d - %s", e.URL, e.RequestID, e.Status, text)
+}
+
+// HandleRequestError processes one of our requests that Tradovate answered with
+// an error status. Chart requests are failed by request ID. A refused quote,
+// DOM or user sync subscription is dropped so subscribing again asks again, and
+// the error is passed to the request error handlers.
+func (s *DataSubscriber) HandleRequestError(requestID int, url string, status int, text string) {
+	if url == chartEndpoint {
+		s.failChart(requestID, status, text)
+		return
+	}
+	wsErr := &WSError{RequestID: requestID, URL: url, Status: status, StatusText: text}
+
+	s.mu.Lock()
+	for _, key := range s.pendingSubs[requestID] {
+		info, ok := s.subscriptions[key]
+		if !ok {
+			continue
+		}
+		if symbol, ok := info.Params["symbol"]; ok {
+			wsErr.Symbols = append(wsErr.Symbols, fmt.Sprint(symbol))
+		}
+		delete(s.subscriptions, key)
+	}
+	delete(s.pendingSubs, requestID)
+	handlers := s.requestErrors
+	s.mu.Unlock()
+
+	if s.log != nil {
+		s.log.Errorf("%v", wsErr)
+	}
+	for _, h := range handlers {
+		if !h.removed.Load() && (h.symbol == "" || containsSymbol(wsErr.Symbols, h.symbol)) {
+			h.fn(wsErr)
+		}
+	}
+}
+
+// AddRequestErrorHandlerFor adds a callback for refused requests other than
+// charts, such as quote subscriptions. A non-empty symbol only receives the
+// refusals of subscriptions for it.
+func (s *DataSubscriber) AddRequestErrorHandlerFor(symbol string, handler func(*WSError)) HandlerID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextHandlerID++
+	s.requestErrors = append(s.requestErrors, &requestErrorHandler{id: s.nextHandlerID, symbol: symbol, fn: handler})
+	return s.nextHandlerID
+}
+
+// RemoveRequestErrorHandler removes a request error callback. It is not called
+// again once this returns.
+func (s *DataSubscriber) RemoveRequestErrorHandler(id HandlerID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := make([]*requestErrorHandler, 0, len(s.requestErrors))
+	for _, h := range s.requestErrors {
+		if h.id == id {
+			h.removed.Store(true)
+			continue
+		}
+		kept = append(kept, h)
+	}
+	s.requestErrors = kept
+}
+
+// sendSubscription sends the request starting the subscriptions with keys and,
+// when the connection returns request IDs, remembers them until its response so
+// a refusal can drop them
+func (s *DataSubscriber) sendSubscription(endpoint string, body interface{}, keys []string) error {
+	rs, ok := s.client.(marketdata.RequestSender)
+	if !ok {
+		return s.client.Send(endpoint, body)
+	}
+	// Held across the send so the response cannot be handled before the request is pending
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requestID, err := rs.SendRequest(endpoint, body)
+	if err == nil {
+		s.pendingSubs[requestID] = keys
+	}
+	return err
+}
+
+// containsSymbol reports whether symbol is one of symbols
+func containsSymbol(symbols []string, symbol string) bool {
+	for _, sym := range symbols {
+		if sym == symbol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package tradovate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Send queue limits used until SetSendQueue changes them
+const (
+	defaultSendQueueSize = 256
+	defaultWriteTimeout  = 5 * time.Second
+)
+
+// ErrSendQueueFull is returned by Send when the connection is not writing
+// frames as fast as they are sent
+var ErrSendQueueFull = errors.New("websocket send queue full")
+
+// SetSendQueue sets how many frames may wait to be written and how long one
+// write may take before the connection is treated as failed. Zero keeps the
+// default. It applies from the next Connect.
+func (c *TradovateWebSocketClient) SetSendQueue(size int, writeTimeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendQueueSize = size
+	c.writeTimeout = writeTimeout
+}
+
+// SetSendErrorHandler sets a callback run, on the writer goroutine, when a
+// frame could not be written. The connection is closed by then.
+func (c *TradovateWebSocketClient) SetSendErrorHandler(handler func(error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onSendError = handler
+}
+
+// startWriter starts the goroutine that writes conn's frames. Caller must hold
+// the lock.
+func (c *TradovateWebSocketClient) startWriter(conn *websocket.Conn) {
+	size, timeout := c.sendQueueSize, c.writeTimeout
+	if size <= 0 {
+		size = defaultSendQueueSize
+	}
+	if timeout <= 0 {
+		timeout = defaultWriteTimeout
+	}
+	c.outbound = make(chan string, size)
+	c.heartbeat = make(chan struct{}, 1)
+	c.writerStop = make(chan struct{})
+	go c.writeLoop(conn, c.outbound, c.heartbeat, c.writerStop, timeout)
+}
+
+// stopWriter ends the writer, dropping the frames it has not written. Caller
+// must hold the lock.
+func (c *TradovateWebSocketClient) stopWriter() {
+	if c.writerStop != nil {
+		close(c.writerStop)
+	}
+	c.outbound, c.heartbeat, c.writerStop = nil, nil, nil
+}
+
+// enqueue queues a frame for the writer without waiting for it to be written.
+// Caller must hold the lock.
+func (c *TradovateWebSocketClient) enqueue(frame string) error {
+	if c.conn == nil || c.outbound == nil {
+		return fmt.Errorf("websocket not connected")
+	}
+	select {
+	case c.outbound <- frame:
+		return nil
+	default:
+		wsSendErrors(c.name, "queue_full").Inc()
+		return fmt.Errorf("%w (%d frames waiting)", ErrSendQueueFull, cap(c.outbound))
+	}
+}
+
+// writeLoop writes conn's frames one at a time, each within timeout. A due
+// heartbeat goes ahead of queued frames so a burst of requests cannot starve
+// the keepalive.
+func (c *TradovateWebSocketClient) writeLoop(conn *websocket.Conn, outbound <-chan string, heartbeat <-chan struct{}, stop <-chan struct{}, timeout time.Duration) {
+	for {
+		frame := ""
+		select {
+		case <-heartbeat:
+			frame = "[]"
+		default:
+			select {
+			case <-heartbeat:
+				frame = "[]"
+			case frame = <-outbound:
+			case <-stop:
+				return
+			}
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(timeout))
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+			c.writeFailed(conn, err)
+			return
+		}
+	}
+}
+
+// writeFailed closes a connection whose frame could not be written, so callers
+// see it disconnected, and reports the error
+func (c *TradovateWebSocketClient) writeFailed(conn *websocket.Conn, err error) {
+	c.mu.Lock()
+	if c.conn != conn {
+		// Disconnect closed it
+		c.mu.Unlock()
+		return
+	}
+	c.conn = nil
+	c.isAuthorized = false
+	c.stopWriter()
+	handler, expected := c.onSendError, c.expectDown
+	c.mu.Unlock()
+
+	conn.Close()
+	wsSendErrors(c.name, "write").Inc()
+	if c.log != nil && expected {
+		c.log.Infof("WebSocket %s write failed during maintenance: %v", c.wsURL, err)
+	} else if c.log != nil {
+		c.log.Errorf("WebSocket %s write failed, connection closed: %v", c.wsURL, err)
+	}
+	if handler != nil {
+		handler(err)
+	}
+}
@@ -7,13 +7,29 @@ import (
 	"tradovate-execution-engine/engine/internal/marketdata"
 )
 
+const (
+	// chartEndpoint both requests chart history and starts the live chart stream
+	chartEndpoint = "md/getchart"
+
+	quoteEndpoint      = "md/subscribequote"
+	quoteUnsubEndpoint = "md/unsubscribequote"
+
+	domEndpoint      = "md/subscribedom"
+	domUnsubEndpoint = "md/unsubscribedom"
+)
+
 // NewDataSubscriber creates a new market data subscriber
 func NewDataSubscriptionManager(client marketdata.WebSocketSender) *DataSubscriber {
 	return &DataSubscriber{
-		client:        client,
-		subscriptions: make(map[string]*SubscriptionInfo),
-		OnQuoteUpdate: make([]func(marketdata.Quote), 0),
-		OnChartUpdate: make([]func(marketdata.ChartUpdate), 0),
+		client:         client,
+		subscriptions:  make(map[string]*SubscriptionInfo),
+		pendingCharts:  make(map[int]string),
+		pendingHistory: make(map[int]*historyPage),
+		pendingGets:    make(map[int]*chartRequest),
+		pendingSubs:    make(map[int][]string),
+		orderRouter:    NewOrderEventRouter(),
+		unknownProps:   make(map[string]int),
+		clock:          marketdata.NewFeedClock(nil),
 	}
 }
 
@@ -22,6 +38,12 @@ func (s *DataSubscriber) SetLogger(l *logger.Logger) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.log = l
+	s.clock.SetLogger(l)
+}
+
+// FeedClock returns the clock quote timestamps are read with, for its skew
+func (s *DataSubscriber) FeedClock() *marketdata.FeedClock {
+	return s.clock
 }
 
 // IsConnected returns whether the underlying client is connected
@@ -29,8 +51,10 @@ func (s *DataSubscriber) IsConnected() bool {
 	return s.client.IsConnected()
 }
 
-// Connect attempts to connect the underlying client
+// Connect attempts to connect the underlying client. Order event state starts
+// over, since a new connection sends its own user/syncrequest snapshot.
 func (s *DataSubscriber) Connect() error {
+	s.orderRouter.Reset()
 	return s.client.Connect()
 }
 
@@ -105,6 +129,7 @@ func (s *DataSubscriber) removeSubscription(key string) (bool, *SubscriptionInfo
 			Endpoint: info.Endpoint,
 			Params:   info.Params,
 			ChartID:  info.ChartID,
+			HistID:   info.HistID,
 			RefCount: info.RefCount,
 		}
 		delete(s.subscriptions, key)
@@ -122,6 +147,8 @@ func (s *DataSubscriber) removeSubscription(key string) (bool, *SubscriptionInfo
 
 // HandleEvent processes incoming market data events
 func (s *DataSubscriber) HandleEvent(eventType string, data json.RawMessage) {
+	wsMessages(eventType).Inc()
+
 	switch eventType {
 	case marketdata.EventMarketData:
 		s.handleMarketData(data)
@@ -136,16 +163,14 @@ func (s *DataSubscriber) HandleEvent(eventType string, data json.RawMessage) {
 		}
 		s.handleUserEvent(data)
 	case marketdata.EventOrder:
-		if s.OnOrderUpdate != nil {
-			s.OnOrderUpdate(data)
-		}
+		s.dispatchOrderUpdate(data)
 	case marketdata.EventPosition:
 		if s.OnPositionUpdate != nil {
 			s.OnPositionUpdate(data)
 		}
 	case marketdata.EventProps:
 		s.handlePropsEvent(data)
-	case "md/subscribequote", "md/unsubscribequote":
+	case "md/subscribequote", "md/unsubscribequote", domEndpoint, domUnsubEndpoint:
 		s.handleSubscriptionResponse(eventType)
 	default:
 		if s.log != nil {
@@ -154,6 +179,82 @@ func (s *DataSubscriber) HandleEvent(eventType string, data json.RawMessage) {
 	}
 }
 
+// HandleResponse processes a response to one of our requests. Chart responses are
+// matched to their pending subscription, history page or GetChart by request ID
+// before normal event handling.
+func (s *DataSubscriber) HandleResponse(requestID int, url string, data json.RawMessage) {
+	if url == chartEndpoint {
+		s.handleChartSubscriptionResponse(requestID, data)
+	} else {
+		s.mu.Lock()
+		delete(s.pendingSubs, requestID)
+		s.mu.Unlock()
+	}
+	s.HandleEvent(url, data)
+}
+
+// handleChartSubscriptionResponse stores the chart ID from an md/getchart reply in the
+// matching subscription. A chart that was unsubscribed before it was confirmed is
+// cancelled now that its ID is known.
+func (s *DataSubscriber) handleChartSubscriptionResponse(requestID int, data json.RawMessage) {
+	var resp marketdata.ChartSubscriptionResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return
+	}
+	if resp.ErrorText != "" || resp.ErrorCode != "" {
+		text := resp.ErrorText
+		if text == "" {
+			text = resp.ErrorCode
+		}
+		s.failChart(requestID, 200, text)
+		return
+	}
+	chartID := resp.RealtimeID
+	if chartID == 0 {
+		chartID = resp.HistoricalID
+	}
+
+	s.mu.Lock()
+	if page, ok := s.pendingHistory[requestID]; ok {
+		delete(s.pendingHistory, requestID)
+		s.mu.Unlock()
+		page.confirm(resp)
+		return
+	}
+	if req, ok := s.pendingGets[requestID]; ok {
+		delete(s.pendingGets, requestID)
+		s.mu.Unlock()
+		req.done <- nil
+		return
+	}
+	key, pending := s.pendingCharts[requestID]
+	if !pending {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.pendingCharts, requestID)
+
+	info, active := s.subscriptions[key]
+	if active {
+		info.ChartID = chartID
+		info.HistID = resp.HistoricalID
+	}
+	s.mu.Unlock()
+
+	if active {
+		if s.log != nil {
+			s.log.Debugf("Chart subscription confirmed: request %d -> chart ID %d", requestID, chartID)
+		}
+		return
+	}
+
+	if chartID != 0 {
+		if err := s.cancelChart(chartID); err != nil && s.log != nil {
+			s.log.Errorf("Error cancelling chart %d: %v", chartID, err)
+		}
+	}
+}
+
 // handleSubscriptionResponse processes subscription confirmations
 func (s *DataSubscriber) handleSubscriptionResponse(eventType string) {
 	if s.log != nil {
@@ -161,21 +262,93 @@ func (s *DataSubscriber) handleSubscriptionResponse(eventType string) {
 	}
 }
 
-// AddQuoteHandler adds a callback for quote updates
-func (s *DataSubscriber) AddQuoteHandler(handler func(marketdata.Quote)) {
+// AddQuoteHandler adds a callback for quote updates on every contract
+func (s *DataSubscriber) AddQuoteHandler(handler func(marketdata.Quote)) HandlerID {
+	return s.AddQuoteHandlerFor(0, handler)
+}
+
+// AddQuoteHandlerFor adds a callback for quotes on one contract. A contractID of
+// 0 receives every contract.
+func (s *DataSubscriber) AddQuoteHandlerFor(contractID int, handler func(marketdata.Quote)) HandlerID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextHandlerID++
+	s.quoteHandlers = append(s.quoteHandlers, &quoteHandler{id: s.nextHandlerID, contractID: contractID, fn: handler})
+	return s.nextHandlerID
+}
+
+// RemoveQuoteHandler removes a quote callback. It is not called again once this returns.
+func (s *DataSubscriber) RemoveQuoteHandler(id HandlerID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := make([]*quoteHandler, 0, len(s.quoteHandlers))
+	for _, h := range s.quoteHandlers {
+		if h.id == id {
+			h.removed.Store(true)
+			continue
+		}
+		kept = append(kept, h)
+	}
+	s.quoteHandlers = kept
+}
+
+// AddChartHandler adds a callback for chart updates on every chart
+func (s *DataSubscriber) AddChartHandler(handler func(marketdata.ChartUpdate)) HandlerID {
+	return s.AddChartHandlerFor("", handler)
+}
+
+// AddChartHandlerFor adds a callback for the charts subscribed with SubscribeChart
+// for symbol. Each update it receives holds only that symbol's charts. An empty
+// symbol receives every chart, including GetChart requests.
+func (s *DataSubscriber) AddChartHandlerFor(symbol string, handler func(marketdata.ChartUpdate)) HandlerID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextHandlerID++
+	s.chartHandlers = append(s.chartHandlers, &chartHandler{id: s.nextHandlerID, symbol: symbol, fn: handler})
+	return s.nextHandlerID
+}
+
+// RemoveChartHandler removes a chart callback. It is not called again once this returns.
+func (s *DataSubscriber) RemoveChartHandler(id HandlerID) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.OnQuoteUpdate = append(s.OnQuoteUpdate, handler)
+	kept := make([]*chartHandler, 0, len(s.chartHandlers))
+	for _, h := range s.chartHandlers {
+		if h.id == id {
+			h.removed.Store(true)
+			continue
+		}
+		kept = append(kept, h)
+	}
+	s.chartHandlers = kept
 }
 
-// AddChartHandler adds a callback for chart updates
-func (s *DataSubscriber) AddChartHandler(handler func(marketdata.ChartUpdate)) {
+// AddDOMHandler adds a callback for depth-of-market updates
+func (s *DataSubscriber) AddDOMHandler(handler func(marketdata.DOM)) HandlerID {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.OnChartUpdate = append(s.OnChartUpdate, handler)
+	s.nextHandlerID++
+	s.domHandlers = append(s.domHandlers, &domHandler{id: s.nextHandlerID, fn: handler})
+	return s.nextHandlerID
 }
 
-// handlePropsEvent handles incremental updates
+// RemoveDOMHandler removes a depth-of-market callback
+func (s *DataSubscriber) RemoveDOMHandler(id HandlerID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := make([]*domHandler, 0, len(s.domHandlers))
+	for _, h := range s.domHandlers {
+		if h.id == id {
+			h.removed.Store(true)
+			continue
+		}
+		kept = append(kept, h)
+	}
+	s.domHandlers = kept
+}
+
+// handlePropsEvent handles incremental updates. Entity types without a handler
+// are counted, and each is logged once at debug level.
 func (s *DataSubscriber) handlePropsEvent(data json.RawMessage) {
 	var props struct {
 		EntityType string          `json:"entityType"`
@@ -190,8 +363,18 @@ func (s *DataSubscriber) handlePropsEvent(data json.RawMessage) {
 
 	switch props.EntityType {
 	case "order":
-		if s.OnOrderUpdate != nil {
-			s.OnOrderUpdate(props.Entity)
+		s.dispatchOrderUpdate(props.Entity)
+	case marketdata.EventFill:
+		if s.OnFillUpdate != nil {
+			s.OnFillUpdate(props.Entity)
+		}
+	case marketdata.EventExecutionReport:
+		if s.OnExecutionReport != nil {
+			s.OnExecutionReport(props.Entity)
+		}
+	case marketdata.EventFillPair:
+		if s.OnFillPairUpdate != nil {
+			s.OnFillPairUpdate(props.Entity)
 		}
 	case marketdata.EventPosition:
 		if s.OnPositionUpdate != nil {
@@ -201,18 +384,80 @@ func (s *DataSubscriber) handlePropsEvent(data json.RawMessage) {
 		if s.OnCashBalanceUpdate != nil {
 			s.OnCashBalanceUpdate(props.Entity)
 		}
+	case marketdata.EventMargin:
+		if s.OnMarginSnapshot != nil {
+			s.OnMarginSnapshot(props.Entity)
+		}
+	default:
+		s.mu.Lock()
+		s.unknownProps[props.EntityType]++
+		first := s.unknownProps[props.EntityType] == 1
+		s.mu.Unlock()
+		if first && s.log != nil {
+			s.log.Debugf("Ignoring props entity type %q (logged once)", props.EntityType)
+		}
 	}
 }
 
+// GetUnknownPropsStats returns how many props events of each entity type were
+// dropped because nothing handles that type
+func (s *DataSubscriber) GetUnknownPropsStats() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stats := make(map[string]int, len(s.unknownProps))
+	for entityType, n := range s.unknownProps {
+		stats[entityType] = n
+	}
+	return stats
+}
+
+// dispatchOrderUpdate delivers an order event to OnOrderUpdate unless it is a
+// duplicate or arrives out of order
+func (s *DataSubscriber) dispatchOrderUpdate(data json.RawMessage) {
+	if s.OnOrderUpdate == nil {
+		return
+	}
+
+	switch s.orderRouter.Route(data) {
+	case OrderEventDuplicate:
+		if s.log != nil {
+			s.log.Debugf("Suppressed duplicate order event: %s", string(data))
+		}
+		return
+	case OrderEventOutOfOrder:
+		if s.log != nil {
+			s.log.Warnf("Dropped out-of-order order event: %s", string(data))
+		}
+		return
+	}
+
+	s.OnOrderUpdate(data)
+}
+
+// GetOrderEventStats returns the number of duplicate and out-of-order order
+// events that were suppressed
+func (s *DataSubscriber) GetOrderEventStats() (duplicates, outOfOrder int) {
+	return s.orderRouter.Stats()
+}
+
 // handleUserEvent processes user sync events
 func (s *DataSubscriber) handleUserEvent(data json.RawMessage) {
 
 	var syncData APIUserSyncData
 
 	if err := json.Unmarshal(data, &syncData); err == nil {
-		if s.OnOrderUpdate != nil {
-			for _, order := range syncData.Orders {
-				s.OnOrderUpdate(order)
+		for _, order := range syncData.Orders {
+			s.dispatchOrderUpdate(order)
+		}
+		if s.OnFillUpdate != nil {
+			for _, fill := range syncData.Fills {
+				s.OnFillUpdate(fill)
+			}
+		}
+		// After the fills, so each pair's fills are known
+		if s.OnFillPairUpdate != nil {
+			for _, pair := range syncData.FillPairs {
+				s.OnFillPairUpdate(pair)
 			}
 		}
 		if s.OnPositionUpdate != nil {
@@ -226,20 +471,60 @@ func (s *DataSubscriber) handleUserEvent(data json.RawMessage) {
 				s.OnCashBalanceUpdate(bal)
 			}
 		}
+		if s.OnMarginSnapshot != nil {
+			for _, snap := range syncData.MarginSnapshots {
+				s.OnMarginSnapshot(snap)
+			}
+		}
 
 		return
 	}
 
 	// Fallback
-	if s.OnOrderUpdate != nil {
-		s.OnOrderUpdate(data)
-	}
+	s.dispatchOrderUpdate(data)
 	if s.OnPositionUpdate != nil {
 		s.OnPositionUpdate(data)
 	}
 }
 
-// handleMarketData processes market data events (quotes)
+// rememberQuote merges quote into the latest quote of its contract. Tradovate
+// sends only the entries that changed, so each entry keeps its last value.
+func (s *DataSubscriber) rememberQuote(quote marketdata.Quote) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastQuotes == nil {
+		s.lastQuotes = make(map[int]marketdata.Quote)
+	}
+	last, ok := s.lastQuotes[quote.ContractID]
+	if !ok {
+		last.Entries = make(map[string]marketdata.Entry, len(quote.Entries))
+	}
+	for name, entry := range quote.Entries {
+		last.Entries[name] = entry
+	}
+	last.ContractID, last.Timestamp, last.Time = quote.ContractID, quote.Timestamp, quote.Time
+	s.lastQuotes[quote.ContractID] = last
+}
+
+// LastQuote returns the latest value of each quote entry received for
+// contractID, and false if none has been
+func (s *DataSubscriber) LastQuote(contractID int) (marketdata.Quote, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	last, ok := s.lastQuotes[contractID]
+	if !ok {
+		return marketdata.Quote{}, false
+	}
+	entries := make(map[string]marketdata.Entry, len(last.Entries))
+	for name, entry := range last.Entries {
+		entries[name] = entry
+	}
+	last.Entries = entries
+	return last, true
+}
+
+// handleMarketData processes market data events (quotes and DOM). Both arrive
+// on the "md" event, so one payload may carry either or both.
 func (s *DataSubscriber) handleMarketData(data json.RawMessage) {
 	quoteData, err := marketdata.ParseQuoteData(data)
 	if err != nil {
@@ -249,13 +534,38 @@ func (s *DataSubscriber) handleMarketData(data json.RawMessage) {
 		return
 	}
 
+	// Removal replaces the slices, so these snapshots are safe to range over unlocked
 	s.mu.RLock()
-	handlers := s.OnQuoteUpdate
+	handlers := s.quoteHandlers
+	domHandlers := s.domHandlers
 	s.mu.RUnlock()
 
 	for _, quote := range quoteData.Quotes {
-		for _, handler := range handlers {
-			handler(quote)
+		quote.Time = s.clock.Time(quote.Timestamp)
+		s.rememberQuote(quote)
+		for _, h := range handlers {
+			if h.removed.Load() || (h.contractID != 0 && h.contractID != quote.ContractID) {
+				continue
+			}
+			h.fn(quote)
+		}
+	}
+
+	if len(domHandlers) == 0 {
+		return
+	}
+	domData, err := marketdata.ParseDOMData(data)
+	if err != nil {
+		if s.log != nil {
+			s.log.Errorf("Error unmarshaling DOM data: %v", err)
+		}
+		return
+	}
+	for _, dom := range domData.Doms {
+		for _, h := range domHandlers {
+			if !h.removed.Load() {
+				h.fn(dom)
+			}
 		}
 	}
 }
@@ -282,98 +592,215 @@ func (s *DataSubscriber) handleChartData(data json.RawMessage) {
 	}
 
 	s.mu.RLock()
-	handlers := s.OnChartUpdate
+	handlers := s.chartHandlers
 	s.mu.RUnlock()
 
-	for _, handler := range handlers {
-		handler(*chartUpdate)
+	for _, h := range handlers {
+		if h.removed.Load() {
+			continue
+		}
+		if h.symbol == "" {
+			h.fn(*chartUpdate)
+			continue
+		}
+		if update := s.chartsFor(h.symbol, chartUpdate); len(update.Charts) > 0 {
+			h.fn(update)
+		}
+	}
+}
+
+// chartsFor returns the charts in update whose subscription is for symbol
+func (s *DataSubscriber) chartsFor(symbol string, update *marketdata.ChartUpdate) marketdata.ChartUpdate {
+	var filtered marketdata.ChartUpdate
+	for _, chart := range update.Charts {
+		if chartSymbol, ok := s.ChartSymbol(chart.ID); ok && chartSymbol == symbol {
+			filtered.Charts = append(filtered.Charts, chart)
+		}
 	}
+	return filtered
 }
 
-// SubscribeQuote subscribes to real-time quote data for a symbol
-func (s *DataSubscriber) SubscribeQuote(symbol interface{}) error {
-	endpoint := "md/subscribequote"
+// SubscribeDOM subscribes to depth-of-market updates for a symbol. Repeated
+// subscriptions share one server stream and are reference counted.
+func (s *DataSubscriber) SubscribeDOM(symbol interface{}) error {
 	params := map[string]interface{}{
 		"symbol": symbol,
 	}
 
-	// Check if already subscribed
-	_, exists := s.isSubscribed(endpoint, params)
-	if exists {
+	if _, exists := s.isSubscribed(domEndpoint, params); exists {
+		s.addSubscription(domEndpoint, params, 0)
 		return nil
 	}
 
-	// Send subscription request
-	if err := s.client.Send(endpoint, params); err != nil {
+	key := s.addSubscription(domEndpoint, params, 0)
+	if err := s.sendSubscription(domEndpoint, params, []string{key}); err != nil {
+		s.removeSubscription(key)
 		return err
 	}
 
-	// Add to subscriptions
-	s.addSubscription(endpoint, params, 0)
-
 	if s.log != nil {
-		s.log.Debugf("Subscribed to %s for %v", endpoint, symbol)
+		s.log.Debugf("Subscribed to DOM for %v", symbol)
 	}
 	return nil
 }
 
-// UnsubscribeQuote unsubscribes from quote data
-func (s *DataSubscriber) UnsubscribeQuote(symbol interface{}) error {
-	subscribeEndpoint := "md/subscribequote"
-	unsubscribeEndpoint := "md/unsubscribequote"
+// UnsubscribeDOM releases a DOM subscription, stopping the stream once no
+// references remain
+func (s *DataSubscriber) UnsubscribeDOM(symbol interface{}) error {
 	params := map[string]interface{}{
 		"symbol": symbol,
 	}
 
-	// Get the key using subscribe endpoint (since that's what we stored it as)
-	key, exists := s.isSubscribed(subscribeEndpoint, params)
+	key, exists := s.isSubscribed(domEndpoint, params)
 	if !exists {
 		if s.log != nil {
-			s.log.Debugf("Not subscribed to quotes for %v", symbol)
+			s.log.Debugf("Not subscribed to DOM for %v", symbol)
 		}
 		return nil
 	}
 
-	// Check if we should actually unsubscribe
-	shouldUnsubscribe, _ := s.removeSubscription(key)
-	if !shouldUnsubscribe {
+	if shouldUnsubscribe, _ := s.removeSubscription(key); !shouldUnsubscribe {
+		return nil
+	}
+
+	if err := s.client.Send(domUnsubEndpoint, params); err != nil {
+		return err
+	}
+
+	if s.log != nil {
+		s.log.Debugf("Unsubscribed from DOM for %v", symbol)
+	}
+	return nil
+}
+
+// SubscribeChart requests chart data and tracks it as a subscription so it can be
+// cancelled with UnsubscribeChart once Tradovate has assigned it a chart ID
+func (s *DataSubscriber) SubscribeChart(params marketdata.HistoricalDataParams) error {
+	keyParams := chartKeyParams(params)
+
+	// Check if already subscribed
+	if key, exists := s.isSubscribed(chartEndpoint, keyParams); exists {
+		s.addSubscription(chartEndpoint, keyParams, 0)
 		if s.log != nil {
-			s.log.Warnf("Still have active references to quotes for %v", symbol)
+			s.log.Debugf("Already subscribed to chart %s", key)
 		}
 		return nil
 	}
 
-	// Send unsubscribe request
-	if err := s.client.Send(unsubscribeEndpoint, params); err != nil {
+	key := s.addSubscription(chartEndpoint, keyParams, 0)
+	requestID := 0
+	var err error
+	if rs, ok := s.client.(marketdata.RequestSender); ok {
+		// Held across the send so the response cannot be handled before the chart is pending
+		s.mu.Lock()
+		requestID, err = rs.SendRequest(chartEndpoint, params)
+		if err == nil {
+			s.pendingCharts[requestID] = key
+		}
+		s.mu.Unlock()
+	} else {
+		err = s.client.Send(chartEndpoint, params)
+	}
+	if err != nil {
+		s.removeSubscription(key)
 		return err
 	}
 
 	if s.log != nil {
-		s.log.Debugf("Unsubscribed from quotes for %v", symbol)
+		s.log.Debugf("Subscribed to chart for %v (request %d)", params.Symbol, requestID)
 	}
 	return nil
 }
 
-// GetChart requests chart data (historical and/or live)
-// Note: This is NOT a subscription, it's a one-time data request
-func (s *DataSubscriber) GetChart(params marketdata.HistoricalDataParams) error {
+// UnsubscribeChart cancels a chart subscription started with SubscribeChart
+func (s *DataSubscriber) UnsubscribeChart(params marketdata.HistoricalDataParams) error {
+	key := s.makeSubscriptionKey(chartEndpoint, chartKeyParams(params))
+
+	shouldUnsubscribe, info := s.removeSubscription(key)
+	if info == nil {
+		if s.log != nil {
+			s.log.Debugf("Not subscribed to chart for %v", params.Symbol)
+		}
+		return nil
+	}
+	if !shouldUnsubscribe {
+		return nil
+	}
+
+	if info.ChartID == 0 {
+		// Still waiting on the confirmation; handleChartSubscriptionResponse cancels it
+		if s.log != nil {
+			s.log.Debugf("Chart for %v not confirmed yet, cancel deferred", params.Symbol)
+		}
+		return nil
+	}
 
-	if err := s.client.Send("md/getchart", params); err != nil {
+	if err := s.cancelChart(info.ChartID); err != nil {
 		return err
 	}
 
 	if s.log != nil {
-		s.log.Debugf("Requested chart data for %v", params.Symbol)
+		s.log.Debugf("Unsubscribed from chart %d for %v", info.ChartID, params.Symbol)
 	}
 	return nil
 }
 
-// SubscribeUserSyncRequests subscribes to user sync updates
-func (s *DataSubscriber) SubscribeUserSyncRequests(users []int) error {
+// ChartSymbol returns the symbol of the confirmed chart subscription with
+// chartID as its realtime or historical ID
+func (s *DataSubscriber) ChartSymbol(chartID int) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, info := range s.subscriptions {
+		if info.Endpoint == chartEndpoint && chartID != 0 && (info.ChartID == chartID || info.HistID == chartID) {
+			symbol, ok := info.Params["symbol"].(string)
+			return symbol, ok
+		}
+	}
+	return "", false
+}
+
+// ChartIDs returns the IDs the chart subscribed with params arrives on, its
+// realtime ID and any historical ID, or nil until the subscription is confirmed
+func (s *DataSubscriber) ChartIDs(params marketdata.HistoricalDataParams) []int {
+	key := s.makeSubscriptionKey(chartEndpoint, chartKeyParams(params))
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	info, ok := s.subscriptions[key]
+	if !ok || info.ChartID == 0 {
+		return nil
+	}
+	ids := []int{info.ChartID}
+	if info.HistID != 0 && info.HistID != info.ChartID {
+		ids = append(ids, info.HistID)
+	}
+	return ids
+}
+
+// cancelChart stops a chart stream on the server
+func (s *DataSubscriber) cancelChart(chartID int) error {
+	return s.client.Send("md/cancelchart", map[string]interface{}{
+		"subscriptionId": chartID,
+	})
+}
+
+// chartKeyParams identifies a chart subscription by symbol and bar type, ignoring the time range
+func chartKeyParams(params marketdata.HistoricalDataParams) map[string]interface{} {
+	return map[string]interface{}{
+		"symbol":           params.Symbol,
+		"chartDescription": params.ChartDescription,
+	}
+}
+
+// SubscribeUserSyncRequests subscribes to user sync updates, limited to the given
+// accounts when any are passed
+func (s *DataSubscriber) SubscribeUserSyncRequests(users []int, accounts []int) error {
 	endpoint := "user/syncrequest"
 	params := map[string]interface{}{
 		"users": users,
 	}
+	if len(accounts) > 0 {
+		params["accounts"] = accounts
+	}
 
 	// Check if already subscribed
 	_, exists := s.isSubscribed(endpoint, params)
@@ -381,12 +808,12 @@ func (s *DataSubscriber) SubscribeUserSyncRequests(users []int) error {
 		return nil
 	}
 
-	if err := s.client.Send(endpoint, params); err != nil {
+	key := s.addSubscription(endpoint, params, 0)
+	if err := s.sendSubscription(endpoint, params, []string{key}); err != nil {
+		s.removeSubscription(key)
 		return err
 	}
 
-	s.addSubscription(endpoint, params, 0)
-
 	if s.log != nil {
 		s.log.Debug("Subscribed to user sync requests")
 	}
@@ -402,8 +829,12 @@ func (s *DataSubscriber) UnsubscribeAll() error {
 	}
 	s.mu.Unlock()
 
-	s.log.Debug("Active Subscription to Disconnect from: ", subscriptions)
+	if s.log != nil {
+		s.log.Debug("Active Subscription to Disconnect from: ", subscriptions)
+	}
 
+	// Quote streams are stopped together in one request
+	var quoteSymbols []interface{}
 	for key, info := range subscriptions {
 		// Force unsubscribe by setting ref count to 1 then removing
 		s.mu.Lock()
@@ -415,11 +846,25 @@ func (s *DataSubscriber) UnsubscribeAll() error {
 		var unsubParams map[string]interface{}
 
 		switch info.Endpoint {
-		case "md/subscribequote":
-			unsubEndpoint = "md/unsubscribequote"
+		case quoteEndpoint:
+			s.removeSubscription(key)
+			quoteSymbols = append(quoteSymbols, info.Params["symbol"])
+			continue
+		case domEndpoint:
+			unsubEndpoint = domUnsubEndpoint
 			unsubParams = map[string]interface{}{
 				"symbol": info.Params["symbol"],
 			}
+		case chartEndpoint:
+			if info.ChartID == 0 {
+				// Unconfirmed; cancelled when the response arrives
+				s.removeSubscription(key)
+				continue
+			}
+			unsubEndpoint = "md/cancelchart"
+			unsubParams = map[string]interface{}{
+				"subscriptionId": info.ChartID,
+			}
 		default:
 			// For other subscriptions, might not have an unsubscribe
 			continue
@@ -436,6 +881,14 @@ func (s *DataSubscriber) UnsubscribeAll() error {
 		}
 	}
 
+	if len(quoteSymbols) > 0 {
+		if err := s.client.Send(quoteUnsubEndpoint, quoteRequest(quoteSymbols)); err != nil {
+			if s.log != nil {
+				s.log.Errorf("Error unsubscribing from %s: %v", quoteEndpoint, err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -456,6 +909,7 @@ func (s *DataSubscriber) GetActiveSubscriptions() map[string]*SubscriptionInfo {
 			Endpoint: v.Endpoint,
 			Params:   paramsCopy,
 			ChartID:  v.ChartID,
+			HistID:   v.HistID,
 			RefCount: v.RefCount,
 		}
 	}
@@ -1,8 +1,11 @@
 package tradovate
 
 import (
+	"compress/gzip"
 	"encoding/json"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 	"tradovate-execution-engine/engine/internal/logger"
 	"tradovate-execution-engine/engine/internal/marketdata"
@@ -15,44 +18,247 @@ type SubscriptionInfo struct {
 	Endpoint string                 // e.g., "md/subscribequote"
 	Params   map[string]interface{} // The body/params that uniquely identify this subscription
 	ChartID  int                    // For chart subscriptions (returned by server)
+	HistID   int                    // For chart subscriptions, the ID history may arrive on instead
 	RefCount int                    // Reference counting for shared subscriptions
 }
 
+// HistoryRequest asks LoadHistory for Bars bars of Params' chart, ending at its
+// ClosestTimestamp (the latest bar when empty). Params' AsMuchAsElements is ignored.
+type HistoryRequest struct {
+	Params       marketdata.HistoricalDataParams
+	Bars         int
+	PageSize     int           // Bars per md/getchart (0 = HistoryPageSize)
+	StallTimeout time.Duration // Longest wait for the next packet of a page (0 = DefaultHistoryStallTimeout)
+}
+
+// ChartError is an md/getchart request Tradovate refused, for example for an
+// unknown symbol or a login without market data for it
+type ChartError struct {
+	RequestID int
+	Symbol    string
+	Status    int    // Response status; 200 when the refusal came in the payload
+	Text      string // Tradovate's reason
+}
+
+// WSError is a request we sent over the WebSocket that Tradovate answered with
+// an error status
+type WSError struct {
+	RequestID  int
+	URL        string
+	Status     int
+	StatusText string   // Tradovate's reason
+	Symbols    []string // Symbols of the subscriptions it refused, if any
+}
+
+// chartRequest is a GetChart request awaiting its response
+type chartRequest struct {
+	symbol string
+	done   chan error // Buffered; receives nil or the *ChartError once
+}
+
+// historyPage collects one md/getchart reply of a paginated history load
+type historyPage struct {
+	mu         sync.Mutex
+	symbol     string
+	ids        map[int]bool // Historical and realtime chart IDs from the response
+	realtimeID int          // Cancelled once the page is complete
+	bars       []marketdata.Bar
+	done       bool
+	err        error         // Why Tradovate refused the page's request
+	eoh        chan struct{} // Closed at the page's end of history, or when it fails
+	progress   chan struct{} // Signalled on each packet, to reset the stall timer
+}
+
 type DataSubscriber struct {
-	client        marketdata.WebSocketSender
-	log           *logger.Logger
-	mu            sync.RWMutex
-	subscriptions map[string]*SubscriptionInfo // key: hash of endpoint+params
+	client         marketdata.WebSocketSender
+	log            *logger.Logger
+	mu             sync.RWMutex
+	subscriptions  map[string]*SubscriptionInfo // key: hash of endpoint+params
+	pendingCharts  map[int]string               // request ID -> subscription key awaiting its chart ID
+	pendingHistory map[int]*historyPage         // request ID -> history page awaiting its chart IDs
+	pendingGets    map[int]*chartRequest        // request ID -> GetChart request awaiting its response
+	pendingSubs    map[int][]string             // request ID -> keys of the other subscriptions it started
+	orderRouter    *OrderEventRouter
+	unknownProps   map[string]int           // props entity type -> events dropped for want of a handler
+	clock          *marketdata.FeedClock    // Parses quote timestamps and tracks feed skew
+	lastQuotes     map[int]marketdata.Quote // Contract ID -> latest value of each quote entry
+
+	// Market data handlers, in registration order
+	quoteHandlers []*quoteHandler
+	chartHandlers []*chartHandler
+	chartErrors   []*chartErrorHandler
+	requestErrors []*requestErrorHandler
+	domHandlers   []*domHandler
+	nextHandlerID HandlerID
 
 	// Callbacks
-	OnQuoteUpdate       []func(marketdata.Quote)
-	OnChartUpdate       []func(marketdata.ChartUpdate)
 	OnOrderUpdate       func(json.RawMessage)
+	OnFillUpdate        func(json.RawMessage)
+	OnExecutionReport   func(json.RawMessage)
+	OnFillPairUpdate    func(json.RawMessage)
 	OnPositionUpdate    func(json.RawMessage)
 	OnUserSync          func(json.RawMessage)
 	OnCashBalanceUpdate func(json.RawMessage)
+	OnMarginSnapshot    func(json.RawMessage)
+}
+
+// OrderError is an order Tradovate refused, from a placeorder response or an
+// order event. Error returns a readable message; Reason keeps the raw code.
+type OrderError struct {
+	StatusCode    int     // HTTP status of the request, 0 for order events
+	Reason        string  // failureReason or rejectReason code, e.g. "InsufficientMargin"
+	Text          string  // Tradovate's free-form detail (failureText or text)
+	PenaltyTicket string  // p-ticket when the request was rate limited
+	PenaltyTime   float64 // p-time, seconds to wait before retrying
+	Body          string  // Raw body when it is not a recognised error
+}
+
+// HandlerID identifies a registered market data handler so it can be removed
+type HandlerID int
+
+// quoteHandler is a registered quote callback; contractID 0 receives every contract
+type quoteHandler struct {
+	id         HandlerID
+	contractID int
+	fn         func(marketdata.Quote)
+	removed    atomic.Bool // Set by RemoveQuoteHandler; dispatch skips removed handlers
+}
+
+// chartHandler is a registered chart callback; an empty symbol receives every chart
+type chartHandler struct {
+	id      HandlerID
+	symbol  string
+	fn      func(marketdata.ChartUpdate)
+	removed atomic.Bool
+}
+
+// chartErrorHandler is a registered callback for refused chart requests; an
+// empty symbol receives every refusal
+type chartErrorHandler struct {
+	id      HandlerID
+	symbol  string
+	fn      func(*ChartError)
+	removed atomic.Bool
+}
+
+// requestErrorHandler is a registered callback for refused requests other than
+// charts; an empty symbol receives every refusal
+type requestErrorHandler struct {
+	id      HandlerID
+	symbol  string
+	fn      func(*WSError)
+	removed atomic.Bool
+}
+
+// domHandler is a registered depth-of-market callback
+type domHandler struct {
+	id      HandlerID
+	fn      func(marketdata.DOM)
+	removed atomic.Bool
+}
+
+// OrderEventVerdict is the outcome of routing a single order event
+type OrderEventVerdict int
+
+const (
+	OrderEventDeliver OrderEventVerdict = iota
+	OrderEventDuplicate
+	OrderEventOutOfOrder
+)
+
+// orderEventState is the last delivered state of an order
+type orderEventState struct {
+	status    string
+	timestamp time.Time
+	hasTime   bool
+}
+
+// OrderEventRouter suppresses repeated order events so each distinct state
+// transition (order id + ordStatus + timestamp) is delivered exactly once.
+// Tradovate sends the same order in user/syncrequest snapshots and props events.
+// An order's seen states are dropped once it is filled, cancelled, rejected or
+// expired.
+type OrderEventRouter struct {
+	mu         sync.Mutex
+	seen       map[int]map[string]struct{} // Per working order, status|timestamp
+	lastState  map[int]orderEventState
+	finished   []int // Finished orders whose last state is kept, oldest first
+	duplicates int
+	outOfOrder int
 }
 
 // MessageHandler is a callback for processing incoming WebSocket messages
 type MessageHandler func(eventType string, data json.RawMessage)
 
+// ResponseHandler is a callback for responses to requests we sent, keyed by request ID
+type ResponseHandler func(requestID int, url string, data json.RawMessage)
+
+// RequestErrorHandler is a callback for requests we sent that Tradovate answered
+// with an error status, with the reason it gave
+type RequestErrorHandler func(requestID int, url string, status int, text string)
+
 // TradovateWebSocketClient manages WebSocket connection lifecycle
 type TradovateWebSocketClient struct {
 	accessToken  string
 	wsURL        string
+	name         string // "md" or "trading", labels this connection's metrics
 	conn         *websocket.Conn
 	isAuthorized bool
 	mu           sync.RWMutex
 	log          *logger.Logger
 
 	// Message handler for routing events
-	messageHandler MessageHandler
+	messageHandler  MessageHandler
+	responseHandler ResponseHandler
+
+	requestErrorHandler RequestErrorHandler
 
 	// Refinements
 	nextRequestID   uint32
 	openChan        chan struct{}
 	pendingRequests map[uint32]string
 	heartbeatStop   chan struct{}
+
+	// Watchdog for a connection that stays open but stops delivering frames
+	lastMessageAt int64         // Unix nanos of the last frame received (atomic)
+	staleTimeout  time.Duration // No frame for this long marks the connection stale
+	stale         bool
+	onStale       func()
+	expectDown    bool // A scheduled outage: losing the connection is logged without alarm
+
+	recorder *Recorder // Copies every frame to a recording when set
+
+	// A re-authorization waiting for its answer
+	reauthID   uint32
+	reauthDone chan WSResponse
+
+	// Frames are written by one goroutine per connection, each within writeTimeout
+	outbound      chan string
+	heartbeat     chan struct{} // A keepalive is due; written ahead of queued frames
+	writerStop    chan struct{} // Closed to end the writer
+	sendQueueSize int           // Frames that may wait in outbound (0 = default)
+	writeTimeout  time.Duration // 0 = default
+	onSendError   func(error)
+}
+
+// Recorder appends raw WebSocket frames, with the time each was seen, to a
+// gzipped JSON lines file so a session can be replayed later
+type Recorder struct {
+	mu        sync.Mutex
+	path      string
+	file      *os.File
+	gz        *gzip.Writer
+	enc       *json.Encoder
+	frames    int
+	lastFlush time.Time
+	err       error // First write error; recording stops after it
+}
+
+// RecordedFrame is one line of a recording
+type RecordedFrame struct {
+	Time  time.Time `json:"t"`
+	Dir   string    `json:"dir"` // "in" from the server, "out" to it
+	Frame string    `json:"frame"`
 }
 
 // WSResponse represents a WebSocket response from Tradovate
@@ -66,11 +272,95 @@ type WSResponse struct {
 
 // APIPosition represents a Tradovate position
 type APIPosition struct {
+	ID          int     `json:"id"`
+	AccountID   int     `json:"accountId"`
+	ContractID  int     `json:"contractId"`
+	NetPos      int     `json:"netPos"`
+	Bought      int     `json:"bought"`
+	BoughtValue float64 `json:"boughtValue"`
+	Sold        int     `json:"sold"`
+	SoldValue   float64 `json:"soldValue"`
+	NetPrice    float64 `json:"netPrice"`
+	PrevPrice   float64 `json:"prevPrice"`
+}
+
+// APIFill is one execution against an order
+type APIFill struct {
+	ID         int     `json:"id"`
+	OrderID    int     `json:"orderId"`
 	ContractID int     `json:"contractId"`
-	NetPos     int     `json:"netPos"`
-	Bought     int     `json:"bought"`
-	NetPrice   float64 `json:"netPrice"`
-	PrevPrice  float64 `json:"prevPrice"`
+	Timestamp  string  `json:"timestamp"`
+	Action     string  `json:"action"` // "Buy" or "Sell"
+	Qty        int     `json:"qty"`
+	Price      float64 `json:"price"`
+}
+
+// OrderEvent is an order entity from any of the shapes Tradovate sends, as read
+// by DecodeOrderEvent. The Has fields tell a missing value from a zero one.
+type OrderEvent struct {
+	ID           int // Exchange order ID
+	AccountID    int
+	ContractID   int
+	Action       string // "Buy" or "Sell"
+	OrderType    string
+	OrdStatus    string
+	RejectReason string
+	Text         string
+	Timestamp    time.Time // UTC
+	HasTimestamp bool
+	Qty          int
+	HasQty       bool
+	Price        float64
+	HasPrice     bool
+	StopPrice    float64
+	HasStopPrice bool
+	CumQty       int // Quantity filled so far, when the event carries it
+	HasCumQty    bool
+	AvgPrice     float64 // Average fill price (avgPx or avgPrice)
+	HasAvgPrice  bool
+}
+
+// FillEvent is a fill entity as read by DecodeFillEvent
+type FillEvent struct {
+	ID           int // Exchange fill ID, 0 if missing
+	OrderID      int
+	ContractID   int
+	Action       string
+	Qty          int
+	Price        float64
+	Timestamp    time.Time // UTC
+	HasTimestamp bool
+}
+
+// ExecutionReport is an executionReport entity as read by DecodeExecutionReport:
+// Tradovate's account of one step in an order's life. Trade reports carry the
+// last fill and the cumulative quantity; fills themselves arrive as fill entities.
+type ExecutionReport struct {
+	ID           int // Report ID, 0 if missing
+	OrderID      int
+	ContractID   int
+	ExecType     string // "New", "Trade", "Canceled", "Rejected", ...
+	OrdStatus    string // Order status after this report
+	Action       string
+	CumQty       int // Quantity filled so far
+	AvgPx        float64
+	LastQty      int // Quantity of the fill this report is for, Trade only
+	LastPx       float64
+	RejectReason string
+	Text         string
+	Timestamp    time.Time // UTC
+	HasTimestamp bool
+}
+
+// APIFillPair is a buy fill and a sell fill Tradovate matched into a closed trade
+type APIFillPair struct {
+	ID         int     `json:"id"`
+	PositionID int     `json:"positionId"`
+	BuyFillID  int     `json:"buyFillId"`
+	SellFillID int     `json:"sellFillId"`
+	Qty        int     `json:"qty"`
+	BuyPrice   float64 `json:"buyPrice"`
+	SellPrice  float64 `json:"sellPrice"`
 }
 
 // APIContract represents a Tradovate contract
@@ -86,13 +376,31 @@ type APITradeDate struct {
 }
 
 type APICashBalance struct {
-	RealizedPnL float64 `json:"realizedPnL"`
+	AccountID       int          `json:"accountId"`
+	TradeDate       APITradeDate `json:"tradeDate"`
+	Amount          float64      `json:"amount"`    // Cash balance
+	AmountSOD       float64      `json:"amountSOD"` // Cash balance at the start of the trade date
+	RealizedPnL     float64      `json:"realizedPnL"`
+	WeekRealizedPnL float64      `json:"weekRealizedPnL"`
+}
+
+// APIMarginSnapshot is an account's margin requirement. Its ID is the account's.
+type APIMarginSnapshot struct {
+	ID                int     `json:"id"`
+	Timestamp         string  `json:"timestamp"`
+	InitialMargin     float64 `json:"initialMargin"`
+	MaintenanceMargin float64 `json:"maintenanceMargin"`
+	AutoLiqLevel      float64 `json:"autoLiqLevel"`
+	LiqOnlyLevel      float64 `json:"liqOnlyLevel"`
+	TotalUsedMargin   float64 `json:"totalUsedMargin"`
+	FullInitialMargin float64 `json:"fullInitialMargin"`
 }
 
 // APIProduct represents a Tradovate product
 type APIProduct struct {
 	Name          string  `json:"name"`
 	ValuePerPoint float64 `json:"valuePerPoint"`
+	TickSize      float64 `json:"tickSize"`
 }
 
 // APIUserSyncData represents the initial user sync response
@@ -100,11 +408,14 @@ type APIUserSyncData struct {
 	Users []struct {
 		ID int `json:"id"`
 	} `json:"users,omitempty"`
-	Positions    []APIPosition     `json:"positions,omitempty"`
-	Contracts    []APIContract     `json:"contracts,omitempty"`
-	Products     []APIProduct      `json:"products,omitempty"`
-	CashBalances []json.RawMessage `json:"cashBalances"`
-	Orders       []json.RawMessage `json:"orders"`
+	Positions       []APIPosition     `json:"positions,omitempty"`
+	Contracts       []APIContract     `json:"contracts,omitempty"`
+	Products        []APIProduct      `json:"products,omitempty"`
+	CashBalances    []json.RawMessage `json:"cashBalances"`
+	MarginSnapshots []json.RawMessage `json:"marginSnapshots"`
+	Orders          []json.RawMessage `json:"orders"`
+	Fills           []json.RawMessage `json:"fills"`
+	FillPairs       []json.RawMessage `json:"fillPairs"`
 }
 
 // APIAuthResponse represents the Tradovate authentication response
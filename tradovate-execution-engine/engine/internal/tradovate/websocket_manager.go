@@ -13,13 +13,15 @@ import (
 )
 
 // NewTradovateWebSocketClient creates a new WebSocket client
-func NewTradovateWebSocketClient(accessToken, environment, wsType string) *TradovateWebSocketClient {
+func NewTradovateWebSocketClient(accessToken string, environment config.Environment, wsType string) *TradovateWebSocketClient {
 	// Market data uses separate endpoints: md-demo and md-live
 
 	var wsURL string
+	name := "trading"
 	switch wsType {
 	case "md":
 		wsURL = config.GetMDWSBaseURL(environment)
+		name = "md"
 	default:
 		wsURL = config.GetWSBaseURL(environment)
 	}
@@ -27,12 +29,68 @@ func NewTradovateWebSocketClient(accessToken, environment, wsType string) *Trado
 	return &TradovateWebSocketClient{
 		accessToken:     accessToken,
 		wsURL:           wsURL,
+		name:            name,
 		openChan:        make(chan struct{}),
 		pendingRequests: make(map[uint32]string),
 		heartbeatStop:   make(chan struct{}),
+		staleTimeout:    defaultStaleTimeout,
 	}
 }
 
+// SetURL points the client at another WebSocket endpoint, such as a local test
+// server. Call it before Connect.
+func (c *TradovateWebSocketClient) SetURL(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.wsURL = url
+}
+
+// defaultStaleTimeout is how long the server may stay silent before the
+// connection is treated as dead. Tradovate sends 'h' frames every few seconds.
+const defaultStaleTimeout = 10 * time.Second
+
+// SetStaleTimeout sets how long without a frame marks the connection stale
+func (c *TradovateWebSocketClient) SetStaleTimeout(d time.Duration) {
+	if d <= 0 {
+		d = defaultStaleTimeout
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.staleTimeout = d
+}
+
+// SetStaleHandler sets a callback run (on its own goroutine) when the connection goes stale
+func (c *TradovateWebSocketClient) SetStaleHandler(handler func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onStale = handler
+}
+
+// SetExpectDown marks a scheduled outage, such as a Tradovate maintenance
+// window. While set, a stale or dropped connection is logged at info level
+// instead of as an error.
+func (c *TradovateWebSocketClient) SetExpectDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expectDown = down
+}
+
+// LastMessageAt returns when the last frame was received (zero before the first)
+func (c *TradovateWebSocketClient) LastMessageAt() time.Time {
+	nanos := atomic.LoadInt64(&c.lastMessageAt)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// IsStale reports whether the server has gone silent for longer than the stale timeout
+func (c *TradovateWebSocketClient) IsStale() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stale
+}
+
 // SetLogger sets the logger for the WebSocket client
 func (c *TradovateWebSocketClient) SetLogger(l *logger.Logger) {
 	c.mu.Lock()
@@ -40,6 +98,35 @@ func (c *TradovateWebSocketClient) SetLogger(l *logger.Logger) {
 	c.log = l
 }
 
+// SetRecorder copies every frame sent and received to r from now on. Disconnect
+// closes it.
+func (c *TradovateWebSocketClient) SetRecorder(r *Recorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recorder = r
+}
+
+// getRecorder returns the recorder, or nil when not recording
+func (c *TradovateWebSocketClient) getRecorder() *Recorder {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.recorder
+}
+
+// SetResponseHandler sets the callback for responses to our own requests.
+// When set it receives those responses instead of the message handler.
+func (c *TradovateWebSocketClient) SetResponseHandler(handler ResponseHandler) {
+	c.responseHandler = handler
+}
+
+// SetRequestErrorHandler sets the callback for our own requests that Tradovate
+// answered with an error status. Without one they are only logged.
+func (c *TradovateWebSocketClient) SetRequestErrorHandler(handler RequestErrorHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requestErrorHandler = handler
+}
+
 // SetMessageHandler sets the callback for handling incoming messages
 func (c *TradovateWebSocketClient) SetMessageHandler(handler MessageHandler) {
 	c.messageHandler = handler
@@ -51,18 +138,23 @@ func (c *TradovateWebSocketClient) Connect() error {
 		c.log.Debugf("Connecting to WebSocket: %s", c.wsURL)
 	}
 
-	var err error
-	c.conn, _, err = websocket.DefaultDialer.Dial(c.wsURL, nil)
+	conn, _, err := websocket.DefaultDialer.Dial(c.wsURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
+	c.mu.Lock()
+	c.conn = conn
+	c.startWriter(conn)
+	c.mu.Unlock()
+	c.markReceived()
+	wsConnects(c.name).Inc()
 
 	if c.log != nil {
 		c.log.Debug("WebSocket connected")
 	}
 
 	// Start message handler
-	go c.handleMessages()
+	go c.handleMessages(conn)
 
 	// Start proactive heartbeat
 	go c.startHeartbeat()
@@ -86,18 +178,19 @@ func (c *TradovateWebSocketClient) authorize() error {
 	}
 
 	// Tradovate uses plain text format delimited by newlines: authorize\n1\n\n{token}
+	c.mu.RLock()
 	authMsg := fmt.Sprintf("authorize\n1\n\n%s", c.accessToken)
+	c.mu.RUnlock()
 
 	if c.log != nil {
 		c.log.Debug("Sending authorization...")
 	}
 
 	c.mu.Lock()
-	if c.conn == nil {
-		c.mu.Unlock()
-		return fmt.Errorf("websocket not connected")
+	err := c.enqueue(authMsg)
+	if err == nil && c.recorder != nil {
+		c.recorder.Record(FrameOut, authMsg)
 	}
-	err := c.conn.WriteMessage(websocket.TextMessage, []byte(authMsg))
 	c.mu.Unlock()
 
 	if err != nil {
@@ -127,14 +220,86 @@ func (c *TradovateWebSocketClient) authorize() error {
 	}
 }
 
+// Reauthorize authorizes the open connection again with accessToken, after a
+// new session replaced the one it was authorized with. The connection and its
+// subscriptions are kept, and the next Connect uses the new token.
+func (c *TradovateWebSocketClient) Reauthorize(accessToken string) error {
+	done := make(chan WSResponse, 1)
+	c.mu.Lock()
+	if c.conn == nil || !c.isAuthorized {
+		c.mu.Unlock()
+		return fmt.Errorf("websocket not connected")
+	}
+	id := atomic.AddUint32(&c.nextRequestID, 1)
+	authMsg := fmt.Sprintf("authorize\n%d\n\n%s", id, accessToken)
+	c.reauthID, c.reauthDone = id, done
+	if err := c.enqueue(authMsg); err != nil {
+		c.reauthDone = nil
+		c.mu.Unlock()
+		return err
+	}
+	if c.recorder != nil {
+		c.recorder.Record(FrameOut, authMsg)
+	}
+	c.mu.Unlock()
+
+	select {
+	case response := <-done:
+		if response.Status != 200 {
+			return fmt.Errorf("authorization refused: status %d - %s", response.Status, responseErrorText(response))
+		}
+	case <-time.After(10 * time.Second):
+		c.mu.Lock()
+		if c.reauthDone == done {
+			c.reauthDone = nil
+		}
+		c.mu.Unlock()
+		return fmt.Errorf("authorization timeout - no response received")
+	}
+
+	c.mu.Lock()
+	c.accessToken = accessToken
+	c.mu.Unlock()
+	if c.log != nil {
+		c.log.Debug("WebSocket re-authorized")
+	}
+	return nil
+}
+
+// reauthorized hands response to a waiting Reauthorize, reporting whether it
+// was the answer to it
+func (c *TradovateWebSocketClient) reauthorized(response WSResponse) bool {
+	if response.ID == 0 {
+		return false
+	}
+	c.mu.Lock()
+	done := c.reauthDone
+	if done == nil || uint32(response.ID) != c.reauthID {
+		c.mu.Unlock()
+		return false
+	}
+	c.reauthDone = nil
+	c.mu.Unlock()
+	done <- response
+	return true
+}
+
 // Send sends a message through the WebSocket in Tradovate plain text format
 // Format: url\nrequest_id\n\njson_body (note the double newline before body)
 func (c *TradovateWebSocketClient) Send(url string, body interface{}) error {
+	_, err := c.SendRequest(url, body)
+	return err
+}
+
+// SendRequest sends a message like Send and returns its request ID. The frame
+// is queued for the connection's writer, so a slow connection does not block
+// the caller; ErrSendQueueFull is returned when too many are waiting.
+func (c *TradovateWebSocketClient) SendRequest(url string, body interface{}) (int, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if c.conn == nil {
-		return fmt.Errorf("websocket not connected")
+		return 0, fmt.Errorf("websocket not connected")
 	}
 
 	// Marshal body to JSON
@@ -142,7 +307,7 @@ func (c *TradovateWebSocketClient) Send(url string, body interface{}) error {
 	if body != nil {
 		jsonData, err := json.Marshal(body)
 		if err != nil {
-			return fmt.Errorf("failed to marshal body: %w", err)
+			return 0, fmt.Errorf("failed to marshal body: %w", err)
 		}
 		jsonBody = string(jsonData)
 	}
@@ -152,84 +317,109 @@ func (c *TradovateWebSocketClient) Send(url string, body interface{}) error {
 	requestID := atomic.AddUint32(&c.nextRequestID, 1)
 	c.pendingRequests[requestID] = url
 	message := fmt.Sprintf("%s\n%d\n\n%s", url, requestID, jsonBody)
-
-	return c.conn.WriteMessage(websocket.TextMessage, []byte(message))
+	if err := c.enqueue(message); err != nil {
+		delete(c.pendingRequests, requestID)
+		return 0, err
+	}
+	if c.recorder != nil {
+		c.recorder.Record(FrameOut, message)
+	}
+	return int(requestID), nil
 }
 
-// handleMessages processes incoming WebSocket messages
-func (c *TradovateWebSocketClient) handleMessages() {
+// handleMessages processes incoming WebSocket messages from conn
+func (c *TradovateWebSocketClient) handleMessages(conn *websocket.Conn) {
+	// The read loop ends once per connection, whether closed by us or the server
+	defer wsDisconnects(c.name).Inc()
+
 	for {
-		_, message, err := c.conn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
 			c.mu.RLock()
-			closed := c.conn == nil
+			closed, expected := c.conn != conn, c.expectDown
 			c.mu.RUnlock()
 
 			if closed {
 				return
 			}
 
-			if c.log != nil {
+			if c.log != nil && expected {
+				c.log.Infof("Connection closed during maintenance: %v", err)
+			} else if c.log != nil {
 				c.log.Warnf("Read error: %v", err)
 			}
 			return
 		}
 
-		// Parse Tradovate frame format
-		if len(message) == 0 {
-			continue
+		c.markReceived()
+		if r := c.getRecorder(); r != nil {
+			r.Record(FrameIn, string(message))
+		}
+		if c.handleFrame(message) {
+			return
 		}
+	}
+}
 
-		frameType := message[0]
-		payload := message[1:]
+// handleFrame parses one Tradovate frame and dispatches what it carries. It
+// reports whether the server closed the connection.
+func (c *TradovateWebSocketClient) handleFrame(message []byte) bool {
+	// Parse Tradovate frame format
+	if len(message) == 0 {
+		return false
+	}
 
-		switch frameType {
-		case 'o':
-			// Open frame - connection established
-			c.mu.Lock()
-			select {
-			case <-c.openChan:
-				// Already closed
-			default:
-				close(c.openChan)
-			}
-			c.mu.Unlock()
+	frameType := message[0]
+	payload := message[1:]
 
-			if c.log != nil {
-				c.log.Debug("WebSocket session opened")
-			}
+	switch frameType {
+	case 'o':
+		// Open frame - connection established
+		c.mu.Lock()
+		select {
+		case <-c.openChan:
+			// Already closed
+		default:
+			close(c.openChan)
+		}
+		c.mu.Unlock()
 
-		case 'h':
-			// Heartbeat frame - we send our own proactive heartbeats every 2.5s
-			// so we can just ignore the server's 'h' frame or log it.
-			// Handled by proactive heartbeat
+		if c.log != nil {
+			c.log.Debug("WebSocket session opened")
+		}
 
-		case 'a':
-			// Array frame - contains JSON data
-			c.handleArrayFrame(payload)
+	case 'h':
+		// Heartbeat frame - we send our own proactive heartbeats every 2.5s.
+		// Receiving it only refreshes lastMessageAt for the stale watchdog.
 
-		case 'c':
-			// Close frame
-			if c.log != nil {
-				c.log.Debugf("Server closing connection: %s", string(payload))
-			}
-			return
+	case 'a':
+		// Array frame - contains JSON data
+		c.handleArrayFrame(payload)
 
-		default:
-			if c.log != nil {
-				c.log.Warnf("Unknown frame type: %c, payload: %s", frameType, string(payload))
-			}
+	case 'c':
+		// Close frame
+		if c.log != nil {
+			c.log.Debugf("Server closing connection: %s", string(payload))
+		}
+		return true
+
+	default:
+		if c.log != nil {
+			c.log.Warnf("Unknown frame type: %c, payload: %s", frameType, string(payload))
 		}
 	}
+	return false
 }
 
-// sendHeartbeat sends a heartbeat response to keep connection alive
+// sendHeartbeat asks the writer for a heartbeat to keep the connection alive.
+// One already waiting is enough.
 func (c *TradovateWebSocketClient) sendHeartbeat() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
-	if c.conn != nil {
-		c.conn.WriteMessage(websocket.TextMessage, []byte("[]"))
+	select {
+	case c.heartbeat <- struct{}{}:
+	default:
 	}
 }
 
@@ -253,6 +443,11 @@ func (c *TradovateWebSocketClient) handleArrayFrame(payload []byte) {
 			continue
 		}
 
+		// Answer to a re-authorization of the open connection
+		if c.reauthorized(response) {
+			continue
+		}
+
 		// Handle authorization response
 		if response.Status == 200 && !c.isAuthorized {
 			c.mu.Lock()
@@ -264,13 +459,6 @@ func (c *TradovateWebSocketClient) handleArrayFrame(payload []byte) {
 			continue
 		}
 
-		// Log any errors
-		if response.Status != 0 && response.Status != 200 {
-			if c.log != nil {
-				c.log.Errorf("Error response: Status %d - %s", response.Status, response.StatusText)
-			}
-		}
-
 		// Handle event messages - delegate to message handler
 		if response.Event != "" && c.messageHandler != nil {
 			c.messageHandler(response.Event, response.Data)
@@ -284,10 +472,24 @@ func (c *TradovateWebSocketClient) handleArrayFrame(payload []byte) {
 			if ok {
 				delete(c.pendingRequests, uint32(response.ID))
 			}
+			errorHandler := c.requestErrorHandler
 			c.mu.Unlock()
 
+			if ok && response.Status != 200 && response.Status != 0 {
+				if errorHandler != nil {
+					errorHandler(response.ID, url, response.Status, responseErrorText(response))
+				} else if c.log != nil {
+					wsErr := &WSError{RequestID: response.ID, URL: url, Status: response.Status, StatusText: responseErrorText(response)}
+					c.log.Errorf("%v", wsErr)
+				}
+				continue
+			}
 			if ok {
-				c.messageHandler(url, response.Data)
+				if c.responseHandler != nil {
+					c.responseHandler(response.ID, url, response.Data)
+				} else {
+					c.messageHandler(url, response.Data)
+				}
 				continue
 			}
 		}
@@ -299,6 +501,19 @@ func (c *TradovateWebSocketClient) handleArrayFrame(payload []byte) {
 	}
 }
 
+// responseErrorText is the reason Tradovate gave for a failed request: its
+// statusText, else the payload, which is often a bare JSON string
+func responseErrorText(response WSResponse) string {
+	if response.StatusText != "" {
+		return response.StatusText
+	}
+	var text string
+	if err := json.Unmarshal(response.Data, &text); err == nil {
+		return text
+	}
+	return string(response.Data)
+}
+
 // handleResponse processes response messages
 func (c *TradovateWebSocketClient) handleResponse(response WSResponse) {
 	if response.Status == 200 {
@@ -307,7 +522,7 @@ func (c *TradovateWebSocketClient) handleResponse(response WSResponse) {
 		}
 	} else {
 		if c.log != nil {
-			c.log.Errorf("Request %d failed: Status %d - %s", response.ID, response.Status, response.StatusText)
+			c.log.Errorf("Request %d failed: Status %d - %s", response.ID, response.Status, responseErrorText(response))
 		}
 	}
 }
@@ -319,11 +534,49 @@ func (c *TradovateWebSocketClient) IsAuthorized() bool {
 	return c.isAuthorized
 }
 
-// IsConnected returns whether the WebSocket is currently connected and authorized
+// IsConnected returns whether the WebSocket is currently connected, authorized
+// and still receiving frames
 func (c *TradovateWebSocketClient) IsConnected() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.conn != nil && c.isAuthorized
+	return c.conn != nil && c.isAuthorized && !c.stale
+}
+
+// markReceived records a received frame and clears the stale flag
+func (c *TradovateWebSocketClient) markReceived() {
+	atomic.StoreInt64(&c.lastMessageAt, time.Now().UnixNano())
+
+	c.mu.Lock()
+	wasStale := c.stale
+	c.stale = false
+	c.mu.Unlock()
+
+	if wasStale && c.log != nil {
+		c.log.Infof("WebSocket %s receiving data again", c.wsURL)
+	}
+}
+
+// checkStale marks the connection stale once no frame has arrived within the timeout
+func (c *TradovateWebSocketClient) checkStale(now time.Time) {
+	last := c.LastMessageAt()
+
+	c.mu.Lock()
+	if c.stale || c.conn == nil || last.IsZero() || now.Sub(last) < c.staleTimeout {
+		c.mu.Unlock()
+		return
+	}
+	c.stale = true
+	handler, expected := c.onStale, c.expectDown
+	c.mu.Unlock()
+
+	if c.log != nil && expected {
+		c.log.Infof("WebSocket %s quiet during maintenance: no data for %s", c.wsURL, now.Sub(last).Round(time.Second))
+	} else if c.log != nil {
+		c.log.Errorf("WebSocket %s stale: no data for %s", c.wsURL, now.Sub(last).Round(time.Second))
+	}
+	if handler != nil {
+		go handler()
+	}
 }
 
 // Disconnect closes the WebSocket connection
@@ -337,6 +590,16 @@ func (c *TradovateWebSocketClient) Disconnect() error {
 	default:
 	}
 
+	if c.recorder != nil {
+		if err := c.recorder.Close(); err != nil && c.log != nil {
+			c.log.Warnf("Recording %s incomplete: %v", c.recorder.Path(), err)
+		} else if c.log != nil {
+			c.log.Infof("Recorded %d frames to %s", c.recorder.Frames(), c.recorder.Path())
+		}
+		c.recorder = nil
+	}
+
+	c.stopWriter()
 	if c.conn != nil {
 		err := c.conn.Close()
 		c.conn = nil
@@ -353,8 +616,9 @@ func (c *TradovateWebSocketClient) startHeartbeat() {
 
 	for {
 		select {
-		case <-ticker.C:
+		case now := <-ticker.C:
 			c.sendHeartbeat()
+			c.checkStale(now)
 		case <-c.heartbeatStop:
 			return
 		}
@@ -0,0 +1,453 @@
+package strategies
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"tradovate-execution-engine/engine/indicators"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// maxChannelLength caps channel_length; the channel keeps a window this long
+const maxChannelLength = 1000
+
+// DonchianBreakout implements a Donchian channel breakout strategy on bar
+// closes: it goes long on a close above the highest close of the previous
+// channel_length bars and short on a close below the lowest
+type DonchianBreakout struct {
+	execution.StrategyLifecycle
+
+	symbol         string
+	channel        *indicators.MaxMin
+	position       Position
+	channelLength  int
+	quantity       int
+	exitOnOpposite bool // Exit at the opposite channel instead of reversing on its breakout
+	broker         execution.Broker
+	logger         *logger.Logger
+	enabled        atomic.Bool
+
+	// Params and the channel are set on the goroutine that starts the strategy
+	// and the channel updates on the data goroutine, while the UI reads both
+	paramMu sync.RWMutex
+
+	// Track last bar timestamp to avoid processing same bar multiple times
+	lastBarTimestamp string
+
+	// Fill reconciliation
+	mu               sync.Mutex // Guards position and the pending order
+	pendingOrderID   string     // Order whose fill will move position to pendingPosition
+	pendingPosition  Position
+	listenerAttached execution.Broker
+	lastSignal       string // Position the last signal called for, "" before the first
+}
+
+// NewDonchianBreakout creates a new Donchian breakout strategy used for testing
+func NewDonchianBreakout(symbol string, length int, exitOnOpposite bool) *DonchianBreakout {
+	return &DonchianBreakout{
+		symbol:         symbol,
+		channelLength:  length,
+		quantity:       1,
+		exitOnOpposite: exitOnOpposite,
+		position:       Flat,
+	}
+}
+
+// NewDefaultDonchianBreakout creates a new Donchian breakout strategy with default settings
+func NewDefaultDonchianBreakout(l *logger.Logger) *DonchianBreakout {
+	d := NewDonchianBreakout("MESH6", 20, false)
+	d.logger = l
+	return d
+}
+
+// Name returns the strategy name
+func (d *DonchianBreakout) Name() string {
+	return "Donchian Breakout"
+}
+
+// Description returns the strategy description
+func (d *DonchianBreakout) Description() string {
+	return "Donchian channel breakout strategy - buys a close above the highest close of the last N bars and sells a close below the lowest, reversing or exiting at the opposite channel"
+}
+
+// GetParams returns the configurable parameters
+func (d *DonchianBreakout) GetParams() []execution.StrategyParam {
+	d.paramMu.RLock()
+	defer d.paramMu.RUnlock()
+	return []execution.StrategyParam{
+		{
+			Name:        "symbol",
+			Type:        "string",
+			Value:       d.symbol,
+			Description: "Trading symbol or product root (e.g. MES resolves to the front month)",
+		},
+		{
+			Name:        "channel_length",
+			Type:        "int",
+			Value:       strconv.Itoa(d.channelLength),
+			Description: "Bars whose highest and lowest close make the channel",
+			Min:         execution.Bound(1),
+			Max:         execution.Bound(maxChannelLength),
+		},
+		{
+			Name:        "quantity",
+			Type:        "int",
+			Value:       strconv.Itoa(d.quantity),
+			Description: "Contracts per position (reversals trade twice this)",
+			Min:         execution.Bound(1),
+		},
+		{
+			Name:        "exit_on_opposite",
+			Type:        "string",
+			Value:       strconv.FormatBool(d.exitOnOpposite),
+			Description: "true: exit flat when a close touches the opposite channel; false: stay in and reverse on its breakout",
+			Options:     []string{"true", "false"},
+		},
+	}
+}
+
+// WarmupBars is a full channel before the first live bar, plus the newest
+// bar, which is still forming and is only fed once it closes
+func (d *DonchianBreakout) WarmupBars() int {
+	d.paramMu.RLock()
+	defer d.paramMu.RUnlock()
+	return d.channelLength + 1
+}
+
+// IsReady reports whether the channel holds a full window, so the next close
+// can break out of it
+func (d *DonchianBreakout) IsReady() bool {
+	if !d.IsInitialized() {
+		return false
+	}
+	d.paramMu.RLock()
+	defer d.paramMu.RUnlock()
+	return d.channel.IsFull()
+}
+
+// SetLogger sets the logger used for signal, order and parameter messages
+func (d *DonchianBreakout) SetLogger(l *logger.Logger) {
+	d.logger = l
+}
+
+// SetParam sets a parameter value
+func (d *DonchianBreakout) SetParam(name, value string) error {
+	if err := d.BeginConfigure(); err != nil {
+		return err
+	}
+
+	d.paramMu.Lock()
+	defer d.paramMu.Unlock()
+	switch name {
+	case "symbol":
+		d.symbol = value
+	case "channel_length":
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid channel_length: %w", err)
+		}
+		if val <= 0 {
+			return fmt.Errorf("channel_length must be positive")
+		}
+		d.channelLength = val
+	case "quantity":
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid quantity: %w", err)
+		}
+		if val <= 0 {
+			return fmt.Errorf("quantity must be positive")
+		}
+		d.quantity = val
+	case "exit_on_opposite":
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid exit_on_opposite: %w", err)
+		}
+		d.exitOnOpposite = val
+	default:
+		return fmt.Errorf("unknown parameter: %s", name)
+	}
+	return nil
+}
+
+// SetEnabled enables or disables trading actions
+func (d *DonchianBreakout) SetEnabled(enabled bool) {
+	d.enabled.Store(enabled)
+}
+
+// Init initializes the strategy with the broker it trades through
+func (d *DonchianBreakout) Init(broker execution.Broker) error {
+	if err := d.BeginInit(); err != nil {
+		return err
+	}
+
+	if broker != nil {
+		resolved, err := broker.ResolveSymbol(d.symbol)
+		if err != nil {
+			return fmt.Errorf("failed to resolve symbol %s: %w", d.symbol, err)
+		}
+		if resolved != d.symbol {
+			if d.logger != nil {
+				d.logger.Infof("Trading %s as %s", d.symbol, resolved)
+			}
+			d.paramMu.Lock()
+			d.symbol = resolved
+			d.paramMu.Unlock()
+		}
+		if limit, enforced := broker.MaxContracts(); enforced && d.quantity > limit {
+			return fmt.Errorf("quantity (%d) exceeds max contracts (%d)", d.quantity, limit)
+		}
+		// A reversal closes and reopens in one order of twice the quantity
+		if limit, enforced := broker.MaxOrderQty(d.symbol); enforced && 2*d.quantity > limit {
+			return fmt.Errorf("quantity (%d) reverses in orders of %d, over max order quantity (%d)", d.quantity, 2*d.quantity, limit)
+		}
+		// The broker keeps its listeners, so only attach once per broker
+		if d.listenerAttached != broker {
+			broker.AddOrderListener(d.onOrderUpdate)
+			d.listenerAttached = broker
+		}
+	}
+
+	d.broker = broker
+	d.paramMu.Lock()
+	d.channel = indicators.NewMaxMin(d.channelLength)
+	d.paramMu.Unlock()
+	d.mu.Lock()
+	d.position = Flat
+	d.pendingOrderID = ""
+	d.mu.Unlock()
+	d.lastBarTimestamp = ""
+	return d.EndInit()
+}
+
+// OnBar checks a closed bar against the channel of the bars before it, then
+// adds it to the channel
+func (d *DonchianBreakout) OnBar(timestamp string, price float64) error {
+	if err := d.CheckInitialized(); err != nil {
+		return err
+	}
+
+	// Skip if we already processed this bar
+	if timestamp == d.lastBarTimestamp {
+		return nil
+	}
+	d.lastBarTimestamp = timestamp
+
+	full, upper, lower := d.channel.IsFull(), d.channel.High(), d.channel.Low()
+	d.channel.Update(price)
+	if !full {
+		return nil
+	}
+
+	d.mu.Lock()
+	newPosition, changed := d.checkSignal(price, upper, lower)
+	if changed {
+		d.lastSignal = newPosition.String()
+	}
+	d.mu.Unlock()
+	if !changed {
+		return nil
+	}
+
+	if d.logger != nil && d.enabled.Load() {
+		d.logger.Infof("! Signal detected at bar %s | Close: %.2f | Upper: %.2f | Lower: %.2f | New Position: %v !",
+			timestamp, price, upper, lower, newPosition)
+	}
+	return d.executePositionChange(newPosition)
+}
+
+// checkSignal returns the position a close calls for against the channel of
+// the bars before it. Caller must hold d.mu.
+func (d *DonchianBreakout) checkSignal(price, upper, lower float64) (Position, bool) {
+	switch {
+	case d.exitOnOpposite && d.position == Long && price <= lower:
+		return Flat, true
+	case d.exitOnOpposite && d.position == Short && price >= upper:
+		return Flat, true
+	case d.exitOnOpposite && d.position != Flat:
+	case price > upper && d.position != Long:
+		return Long, true
+	case price < lower && d.position != Short:
+		return Short, true
+	}
+	return d.position, false
+}
+
+// executePositionChange handles position transitions. The position only changes once
+// the order is reported filled, so a rejected order leaves the strategy where it was.
+func (d *DonchianBreakout) executePositionChange(newPosition Position) error {
+	signalAt := time.Now()
+
+	if !d.enabled.Load() {
+		if d.logger != nil {
+			d.logger.Debug("[Disabled] ")
+		}
+		return nil
+	}
+
+	d.mu.Lock()
+	if d.pendingOrderID != "" {
+		pending := d.pendingOrderID
+		d.mu.Unlock()
+		if d.logger != nil {
+			d.logger.Warnf("Signal ignored: order %s still pending", pending)
+		}
+		return nil
+	}
+
+	var side models.OrderSide
+	var logMsg string
+	quantity := d.quantity
+	switch {
+	case d.position == newPosition:
+		d.mu.Unlock()
+		return nil
+	case newPosition == Long:
+		side, logMsg = models.SideBuy, "GOING LONG"
+	case newPosition == Short:
+		side, logMsg = models.SideSell, "GOING SHORT"
+	case d.position == Long:
+		side, logMsg = models.SideSell, "EXITING Long"
+	default:
+		side, logMsg = models.SideBuy, "EXITING Short"
+	}
+	if d.position != Flat && newPosition != Flat {
+		quantity = 2 * d.quantity
+		logMsg = fmt.Sprintf("REVERSING: %v → %v", d.position, newPosition)
+	}
+	d.mu.Unlock()
+
+	if d.logger != nil {
+		d.logger.Info(logMsg)
+	}
+
+	order, err := d.broker.SubmitOrder(d.symbol, side, quantity, execution.OrderOptions{SignalAt: signalAt})
+	if err != nil {
+		if d.logger != nil {
+			d.logger.Errorf("Order %s %d %s failed: %v", side, quantity, d.symbol, err)
+		}
+		return err
+	}
+	// A snapshot, as the order manager may be updating the order
+	if d.logger != nil {
+		if current, _ := d.broker.OrderSnapshot(order.ID); current.Status == models.StatusQueued {
+			d.logger.Warnf("Queued %s %d %s (order %s) until Tradovate can be reached", side, quantity, d.symbol, order.ID)
+		} else {
+			d.logger.Infof("Submitted %s %d %s (order %s)", side, quantity, d.symbol, order.ID)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if current, ok := d.broker.OrderSnapshot(order.ID); ok && current.Status == models.StatusFilled {
+		// Already filled (simulated or the fill raced the submit response)
+		d.position = newPosition
+		return nil
+	}
+	d.pendingOrderID = order.ID
+	d.pendingPosition = newPosition
+	return nil
+}
+
+// onOrderUpdate reconciles the position with the outcome of the pending order
+func (d *DonchianBreakout) onOrderUpdate(order models.Order) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if order.ID != d.pendingOrderID {
+		return
+	}
+
+	switch order.Status {
+	case models.StatusFilled:
+		d.position = d.pendingPosition
+		if d.logger != nil {
+			d.logger.Infof("Order %s filled, position now %v", order.ID, d.position)
+		}
+	case models.StatusRejected, models.StatusCanceled, models.StatusExpired, models.StatusFailed:
+		if d.logger != nil {
+			d.logger.Warnf("Order %s %s, position stays %v", order.ID, order.Status, d.position)
+		}
+	default:
+		return
+	}
+	d.pendingOrderID = ""
+}
+
+// GetPosition returns the current position
+func (d *DonchianBreakout) GetPosition() Position {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.position
+}
+
+// LastSignal returns the position the last signal called for: "Long", "Short"
+// or "Flat", or "" before the first
+func (d *DonchianBreakout) LastSignal() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.lastSignal
+}
+
+// StrategyPosition returns the position in contracts and whether an order that
+// would change it is still pending
+func (d *DonchianBreakout) StrategyPosition() (int, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.position.contracts(d.quantity), d.pendingOrderID != ""
+}
+
+// SyncPosition takes the side of the broker's net position
+func (d *DonchianBreakout) SyncPosition(netPos int) {
+	d.mu.Lock()
+	previous := d.position
+	d.position = positionOf(netPos)
+	d.pendingOrderID = ""
+	d.mu.Unlock()
+
+	if d.logger != nil {
+		d.logger.Warnf("Position synced to the broker: %v -> %v (%+d contracts)", previous, positionOf(netPos), netPos)
+	}
+}
+
+// GetMetrics returns real-time metrics for the strategy: the channel the next
+// close is checked against
+func (d *DonchianBreakout) GetMetrics() map[string]float64 {
+	metrics := make(map[string]float64)
+	d.paramMu.RLock()
+	defer d.paramMu.RUnlock()
+	if d.channel != nil {
+		metrics["Upper Channel"] = d.channel.High()
+		metrics["Lower Channel"] = d.channel.Low()
+	}
+	return metrics
+}
+
+// Reset clears the channel, position and pending order and returns the
+// strategy to configured
+func (d *DonchianBreakout) Reset() {
+	d.ResetLifecycle()
+	d.paramMu.RLock()
+	if d.channel != nil {
+		d.channel.Reset()
+	}
+	d.paramMu.RUnlock()
+	d.mu.Lock()
+	d.position = Flat
+	d.pendingOrderID = ""
+	d.lastSignal = ""
+	d.mu.Unlock()
+	d.lastBarTimestamp = ""
+}
+
+// Register the strategy with the registry
+func init() {
+	execution.Register("donchian_breakout", func(l *logger.Logger) execution.Strategy {
+		return NewDefaultDonchianBreakout(l)
+	})
+}
@@ -3,9 +3,13 @@ package strategies
 import (
 	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 	"tradovate-execution-engine/engine/indicators"
 	"tradovate-execution-engine/engine/internal/execution"
 	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
 	"tradovate-execution-engine/engine/internal/models"
 )
 
@@ -18,22 +22,87 @@ const (
 	Short
 )
 
+func (p Position) String() string {
+	switch p {
+	case Long:
+		return "Long"
+	case Short:
+		return "Short"
+	default:
+		return "Flat"
+	}
+}
+
+// positionOf is the side of a net position in contracts
+func positionOf(netPos int) Position {
+	switch {
+	case netPos > 0:
+		return Long
+	case netPos < 0:
+		return Short
+	default:
+		return Flat
+	}
+}
+
+// contracts is the net position of p when each side holds quantity contracts
+func (p Position) contracts(quantity int) int {
+	switch p {
+	case Long:
+		return quantity
+	case Short:
+		return -quantity
+	default:
+		return 0
+	}
+}
+
+// maxSMALength caps fast_length and slow_length; each SMA keeps a window this long
+const maxSMALength = 1000
+
 // MACrossover implements a moving average crossover strategy
 type MACrossover struct {
-	symbol      string
-	fastSMA     *indicators.SMA
-	slowSMA     *indicators.SMA
-	position    Position
-	fastLength  int
-	slowLength  int
-	mode        indicators.UpdateMode
-	orderMgr    *execution.OrderManager
-	logger      *logger.Logger
-	initialized bool
+	execution.StrategyLifecycle
+
+	symbol     string
+	fastSMA    *indicators.SMA
+	slowSMA    *indicators.SMA
+	position   Position
+	fastLength int
+	slowLength int
+	mode       indicators.UpdateMode
+	broker     execution.Broker
+	logger     *logger.Logger
+
+	// Params and averages are set on the goroutine that starts the strategy and
+	// the averages update on the data goroutine, while the UI reads both
+	paramMu sync.RWMutex
 
 	// Track last bar timestamp to avoid processing same bar multiple times
 	lastBarTimestamp string
-	enabled          bool
+	enabled          atomic.Bool
+
+	// Position sizing and fill reconciliation
+	quantity         int
+	mu               sync.Mutex // Guards position and the pending order
+	pendingOrderID   string     // Order whose fill will move position to pendingPosition
+	pendingPosition  Position
+	listenerAttached execution.Broker
+	signalledThisBar bool   // OnQuote already signalled on the forming bar
+	lastSignal       string // Position the last signal called for, "" before the first
+
+	// Tick based exits (0 = disabled), measured from the entry fill price
+	stopLossTicks   int
+	takeProfitTicks int
+	tickSize        float64
+	entryPrice      float64 // Average fill price of the open position (0 when flat)
+
+	// Bars the engine builds for this strategy, e.g. 1 minute or 1000 volume
+	barType marketdata.BarType
+	barSize int
+
+	// How the engine places entries: market or a limit off the quote
+	entry execution.EntryPolicy
 }
 
 // NewDefaultMACrossover creates a new MA crossover strategy used for testing
@@ -44,7 +113,10 @@ func NewMACrossover(symbol string, fast, slow int, mode indicators.UpdateMode) *
 		slowLength: slow,
 		mode:       mode,
 		position:   Flat,
-		enabled:    false,
+		quantity:   1,
+		barType:    marketdata.BarTypeMinute,
+		barSize:    1,
+		entry:      execution.DefaultEntryPolicy(),
 	}
 }
 
@@ -56,8 +128,11 @@ func NewDefaultMACrossover(l *logger.Logger) *MACrossover {
 		slowLength: 15,
 		mode:       indicators.OnBarClose,
 		position:   Flat,
-		enabled:    false,
 		logger:     l,
+		quantity:   1,
+		barType:    marketdata.BarTypeMinute,
+		barSize:    1,
+		entry:      execution.DefaultEntryPolicy(),
 	}
 }
 
@@ -73,40 +148,147 @@ func (m *MACrossover) Description() string {
 
 // GetParams returns the configurable parameters
 func (m *MACrossover) GetParams() []execution.StrategyParam {
-	return []execution.StrategyParam{
+	m.paramMu.RLock()
+	defer m.paramMu.RUnlock()
+	return append([]execution.StrategyParam{
 		{
 			Name:        "symbol",
 			Type:        "string",
 			Value:       m.symbol,
-			Description: "Trading symbol",
+			Description: "Trading symbol or product root (e.g. MES resolves to the front month)",
 		},
 		{
 			Name:        "fast_length",
 			Type:        "int",
 			Value:       strconv.Itoa(m.fastLength),
 			Description: "Fast SMA period length",
+			Min:         execution.Bound(1),
+			Max:         execution.Bound(maxSMALength),
 		},
 		{
 			Name:        "slow_length",
 			Type:        "int",
 			Value:       strconv.Itoa(m.slowLength),
 			Description: "Slow SMA period length",
+			Min:         execution.Bound(2),
+			Max:         execution.Bound(maxSMALength),
+		},
+		{
+			Name:        "quantity",
+			Type:        "int",
+			Value:       strconv.Itoa(m.quantity),
+			Description: "Contracts per position (reversals trade twice this)",
+			Min:         execution.Bound(1),
+		},
+		{
+			Name:        "stop_loss_ticks",
+			Type:        "int",
+			Value:       strconv.Itoa(m.stopLossTicks),
+			Description: "Exit when price moves this many ticks against the entry (0 = off)",
+			Min:         execution.Bound(0),
+		},
+		{
+			Name:        "take_profit_ticks",
+			Type:        "int",
+			Value:       strconv.Itoa(m.takeProfitTicks),
+			Description: "Exit when price moves this many ticks in favor of the entry (0 = off)",
+			Min:         execution.Bound(0),
+		},
+		{
+			Name:        "bar_type",
+			Type:        "string",
+			Value:       string(m.barType),
+			Description: "Bars to trade on: minute, volume or tick",
+			Options:     []string{string(marketdata.BarTypeMinute), string(marketdata.BarTypeVolume), string(marketdata.BarTypeTick)},
+		},
+		{
+			Name:        "bar_size",
+			Type:        "int",
+			Value:       strconv.Itoa(m.barSize),
+			Description: "Minutes, contracts or trades per bar",
+			Min:         execution.Bound(1),
 		},
 		{
 			Name:        "update_mode",
 			Type:        "string",
 			Value:       strconv.Itoa(int(m.mode)),
-			Description: "Update mode: 0=OnEachTick, 1=OnBarClose",
+			Description: "Update mode: 0=OnEachTick (signal intrabar on trades), 1=OnBarClose",
+			Options:     []string{"0", "1"},
 		},
+	}, m.entry.Params()...)
+}
+
+// ValidateParams checks the constraints between params before Init runs
+func (m *MACrossover) ValidateParams(params map[string]string) error {
+	fast, _ := strconv.Atoi(params["fast_length"])
+	slow, _ := strconv.Atoi(params["slow_length"])
+	if fast >= slow {
+		return fmt.Errorf("fast_length (%d) must be less than slow_length (%d)", fast, slow)
+	}
+	if value, ok := params["entry_type"]; ok {
+		if _, _, err := execution.ParseEntryType(value); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// WarmupBars is the history needed for both averages and the bar before, so
+// the first live bar can already signal a cross, plus the newest bar, which is
+// still forming and is only fed once it closes
+func (m *MACrossover) WarmupBars() int {
+	return m.slowLength + 2
+}
+
+// IsReady reports whether the slow average has a value for this bar and the
+// one before, which is what a cross needs
+func (m *MACrossover) IsReady() bool {
+	if !m.IsInitialized() {
+		return false
+	}
+	m.paramMu.RLock()
+	defer m.paramMu.RUnlock()
+	return m.slowSMA.Value.Get(1) != 0
+}
+
+// SetLogger sets the logger used for signal, order and parameter messages
+func (m *MACrossover) SetLogger(l *logger.Logger) {
+	m.logger = l
 }
 
 // SetParam sets a parameter value
 func (m *MACrossover) SetParam(name, value string) error {
-	if m.initialized {
-		return fmt.Errorf("cannot modify parameters after initialization")
+	if err := m.BeginConfigure(); err != nil {
+		return err
 	}
 
+	old := m.paramValue(name)
+	m.paramMu.Lock()
+	err := m.setParam(name, value)
+	m.paramMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if m.logger != nil {
+		if current := m.paramValue(name); current != old {
+			m.logger.Infof("Parameter %s: %s -> %s", name, old, current)
+		}
+	}
+	return nil
+}
+
+// paramValue returns the current value of a parameter as shown by GetParams
+func (m *MACrossover) paramValue(name string) string {
+	for _, p := range m.GetParams() {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// setParam sets a parameter value. Caller must hold paramMu.
+func (m *MACrossover) setParam(name, value string) error {
 	switch name {
 	case "symbol":
 		m.symbol = value
@@ -128,6 +310,48 @@ func (m *MACrossover) SetParam(name, value string) error {
 			return fmt.Errorf("slow_length must be positive")
 		}
 		m.slowLength = val
+	case "quantity":
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid quantity: %w", err)
+		}
+		if val <= 0 {
+			return fmt.Errorf("quantity must be positive")
+		}
+		m.quantity = val
+	case "stop_loss_ticks":
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid stop_loss_ticks: %w", err)
+		}
+		if val < 0 {
+			return fmt.Errorf("stop_loss_ticks cannot be negative")
+		}
+		m.stopLossTicks = val
+	case "take_profit_ticks":
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid take_profit_ticks: %w", err)
+		}
+		if val < 0 {
+			return fmt.Errorf("take_profit_ticks cannot be negative")
+		}
+		m.takeProfitTicks = val
+	case "bar_type":
+		spec, err := marketdata.ParseBarSpec(value, m.barSize)
+		if err != nil {
+			return err
+		}
+		m.barType = spec.Type
+	case "bar_size":
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid bar_size: %w", err)
+		}
+		if val <= 0 {
+			return fmt.Errorf("bar_size must be positive")
+		}
+		m.barSize = val
 	case "update_mode":
 		val, err := strconv.Atoi(value)
 		if err != nil {
@@ -135,39 +359,90 @@ func (m *MACrossover) SetParam(name, value string) error {
 		}
 		m.mode = indicators.UpdateMode(val)
 	default:
+		if ok, err := m.entry.SetParam(name, value); ok {
+			return err
+		}
 		return fmt.Errorf("unknown parameter: %s", name)
 	}
 	return nil
 }
 
+// EntryPolicy is how the engine places this strategy's entries
+func (m *MACrossover) EntryPolicy() execution.EntryPolicy {
+	m.paramMu.RLock()
+	defer m.paramMu.RUnlock()
+	return m.entry
+}
+
 // SetEnabled enables or disables trading actions
 func (m *MACrossover) SetEnabled(enabled bool) {
-	m.enabled = enabled
+	m.enabled.Store(enabled)
 }
 
-// Init initializes the strategy with the order manager
-func (m *MACrossover) Init(om *execution.OrderManager) error {
-	if m.initialized {
-		return fmt.Errorf("strategy already initialized")
+// Init initializes the strategy with the broker it trades through
+func (m *MACrossover) Init(broker execution.Broker) error {
+	if err := m.BeginInit(); err != nil {
+		return err
 	}
 
 	if m.fastLength >= m.slowLength {
 		return fmt.Errorf("fast_length (%d) must be less than slow_length (%d)", m.fastLength, m.slowLength)
 	}
 
-	m.orderMgr = om
+	if broker != nil {
+		resolved, err := broker.ResolveSymbol(m.symbol)
+		if err != nil {
+			return fmt.Errorf("failed to resolve symbol %s: %w", m.symbol, err)
+		}
+		if resolved != m.symbol {
+			if m.logger != nil {
+				m.logger.Infof("Trading %s as %s", m.symbol, resolved)
+			}
+			m.paramMu.Lock()
+			m.symbol = resolved
+			m.paramMu.Unlock()
+		}
+		if limit, enforced := broker.MaxContracts(); enforced && m.quantity > limit {
+			return fmt.Errorf("quantity (%d) exceeds max contracts (%d)", m.quantity, limit)
+		}
+		// A reversal closes and reopens in one order of twice the quantity
+		if limit, enforced := broker.MaxOrderQty(m.symbol); enforced && 2*m.quantity > limit {
+			return fmt.Errorf("quantity (%d) reverses in orders of %d, over max order quantity (%d)", m.quantity, 2*m.quantity, limit)
+		}
+		if m.stopLossTicks > 0 || m.takeProfitTicks > 0 {
+			m.tickSize = broker.GetTickSize(m.symbol)
+			if m.tickSize <= 0 {
+				return fmt.Errorf("tick size unknown for %s (needed for stop_loss_ticks/take_profit_ticks)", m.symbol)
+			}
+		}
+		// The broker keeps its listeners, so only attach once per broker
+		if m.listenerAttached != broker {
+			broker.AddOrderListener(m.onOrderUpdate)
+			m.listenerAttached = broker
+		}
+	}
+
+	m.broker = broker
+	m.paramMu.Lock()
 	m.fastSMA = indicators.NewSMA(m.fastLength, m.mode)
 	m.slowSMA = indicators.NewSMA(m.slowLength, m.mode)
+	m.paramMu.Unlock()
+	m.mu.Lock()
 	m.position = Flat
-	m.initialized = true
+	m.entryPrice = 0
+	m.pendingOrderID = ""
+	m.signalledThisBar = false
+	m.lastSignal = ""
+	m.mu.Unlock()
+	m.lastBarTimestamp = ""
 
-	return nil
+	return m.EndInit()
 }
 
 // OnBar processes a completed bar (for OnBarClose mode)
 func (m *MACrossover) OnBar(timestamp string, price float64) error {
-	if !m.initialized {
-		return fmt.Errorf("strategy not initialized")
+	if err := m.CheckInitialized(); err != nil {
+		return err
 	}
 
 	// Skip if we already processed this bar
@@ -180,14 +455,25 @@ func (m *MACrossover) OnBar(timestamp string, price float64) error {
 	m.fastSMA.Update(price)
 	m.slowSMA.Update(price)
 
+	// A stop or target hit on this close takes priority over a new signal
+	if exited, err := m.checkExit(price); exited {
+		return err
+	}
+
 	// Check for crossover signal
+	m.mu.Lock()
+	m.signalledThisBar = false
 	newPosition, changed := m.checkSignal(1)
+	if changed {
+		m.lastSignal = newPosition.String()
+	}
+	m.mu.Unlock()
 	if !changed {
 		return nil
 	}
 
 	if m.logger != nil {
-		if m.enabled {
+		if m.enabled.Load() {
 			m.logger.Infof("! Signal detected at bar %s | Fast: %.2f | Slow: %.2f | New Position: %v !",
 				timestamp, m.fastSMA.CurrentValue(), m.slowSMA.CurrentValue(), newPosition)
 		}
@@ -197,10 +483,12 @@ func (m *MACrossover) OnBar(timestamp string, price float64) error {
 	return m.executePositionChange(newPosition)
 }
 
-// executePositionChange handles position transitions
+// executePositionChange handles position transitions. The position only changes once
+// the order is reported filled, so a rejected order leaves the strategy where it was.
 func (m *MACrossover) executePositionChange(newPosition Position) error {
+	signalAt := time.Now()
 
-	if !m.enabled {
+	if !m.enabled.Load() {
 		if m.logger != nil {
 			m.logger.Debug("[Disabled] ")
 		}
@@ -210,41 +498,219 @@ func (m *MACrossover) executePositionChange(newPosition Position) error {
 	var quantity int
 	var logMsg string
 
+	m.mu.Lock()
+	if m.pendingOrderID != "" {
+		pending := m.pendingOrderID
+		m.mu.Unlock()
+		if m.logger != nil {
+			m.logger.Warnf("Signal ignored: order %s still pending", pending)
+		}
+		return nil
+	}
+
 	switch {
 	case m.position == Flat && newPosition == Long:
 		logMsg = "GOING LONG"
 		side = models.SideBuy
-		quantity = 1
+		quantity = m.quantity
 
 	case m.position == Flat && newPosition == Short:
 		logMsg = "GOING SHORT"
 		side = models.SideSell
-		quantity = 1
+		quantity = m.quantity
 
 	case m.position == Long && newPosition == Short:
-		logMsg = "REVERSING: Long → Flat"
+		logMsg = "REVERSING: Long → Short"
 		side = models.SideSell
-		quantity = 1
+		quantity = 2 * m.quantity
 
 	case m.position == Short && newPosition == Long:
-		logMsg = "REVERSING: Short → Flat"
+		logMsg = "REVERSING: Short → Long"
 		side = models.SideBuy
-		quantity = 1
+		quantity = 2 * m.quantity
+
+	case m.position == Long && newPosition == Flat:
+		logMsg = "EXITING Long"
+		side = models.SideSell
+		quantity = m.quantity
+
+	case m.position == Short && newPosition == Flat:
+		logMsg = "EXITING Short"
+		side = models.SideBuy
+		quantity = m.quantity
 
 	default:
+		m.mu.Unlock()
 		return nil
 	}
-
-	m.position = newPosition
+	m.mu.Unlock()
 
 	if m.logger != nil {
 		m.logger.Info(logMsg)
 	}
 
-	_, err := m.orderMgr.SubmitMarketOrder(m.symbol, side, quantity)
+	order, err := m.broker.SubmitOrder(m.symbol, side, quantity, execution.OrderOptions{SignalAt: signalAt})
+	if err != nil {
+		if m.logger != nil {
+			m.logger.Errorf("Order %s %d %s failed: %v", side, quantity, m.symbol, err)
+		}
+		return err
+	}
+	// A snapshot, as the order manager may be updating the order
+	if m.logger != nil {
+		if current, _ := m.broker.OrderSnapshot(order.ID); current.Status == models.StatusQueued {
+			m.logger.Warnf("Queued %s %d %s (order %s) until Tradovate can be reached", side, quantity, m.symbol, order.ID)
+		} else {
+			m.logger.Infof("Submitted %s %d %s (order %s)", side, quantity, m.symbol, order.ID)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, ok := m.broker.OrderSnapshot(order.ID); ok && current.Status == models.StatusFilled {
+		// Already filled (simulated or the fill raced the submit response)
+		m.setPosition(newPosition, current)
+		return nil
+	}
+	m.pendingOrderID = order.ID
+	m.pendingPosition = newPosition
+	return nil
+}
+
+// onOrderUpdate reconciles the position with the outcome of the pending order
+func (m *MACrossover) onOrderUpdate(order models.Order) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if order.ID != m.pendingOrderID {
+		return
+	}
+
+	switch order.Status {
+	case models.StatusFilled:
+		m.setPosition(m.pendingPosition, order)
+		if m.logger != nil {
+			m.logger.Infof("Order %s filled, position now %v", order.ID, m.position)
+		}
+	case models.StatusRejected, models.StatusCanceled, models.StatusExpired, models.StatusFailed:
+		if m.logger != nil {
+			m.logger.Warnf("Order %s %s, position stays %v", order.ID, order.Status, m.position)
+		}
+	default:
+		return
+	}
+	m.pendingOrderID = ""
+}
+
+// setPosition moves to the filled position and records its entry price from the
+// order's fills. Caller must hold m.mu.
+func (m *MACrossover) setPosition(position Position, filled models.Order) {
+	m.position = position
+	if position == Flat {
+		m.entryPrice = 0
+		return
+	}
+	m.entryPrice = filled.AvgFillPrice()
+	if m.entryPrice == 0 {
+		m.entryPrice = filled.Price
+	}
+}
+
+// OnPrice checks the stop loss and take profit against a live trade price
+func (m *MACrossover) OnPrice(price float64) error {
+	if err := m.CheckInitialized(); err != nil {
+		return err
+	}
+	_, err := m.checkExit(price)
 	return err
 }
 
+// UpdateMode asks the engine for quotes as well as bars when update_mode is
+// OnEachTick
+func (m *MACrossover) UpdateMode() execution.UpdateMode {
+	if m.mode == indicators.OnEachTick {
+		return execution.UpdateOnEachTick
+	}
+	return execution.UpdateOnBarClose
+}
+
+// OnQuote looks for a cross on the forming bar in OnEachTick mode. Each trade is
+// taken as the bar's close so far, and the first cross it makes signals at once
+// instead of at the close; OnBar still closes the bar.
+func (m *MACrossover) OnQuote(quote marketdata.Quote) error {
+	if err := m.CheckInitialized(); err != nil {
+		return err
+	}
+	trade, ok := quote.LastTrade()
+	if m.mode != indicators.OnEachTick || !ok {
+		return nil
+	}
+
+	fastPrev, slowPrev := m.fastSMA.CurrentValue(), m.slowSMA.CurrentValue()
+	fastNow, slowNow := m.fastSMA.Preview(trade.Price), m.slowSMA.Preview(trade.Price)
+	if fastPrev == 0 || slowPrev == 0 || fastNow == 0 || slowNow == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	newPosition, changed := m.position, false
+	switch {
+	case m.signalledThisBar:
+	case fastPrev <= slowPrev && fastNow > slowNow && m.position != Long:
+		newPosition, changed = Long, true
+	case fastPrev >= slowPrev && fastNow < slowNow && m.position != Short:
+		newPosition, changed = Short, true
+	}
+	m.signalledThisBar = m.signalledThisBar || changed
+	if changed {
+		m.lastSignal = newPosition.String()
+	}
+	m.mu.Unlock()
+	if !changed {
+		return nil
+	}
+
+	if m.logger != nil && m.enabled.Load() {
+		m.logger.Infof("! Intrabar signal at %.2f | Fast: %.2f | Slow: %.2f | New Position: %v !",
+			trade.Price, fastNow, slowNow, newPosition)
+	}
+	return m.executePositionChange(newPosition)
+}
+
+// checkExit closes the position when price has moved stop_loss_ticks against or
+// take_profit_ticks in favor of the entry. It reports whether an exit was sent.
+func (m *MACrossover) checkExit(price float64) (bool, error) {
+	m.mu.Lock()
+	if m.position == Flat || m.entryPrice == 0 || m.tickSize <= 0 || m.pendingOrderID != "" {
+		m.mu.Unlock()
+		return false, nil
+	}
+
+	moved := (price - m.entryPrice) / m.tickSize
+	if m.position == Short {
+		moved = -moved
+	}
+	// Allow for float error, e.g. (5000.75-5000)/0.25 = 2.9999...
+	const epsilon = 1e-9
+	var reason string
+	switch {
+	case m.stopLossTicks > 0 && moved <= -float64(m.stopLossTicks)+epsilon:
+		reason = "Stop loss"
+	case m.takeProfitTicks > 0 && moved >= float64(m.takeProfitTicks)-epsilon:
+		reason = "Take profit"
+	}
+	entry, position := m.entryPrice, m.position
+	m.mu.Unlock()
+
+	if reason == "" || !m.enabled.Load() {
+		return false, nil
+	}
+	if m.logger != nil {
+		m.logger.Infof("%s hit: %v from %.2f, price %.2f (%.0f ticks)", reason, position, entry, price, moved)
+	}
+	return true, m.executePositionChange(Flat)
+}
+
 // checkSignal checks for crossover signals
 func (m *MACrossover) checkSignal(lookback int) (Position, bool) {
 	if m.CrossAbove(lookback) {
@@ -306,12 +772,47 @@ func (m *MACrossover) CrossBelow(barsAgo int) bool {
 
 // GetPosition returns the current position
 func (m *MACrossover) GetPosition() Position {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.position
 }
 
+// LastSignal returns the position the last signal called for: "Long", "Short"
+// or "Flat", or "" before the first
+func (m *MACrossover) LastSignal() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastSignal
+}
+
+// StrategyPosition returns the position in contracts and whether an order that
+// would change it is still pending
+func (m *MACrossover) StrategyPosition() (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.position.contracts(m.quantity), m.pendingOrderID != ""
+}
+
+// SyncPosition takes the side of the broker's net position. Its entry price is
+// not known, so the tick exits wait for the next entry.
+func (m *MACrossover) SyncPosition(netPos int) {
+	m.mu.Lock()
+	previous := m.position
+	m.position = positionOf(netPos)
+	m.entryPrice = 0
+	m.pendingOrderID = ""
+	m.mu.Unlock()
+
+	if m.logger != nil {
+		m.logger.Warnf("Position synced to the broker: %v -> %v (%+d contracts)", previous, positionOf(netPos), netPos)
+	}
+}
+
 // GetMetrics returns real-time metrics for the strategy
 func (m *MACrossover) GetMetrics() map[string]float64 {
 	metrics := make(map[string]float64)
+	m.paramMu.RLock()
+	defer m.paramMu.RUnlock()
 	if m.fastSMA != nil {
 		metrics["Fast SMA"] = m.fastSMA.Value.Get(0)
 	}
@@ -321,17 +822,26 @@ func (m *MACrossover) GetMetrics() map[string]float64 {
 	return metrics
 }
 
-// Reset resets the strategy state
+// Reset clears the averages, position and pending order and returns the
+// strategy to configured, so its params can be changed and Init called again
 func (m *MACrossover) Reset() {
+	m.ResetLifecycle()
+	m.paramMu.RLock()
 	if m.fastSMA != nil {
 		m.fastSMA.Reset()
 	}
 	if m.slowSMA != nil {
 		m.slowSMA.Reset()
 	}
+	m.paramMu.RUnlock()
+	m.mu.Lock()
 	m.position = Flat
+	m.entryPrice = 0
+	m.pendingOrderID = ""
+	m.signalledThisBar = false
+	m.lastSignal = ""
+	m.mu.Unlock()
 	m.lastBarTimestamp = ""
-	m.initialized = false
 }
 
 // Register the strategy with the registry
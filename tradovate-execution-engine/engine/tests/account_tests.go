@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunAccountTests executes all tests for account selection.
+func RunAccountTests() {
+	accounts := []tradovate.APIAccount{
+		{ID: 101, Name: "DEMO1001"},
+		{ID: 202, Name: "DEMO2002"},
+	}
+
+	a, err := auth.SelectAccount(accounts, 0, "")
+	check("No preference uses the first account", err == nil && a.ID == 101)
+
+	a, err = auth.SelectAccount(accounts, 202, "")
+	check("Account is selected by ID", err == nil && a.Name == "DEMO2002")
+
+	a, err = auth.SelectAccount(accounts, 0, "demo2002")
+	check("Account is selected by name, ignoring case", err == nil && a.ID == 202)
+
+	_, err = auth.SelectAccount(accounts, 0, "LIVE9")
+	check("Unknown account is an error", err != nil)
+
+	_, err = auth.SelectAccount(nil, 0, "")
+	check("Empty account list is an error", err != nil)
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunAdminTests executes the tests for the read-only admin API.
+func RunAdminTests() {
+	testAdminEndpoints()
+	testAdminLoopbackOnly()
+}
+
+// getAdmin fetches path from the admin API at addr and decodes the JSON into v,
+// returning the status code
+func getAdmin(addr, path string, v interface{}) int {
+	client := http.Client{Timeout: time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://%s%s", addr, path))
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	if v != nil && json.NewDecoder(resp.Body).Decode(v) != nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+func testAdminEndpoints() {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	cfg := &config.Config{
+		Risk:  config.RiskConfig{DailyLossLimit: 500},
+		Admin: config.AdminConfig{Enabled: true, Addr: "127.0.0.1:0"},
+	}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	addr := e.AdminAddr()
+	check("Admin API starts with the engine", addr != "")
+	if addr == "" {
+		e.Shutdown(false)
+		return
+	}
+
+	e.MarketData().SubscribeQuote("MESH6")
+	var order *models.Order
+	var err error
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if order, err = e.OrderManager().SubmitMarketOrder("MESH6", models.SideBuy, 1); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		check(fmt.Sprintf("Demo order fills (Error: %v)", err), false)
+		e.Shutdown(false)
+		return
+	}
+	waitFor(func() bool { return e.Portfolio().GetPLSummary()["MESH6"].NetPos == 1 })
+
+	// Orders refused before the first quote are listed too; the filled one is last
+	var orders []map[string]interface{}
+	check("/orders answers", getAdmin(addr, "/orders", &orders) == http.StatusOK)
+	n := len(orders)
+	check("/orders lists the order by its JSON field names, oldest first", n > 0 &&
+		orders[n-1]["id"] == order.ID && orders[n-1]["symbol"] == "MESH6" && orders[n-1]["side"] == "Buy" &&
+		orders[n-1]["status"] == string(models.StatusFilled) && orders[n-1]["filledQty"] == float64(1) &&
+		orders[n-1]["origin"] == "manual")
+
+	var positions []map[string]interface{}
+	check("/positions answers", getAdmin(addr, "/positions", &positions) == http.StatusOK)
+	check("/positions lists the open position", len(positions) == 1 &&
+		positions[0]["symbol"] == "MESH6" && positions[0]["netPos"] == float64(1) &&
+		positions[0]["avgPrice"] != nil && positions[0]["unrealizedPnL"] != nil)
+
+	var pnl map[string]interface{}
+	check("/pnl answers", getAdmin(addr, "/pnl", &pnl) == http.StatusOK)
+	check("/pnl reports realized, unrealized and the loss limit", pnl["connected"] == true &&
+		pnl["realized"] != nil && pnl["unrealized"] != nil && pnl["dailyLossLimit"] == float64(500))
+
+	if _, err := e.AddStrategy("ma_crossover", nil); err != nil {
+		check(fmt.Sprintf("Strategy is added (Error: %v)", err), false)
+	}
+	var strategies []map[string]interface{}
+	check("/strategy answers", getAdmin(addr, "/strategy", &strategies) == http.StatusOK)
+	check("/strategy lists the instance with its status and params", len(strategies) == 1 &&
+		strategies[0]["name"] == "ma_crossover" && strategies[0]["symbol"] == "MESH6" &&
+		strategies[0]["status"] == "disabled" && strategies[0]["params"] != nil)
+
+	var health map[string]interface{}
+	check("/health answers", getAdmin(addr, "/health", &health) == http.StatusOK)
+	check("/health reports the demo connection", health["connected"] == true && health["demo"] == true)
+
+	client := http.Client{Timeout: time.Second}
+	resp, err := client.Post(fmt.Sprintf("http://%s/orders", addr), "application/json", strings.NewReader(`{}`))
+	if err == nil {
+		resp.Body.Close()
+	}
+	check("The API refuses anything but GET", err == nil && resp.StatusCode == http.StatusMethodNotAllowed)
+	check("Unknown paths are not found", getAdmin(addr, "/flatten", nil) == http.StatusNotFound)
+
+	e.OrderManager().FlattenPositions()
+	e.Shutdown(false)
+	check("Admin API stops with the engine", e.AdminAddr() == "")
+	_, err = client.Get(fmt.Sprintf("http://%s/health", addr))
+	check("Closed admin API refuses requests", err != nil)
+}
+
+func testAdminLoopbackOnly() {
+	cases := []struct {
+		name    string
+		admin   config.AdminConfig
+		serving bool
+	}{
+		{"Disabled by default", config.AdminConfig{Addr: "127.0.0.1:0"}, false},
+		{"Loopback address", config.AdminConfig{Enabled: true, Addr: "127.0.0.1:0"}, true},
+		{"localhost", config.AdminConfig{Enabled: true, Addr: "localhost:0"}, true},
+		{"All interfaces refused", config.AdminConfig{Enabled: true, Addr: ":0"}, false},
+		{"Remote address refused", config.AdminConfig{Enabled: true, Addr: "0.0.0.0:0"}, false},
+		{"Remote address with allowRemote", config.AdminConfig{Enabled: true, Addr: "0.0.0.0:0", AllowRemote: true}, true},
+	}
+
+	for _, tc := range cases {
+		quiet := logger.NewLogger(100, logger.LevelError)
+		e := app.NewEngine(quiet, quiet, quiet)
+		cfg := &config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}, Admin: tc.admin}
+		if err := e.ConnectDemo(cfg); err != nil {
+			check(fmt.Sprintf("%s: demo connects (Error: %v)", tc.name, err), false)
+			continue
+		}
+		check(fmt.Sprintf("%s: serving %v", tc.name, tc.serving), (e.AdminAddr() != "") == tc.serving)
+		e.Shutdown(false)
+	}
+}
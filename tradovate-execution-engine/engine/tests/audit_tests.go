@@ -0,0 +1,280 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/audit"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunAuditTests executes all tests for the hash chained audit trail
+func RunAuditTests() {
+	testAuditChain()
+	testAuditTamper()
+	testAuditReopen()
+	testAuditOverflow()
+	testAuditClosed()
+	testAuditOrders()
+	testAuditEngine()
+}
+
+// readAudit returns the entries of the audit file at path, nil if it cannot be read
+func readAudit(path string) []audit.Entry {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	var entries []audit.Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry audit.Entry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// auditActions returns the "actor action" of each entry
+func auditActions(entries []audit.Entry) []string {
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Actor+" "+entry.Action)
+	}
+	return actions
+}
+
+// hasAction reports whether an entry by actor has action
+func hasAction(entries []audit.Entry, actor, action string) bool {
+	for _, entry := range entries {
+		if entry.Actor == actor && entry.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// writeAudit writes three entries to a new trail in dir and returns its file
+func writeAudit(dir string) (string, error) {
+	trail, err := audit.NewTrail(dir, 0)
+	if err != nil {
+		return "", err
+	}
+	trail.Record("ma_crossover-1", audit.ActionOrderCreated, map[string]interface{}{"orderId": "ORD-1", "quantity": 1})
+	trail.Record(audit.ActorManual, audit.ActionKillSwitch, nil)
+	trail.Record(audit.ActorSystem, audit.ActionFlatten, map[string]interface{}{"orders": []string{"Sell 1 MESH6"}})
+	return trail.Path(), trail.Close()
+}
+
+func testAuditChain() {
+	dir, _ := os.MkdirTemp("", "audit")
+	defer os.RemoveAll(dir)
+	path, err := writeAudit(dir)
+	check("Audit trail writes and closes", err == nil)
+	check("The file is named after the UTC day", filepath.Base(path) == "audit-"+time.Now().UTC().Format("2006-01-02")+".jsonl")
+
+	n, err := audit.Verify(path)
+	check("An untouched file verifies", err == nil && n == 3)
+	entries := readAudit(path)
+	if len(entries) != 3 {
+		check("Audit file holds every entry", false)
+		return
+	}
+	check("Entries keep their order, actor and action", strings.Join(auditActions(entries), ",") ==
+		"ma_crossover-1 order_created,manual kill_switch,system flatten")
+	check("The first line starts the chain", entries[0].Prev == "" && len(entries[1].Prev) == 64)
+	check("Details are kept", entries[0].Details["orderId"] == "ORD-1")
+	check("Entries are stamped", time.Since(entries[0].Time) < time.Minute)
+
+	info, err := os.Stat(path)
+	check("The audit file is owner only", err == nil && info.Mode().Perm() == 0600)
+}
+
+func testAuditTamper() {
+	dir, _ := os.MkdirTemp("", "audit")
+	defer os.RemoveAll(dir)
+	path, err := writeAudit(dir)
+	if err != nil {
+		check(fmt.Sprintf("Audit trail writes (Error: %v)", err), false)
+		return
+	}
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	edited := append([]string(nil), lines...)
+	edited[0] = strings.Replace(edited[0], `"quantity":1`, `"quantity":5`, 1)
+	os.WriteFile(path, []byte(strings.Join(edited, "\n")+"\n"), 0600)
+	n, err := audit.Verify(path)
+	check("An edited line breaks the chain after it", errors.Is(err, audit.ErrChainBroken) && n == 1 &&
+		strings.Contains(err.Error(), "line 2"))
+
+	os.WriteFile(path, []byte(lines[0]+"\n"+lines[2]+"\n"), 0600)
+	_, err = audit.Verify(path)
+	check("A deleted line breaks the chain", errors.Is(err, audit.ErrChainBroken))
+
+	os.WriteFile(path, []byte(lines[1]+"\n"+lines[2]+"\n"), 0600)
+	_, err = audit.Verify(path)
+	check("A deleted first line breaks the chain", errors.Is(err, audit.ErrChainBroken) && strings.Contains(err.Error(), "line 1"))
+
+	os.WriteFile(path, []byte(lines[0]+"\nnot json\n"), 0600)
+	_, err = audit.Verify(path)
+	check("A line that is not an entry is refused", errors.Is(err, audit.ErrChainBroken))
+
+	_, err = audit.Verify(filepath.Join(dir, "missing.jsonl"))
+	check("A missing file is an error", err != nil && !errors.Is(err, audit.ErrChainBroken))
+}
+
+func testAuditReopen() {
+	dir, _ := os.MkdirTemp("", "audit")
+	defer os.RemoveAll(dir)
+	if _, err := writeAudit(dir); err != nil {
+		check(fmt.Sprintf("Audit trail writes (Error: %v)", err), false)
+		return
+	}
+	trail, err := audit.NewTrail(dir, 0)
+	if err != nil {
+		check(fmt.Sprintf("Audit trail reopens (Error: %v)", err), false)
+		return
+	}
+	trail.Record(audit.ActorManual, audit.ActionArm, nil)
+	trail.Close()
+
+	n, err := audit.Verify(trail.Path())
+	check("A restart carries on the day's chain", err == nil && n == 4)
+}
+
+func testAuditOverflow() {
+	dir, _ := os.MkdirTemp("", "audit")
+	defer os.RemoveAll(dir)
+	trail, err := audit.NewTrail(dir, 1)
+	if err != nil {
+		check(fmt.Sprintf("Audit trail opens (Error: %v)", err), false)
+		return
+	}
+	started := time.Now()
+	for i := 0; i < 2000; i++ {
+		trail.Record(audit.ActorSystem, audit.ActionOrderCreated, map[string]interface{}{"n": i})
+	}
+	check("Record never waits on the disk", time.Since(started) < time.Second)
+	trail.Close()
+
+	dropped := trail.Dropped()
+	entries := readAudit(trail.Path())
+	recorded, noted := 0, 0.0
+	for _, entry := range entries {
+		switch entry.Action {
+		case audit.ActionOrderCreated:
+			recorded++
+		case audit.ActionDropped:
+			noted += entry.Details["dropped"].(float64)
+		}
+	}
+	check("A full buffer drops entries and counts them", dropped > 0 && int64(recorded)+dropped == 2000)
+	check("Dropped entries are noted in the file", int64(noted) == dropped)
+	_, err = audit.Verify(trail.Path())
+	check("A file with drop notices still verifies", err == nil)
+}
+
+func testAuditClosed() {
+	var trail *audit.Trail
+	trail.Record(audit.ActorManual, audit.ActionArm, nil)
+	check("A nil trail records nothing", true)
+
+	dir, _ := os.MkdirTemp("", "audit")
+	defer os.RemoveAll(dir)
+	trail, _ = audit.NewTrail(dir, 0)
+	trail.Record(audit.ActorManual, audit.ActionArm, nil)
+	check("Close flushes queued entries", trail.Close() == nil && len(readAudit(trail.Path())) == 1)
+	trail.Record(audit.ActorManual, audit.ActionArm, nil)
+	check("A closed trail records nothing", trail.Close() == nil && len(readAudit(trail.Path())) == 1)
+}
+
+func testAuditOrders() {
+	dir, _ := os.MkdirTemp("", "audit")
+	defer os.RemoveAll(dir)
+	trail, err := audit.NewTrail(dir, 0)
+	if err != nil {
+		check(fmt.Sprintf("Audit trail opens (Error: %v)", err), false)
+		return
+	}
+	sim := execution.NewSimulatedExecutor()
+	sim.SetTickSize("MESH6", 0.25)
+	sim.SetMarket("MESH6", 5000, "2026-01-05T15:00:00Z")
+	om := execution.NewSimulatedOrderManager(sim, &config.Config{}, logger.NewLogger(10, logger.LevelWarn))
+	om.SetAuditTrail(trail)
+	om.SetSymbolOrigin("MESH6", "ma_crossover-1")
+
+	om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	limit, _ := om.SubmitLimitOrder("MESH6", models.SideBuy, 1, 4990)
+	om.ModifyOrder(limit.ID, 2, 4995)
+	om.CancelOrder(limit.ID)
+	trail.Close()
+
+	entries := readAudit(trail.Path())
+	check("Order state changes are recorded in order", strings.Join(auditActions(entries), ",") == strings.Join([]string{
+		"ma_crossover-1 order_created", "ma_crossover-1 order_filled",
+		"ma_crossover-1 order_created", "ma_crossover-1 order_submitted",
+		"ma_crossover-1 order_modified", "ma_crossover-1 order_canceled",
+	}, ","))
+	if len(entries) == 6 {
+		check("A fill records its price", entries[1].Details["avgPrice"] == 5000.0 && entries[1].Details["status"] == "FILLED")
+		check("A modify records the new quantity and price", entries[4].Details["quantity"] == 2.0 && entries[4].Details["price"] == 4995.0)
+		check("A cancel names the order", entries[5].Details["orderId"] == limit.ID)
+	}
+}
+
+func testAuditEngine() {
+	dir, _ := os.MkdirTemp("", "audit")
+	defer os.RemoveAll(dir)
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	cfg := &config.Config{
+		Risk:  config.RiskConfig{DailyLossLimit: 500},
+		Audit: config.AuditConfig{Enabled: true, Dir: dir},
+	}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	trail := e.AuditTrail()
+	check("The engine opens the audit trail on connect", trail != nil)
+
+	reloaded := *cfg
+	reloaded.Risk.DailyLossLimit = 400
+	e.ApplyConfig(&reloaded)
+	e.ApplyConfig(&reloaded)
+	e.KillSwitch()
+	e.Arm()
+	check("Shutdown finishes within its timeout", e.Shutdown(false) == nil)
+	check("Shutdown closes the audit trail", e.AuditTrail() == nil)
+	if trail == nil {
+		return
+	}
+
+	entries := readAudit(trail.Path())
+	reloads := 0
+	for _, entry := range entries {
+		if entry.Action == audit.ActionConfigReload {
+			reloads++
+			changes, _ := entry.Details["changes"].([]interface{})
+			check("A reload records each change", len(changes) == 1 && changes[0] == "risk.dailyLossLimit: 500 -> 400")
+		}
+	}
+	check("A reload without changes is not recorded", reloads == 1)
+	check("The kill switch is recorded", hasAction(entries, audit.ActorManual, audit.ActionKillSwitch))
+	check("Its flatten is recorded", hasAction(entries, audit.ActorManual, audit.ActionFlatten))
+	check("Arm is recorded", hasAction(entries, audit.ActorManual, audit.ActionArm))
+	_, err := audit.Verify(trail.Path())
+	check("The engine's audit file verifies", err == nil)
+}
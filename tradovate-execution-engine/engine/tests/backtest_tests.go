@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"fmt"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/backtest"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// RunBacktestTests executes all tests for the backtest harness.
+func RunBacktestTests() {
+	testBacktestRoundTrip()
+	testBacktestNoSignals()
+}
+
+// syntheticBars builds one bar per close with unique timestamps
+func syntheticBars(closes ...float64) []marketdata.Bar {
+	bars := make([]marketdata.Bar, len(closes))
+	for i, c := range closes {
+		bars[i] = marketdata.Bar{Timestamp: fmt.Sprintf("2026-01-05T15:%02d:00Z", i), Close: c}
+	}
+	return bars
+}
+
+func backtestConfig(bars []marketdata.Bar) backtest.Config {
+	return backtest.Config{
+		Strategy:      "ma_crossover",
+		Params:        map[string]string{"fast_length": "2", "slow_length": "4", "update_mode": "1"},
+		Symbol:        "MESH6",
+		ValuePerPoint: 5,
+		Bars:          bars,
+		Risk:          config.RiskConfig{MaxContracts: 1, DailyLossLimit: 500, EnableRiskChecks: true},
+	}
+}
+
+func testBacktestRoundTrip() {
+	// Cross above at 11 (buy), run up to 16, cross below at 12 (sell)
+	bars := syntheticBars(10, 10, 10, 10, 10, 11, 14, 16, 14, 12)
+	report, err := backtest.Run(backtestConfig(bars), nil)
+	check("Backtest runs without error", err == nil)
+	if err != nil {
+		return
+	}
+
+	check("Backtest records one closed trade", report.NumTrades == 1)
+	assertEqualsFloat("Backtest PnL is (12-11) * $5", 5.0, report.TotalPnL, 0.001)
+	assertEqualsFloat("Backtest win rate is 100%", 100.0, report.WinRate, 0.001)
+	// Equity peaks at (16-11)*5 = 25 then ends at 5
+	assertEqualsFloat("Backtest max drawdown is 20", 20.0, report.MaxDrawdown, 0.001)
+	check("Equity curve has a point per bar", len(report.EquityCurve) == len(bars))
+}
+
+func testBacktestNoSignals() {
+	report, err := backtest.Run(backtestConfig(syntheticBars(10, 10, 10, 10, 10, 10)), nil)
+	check("Flat market backtest runs without error", err == nil)
+	if err != nil {
+		return
+	}
+	check("Flat market produces no trades", report.NumTrades == 0 && report.TotalPnL == 0)
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunBalanceTests executes all tests for the account cash balance and margin.
+func RunBalanceTests() {
+	testBalanceFromUserSync()
+	testBalanceUpdates()
+	testLowBalanceWarning()
+}
+
+// newBalanceTracker starts a portfolio tracker for account 1 and returns it with
+// the trading subscriber that feeds it
+func newBalanceTracker(log *logger.Logger) (*portfolio.PortfolioTracker, *tradovate.DataSubscriber) {
+	trading := tradovate.NewDataSubscriptionManager(nullSender{})
+	md := tradovate.NewDataSubscriptionManager(nullSender{})
+	pt := portfolio.NewPortfolioTracker(trading, md, 7, 1, log)
+	pt.Start("demo")
+	return pt, trading
+}
+
+func cashBalanceEvent(trading *tradovate.DataSubscriber, entity string) {
+	trading.HandleEvent("props", json.RawMessage(`{"entityType":"cashBalance","entity":`+entity+`}`))
+}
+
+// warnings counts the log's warnings that contain text
+func warnings(log *logger.Logger, text string) int {
+	n := 0
+	for _, e := range log.GetEntries() {
+		if e.Level == logger.LevelWarn && strings.Contains(e.Message, text) {
+			n++
+		}
+	}
+	return n
+}
+
+func testBalanceFromUserSync() {
+	log := logger.NewLogger(50, logger.LevelDebug)
+	pt, trading := newBalanceTracker(log)
+
+	_, ok := pt.GetAvailableMargin()
+	check("No balance before user sync", pt.GetAccountBalance().UpdatedAt.IsZero() && !ok)
+
+	trading.HandleEvent("user/syncrequest", json.RawMessage(`{
+		"users":[{"id":7}],
+		"cashBalances":[
+			{"accountId":1,"tradeDate":{"year":2026,"month":1,"day":5},"amount":50250,"amountSOD":50000,"realizedPnL":250,"weekRealizedPnL":900},
+			{"accountId":2,"amount":999999}
+		],
+		"marginSnapshots":[{"id":1,"initialMargin":1500,"maintenanceMargin":1200,"totalUsedMargin":1500}]
+	}`))
+
+	b := pt.GetAccountBalance()
+	check("Balance and start of day balance come from user sync", b.Amount == 50250 && b.AmountSOD == 50000)
+	check("Day change is balance less start of day", b.DayChange() == 250)
+	check("Week realized PnL is kept", b.WeekRealizedPnL == 900)
+	check("Realized PnL still reaches the PnL tracker", pt.GetRealizedPnL() == 250)
+	check("Margin snapshot from user sync is kept", b.HasMargin && b.InitialMargin == 1500 && b.MaintenanceMargin == 1200)
+
+	available, ok := pt.GetAvailableMargin()
+	check("Available margin is balance less initial margin", ok && available == 48750)
+}
+
+func testBalanceUpdates() {
+	log := logger.NewLogger(50, logger.LevelDebug)
+	pt, trading := newBalanceTracker(log)
+
+	cashBalanceEvent(trading, `{"accountId":1,"amount":20000,"amountSOD":21000,"realizedPnL":-1000}`)
+	cashBalanceEvent(trading, `{"accountId":3,"amount":5}`)
+	b := pt.GetAccountBalance()
+	check("Cash balance events update the balance", b.Amount == 20000 && b.DayChange() == -1000)
+	check("Balances of other accounts are ignored", b.Amount != 5)
+
+	trading.HandleEvent("props", json.RawMessage(`{"entityType":"marginSnapshot","entity":{"id":1,"initialMargin":800,"autoLiqLevel":400}}`))
+	b = pt.GetAccountBalance()
+	check("Margin snapshot events update the margin", b.HasMargin && b.InitialMargin == 800 && b.AutoLiqLevel == 400)
+}
+
+func testLowBalanceWarning() {
+	log := logger.NewLogger(50, logger.LevelDebug)
+	pt, trading := newBalanceTracker(log)
+	pt.SetBalanceWarning(10000)
+
+	cashBalanceEvent(trading, `{"accountId":1,"amount":12000}`)
+	check("No warning above the threshold", warnings(log, "below the") == 0)
+
+	cashBalanceEvent(trading, `{"accountId":1,"amount":9500}`)
+	cashBalanceEvent(trading, `{"accountId":1,"amount":9400}`)
+	check("Falling below the threshold warns once", warnings(log, "below the $10000.00 warning level") == 1)
+
+	cashBalanceEvent(trading, `{"accountId":1,"amount":10500}`)
+	cashBalanceEvent(trading, `{"accountId":1,"amount":9000}`)
+	check("Warning repeats after the balance recovers", warnings(log, "below the") == 2)
+
+	pt.SetBalanceWarning(0)
+	cashBalanceEvent(trading, `{"accountId":1,"amount":100}`)
+	check("Threshold 0 turns the warning off", warnings(log, "below the") == 2)
+}
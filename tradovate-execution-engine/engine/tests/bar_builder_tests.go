@@ -0,0 +1,314 @@
+package tests
+
+import (
+	"time"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// RunBarBuilderTests executes all tests for quote to bar aggregation.
+func RunBarBuilderTests() {
+	testBarBuilderOHLC()
+	testBarBuilderFirstPartialBar()
+	testBarBuilderOutOfOrder()
+	testBarBuilderGaps()
+	testBarBuilderOnQuote()
+	testVolumeBarThreshold()
+	testVolumeBarLargeTrade()
+	testVolumeBarQuoteDelta()
+	testTickBars()
+	testBarSpec()
+	testFeedTimeFormats()
+	testFeedClockMalformed()
+	testFeedClockSkew()
+	testBarGraceStraggler()
+	testBarGraceQuietMarket()
+	testBarBuilderQuoteTime()
+}
+
+func collectBars(interval time.Duration) (*marketdata.BarBuilder, *[]marketdata.Bar) {
+	var bars []marketdata.Bar
+	b := marketdata.NewBarBuilder(interval, func(bar marketdata.Bar) {
+		bars = append(bars, bar)
+	})
+	return b, &bars
+}
+
+func collectSpecBars(barType marketdata.BarType, size int) (*marketdata.BarBuilder, *[]marketdata.Bar) {
+	var bars []marketdata.Bar
+	b := marketdata.NewBarBuilderFor(marketdata.BarSpec{Type: barType, Size: size}, func(bar marketdata.Bar) {
+		bars = append(bars, bar)
+	})
+	return b, &bars
+}
+
+var barBase = time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)
+
+func testBarBuilderOHLC() {
+	b, bars := collectBars(time.Minute)
+	b.AddTrade(100, barBase)
+	b.AddTrade(103, barBase.Add(10*time.Second))
+	b.AddTrade(98, barBase.Add(20*time.Second))
+	b.AddTrade(101, barBase.Add(50*time.Second))
+	check("Bar is not emitted while open", len(*bars) == 0)
+
+	b.AddTrade(102, barBase.Add(time.Minute))
+	check("Bar is emitted on next interval", len(*bars) == 1)
+	if len(*bars) == 1 {
+		bar := (*bars)[0]
+		assertEqualsFloat("Bar open", 100, bar.Open, 0.001)
+		assertEqualsFloat("Bar high", 103, bar.High, 0.001)
+		assertEqualsFloat("Bar low", 98, bar.Low, 0.001)
+		assertEqualsFloat("Bar close", 101, bar.Close, 0.001)
+		check("Bar is stamped with its start", bar.Timestamp == "2026-01-05T15:00:00Z")
+	}
+}
+
+func testBarBuilderFirstPartialBar() {
+	b, bars := collectBars(time.Minute)
+	b.AddTrade(100, barBase.Add(30*time.Second))
+	b.AddTrade(101, barBase.Add(70*time.Second))
+	check("First partial bar is dropped", len(*bars) == 0)
+
+	b.AddTrade(102, barBase.Add(2*time.Minute))
+	check("Following bar is emitted", len(*bars) == 1 && (*bars)[0].Close == 101)
+}
+
+func testBarBuilderOutOfOrder() {
+	b, bars := collectBars(time.Minute)
+	b.AddTrade(100, barBase)
+	b.AddTrade(101, barBase.Add(40*time.Second))
+	b.AddTrade(95, barBase.Add(20*time.Second)) // Late trade inside the bar
+	b.AddTrade(110, barBase.Add(time.Minute))
+	b.AddTrade(90, barBase.Add(30*time.Second)) // Belongs to the closed bar
+
+	check("Late trades do not emit bars", len(*bars) == 1)
+	if len(*bars) == 1 {
+		assertEqualsFloat("Late trade updates low", 95, (*bars)[0].Low, 0.001)
+		assertEqualsFloat("Late trade does not replace close", 101, (*bars)[0].Close, 0.001)
+	}
+
+	b.AddTrade(111, barBase.Add(2*time.Minute))
+	check("Next bar is emitted", len(*bars) == 2)
+	if len(*bars) == 2 {
+		assertEqualsFloat("Trade for closed bar is dropped", 110, (*bars)[1].Low, 0.001)
+	}
+}
+
+func testBarBuilderGaps() {
+	b, bars := collectBars(5 * time.Minute)
+	b.AddTrade(100, barBase)
+	b.AddTrade(105, barBase.Add(17*time.Minute))
+	check("Gap produces a single bar", len(*bars) == 1)
+	if len(*bars) == 1 {
+		check("Bar uses 5 minute start", (*bars)[0].Timestamp == "2026-01-05T15:00:00Z")
+	}
+
+	b.AddTrade(106, barBase.Add(21*time.Minute))
+	check("Bar after gap is stamped at its own interval",
+		len(*bars) == 2 && (*bars)[1].Timestamp == "2026-01-05T15:15:00Z")
+}
+
+func testBarBuilderOnQuote() {
+	b, bars := collectBars(time.Minute)
+	b.OnQuote(marketdata.Quote{Timestamp: "2026-01-05T15:00:00Z", Entries: map[string]marketdata.Entry{"Trade": {Price: 100}}})
+	b.OnQuote(marketdata.Quote{Timestamp: "2026-01-05T15:00:30Z", Entries: map[string]marketdata.Entry{"Bid": {Price: 99}}})
+	b.OnQuote(marketdata.Quote{Timestamp: "not a time", Entries: map[string]marketdata.Entry{"Trade": {Price: 1}}})
+	b.OnQuote(marketdata.Quote{Timestamp: "2026-01-05T15:01:00Z", Entries: map[string]marketdata.Entry{"Trade": {Price: 101}}})
+
+	check("Quote trades build bars", len(*bars) == 1)
+	if len(*bars) == 1 {
+		bar := (*bars)[0]
+		check("Non-trade and bad quotes are ignored", bar.Low == 100 && bar.High == 100)
+	}
+}
+
+func testVolumeBarThreshold() {
+	b, bars := collectSpecBars(marketdata.BarTypeVolume, 10)
+	b.AddSizedTrade(100, 4, barBase)
+	b.AddSizedTrade(102, 4, barBase.Add(time.Second))
+	check("Volume bar stays open below the threshold", len(*bars) == 0)
+
+	b.AddSizedTrade(99, 5, barBase.Add(2*time.Second))
+	check("Volume bar closes at the threshold", len(*bars) == 1)
+	if len(*bars) == 1 {
+		bar := (*bars)[0]
+		check("Volume bar range", bar.Open == 100 && bar.High == 102 && bar.Low == 99 && bar.Close == 99)
+	}
+
+	// Three contracts carried into the next bar
+	b.AddSizedTrade(101, 9, barBase.Add(3*time.Second))
+	check("Remainder carries into the next bar", len(*bars) == 2 && (*bars)[1].Open == 99 && (*bars)[1].Close == 101)
+}
+
+func testVolumeBarLargeTrade() {
+	b, bars := collectSpecBars(marketdata.BarTypeVolume, 10)
+	b.AddSizedTrade(100, 35, barBase)
+	check("Large trade closes several bars", len(*bars) == 3)
+	if len(*bars) == 3 {
+		check("Bars from one trade have distinct timestamps",
+			(*bars)[0].Timestamp != (*bars)[1].Timestamp && (*bars)[1].Timestamp != (*bars)[2].Timestamp)
+	}
+}
+
+func testVolumeBarQuoteDelta() {
+	b, bars := collectSpecBars(marketdata.BarTypeVolume, 10)
+	quote := func(ts string, price, size, total float64) marketdata.Quote {
+		return marketdata.Quote{Timestamp: ts, Entries: map[string]marketdata.Entry{
+			"Trade":            {Price: price, Size: size},
+			"TotalTradeVolume": {Size: total},
+		}}
+	}
+	b.OnQuote(quote("2026-01-05T15:00:00Z", 100, 3, 1000))
+	b.OnQuote(quote("2026-01-05T15:00:01Z", 100, 3, 1006))
+	// Bid/offer change repeats the last trade without new volume
+	b.OnQuote(quote("2026-01-05T15:00:02Z", 100, 3, 1006))
+	b.OnQuote(quote("2026-01-05T15:00:03Z", 100, 3, 1006))
+	check("Repeated quotes add no volume", len(*bars) == 0)
+
+	b.OnQuote(quote("2026-01-05T15:00:04Z", 101, 4, 1010))
+	check("Volume is taken from the TotalTradeVolume increase", len(*bars) == 1)
+}
+
+func testTickBars() {
+	b, bars := collectSpecBars(marketdata.BarTypeTick, 3)
+	b.AddSizedTrade(100, 50, barBase)
+	b.AddSizedTrade(101, 1, barBase.Add(time.Second))
+	check("Tick bar counts trades, not contracts", len(*bars) == 0)
+
+	b.AddSizedTrade(102, 7, barBase.Add(2*time.Second))
+	check("Tick bar closes on the third trade", len(*bars) == 1 && (*bars)[0].Close == 102)
+}
+
+func testBarSpec() {
+	spec, err := marketdata.ParseBarSpec("", 1)
+	check("Empty bar type defaults to minute", err == nil && spec.Type == marketdata.BarTypeMinute)
+
+	_, err = marketdata.ParseBarSpec("range", 4)
+	check("Unknown bar type is rejected", err != nil)
+	_, err = marketdata.ParseBarSpec("volume", 0)
+	check("Zero bar size is rejected", err != nil)
+
+	desc := marketdata.BarSpec{Type: marketdata.BarTypeVolume, Size: 1000}.ChartDescription()
+	check("Volume chart description", desc.UnderlyingType == "Tick" && desc.ElementSize == 1000 && desc.ElementSizeUnit == "Volume")
+	desc = marketdata.BarSpec{Type: marketdata.BarTypeTick, Size: 500}.ChartDescription()
+	check("Tick chart description", desc.UnderlyingType == "Tick" && desc.ElementSize == 500 && desc.ElementSizeUnit == "UnderlyingUnits")
+}
+
+func testFeedTimeFormats() {
+	want := time.Date(2026, 1, 5, 15, 0, 0, 250_000_000, time.UTC)
+	for _, ts := range []string{
+		"2026-01-05T15:00:00.250Z",
+		"2026-01-05T10:00:00.25-05:00",
+		"2026-01-05T15:00:00.250",
+		"2026-01-05 15:00:00.25Z",
+	} {
+		got, err := marketdata.ParseFeedTime(ts)
+		check("Feed time "+ts+" parses", err == nil && got.Equal(want) && got.Location() == time.UTC)
+	}
+	got, err := marketdata.ParseFeedTime("2026-01-05T15:00:00Z")
+	check("Whole-second feed time parses", err == nil && got.Equal(barBase))
+
+	_, err = marketdata.ParseFeedTime("01/05/2026 15:00")
+	check("Unknown feed time format is an error", err != nil)
+}
+
+func testFeedClockMalformed() {
+	local := barBase.Add(10 * time.Second)
+	clock := marketdata.NewFeedClock(func() time.Time { return local })
+
+	got := clock.Time("garbage")
+	check("Malformed timestamp falls back to receive time", got.Equal(local))
+	check("Parse failure is counted", clock.Stats().ParseFailures == 1)
+
+	// Once skew is known the fallback is shifted onto the feed's clock
+	clock.Time("2026-01-05T15:00:07Z")
+	got = clock.Time("")
+	check("Fallback is corrected by the observed skew", got.Equal(barBase.Add(7*time.Second)))
+	check("Failures do not count as skew samples", clock.Stats().Samples == 1 && clock.Stats().ParseFailures == 2)
+}
+
+func testFeedClockSkew() {
+	local := barBase
+	clock := marketdata.NewFeedClock(func() time.Time { return local })
+	check("No skew before the first quote", clock.Skew() == 0)
+
+	// Feed runs 3 seconds ahead of the local clock
+	for i := 0; i < 5; i++ {
+		local = barBase.Add(time.Duration(i) * time.Second)
+		got := clock.Time(local.Add(3 * time.Second).Format(time.RFC3339Nano))
+		check("Parsed feed time is returned", got.Equal(local.Add(3*time.Second)))
+	}
+	check("Steady skew is measured", clock.Skew() == 3*time.Second)
+
+	// One delayed quote moves the smoothed skew only a little
+	local = barBase.Add(10 * time.Second)
+	clock.Time(barBase.Format(time.RFC3339Nano))
+	stats := clock.Stats()
+	check("Outlier is damped", stats.Skew > 1500*time.Millisecond && stats.Skew < 3*time.Second)
+	check("Largest reading is kept", stats.MaxSkew == -10*time.Second)
+	check("Samples are counted", stats.Samples == 6)
+}
+
+func testBarGraceStraggler() {
+	b, bars := collectBars(time.Minute)
+	b.SetGrace(2 * time.Second)
+	b.AddTrade(100, barBase)
+	b.AddTrade(101, barBase.Add(59*time.Second))
+	b.AddTrade(105, barBase.Add(time.Minute))
+	check("Bar is held for the grace window", len(*bars) == 0)
+
+	// Stamped before the boundary but delivered after the next bar opened
+	b.AddTrade(97, barBase.Add(59*time.Second+900*time.Millisecond))
+	b.AddTrade(106, barBase.Add(time.Minute+time.Second))
+	check("Straggler does not emit the bar", len(*bars) == 0)
+
+	b.AddTrade(107, barBase.Add(time.Minute+2*time.Second))
+	check("Bar is emitted once the grace window passes", len(*bars) == 1)
+	if len(*bars) == 1 {
+		bar := (*bars)[0]
+		assertEqualsFloat("Straggler updates the held bar's low", 97, bar.Low, 0.001)
+		assertEqualsFloat("Straggler is the held bar's close", 97, bar.Close, 0.001)
+		check("Held bar keeps its start", bar.Timestamp == "2026-01-05T15:00:00Z")
+	}
+
+	b.AddTrade(90, barBase.Add(30*time.Second))
+	b.AddTrade(108, barBase.Add(2*time.Minute))
+	b.AddTrade(109, barBase.Add(2*time.Minute+2*time.Second))
+	check("Trade after the grace window is dropped", len(*bars) == 2)
+	if len(*bars) == 2 {
+		bar := (*bars)[1]
+		check("Next bar is untouched by the late trade", bar.Low == 105 && bar.High == 107 && bar.Close == 107)
+	}
+
+	b.SetGrace(time.Hour)
+	b.AddTrade(110, barBase.Add(3*time.Minute+30*time.Second))
+	check("Grace is capped at half the interval", len(*bars) == 3)
+}
+
+func testBarGraceQuietMarket() {
+	b, bars := collectBars(time.Minute)
+	b.SetGrace(time.Second)
+	b.OnQuote(marketdata.Quote{Timestamp: "2026-01-05T15:00:00Z", Entries: map[string]marketdata.Entry{"Trade": {Price: 100}}})
+	b.OnQuote(marketdata.Quote{Timestamp: "2026-01-05T15:01:00Z", Entries: map[string]marketdata.Entry{"Trade": {Price: 101}}})
+	b.OnQuote(marketdata.Quote{Timestamp: "2026-01-05T15:01:00.5Z", Entries: map[string]marketdata.Entry{"Bid": {Price: 100.75}}})
+	check("Bid inside the grace window does not close the bar", len(*bars) == 0)
+
+	b.OnQuote(marketdata.Quote{Timestamp: "2026-01-05T15:01:01Z", Entries: map[string]marketdata.Entry{"Bid": {Price: 100.75}}})
+	check("Quotes without trades release the held bar", len(*bars) == 1 && (*bars)[0].Close == 100)
+
+	b.Reset()
+	b.AddTrade(100, barBase.Add(2*time.Minute))
+	b.AddTrade(101, barBase.Add(3*time.Minute))
+	b.Reset()
+	b.Advance(barBase.Add(4 * time.Minute))
+	check("Reset discards the held bar", len(*bars) == 1)
+}
+
+func testBarBuilderQuoteTime() {
+	b, bars := collectBars(time.Minute)
+	// A subscriber-set Time wins over the raw timestamp
+	b.OnQuote(marketdata.Quote{Timestamp: "bad", Time: barBase, Entries: map[string]marketdata.Entry{"Trade": {Price: 100}}})
+	b.OnQuote(marketdata.Quote{Timestamp: "2026-01-05T15:00:10", Entries: map[string]marketdata.Entry{"Trade": {Price: 102}}})
+	b.OnQuote(marketdata.Quote{Timestamp: "bad", Time: barBase.Add(time.Minute), Entries: map[string]marketdata.Entry{"Trade": {Price: 103}}})
+	check("Quote Time and zoneless timestamps build bars", len(*bars) == 1 && (*bars)[0].High == 102)
+}
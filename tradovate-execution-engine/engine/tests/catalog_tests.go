@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tradovate-execution-engine/engine/internal/contracts"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// fakeCatalogAPI answers the contract, maturity and product lookups for MESH6
+// and contract searches for MES, MESH6, MESZ9 and XYZ. Any other lookup fails.
+type fakeCatalogAPI struct {
+	calls []string
+}
+
+func (f *fakeCatalogAPI) GetJSON(endpoint string, out interface{}) error {
+	f.calls = append(f.calls, endpoint)
+	var body string
+	switch endpoint {
+	case "/v1/contract/find?name=MESH6", "/v1/contract/item?id=1":
+		body = `{"id":1,"name":"MESH6","contractMaturityId":10}`
+	case "/v1/contractMaturity/item?id=10":
+		body = `{"id":10,"productId":100,"expirationDate":"2026-03-20T13:30:00Z"}`
+	case "/v1/product/find?name=MES":
+		body = `{"id":100,"name":"MES","tickSize":0.25,"valuePerPoint":5}`
+	case "/v1/contract/suggest?t=MES&l=20":
+		body = `[{"id":1,"name":"MESH6","contractMaturityId":10},{"id":2,"name":"MESM6","contractMaturityId":11}]`
+	case "/v1/contract/suggest?t=MES&l=1", "/v1/contract/suggest?t=MESH6&l=20":
+		body = `[{"id":1,"name":"MESH6","contractMaturityId":10}]`
+	case "/v1/contract/suggest?t=MESZ9&l=20", "/v1/contract/suggest?t=XYZ&l=20":
+		body = `[]`
+	case "/v1/contractMaturity/items?ids=10,11":
+		body = `[{"id":10,"productId":100,"expirationDate":"2026-03-20T13:30:00Z"},{"id":11,"productId":100,"expirationDate":"2026-06-18T13:30:00Z"}]`
+	case "/v1/contractMaturity/items?ids=10":
+		body = `[{"id":10,"productId":100,"expirationDate":"2026-03-20T13:30:00Z"}]`
+	case "/v1/product/items?ids=100":
+		body = `[{"id":100,"name":"MES","description":"Micro E-mini S&P 500","tickSize":0.25,"valuePerPoint":5}]`
+	default:
+		return fmt.Errorf("unexpected endpoint %s", endpoint)
+	}
+	return json.Unmarshal([]byte(body), out)
+}
+
+// RunCatalogTests executes all tests for the contract metadata catalog.
+func RunCatalogTests() {
+	testCatalogSpecIsCached()
+	testCatalogSpecByID()
+	testCatalogFailedLookupBacksOff()
+	testCatalogTickHelpers()
+	testOrderPricesRoundedToTick()
+	testPortfolioFallsBackToCatalog()
+	testCatalogSearch()
+	testUnknownSymbols()
+}
+
+func testCatalogSpecIsCached() {
+	api := &fakeCatalogAPI{}
+	catalog := contracts.NewCatalog(api, nil)
+
+	spec, err := catalog.Spec("mesh6")
+	check("Spec is assembled from contract, maturity and product", err == nil && spec.Symbol == "MESH6" &&
+		spec.ContractID == 1 && spec.Product == "MES" && spec.TickSize == 0.25 && spec.ValuePerPoint == 5)
+	check("Spec carries the contract's expiration", spec.Expiration.Format("2006-01-02") == "2026-03-20")
+
+	fetched := len(api.calls)
+	catalog.Spec("MESH6")
+	catalog.TickSize("MESH6")
+	check("Later lookups are served from the cache", len(api.calls) == fetched)
+}
+
+func testCatalogSpecByID() {
+	api := &fakeCatalogAPI{}
+	catalog := contracts.NewCatalog(api, nil)
+
+	spec, err := catalog.SpecByID(1)
+	check("SpecByID names the contract from contract/item", err == nil && spec.Symbol == "MESH6" && spec.ValuePerPoint == 5)
+
+	fetched := len(api.calls)
+	catalog.Spec("MESH6")
+	catalog.SpecByID(1)
+	check("A contract found by ID is cached under its symbol too", len(api.calls) == fetched)
+}
+
+func testCatalogFailedLookupBacksOff() {
+	api := &fakeCatalogAPI{}
+	catalog := contracts.NewCatalog(api, nil)
+
+	_, err := catalog.Spec("MNQH6")
+	check("Unknown contract is an error", err != nil)
+	fetched := len(api.calls)
+	check("Value per point of an unknown contract is 0", catalog.ValuePerPoint("MNQH6") == 0)
+	check("A failed lookup is not retried straight away", len(api.calls) == fetched)
+}
+
+func testCatalogTickHelpers() {
+	catalog := contracts.NewCatalog(&fakeCatalogAPI{}, nil)
+
+	price, err := catalog.RoundToTick("MESH6", 5010.3)
+	check("RoundToTick rounds to the nearest quarter point", err == nil && price == 5010.25)
+	price, _ = catalog.RoundToTick("MESH6", 5010.38)
+	check("RoundToTick rounds up past half a tick", price == 5010.5)
+	distance, err := catalog.TicksToPrice("MESH6", 6)
+	check("TicksToPrice converts ticks to points", err == nil && distance == 1.5)
+	_, err = catalog.RoundToTick("MNQH6", 100)
+	check("RoundToTick fails for an unknown contract", err != nil)
+	check("RoundPrice with a cent tick has no float noise", contracts.RoundPrice(1.005*3, 0.01) == 3.02)
+	check("OnTick accepts a price on the grid", contracts.OnTick(6005.25, 0.25) && contracts.OnTick(0.1+0.2, 0.1))
+	check("OnTick refuses a price between ticks", !contracts.OnTick(6005.3, 0.25))
+	check("OnTick accepts any price when the tick is unknown", contracts.OnTick(6005.3, 0))
+}
+
+func testOrderPricesRoundedToTick() {
+	var body map[string]interface{}
+	om, cleanup := capturePlaceOrder(&body)
+	defer cleanup()
+	om.SetContractCatalog(contracts.NewCatalog(&fakeCatalogAPI{}, nil))
+
+	check("Tick size comes from the catalog when nothing else knows it", om.GetTickSize("MESH6") == 0.25)
+
+	order, err := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{
+		Type: models.TypeStopLimit, StopPrice: 5010.1, Price: 5011.4,
+	})
+	check("Stop-limit prices are rounded before submission", err == nil && order.StopPrice == 5010.0 && order.Price == 5011.5)
+	check("Rounded prices are what placeorder sends", body["stopPrice"] == 5010.0 && body["price"] == 5011.5)
+}
+
+func testPortfolioFallsBackToCatalog() {
+	ds := tradovate.NewDataSubscriptionManager(nullSender{})
+	tracker := portfolio.NewPortfolioTracker(ds, ds, 1, 0, nil)
+	check("Without a catalog an unsynced product has no value per point", tracker.GetValuePerPoint("MESH6") == 0)
+
+	tracker.SetContractCatalog(contracts.NewCatalog(&fakeCatalogAPI{}, nil))
+	check("Unsynced product's value per point comes from the catalog", tracker.GetValuePerPoint("MESH6") == 5)
+	check("Unsynced product's tick size comes from the catalog", tracker.GetTickSize("MESH6") == 0.25)
+}
+
+func testCatalogSearch() {
+	api := &fakeCatalogAPI{}
+	catalog := contracts.NewCatalog(api, nil)
+
+	matches, err := catalog.Search("mes", 0)
+	check("Search returns every suggested contract", err == nil && len(matches) == 2 &&
+		matches[0].Name == "MESH6" && matches[1].Name == "MESM6")
+	check("Matches carry their product description and expiration", len(matches) == 2 &&
+		matches[0].Description == "Micro E-mini S&P 500" && matches[1].Expiration.Format("2006-01-02") == "2026-06-18")
+
+	matches, err = catalog.Search("MES", 1)
+	check("Search passes the limit to Tradovate", err == nil && len(matches) == 1 && matches[0].ID == 1)
+
+	_, err = catalog.Search("NQ", 0)
+	check("A failed search is an error", err != nil)
+}
+
+func testUnknownSymbols() {
+	api := &fakeCatalogAPI{}
+	catalog := contracts.NewCatalog(api, nil)
+
+	check("A listed contract validates", catalog.Validate("mesh6") == nil)
+	err := catalog.Validate("MESZ9")
+	check("An unlisted contract is an unknown symbol", errors.Is(err, contracts.ErrUnknownSymbol))
+	check("A failed lookup is not an unknown symbol", !errors.Is(catalog.Validate("NQH6"), contracts.ErrUnknownSymbol))
+
+	catalog.Spec("MESH6")
+	calls := len(api.calls)
+	check("A contract in the catalog validates without a lookup", catalog.Validate("MESH6") == nil && len(api.calls) == calls)
+
+	resolver := contracts.NewResolver(api, 8, nil)
+	_, err = resolver.Resolve("XYZ")
+	check("A root with no contracts is an unknown symbol", errors.Is(err, contracts.ErrUnknownSymbol))
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunChartErrorTests executes all tests for refused md/getchart requests.
+func RunChartErrorTests() {
+	testHistoryRefusedFailsFast()
+	testGetChartAndWait()
+	testRefusedChartSubscription()
+}
+
+// answeringSender hands out request IDs and answers each md/getchart from
+// another goroutine, as the WebSocket reader would
+type answeringSender struct {
+	recordingSender
+	answer func(requestID int)
+}
+
+func (a *answeringSender) Send(url string, body interface{}) error {
+	_, err := a.SendRequest(url, body)
+	return err
+}
+
+func (a *answeringSender) SendRequest(url string, body interface{}) (int, error) {
+	id, err := a.recordingSender.SendRequest(url, body)
+	if url == "md/getchart" && a.answer != nil {
+		go a.answer(id)
+	}
+	return id, err
+}
+
+func testHistoryRefusedFailsFast() {
+	feed := newHistoryFeed(50, 100)
+	feed.refuse = "Unknown symbol"
+
+	start := time.Now()
+	_, err := feed.ds.LoadHistory(context.Background(), historyRequest(50))
+	var chartErr *tradovate.ChartError
+	check("Refused history page returns a ChartError", errors.As(err, &chartErr))
+	check("Refusal does not wait for the stall timeout", time.Since(start) < 500*time.Millisecond)
+	check("Refusal names the symbol and Tradovate's reason", err != nil &&
+		strings.Contains(err.Error(), "historical data request failed for MESH6: Unknown symbol"))
+}
+
+func testGetChartAndWait() {
+	sender := &answeringSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+
+	sender.answer = func(id int) {
+		ds.HandleResponse(id, "md/getchart", json.RawMessage(`{"historicalId":41,"realtimeId":42}`))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	check("Accepted chart request returns nil", ds.GetChartAndWait(ctx, testChartParams()) == nil)
+
+	sender.answer = func(id int) {
+		ds.HandleResponse(id, "md/getchart", json.RawMessage(`{"errorText":"Symbol not found","errorCode":"UnknownSymbol"}`))
+	}
+	err := ds.GetChartAndWait(ctx, testChartParams())
+	var chartErr *tradovate.ChartError
+	check("Refusal in the response payload is returned", errors.As(err, &chartErr) &&
+		chartErr.Status == 200 && chartErr.Text == "Symbol not found" && chartErr.Symbol == "MESH6")
+
+	sender.answer = nil
+	short, cancelShort := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelShort()
+	check("Unanswered request gives up with the context",
+		errors.Is(ds.GetChartAndWait(short, testChartParams()), context.DeadlineExceeded))
+}
+
+func testRefusedChartSubscription() {
+	sender := &recordingSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+
+	var mesErrors, nqErrors []*tradovate.ChartError
+	ds.AddChartErrorHandlerFor("MESH6", func(err *tradovate.ChartError) { mesErrors = append(mesErrors, err) })
+	ds.AddChartErrorHandlerFor("NQH6", func(err *tradovate.ChartError) { nqErrors = append(nqErrors, err) })
+	removed := ds.AddChartErrorHandlerFor("", func(err *tradovate.ChartError) { nqErrors = append(nqErrors, err) })
+	ds.RemoveChartErrorHandler(removed)
+
+	ds.SubscribeChart(testChartParams())
+	ds.HandleRequestError(1, "md/getchart", 404, "No market data entitlement")
+
+	check("Refused subscription reaches its symbol's error handler",
+		len(mesErrors) == 1 && mesErrors[0].Text == "No market data entitlement" && mesErrors[0].RequestID == 1)
+	check("Other symbols' and removed handlers are not called", len(nqErrors) == 0)
+	check("Refused subscription is no longer active", len(ds.GetActiveSubscriptions()) == 0)
+
+	ds.SubscribeChart(testChartParams())
+	check("Subscribing again sends a new request", len(sender.sent) == 2 && sender.sent[1].url == "md/getchart")
+}
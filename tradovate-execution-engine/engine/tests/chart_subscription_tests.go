@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"encoding/json"
+	"time"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunChartSubscriptionTests executes all tests for chart subscription tracking.
+func RunChartSubscriptionTests() {
+	testChartSubscribeConfirmUnsubscribe()
+	testChartUnsubscribeBeforeConfirm()
+	testChartConfirmedBeforeSendReturns()
+	testChartSymbolByID()
+	testRemovedHandlerNeverFires()
+	testHandlersFilteredByInstrument()
+}
+
+// sentMessage is a message captured by recordingSender
+type sentMessage struct {
+	url  string
+	body interface{}
+}
+
+// recordingSender is a WebSocketSender that records messages and hands out request IDs
+type recordingSender struct {
+	sent   []sentMessage
+	nextID int
+}
+
+func (r *recordingSender) Send(url string, body interface{}) error {
+	_, err := r.SendRequest(url, body)
+	return err
+}
+
+func (r *recordingSender) SendRequest(url string, body interface{}) (int, error) {
+	r.nextID++
+	r.sent = append(r.sent, sentMessage{url: url, body: body})
+	return r.nextID, nil
+}
+
+func (r *recordingSender) IsConnected() bool { return true }
+func (r *recordingSender) Connect() error    { return nil }
+
+func testChartParams() marketdata.HistoricalDataParams {
+	return marketdata.HistoricalDataParams{
+		Symbol:           "MESH6",
+		ChartDescription: marketdata.ChartDesc{UnderlyingType: "MinuteBar", ElementSize: 1, ElementSizeUnit: "UnderlyingUnits"},
+		TimeRange:        marketdata.TimeRange{AsMuchAsElements: 25},
+	}
+}
+
+// cancelledChartID returns the subscriptionId of a md/cancelchart message, or 0
+func cancelledChartID(msg sentMessage) int {
+	if msg.url != "md/cancelchart" {
+		return 0
+	}
+	body, ok := msg.body.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	id, _ := body["subscriptionId"].(int)
+	return id
+}
+
+func testChartSubscribeConfirmUnsubscribe() {
+	sender := &recordingSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+
+	ds.SubscribeChart(testChartParams())
+	check("Subscribe sends md/getchart", len(sender.sent) == 1 && sender.sent[0].url == "md/getchart")
+
+	ds.HandleResponse(1, "md/getchart", json.RawMessage(`{"historicalId":41,"realtimeId":42}`))
+
+	confirmed := false
+	for _, info := range ds.GetActiveSubscriptions() {
+		confirmed = info.ChartID == 42
+	}
+	check("Confirmation stores the realtime chart ID", confirmed)
+
+	ds.UnsubscribeChart(testChartParams())
+	check("Unsubscribe sends md/cancelchart with the chart ID",
+		len(sender.sent) == 2 && cancelledChartID(sender.sent[1]) == 42)
+	check("Subscription map is cleaned up", len(ds.GetActiveSubscriptions()) == 0)
+}
+
+func testChartUnsubscribeBeforeConfirm() {
+	sender := &recordingSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+
+	ds.SubscribeChart(testChartParams())
+	ds.UnsubscribeChart(testChartParams())
+	check("No cancel is sent before the chart ID is known", len(sender.sent) == 1)
+
+	ds.HandleResponse(1, "md/getchart", json.RawMessage(`{"historicalId":41,"realtimeId":42}`))
+	check("Late confirmation cancels the chart",
+		len(sender.sent) == 2 && cancelledChartID(sender.sent[1]) == 42)
+}
+
+// fastChartSender is a RequestSender whose md/getchart confirmation arrives
+// before SendRequest returns
+type fastChartSender struct {
+	recordingSender
+	ds *tradovate.DataSubscriber
+}
+
+func (f *fastChartSender) SendRequest(url string, body interface{}) (int, error) {
+	id, err := f.recordingSender.SendRequest(url, body)
+	if url == "md/getchart" {
+		go f.ds.HandleResponse(id, url, json.RawMessage(`{"historicalId":41,"realtimeId":42}`))
+		time.Sleep(20 * time.Millisecond)
+	}
+	return id, err
+}
+
+func testChartConfirmedBeforeSendReturns() {
+	sender := &fastChartSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+	sender.ds = ds
+
+	ds.SubscribeChart(testChartParams())
+	check("A confirmation racing the send still stores the chart ID", waitFor(func() bool {
+		_, known := ds.ChartSymbol(42)
+		return known
+	}))
+	ds.UnsubscribeChart(testChartParams())
+	check("The quickly confirmed chart is cancelled", len(sender.sent) == 2 && cancelledChartID(sender.sent[1]) == 42)
+}
+
+func testChartSymbolByID() {
+	sender := &recordingSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+
+	mnq := testChartParams()
+	mnq.Symbol = "MNQH6"
+	ds.SubscribeChart(testChartParams())
+	ds.SubscribeChart(mnq)
+
+	_, known := ds.ChartSymbol(42)
+	check("Unconfirmed chart has no symbol", !known)
+
+	ds.HandleResponse(1, "md/getchart", json.RawMessage(`{"historicalId":41,"realtimeId":42}`))
+	ds.HandleResponse(2, "md/getchart", json.RawMessage(`{"historicalId":51,"realtimeId":52}`))
+	first, _ := ds.ChartSymbol(42)
+	second, _ := ds.ChartSymbol(52)
+	check("Each chart ID maps to its own symbol", first == "MESH6" && second == "MNQH6")
+}
+
+func testRemovedHandlerNeverFires() {
+	ds := tradovate.NewDataSubscriptionManager(nullSender{})
+
+	kept, removed := 0, 0
+	ds.AddQuoteHandler(func(marketdata.Quote) { kept++ })
+	id := ds.AddQuoteHandler(func(marketdata.Quote) { removed++ })
+	chartID := ds.AddChartHandler(func(marketdata.ChartUpdate) { removed++ })
+
+	quote := json.RawMessage(`{"quotes":[{"contractId":1,"entries":{"Trade":{"price":5000,"size":1}}}]}`)
+	chart := json.RawMessage(`{"charts":[{"id":42,"bars":[{"timestamp":"2026-01-05T15:00:00Z","close":5000}]}]}`)
+	ds.HandleEvent(marketdata.EventMarketData, quote)
+	check("Registered handlers receive events", kept == 1 && removed == 1)
+
+	ds.RemoveQuoteHandler(id)
+	ds.RemoveChartHandler(chartID)
+	for i := 0; i < 3; i++ {
+		ds.HandleEvent(marketdata.EventMarketData, quote)
+		ds.HandleEvent(marketdata.EventChart, chart)
+	}
+	check("Removed handlers never fire again", removed == 1)
+	check("Other handlers keep firing", kept == 4)
+}
+
+func testHandlersFilteredByInstrument() {
+	sender := &recordingSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+
+	mnq := testChartParams()
+	mnq.Symbol = "MNQH6"
+	ds.SubscribeChart(testChartParams())
+	ds.SubscribeChart(mnq)
+	ds.HandleResponse(1, "md/getchart", json.RawMessage(`{"historicalId":41,"realtimeId":42}`))
+	ds.HandleResponse(2, "md/getchart", json.RawMessage(`{"historicalId":51,"realtimeId":52}`))
+
+	var mesCharts, mnqCharts []int
+	ds.AddChartHandlerFor("MESH6", func(u marketdata.ChartUpdate) {
+		for _, c := range u.Charts {
+			mesCharts = append(mesCharts, c.ID)
+		}
+	})
+	ds.AddChartHandlerFor("MNQH6", func(u marketdata.ChartUpdate) {
+		for _, c := range u.Charts {
+			mnqCharts = append(mnqCharts, c.ID)
+		}
+	})
+	ds.HandleEvent(marketdata.EventChart, json.RawMessage(`{"charts":[{"id":42,"bars":[]},{"id":52,"bars":[]},{"id":99,"bars":[]}]}`))
+	check("Chart handlers only see their symbol's charts",
+		len(mesCharts) == 1 && mesCharts[0] == 42 && len(mnqCharts) == 1 && mnqCharts[0] == 52)
+
+	var contracts []int
+	ds.AddQuoteHandlerFor(7, func(q marketdata.Quote) { contracts = append(contracts, q.ContractID) })
+	ds.HandleEvent(marketdata.EventMarketData, json.RawMessage(`{"quotes":[{"contractId":7,"entries":{}},{"contractId":8,"entries":{}}]}`))
+	check("Quote handlers only see their contract", len(contracts) == 1 && contracts[0] == 7)
+}
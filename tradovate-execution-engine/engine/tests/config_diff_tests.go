@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"tradovate-execution-engine/engine/config"
+)
+
+// RunConfigDiffTests executes all tests for describing what a reload changes
+func RunConfigDiffTests() {
+	testRiskChanges()
+	testReconnectRequiredChanges()
+}
+
+func testRiskChanges() {
+	base := validConfig()
+	cases := []struct {
+		name   string
+		change func(cfg *config.Config)
+		want   []string
+	}{
+		{"Identical configs have no risk changes", func(cfg *config.Config) {}, nil},
+		{"A changed limit shows old and new values", func(cfg *config.Config) { cfg.Risk.MaxContracts = 3 },
+			[]string{"maxContracts: 1 -> 3"}},
+		{"Each changed field is listed in struct order", func(cfg *config.Config) {
+			cfg.Risk.EnableRiskChecks = true
+			cfg.Risk.DailyLossLimit = 250
+		}, []string{"dailyLossLimit: 500 -> 250", "enableRiskChecks: false -> true"}},
+		{"Settings outside risk are not risk changes", func(cfg *config.Config) {
+			cfg.Fees.Commission = 1
+			cfg.Tradovate.Password = "other"
+		}, nil},
+	}
+	for _, tc := range cases {
+		newCfg := *base
+		tc.change(&newCfg)
+		got := config.RiskChanges(base, &newCfg)
+		check(fmt.Sprintf("%s (got %q)", tc.name, got), strings.Join(got, "|") == strings.Join(tc.want, "|"))
+	}
+}
+
+func testReconnectRequiredChanges() {
+	base := validConfig()
+	cases := []struct {
+		name   string
+		change func(cfg *config.Config)
+		want   []string
+	}{
+		{"Identical configs need no reconnect", func(cfg *config.Config) {}, nil},
+		{"A changed credential is named without its values", func(cfg *config.Config) { cfg.Tradovate.Password = "other" },
+			[]string{"password"}},
+		{"Each changed Tradovate field is listed", func(cfg *config.Config) {
+			cfg.Tradovate.Environment = "live"
+			cfg.Tradovate.Username = "someone"
+		}, []string{"environment", "username"}},
+		{"Hot-applied settings need no reconnect", func(cfg *config.Config) {
+			cfg.Risk.MaxContracts = 3
+			cfg.Logging.Main = "debug"
+		}, nil},
+	}
+	for _, tc := range cases {
+		newCfg := *base
+		tc.change(&newCfg)
+		got := config.ReconnectRequiredChanges(base, &newCfg)
+		check(fmt.Sprintf("%s (got %q)", tc.name, got), strings.Join(got, "|") == strings.Join(tc.want, "|"))
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// RunConfigValidationTests executes all tests for validating a config before
+// logging in.
+func RunConfigValidationTests() {
+	testConfigValidationTemplate()
+	testConfigValidationValid()
+	testConfigValidationRiskLimits()
+	testConnectRefusesInvalidConfig()
+}
+
+// validConfig is a config that passes Validate
+func validConfig() *config.Config {
+	return &config.Config{
+		Tradovate: config.TradovateConfig{
+			AppID: "Engine", AppVersion: "1.0", Chl: "chl", Cid: "1234", DeviceID: "device",
+			Environment: "demo", Username: "trader", Password: "secret", Sec: "token",
+		},
+		Risk: config.RiskConfig{MaxContracts: 1, DailyLossLimit: 500},
+	}
+}
+
+// problemKeys returns the keys err's problems name, nil when err is not a
+// *config.ValidationError
+func problemKeys(err error) []string {
+	var invalid *config.ValidationError
+	if !errors.As(err, &invalid) {
+		return nil
+	}
+	return invalid.Keys()
+}
+
+func testConfigValidationTemplate() {
+	path := filepath.Join(os.TempDir(), "config_validation_template.json")
+	defer os.Remove(path)
+	if err := config.CreateDefaultConfig(path); err != nil {
+		check("Template config written", false)
+		return
+	}
+	data, _ := os.ReadFile(path)
+	cfg, err := config.ParseConfig(data)
+	check("Template config parses", err == nil)
+	if err != nil {
+		return
+	}
+
+	keys := problemKeys(cfg.Validate())
+	check("Every template credential and the environment are problems", len(keys) == 9)
+	joined := strings.Join(keys, ",")
+	for _, key := range []string{"tradovate.appId", "tradovate.password", "tradovate.sec", "tradovate.environment"} {
+		check("Template problem names "+key, strings.Contains(joined, key))
+	}
+	check("Template risk limits pass", !strings.Contains(joined, "risk."))
+
+	msg := cfg.Validate().Error()
+	check("The error names the placeholder", strings.Contains(msg, `"your_password_here"`))
+	check("The error names the template environment", strings.Contains(msg, `"'live' or 'demo'"`))
+}
+
+func testConfigValidationValid() {
+	cfg := validConfig()
+	check("A complete demo config is valid", cfg.Validate() == nil)
+	cfg.Tradovate.Environment = "live"
+	check("A complete live config is valid", cfg.Validate() == nil)
+
+	cfg.Tradovate.Environment = "Live"
+	check("Environment must be exactly live or demo", strings.Join(problemKeys(cfg.Validate()), ",") == "tradovate.environment")
+
+	cfg = validConfig()
+	cfg.Tradovate.Username = "  "
+	cfg.Tradovate.DeviceID = "YOUR_DEVICE_ID_HERE"
+	keys := problemKeys(cfg.Validate())
+	check("Blank and placeholder credentials are both listed", len(keys) == 2 &&
+		keys[0] == "tradovate.deviceId" && keys[1] == "tradovate.username")
+}
+
+func testConfigValidationRiskLimits() {
+	cfg := validConfig()
+	cfg.Risk.MaxContracts = 0
+	cfg.Risk.DailyLossLimit = 0
+	keys := problemKeys(cfg.Validate())
+	check("maxContracts below 1 and a zero dailyLossLimit are listed", len(keys) == 2 &&
+		keys[0] == "risk.maxContracts" && keys[1] == "risk.dailyLossLimit")
+
+	cfg.Risk.DailyLossLimit = -10
+	check("A negative dailyLossLimit is a problem", len(problemKeys(cfg.Validate())) == 2)
+}
+
+func testConnectRefusesInvalidConfig() {
+	log := logger.NewLogger(50, logger.LevelDebug)
+	engine := app.NewEngine(log, logger.NewLogger(50, logger.LevelDebug), logger.NewLogger(50, logger.LevelDebug))
+	cfg := validConfig()
+	cfg.Tradovate.Password = "your_password_here"
+	cfg.Tradovate.Environment = "'live' or 'demo'"
+
+	err := engine.Connect(cfg)
+	keys := problemKeys(err)
+	check("Connect returns the validation problems", len(keys) == 2)
+	check("Connect does not attempt authentication", infosLogged(log, "Attempting Authentication") == 0)
+	check("Connect logs each problem", errorsLogged(log, "Config: tradovate.password") == 1 &&
+		errorsLogged(log, "Config: tradovate.environment") == 1)
+	check("Engine stays disconnected", !engine.IsConnected())
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/contracts"
+)
+
+// fakeContractAPI answers contract/suggest and contractMaturity/items from fixed JSON
+type fakeContractAPI struct {
+	calls int
+}
+
+func (f *fakeContractAPI) GetJSON(endpoint string, out interface{}) error {
+	f.calls++
+	var body string
+	switch {
+	case strings.HasPrefix(endpoint, "/v1/contract/suggest"):
+		body = `[{"id":1,"name":"MESH6","contractMaturityId":10},{"id":2,"name":"MESM6","contractMaturityId":11},{"id":3,"name":"MESU6","contractMaturityId":12}]`
+	case strings.HasPrefix(endpoint, "/v1/contractMaturity/items"):
+		// Expiries relative to now, since the resolver uses the wall clock
+		now := time.Now().UTC()
+		body = fmt.Sprintf(`[{"id":10,"expirationDate":%q},{"id":11,"expirationDate":%q},{"id":12,"expirationDate":%q}]`,
+			now.AddDate(0, 0, 3).Format(time.RFC3339), now.AddDate(0, 0, 90).Format(time.RFC3339), now.AddDate(0, 0, 180).Format(time.RFC3339))
+	default:
+		return fmt.Errorf("unexpected endpoint %s", endpoint)
+	}
+	return json.Unmarshal([]byte(body), out)
+}
+
+// RunContractTests executes all tests for front-month contract resolution.
+func RunContractTests() {
+	testFrontMonthRollover()
+	testResolverCacheAndOverride()
+}
+
+func testFrontMonthRollover() {
+	candidates := []contracts.Contract{
+		{Name: "MESH6", ContractMaturityID: 10},
+		{Name: "MESM6", ContractMaturityID: 11},
+		{Name: "MESH6-MESM6", ContractMaturityID: 13}, // Calendar spread
+	}
+	maturities := []contracts.ContractMaturity{
+		{ID: 10, ExpirationDate: "2026-03-20T13:30:00Z"},
+		{ID: 11, ExpirationDate: "2026-06-18T13:30:00Z"},
+		{ID: 13, ExpirationDate: "2026-03-20T13:30:00Z"},
+	}
+
+	early := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	got, err := contracts.FrontMonth("MES", candidates, maturities, early, 8)
+	check("Front month is the nearest expiry", err == nil && got == "MESH6")
+
+	inRollWindow := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	got, err = contracts.FrontMonth("MES", candidates, maturities, inRollWindow, 8)
+	check("Rolls to the next contract inside the rollover window", err == nil && got == "MESM6")
+
+	_, err = contracts.FrontMonth("MES", candidates, maturities, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), 8)
+	check("No live contract is an error", err != nil)
+
+	check("Concrete symbol is recognised", contracts.IsContract("MESH6"))
+	check("Root symbol is not a contract", !contracts.IsContract("MES") && !contracts.IsContract("M2K"))
+}
+
+func testResolverCacheAndOverride() {
+	api := &fakeContractAPI{}
+	r := contracts.NewResolver(api, 8, nil)
+
+	got, err := r.Resolve("MESH6")
+	check("Concrete symbols pass through without a lookup", err == nil && got == "MESH6" && api.calls == 0)
+
+	first, err := r.Resolve("mes")
+	check("Root resolves past the contract inside the rollover window", err == nil && first == "MESM6")
+	calls := api.calls
+	second, _ := r.Resolve("MES")
+	check("Resolution is cached for the session", second == first && api.calls == calls)
+
+	check("Override must be a contract", r.SetOverride("MES", "MES") != nil)
+	r.SetOverride("MES", "mesu6")
+	got, _ = r.Resolve("MES")
+	check("Override takes precedence", got == "MESU6")
+
+	r.ClearOverride("MES")
+	r.Resolve("MES")
+	check("Clearing the override resolves again", api.calls > calls)
+}
@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"tradovate-execution-engine/engine/indicators"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/testsupport"
+	"tradovate-execution-engine/engine/strategies"
+)
+
+// RunCrossoverOrderTests executes all tests for the orders MACrossover submits
+// through a recording fake broker
+func RunCrossoverOrderTests() {
+	testNoOrdersDuringWarmup()
+	testCrossUpBuys()
+	testCrossDownSells()
+	testReversalOrders()
+	testDisabledSubmitsNothing()
+	testPendingOrderBlocksSignals()
+	testBrokerRejectKeepsPosition()
+	testBrokerExpiryKeepsPosition()
+	testSubmitFailureKeepsPosition()
+	testInitChecksBroker()
+}
+
+// newBrokerCrossover returns a 2/4 MACrossover on MESH6 trading quantity
+// through a fake broker that fills each order as it is submitted
+func newBrokerCrossover(quantity string) (*strategies.MACrossover, *testsupport.FakeBroker, error) {
+	broker := testsupport.NewFakeBroker()
+	broker.SetAutoFill(true)
+	strategy := strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	if err := strategy.SetParam("quantity", quantity); err != nil {
+		return nil, nil, err
+	}
+	if err := strategy.Init(broker); err != nil {
+		return nil, nil, err
+	}
+	strategy.SetEnabled(true)
+	return strategy, broker, nil
+}
+
+// barSeq numbers the bars closeBars sends, since OnBar skips a repeated timestamp
+var barSeq int
+
+// closeBars sends closes to the strategy as bars, each filling at its close
+func closeBars(strategy *strategies.MACrossover, broker *testsupport.FakeBroker, closes ...float64) {
+	for _, c := range closes {
+		barSeq++
+		broker.SetPrice(c)
+		strategy.OnBar(fmt.Sprintf("B%d", barSeq), c)
+	}
+}
+
+// isOrder reports whether order is a market order for quantity on side
+func isOrder(order models.Order, side models.OrderSide, quantity int) bool {
+	return order.Symbol == "MESH6" && order.Side == side && order.Quantity == quantity && order.Type == models.TypeMarket
+}
+
+func testNoOrdersDuringWarmup() {
+	strategy, broker, err := newBrokerCrossover("1")
+	if err != nil {
+		check(fmt.Sprintf("Strategy initializes (Error: %v)", err), false)
+		return
+	}
+	// The fast average is above the slow one from the first bar both have
+	closeBars(strategy, broker, 10, 11, 12, 13)
+	check("No orders before slow_length bars", len(broker.Orders()) == 0)
+	closeBars(strategy, broker, 14, 15)
+	check("A trend that was there when warm-up ended is not traded", len(broker.Orders()) == 0 && strategy.GetPosition() == strategies.Flat)
+}
+
+func testCrossUpBuys() {
+	strategy, broker, err := newBrokerCrossover("2")
+	if err != nil {
+		check(fmt.Sprintf("Strategy initializes (Error: %v)", err), false)
+		return
+	}
+	closeBars(strategy, broker, 10, 10, 10, 10, 10)
+	check("Flat averages submit nothing", len(broker.Orders()) == 0)
+
+	closeBars(strategy, broker, 11)
+	orders := broker.Orders()
+	check("A cross up submits exactly one order", len(orders) == 1)
+	if len(orders) == 1 {
+		check("It is a market Buy for quantity", isOrder(orders[0], models.SideBuy, 2))
+	}
+	check("The strategy is Long once it fills", strategy.GetPosition() == strategies.Long)
+
+	closeBars(strategy, broker, 12, 13)
+	check("Staying above submits nothing more", len(broker.Orders()) == 1)
+}
+
+func testCrossDownSells() {
+	strategy, broker, err := newBrokerCrossover("1")
+	if err != nil {
+		check(fmt.Sprintf("Strategy initializes (Error: %v)", err), false)
+		return
+	}
+	closeBars(strategy, broker, 10, 10, 10, 10, 10, 9)
+	order, ok := broker.LastOrder()
+	check("A cross down from flat submits one Sell for quantity", ok && len(broker.Orders()) == 1 && isOrder(order, models.SideSell, 1))
+	check("The strategy is Short once it fills", strategy.GetPosition() == strategies.Short)
+}
+
+func testReversalOrders() {
+	strategy, broker, err := newBrokerCrossover("2")
+	if err != nil {
+		check(fmt.Sprintf("Strategy initializes (Error: %v)", err), false)
+		return
+	}
+	// Cross above at 11, cross below at 12, cross above again at 14
+	closeBars(strategy, broker, 10, 10, 10, 10, 10, 11, 14, 16, 14, 12, 8, 10, 14)
+	orders := broker.Orders()
+	check("Each cross submits one order", len(orders) == 3)
+	if len(orders) == 3 {
+		check("The first cross buys quantity", isOrder(orders[0], models.SideBuy, 2))
+		check("A reversal to short sells twice quantity", isOrder(orders[1], models.SideSell, 4))
+		check("A reversal to long buys twice quantity", isOrder(orders[2], models.SideBuy, 4))
+	}
+	check("The strategy ends Long", strategy.GetPosition() == strategies.Long)
+	check("No order was cancelled", len(broker.Cancelled()) == 0)
+}
+
+func testDisabledSubmitsNothing() {
+	strategy, broker, err := newBrokerCrossover("1")
+	if err != nil {
+		check(fmt.Sprintf("Strategy initializes (Error: %v)", err), false)
+		return
+	}
+	strategy.SetEnabled(false)
+	closeBars(strategy, broker, 10, 10, 10, 10, 10, 11, 14, 16, 14, 12)
+	check("A disabled strategy submits no orders", len(broker.Orders()) == 0)
+	check("A disabled strategy stays Flat", strategy.GetPosition() == strategies.Flat)
+}
+
+func testPendingOrderBlocksSignals() {
+	strategy, broker, err := newBrokerCrossover("1")
+	if err != nil {
+		check(fmt.Sprintf("Strategy initializes (Error: %v)", err), false)
+		return
+	}
+	broker.SetAutoFill(false)
+	closeBars(strategy, broker, 10, 10, 10, 10, 10, 11)
+	first, ok := broker.LastOrder()
+	check("Cross up submits a Buy that rests", ok && isOrder(first, models.SideBuy, 1) && first.Status == models.StatusSubmitted)
+	check("The position waits for the fill", strategy.GetPosition() == strategies.Flat)
+
+	closeBars(strategy, broker, 14, 16, 14, 12)
+	check("A signal while the order rests submits nothing", len(broker.Orders()) == 1)
+
+	broker.Fill(first.ID, 11)
+	check("The fill moves the position", strategy.GetPosition() == strategies.Long)
+	closeBars(strategy, broker, 16, 18, 14, 10)
+	order, _ := broker.LastOrder()
+	check("The next cross submits once the order has filled", len(broker.Orders()) == 2 && isOrder(order, models.SideSell, 2))
+}
+
+func testBrokerRejectKeepsPosition() {
+	strategy, broker, err := newBrokerCrossover("1")
+	if err != nil {
+		check(fmt.Sprintf("Strategy initializes (Error: %v)", err), false)
+		return
+	}
+	broker.SetAutoFill(false)
+	closeBars(strategy, broker, 10, 10, 10, 10, 10, 11)
+	order, _ := broker.LastOrder()
+	broker.Reject(order.ID, "Not enough margin")
+	check("A rejected order leaves the strategy Flat", strategy.GetPosition() == strategies.Flat)
+
+	broker.SetAutoFill(true)
+	closeBars(strategy, broker, 14, 16, 14, 12)
+	order, _ = broker.LastOrder()
+	check("The next cross trades from Flat", len(broker.Orders()) == 2 && isOrder(order, models.SideSell, 1) &&
+		strategy.GetPosition() == strategies.Short)
+}
+
+func testBrokerExpiryKeepsPosition() {
+	strategy, broker, err := newBrokerCrossover("1")
+	if err != nil {
+		check(fmt.Sprintf("Strategy initializes (Error: %v)", err), false)
+		return
+	}
+	broker.SetAutoFill(false)
+	closeBars(strategy, broker, 10, 10, 10, 10, 10, 11)
+	order, _ := broker.LastOrder()
+	broker.Expire(order.ID)
+	check("An expired order leaves the strategy Flat", strategy.GetPosition() == strategies.Flat)
+
+	broker.SetAutoFill(true)
+	closeBars(strategy, broker, 14, 16, 14, 12)
+	order, _ = broker.LastOrder()
+	check("The next cross trades after an expiry", len(broker.Orders()) == 2 && isOrder(order, models.SideSell, 1) &&
+		strategy.GetPosition() == strategies.Short)
+}
+
+func testSubmitFailureKeepsPosition() {
+	strategy, broker, err := newBrokerCrossover("1")
+	if err != nil {
+		check(fmt.Sprintf("Strategy initializes (Error: %v)", err), false)
+		return
+	}
+	broker.FailSubmits(errors.New("order refused"))
+	closeBars(strategy, broker, 10, 10, 10, 10, 10)
+	barSeq++
+	err = strategy.OnBar(fmt.Sprintf("B%d", barSeq), 11)
+	check("A failed submit is returned from OnBar", err != nil)
+	check("A failed submit leaves the strategy Flat", strategy.GetPosition() == strategies.Flat && len(broker.Orders()) == 0)
+}
+
+func testInitChecksBroker() {
+	broker := testsupport.NewFakeBroker()
+	broker.SetMaxContracts(1)
+	strategy := strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	strategy.SetParam("quantity", "2")
+	check("Init refuses a quantity above the broker's max contracts", strategy.Init(broker) != nil)
+
+	broker = testsupport.NewFakeBroker()
+	broker.SetMaxOrderQty(3)
+	strategy = strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	strategy.SetParam("quantity", "2")
+	check("Init refuses a quantity whose reversal is over the max order quantity", strategy.Init(broker) != nil)
+	broker.SetMaxOrderQty(4)
+	check("Init allows a reversal at the max order quantity", strategy.Init(broker) == nil)
+
+	broker = testsupport.NewFakeBroker()
+	broker.SetTickSize("MESH6", 0)
+	strategy = strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	strategy.SetParam("stop_loss_ticks", "8")
+	check("Init needs a tick size for stop_loss_ticks", strategy.Init(broker) != nil)
+	broker.SetTickSize("MESH6", 0.25)
+	check("Init succeeds once the tick size is known", strategy.Init(broker) == nil)
+}
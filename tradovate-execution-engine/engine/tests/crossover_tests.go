@@ -2,7 +2,12 @@ package tests
 
 import (
 	"fmt"
+	"strings"
+	"tradovate-execution-engine/engine/config"
 	"tradovate-execution-engine/engine/indicators"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
 	"tradovate-execution-engine/engine/strategies"
 )
 
@@ -10,6 +15,69 @@ import (
 func RunMACrossoverTests() {
 	testCrossAbove()
 	testCrossBelow()
+	testQuantityExceedsMaxContracts()
+	testReversalFlipsPosition()
+	testRejectedOrderKeepsPosition()
+	testStrategyLogRouting()
+	testStopLossFromFillPrice()
+	testTakeProfitAndReentry()
+	testIntrabarSignalOnEachTick()
+	testDescribeAll()
+}
+
+// newSimulatedCrossover returns an enabled Fast(2)/Slow(4) strategy trading through a simulator
+func newSimulatedCrossover(risk config.RiskConfig, quantity string) (*strategies.MACrossover, *execution.SimulatedExecutor, error) {
+	log := logger.NewLogger(100, logger.LevelWarn)
+	sim := execution.NewSimulatedExecutor()
+	om := execution.NewSimulatedOrderManager(sim, &config.Config{Risk: risk}, log)
+
+	strategy := strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	strategy.SetParam("quantity", quantity)
+	if err := strategy.Init(om); err != nil {
+		return nil, nil, err
+	}
+	strategy.SetEnabled(true)
+	return strategy, sim, nil
+}
+
+// feedBars sends closes to the strategy, marking the simulator first
+func feedBars(strategy *strategies.MACrossover, sim *execution.SimulatedExecutor, closes ...float64) {
+	for i, c := range closes {
+		ts := fmt.Sprintf("T%d", i)
+		sim.SetMarket("MESH6", c, ts)
+		strategy.OnBar(ts, c)
+	}
+}
+
+func testQuantityExceedsMaxContracts() {
+	_, _, err := newSimulatedCrossover(config.RiskConfig{MaxContracts: 1, DailyLossLimit: 500, EnableRiskChecks: true}, "2")
+	check("Init rejects quantity above max contracts", err != nil)
+}
+
+func testReversalFlipsPosition() {
+	strategy, sim, err := newSimulatedCrossover(config.RiskConfig{MaxContracts: 2, DailyLossLimit: 500, EnableRiskChecks: true}, "2")
+	if err != nil {
+		check("Simulated strategy initializes", false)
+		return
+	}
+
+	// Cross above at 11, cross below at 12
+	feedBars(strategy, sim, 10, 10, 10, 10, 10, 11, 14, 16, 14, 12)
+	check("Reversal leaves the strategy Short", strategy.GetPosition() == strategies.Short)
+	check("Reversal flips the actual position to -quantity", sim.GetPosition("MESH6").NetPos == -2)
+}
+
+func testRejectedOrderKeepsPosition() {
+	// A zero daily loss limit makes the risk manager reject every order
+	strategy, sim, err := newSimulatedCrossover(config.RiskConfig{MaxContracts: 1, EnableRiskChecks: true}, "1")
+	if err != nil {
+		check("Simulated strategy initializes", false)
+		return
+	}
+
+	feedBars(strategy, sim, 10, 10, 10, 10, 10, 11)
+	check("Rejected entry keeps the strategy Flat", strategy.GetPosition() == strategies.Flat)
+	check("Rejected entry leaves no position", sim.GetPosition("MESH6").NetPos == 0)
 }
 
 func testCrossAbove() {
@@ -48,3 +116,184 @@ func testCrossBelow() {
 	// Current: Fast(18.33) < Slow(19). Previous: Fast(20) == Slow(20).
 	check("Cross below detected when fast moves below slow", strategy.CrossBelow(1))
 }
+
+func testStrategyLogRouting() {
+	strategy, sim, err := newSimulatedCrossover(config.RiskConfig{MaxContracts: 1, DailyLossLimit: 500, EnableRiskChecks: true}, "1")
+	if err != nil {
+		check("Simulated strategy initializes", false)
+		return
+	}
+	stratLog := logger.NewLogger(100, logger.LevelInfo)
+	strategy.SetLogger(stratLog)
+
+	feedBars(strategy, sim, 10, 10, 10, 10, 10, 11)
+
+	var signal, submitted bool
+	for _, e := range stratLog.GetEntries() {
+		signal = signal || strings.Contains(e.Message, "Signal detected")
+		submitted = submitted || strings.Contains(e.Message, "Submitted Buy 1 MESH6")
+	}
+	check("Signal is logged to the injected strategy logger", signal)
+	check("Strategy order submission is logged to the strategy logger", submitted)
+
+	created, err := execution.CreateStrategy("ma_crossover", stratLog)
+	check("CreateStrategy builds a registered strategy", err == nil && created != nil)
+	if created != nil {
+		stratLog.Clear()
+		created.SetParam("fast_length", "3")
+		found := false
+		for _, e := range stratLog.GetEntries() {
+			found = found || strings.Contains(e.Message, "Parameter fast_length: 5 -> 3")
+		}
+		check("Parameter changes are logged to the strategy logger", found)
+	}
+}
+
+// newBracketedCrossover returns an enabled long-capable strategy with tick exits on a 0.25 tick contract
+func newBracketedCrossover(stopTicks, targetTicks string) (*strategies.MACrossover, *execution.SimulatedExecutor, error) {
+	log := logger.NewLogger(100, logger.LevelWarn)
+	sim := execution.NewSimulatedExecutor()
+	sim.SetTickSize("MESH6", 0.25)
+	om := execution.NewSimulatedOrderManager(sim, &config.Config{Risk: config.RiskConfig{MaxContracts: 2, DailyLossLimit: 500, EnableRiskChecks: true}}, log)
+
+	strategy := strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	strategy.SetParam("stop_loss_ticks", stopTicks)
+	strategy.SetParam("take_profit_ticks", targetTicks)
+	if err := strategy.Init(om); err != nil {
+		return nil, nil, err
+	}
+	strategy.SetEnabled(true)
+	return strategy, sim, nil
+}
+
+func testStopLossFromFillPrice() {
+	noTick := strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	noTick.SetParam("stop_loss_ticks", "4")
+	err := noTick.Init(execution.NewSimulatedOrderManager(execution.NewSimulatedExecutor(),
+		&config.Config{Risk: config.RiskConfig{MaxContracts: 1, DailyLossLimit: 500}}, logger.NewLogger(10, logger.LevelWarn)))
+	check("Init rejects tick exits when the tick size is unknown", err != nil)
+
+	strategy, sim, err := newBracketedCrossover("4", "0")
+	if err != nil {
+		check(fmt.Sprintf("Bracketed strategy initializes (Error: %v)", err), false)
+		return
+	}
+
+	feedBars(strategy, sim, 10, 10, 10, 10, 10)
+	// The signal bar closes at 11 but the entry fills at 11.50
+	sim.SetMarket("MESH6", 11.5, "T5")
+	strategy.OnBar("T5", 11)
+	check("Entry goes Long", strategy.GetPosition() == strategies.Long)
+
+	strategy.OnPrice(11)
+	check("Two ticks below the fill does not stop out", strategy.GetPosition() == strategies.Long)
+
+	sim.SetMarket("MESH6", 10.5, "T6")
+	strategy.OnPrice(10.5)
+	check("Four ticks below the fill price stops out", strategy.GetPosition() == strategies.Flat)
+	check("Stop closes the actual position", sim.GetPosition("MESH6").NetPos == 0)
+}
+
+func testTakeProfitAndReentry() {
+	strategy, sim, err := newBracketedCrossover("0", "8")
+	if err != nil {
+		check(fmt.Sprintf("Bracketed strategy initializes (Error: %v)", err), false)
+		return
+	}
+
+	feedBars(strategy, sim, 10, 10, 10, 10, 10, 11, 12)
+	check("Seven ticks in favor keeps the position", strategy.GetPosition() == strategies.Long)
+
+	sim.SetMarket("MESH6", 13, "T7")
+	strategy.OnBar("T7", 13)
+	check("Take profit on the bar close exits to Flat", strategy.GetPosition() == strategies.Flat)
+	check("Take profit closes the actual position", sim.GetPosition("MESH6").NetPos == 0)
+
+	// Fast drops below slow: a Flat strategy can enter again
+	for i, c := range []float64{11, 9} {
+		ts := fmt.Sprintf("R%d", i)
+		sim.SetMarket("MESH6", c, ts)
+		strategy.OnBar(ts, c)
+	}
+	check("Next crossover re-enters after the exit", strategy.GetPosition() == strategies.Short)
+	check("Re-entry is a fresh quantity, not a reversal", sim.GetPosition("MESH6").NetPos == -1)
+}
+
+// tradeQuote is a quote carrying only a trade at price
+func tradeQuote(price float64) marketdata.Quote {
+	return marketdata.Quote{Entries: map[string]marketdata.Entry{"Trade": {Price: price, Size: 1}}}
+}
+
+func testIntrabarSignalOnEachTick() {
+	log := logger.NewLogger(100, logger.LevelWarn)
+	sim := execution.NewSimulatedExecutor()
+	om := execution.NewSimulatedOrderManager(sim, &config.Config{Risk: config.RiskConfig{MaxContracts: 1, DailyLossLimit: 500, EnableRiskChecks: true}}, log)
+
+	barClose := strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	check("OnBarClose asks for bars only", execution.UpdateModeFor(barClose) == execution.UpdateOnBarClose)
+
+	strategy := strategies.NewMACrossover("MESH6", 2, 4, indicators.OnEachTick)
+	check("OnEachTick asks for quotes and bars", execution.UpdateModeFor(strategy) == execution.UpdateOnEachTick)
+	if err := strategy.Init(om); err != nil {
+		check("OnEachTick strategy initializes", false)
+		return
+	}
+	strategy.SetEnabled(true)
+
+	feedBars(strategy, sim, 10, 10, 10, 10)
+	sim.SetMarket("MESH6", 10.75, "T4")
+	strategy.OnQuote(tradeQuote(10.75))
+	check("A trade that crosses the averages on the forming bar enters at once", strategy.GetPosition() == strategies.Long)
+
+	sim.SetMarket("MESH6", 9, "T4")
+	strategy.OnQuote(tradeQuote(9))
+	check("Only the first cross in a bar signals", strategy.GetPosition() == strategies.Long && sim.GetPosition("MESH6").NetPos == 1)
+
+	strategy.OnBar("T4", 10.75)
+	check("The closing bar does not enter again", sim.GetPosition("MESH6").NetPos == 1)
+
+	strategy.OnBar("T5", 10.75)
+	sim.SetMarket("MESH6", 9, "T6")
+	strategy.OnQuote(tradeQuote(9))
+	check("A later bar can signal again intrabar", strategy.GetPosition() == strategies.Short)
+
+	sma := indicators.NewSMA(3, indicators.OnEachTick)
+	check("Preview is zero without a full period", sma.Preview(1) == 0)
+	sma.Update(1)
+	sma.Update(2)
+	check("Preview takes the price as the next input", sma.Preview(6) == 3 && sma.CurrentValue() == 0)
+	sma.Update(3)
+	check("Preview drops the oldest input once full", sma.Preview(7) == 4 && sma.CurrentValue() == 2)
+}
+
+func testDescribeAll() {
+	var info *execution.StrategyInfo
+	infos := execution.DescribeAll()
+	for i := range infos {
+		if infos[i].Key == "ma_crossover" {
+			info = &infos[i]
+		}
+	}
+	check("DescribeAll lists the registered strategy", info != nil && len(infos) == len(execution.GetAvailableStrategies()))
+	if info == nil {
+		return
+	}
+	check("Description comes from the strategy", info.Name == "MA Crossover" && strings.Contains(info.Description, "Moving Average"))
+
+	defaults := make(map[string]string)
+	for _, p := range info.Params {
+		defaults[p.Name] = p.Value
+	}
+	check("Params carry their defaults", defaults["symbol"] == "MESH6" && defaults["fast_length"] == "5" && defaults["slow_length"] == "15")
+
+	if created, err := execution.CreateStrategy("ma_crossover", logger.NewLogger(10, logger.LevelWarn)); err == nil {
+		created.SetParam("fast_length", "3")
+	}
+	for _, info := range execution.DescribeAll() {
+		for _, p := range info.Params {
+			if info.Key == "ma_crossover" && p.Name == "fast_length" {
+				check("Defaults are read from a fresh instance", p.Value == "5")
+			}
+		}
+	}
+}
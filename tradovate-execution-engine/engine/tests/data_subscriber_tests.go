@@ -0,0 +1,251 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/testsupport"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunDataSubscriberTests executes the DataSubscriber tests run against a scripted fake sender.
+func RunDataSubscriberTests() {
+	testSubscriberRefCounting()
+	testSubscriberChartCorrelation()
+	testSubscriberEventDispatch()
+	testSubscriberUnsubscribeAll()
+	testSubscriberRefusals()
+}
+
+// newFakeSubscriber returns a DataSubscriber on a fake sender that confirms
+// quote, DOM and user sync subscriptions and answers each md/getchart with
+// historical ID 10*n+1 and realtime ID 10*n+2 for its nth chart
+func newFakeSubscriber() (*tradovate.DataSubscriber, *testsupport.FakeSender) {
+	sender := testsupport.NewFakeSender()
+	ds := tradovate.NewDataSubscriptionManager(sender)
+	sender.Attach(ds)
+	for _, url := range []string{"md/subscribequote", "md/subscribedom", "user/syncrequest"} {
+		sender.AnswerAll(url, testsupport.Confirm())
+	}
+	charts := 0
+	sender.Answer("md/getchart", func(testsupport.Sent) testsupport.Reply {
+		charts++
+		return testsupport.Respond(fmt.Sprintf(`{"historicalId":%d,"realtimeId":%d}`, 10*charts+1, 10*charts+2))
+	})
+	return ds, sender
+}
+
+// chartParams is a one minute chart of symbol
+func chartParams(symbol string) marketdata.HistoricalDataParams {
+	params := testChartParams()
+	params.Symbol = symbol
+	return params
+}
+
+// refCount returns the reference count of the subscription to endpoint, 0 if there is none
+func refCount(ds *tradovate.DataSubscriber, endpoint string) int {
+	for _, info := range ds.GetActiveSubscriptions() {
+		if info.Endpoint == endpoint {
+			return info.RefCount
+		}
+	}
+	return 0
+}
+
+func testSubscriberRefCounting() {
+	type step struct {
+		do       func(ds *tradovate.DataSubscriber)
+		sends    []string // URLs sent by the step
+		refCount int      // Of the subscription afterwards
+	}
+	cases := []struct {
+		name     string
+		endpoint string
+		steps    []step
+	}{
+		{
+			name:     "Quotes",
+			endpoint: "md/subscribequote",
+			steps: []step{
+				{func(ds *tradovate.DataSubscriber) { ds.SubscribeQuote("MESH6") }, []string{"md/subscribequote"}, 1},
+				{func(ds *tradovate.DataSubscriber) { ds.SubscribeQuote("MESH6") }, nil, 2},
+				{func(ds *tradovate.DataSubscriber) { ds.UnsubscribeQuote("MESH6") }, nil, 1},
+				{func(ds *tradovate.DataSubscriber) { ds.UnsubscribeQuote("MESH6") }, []string{"md/unsubscribequote"}, 0},
+				{func(ds *tradovate.DataSubscriber) { ds.UnsubscribeQuote("MESH6") }, nil, 0},
+			},
+		},
+		{
+			name:     "DOM",
+			endpoint: "md/subscribedom",
+			steps: []step{
+				{func(ds *tradovate.DataSubscriber) { ds.SubscribeDOM("MNQH6") }, []string{"md/subscribedom"}, 1},
+				{func(ds *tradovate.DataSubscriber) { ds.SubscribeDOM("MNQH6") }, nil, 2},
+				{func(ds *tradovate.DataSubscriber) { ds.UnsubscribeDOM("MNQH6") }, nil, 1},
+				{func(ds *tradovate.DataSubscriber) { ds.UnsubscribeDOM("MNQH6") }, []string{"md/unsubscribedom"}, 0},
+			},
+		},
+		{
+			name:     "Charts",
+			endpoint: "md/getchart",
+			steps: []step{
+				{func(ds *tradovate.DataSubscriber) { ds.SubscribeChart(chartParams("MESH6")) }, []string{"md/getchart"}, 1},
+				{func(ds *tradovate.DataSubscriber) { ds.SubscribeChart(chartParams("MESH6")) }, nil, 2},
+				{func(ds *tradovate.DataSubscriber) { ds.UnsubscribeChart(chartParams("MESH6")) }, nil, 1},
+				{func(ds *tradovate.DataSubscriber) { ds.UnsubscribeChart(chartParams("MESH6")) }, []string{"md/cancelchart"}, 0},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		ds, sender := newFakeSubscriber()
+		for i, st := range tc.steps {
+			sender.Clear()
+			st.do(ds)
+			sender.Deliver()
+			name := fmt.Sprintf("%s step %d", tc.name, i+1)
+			check(name+" sends "+fmt.Sprint(st.sends), strings.Join(sender.URLs(), ",") == strings.Join(st.sends, ","))
+			check(fmt.Sprintf("%s leaves %d references", name, st.refCount), refCount(ds, tc.endpoint) == st.refCount)
+		}
+	}
+}
+
+func testSubscriberChartCorrelation() {
+	ds, sender := newFakeSubscriber()
+	ds.SubscribeChart(chartParams("MESH6"))
+	ds.SubscribeChart(chartParams("MNQH6"))
+	_, pending := ds.ChartSymbol(12)
+	check("Charts have no ID before their responses", !pending)
+
+	sender.Deliver()
+	mes, _ := ds.ChartSymbol(12)
+	mnq, _ := ds.ChartSymbol(22)
+	check("Each response's realtime ID goes to the chart that requested it", mes == "MESH6" && mnq == "MNQH6")
+
+	var got []string
+	ds.AddChartHandlerFor("MNQH6", func(update marketdata.ChartUpdate) {
+		for _, chart := range update.Charts {
+			got = append(got, fmt.Sprint(chart.ID))
+		}
+	})
+	sender.Event(marketdata.EventChart, `{"charts":[{"id":12,"bars":[]},{"id":22,"bars":[]}]}`)
+	check("Chart events reach the handler of their symbol only", strings.Join(got, ",") == "22")
+	sender.Event(marketdata.EventChart, `{"charts":[{"id":21,"bars":[]},{"id":11,"eoh":true},{"id":99,"eoh":true}]}`)
+	check("History on the historical ID reaches the handler too", strings.Join(got, ",") == "22,21")
+	check("A chart's IDs are its realtime then historical ID", fmt.Sprint(ds.ChartIDs(chartParams("MNQH6"))) == "[22 21]")
+
+	ds.UnsubscribeChart(chartParams("MNQH6"))
+	cancels := sender.SentTo("md/cancelchart")
+	check("Unsubscribing cancels the chart by its realtime ID", len(cancels) == 1 && cancels[0].JSON() == `{"subscriptionId":22}`)
+}
+
+func testSubscriberEventDispatch() {
+	cases := []struct {
+		name    string
+		event   string
+		data    string
+		handler string // Handler expected to receive it
+	}{
+		{"Quote", marketdata.EventMarketData, `{"quotes":[{"contractId":1,"entries":{"Trade":{"price":5000}}}]}`, "quote"},
+		{"Chart", marketdata.EventChart, `{"charts":[{"id":5,"bars":[]}]}`, "chart"},
+		{"User sync", marketdata.EventUser, `{"users":[{"id":1}]}`, "userSync"},
+		{"Position", marketdata.EventPosition, `{"id":3,"netPos":1}`, "position"},
+		{"Props order", marketdata.EventProps, `{"entityType":"order","entity":{"id":1,"ordStatus":"Working"}}`, "order"},
+		{"Props fill", marketdata.EventProps, `{"entityType":"fill","entity":{"id":1}}`, "fill"},
+		{"Props execution report", marketdata.EventProps, `{"entityType":"executionReport","entity":{"id":1}}`, "executionReport"},
+		{"Props fill pair", marketdata.EventProps, `{"entityType":"fillPair","entity":{"id":1}}`, "fillPair"},
+		{"Props position", marketdata.EventProps, `{"entityType":"position","entity":{"id":1}}`, "position"},
+		{"Props cash balance", marketdata.EventProps, `{"entityType":"cashBalance","entity":{"id":1}}`, "cashBalance"},
+		{"Props margin snapshot", marketdata.EventProps, `{"entityType":"marginSnapshot","entity":{"id":1}}`, "margin"},
+		{"Props of an unhandled type", marketdata.EventProps, `{"entityType":"contract","entity":{"id":1}}`, ""},
+	}
+
+	for _, tc := range cases {
+		ds, sender := newFakeSubscriber()
+		var got []string
+		record := func(name string) func(json.RawMessage) {
+			return func(json.RawMessage) { got = append(got, name) }
+		}
+		ds.AddQuoteHandler(func(marketdata.Quote) { got = append(got, "quote") })
+		ds.AddChartHandler(func(marketdata.ChartUpdate) { got = append(got, "chart") })
+		ds.OnUserSync = record("userSync")
+		ds.OnOrderUpdate = record("order")
+		ds.OnFillUpdate = record("fill")
+		ds.OnExecutionReport = record("executionReport")
+		ds.OnFillPairUpdate = record("fillPair")
+		ds.OnPositionUpdate = record("position")
+		ds.OnCashBalanceUpdate = record("cashBalance")
+		ds.OnMarginSnapshot = record("margin")
+
+		sender.Event(tc.event, tc.data)
+		want := []string{}
+		if tc.handler != "" {
+			want = []string{tc.handler}
+		}
+		check(fmt.Sprintf("%s event reaches %v", tc.name, want), strings.Join(got, ",") == strings.Join(want, ","))
+	}
+}
+
+func testSubscriberUnsubscribeAll() {
+	ds, sender := newFakeSubscriber()
+	ds.SubscribeQuotes([]string{"MESH6", "MNQH6"})
+	ds.SubscribeQuote("MESH6")
+	ds.SubscribeDOM("MNQH6")
+	ds.SubscribeChart(chartParams("MESH6"))
+	ds.SubscribeUserSyncRequests([]int{1}, nil)
+	sender.Deliver()
+	ds.SubscribeChart(chartParams("MNQH6")) // Left unconfirmed
+	sender.Clear()
+
+	ds.UnsubscribeAll()
+	var payloads []string
+	for _, msg := range sender.Sent() {
+		body := msg.JSON()
+		if msg.URL == "md/unsubscribequote" {
+			var req struct{ Symbol []string }
+			msg.Decode(&req)
+			sort.Strings(req.Symbol)
+			body = strings.Join(req.Symbol, "+")
+		}
+		payloads = append(payloads, msg.URL+" "+body)
+	}
+	sort.Strings(payloads)
+	want := []string{
+		`md/cancelchart {"subscriptionId":12}`,
+		`md/unsubscribedom {"symbol":"MNQH6"}`,
+		`md/unsubscribequote MESH6+MNQH6`,
+	}
+	check("UnsubscribeAll sends exactly one unsubscribe per stream", strings.Join(payloads, "\n") == strings.Join(want, "\n"))
+	active := ds.GetActiveSubscriptions()
+	check("UnsubscribeAll drops every stream; user sync has no unsubscribe and stays", len(active) == 1 && refCount(ds, "user/syncrequest") == 1)
+
+	sender.Clear()
+	sender.Deliver()
+	cancels := sender.SentTo("md/cancelchart")
+	check("A chart confirmed after UnsubscribeAll is cancelled", len(cancels) == 1 && cancels[0].JSON() == `{"subscriptionId":22}`)
+}
+
+func testSubscriberRefusals() {
+	ds, sender := newFakeSubscriber()
+	sender.AnswerAll("md/subscribequote", testsupport.Refuse(400, "Unknown symbol"))
+	var refused []*tradovate.WSError
+	ds.AddRequestErrorHandlerFor("", func(err *tradovate.WSError) { refused = append(refused, err) })
+
+	ds.SubscribeQuote("XYZH6")
+	sender.Deliver()
+	check("A refused subscription reaches the request error handlers", len(refused) == 1 &&
+		refused[0].Status == 400 && strings.Join(refused[0].Symbols, ",") == "XYZH6")
+	check("A refused subscription is dropped", refCount(ds, "md/subscribequote") == 0)
+
+	sender.AnswerAll("md/subscribequote", testsupport.Confirm())
+	sender.Clear()
+	ds.SubscribeQuote("XYZH6")
+	sender.Deliver()
+	check("Subscribing again after a refusal asks again", len(sender.SentTo("md/subscribequote")) == 1 &&
+		refCount(ds, "md/subscribequote") == 1)
+
+	sender.FailSends(fmt.Errorf("connection closed"))
+	check("A failed send returns its error", ds.SubscribeDOM("MNQH6") != nil)
+	check("A failed send leaves no subscription", refCount(ds, "md/subscribedom") == 0)
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"encoding/json"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// sampleDOMPayload is an "md" event body as sent for md/subscribedom
+const sampleDOMPayload = `{"doms":[{"contractId":3570918,"timestamp":"2026-01-05T15:04:12.511Z",
+"bids":[{"price":5012.25,"size":41},{"price":5012,"size":87},{"price":5011.75,"size":103}],
+"offers":[{"price":5012.5,"size":36},{"price":5012.75,"size":92}]}]}`
+
+// RunDOMTests executes all tests for depth-of-market parsing and subscriptions.
+func RunDOMTests() {
+	testParseDOMPayload()
+	testDOMHandlerDispatch()
+	testDOMSubscriptionRefCount()
+}
+
+func testParseDOMPayload() {
+	data, err := marketdata.ParseDOMData(json.RawMessage(sampleDOMPayload))
+	check("DOM payload parses", err == nil && data != nil && len(data.Doms) == 1)
+	if err != nil || len(data.Doms) != 1 {
+		return
+	}
+
+	dom := data.Doms[0]
+	check("Contract ID is parsed", dom.ContractID == 3570918)
+	check("Bid and offer ladders have every level", len(dom.Bids) == 3 && len(dom.Offers) == 2)
+
+	bid, okBid := dom.BestBid()
+	offer, okOffer := dom.BestOffer()
+	check("Best bid is the first bid level", okBid && bid.Price == 5012.25 && bid.Size == 41)
+	check("Best offer is the first offer level", okOffer && offer.Price == 5012.5 && offer.Size == 36)
+
+	_, ok := marketdata.DOM{}.BestBid()
+	check("Empty book has no best bid", !ok)
+}
+
+func testDOMHandlerDispatch() {
+	ds := tradovate.NewDataSubscriptionManager(nullSender{})
+
+	var doms []marketdata.DOM
+	quotes := 0
+	ds.AddDOMHandler(func(d marketdata.DOM) { doms = append(doms, d) })
+	ds.AddQuoteHandler(func(marketdata.Quote) { quotes++ })
+
+	ds.HandleEvent(marketdata.EventMarketData, json.RawMessage(sampleDOMPayload))
+	check("DOM handler receives the book", len(doms) == 1 && len(doms[0].Bids) == 3)
+	check("DOM payload does not produce quotes", quotes == 0)
+
+	ds.HandleEvent(marketdata.EventMarketData, json.RawMessage(`{"quotes":[{"contractId":3570918,"entries":{"Trade":{"price":5012.5,"size":1}}}]}`))
+	check("Quote payload does not produce DOM updates", len(doms) == 1 && quotes == 1)
+}
+
+func testDOMSubscriptionRefCount() {
+	sender := &recordingSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+	ds.SetLogger(logger.NewLogger(10, logger.LevelDebug))
+
+	ds.SubscribeDOM("MESH6")
+	ds.SubscribeDOM("MESH6")
+	check("Second DOM subscription reuses the stream", len(sender.sent) == 1 && sender.sent[0].url == "md/subscribedom")
+
+	ds.UnsubscribeDOM("MESH6")
+	check("DOM stream stays open while referenced", len(sender.sent) == 1)
+
+	ds.UnsubscribeDOM("MESH6")
+	check("Last DOM unsubscribe stops the stream", len(sender.sent) == 2 && sender.sent[1].url == "md/unsubscribedom")
+
+	ds.SubscribeDOM("MNQH6")
+	ds.UnsubscribeAll()
+	last := sender.sent[len(sender.sent)-1]
+	body, _ := last.body.(map[string]interface{})
+	check("UnsubscribeAll tears down DOM subscriptions", last.url == "md/unsubscribedom" && body["symbol"] == "MNQH6")
+	check("No subscriptions remain", len(ds.GetActiveSubscriptions()) == 0)
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"fmt"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/indicators"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/testsupport"
+	"tradovate-execution-engine/engine/strategies"
+)
+
+// RunDonchianTests executes all tests for the rolling max/min indicator and
+// the Donchian breakout strategy.
+func RunDonchianTests() {
+	testMaxMinRolling()
+	testMaxMinReset()
+	testDonchianNoSignalUntilFull()
+	testDonchianBreakoutReverses()
+	testDonchianExitOnOpposite()
+	testDonchianMetricsAndWarmup()
+	testDonchianExpiredOrder()
+	testDonchianInitChecksOrderQty()
+	testDonchianRegistered()
+}
+
+// newSimulatedDonchian returns an enabled breakout strategy trading MESH6 through a simulator
+func newSimulatedDonchian(length int, exitOnOpposite bool) (*strategies.DonchianBreakout, *execution.SimulatedExecutor, error) {
+	log := logger.NewLogger(100, logger.LevelWarn)
+	sim := execution.NewSimulatedExecutor()
+	risk := config.RiskConfig{MaxContracts: 2, DailyLossLimit: 500, EnableRiskChecks: true}
+	om := execution.NewSimulatedOrderManager(sim, &config.Config{Risk: risk}, log)
+
+	strategy := strategies.NewDonchianBreakout("MESH6", length, exitOnOpposite)
+	if err := strategy.Init(om); err != nil {
+		return nil, nil, err
+	}
+	strategy.SetEnabled(true)
+	return strategy, sim, nil
+}
+
+// feedDonchian sends closes to the strategy, marking the simulator first, and
+// returns the net position after each bar
+func feedDonchian(strategy *strategies.DonchianBreakout, sim *execution.SimulatedExecutor, closes ...float64) []int {
+	positions := make([]int, len(closes))
+	for i, c := range closes {
+		ts := fmt.Sprintf("T%d", i)
+		sim.SetMarket("MESH6", c, ts)
+		strategy.OnBar(ts, c)
+		positions[i] = sim.GetPosition("MESH6").NetPos
+	}
+	return positions
+}
+
+func testMaxMinRolling() {
+	mm := indicators.NewMaxMin(3)
+	high, low := mm.Update(10)
+	check("Max/min is zero before a full period", high == 0 && low == 0 && !mm.IsFull())
+	mm.Update(12)
+	high, low = mm.Update(11)
+	check("Max/min of the first full period", high == 12 && low == 10 && mm.IsFull())
+	high, low = mm.Update(9)
+	check("The oldest price leaves the window", high == 12 && low == 9)
+	mm.Update(9)
+	high, low = mm.Update(9)
+	check("A high leaves the window after period prices", high == 9 && low == 9 && mm.High() == 9 && mm.Low() == 9)
+}
+
+func testMaxMinReset() {
+	mm := indicators.NewMaxMin(2)
+	mm.Update(5)
+	mm.Update(6)
+	mm.Reset()
+	check("Reset empties the window", !mm.IsFull() && mm.High() == 0 && mm.Low() == 0)
+	high, low := mm.Update(7)
+	check("A reset window fills again from scratch", high == 0 && low == 0)
+	high, low = mm.Update(8)
+	check("Prices before the reset are gone", high == 8 && low == 7)
+}
+
+func testDonchianNoSignalUntilFull() {
+	strategy, sim, err := newSimulatedDonchian(3, false)
+	if err != nil {
+		check(fmt.Sprintf("Donchian initializes (Error: %v)", err), false)
+		return
+	}
+	// Rising closes would each break out, but the channel is not full until the fourth bar
+	positions := feedDonchian(strategy, sim, 10, 11, 12)
+	check("No breakout before the channel is full", fmt.Sprint(positions) == "[0 0 0]")
+	check("Ready once the channel is full", strategy.IsReady())
+}
+
+func testDonchianBreakoutReverses() {
+	strategy, sim, err := newSimulatedDonchian(3, false)
+	if err != nil {
+		check(fmt.Sprintf("Donchian initializes (Error: %v)", err), false)
+		return
+	}
+	// Channel of T0-T2 is 10..12. T3 closes at the upper line (no breakout),
+	// T4 above it (long), T5-T7 inside, T8 below the 12..13 channel (reverse short)
+	positions := feedDonchian(strategy, sim, 10, 12, 11, 12, 13, 12.5, 12, 12.5, 11.75)
+	check("A close at the upper line is not a breakout", positions[3] == 0)
+	check("A close above the upper line goes long on that bar", positions[4] == 1)
+	check("Closes inside the channel hold the position", positions[5] == 1 && positions[6] == 1 && positions[7] == 1)
+	check("A close below the lower line reverses short on that bar", positions[8] == -1 && strategy.GetPosition() == strategies.Short)
+}
+
+func testDonchianExitOnOpposite() {
+	strategy, sim, err := newSimulatedDonchian(3, true)
+	if err != nil {
+		check(fmt.Sprintf("Donchian initializes (Error: %v)", err), false)
+		return
+	}
+	// Short on T3 below 10..12; T4 inside; T5 touches the upper line of 9..11 (exit);
+	// T6 breaks above 9..11 again from flat (long); T7 touches the lower line 10..11 (exit)
+	positions := feedDonchian(strategy, sim, 10, 12, 11, 9, 10, 11, 12, 10)
+	check("A close below the lower line goes short", positions[3] == -1)
+	check("A close inside the channel holds the short", positions[4] == -1)
+	check("Touching the upper line exits the short flat", positions[5] == 0 && strategy.GetPosition() != strategies.Long)
+	check("A breakout from flat enters again", positions[6] == 1)
+	check("Touching the lower line exits the long flat", positions[7] == 0 && strategy.GetPosition() == strategies.Flat)
+}
+
+func testDonchianMetricsAndWarmup() {
+	strategy := strategies.NewDonchianBreakout("MESH6", 4, false)
+	check("Warm-up is a full channel plus the forming bar", strategy.WarmupBars() == 5)
+	check("Not ready before Init", !strategy.IsReady())
+	strategy.Init(nil)
+	for i, c := range []float64{10, 14, 12, 11} {
+		strategy.OnBar(fmt.Sprintf("T%d", i), c)
+	}
+	metrics := strategy.GetMetrics()
+	check("Metrics show the current channel", metrics["Upper Channel"] == 14 && metrics["Lower Channel"] == 10)
+
+	strategy.Reset()
+	metrics = strategy.GetMetrics()
+	check("Reset clears the channel", metrics["Upper Channel"] == 0 && metrics["Lower Channel"] == 0 && !strategy.IsReady())
+	check("Reset allows parameter changes", strategy.SetParam("channel_length", "6") == nil && strategy.WarmupBars() == 7)
+	check("exit_on_opposite takes a boolean", strategy.SetParam("exit_on_opposite", "maybe") != nil &&
+		strategy.SetParam("exit_on_opposite", "true") == nil)
+}
+
+func testDonchianExpiredOrder() {
+	broker := testsupport.NewFakeBroker()
+	strategy := strategies.NewDonchianBreakout("MESH6", 3, false)
+	if err := strategy.Init(broker); err != nil {
+		check(fmt.Sprintf("Donchian initializes (Error: %v)", err), false)
+		return
+	}
+	strategy.SetEnabled(true)
+
+	// T3 breaks above 10..12 with an order that rests, then expires
+	for i, c := range []float64{10, 12, 11, 13} {
+		broker.SetPrice(c)
+		strategy.OnBar(fmt.Sprintf("E%d", i), c)
+	}
+	order, ok := broker.LastOrder()
+	if !ok {
+		check("The breakout submits an order", false)
+		return
+	}
+	broker.Expire(order.ID)
+	check("An expired order leaves the strategy Flat", strategy.GetPosition() == strategies.Flat)
+
+	broker.SetAutoFill(true)
+	broker.SetPrice(14)
+	strategy.OnBar("E4", 14)
+	check("The next breakout trades after an expiry", len(broker.Orders()) == 2 && strategy.GetPosition() == strategies.Long)
+}
+
+func testDonchianInitChecksOrderQty() {
+	broker := testsupport.NewFakeBroker()
+	broker.SetMaxOrderQty(1)
+	strategy := strategies.NewDonchianBreakout("MESH6", 3, false)
+	check("Init refuses a quantity whose reversal is over the max order quantity", strategy.Init(broker) != nil)
+	broker.SetMaxOrderQty(2)
+	check("Init allows a reversal at the max order quantity", strategy.Init(broker) == nil)
+}
+
+func testDonchianRegistered() {
+	created, err := execution.CreateStrategy("donchian_breakout", logger.NewLogger(10, logger.LevelWarn))
+	check("donchian_breakout is registered", err == nil && created != nil && created.Name() == "Donchian Breakout")
+	if err != nil {
+		return
+	}
+	defaults := make(map[string]string)
+	for _, p := range created.GetParams() {
+		defaults[p.Name] = p.Value
+	}
+	check("Defaults cover channel length, symbol, quantity and exit", defaults["channel_length"] == "20" &&
+		defaults["symbol"] == "MESH6" && defaults["quantity"] == "1" && defaults["exit_on_opposite"] == "false")
+	check("Warm-up comes from the strategy", execution.WarmupBarsFor(created) == 21)
+	check("Params are checked against the schema", execution.ValidateParams(created, map[string]string{"channel_length": "0"}) != nil)
+}
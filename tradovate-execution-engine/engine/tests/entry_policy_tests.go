@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"fmt"
+	"time"
+	"tradovate-execution-engine/engine/internal/contracts"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/strategies"
+)
+
+// fixedQuotes is a QuoteSource with one bid and ask for every symbol
+type fixedQuotes struct {
+	bid, ask float64
+}
+
+func (q fixedQuotes) BestBidAsk(symbol string) (float64, float64, bool) {
+	return q.bid, q.ask, q.bid > 0
+}
+
+// RunEntryPolicyTests executes all tests for limit entries placed by entry policy.
+func RunEntryPolicyTests() {
+	testParseEntryType()
+	testEntryPolicyParams()
+	testLimitEntryPricing()
+	testLimitEntryFallsBackToMarket()
+	testLimitEntryTimeout()
+}
+
+// newEntryOrderManager returns an order manager whose MESH6 tick is 0.25,
+// whose quotes are 5000.00 bid / 5000.50 ask and that does not throttle the
+// test origins
+func newEntryOrderManager(log *logger.Logger) (*execution.OrderManager, *ocoExchange, func()) {
+	om, ex, cleanup := newOCOOrderManager(log)
+	cfg := om.GetRiskManager().GetConfig()
+	cfg.Risk.MaxStrategyOrdersPerMinute, cfg.Risk.MinOrderIntervalSeconds = -1, -1
+	om.SetContractCatalog(contracts.NewCatalog(&fakeCatalogAPI{}, nil))
+	om.SetQuoteSource(fixedQuotes{bid: 5000, ask: 5000.5})
+	return om, ex, cleanup
+}
+
+func testParseEntryType() {
+	valid := map[string]int{"market": 0, "limit_join": 0, "limit_through_2_ticks": 2, "LIMIT_THROUGH_1_TICK": 1, "limit_through_3": 3}
+	for value, ticks := range valid {
+		_, n, err := execution.ParseEntryType(value)
+		check(fmt.Sprintf("%s is an entry type", value), err == nil && n == ticks)
+	}
+	for _, value := range []string{"limit", "limit_through_0_ticks", "limit_through_x_ticks", ""} {
+		_, _, err := execution.ParseEntryType(value)
+		check(fmt.Sprintf("%q is refused", value), err != nil)
+	}
+}
+
+func testEntryPolicyParams() {
+	s := strategies.NewMACrossover("MESH6", 5, 15, 1)
+	check("Strategies enter at market by default", s.EntryPolicy().Type == execution.EntryMarket)
+
+	check("entry_type is set from its param", s.SetParam("entry_type", "limit_through_2_ticks") == nil &&
+		s.EntryPolicy().Type == execution.EntryLimitThrough && s.EntryPolicy().ThroughTicks == 2)
+	check("entry_timeout and entry_fallback are set", s.SetParam("entry_timeout", "5") == nil &&
+		s.SetParam("entry_fallback", "market") == nil &&
+		s.EntryPolicy().Timeout == 5*time.Second && s.EntryPolicy().Fallback == execution.EntryFallbackMarket)
+	check("A bad entry_type is refused", s.SetParam("entry_type", "limit") != nil)
+
+	p, ok := execution.FindParam(s, "entry_type")
+	check("entry_type is listed with its current value", ok && p.Value == "limit_through_2_ticks")
+	params := map[string]string{"fast_length": "5", "slow_length": "15", "entry_type": "join"}
+	check("Param validation catches a bad entry_type", s.ValidateParams(params) != nil)
+}
+
+func testLimitEntryPricing() {
+	om, ex, cleanup := newEntryOrderManager(logger.NewLogger(50, logger.LevelError))
+	defer cleanup()
+
+	om.SetEntryPolicy("join", execution.EntryPolicy{Type: execution.EntryLimitJoin, Timeout: time.Minute})
+	om.SetEntryPolicy("through", execution.EntryPolicy{Type: execution.EntryLimitThrough, ThroughTicks: 1, Timeout: time.Minute})
+
+	order, err := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: "join"})
+	check(fmt.Sprintf("Joining buy rests at the bid (Error: %v)", err), err == nil &&
+		order.Type == models.TypeLimit && order.Price == 5000)
+
+	order, err = om.SubmitOrder("MESH6", models.SideSell, 1, execution.OrderOptions{Origin: "join"})
+	check("Joining sell rests at the ask", err == nil && order.Type == models.TypeLimit && order.Price == 5000.5)
+
+	order, err = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: "through"})
+	check("Through buy is a tick above the bid", err == nil && order.Price == 5000.25)
+	order, err = om.SubmitOrder("MESH6", models.SideSell, 1, execution.OrderOptions{Origin: "through"})
+	check("Through sell is a tick below the ask", err == nil && order.Price == 5000.25)
+
+	ex.mu.Lock()
+	placed := ex.placed
+	ex.mu.Unlock()
+	check("Limit entries are sent as limit orders", len(placed) == 4 && placed[0]["orderType"] == "Limit" && placed[0]["price"] == 5000.0)
+
+	order, _ = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: "manual"})
+	check("Orders without a policy stay at market", order.Type == models.TypeMarket)
+	order, _ = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: "join", Type: models.TypeStop, StopPrice: 5010})
+	check("Orders that are not market orders are left alone", order.Type == models.TypeStop)
+}
+
+func testLimitEntryFallsBackToMarket() {
+	om, _, cleanup := newEntryOrderManager(logger.NewLogger(50, logger.LevelError))
+	defer cleanup()
+	om.SetEntryPolicy("join", execution.EntryPolicy{Type: execution.EntryLimitJoin, Timeout: time.Minute})
+
+	om.SetQuoteSource(fixedQuotes{})
+	order, err := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: "join"})
+	check("Without a quote the entry goes at market", err == nil && order.Type == models.TypeMarket)
+
+	om.SetQuoteSource(fixedQuotes{bid: 5000, ask: 5000.5})
+	order, err = om.SubmitOrder("NQH6", models.SideBuy, 1, execution.OrderOptions{Origin: "join"})
+	check("Without a tick size the entry goes at market", err == nil && order.Type == models.TypeMarket)
+
+	om.ClearEntryPolicy("join")
+	order, _ = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: "join"})
+	check("A cleared policy enters at market", order.Type == models.TypeMarket)
+}
+
+func testLimitEntryTimeout() {
+	om, ex, cleanup := newEntryOrderManager(logger.NewLogger(50, logger.LevelError))
+	defer cleanup()
+	om.SetEntryPolicy("cancel", execution.EntryPolicy{Type: execution.EntryLimitJoin, Timeout: 20 * time.Millisecond, Fallback: execution.EntryFallbackCancel})
+	om.SetEntryPolicy("market", execution.EntryPolicy{Type: execution.EntryLimitJoin, Timeout: 20 * time.Millisecond, Fallback: execution.EntryFallbackMarket})
+
+	cancelled, _ := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: "cancel"})
+	check("Unfilled limit entry is cancelled at its timeout", waitFor(func() bool {
+		return orderStatus(om, cancelled.ID) == models.StatusCanceled
+	}))
+
+	converted, _ := om.SubmitOrder("MESH6", models.SideBuy, 2, execution.OrderOptions{Origin: "market"})
+	check("Unfilled limit entry is converted to market at its timeout", waitFor(func() bool {
+		ex.mu.Lock()
+		defer ex.mu.Unlock()
+		return len(ex.modified) == 1 && ex.modified[0]["orderType"] == "Market" && ex.modified[0]["orderQty"] == 2.0
+	}))
+	order, _ := om.GetOrder(converted.ID)
+	om.Mu.RLock()
+	check("Converted entry is a market order", order.Type == models.TypeMarket && order.Price == 0)
+	om.Mu.RUnlock()
+
+	partial, _ := om.SubmitOrder("MESH6", models.SideBuy, 2, execution.OrderOptions{Origin: "cancel"})
+	fillLeg(om, partial.ID, 1, 1)
+	check("Partly filled entry is converted rather than cancelled", waitFor(func() bool {
+		ex.mu.Lock()
+		defer ex.mu.Unlock()
+		return len(ex.modified) == 2
+	}) && orderStatus(om, partial.ID) == models.StatusPartiallyFilled)
+
+	filled, _ := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: "cancel"})
+	fillLeg(om, filled.ID, 2, 1)
+	time.Sleep(50 * time.Millisecond)
+	check("Filled entry is left alone", orderStatus(om, filled.ID) == models.StatusFilled)
+	ex.mu.Lock()
+	check("Only the unfilled entries were cancelled", len(ex.cancelled) == 1)
+	ex.mu.Unlock()
+}
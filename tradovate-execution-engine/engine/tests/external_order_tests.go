@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/risk"
+)
+
+// RunExternalOrderTests executes all tests for orders placed outside the engine.
+func RunExternalOrderTests() {
+	testExternalOrderLifecycle()
+	testExternalOrderFromFill()
+	testExternalOrderAdopted()
+	testExternalOrdersInRiskChecks()
+}
+
+// externalOrders returns the external orders the order manager tracks
+func externalOrders(om *execution.OrderManager) []models.Order {
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	var orders []models.Order
+	for _, o := range om.GetAllOrders() {
+		if o.IsExternal() {
+			orders = append(orders, *o)
+		}
+	}
+	return orders
+}
+
+func testExternalOrderLifecycle() {
+	om, ex, cleanup := newOCOOrderManager(logger.NewLogger(20, logger.LevelWarn))
+	defer cleanup()
+	pt, _ := newLedgerTracker("")
+	om.SetPortfolioTracker(pt)
+
+	// A limit order from the mobile app to scale out
+	om.HandleOrderEvent(json.RawMessage(`{"id":99001,"contractId":100,"action":"Sell","orderType":"Limit","ordStatus":"Working","qty":2,"price":5010}`))
+	orders := externalOrders(om)
+	check("Unknown working order is tracked", len(orders) == 1)
+	if len(orders) != 1 {
+		return
+	}
+	o := orders[0]
+	check("External order is flagged with its origin", o.Origin == models.OriginExternal && o.ExternalID == "99001")
+	check("External order takes its details from the event",
+		o.Side == models.SideSell && o.Type == models.TypeLimit && o.Quantity == 2 && o.Price == 5010)
+	check("External order is working", o.Status == models.StatusSubmitted && om.WorkingOrderCount() == 1)
+	check("External orders are counted", om.ExternalOrderCount() == 1)
+
+	var updates []models.OrderStatus
+	om.AddOrderListener(func(o models.Order) { updates = append(updates, o.Status) })
+	om.HandleFillEvent(json.RawMessage(`{"id":501,"orderId":99001,"contractId":100,"action":"Sell","qty":1,"price":5010}`))
+	got, _ := om.GetOrder(o.ID)
+	check("Fill on an external order is applied", got.Status == models.StatusPartiallyFilled && got.FilledQty() == 1)
+
+	om.HandleOrderEvent(json.RawMessage(`{"id":99001,"ordStatus":"Canceled"}`))
+	got, _ = om.GetOrder(o.ID)
+	check("Cancel of an external order is tracked", got.Status == models.StatusCanceled && om.WorkingOrderCount() == 0)
+	check("Listeners see external status changes",
+		len(updates) == 2 && updates[0] == models.StatusPartiallyFilled && updates[1] == models.StatusCanceled)
+	check("Only one order is kept per external ID", om.ExternalOrderCount() == 1)
+
+	// Resting external orders are cancelled with ours
+	om.HandleOrderEvent(json.RawMessage(`{"id":99002,"contractId":100,"action":"Buy","orderType":"Stop","ordStatus":"Working","qty":1,"stopPrice":4990}`))
+	cancelled, err := om.CancelWorkingOrders()
+	check("Working external orders are cancelled by CancelWorkingOrders",
+		err == nil && cancelled == 1 && len(ex.cancelled) == 1 && ex.cancelled[0] == "99002")
+}
+
+func testExternalOrderFromFill() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(20, logger.LevelWarn))
+	defer cleanup()
+
+	om.HandleFillEvent(json.RawMessage(`{"id":601,"orderId":99003,"contractId":100,"action":"Buy","qty":1,"price":5000}`))
+	orders := externalOrders(om)
+	check("Fill for an unknown order creates an external order", len(orders) == 1 && orders[0].FilledQty() == 1)
+	if len(orders) != 1 {
+		return
+	}
+	check("Order seen only in a fill is not counted as working", om.WorkingOrderCount() == 0)
+	check("Contract without a name keeps its ID as the symbol", orders[0].Symbol == "100")
+	check("Order of unknown size waits for its Filled event", orders[0].Status == models.StatusPartiallyFilled)
+
+	om.HandleOrderEvent(json.RawMessage(`{"id":99003,"action":"Buy","orderType":"Market","ordStatus":"Filled"}`))
+	got, _ := om.GetOrder(orders[0].ID)
+	check("Filled event completes the external order", got.Status == models.StatusFilled)
+}
+
+func testExternalOrderAdopted() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(20, logger.LevelWarn))
+	defer cleanup()
+
+	order, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	if err != nil {
+		check("Order submits", false)
+		return
+	}
+
+	// Events for the next exchange ID, as if they beat the placeorder response
+	om.HandleFillEvent(json.RawMessage(`{"id":701,"orderId":9100,"contractId":100,"action":"Buy","qty":1,"price":5000}`))
+	om.HandleOrderEvent(json.RawMessage(`{"id":9100,"action":"Buy","orderType":"Market","ordStatus":"Filled"}`))
+	check("Early events are tracked until the order is claimed", om.ExternalOrderCount() == 1)
+
+	second, _ := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	om.AssignExternalID(second.ID, "9100")
+	got, _ := om.GetOrder(second.ID)
+	check("Claimed order takes the early fill and status", got.Status == models.StatusFilled && got.FilledQty() == 1)
+	check("External order is folded into ours", om.ExternalOrderCount() == 0 && len(om.GetAllOrders()) == 2)
+	check("Origin follows the claimed order", om.OriginOf(9100) == models.OriginManual)
+	first, _ := om.GetOrder(order.ID)
+	check("Other orders are left alone", first.ExternalID != "9100")
+}
+
+func testExternalOrdersInRiskChecks() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(20, logger.LevelWarn))
+	defer cleanup()
+	om.ApplyConfig(&config.Config{Risk: config.RiskConfig{MaxContracts: 10, DailyLossLimit: 500, MaxWorkingOrders: 3, EnableRiskChecks: true}})
+
+	om.HandleOrderEvent(json.RawMessage(`{"id":99011,"contractId":100,"action":"Sell","orderType":"Limit","ordStatus":"Working","qty":1,"price":5010}`))
+	om.HandleOrderEvent(json.RawMessage(`{"id":99012,"contractId":100,"action":"Sell","orderType":"Limit","ordStatus":"Working","qty":1,"price":5020}`))
+
+	// The new order is pending while it is checked, so it is the third
+	_, err := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Type: models.TypeLimit, Price: 4990})
+	check("External working orders count toward the working order limit", errors.Is(err, risk.ErrMaxWorkingOrders))
+
+	om.HandleOrderEvent(json.RawMessage(`{"id":99012,"ordStatus":"Canceled"}`))
+	_, err = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Type: models.TypeLimit, Price: 4990})
+	check(fmt.Sprintf("Cancelled external orders free the limit (Error: %v)", err), err == nil)
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"encoding/json"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/backtest"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+)
+
+// RunFeeTests executes all tests for the commission and exchange fee model.
+func RunFeeTests() {
+	testFeeConfig()
+	testClosedTradeFees()
+	testBacktestFees()
+	testDailyLossNetOfFees()
+}
+
+// feeLedgerSync is a user sync with one long 10 lot MESH6 round trip, $1 a point gross $50
+const feeLedgerSync = `{"users":[{"id":1}],
+	"fills":[
+		{"id":1,"orderId":11,"contractId":100,"timestamp":"2026-01-05T15:00:00Z","action":"Buy","qty":10,"price":5000},
+		{"id":2,"orderId":12,"contractId":100,"timestamp":"2026-01-05T15:05:00Z","action":"Sell","qty":10,"price":5001}],
+	"fillPairs":[{"id":50,"positionId":7,"buyFillId":1,"sellFillId":2,"qty":10,"buyPrice":5000,"sellPrice":5001}]}`
+
+func testFeeConfig() {
+	fees := config.FeeConfig{
+		Commission:  0.25,
+		ExchangeFee: 0.35,
+		Symbols:     map[string]config.ProductFees{"cl": {Commission: 1, ExchangeFee: 1.5}},
+	}
+	assertEqualsFloat("Default fees per contract per side", 0.60, fees.PerContract("MESH6"), 0.0001)
+	assertEqualsFloat("Product fees match by root whatever the key's case", 2.50, fees.PerContract("CLZ6"), 0.0001)
+	assertEqualsFloat("Round trip pays both sides for every contract", 2.40, fees.RoundTrip("MESH6", -2), 0.0001)
+
+	check("Fee config is valid", fees.Validate() == nil)
+	check("Negative fees are refused", config.FeeConfig{Commission: -1}.Validate() != nil)
+	check("A contract is refused as a product key",
+		config.FeeConfig{Symbols: map[string]config.ProductFees{"CLZ6": {}}}.Validate() != nil)
+	_, err := config.ParseConfig([]byte(`{"fees":{"symbols":{"MES":{"exchangeFee":-0.1}}}}`))
+	check("Config with a negative product fee is refused on load", err != nil)
+}
+
+func testClosedTradeFees() {
+	pt, _ := newLedgerTracker(feeLedgerSync)
+	pt.SetFees(config.FeeConfig{Commission: 0.25, ExchangeFee: 0.35})
+
+	trades := pt.GetClosedTrades()
+	check("Fee ledger has one closed trade", len(trades) == 1)
+	if len(trades) != 1 {
+		return
+	}
+	assertEqualsFloat("Trade fees are a round trip for its quantity", 12, trades[0].Fees, 0.0001)
+	assertEqualsFloat("Trade net PnL is gross less fees", 38, trades[0].NetPnL, 0.0001)
+	assertEqualsFloat("Fees since a time count trades closed after it", 12,
+		pt.GetFeesSince(time.Date(2026, 1, 5, 15, 1, 0, 0, time.UTC)), 0.0001)
+	assertEqualsFloat("Trades closed before the time are left out", 0,
+		pt.GetFeesSince(time.Date(2026, 1, 5, 16, 0, 0, 0, time.UTC)), 0.0001)
+
+	report := portfolio.BuildSessionReport(portfolio.SessionSnapshot{
+		SessionStart:      time.Date(2026, 1, 5, 14, 0, 0, 0, time.UTC),
+		EndingRealizedPnL: 50,
+		Trades:            trades,
+	}, nil, nil)
+	assertEqualsFloat("Session report sums the fees of its trades", 12, report.Fees, 0.0001)
+	assertEqualsFloat("Session report net PnL is realized less fees", 38, report.SessionNetPnL, 0.0001)
+}
+
+func testBacktestFees() {
+	cfg := backtestConfig(syntheticBars(10, 10, 10, 10, 10, 11, 14, 16, 14, 12))
+	cfg.Fees = config.FeeConfig{Commission: 0.5, ExchangeFee: 0.5}
+	report, err := backtest.Run(cfg, nil)
+	check("Backtest with fees runs without error", err == nil)
+	if err != nil {
+		return
+	}
+	assertEqualsFloat("Backtest gross PnL is unchanged by fees", 5, report.GrossPnL, 0.001)
+	// The cross below reverses into a short: one round trip plus the short's entry
+	assertEqualsFloat("Backtest fees are charged on every fill", 3, report.Fees, 0.001)
+	assertEqualsFloat("Backtest total PnL is net", 2, report.TotalPnL, 0.001)
+	if len(report.Trades) == 1 {
+		assertEqualsFloat("Simulated trade carries its net PnL", 3, report.Trades[0].NetPnL, 0.001)
+	}
+
+	sim := execution.NewSimulatedExecutor()
+	sim.SetFees("MESH6", 0.6)
+	sim.SetMarket("MESH6", 5000, "2026-01-05T15:00:00Z")
+	om := execution.NewSimulatedOrderManager(sim, &config.Config{}, logger.NewLogger(10, logger.LevelWarn))
+	om.SubmitMarketOrder("MESH6", models.SideBuy, 2)
+	assertEqualsFloat("Opening fill pays its side of the fees", 1.2, sim.GetPosition("MESH6").Fees, 0.0001)
+}
+
+func testDailyLossNetOfFees() {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	log := logger.NewLogger(10, logger.LevelDebug)
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 10, DailyLossLimit: 500, EnableRiskChecks: true}}
+
+	pt, trading := newLedgerTracker(feeLedgerSync)
+	pt.SetFees(config.FeeConfig{Commission: 0.25, ExchangeFee: 0.35})
+	om := execution.NewSimulatedOrderManager(execution.NewSimulatedExecutor(), cfg, log)
+	om.SetPortfolioTracker(pt)
+	rm := om.GetRiskManager()
+	rm.SetClock(func() time.Time { return time.Date(2026, 1, 5, 10, 0, 0, 0, chicago) })
+
+	trading.HandleEvent("props", json.RawMessage(
+		`{"entityType":"cashBalance","entity":{"accountId":1,"tradeDate":{"year":2026,"month":1,"day":5},"realizedPnL":-490}}`))
+
+	check("Gross daily PnL stays inside the limit", !rm.IsDailyLossExceeded())
+	cfg.Risk.DailyLossNet = true
+	assertEqualsFloat("Net daily PnL takes off the day's fees", -502, rm.GetDailyPnL(), 0.0001)
+	check("Fees can't mask a breach when the limit is net", rm.IsDailyLossExceeded())
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunFlattenPlanTests executes all tests for the flatten and kill switch previews.
+func RunFlattenPlanTests() {
+	testPreviewFlatten()
+	testPreviewMatchesExecution()
+	testPreviewNeedsPortfolio()
+	testEnginePreviews()
+}
+
+// newFlattenOrderManager returns an order manager holding long 2 MESH6 and
+// short 1 MNQH6, with a limit and a stop order resting
+func newFlattenOrderManager() (*execution.OrderManager, *ocoExchange, func()) {
+	om, ex, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelDebug))
+	trading := tradovate.NewDataSubscriptionManager(nullSender{})
+	md := tradovate.NewDataSubscriptionManager(nullSender{})
+	pt := portfolio.NewPortfolioTracker(trading, md, 1, 0, logger.NewLogger(10, logger.LevelWarn))
+	pt.Start("demo")
+	trading.HandleEvent("user/syncrequest", json.RawMessage(`{"users":[{"id":1}],
+		"positions":[
+			{"id":7,"accountId":1,"contractId":100,"netPos":2,"netPrice":5000},
+			{"id":8,"accountId":1,"contractId":101,"netPos":-1,"netPrice":18000}],
+		"contracts":[{"id":100,"name":"MESH6"},{"id":101,"name":"MNQH6"}],
+		"products":[{"name":"MES","valuePerPoint":5},{"name":"MNQ","valuePerPoint":2}]}`))
+	// Positions reach the PnL summary with their first quote
+	md.HandleEvent(marketdata.EventMarketData, json.RawMessage(`{"quotes":[
+		{"contractId":100,"entries":{"Trade":{"price":5001,"size":1}}},
+		{"contractId":101,"entries":{"Trade":{"price":17990,"size":1}}}]}`))
+	om.SetPortfolioTracker(pt)
+
+	om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Type: models.TypeLimit, Price: 4990})
+	om.SubmitStopOrder("MESH6", models.SideSell, 2, 4980)
+	return om, ex, cleanup
+}
+
+func testPreviewFlatten() {
+	om, ex, cleanup := newFlattenOrderManager()
+	defer cleanup()
+	placed := len(ex.placed)
+
+	plan, err := om.PreviewFlatten()
+	check(fmt.Sprintf("Flatten preview is built (Error: %v)", err), err == nil && !plan.Empty())
+	check("Both resting orders would be cancelled", len(plan.Cancels) == 2 &&
+		plan.Cancels[0].Type == models.TypeLimit && plan.Cancels[1].Type == models.TypeStop)
+	check("Each position has a closing order", len(plan.Closes) == 2)
+	if len(plan.Closes) == 2 {
+		check("Long position closes with a sell", plan.Closes[0] == execution.FlattenClose{Symbol: "MESH6", NetPos: 2, Side: models.SideSell, Quantity: 2})
+		check("Short position closes with a buy", plan.Closes[1] == execution.FlattenClose{Symbol: "MNQH6", NetPos: -1, Side: models.SideBuy, Quantity: 1})
+	}
+	check("Preview sends nothing", len(ex.placed) == placed && len(ex.cancelled) == 0)
+}
+
+func testPreviewMatchesExecution() {
+	om, ex, cleanup := newFlattenOrderManager()
+	defer cleanup()
+	placed := len(ex.placed)
+
+	plan, _ := om.PreviewFlatten()
+	om.CancelWorkingOrders()
+	om.FlattenPositions()
+
+	var wantCancels []string
+	for _, o := range plan.Cancels {
+		wantCancels = append(wantCancels, o.ExternalID)
+	}
+	check("Cancels sent match the preview", fmt.Sprint(ex.cancelled) == fmt.Sprint(wantCancels))
+
+	var want, got []string
+	for _, c := range plan.Closes {
+		want = append(want, fmt.Sprintf("%s %d", c.Side, c.Quantity))
+	}
+	for _, body := range ex.placed[placed:] {
+		got = append(got, fmt.Sprintf("%v %v", body["action"], body["orderQty"]))
+	}
+	check(fmt.Sprintf("Orders sent match the preview (want %v, got %v)", want, got), fmt.Sprint(got) == fmt.Sprint(want))
+
+	after, _ := om.PreviewFlatten()
+	check("Cancelled orders leave the preview", len(after.Cancels) == 0)
+}
+
+func testPreviewNeedsPortfolio() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(10, logger.LevelWarn))
+	defer cleanup()
+	_, err := om.PreviewFlatten()
+	check("Preview fails without a portfolio tracker", err != nil)
+}
+
+func testEnginePreviews() {
+	quiet := logger.NewLogger(10, logger.LevelWarn)
+	e := app.NewEngine(quiet, quiet, quiet)
+
+	_, err := e.PreviewFlatten()
+	check("Flatten preview needs a connection", err != nil)
+
+	plan, err := e.PreviewKillSwitch()
+	check("Kill switch preview works while disconnected", err == nil && plan.Empty() && len(plan.Strategies) == 0)
+	check("Preview does not engage the kill switch", !e.KillSwitchEngaged())
+}
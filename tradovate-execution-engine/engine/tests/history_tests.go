@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunHistoryTests executes all tests for paginated historical data.
+func RunHistoryTests() {
+	testHistoryPagination()
+	testHistoryStartOfData()
+	testHistoryStall()
+	testHistoryContextCancel()
+	testHistoryNeedsRequestIDs()
+}
+
+var historyBase = time.Date(2026, 1, 5, 14, 0, 0, 0, time.UTC)
+
+// historyFeed is a RequestSender that answers md/getchart from a series of one
+// minute bars, at most limit per request like Tradovate. Replies are sent from
+// another goroutine, as the WebSocket reader would. Pages after stallAfter get
+// a response but no bars.
+type historyFeed struct {
+	mu         sync.Mutex
+	ds         *tradovate.DataSubscriber
+	series     []marketdata.Bar
+	limit      int
+	stallAfter int
+	refuse     string // When set, every md/getchart is refused with this reason
+	nextID     int
+	requests   []marketdata.TimeRange
+	cancelled  []int
+}
+
+func newHistoryFeed(bars, limit int) *historyFeed {
+	f := &historyFeed{limit: limit}
+	for i := 0; i < bars; i++ {
+		f.series = append(f.series, marketdata.Bar{
+			Timestamp: historyBase.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+			Close:     float64(i),
+		})
+	}
+	f.ds = tradovate.NewDataSubscriptionManager(f)
+	return f
+}
+
+func (f *historyFeed) Send(url string, body interface{}) error {
+	_, err := f.SendRequest(url, body)
+	return err
+}
+
+func (f *historyFeed) SendRequest(url string, body interface{}) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	id := f.nextID
+
+	switch url {
+	case "md/cancelchart":
+		f.cancelled = append(f.cancelled, body.(map[string]interface{})["subscriptionId"].(int))
+	case "md/getchart":
+		params := body.(marketdata.HistoricalDataParams)
+		f.requests = append(f.requests, params.TimeRange)
+		if f.refuse != "" {
+			go f.ds.HandleRequestError(id, url, 404, f.refuse)
+			break
+		}
+		stall := f.stallAfter > 0 && len(f.requests) > f.stallAfter
+		go f.reply(id, f.page(params.TimeRange), stall)
+	}
+	return id, nil
+}
+
+func (f *historyFeed) IsConnected() bool { return true }
+func (f *historyFeed) Connect() error    { return nil }
+
+// page returns the bars at or before the closest timestamp, newest last
+func (f *historyFeed) page(tr marketdata.TimeRange) []marketdata.Bar {
+	end := len(f.series)
+	if tr.ClosestTimestamp != "" {
+		closest, _ := time.Parse(time.RFC3339, tr.ClosestTimestamp)
+		end = int(closest.Sub(historyBase)/time.Minute) + 1
+	}
+	end = max(min(end, len(f.series)), 0)
+	start := max(end-min(tr.AsMuchAsElements, f.limit), 0)
+	return f.series[start:end]
+}
+
+// reply answers with the chart IDs, then the bars newest first in two packets,
+// then the end of history marker
+func (f *historyFeed) reply(requestID int, bars []marketdata.Bar, stall bool) {
+	chartID := requestID * 10
+	f.ds.HandleResponse(requestID, "md/getchart", json.RawMessage(fmt.Sprintf(`{"historicalId":%d,"realtimeId":%d}`, chartID, chartID+1)))
+	if stall {
+		return
+	}
+
+	reversed := make([]marketdata.Bar, len(bars))
+	for i, bar := range bars {
+		reversed[len(bars)-1-i] = bar
+	}
+	half := len(reversed) / 2
+	for _, part := range [][]marketdata.Bar{reversed[:half], reversed[half:]} {
+		data, _ := json.Marshal(marketdata.ChartUpdate{Charts: []marketdata.Chart{{ID: chartID, Bars: part}}})
+		f.ds.HandleEvent("chart", data)
+	}
+	// Another chart's bars must not be mixed in
+	f.ds.HandleEvent("chart", json.RawMessage(`{"charts":[{"id":999,"bars":[{"timestamp":"2026-01-05T13:00:00Z","close":-1}]}]}`))
+	f.ds.HandleEvent("chart", json.RawMessage(fmt.Sprintf(`{"charts":[{"id":%d,"eoh":true}]}`, chartID)))
+}
+
+func historyRequest(bars int) tradovate.HistoryRequest {
+	return tradovate.HistoryRequest{
+		Params:       testChartParams(),
+		Bars:         bars,
+		PageSize:     100,
+		StallTimeout: time.Second,
+	}
+}
+
+// historyIsContiguous reports whether bars are one minute apart, oldest first,
+// ending at the last bar of the series
+func historyIsContiguous(bars []marketdata.Bar, seriesLen int) bool {
+	for i, bar := range bars {
+		if bar.Close != float64(seriesLen-len(bars)+i) {
+			return false
+		}
+	}
+	return true
+}
+
+func testHistoryPagination() {
+	feed := newHistoryFeed(1000, 100)
+	bars, err := feed.ds.LoadHistory(context.Background(), historyRequest(250))
+
+	check(fmt.Sprintf("Paged history loads (Error: %v)", err), err == nil)
+	check("Requested number of bars is returned", len(bars) == 250)
+	check("Pages are stitched oldest first without the overlap bar twice", historyIsContiguous(bars, 1000))
+	check("Three pages are requested", len(feed.requests) == 3)
+	if len(feed.requests) == 3 {
+		check("First page ends at the latest bar", feed.requests[0].ClosestTimestamp == "" && feed.requests[0].AsMuchAsElements == 100)
+		check("Next page ends at the oldest bar so far", feed.requests[1].ClosestTimestamp == bars[150].Timestamp)
+		check("Last page asks only for what is left plus the overlap", feed.requests[2].AsMuchAsElements == 52)
+	}
+	check("Each page's chart is cancelled", len(feed.cancelled) == 3 && feed.cancelled[0] == 11)
+}
+
+func testHistoryStartOfData() {
+	feed := newHistoryFeed(150, 100)
+	bars, err := feed.ds.LoadHistory(context.Background(), historyRequest(400))
+	check("Load stops cleanly at the start of the data", err == nil && len(bars) == 150 && historyIsContiguous(bars, 150))
+	check("An empty page ends the load", len(feed.requests) == 3)
+}
+
+func testHistoryStall() {
+	feed := newHistoryFeed(1000, 100)
+	feed.stallAfter = 1
+	req := historyRequest(300)
+	req.StallTimeout = 50 * time.Millisecond
+
+	started := time.Now()
+	bars, err := feed.ds.LoadHistory(context.Background(), req)
+	check("Stalled page fails the load", err != nil && bars == nil && strings.Contains(err.Error(), "stalled"))
+	check("Error names the page", err != nil && strings.Contains(err.Error(), "page 2"))
+	check("Stall is detected promptly", time.Since(started) < time.Second)
+	check("Stalled page's chart is still cancelled", len(feed.cancelled) == 2)
+}
+
+func testHistoryContextCancel() {
+	feed := newHistoryFeed(1000, 100)
+	feed.stallAfter = 1
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := feed.ds.LoadHistory(ctx, historyRequest(300))
+	check("Context deadline aborts the load", errors.Is(err, context.DeadlineExceeded))
+}
+
+func testHistoryNeedsRequestIDs() {
+	ds := tradovate.NewDataSubscriptionManager(nullSender{})
+	_, err := ds.LoadHistory(context.Background(), historyRequest(10))
+	check("Senders without request IDs are refused", err != nil)
+}
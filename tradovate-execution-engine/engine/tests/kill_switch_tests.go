@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunKillSwitchTests executes all tests for the kill switch.
+func RunKillSwitchTests() {
+	testKillSwitchBlocksOrders()
+	testKillSwitchAllowsFlatten()
+	testEngineKillSwitch()
+}
+
+func testKillSwitchBlocksOrders() {
+	var body map[string]interface{}
+	om, cleanup := capturePlaceOrder(&body)
+	defer cleanup()
+
+	om.EngageKillSwitch()
+	check("Kill switch reports engaged", om.KillSwitchEngaged())
+
+	_, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Market order is refused", errors.Is(err, execution.ErrKillSwitch))
+	_, err = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Type: models.TypeLimit, Price: 4990})
+	check("Limit order is refused", errors.Is(err, execution.ErrKillSwitch))
+	_, err = om.SubmitStopOrder("MESH6", models.SideSell, 1, 4980)
+	check("Stop order is refused", errors.Is(err, execution.ErrKillSwitch))
+	check("Refused orders never reach Tradovate", body == nil)
+	check("Refused orders are not tracked", len(om.GetAllOrders()) == 0)
+
+	om.Arm()
+	order, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Orders are accepted again after Arm", err == nil && order.Status == models.StatusSubmitted)
+}
+
+func testKillSwitchAllowsFlatten() {
+	var body map[string]interface{}
+	om, cleanup := capturePlaceOrder(&body)
+	defer cleanup()
+
+	om.EngageKillSwitch()
+	order, err := om.Flatten("MESH6", models.SideSell, 2)
+	check("Flatten order goes through while engaged", err == nil && order.Status == models.StatusSubmitted)
+	check("Flatten order is sent", body["action"] == "Sell")
+}
+
+func testEngineKillSwitch() {
+	orderLog := logger.NewLogger(50, logger.LevelDebug)
+	quiet := logger.NewLogger(10, logger.LevelWarn)
+	e := app.NewEngine(quiet, orderLog, quiet)
+
+	var events []app.EventKind
+	e.AddEventHandler(func(ev app.Event) { events = append(events, ev.Kind) })
+
+	check("Kill switch starts released", !e.KillSwitchEngaged())
+	check("Arm fails when not engaged", e.Arm() != nil)
+
+	err := e.KillSwitch()
+	check("Kill switch works while disconnected", err == nil && e.KillSwitchEngaged())
+
+	var steps []string
+	for _, entry := range orderLog.GetEntries() {
+		if strings.HasPrefix(entry.Message, "KILL SWITCH") {
+			steps = append(steps, entry.Message)
+		}
+	}
+	check("Each step is logged to the order log", len(steps) >= 4)
+	check("Engage is logged with its time",
+		len(steps) > 0 && strings.HasPrefix(steps[0], "KILL SWITCH ENGAGED at "))
+	check("Strategies are stopped", strings.Contains(strings.Join(steps, "\n"), "stopped 0 strategies"))
+
+	check("Arm releases the kill switch", e.Arm() == nil && !e.KillSwitchEngaged())
+	check("Engage and arm are reported as events",
+		len(events) == 2 && events[0] == app.EventKillSwitch && events[1] == app.EventArmed)
+}
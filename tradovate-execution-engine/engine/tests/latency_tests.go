@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunLatencyTests executes all tests for signal-to-fill latency measurement.
+func RunLatencyTests() {
+	testOrderStageTimestamps()
+	testExecutionStatsAggregate()
+	testSimulatedOrdersNotMeasured()
+	testLatencyStatsString()
+}
+
+// newSlowOrderManager returns a live order manager whose placeorder responses take
+// delay. Each order gets the next exchange ID, starting at 8001.
+func newSlowOrderManager(delay time.Duration) (*execution.OrderManager, func()) {
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 10, DailyLossLimit: 500, EnableRiskChecks: true}}
+	next := 8000
+	return newHTTPOrderManager(cfg, logger.NewLogger(10, logger.LevelDebug), func(om *execution.OrderManager, w http.ResponseWriter) {
+		time.Sleep(delay)
+		next++
+		fmt.Fprintf(w, `{"orderId":%d}`, next)
+	})
+}
+
+// fillOrder reports the order's exchange ID as filled
+func fillOrder(om *execution.OrderManager, order *models.Order) {
+	om.HandleOrderEvent(json.RawMessage(fmt.Sprintf(`{"id":%s,"ordStatus":"Filled"}`, order.ExternalID)))
+}
+
+func testOrderStageTimestamps() {
+	om, cleanup := newSlowOrderManager(20 * time.Millisecond)
+	defer cleanup()
+
+	signalAt := time.Now().Add(-5 * time.Millisecond)
+	order, err := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{SignalAt: signalAt})
+	time.Sleep(10 * time.Millisecond)
+	fillOrder(om, order)
+
+	got, _ := om.GetOrder(order.ID)
+	check("Order keeps the strategy's signal time", err == nil && got.SignalAt.Equal(signalAt))
+	check("Stages are stamped in order", !got.SentAt.Before(got.SignalAt) &&
+		got.AckedAt.Sub(got.SentAt) >= 20*time.Millisecond && got.FilledAt.Sub(got.AckedAt) >= 10*time.Millisecond)
+}
+
+func testExecutionStatsAggregate() {
+	om, cleanup := newSlowOrderManager(5 * time.Millisecond)
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		order, _ := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+		fillOrder(om, order)
+	}
+	// Not filled, so not measured
+	om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+
+	stats := om.GetExecutionStats()
+	check("Every stage has a sample per filled order", stats.SignalToSubmit.Count == 3 && stats.SubmitToAck.Count == 3 &&
+		stats.AckToFill.Count == 3 && stats.SignalToFill.Count == 3)
+	check("Percentiles are ordered", stats.SubmitToAck.P50 <= stats.SubmitToAck.P95 && stats.SubmitToAck.P95 <= stats.SubmitToAck.Max)
+	check("Submit to ack includes the response delay", stats.SubmitToAck.P50 >= 5*time.Millisecond)
+	check("End to end covers submit to ack", stats.SignalToFill.Max >= stats.SubmitToAck.Max)
+
+	om.Reset()
+	check("Reset clears the stats", om.GetExecutionStats().SignalToFill.Count == 0)
+}
+
+func testSimulatedOrdersNotMeasured() {
+	sim := execution.NewSimulatedExecutor()
+	sim.SetMarket("MESH6", 5000, "2026-01-05T15:00:00Z")
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500, EnableRiskChecks: true}}
+	om := execution.NewSimulatedOrderManager(sim, cfg, logger.NewLogger(10, logger.LevelWarn))
+
+	order, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Simulated order fills", err == nil && order.Status == models.StatusFilled)
+	check("Simulated fills are left out of the latency stats", om.GetExecutionStats().SignalToFill.Count == 0)
+}
+
+func testLatencyStatsString() {
+	s := execution.LatencyStats{Count: 4, P50: 12300 * time.Microsecond, P95: 40 * time.Millisecond, Max: 1234 * time.Millisecond}
+	check("Stats format with rounded durations", s.String() == "p50 12.3ms  p95 40ms  max 1.23s (n=4)")
+	check("Empty stats say so", strings.Contains(execution.LatencyStats{}.String(), "no samples"))
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunLiveInterlockTests executes all tests for environment parsing and the live
+// trading interlock
+func RunLiveInterlockTests() {
+	testParseEnvironment()
+	testLiveInterlockBlocksOrders()
+	testEngineLiveInterlock()
+}
+
+func testParseEnvironment() {
+	for _, s := range []string{"live", "demo"} {
+		env, err := config.ParseEnvironment(s)
+		check(fmt.Sprintf("Environment %q parses", s), err == nil && string(env) == s)
+	}
+	for _, s := range []string{"Live", "LIVE", " live", "demo ", "", "sim"} {
+		_, err := config.ParseEnvironment(s)
+		check(fmt.Sprintf("Environment %q is refused", s), err != nil)
+	}
+
+	check("Only live is live", config.EnvironmentLive.IsLive() && !config.EnvironmentDemo.IsLive() && !config.Environment("Live").IsLive())
+	check("Live and demo have their own URLs",
+		config.GetHTTPBaseURL(config.EnvironmentLive) == "https://live.tradovateapi.com" &&
+			config.GetHTTPBaseURL(config.EnvironmentDemo) == "https://demo.tradovateapi.com" &&
+			config.GetWSBaseURL(config.EnvironmentLive) == "wss://live.tradovateapi.com/v1/websocket" &&
+			config.GetMDWSBaseURL(config.EnvironmentDemo) == "wss://md-demo.tradovateapi.com/v1/websocket")
+	check("A bad environment has no URL instead of demo's",
+		config.GetHTTPBaseURL("Live") == "" && config.GetWSBaseURL("Live") == "" && config.GetMDWSBaseURL("Live") == "")
+	check("Only live uses the live token cache", auth.TokenCachePath("Live") == auth.TokenCachePath(config.EnvironmentDemo))
+}
+
+func testLiveInterlockBlocksOrders() {
+	var body map[string]interface{}
+	om, cleanup := capturePlaceOrder(&body)
+	defer cleanup()
+
+	om.RequireLiveConfirm()
+	check("Interlock reports pending", om.LiveConfirmPending())
+
+	_, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Market order waits for confirmation", errors.Is(err, execution.ErrLiveNotConfirmed))
+	_, err = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Type: models.TypeLimit, Price: 4990})
+	check("Limit order waits for confirmation", errors.Is(err, execution.ErrLiveNotConfirmed))
+	_, err = om.SubmitStopOrder("MESH6", models.SideSell, 1, 4980)
+	check("Stop order waits for confirmation", errors.Is(err, execution.ErrLiveNotConfirmed))
+	check("Unconfirmed orders never reach Tradovate", body == nil)
+
+	om.EngageKillSwitch()
+	_, err = om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("The kill switch is reported first", errors.Is(err, execution.ErrKillSwitch))
+	om.Arm()
+
+	order, err := om.Flatten("MESH6", models.SideSell, 1)
+	check("Flatten goes through before confirmation", err == nil && order.Status == models.StatusSubmitted)
+
+	om.ConfirmLive()
+	order, err = om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Orders are accepted once confirmed", err == nil && order.Status == models.StatusSubmitted && !om.LiveConfirmPending())
+}
+
+func testEngineLiveInterlock() {
+	quiet := logger.NewLogger(10, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	check("Confirming needs a live connection", e.ConfirmLive() != nil && !e.LiveConfirmed())
+
+	// Demo data is never the live account, whatever the config names
+	cfg := &config.Config{
+		Tradovate: config.TradovateConfig{Environment: config.EnvironmentLive},
+		Risk:      config.RiskConfig{DailyLossLimit: 500},
+	}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Disconnect()
+	check("Demo data is not the live account", !e.IsLiveAccount())
+	check("Demo data needs no confirmation", !e.LiveConfirmPending())
+	check("Demo data cannot be confirmed as live", e.ConfirmLive() != nil)
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// RunLoggerTests executes all tests for log export and subscriptions.
+func RunLoggerTests() {
+	testExportToJSON()
+	testSubscribeReceivesEntries()
+	testSlowSubscriberDoesNotBlock()
+	testChildLoggerForwardsToParent()
+	testSetMinLevel()
+	testParseLevel()
+	testEngineLogLevels()
+	testLoggingConfigValidation()
+	testEntryRangeAcrossTrims()
+}
+
+func testExportToJSON() {
+	l := logger.NewLogger(10, logger.LevelInfo)
+	l.Info("first")
+	l.Warnf("second %d", 2)
+
+	data, err := l.ExportToJSON()
+	check("JSON export succeeds", err == nil)
+
+	var entries []struct {
+		Timestamp string `json:"timestamp"`
+		Level     string `json:"level"`
+		Message   string `json:"message"`
+	}
+	json.Unmarshal(data, &entries)
+	check("JSON export has every entry", len(entries) == 2)
+	if len(entries) == 2 {
+		check("JSON entry carries level and message", entries[1].Level == "WARN" && entries[1].Message == "second 2")
+		check("JSON timestamp is set", entries[0].Timestamp != "")
+	}
+}
+
+func testSubscribeReceivesEntries() {
+	l := logger.NewLogger(10, logger.LevelInfo)
+	ch := make(chan logger.LogEntry, 4)
+	unsubscribe := l.Subscribe(ch)
+
+	l.Info("hello")
+	check("Subscriber receives new entry", len(ch) == 1 && (<-ch).Message == "hello")
+
+	unsubscribe()
+	l.Info("after")
+	check("Unsubscribed channel receives nothing", len(ch) == 0)
+}
+
+func testSlowSubscriberDoesNotBlock() {
+	l := logger.NewLogger(3, logger.LevelInfo)
+	ch := make(chan logger.LogEntry, 1)
+	l.Subscribe(ch)
+
+	for i := 0; i < 5; i++ {
+		l.Infof("entry %d", i)
+	}
+
+	check("Full subscriber drops entries instead of blocking", l.DroppedCount() == 4)
+	check("Ring buffer still truncates to max size", l.Count() == 3)
+}
+
+func testChildLoggerForwardsToParent() {
+	parent := logger.NewLogger(10, logger.LevelInfo)
+	first := logger.NewChildLogger(parent, 10, "[1] ")
+	second := logger.NewChildLogger(parent, 10, "[2] ")
+
+	first.Info("from one")
+	second.Warn("from two")
+	first.Debug("below parent level")
+
+	check("Child keeps only its own entries", first.Count() == 1 && second.Count() == 1)
+	entries := parent.GetEntries()
+	check("Parent receives every child entry with its prefix",
+		len(entries) == 2 && entries[0].Message == "[1] from one" && entries[1].Message == "[2] from two")
+}
+
+func testSetMinLevel() {
+	l := logger.NewLogger(1000, logger.LevelInfo)
+	l.Debug("hidden")
+	l.SetMinLevel(logger.LevelDebug)
+	l.Debug("shown")
+	check("Lowering the level keeps debug entries from then on", l.Count() == 1 && l.GetEntries()[0].Message == "shown")
+
+	l.SetMinLevel(logger.LevelError)
+	l.Warn("dropped")
+	check("Raising the level drops warnings", l.Count() == 1 && l.MinLevel() == logger.LevelError)
+
+	// Changing the level while other goroutines log must be safe (run with -race)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Infof("entry %d", j)
+			}
+		}()
+	}
+	for _, level := range []logger.LogLevel{logger.LevelDebug, logger.LevelWarn, logger.LevelInfo} {
+		l.SetMinLevel(level)
+	}
+	wg.Wait()
+	check("Level changes during logging leave the logger usable", l.MinLevel() == logger.LevelInfo)
+}
+
+func testParseLevel() {
+	level, err := logger.ParseLevel("debug")
+	check("ParseLevel accepts lower case", err == nil && level == logger.LevelDebug)
+	level, err = logger.ParseLevel("Warning")
+	check("ParseLevel accepts warning for warn", err == nil && level == logger.LevelWarn)
+	_, err = logger.ParseLevel("verbose")
+	check("ParseLevel rejects an unknown level", err != nil)
+}
+
+func testEngineLogLevels() {
+	mainLog := logger.NewLogger(10, logger.LevelInfo)
+	orderLog := logger.NewLogger(10, logger.LevelInfo)
+	strategyLog := logger.NewLogger(10, logger.LevelInfo)
+	e := app.NewEngine(mainLog, orderLog, strategyLog)
+	inst, err := e.AddStrategy("ma_crossover", nil)
+	if err != nil {
+		check("ma_crossover instance is added", false)
+		return
+	}
+
+	check("Strategy target covers instance logs", e.SetLogLevel("strategy", logger.LevelDebug) == nil &&
+		strategyLog.MinLevel() == logger.LevelDebug && inst.Log.MinLevel() == logger.LevelDebug)
+	check("Unknown target is refused", e.SetLogLevel("ws", logger.LevelDebug) != nil)
+
+	err = e.ApplyLogging(config.LoggingConfig{Order: "warn"})
+	check("Configured levels are applied and empty ones left alone", err == nil &&
+		orderLog.MinLevel() == logger.LevelWarn && mainLog.MinLevel() == logger.LevelInfo && strategyLog.MinLevel() == logger.LevelDebug)
+	level, _ := e.LogLevel("order")
+	check("LogLevel reports the current level", level == logger.LevelWarn)
+}
+
+func testLoggingConfigValidation() {
+	_, err := config.ParseConfig([]byte(`{"logging":{"main":"debug","order":"WARN"}}`))
+	check("Valid logging section parses", err == nil)
+	_, err = config.ParseConfig([]byte(`{"logging":{"strategy":"loud"}}`))
+	check("Unknown level in config is refused", err != nil)
+}
+
+func testEntryRangeAcrossTrims() {
+	l := logger.NewLogger(5, logger.LevelInfo)
+	for i := 0; i < 3; i++ {
+		l.Infof("line %d", i)
+	}
+	check("Nothing trimmed yet", l.FirstIndex() == 0)
+
+	// A selection of lines 1-2, made before more entries arrive
+	from, to := l.FirstIndex()+2, l.FirstIndex()+1
+	for i := 3; i < 7; i++ {
+		l.Infof("line %d", i)
+	}
+	check("Trimmed entries advance the first index", l.FirstIndex() == 2 && l.Count() == 5)
+
+	got := l.EntryRange(from, to)
+	check("Range is clamped to the kept entries", len(got) == 1 && got[0].Message == "line 2")
+	got = l.EntryRange(3, 5)
+	check("Range keeps addressing the same entries", len(got) == 3 && got[0].Message == "line 3" && got[2].Message == "line 5")
+	check("Range past the newest entry is clamped", len(l.EntryRange(5, 50)) == 2)
+
+	line := logger.FormatEntry(got[0])
+	check("Formatted entry keeps timestamp and level",
+		strings.HasPrefix(line, "["+got[0].Timestamp.Format("2006-01-02 15:04:05")+"] INFO  line 3"))
+	check("Export uses the same format", strings.Contains(l.ExportToString(), line+"\n"))
+
+	l.Clear()
+	check("Cleared entries advance the first index", l.FirstIndex() == 7 && len(l.EntryRange(2, 6)) == 0)
+	l.Info("after clear")
+	got = l.EntryRange(7, 7)
+	check("Entries after a clear are addressed past it", len(got) == 1 && got[0].Message == "after clear")
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/risk"
+)
+
+// RunLossStreakTests executes all tests for the consecutive loss circuit breaker.
+func RunLossStreakTests() {
+	testLossStreakCounting()
+	testLossStreakBreaker()
+	testLossStreakReset()
+}
+
+// lossStreakRig is a risk manager fed by a trade ledger with a fixed clock.
+// trade closes a 1 lot MESH6 round trip for points ($5 a point, no fees).
+type lossStreakRig struct {
+	rm    *risk.RiskManager
+	now   *time.Time
+	trade func(points float64)
+}
+
+func newLossStreakRig(maxLosses, cooldownMinutes int) lossStreakRig {
+	cfg := &config.Config{Risk: config.RiskConfig{
+		MaxContracts:              10,
+		DailyLossLimit:            10000,
+		EnableRiskChecks:          true,
+		MaxConsecutiveLosses:      maxLosses,
+		LossStreakCooldownMinutes: cooldownMinutes,
+	}}
+	pt, trading := newLedgerTracker(`{"users":[{"id":1}],"positions":[{"id":7,"accountId":1,"contractId":100,"netPos":0}]}`)
+	om := execution.NewSimulatedOrderManager(execution.NewSimulatedExecutor(), cfg, logger.NewLogger(10, logger.LevelDebug))
+	om.SetPortfolioTracker(pt)
+
+	chicago, _ := time.LoadLocation("America/Chicago")
+	now := time.Date(2026, 1, 5, 10, 0, 0, 0, chicago)
+	rm := om.GetRiskManager()
+	rm.SetClock(func() time.Time { return now })
+
+	id := 0
+	return lossStreakRig{rm: rm, now: &now, trade: func(points float64) {
+		id++
+		trading.HandleEvent("props", []byte(fmt.Sprintf(
+			`{"entityType":"fillPair","entity":{"id":%d,"positionId":7,"buyFillId":%d,"sellFillId":%d,"qty":1,"buyPrice":5000,"sellPrice":%.2f}}`,
+			id, 1000+id, 2000+id, 5000+points)))
+	}}
+}
+
+func testLossStreakCounting() {
+	rig := newLossStreakRig(3, 30)
+	rig.trade(-2)
+	rig.trade(-1)
+	check("Two losses do not trip a limit of three", !rig.rm.UpdateLossStreak())
+	check("Losing trades are counted", rig.rm.GetLossStreak() == 2)
+
+	rig.trade(0)
+	rig.rm.UpdateLossStreak()
+	check("A scratch trade leaves the streak", rig.rm.GetLossStreak() == 2)
+
+	rig.trade(3)
+	rig.rm.UpdateLossStreak()
+	check("A winning trade resets the streak", rig.rm.GetLossStreak() == 0)
+
+	rig.rm.UpdateLossStreak()
+	check("Trades already seen are not counted again", rig.rm.GetLossStreak() == 0)
+}
+
+func testLossStreakBreaker() {
+	rig := newLossStreakRig(3, 30)
+	for i := 0; i < 3; i++ {
+		rig.trade(-1)
+	}
+	check("Third loss in a row trips the breaker", rig.rm.UpdateLossStreak())
+	check("Breaker trips once", !rig.rm.UpdateLossStreak())
+
+	tripped, until := rig.rm.LossBreaker()
+	check("Breaker reports tripped", tripped)
+	check("Breaker cooldown ends after the configured minutes", until.Equal(rig.now.Add(30*time.Minute)))
+
+	strategyOrder := &models.Order{Symbol: "MESH6", Side: models.SideBuy, Quantity: 1, Origin: "sma-1"}
+	manualOrder := &models.Order{Symbol: "MESH6", Side: models.SideBuy, Quantity: 1, Origin: models.OriginManual}
+	check("Strategy orders are refused while tripped",
+		errors.Is(rig.rm.CheckOrderRisk(strategyOrder, nil, 0), risk.ErrLossStreak))
+	check("Manual orders still go through", rig.rm.CheckOrderRisk(manualOrder, nil, 0) == nil)
+
+	*rig.now = rig.now.Add(31 * time.Minute)
+	tripped, _ = rig.rm.LossBreaker()
+	check("Breaker resets when the cooldown expires", !tripped && rig.rm.GetLossStreak() == 0)
+	check("Strategy orders are accepted after the cooldown", rig.rm.CheckOrderRisk(strategyOrder, nil, 0) == nil)
+}
+
+func testLossStreakReset() {
+	rig := newLossStreakRig(2, 0)
+	rig.trade(-1)
+	rig.trade(-1)
+	check("Breaker trips at the configured limit", rig.rm.UpdateLossStreak())
+
+	*rig.now = rig.now.Add(8 * time.Hour)
+	tripped, until := rig.rm.LossBreaker()
+	check("Without a cooldown the breaker waits for a reset", tripped && until.IsZero())
+
+	check("Reset releases a tripped breaker", rig.rm.ResetLossBreaker(":arm"))
+	tripped, _ = rig.rm.LossBreaker()
+	check("Reset clears the breaker and the streak", !tripped && rig.rm.GetLossStreak() == 0)
+	check("Reset reports when there was nothing to release", !rig.rm.ResetLossBreaker(":arm"))
+
+	off := newLossStreakRig(0, 0)
+	for i := 0; i < 5; i++ {
+		off.trade(-1)
+	}
+	check("A limit of 0 never trips", !off.rm.UpdateLossStreak())
+	check("Streak is still counted with the breaker off", off.rm.GetLossStreak() == 5)
+}
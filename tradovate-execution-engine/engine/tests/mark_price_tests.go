@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunMarkPriceTests executes all tests for marking open positions to the bid, offer or midpoint.
+func RunMarkPriceTests() {
+	testMarkLongToBid()
+	testMarkShortToOffer()
+	testMarkFallsBackToTrade()
+	testMarkToMidpoint()
+}
+
+// newMarkTracker returns a tracker holding netPos MESH6 at 5000 ($5 a point)
+// and a func that sends it a quote. Entries at price 0 are left out.
+func newMarkTracker(netPos int) (*portfolio.PortfolioTracker, func(bid, offer, trade float64)) {
+	trading := tradovate.NewDataSubscriptionManager(nullSender{})
+	md := tradovate.NewDataSubscriptionManager(nullSender{})
+	pt := portfolio.NewPortfolioTracker(trading, md, 1, 0, logger.NewLogger(50, logger.LevelDebug))
+	pt.Start("demo")
+	trading.HandleEvent("user/syncrequest", json.RawMessage(fmt.Sprintf(`{"users":[{"id":1}],
+		"positions":[{"id":7,"accountId":1,"contractId":100,"netPos":%d,"netPrice":5000}],
+		"contracts":[{"id":100,"name":"MESH6"}],
+		"products":[{"name":"MES","valuePerPoint":5}]}`, netPos)))
+	quote := func(bid, offer, trade float64) {
+		var entries []string
+		for name, price := range map[string]float64{"Bid": bid, "Offer": offer, "Trade": trade} {
+			if price != 0 {
+				entries = append(entries, fmt.Sprintf(`%q:{"price":%g,"size":1}`, name, price))
+			}
+		}
+		md.HandleEvent(marketdata.EventMarketData, json.RawMessage(fmt.Sprintf(
+			`{"quotes":[{"contractId":100,"entries":{%s}}]}`, strings.Join(entries, ","))))
+	}
+	return pt, quote
+}
+
+func testMarkLongToBid() {
+	pt, quote := newMarkTracker(2)
+	quote(5004, 5005, 5006)
+	pos := pt.GetPLSummary()["MESH6"]
+	check("A long is marked to the bid", pos.LastPrice == 5004 && pos.Mark == portfolio.MarkBid)
+	check("A long's PnL is at the bid, not the last trade", pos.PL == 40)
+
+	report := portfolio.BuildSessionReport(portfolio.SessionSnapshot{Positions: pt.GetPLSummary()}, nil, nil)
+	check("Session report says which price the position is marked at",
+		len(report.Symbols) == 1 && report.Symbols[0].Mark == portfolio.MarkBid &&
+			strings.Contains(report.Text(), "marked 5004.00 (bid)"))
+}
+
+func testMarkShortToOffer() {
+	pt, quote := newMarkTracker(-2)
+	quote(4994, 4995, 4993)
+	pos := pt.GetPLSummary()["MESH6"]
+	check("A short is marked to the offer", pos.LastPrice == 4995 && pos.Mark == portfolio.MarkOffer)
+	check("A short's PnL is at the offer", pos.PL == 50)
+}
+
+func testMarkFallsBackToTrade() {
+	pt, quote := newMarkTracker(2)
+	quote(0, 5005, 5003)
+	pos := pt.GetPLSummary()["MESH6"]
+	check("A long without a bid is marked to the last trade", pos.LastPrice == 5003 && pos.Mark == portfolio.MarkTrade && pos.PL == 30)
+
+	quote(0, 0, 0)
+	check("A quote with no usable price leaves the mark", pt.GetPLSummary()["MESH6"].LastPrice == 5003)
+}
+
+func testMarkToMidpoint() {
+	pt, quote := newMarkTracker(2)
+	pt.SetMarkToMidpoint(true)
+	quote(5004, 5005, 5010)
+	pos := pt.GetPLSummary()["MESH6"]
+	check("MarkToMidpoint marks to the bid/offer midpoint", pos.LastPrice == 5004.5 && pos.Mark == portfolio.MarkMid && pos.PL == 45)
+
+	pt, quote = newMarkTracker(-2)
+	pt.SetMarkToMidpoint(true)
+	quote(5004, 0, 5010)
+	pos = pt.GetPLSummary()["MESH6"]
+	check("MarkToMidpoint without both sides uses the last trade", pos.Mark == portfolio.MarkTrade && pos.PL == -100)
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/strategies"
+)
+
+// RunMetricsRecorderTests executes all tests for the strategy metrics CSV
+func RunMetricsRecorderTests() {
+	testMetricsRecorderRows()
+	testMetricsRecorderNewColumns()
+	testMetricsRecorderAppends()
+	testLastSignal()
+	testEngineMetricsToggle()
+}
+
+// metricsDay is the date metrics test files are named after
+var metricsDay = time.Date(2026, 3, 11, 9, 30, 0, 0, time.UTC)
+
+// readMetrics returns the lines of a metrics CSV
+func readMetrics(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func testMetricsRecorderRows() {
+	dir, err := os.MkdirTemp("", "metrics")
+	if err != nil {
+		check(fmt.Sprintf("Temp dir (Error: %v)", err), false)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	rec, err := execution.NewMetricsRecorder(dir, "ma_crossover-1", metricsDay)
+	if err != nil {
+		check(fmt.Sprintf("Recorder opens (Error: %v)", err), false)
+		return
+	}
+	check("File is named after the strategy and date", rec.Path() == filepath.Join(dir, "ma_crossover-1_2026-03-11.csv"))
+
+	rec.Record(execution.MetricsRow{Timestamp: "2026-03-11T14:30:00Z", Metrics: map[string]float64{"Slow SMA": 5001.5, "Fast SMA": 5002.25}})
+	rec.Record(execution.MetricsRow{Timestamp: "2026-03-11T14:31:00Z", Metrics: map[string]float64{"Slow SMA": 5001.75, "Fast SMA": 5000}, Position: -1, Signal: "Short"})
+	check("Rows are buffered until flushed", len(readMetrics(rec.Path())) == 1 && readMetrics(rec.Path())[0] == "")
+	check("Rows are counted", rec.Rows() == 2)
+
+	check("Recorder closes cleanly", rec.Close() == nil)
+	lines := readMetrics(rec.Path())
+	check("Header is derived from the metric names", len(lines) == 3 && lines[0] == "timestamp,Fast SMA,Slow SMA,position,last_signal")
+	check("Rows carry metrics, position and signal", len(lines) == 3 &&
+		lines[1] == "2026-03-11T14:30:00Z,5002.25,5001.5,0," && lines[2] == "2026-03-11T14:31:00Z,5000,5001.75,-1,Short")
+	check("Closed recorder refuses rows", rec.Record(execution.MetricsRow{Timestamp: "x"}) != nil)
+}
+
+func testMetricsRecorderNewColumns() {
+	dir, err := os.MkdirTemp("", "metrics")
+	if err != nil {
+		check(fmt.Sprintf("Temp dir (Error: %v)", err), false)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	rec, err := execution.NewMetricsRecorder(dir, "donchian_breakout-2", metricsDay)
+	if err != nil {
+		check(fmt.Sprintf("Recorder opens (Error: %v)", err), false)
+		return
+	}
+	// Metrics appear once the strategy has warmed up
+	rec.Record(execution.MetricsRow{Timestamp: "T1"})
+	rec.Record(execution.MetricsRow{Timestamp: "T2", Metrics: map[string]float64{"Upper Channel": 5010, "Lower Channel": 4990}, Position: 1, Signal: "Long"})
+	rec.Record(execution.MetricsRow{Timestamp: "T3", Metrics: map[string]float64{"Upper Channel": 5011}})
+	rec.Close()
+
+	lines := readMetrics(rec.Path())
+	check("Metrics first seen later add columns", len(lines) == 4 && lines[0] == "timestamp,Lower Channel,Upper Channel,position,last_signal")
+	check("Earlier rows are blank in new columns", len(lines) == 4 && lines[1] == "T1,,,0,")
+	check("Later rows fill them", len(lines) == 4 && lines[2] == "T2,4990,5010,1,Long" && lines[3] == "T3,,5011,0,")
+}
+
+func testMetricsRecorderAppends() {
+	dir, err := os.MkdirTemp("", "metrics")
+	if err != nil {
+		check(fmt.Sprintf("Temp dir (Error: %v)", err), false)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	first, _ := execution.NewMetricsRecorder(dir, "ma_crossover-1", metricsDay)
+	first.Record(execution.MetricsRow{Timestamp: "T1", Metrics: map[string]float64{"Fast SMA": 1}})
+	first.Close()
+
+	second, err := execution.NewMetricsRecorder(dir, "ma_crossover-1", metricsDay)
+	if err != nil {
+		check(fmt.Sprintf("Recorder reopens (Error: %v)", err), false)
+		return
+	}
+	second.Record(execution.MetricsRow{Timestamp: "T2", Metrics: map[string]float64{"Fast SMA": 2}})
+	second.Close()
+	lines := readMetrics(second.Path())
+	check("A restart the same day appends under the same header", len(lines) == 3 &&
+		lines[0] == "timestamp,Fast SMA,position,last_signal" && lines[2] == "T2,2,0,")
+
+	os.WriteFile(filepath.Join(dir, "other_2026-03-11.csv"), []byte("a,b\n1,2\n"), 0o600)
+	_, err = execution.NewMetricsRecorder(dir, "other", metricsDay)
+	check("A file that is not a metrics CSV is left alone", err != nil)
+}
+
+func testLastSignal() {
+	strategy, sim, err := newSimulatedCrossover(config.RiskConfig{MaxContracts: 2, DailyLossLimit: 500, EnableRiskChecks: true}, "1")
+	if err != nil {
+		check("Simulated strategy initializes", false)
+		return
+	}
+	var reporter execution.SignalReporter = strategy
+	check("No signal before the first cross", reporter.LastSignal() == "")
+	feedBars(strategy, sim, 10, 10, 10, 10, 10, 11)
+	check("Last signal follows a cross above", strategy.LastSignal() == "Long")
+	feedBars(strategy, sim, 14, 16, 14, 12)
+	check("Last signal follows a cross below", strategy.LastSignal() == "Short")
+	strategy.Reset()
+	check("Reset clears the last signal", strategy.LastSignal() == "")
+
+	var _ execution.SignalReporter = strategies.NewDonchianBreakout("MESH6", 3, true)
+}
+
+func testEngineMetricsToggle() {
+	quiet := logger.NewLogger(10, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	check("Metrics recording starts off", !e.MetricsRecording())
+	e.SetMetricsRecording(true)
+	check("Metrics recording turns on", e.MetricsRecording())
+	e.SetMetricsRecording(false)
+	check("Metrics recording turns off", !e.MetricsRecording())
+
+	cfg := &config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}, Recording: config.RecordingConfig{Metrics: true}}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	check("recording.metrics turns it on when connecting", e.MetricsRecording())
+	e.SetMetricsRecording(false)
+	e.Disconnect()
+}
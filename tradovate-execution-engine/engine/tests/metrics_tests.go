@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/metrics"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunMetricsTests executes all tests for the metrics registry and endpoint.
+func RunMetricsTests() {
+	testMetricsTextFormat()
+	testMetricsEndpointLifecycle()
+	testOrderMetrics()
+	testMessageMetrics()
+}
+
+func testMetricsTextFormat() {
+	r := metrics.NewRegistry()
+	r.Counter("test_events_total", "Events seen", "kind", "b").Inc()
+	c := r.Counter("test_events_total", "Events seen", "kind", "a")
+	c.Inc()
+	c.Inc()
+	r.GaugeFunc("test_level", "Current level", func() float64 { return -12.5 })
+
+	var sb strings.Builder
+	r.WriteText(&sb)
+	want := "# HELP test_events_total Events seen\n" +
+		"# TYPE test_events_total counter\n" +
+		"test_events_total{kind=\"a\"} 2\n" +
+		"test_events_total{kind=\"b\"} 1\n" +
+		"# HELP test_level Current level\n" +
+		"# TYPE test_level gauge\n" +
+		"test_level -12.5\n"
+	check("Registry renders sorted Prometheus text", sb.String() == want)
+
+	check("Counter is shared by name and labels", r.Counter("test_events_total", "", "kind", "a").Value() == 2)
+
+	r.Unregister("test_level")
+	sb.Reset()
+	r.WriteText(&sb)
+	check("Unregistered gauge is not rendered", !strings.Contains(sb.String(), "test_level"))
+}
+
+func testMetricsEndpointLifecycle() {
+	r := metrics.NewRegistry()
+	r.Counter("test_scrapes_total", "Scrapes").Inc()
+
+	srv, err := metrics.Serve("127.0.0.1:0", r)
+	if err != nil {
+		check("Metrics listener starts", false)
+		return
+	}
+	url := fmt.Sprintf("http://%s/metrics", srv.Addr())
+
+	body := ""
+	if resp, err := http.Get(url); err == nil {
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		body = string(data)
+	}
+	check("Endpoint serves the registry", strings.Contains(body, "test_scrapes_total 1"))
+
+	_, err = metrics.Serve(srv.Addr(), r)
+	check("Address in use is reported on start", err != nil)
+
+	check("Listener closes cleanly", srv.Close(time.Second) == nil)
+	client := http.Client{Timeout: time.Second}
+	_, err = client.Get(url)
+	check("Closed listener refuses scrapes", err != nil)
+}
+
+func testOrderMetrics() {
+	submitted := metrics.Default.Counter("engine_orders_submitted_total", "")
+	filled := metrics.Default.Counter("engine_orders_filled_total", "")
+	rejected := metrics.Default.Counter("engine_orders_rejected_total", "")
+	baseSubmitted, baseFilled, baseRejected := submitted.Value(), filled.Value(), rejected.Value()
+
+	log := logger.NewLogger(10, logger.LevelInfo)
+	nextID := 9300
+	om, cleanup := newHTTPOrderManager(orderErrorTestConfig(), log, func(_ *execution.OrderManager, w http.ResponseWriter) {
+		nextID++
+		if nextID == 9302 {
+			fmt.Fprint(w, sampleMarginFailure)
+			return
+		}
+		fmt.Fprintf(w, `{"orderId":%d}`, nextID)
+	})
+	defer cleanup()
+
+	om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	om.HandleOrderEvent(json.RawMessage(`{"id":9301,"ordStatus":"Filled"}`))
+	om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+
+	check("Accepted order counts as submitted", submitted.Value()-baseSubmitted == 1)
+	check("Exchange fill is counted", filled.Value()-baseFilled == 1)
+	check("Rejected order is counted", rejected.Value()-baseRejected == 1)
+
+	// Backtests and paper trading do not move the live counters
+	sim := execution.NewSimulatedExecutor()
+	sim.SetMarket("MESH6", 5000, "2026-01-05T15:00:00Z")
+	simOM := execution.NewSimulatedOrderManager(sim, orderErrorTestConfig(), log)
+	simOM.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Simulated fills are not counted", filled.Value()-baseFilled == 1)
+}
+
+func testMessageMetrics() {
+	messages := metrics.Default.Counter("engine_ws_messages_received_total", "", "event", "props")
+	before := messages.Value()
+
+	ds := tradovate.NewDataSubscriptionManager(&nullSender{})
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"unknown"}`))
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"unknown"}`))
+	check("Received messages are counted by event type", messages.Value()-before == 2)
+}
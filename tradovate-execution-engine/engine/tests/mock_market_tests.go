@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/marketdata/mock"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunMockMarketTests executes all tests for the synthetic market data feed.
+func RunMockMarketTests() {
+	testMockMarketQuotes()
+	testMockMarketHistory()
+	testMockFeedHistory()
+	testMockFeedQuotes()
+	testMockAccountFills()
+	testEngineConnectDemo()
+}
+
+// mockConfig is a fast, seeded market for tests
+func mockConfig() mock.Config {
+	cfg := mock.DefaultConfig()
+	cfg.Seed = 42
+	cfg.Interval = 5 * time.Millisecond
+	cfg.Latency = time.Millisecond
+	return cfg
+}
+
+// barTime parses the timestamp of a chart bar
+func barTime(bar marketdata.Bar) time.Time {
+	t, _ := time.Parse(time.RFC3339, bar.Timestamp)
+	return t
+}
+
+func testMockMarketQuotes() {
+	a, b := mock.NewMarket(mockConfig()), mock.NewMarket(mockConfig())
+	same := true
+	for i := 0; i < 20; i++ {
+		if a.Next("MESH6").Entries["Trade"].Price != b.Next("MESH6").Entries["Trade"].Price {
+			same = false
+		}
+	}
+	check("Same seed gives the same prices", same)
+
+	inst := a.Instrument("MESH6")
+	check("Instrument takes its product from the symbol", inst.Product == "MES" && inst.TickSize == 0.25 && inst.ValuePerPoint == 5)
+	symbol, ok := a.Symbol(inst.ContractID)
+	check("Contract ID maps back to the symbol", ok && symbol == "MESH6")
+
+	valid, rising := true, true
+	var volume float64
+	for i := 0; i < 200; i++ {
+		q := a.Next("MESH6")
+		bid, offer, trade := q.Entries["Bid"].Price, q.Entries["Offer"].Price, q.Entries["Trade"].Price
+		if bid >= offer || trade < bid || trade > offer || math.Mod(trade, inst.TickSize) != 0 {
+			valid = false
+		}
+		if q.ContractID != inst.ContractID {
+			valid = false
+		}
+		total := q.Entries["TotalTradeVolume"].Size
+		if total <= volume {
+			rising = false
+		}
+		volume = total
+	}
+	check("Quotes have bid below offer and trades on the tick", valid)
+	check("Total trade volume rises", rising)
+}
+
+func testMockMarketHistory() {
+	market := mock.NewMarket(mockConfig())
+	now := time.Date(2026, 1, 5, 15, 30, 20, 0, time.UTC)
+	market.SetClock(func() time.Time { return now })
+	desc := marketdata.ChartDesc{UnderlyingType: "MinuteBar", ElementSize: 1, ElementSizeUnit: "UnderlyingUnits"}
+
+	bars := market.History("MESH6", desc, now, 30)
+	check("History returns the bars asked for", len(bars) == 30)
+	if len(bars) != 30 {
+		return
+	}
+	spaced, shaped := true, true
+	for i, bar := range bars {
+		if i > 0 && !barTime(bar).Equal(barTime(bars[i-1]).Add(time.Minute)) {
+			spaced = false
+		}
+		if bar.High < math.Max(bar.Open, bar.Close) || bar.Low > math.Min(bar.Open, bar.Close) {
+			shaped = false
+		}
+	}
+	check("History bars are one minute apart", spaced)
+	check("History bars contain their open and close", shaped)
+	check("Last bar is the current minute", barTime(bars[29]).Equal(now.Truncate(time.Minute)))
+	check("Last bar closes at the current price", bars[29].Close == market.Price("MESH6"))
+
+	older := market.History("MESH6", desc, barTime(bars[10]), 20)
+	check("Page before an earlier bar ends on it",
+		len(older) == 20 && older[19].Timestamp == bars[10].Timestamp && older[19].Close == bars[10].Close)
+}
+
+func testMockFeedHistory() {
+	market := mock.NewMarket(mockConfig())
+	feed := mock.NewFeed(market)
+	defer feed.Close()
+	ds := tradovate.NewDataSubscriptionManager(feed)
+	feed.SetMessageHandler(ds.HandleEvent)
+	feed.SetResponseHandler(ds.HandleResponse)
+	feed.SetRequestErrorHandler(ds.HandleRequestError)
+	ds.Connect()
+
+	bars, err := ds.LoadHistory(context.Background(), tradovate.HistoryRequest{
+		Params:       testChartParams(),
+		Bars:         120,
+		PageSize:     50,
+		StallTimeout: time.Second,
+	})
+	check("History loads through the feed in pages", err == nil && len(bars) == 120)
+	increasing := len(bars) > 0
+	for i := 1; i < len(bars); i++ {
+		if !barTime(bars[i]).After(barTime(bars[i-1])) {
+			increasing = false
+		}
+	}
+	check("Pages are stitched without repeats", increasing)
+
+	params := testChartParams()
+	params.Symbol = ""
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var chartErr *tradovate.ChartError
+	check("Chart request without a symbol is refused",
+		errors.As(ds.GetChartAndWait(ctx, params), &chartErr) && chartErr.Status == 404)
+}
+
+func testMockFeedQuotes() {
+	market := mock.NewMarket(mockConfig())
+	feed := mock.NewFeed(market)
+	defer feed.Close()
+	ds := tradovate.NewDataSubscriptionManager(feed)
+	feed.SetMessageHandler(ds.HandleEvent)
+	feed.SetResponseHandler(ds.HandleResponse)
+	ds.Connect()
+
+	var mu sync.Mutex
+	var quotes []marketdata.Quote
+	ds.AddQuoteHandler(func(q marketdata.Quote) {
+		mu.Lock()
+		quotes = append(quotes, q)
+		mu.Unlock()
+	})
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(quotes)
+	}
+
+	check("Subscribing starts the quote stream", ds.SubscribeQuote("MESH6") == nil && waitFor(func() bool { return count() >= 3 }))
+	mu.Lock()
+	first := quotes[0]
+	mu.Unlock()
+	check("Quotes carry the contract and a trade", first.ContractID == market.Instrument("MESH6").ContractID && first.Entries["Trade"].Price > 0)
+
+	ds.UnsubscribeQuote("MESH6")
+	time.Sleep(20 * time.Millisecond)
+	stopped := count()
+	time.Sleep(30 * time.Millisecond)
+	check("Unsubscribing stops the quote stream", count() == stopped)
+}
+
+func testMockAccountFills() {
+	market := mock.NewMarket(mockConfig())
+	account := mock.NewAccount(market, 1, 1, 50000)
+	defer account.Close()
+	feed := mock.NewFeed(market)
+	defer feed.Close()
+
+	trading := tradovate.NewDataSubscriptionManager(account)
+	md := tradovate.NewDataSubscriptionManager(feed)
+	account.SetMessageHandler(trading.HandleEvent)
+	trading.Connect()
+	md.Connect()
+	pt := portfolio.NewPortfolioTracker(trading, md, 1, 1, logger.NewLogger(10, logger.LevelWarn))
+	trading.OnFillUpdate = pt.RecordFill
+	check("Tracker starts on the synthetic account", pt.Start("demo") == nil)
+
+	at := time.Now().UTC()
+	account.Fill("MESH6", "Buy", 2, 5000, at)
+	account.Fill("MESH6", "Sell", 1, 5002, at.Add(time.Minute))
+	account.Fill("MESH6", "Sell", 1, 4999, at.Add(2*time.Minute))
+
+	check("Fills reach the tracker as closed trades", waitFor(func() bool { return len(pt.GetClosedTrades()) == 2 }))
+	var winner, loser bool
+	var total float64
+	for _, t := range pt.GetClosedTrades() {
+		winner = winner || (t.ExitPrice == 5002 && t.PnL == 10)
+		loser = loser || (t.ExitPrice == 4999 && t.PnL == -5)
+		total += t.PnL
+	}
+	check("Lots are matched first in first out", winner && loser)
+	assertEqualsFloat("Closed trades add up to the account's realized PnL", account.RealizedPnL(), total, 0.0001)
+	assertEqualsFloat("Account realized PnL", 5, account.RealizedPnL(), 0.0001)
+	check("Position is flat", waitFor(func() bool { return pt.GetPLSummary()["MESH6"].NetPos == 0 }))
+}
+
+func testEngineConnectDemo() {
+	quiet := logger.NewLogger(10, logger.LevelWarn)
+	e := app.NewEngine(quiet, quiet, quiet)
+	var connected bool
+	e.AddEventHandler(func(ev app.Event) {
+		if ev.Kind == app.EventConnected {
+			connected = true
+		}
+	})
+	// A loss limit, or the risk supervisor takes the flat start as a breach
+	cfg := &config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}}
+	check("Demo connect succeeds without credentials", e.ConnectDemo(cfg) == nil && connected)
+	defer e.Disconnect()
+
+	check("Quotes subscribe on the demo feed", e.MarketData().SubscribeQuote("MESH6") == nil)
+	// Orders are refused until the first quote gives the simulator a price
+	var order *models.Order
+	var err error
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if order, err = e.OrderManager().SubmitMarketOrder("MESH6", models.SideBuy, 1); err == nil {
+			break
+		}
+	}
+	check("Market order fills at the synthetic price", err == nil && order.Status == models.StatusFilled)
+
+	pt := e.Portfolio()
+	check("Fill reaches the portfolio as a position",
+		waitFor(func() bool { return pt.GetPLSummary()["MESH6"].NetPos == 1 }))
+	check("Flatten closes the demo position", e.OrderManager().FlattenPositions() == nil &&
+		waitFor(func() bool { return len(pt.GetClosedTrades()) == 1 }))
+	if trades := pt.GetClosedTrades(); len(trades) == 1 {
+		check("Demo trade is attributed to manual orders", trades[0].Origin == "manual")
+	}
+}
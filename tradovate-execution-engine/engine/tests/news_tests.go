@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/schedule"
+)
+
+// RunNewsTests executes all tests for the news calendar and lockout
+func RunNewsTests() {
+	testNewsCalendar()
+	testNewsCalendarErrors()
+	testNewsCalendarFile()
+	testNewsLockout()
+}
+
+// newsConfig is an enabled UTC calendar locking 5 minutes before each event
+func newsConfig(events ...config.NewsEvent) config.NewsConfig {
+	return config.NewsConfig{Enabled: true, LeadMinutes: 5, Timezone: "UTC", Events: events}
+}
+
+func utcAt(hour, minute int) time.Time {
+	return time.Date(2026, 3, 11, hour, minute, 0, 0, time.UTC)
+}
+
+func testNewsCalendar() {
+	cal, err := schedule.NewNewsCalendar(newsConfig(
+		config.NewsEvent{Name: "FOMC", Start: "2026-03-11 14:00", End: "2026-03-11 14:15"},
+		config.NewsEvent{Name: "CPI", Start: "2026-03-11 08:30", End: "2026-03-11 08:45"},
+	), "")
+	check("News calendar parses", err == nil && cal.Enabled())
+	if err != nil {
+		return
+	}
+
+	_, active := cal.Active(utcAt(8, 24))
+	check("No lockout before the lead time", !active)
+	w, active := cal.Active(utcAt(8, 25))
+	check("Lockout begins the lead time before the event", active && w.Name == "CPI" && w.End.Equal(utcAt(8, 45)))
+	_, active = cal.Active(utcAt(8, 45))
+	check("Lockout ends with the event", !active)
+	w, active = cal.Active(utcAt(14, 10))
+	check("Later event is found", active && w.Name == "FOMC")
+
+	next, ok := cal.Next(utcAt(9, 0))
+	check("Next returns the first lockout still to begin", ok && next.Name == "FOMC" && next.Lock.Equal(utcAt(13, 55)))
+	_, ok = cal.Next(utcAt(14, 0))
+	check("Next is empty after the last lockout began", !ok)
+	check("Window describes itself", next.String() == "FOMC 14:00-14:15 (locked from 13:55)")
+
+	merged, _ := schedule.NewNewsCalendar(newsConfig(
+		config.NewsEvent{Name: "NFP", Start: "2026-03-11 08:30", End: "2026-03-11 08:45"},
+		config.NewsEvent{Name: "ISM", Start: "2026-03-11 08:48", End: "2026-03-11 09:00"},
+	), "")
+	w, active = merged.Active(utcAt(8, 40))
+	check("Overlapping lockouts are joined", active && w.Name == "NFP" && w.End.Equal(utcAt(9, 0)))
+
+	off, err := schedule.NewNewsCalendar(config.NewsConfig{Events: []config.NewsEvent{{Name: "CPI", Start: "bad", End: "bad"}}}, "")
+	_, active = off.Active(utcAt(8, 30))
+	check("Disabled calendar has no lockouts", err == nil && !off.Enabled() && !active && off.String() == "none")
+
+	ny, err := schedule.NewNewsCalendar(config.NewsConfig{Enabled: true, Events: []config.NewsEvent{
+		{Name: "CPI", Start: "2026-03-11 08:30", End: "2026-03-11 08:45"},
+	}}, "")
+	w, _ = ny.Next(utcAt(0, 0))
+	check("Times default to New York", err == nil && w.Start.Equal(time.Date(2026, 3, 11, 12, 30, 0, 0, time.UTC)))
+}
+
+func testNewsCalendarErrors() {
+	cases := []struct {
+		name string
+		cfg  config.NewsConfig
+	}{
+		{"Bad start", newsConfig(config.NewsEvent{Name: "CPI", Start: "08:30", End: "2026-03-11 08:45"})},
+		{"Bad end", newsConfig(config.NewsEvent{Name: "CPI", Start: "2026-03-11 08:30", End: "tomorrow"})},
+		{"End before start", newsConfig(config.NewsEvent{Name: "CPI", Start: "2026-03-11 08:30", End: "2026-03-11 08:30"})},
+		{"Negative lead", config.NewsConfig{Enabled: true, LeadMinutes: -1}},
+		{"Unknown timezone", config.NewsConfig{Enabled: true, Timezone: "Mars/Olympus"}},
+		{"Missing calendar file", config.NewsConfig{Enabled: true, CalendarFile: "no_such_calendar.json"}},
+	}
+	for _, c := range cases {
+		_, err := schedule.NewNewsCalendar(c.cfg, os.TempDir())
+		check(fmt.Sprintf("News config refused: %s", c.name), err != nil)
+	}
+}
+
+func testNewsCalendarFile() {
+	dir, err := os.MkdirTemp("", "news")
+	if err != nil {
+		check(fmt.Sprintf("Temp dir (Error: %v)", err), false)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "news.json")
+	os.WriteFile(path, []byte(`[{"name":"PPI","start":"2026-03-11 08:30","end":"2026-03-11 08:40"}]`), 0o644)
+	cfg := newsConfig(config.NewsEvent{Name: "FOMC", Start: "2026-03-11 14:00", End: "2026-03-11 14:15"})
+	cfg.CalendarFile = "news.json"
+	cal, err := schedule.NewNewsCalendar(cfg, dir)
+	check("Calendar file is read next to the config", err == nil && cal.String() == "2 events, locked 5m0s before each, UTC")
+	if err == nil {
+		w, active := cal.Active(utcAt(8, 27))
+		check("Calendar file events lock out trading", active && w.Name == "PPI")
+	}
+
+	os.WriteFile(path, []byte(`{"name":"PPI"}`), 0o644)
+	_, err = schedule.NewNewsCalendar(cfg, dir)
+	check("Malformed calendar file is refused", err != nil && strings.Contains(err.Error(), "news.json"))
+}
+
+func testNewsLockout() {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, logger.NewLogger(100, logger.LevelWarn))
+	cfg := &config.Config{
+		Risk: config.RiskConfig{DailyLossLimit: 500},
+		News: newsConfig(config.NewsEvent{Name: "CPI", Start: "2026-03-11 08:30", End: "2026-03-11 08:45"}),
+	}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Disconnect()
+
+	var mu sync.Mutex
+	var events []string
+	e.AddEventHandler(func(ev app.Event) {
+		if ev.Kind == app.EventNewsLockout {
+			mu.Lock()
+			events = append(events, ev.Message)
+			mu.Unlock()
+		}
+	})
+	eventCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events)
+	}
+
+	// Bars too large to close during the test keep the strategy's own orders out of it
+	inst, err := e.AddStrategy("ma_crossover", map[string]string{"fast_length": "2", "slow_length": "3", "update_mode": "1",
+		"bar_type": "volume", "bar_size": "1000000"})
+	if err != nil {
+		check(fmt.Sprintf("Strategy is added (Error: %v)", err), false)
+		return
+	}
+	if err := e.StartStrategy(inst.ID); err != nil {
+		check(fmt.Sprintf("Strategy starts (Error: %v)", err), false)
+		return
+	}
+	waitFor(func() bool { return inst.Runtime.Status() == app.StrategyRunning })
+
+	e.MarketData().SubscribeQuote("MESH6")
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err = e.OrderManager().SubmitMarketOrder("MESH6", models.SideBuy, 1); err == nil {
+			break
+		}
+	}
+	check("Demo order fills", err == nil && waitFor(func() bool { return e.Portfolio().GetPLSummary()["MESH6"].NetPos == 1 }))
+
+	began, ended := e.CheckNews(utcAt(8, 20), true)
+	check("Nothing happens before the lockout", !began && !ended && !e.InNewsLockout())
+
+	began, _ = e.CheckNews(utcAt(8, 25), true)
+	window, held := e.NewsLockout()
+	check("Lockout begins the lead time before the event", began && held && window.Name == "CPI")
+	check("Lockout flattens open positions", waitFor(func() bool { return e.Portfolio().GetPLSummary()["MESH6"].NetPos == 0 }))
+	check("Running strategy is paused and logged", logged(inst.Log, "News lockout (CPI) - strategy paused until 08:45"))
+	check("Running strategy keeps running", inst.Runtime.Status() == app.StrategyRunning)
+	began, _ = e.CheckNews(utcAt(8, 30), true)
+	check("Lockout begins once", !began && e.InNewsLockout())
+
+	_, ended = e.CheckNews(utcAt(8, 45), true)
+	check("Lockout ends with the event", ended && !e.InNewsLockout())
+	check("Lockout start and end are reported", eventCount() == 2)
+
+	_, err = e.OverrideNewsLockout()
+	check("Override needs a lockout in force", err != nil)
+
+	// A calendar event that covers the real clock, so the override stays in force
+	now := time.Now().UTC()
+	cfg.News = newsConfig(config.NewsEvent{Name: "FOMC", Start: now.Format("2006-01-02 15:04"), End: now.Add(time.Hour).Format("2006-01-02 15:04")})
+	e.ApplyConfig(cfg)
+	began, _ = e.CheckNews(now, true)
+	check("Reloaded calendar locks out trading", began && e.InNewsLockout())
+
+	window, err = e.OverrideNewsLockout()
+	check("Override lifts the lockout", err == nil && window.Name == "FOMC" && !e.InNewsLockout())
+	began, _ = e.CheckNews(now.Add(time.Minute), true)
+	check("Overridden lockout stays lifted until it ends", !began && !e.InNewsLockout())
+	check("Override is reported", eventCount() == 4)
+
+	check("Strategy stops", e.StopStrategy(inst.ID) == nil)
+}
+
+// logged reports whether l holds an entry containing text
+func logged(l *logger.Logger, text string) bool {
+	for _, entry := range l.GetEntries() {
+		if strings.Contains(entry.Message, text) {
+			return true
+		}
+	}
+	return false
+}
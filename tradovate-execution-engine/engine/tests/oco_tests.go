@@ -0,0 +1,244 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunOCOTests executes all tests for one-cancels-other order pairs.
+func RunOCOTests() {
+	testOCOFillCancelsSibling()
+	testOCOCancelPair()
+	testOCOBothFillsFlatten()
+	testOCOFillAfterLocalCancel()
+	testOCOSecondLegRefused()
+	testOCOInvalidPrices()
+	testOCORestore()
+}
+
+// ocoExchange answers placeorder with a new order ID each time and records
+// cancelorder and modifyorder requests. Cancels are refused while rejectCancel is set, and the
+// placeorder after rejectPlaceAfter orders is refused. While hold is set, each request counts
+// itself in held and waits for hold to close.
+type ocoExchange struct {
+	mu               sync.Mutex
+	nextID           int
+	placed           []map[string]interface{}
+	cancelled        []string
+	modified         []map[string]interface{}
+	rejectCancel     bool
+	rejectPlaceAfter int
+	hold             chan struct{}
+	held             int
+}
+
+func newOCOOrderManager(log *logger.Logger) (*execution.OrderManager, *ocoExchange, func()) {
+	ex := &ocoExchange{nextID: 8000}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		ex.mu.Lock()
+		hold := ex.hold
+		if hold != nil {
+			ex.held++
+		}
+		ex.mu.Unlock()
+		if hold != nil {
+			<-hold
+		}
+		ex.mu.Lock()
+		defer ex.mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/order/placeorder":
+			if ex.rejectPlaceAfter > 0 && len(ex.placed) >= ex.rejectPlaceAfter {
+				fmt.Fprint(w, `{"failureReason":"RiskCheck","failureText":"refused"}`)
+				return
+			}
+			ex.placed = append(ex.placed, body)
+			ex.nextID++
+			fmt.Fprintf(w, `{"orderId":%d}`, ex.nextID)
+		case "/v1/order/cancelorder":
+			if ex.rejectCancel {
+				fmt.Fprint(w, `{"failureReason":"TooLate","failureText":"order already filled"}`)
+				return
+			}
+			ex.cancelled = append(ex.cancelled, fmt.Sprint(body["orderId"]))
+			fmt.Fprint(w, `{}`)
+		case "/v1/order/modifyorder":
+			ex.modified = append(ex.modified, body)
+			fmt.Fprint(w, `{}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 10, DailyLossLimit: 500, EnableRiskChecks: true}}
+	tm := auth.NewTokenManager(cfg)
+	tm.SetSessionForTest(srv.URL, "test-token", 1)
+	om := execution.NewOrderManager(tm, cfg, log)
+
+	return om, ex, srv.Close
+}
+
+// fillLeg delivers a Tradovate fill of qty for the given local order
+func fillLeg(om *execution.OrderManager, orderID string, fillID, qty int) {
+	order, ok := om.GetOrder(orderID)
+	if !ok {
+		return
+	}
+	om.HandleFillEvent(json.RawMessage(fmt.Sprintf(`{"id":%d,"orderId":%s,"qty":%d,"price":5000}`, fillID, order.ExternalID, qty)))
+}
+
+func orderStatus(om *execution.OrderManager, orderID string) models.OrderStatus {
+	order, ok := om.GetOrder(orderID)
+	if !ok {
+		return ""
+	}
+	om.Mu.RLock()
+	defer om.Mu.RUnlock()
+	return order.Status
+}
+
+// errorsLogged counts error entries containing text
+func errorsLogged(log *logger.Logger, text string) int {
+	n := 0
+	for _, e := range log.GetEntries() {
+		if e.Level == logger.LevelError && strings.Contains(e.Message, text) {
+			n++
+		}
+	}
+	return n
+}
+
+func testOCOFillCancelsSibling() {
+	om, ex, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelDebug))
+	defer cleanup()
+
+	id, err := om.SubmitOCOPair("MESH6", 2, 5010, 4990)
+	check(fmt.Sprintf("OCO pair is submitted (Error: %v)", err), err == nil && id != "")
+	pair, ok := om.GetOCOPair(id)
+	if !ok {
+		check("Pair is registered", false)
+		return
+	}
+	check("Both legs rest as stops", len(ex.placed) == 2 && ex.placed[0]["orderType"] == "Stop" && ex.placed[1]["action"] == "Sell")
+	check("Pair starts working", pair.State == execution.OCOWorking)
+
+	fillLeg(om, pair.BuyOrderID, 1, 1)
+	sell, _ := om.GetOrder(pair.SellOrderID)
+	check("A partial fill cancels the sibling at the exchange", len(ex.cancelled) == 1 && ex.cancelled[0] == sell.ExternalID)
+	check("Sibling is marked cancelled", orderStatus(om, pair.SellOrderID) == models.StatusCanceled)
+
+	fillLeg(om, pair.BuyOrderID, 2, 1)
+	pair, _ = om.GetOCOPair(id)
+	check("Pair records the leg that triggered", pair.State == execution.OCOTriggered && pair.FilledLeg == pair.BuyOrderID)
+	check("The rest of the fill sends no more cancels", len(ex.cancelled) == 1)
+}
+
+func testOCOCancelPair() {
+	om, ex, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelDebug))
+	defer cleanup()
+
+	id, _ := om.SubmitOCOPair("MESH6", 1, 5010, 4990)
+	err := om.CancelOCOPair(id)
+	pair, _ := om.GetOCOPair(id)
+	check(fmt.Sprintf("Cancelling the pair cancels both legs (Error: %v)", err), err == nil && len(ex.cancelled) == 2)
+	check("Pair is cancelled", pair.State == execution.OCOCanceled &&
+		orderStatus(om, pair.BuyOrderID) == models.StatusCanceled && orderStatus(om, pair.SellOrderID) == models.StatusCanceled)
+	check("Unknown pair is an error", om.CancelOCOPair("OCO-missing") != nil)
+}
+
+// testOCOBothFillsFlatten has both stops trigger in the same batch: the
+// sibling's cancel is refused because it already filled, then its fill arrives
+func testOCOBothFillsFlatten() {
+	log := logger.NewLogger(100, logger.LevelDebug)
+	om, ex, cleanup := newOCOOrderManager(log)
+	defer cleanup()
+
+	id, _ := om.SubmitOCOPair("MESH6", 2, 5010, 4990)
+	pair, _ := om.GetOCOPair(id)
+	ex.rejectCancel = true
+	fillLeg(om, pair.BuyOrderID, 1, 2)
+	check("Refused sibling cancel is logged", errorsLogged(log, "TooLate") > 0 || errorsLogged(log, "already filled") > 0)
+
+	ex.rejectCancel = false
+	fillLeg(om, pair.SellOrderID, 2, 1)
+	pair, _ = om.GetOCOPair(id)
+	check("Second fill marks the pair", pair.State == execution.OCOBothFills)
+	check("Second fill is logged loudly", errorsLogged(log, "BOTH LEGS FILLED") == 1)
+	check("Remainder of the second leg is cancelled", orderStatus(om, pair.SellOrderID) == models.StatusCanceled)
+
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	last := ex.placed[len(ex.placed)-1]
+	check("Net exposure is flattened with a market order", len(ex.placed) == 3 &&
+		last["orderType"] == "Market" && last["action"] == "Sell" && last["orderQty"] == 1.0)
+}
+
+// testOCOFillAfterLocalCancel has the sibling's cancel accepted although the
+// exchange had filled it first
+func testOCOFillAfterLocalCancel() {
+	log := logger.NewLogger(100, logger.LevelDebug)
+	om, ex, cleanup := newOCOOrderManager(log)
+	defer cleanup()
+
+	id, _ := om.SubmitOCOPair("MESH6", 1, 5010, 4990)
+	pair, _ := om.GetOCOPair(id)
+	fillLeg(om, pair.SellOrderID, 1, 1)
+	fillLeg(om, pair.BuyOrderID, 2, 1)
+
+	pair, _ = om.GetOCOPair(id)
+	check("Fill on a cancelled leg is caught", pair.State == execution.OCOBothFills && errorsLogged(log, "BOTH LEGS FILLED") == 1)
+	check("Offsetting fills need no flatten", len(ex.placed) == 2 && errorsLogged(log, "fills offset") == 1)
+}
+
+func testOCOSecondLegRefused() {
+	om, ex, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelDebug))
+	defer cleanup()
+	ex.rejectPlaceAfter = 1
+
+	_, err := om.SubmitOCOPair("MESH6", 1, 5010, 4990)
+	check("Refused sell stop fails the pair", err != nil && strings.Contains(err.Error(), "sell stop"))
+	check("Buy stop is cancelled when the sell stop is refused", len(ex.cancelled) == 1)
+	check("No pair is left behind", len(om.GetOCOPairs()) == 0)
+}
+
+func testOCOInvalidPrices() {
+	om, ex, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelDebug))
+	defer cleanup()
+
+	_, err := om.SubmitOCOPair("MESH6", 1, 4990, 5010)
+	check("Buy stop below the sell stop is refused", err != nil && len(ex.placed) == 0)
+}
+
+func testOCORestore() {
+	om, ex, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelDebug))
+	defer cleanup()
+
+	buy, _ := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Type: models.TypeStop, StopPrice: 5010})
+	sell, _ := om.SubmitOrder("MESH6", models.SideSell, 1, execution.OrderOptions{Type: models.TypeStop, StopPrice: 4990})
+	if buy == nil || sell == nil {
+		check("Legs are submitted", false)
+		return
+	}
+	saved, _ := json.Marshal(execution.OCOPair{ID: "OCO-saved", Symbol: "MESH6", Quantity: 1,
+		BuyOrderID: buy.ID, SellOrderID: sell.ID, State: execution.OCOWorking})
+
+	var pair execution.OCOPair
+	json.Unmarshal(saved, &pair)
+	check("Saved pair relinks", om.RestoreOCOPair(pair) == nil)
+	fillLeg(om, sell.ID, 1, 1)
+	check("Relinked pair cancels the sibling", len(ex.cancelled) == 1 && orderStatus(om, buy.ID) == models.StatusCanceled)
+
+	pair.ID, pair.BuyOrderID = "OCO-stale", "ORD-gone"
+	check("Pair with an unknown leg is refused", om.RestoreOCOPair(pair) != nil)
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunOrderDecodeTests executes all tests for decoding order and fill payloads.
+func RunOrderDecodeTests() {
+	testDecodeOrderShapes()
+	testDecodeOrderTimestamps()
+	testDecodeOrderMissingFields()
+	testDecodeFillVariants()
+	testStringIDFillApplied()
+	testRouterMatchesTimestampResolutions()
+}
+
+var decodeTime = time.Date(2026, 1, 5, 15, 0, 1, 0, time.UTC)
+
+func decodeOrder(payload string) (tradovate.OrderEvent, error) {
+	return tradovate.DecodeOrderEvent(json.RawMessage(payload))
+}
+
+func testDecodeOrderShapes() {
+	// Captured shapes: sync snapshot, props entity with string IDs, order
+	// version with its own id, and a whole props event
+	payloads := map[string]string{
+		"Snapshot order": `{"id":274612345,"accountId":1,"contractId":3570918,"timestamp":"2026-01-05T15:00:01Z","action":"Buy","ordStatus":"Working","archived":false}`,
+		"String IDs":     `{"id":"274612345","accountId":"1","contractId":"3570918","timestamp":"2026-01-05T15:00:01Z","action":"Buy","ordStatus":"Working"}`,
+		"Order version":  `{"id":991,"orderId":274612345,"accountId":1,"contractId":3570918,"timestamp":"2026-01-05T15:00:01Z","action":"Buy","ordStatus":"Working"}`,
+		"Props event":    `{"entityType":"order","eventType":"Updated","entity":{"id":274612345,"accountId":1,"contractId":3570918,"timestamp":"2026-01-05T15:00:01Z","action":"Buy","ordStatus":"Working"}}`,
+	}
+	for name, payload := range payloads {
+		ev, err := decodeOrder(payload)
+		check(fmt.Sprintf("%s decodes to the same order (Error: %v)", name, err), err == nil &&
+			ev.ID == 274612345 && ev.AccountID == 1 && ev.ContractID == 3570918 &&
+			ev.Action == "Buy" && ev.OrdStatus == "Working" && ev.Timestamp.Equal(decodeTime))
+	}
+
+	ev, _ := decodeOrder(`{"id":991,"orderId":274612345,"orderQty":"2","orderType":"Limit","price":"5001.25","stopPrice":null}`)
+	check("Order version quantity and price are read", ev.HasQty && ev.Qty == 2 && ev.HasPrice && ev.Price == 5001.25)
+	check("Null stop price is missing, not zero", !ev.HasStopPrice)
+
+	ev, _ = decodeOrder(`{"id":274612345,"ordStatus":"Filled","cumQty":"2","avgPx":5001.375}`)
+	check("Filled quantity and average price are read", ev.HasCumQty && ev.CumQty == 2 && ev.HasAvgPrice && ev.AvgPrice == 5001.375)
+	ev, _ = decodeOrder(`{"id":274612345,"ordStatus":"Filled","avgPrice":"5001.50"}`)
+	check("avgPrice is read when avgPx is absent", ev.HasAvgPrice && ev.AvgPrice == 5001.5 && !ev.HasCumQty)
+}
+
+func testDecodeOrderTimestamps() {
+	stamps := map[string]string{
+		"Seconds":      `"2026-01-05T15:00:01Z"`,
+		"Milliseconds": `"2026-01-05T15:00:01.000Z"`,
+		"Nanoseconds":  `"2026-01-05T15:00:01.000000000Z"`,
+		"No zone":      `"2026-01-05T15:00:01"`,
+		"Offset":       `"2026-01-05T09:00:01-06:00"`,
+		"Epoch millis": `1767625201000`,
+		"Epoch secs":   `"1767625201"`,
+	}
+	for name, stamp := range stamps {
+		ev, err := decodeOrder(`{"id":1,"ordStatus":"Filled","timestamp":` + stamp + `}`)
+		check(fmt.Sprintf("%s timestamp is read as UTC (got %v)", name, ev.Timestamp), err == nil &&
+			ev.HasTimestamp && ev.Timestamp.Equal(decodeTime) && ev.Timestamp.Location() == time.UTC)
+	}
+
+	ev, err := decodeOrder(`{"id":1,"ordStatus":"Filled","timestamp":"yesterday"}`)
+	check("Unreadable timestamp is missing, not an error", err == nil && !ev.HasTimestamp && ev.Timestamp.IsZero())
+}
+
+func testDecodeOrderMissingFields() {
+	ev, err := decodeOrder(`{"id":5,"ordStatus":"Canceled"}`)
+	check("Only the ID is required", err == nil && ev.ID == 5)
+	check("Absent fields are marked missing", !ev.HasQty && !ev.HasPrice && !ev.HasStopPrice && !ev.HasTimestamp &&
+		!ev.HasCumQty && !ev.HasAvgPrice)
+
+	ev, _ = decodeOrder(`{"id":5,"qty":0,"price":0}`)
+	check("Zero values are present", ev.HasQty && ev.HasPrice)
+	ev, _ = decodeOrder(`{"id":5,"qty":1.5,"price":"n/a"}`)
+	check("Fractional quantity and text price are missing", !ev.HasQty && !ev.HasPrice)
+
+	for _, payload := range []string{`{"ordStatus":"Working"}`, `{"id":0}`, `{"id":"abc"}`, `[1,2]`, `not json`} {
+		_, err := decodeOrder(payload)
+		check("Order without a readable ID is refused: "+payload, err != nil)
+	}
+}
+
+func testDecodeFillVariants() {
+	fill, err := tradovate.DecodeFillEvent(json.RawMessage(`{"id":"61","orderId":"274612345","contractId":3570918,"timestamp":1767625201000,"action":"Sell","qty":"2","price":"5001.50"}`))
+	check(fmt.Sprintf("String fill fields are read (Error: %v)", err), err == nil &&
+		fill.ID == 61 && fill.OrderID == 274612345 && fill.Qty == 2 && fill.Price == 5001.5 && fill.Timestamp.Equal(decodeTime))
+
+	fill, err = tradovate.DecodeFillEvent(json.RawMessage(`{"entityType":"fill","entity":{"id":62,"orderId":274612345,"qty":1,"price":5001}}`))
+	check("Fill inside a whole props event is read", err == nil && fill.ID == 62 && !fill.HasTimestamp)
+
+	refused := map[string]string{
+		"No price":    `{"id":63,"orderId":274612345,"qty":1}`,
+		"No quantity": `{"id":63,"orderId":274612345,"price":5001}`,
+		"Zero qty":    `{"id":63,"orderId":274612345,"qty":0,"price":5001}`,
+		"No order":    `{"id":63,"qty":1,"price":5001}`,
+	}
+	for name, payload := range refused {
+		_, err := tradovate.DecodeFillEvent(json.RawMessage(payload))
+		check(name+" is refused rather than applied as zero", err != nil)
+	}
+}
+
+func testStringIDFillApplied() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(20, logger.LevelError))
+	defer cleanup()
+
+	order, err := om.SubmitOrder("MESH6", models.SideBuy, 2, execution.OrderOptions{Type: models.TypeLimit, Price: 4990})
+	if err != nil {
+		check(fmt.Sprintf("Order is placed (Error: %v)", err), false)
+		return
+	}
+	om.HandleFillEvent(json.RawMessage(fmt.Sprintf(`{"id":"1","orderId":"%s","qty":"1"}`, order.ExternalID)))
+	check("A fill without a price is not applied", len(om.GetFills(order.ID)) == 0)
+
+	om.HandleFillEvent(json.RawMessage(fmt.Sprintf(`{"id":"1","orderId":"%s","qty":"1","price":"4990.00"}`, order.ExternalID)))
+	om.HandleOrderEvent(json.RawMessage(fmt.Sprintf(`{"id":"%s","ordStatus":"Canceled"}`, order.ExternalID)))
+	fills := om.GetFills(order.ID)
+	check("String-ID fill reaches its order", len(fills) == 1 && fills[0].Price == 4990 && fills[0].Quantity == 1)
+	check("Fills are found by the exchange order ID", len(om.GetFillsByExternalID(order.ExternalID)) == 1 &&
+		om.GetFillsByExternalID("999") == nil)
+	check("String-ID order event reaches its order", orderStatus(om, order.ID) == models.StatusCanceled)
+}
+
+func testRouterMatchesTimestampResolutions() {
+	var delivered []string
+	ds := newOrderEventSubscriber(&delivered)
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":{"id":101,"ordStatus":"Filled","timestamp":"2026-01-05T15:00:01Z"}}`))
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":{"id":"101","ordStatus":"Filled","timestamp":"2026-01-05T15:00:01.000Z"}}`))
+	check("Same state in another ID type and time resolution is a duplicate", len(delivered) == 1)
+
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":{"id":102,"ordStatus":"Working","timestamp":"2026-01-05T15:00:02Z"}}`))
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":{"id":102,"ordStatus":"Working","timestamp":1767625201000}}`))
+	_, outOfOrder := ds.GetOrderEventStats()
+	check("Epoch timestamps are compared with RFC 3339 ones", len(delivered) == 2 && outOfOrder == 1)
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// Sample placeorder error responses in the shapes Tradovate returns them
+const (
+	sampleMarginFailure  = `{"failureReason":"InsufficientMargin","failureText":"Insufficient margin. Required margin: 1234.00, available: 512.40"}`
+	sampleSessionClosed  = `{"failureReason":"SessionClosed","failureText":"Trading session is closed for MESH6"}`
+	samplePenaltyTicket  = `{"p-ticket":"9b1f0c2a","p-time":15,"p-captcha":false}`
+	sampleUnknownFailure = `{"failureReason":"UnknownReason","failureText":"Order could not be placed"}`
+)
+
+// RunOrderErrorTests executes all tests for order rejection parsing.
+func RunOrderErrorTests() {
+	testParseOrderErrorPayloads()
+	testDescribeRejection()
+	testPlaceOrderFailureRejectsOrder()
+	testOrderEventRejectReason()
+}
+
+func testParseOrderErrorPayloads() {
+	check("Successful placeorder is not an error", tradovate.ParseOrderError(200, []byte(`{"orderId":9100}`)) == nil)
+
+	oe := tradovate.ParseOrderError(200, []byte(sampleMarginFailure))
+	check("failureReason on a 200 is a rejection", oe != nil && oe.IsRejection() && oe.Reason == "InsufficientMargin")
+	check("Margin rejection states the requirement", oe != nil && oe.Error() == "insufficient margin (need $1,234)")
+
+	oe = tradovate.ParseOrderError(200, []byte(sampleSessionClosed))
+	check("Known code keeps Tradovate's detail",
+		oe != nil && oe.Error() == "market is closed (Trading session is closed for MESH6)")
+
+	oe = tradovate.ParseOrderError(200, []byte(samplePenaltyTicket))
+	check("Penalty ticket is reported with its wait", oe != nil && !oe.IsRejection() && oe.Error() == "rate limited by Tradovate, retry in 15s")
+
+	oe = tradovate.ParseOrderError(500, []byte("Internal Server Error\n"))
+	check("Non-JSON body is kept", oe != nil && !oe.IsRejection() && oe.Error() == "order request failed (HTTP 500): Internal Server Error")
+}
+
+func testDescribeRejection() {
+	check("Unknown code is shown as-is with its text",
+		tradovate.DescribeRejection("UnknownReason", "Order could not be placed") == "UnknownReason (Order could not be placed)")
+	check("Known code alone is readable", tradovate.DescribeRejection("MaxPosLimitReached", "") == "position limit reached for this contract")
+	check("Text repeating the code is not duplicated", tradovate.DescribeRejection("NoQuote", "NoQuote") == "no quote available for this contract")
+	check("Amounts keep their cents",
+		tradovate.DescribeRejection("InsufficientMargin", "requires $12,500.5 of margin") == "insufficient margin (need $12,500.50)")
+	check("Margin text without an amount falls back to the text",
+		tradovate.DescribeRejection("InsufficientMargin", "Not enough margin") == "insufficient margin (Not enough margin)")
+}
+
+func orderErrorTestConfig() *config.Config {
+	return &config.Config{
+		Risk: config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500, EnableRiskChecks: true},
+	}
+}
+
+func testPlaceOrderFailureRejectsOrder() {
+	log := logger.NewLogger(10, logger.LevelInfo)
+	om, cleanup := newHTTPOrderManager(orderErrorTestConfig(), log, func(_ *execution.OrderManager, w http.ResponseWriter) {
+		fmt.Fprint(w, sampleMarginFailure)
+	})
+	defer cleanup()
+
+	order, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Placeorder rejection is returned as an error", err != nil)
+	check("Order is marked rejected with a readable reason",
+		order != nil && order.Status == models.StatusRejected && order.RejectReason == "insufficient margin (need $1,234)")
+
+	logged := false
+	for _, e := range log.GetEntries() {
+		logged = logged || strings.Contains(e.Message, "insufficient margin (need $1,234)")
+	}
+	check("Order log shows the reason", logged)
+}
+
+func testOrderEventRejectReason() {
+	om, cleanup := newHTTPOrderManager(orderErrorTestConfig(), logger.NewLogger(10, logger.LevelInfo), func(_ *execution.OrderManager, w http.ResponseWriter) {
+		fmt.Fprint(w, `{"orderId":9200}`)
+	})
+	defer cleanup()
+
+	order, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	if err != nil || order == nil {
+		check("Order accepted before the exchange rejects it", false)
+		return
+	}
+	om.HandleOrderEvent(json.RawMessage(`{"id":9200,"ordStatus":"Rejected","rejectReason":"MaxPosLimitReached","text":"Position limit 2 exceeded"}`))
+
+	got, _ := om.GetOrder(order.ID)
+	check("WebSocket reject code is translated",
+		got != nil && got.Status == models.StatusRejected && got.RejectReason == "position limit reached for this contract (Position limit 2 exceeded)")
+}
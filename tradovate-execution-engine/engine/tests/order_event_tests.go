@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunOrderEventTests executes all tests for order event de-duplication.
+func RunOrderEventTests() {
+	testSnapshotThenPropsOverlap()
+	testOutOfOrderEventDropped()
+	testFillBeforePlaceOrderResponse()
+	testFinishedOrderEvents()
+	testReconnectResetsOrderEvents()
+}
+
+// nullSender is a WebSocketSender that discards everything
+type nullSender struct{}
+
+func (nullSender) Send(url string, body interface{}) error { return nil }
+func (nullSender) IsConnected() bool                       { return true }
+func (nullSender) Connect() error                          { return nil }
+
+func newOrderEventSubscriber(delivered *[]string) *tradovate.DataSubscriber {
+	ds := tradovate.NewDataSubscriptionManager(nullSender{})
+	ds.OnOrderUpdate = func(data json.RawMessage) {
+		var o struct {
+			OrdStatus string `json:"ordStatus"`
+		}
+		json.Unmarshal(data, &o)
+		*delivered = append(*delivered, o.OrdStatus)
+	}
+	return ds
+}
+
+func testSnapshotThenPropsOverlap() {
+	var delivered []string
+	ds := newOrderEventSubscriber(&delivered)
+
+	working := `{"id":101,"ordStatus":"Working","timestamp":"2026-01-05T15:00:00.000Z"}`
+	filled := `{"id":101,"ordStatus":"Filled","timestamp":"2026-01-05T15:00:01.000Z"}`
+
+	// Snapshot contains the working order
+	ds.HandleEvent("user/syncrequest", json.RawMessage(`{"users":[{"id":1}],"orders":[`+working+`]}`))
+	// Props repeats the same state, then the fill arrives twice
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":`+working+`}`))
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":`+filled+`}`))
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":`+filled+`}`))
+
+	check("Each order state transition is delivered exactly once",
+		len(delivered) == 2 && delivered[0] == "Working" && delivered[1] == "Filled")
+
+	duplicates, _ := ds.GetOrderEventStats()
+	check("Duplicate counter reflects suppressed snapshot/props overlap", duplicates == 2)
+}
+
+func testOutOfOrderEventDropped() {
+	var delivered []string
+	ds := newOrderEventSubscriber(&delivered)
+
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":{"id":202,"ordStatus":"Filled","timestamp":"2026-01-05T15:00:02.000Z"}}`))
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":{"id":202,"ordStatus":"Working","timestamp":"2026-01-05T15:00:01.000Z"}}`))
+
+	check("Working arriving after Filled is not delivered", len(delivered) == 1 && delivered[0] == "Filled")
+
+	_, outOfOrder := ds.GetOrderEventStats()
+	check("Out-of-order counter is incremented", outOfOrder == 1)
+}
+
+func testFinishedOrderEvents() {
+	var delivered []string
+	ds := newOrderEventSubscriber(&delivered)
+
+	filled := `{"entityType":"order","entity":{"id":303,"ordStatus":"Filled","timestamp":"2026-01-05T15:00:02.000Z"}}`
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":{"id":303,"ordStatus":"Working","timestamp":"2026-01-05T15:00:01.000Z"}}`))
+	ds.HandleEvent("props", json.RawMessage(filled))
+	ds.HandleEvent("props", json.RawMessage(filled))
+	ds.HandleEvent("props", json.RawMessage(`{"entityType":"order","entity":{"id":303,"ordStatus":"Working","timestamp":"2026-01-05T15:00:01.000Z"}}`))
+	duplicates, outOfOrder := ds.GetOrderEventStats()
+	check("A finished order's repeats are still suppressed", len(delivered) == 2 && duplicates == 1 && outOfOrder == 1)
+
+	// Enough later orders finish that the first one is forgotten
+	for id := 1000; id <= 2000; id++ {
+		ds.HandleEvent("props", json.RawMessage(fmt.Sprintf(`{"entityType":"order","entity":{"id":%d,"ordStatus":"Canceled"}}`, id)))
+	}
+	delivered = nil
+	ds.HandleEvent("props", json.RawMessage(filled))
+	check("The oldest finished orders are forgotten", len(delivered) == 1)
+}
+
+func testReconnectResetsOrderEvents() {
+	var delivered []string
+	ds := newOrderEventSubscriber(&delivered)
+
+	filled := `{"entityType":"order","entity":{"id":404,"ordStatus":"Filled","timestamp":"2026-01-05T15:00:02.000Z"}}`
+	ds.HandleEvent("props", json.RawMessage(filled))
+	ds.HandleEvent("props", json.RawMessage(filled))
+	ds.Connect()
+	duplicates, _ := ds.GetOrderEventStats()
+	check("Reconnecting clears the order event counters", duplicates == 0)
+
+	ds.HandleEvent("props", json.RawMessage(filled))
+	check("The reconnect snapshot's orders are delivered again", len(delivered) == 2)
+}
+
+// newHTTPOrderManager returns a live order manager whose placeorder requests are
+// answered by placeOrder. The returned func shuts the server down.
+func newHTTPOrderManager(cfg *config.Config, log *logger.Logger, placeOrder func(*execution.OrderManager, http.ResponseWriter)) (*execution.OrderManager, func()) {
+	var om *execution.OrderManager
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/order/placeorder" {
+			http.NotFound(w, r)
+			return
+		}
+		placeOrder(om, w)
+	}))
+
+	tm := auth.NewTokenManager(cfg)
+	tm.SetSessionForTest(srv.URL, "test-token", 1)
+	om = execution.NewOrderManager(tm, cfg, log)
+
+	return om, srv.Close
+}
+
+// testFillBeforePlaceOrderResponse delivers the WebSocket fill while placeorder is
+// still in flight, so the order has no external ID yet when the event arrives
+func testFillBeforePlaceOrderResponse() {
+	log := logger.NewLogger(10, logger.LevelDebug)
+	cfg := &config.Config{
+		Risk: config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500, EnableRiskChecks: true},
+	}
+
+	om, cleanup := newHTTPOrderManager(cfg, log, func(om *execution.OrderManager, w http.ResponseWriter) {
+		// Another order's event first, then our fill, both before the response
+		om.HandleOrderEvent(json.RawMessage(`{"id":9001,"ordStatus":"Rejected"}`))
+		om.HandleOrderEvent(json.RawMessage(`{"id":9002,"ordStatus":"Filled"}`))
+		fmt.Fprint(w, `{"orderId":9002}`)
+	})
+	defer cleanup()
+
+	var updates []models.OrderStatus
+	om.AddOrderListener(func(o models.Order) { updates = append(updates, o.Status) })
+
+	order, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check(fmt.Sprintf("Order submits without error (Error: %v)", err), err == nil)
+	if order == nil {
+		return
+	}
+
+	got, _ := om.GetOrder(order.ID)
+	check("External ID is taken from the placeorder response", got.ExternalID == "9002")
+	check("Fill that beat the REST response is applied", got.Status == models.StatusFilled)
+	check("Listener sees the fill", len(updates) > 0 && updates[len(updates)-1] == models.StatusFilled)
+
+	// The same fill arriving again once correlated is a no-op
+	om.HandleOrderEvent(json.RawMessage(`{"id":9002,"ordStatus":"Filled"}`))
+	got, _ = om.GetOrder(order.ID)
+	check("Correlated order keeps its fill", got.Status == models.StatusFilled)
+}
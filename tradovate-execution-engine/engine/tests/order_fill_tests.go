@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunOrderFillTests executes all tests for partial fill tracking.
+func RunOrderFillTests() {
+	testFillAveraging()
+	testPartialFills()
+	testFillBeforeOrderID()
+}
+
+func newFillTestConfig() *config.Config {
+	return &config.Config{
+		Risk: config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500, EnableRiskChecks: true},
+	}
+}
+
+func testFillAveraging() {
+	order := &models.Order{Quantity: 3, Fills: []models.Fill{
+		{ID: "1", Quantity: 1, Price: 100},
+		{ID: "2", Quantity: 2, Price: 103},
+	}}
+
+	check("Filled quantity is cumulative", order.FilledQty() == 3)
+	assertEqualsFloat("Average fill price is volume weighted", 102, order.AvgFillPrice(), 0.0001)
+	check("No fills averages to zero", (&models.Order{}).AvgFillPrice() == 0)
+}
+
+func testPartialFills() {
+	log := logger.NewLogger(10, logger.LevelDebug)
+	om, cleanup := newHTTPOrderManager(newFillTestConfig(), log, func(_ *execution.OrderManager, w http.ResponseWriter) {
+		fmt.Fprint(w, `{"orderId":7001}`)
+	})
+	defer cleanup()
+
+	var updates []models.OrderStatus
+	om.AddOrderListener(func(o models.Order) { updates = append(updates, o.Status) })
+
+	order, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 3)
+	check(fmt.Sprintf("3-lot submits without error (Error: %v)", err), err == nil)
+	if order == nil {
+		return
+	}
+
+	om.HandleFillEvent(json.RawMessage(`{"id":1,"orderId":7001,"qty":1,"price":100,"timestamp":"2026-01-05T15:00:00.000Z"}`))
+	got, _ := om.GetOrder(order.ID)
+	check("First fill leaves the order partially filled", got.Status == models.StatusPartiallyFilled)
+
+	// Same fill repeated by the snapshot/props overlap
+	om.HandleFillEvent(json.RawMessage(`{"id":1,"orderId":7001,"qty":1,"price":100,"timestamp":"2026-01-05T15:00:00.000Z"}`))
+	check("Repeated fill ID is ignored", len(om.GetFills(order.ID)) == 1)
+
+	om.HandleFillEvent(json.RawMessage(`{"id":2,"orderId":7001,"qty":2,"price":103,"timestamp":"2026-01-05T15:00:01.000Z"}`))
+	got, _ = om.GetOrder(order.ID)
+	check("Order is filled once cumulative quantity reaches order quantity", got.Status == models.StatusFilled)
+	check("Both fills are kept", len(om.GetFills(order.ID)) == 2)
+	assertEqualsFloat("Order average price covers both fills", 102, got.AvgFillPrice(), 0.0001)
+	check("Listeners see partial then filled",
+		len(updates) >= 2 && updates[len(updates)-2] == models.StatusPartiallyFilled && updates[len(updates)-1] == models.StatusFilled)
+}
+
+// testFillBeforeOrderID delivers a fill while placeorder is still in flight
+func testFillBeforeOrderID() {
+	log := logger.NewLogger(10, logger.LevelDebug)
+	om, cleanup := newHTTPOrderManager(newFillTestConfig(), log, func(om *execution.OrderManager, w http.ResponseWriter) {
+		om.HandleFillEvent(json.RawMessage(`{"id":11,"orderId":7002,"qty":1,"price":4999.75,"timestamp":"2026-01-05T15:00:00.000Z"}`))
+		fmt.Fprint(w, `{"orderId":7002}`)
+	})
+	defer cleanup()
+
+	order, err := om.SubmitMarketOrder("MESH6", models.SideSell, 2)
+	check(fmt.Sprintf("2-lot submits without error (Error: %v)", err), err == nil)
+	if order == nil {
+		return
+	}
+
+	got, _ := om.GetOrder(order.ID)
+	check("Early fill is applied once the order ID is known", got.FilledQty() == 1)
+	check("Early partial fill is not overwritten by Submitted", got.Status == models.StatusPartiallyFilled)
+}
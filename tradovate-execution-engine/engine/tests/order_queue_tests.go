@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunOrderQueueTests executes all tests for queueing strategy orders while Tradovate cannot be reached
+func RunOrderQueueTests() {
+	testQueuedOrderSentWhenBack()
+	testQueuedOrderExpires()
+	testQueuedOrderRechecked()
+	testQueuedOrderWithoutSession()
+	testOrdersNotQueued()
+	testCancelQueuedOrders()
+}
+
+// flakyExchange is a placeorder endpoint that answers with status until it is
+// changed, and counts the orders it accepted
+type flakyExchange struct {
+	status   atomic.Int32
+	accepted atomic.Int32
+}
+
+// newQueueTestManager returns a live order manager in front of a flaky
+// exchange that starts out failing with a 503. The returned func shuts it down.
+func newQueueTestManager(risk config.RiskConfig) (*execution.OrderManager, *flakyExchange, *logger.Logger, func()) {
+	ex := &flakyExchange{}
+	ex.status.Store(http.StatusServiceUnavailable)
+	log := logger.NewLogger(50, logger.LevelDebug)
+	om, cleanup := newHTTPOrderManager(&config.Config{Risk: risk}, log, func(_ *execution.OrderManager, w http.ResponseWriter) {
+		if status := int(ex.status.Load()); status != http.StatusOK {
+			w.WriteHeader(status)
+			fmt.Fprint(w, "upstream unavailable")
+			return
+		}
+		id := 7000 + ex.accepted.Add(1)
+		fmt.Fprintf(w, `{"orderId":%d}`, id)
+	})
+	return om, ex, log, cleanup
+}
+
+// queueTestRisk has risk checks on with the default strategy order throttle
+func queueTestRisk() config.RiskConfig {
+	return config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500, EnableRiskChecks: true}
+}
+
+// strategyOrder submits a market order for strategy instance "1"
+func strategyOrder(om *execution.OrderManager, side models.OrderSide, qty int) (*models.Order, error) {
+	return om.SubmitOrder("MESH6", side, qty, execution.OrderOptions{Origin: "1", SignalAt: time.Now()})
+}
+
+func testQueuedOrderSentWhenBack() {
+	om, ex, log, cleanup := newQueueTestManager(queueTestRisk())
+	defer cleanup()
+
+	var mu sync.Mutex
+	var seen []models.OrderStatus
+	om.AddOrderListener(func(o models.Order) {
+		mu.Lock()
+		seen = append(seen, o.Status)
+		mu.Unlock()
+	})
+
+	order, err := strategyOrder(om, models.SideBuy, 1)
+	check("A strategy order Tradovate cannot take is queued, not failed", err == nil && order != nil && order.Status == models.StatusQueued)
+	check("The queue holds it", om.QueuedOrderCount() == 1 && logged(log, "queued until Tradovate can be reached"))
+
+	sent, dropped := om.RetryQueuedOrders(time.Now())
+	check("A retry while Tradovate still fails keeps it queued", sent == 0 && dropped == 0 && order.Status == models.StatusQueued && om.QueuedOrderCount() == 1)
+	om.RetryQueuedOrders(time.Now())
+	mu.Lock()
+	check(fmt.Sprintf("Retries held again tell listeners nothing new (got %v)", seen), len(seen) == 1 && seen[0] == models.StatusQueued)
+	mu.Unlock()
+
+	ex.status.Store(http.StatusOK)
+	sent, dropped = om.RetryQueuedOrders(time.Now())
+	check("It is sent once Tradovate answers", sent == 1 && dropped == 0 && order.Status == models.StatusSubmitted && order.ExternalID == "7001")
+	check("The queue is empty", om.QueuedOrderCount() == 0 && ex.accepted.Load() == 1)
+	check("The throttle counted the order once, when it was first checked", om.GetRiskManager().ThrottleViolations("1") == 0)
+
+	mu.Lock()
+	defer mu.Unlock()
+	check("Listeners see it queued, then submitted", len(seen) >= 2 && seen[0] == models.StatusQueued && seen[len(seen)-1] == models.StatusSubmitted)
+}
+
+func testQueuedOrderExpires() {
+	om, ex, log, cleanup := newQueueTestManager(queueTestRisk())
+	defer cleanup()
+
+	order, _ := strategyOrder(om, models.SideSell, 1)
+	check("Strategy order is queued", order != nil && order.Status == models.StatusQueued)
+	check("Nothing expires within the queue age", om.DropExpiredOrders(time.Now().Add(20*time.Second)) == 0)
+
+	// The connection comes back too late
+	ex.status.Store(http.StatusOK)
+	sent, dropped := om.RetryQueuedOrders(order.SignalAt.Add(execution.DefaultOrderQueueAge + time.Second))
+	check("An order older than the queue age is dropped, not sent", sent == 0 && dropped == 1 && order.Status == models.StatusFailed && ex.accepted.Load() == 0)
+	check("The drop is logged loudly", logged(log, "QUEUED ORDER DROPPED") && logged(log, "the market has moved"))
+	check("A dropped order leaves the queue", om.QueuedOrderCount() == 0)
+}
+
+func testQueuedOrderRechecked() {
+	risk := queueTestRisk()
+	om, ex, _, cleanup := newQueueTestManager(risk)
+	defer cleanup()
+
+	first, _ := strategyOrder(om, models.SideBuy, 2)
+	check("Order within the limits is queued", first != nil && first.Status == models.StatusQueued)
+
+	// The limit is lowered while the order waits
+	risk.MaxOrderQty = 1
+	om.ApplyConfig(&config.Config{Risk: risk})
+	ex.status.Store(http.StatusOK)
+	sent, _ := om.RetryQueuedOrders(time.Now())
+	check("The risk checks are run again when it is sent", sent == 1 && first.Status == models.StatusRejected && ex.accepted.Load() == 0)
+
+	om2, ex2, _, cleanup2 := newQueueTestManager(queueTestRisk())
+	defer cleanup2()
+	queued, _ := strategyOrder(om2, models.SideBuy, 1)
+	om2.EngageKillSwitch()
+	ex2.status.Store(http.StatusOK)
+	om2.RetryQueuedOrders(time.Now())
+	check("The kill switch stops a queued order", queued != nil && queued.Status == models.StatusRejected && ex2.accepted.Load() == 0)
+}
+
+func testQueuedOrderWithoutSession() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"orderId":8001}`)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{Risk: queueTestRisk()}
+	tm := auth.NewTokenManager(cfg)
+	om := execution.NewOrderManager(tm, cfg, logger.NewLogger(50, logger.LevelDebug))
+
+	order, err := strategyOrder(om, models.SideBuy, 1)
+	check("A strategy order without a session is queued", err == nil && order.Status == models.StatusQueued)
+	om.RetryQueuedOrders(time.Now())
+	check("It stays queued until the session is back", order.Status == models.StatusQueued)
+
+	tm.SetSessionForTest(srv.URL, "test-token", 1)
+	sent, _ := om.RetryQueuedOrders(time.Now())
+	check("It is sent once the session is restored", sent == 1 && order.Status == models.StatusSubmitted && order.ExternalID == "8001")
+}
+
+func testOrdersNotQueued() {
+	om, _, _, cleanup := newQueueTestManager(queueTestRisk())
+	defer cleanup()
+
+	order, err := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: models.OriginManual})
+	check("A manual order fails at once", err != nil && order.Status == models.StatusFailed && om.QueuedOrderCount() == 0)
+
+	risk := queueTestRisk()
+	risk.OrderQueueSeconds = -1
+	off, _, _, cleanupOff := newQueueTestManager(risk)
+	defer cleanupOff()
+	order, err = strategyOrder(off, models.SideBuy, 1)
+	check("A negative OrderQueueSeconds turns the queue off", err != nil && order.Status == models.StatusFailed && off.QueuedOrderCount() == 0)
+
+	stale, _, _, cleanupStale := newQueueTestManager(queueTestRisk())
+	defer cleanupStale()
+	order, err = stale.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: "1", SignalAt: time.Now().Add(-time.Minute)})
+	check("An order whose signal is already too old is not queued", err != nil && order.Status == models.StatusFailed)
+
+	rejected, cleanupRejected := newHTTPOrderManager(&config.Config{Risk: queueTestRisk()}, logger.NewLogger(50, logger.LevelDebug), func(_ *execution.OrderManager, w http.ResponseWriter) {
+		fmt.Fprint(w, `{"failureReason":"InsufficientMargin","failureText":"Not enough margin"}`)
+	})
+	defer cleanupRejected()
+	order, err = strategyOrder(rejected, models.SideBuy, 1)
+	check("An order Tradovate rejects is not queued", err != nil && order.Status == models.StatusRejected && rejected.QueuedOrderCount() == 0)
+}
+
+func testCancelQueuedOrders() {
+	om, ex, _, cleanup := newQueueTestManager(config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500})
+	defer cleanup()
+
+	first, _ := strategyOrder(om, models.SideBuy, 1)
+	second, _ := om.SubmitOrder("MNQH6", models.SideSell, 1, execution.OrderOptions{Origin: "2", SignalAt: time.Now()})
+	third, _ := strategyOrder(om, models.SideSell, 1)
+	check("Three strategy orders are queued", om.QueuedOrderCount() == 3)
+
+	check("A queued order is cancelled without asking Tradovate", om.CancelOrder(first.ID) == nil && first.Status == models.StatusCanceled)
+	check("Stopping a strategy cancels only its queued orders", om.CancelQueuedOrders("2") == 1 &&
+		second.Status == models.StatusCanceled && third.Status == models.StatusQueued)
+
+	cancelled, err := om.CancelWorkingOrders()
+	check("Cancelling every working order includes the queue", err == nil && cancelled == 1 && third.Status == models.StatusCanceled)
+
+	ex.status.Store(http.StatusOK)
+	sent, _ := om.RetryQueuedOrders(time.Now())
+	check("Cancelled orders are never sent", sent == 0 && ex.accepted.Load() == 0)
+}
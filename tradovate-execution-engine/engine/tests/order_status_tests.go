@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/risk"
+)
+
+// RunOrderStatusTests executes all tests for suspended bracket legs and expired orders
+func RunOrderStatusTests() {
+	testSuspendedLegLifecycle()
+	testExpiredExecutionReport()
+	testSuspendedLegsInRiskChecks()
+	testSuspendedLegsFlattened()
+	testExpiredOCOLeg()
+	testEarlySuspendedEvent()
+}
+
+// orderEvent delivers a Tradovate order entity with ordStatus for a local order
+func orderEvent(om *execution.OrderManager, orderID, ordStatus string) {
+	order, ok := om.GetOrder(orderID)
+	if !ok {
+		return
+	}
+	om.HandleOrderEvent(json.RawMessage(fmt.Sprintf(`{"id":%s,"ordStatus":%q}`, order.ExternalID, ordStatus)))
+}
+
+func testSuspendedLegLifecycle() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelWarn))
+	defer cleanup()
+
+	var updates []models.OrderStatus
+	om.AddOrderListener(func(o models.Order) {
+		if !o.IsExternal() {
+			updates = append(updates, o.Status)
+		}
+	})
+	order, err := om.SubmitLimitOrder("MESH6", models.SideSell, 1, 5010)
+	if err != nil {
+		check(fmt.Sprintf("Limit order submits (Error: %v)", err), false)
+		return
+	}
+	check("Order starts submitted", orderStatus(om, order.ID) == models.StatusSubmitted && om.WorkingOrderCount() == 1)
+
+	orderEvent(om, order.ID, "Suspended")
+	check("Suspended event suspends the order", orderStatus(om, order.ID) == models.StatusSuspended)
+	check("A suspended leg is not working", om.WorkingOrderCount() == 0 && om.SuspendedOrderCount() == 1)
+
+	orderEvent(om, order.ID, "Working")
+	check("Working event resumes a suspended leg", orderStatus(om, order.ID) == models.StatusSubmitted)
+	check("A resumed leg is working again", om.WorkingOrderCount() == 1 && om.SuspendedOrderCount() == 0)
+
+	orderEvent(om, order.ID, "Expired")
+	check("Expired event expires the order", orderStatus(om, order.ID) == models.StatusExpired)
+	check("An expired order is neither working nor suspended", om.WorkingOrderCount() == 0 && om.SuspendedOrderCount() == 0)
+
+	orderEvent(om, order.ID, "Working")
+	orderEvent(om, order.ID, "Suspended")
+	check("An expired order stays expired", orderStatus(om, order.ID) == models.StatusExpired)
+	check("Listeners see each transition once", fmt.Sprint(updates) == fmt.Sprint([]models.OrderStatus{
+		models.StatusSubmitted, models.StatusSuspended, models.StatusSubmitted, models.StatusExpired,
+	}))
+}
+
+func testExpiredExecutionReport() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelWarn))
+	defer cleanup()
+
+	order, err := om.SubmitLimitOrder("MESH6", models.SideBuy, 2, 4990)
+	if err != nil {
+		check(fmt.Sprintf("Limit order submits (Error: %v)", err), false)
+		return
+	}
+	om.HandleExecutionReport(json.RawMessage(fmt.Sprintf(`{"orderId":%s,"execType":"Expired","ordStatus":"Expired"}`, order.ExternalID)))
+	check("Expired execution report expires the order", orderStatus(om, order.ID) == models.StatusExpired)
+
+	fillLeg(om, order.ID, 1, 1)
+	got, _ := om.GetOrder(order.ID)
+	check("A late fill does not reopen an expired order", got.Status == models.StatusExpired && got.FilledQty() == 1)
+}
+
+func testSuspendedLegsInRiskChecks() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelWarn))
+	defer cleanup()
+	om.ApplyConfig(&config.Config{Risk: config.RiskConfig{MaxContracts: 10, DailyLossLimit: 500, MaxWorkingOrders: 2, EnableRiskChecks: true}})
+
+	leg, err := om.SubmitLimitOrder("MESH6", models.SideSell, 1, 5010)
+	if err != nil {
+		check(fmt.Sprintf("Limit order submits (Error: %v)", err), false)
+		return
+	}
+	orderEvent(om, leg.ID, "Suspended")
+
+	// The new order is pending while it is checked, so it is the second
+	_, err = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Type: models.TypeLimit, Price: 4990})
+	check("Suspended legs count toward the working order limit", errors.Is(err, risk.ErrMaxWorkingOrders))
+
+	orderEvent(om, leg.ID, "Expired")
+	_, err = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Type: models.TypeLimit, Price: 4990})
+	check(fmt.Sprintf("Expired legs free the limit (Error: %v)", err), err == nil)
+}
+
+func testSuspendedLegsFlattened() {
+	om, ex, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelWarn))
+	defer cleanup()
+	pt, _ := newLedgerTracker("")
+	om.SetPortfolioTracker(pt)
+
+	leg, err := om.SubmitLimitOrder("MESH6", models.SideSell, 1, 5010)
+	if err != nil {
+		check(fmt.Sprintf("Limit order submits (Error: %v)", err), false)
+		return
+	}
+	orderEvent(om, leg.ID, "Suspended")
+
+	plan, _ := om.PreviewFlatten()
+	check("Flatten plans to cancel a suspended leg", len(plan.Cancels) == 1 && plan.Cancels[0].ID == leg.ID)
+	cancelled, err := om.CancelWorkingOrders()
+	check("CancelWorkingOrders cancels a suspended leg", err == nil && cancelled == 1 &&
+		len(ex.cancelled) == 1 && orderStatus(om, leg.ID) == models.StatusCanceled)
+}
+
+func testExpiredOCOLeg() {
+	om, ex, cleanup := newOCOOrderManager(logger.NewLogger(50, logger.LevelWarn))
+	defer cleanup()
+
+	id, err := om.SubmitOCOPair("MESH6", 1, 5010, 4990)
+	pair, ok := om.GetOCOPair(id)
+	if err != nil || !ok {
+		check(fmt.Sprintf("OCO pair is submitted (Error: %v)", err), false)
+		return
+	}
+	orderEvent(om, pair.BuyOrderID, "Expired")
+	sell, _ := om.GetOrder(pair.SellOrderID)
+	check("An expired leg cancels its sibling", len(ex.cancelled) == 1 && ex.cancelled[0] == sell.ExternalID &&
+		orderStatus(om, pair.SellOrderID) == models.StatusCanceled)
+	pair, _ = om.GetOCOPair(id)
+	check("The pair ends cancelled", pair.State == execution.OCOCanceled)
+}
+
+// testEarlySuspendedEvent delivers Suspended while placeorder is still in flight
+func testEarlySuspendedEvent() {
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500, EnableRiskChecks: true}}
+	om, cleanup := newHTTPOrderManager(cfg, logger.NewLogger(50, logger.LevelWarn), func(om *execution.OrderManager, w http.ResponseWriter) {
+		om.HandleOrderEvent(json.RawMessage(`{"id":9100,"ordStatus":"Suspended"}`))
+		fmt.Fprint(w, `{"orderId":9100}`)
+	})
+	defer cleanup()
+
+	var updates []models.OrderStatus
+	om.AddOrderListener(func(o models.Order) {
+		if !o.IsExternal() {
+			updates = append(updates, o.Status)
+		}
+	})
+	order, err := om.SubmitLimitOrder("MESH6", models.SideSell, 1, 5010)
+	if err != nil {
+		check(fmt.Sprintf("Limit order submits (Error: %v)", err), false)
+		return
+	}
+	check("An early Suspended is not replayed as the order's outcome", orderStatus(om, order.ID) == models.StatusSubmitted &&
+		fmt.Sprint(updates) == fmt.Sprint([]models.OrderStatus{models.StatusSubmitted}))
+	check("The early external order is folded in", om.ExternalOrderCount() == 0)
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"errors"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/risk"
+)
+
+// RunOrderThrottleTests executes all tests for the strategy order rate throttle.
+func RunOrderThrottleTests() {
+	testOrderThrottleInterval()
+	testOrderThrottlePerMinute()
+	testOrderThrottleViolations()
+	testOrderThrottleOff()
+}
+
+// newThrottleRig returns a risk manager using riskCfg's throttle settings and a
+// clock the test moves
+func newThrottleRig(riskCfg config.RiskConfig) (*risk.RiskManager, *time.Time) {
+	riskCfg.MaxContracts = 10
+	riskCfg.DailyLossLimit = 10000
+	riskCfg.EnableRiskChecks = true
+	om := execution.NewSimulatedOrderManager(execution.NewSimulatedExecutor(), &config.Config{Risk: riskCfg},
+		logger.NewLogger(10, logger.LevelDebug))
+	pt, _ := newLedgerTracker("")
+	om.SetPortfolioTracker(pt)
+
+	now := time.Date(2026, 1, 5, 16, 0, 0, 0, time.UTC)
+	rm := om.GetRiskManager()
+	rm.SetClock(func() time.Time { return now })
+	return rm, &now
+}
+
+// throttleOrder is a 1 lot MESH6 buy from origin
+func throttleOrder(origin string) *models.Order {
+	return &models.Order{Symbol: "MESH6", Side: models.SideBuy, Quantity: 1, Origin: origin}
+}
+
+func testOrderThrottleInterval() {
+	rm, now := newThrottleRig(config.RiskConfig{})
+	check("First strategy order passes", rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0) == nil)
+
+	*now = now.Add(4 * time.Second)
+	err := rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0)
+	check("Order inside the default 10s interval is refused", errors.Is(err, risk.ErrOrderThrottle))
+	check("Refusal is counted as a violation", rm.ThrottleViolations("sma-1") == 1)
+	check("Other strategies have their own interval", rm.CheckOrderRisk(throttleOrder("sma-2"), nil, 0) == nil)
+	check("Manual orders are not throttled", rm.CheckOrderRisk(throttleOrder(models.OriginManual), nil, 0) == nil)
+
+	*now = now.Add(6 * time.Second)
+	check("Order after the interval passes", rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0) == nil)
+}
+
+func testOrderThrottlePerMinute() {
+	rm, now := newThrottleRig(config.RiskConfig{MaxStrategyOrdersPerMinute: 3, MinOrderIntervalSeconds: -1})
+	for i := 0; i < 3; i++ {
+		check("Orders within the per-minute limit pass", rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0) == nil)
+		*now = now.Add(time.Second)
+	}
+	check("Fourth order in a minute is refused",
+		errors.Is(rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0), risk.ErrOrderThrottle))
+
+	*now = now.Add(58 * time.Second)
+	check("Orders older than a minute no longer count", rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0) == nil)
+}
+
+func testOrderThrottleViolations() {
+	rm, now := newThrottleRig(config.RiskConfig{MaxThrottleViolations: 2})
+	var calls []int
+	rm.AddThrottleHandler(func(origin string, violations int) {
+		if origin == "sma-1" {
+			calls = append(calls, violations)
+		}
+	})
+
+	rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0)
+	for i := 0; i < 4; i++ {
+		*now = now.Add(time.Second)
+		rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0)
+	}
+	check("All refusals are counted", rm.ThrottleViolations("sma-1") == 4)
+	check("Handler runs once when the violation limit is reached", len(calls) == 1 && calls[0] == 2)
+
+	rm.ResetOrderThrottle("sma-1")
+	check("Reset clears the violations", rm.ThrottleViolations("sma-1") == 0)
+	check("Reset clears the order history", rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0) == nil)
+	for i := 0; i < 2; i++ {
+		*now = now.Add(time.Second)
+		rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0)
+	}
+	check("Handler runs again after a reset", len(calls) == 2)
+}
+
+func testOrderThrottleOff() {
+	rm, _ := newThrottleRig(config.RiskConfig{MaxStrategyOrdersPerMinute: -1, MinOrderIntervalSeconds: -1})
+	passed := 0
+	for i := 0; i < 10; i++ {
+		if rm.CheckOrderRisk(throttleOrder("sma-1"), nil, 0) == nil {
+			passed++
+		}
+	}
+	check("Negative limits turn the throttle off", passed == 10 && rm.ThrottleViolations("sma-1") == 0)
+}
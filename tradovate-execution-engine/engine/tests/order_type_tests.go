@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunOrderTypeTests executes all tests for stop-limit orders and time in force.
+func RunOrderTypeTests() {
+	testOrderValidation()
+	testParseTimeInForce()
+	testStopLimitPlaceOrderRequest()
+	testMarketPlaceOrderOmitsTimeInForce()
+	testInvalidOrderRejectedBeforeSubmit()
+}
+
+func testOrderValidation() {
+	cases := []struct {
+		name   string
+		order  models.Order
+		market float64
+		valid  bool
+	}{
+		{"Day market order is valid", models.Order{Type: models.TypeMarket, Side: models.SideBuy, Quantity: 1}, 5000, true},
+		{"IOC market order is rejected as redundant", models.Order{Type: models.TypeMarket, Side: models.SideBuy, Quantity: 1, TimeInForce: models.TIFIOC}, 5000, false},
+		{"GTC limit order is valid", models.Order{Type: models.TypeLimit, Side: models.SideBuy, Quantity: 1, Price: 4990, TimeInForce: models.TIFGTC}, 5000, true},
+		{"Limit order without a price is rejected", models.Order{Type: models.TypeLimit, Side: models.SideBuy, Quantity: 1}, 5000, false},
+		{"Buy stop above the market is valid", models.Order{Type: models.TypeStop, Side: models.SideBuy, Quantity: 1, StopPrice: 5010}, 5000, true},
+		{"Buy stop below the market is rejected", models.Order{Type: models.TypeStop, Side: models.SideBuy, Quantity: 1, StopPrice: 4990}, 5000, false},
+		{"Buy stop-limit above the market is valid", models.Order{Type: models.TypeStopLimit, Side: models.SideBuy, Quantity: 1, StopPrice: 5010, Price: 5012}, 5000, true},
+		{"Sell stop-limit below the market is valid", models.Order{Type: models.TypeStopLimit, Side: models.SideSell, Quantity: 1, StopPrice: 4990, Price: 4988}, 5000, true},
+		{"Stop-limit without a limit price is rejected", models.Order{Type: models.TypeStopLimit, Side: models.SideBuy, Quantity: 1, StopPrice: 5010}, 5000, false},
+		{"Sell stop-limit with its limit above the market is rejected", models.Order{Type: models.TypeStopLimit, Side: models.SideSell, Quantity: 1, StopPrice: 4990, Price: 5005}, 5000, false},
+		{"Stop side is not checked without a market price", models.Order{Type: models.TypeStop, Side: models.SideBuy, Quantity: 1, StopPrice: 4990}, 0, true},
+		{"Unknown time in force is rejected", models.Order{Type: models.TypeLimit, Side: models.SideBuy, Quantity: 1, Price: 4990, TimeInForce: "FOK"}, 5000, false},
+		{"Zero quantity is rejected", models.Order{Type: models.TypeMarket, Side: models.SideBuy}, 5000, false},
+	}
+	for _, c := range cases {
+		err := c.order.Validate(c.market)
+		check(c.name, (err == nil) == c.valid)
+	}
+}
+
+func testParseTimeInForce() {
+	tif, err := models.ParseTimeInForce("gtc")
+	check("ParseTimeInForce accepts lower case", err == nil && tif == models.TIFGTC)
+	_, err = models.ParseTimeInForce("fok")
+	check("ParseTimeInForce rejects an unsupported value", err != nil)
+}
+
+// capturePlaceOrder returns a live order manager whose placeorder request bodies
+// are decoded into *body. The returned func shuts the server down.
+func capturePlaceOrder(body *map[string]interface{}) (*execution.OrderManager, func()) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(body)
+		fmt.Fprint(w, `{"orderId":7001}`)
+	}))
+
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500, EnableRiskChecks: true}}
+	tm := auth.NewTokenManager(cfg)
+	tm.SetSessionForTest(srv.URL, "test-token", 1)
+	om := execution.NewOrderManager(tm, cfg, logger.NewLogger(10, logger.LevelDebug))
+
+	return om, srv.Close
+}
+
+func testStopLimitPlaceOrderRequest() {
+	var body map[string]interface{}
+	om, cleanup := capturePlaceOrder(&body)
+	defer cleanup()
+
+	order, err := om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{
+		Type:        models.TypeStopLimit,
+		StopPrice:   5010.25,
+		Price:       5011,
+		TimeInForce: models.TIFGTC,
+	})
+	check("Stop-limit order is submitted", err == nil && order.Status == models.StatusSubmitted)
+	check("placeorder carries orderType StopLimit", body["orderType"] == "StopLimit")
+	check("placeorder carries both prices", body["stopPrice"] == 5010.25 && body["price"] == 5011.0)
+	check("placeorder carries timeInForce", body["timeInForce"] == "GTC")
+}
+
+func testMarketPlaceOrderOmitsTimeInForce() {
+	var body map[string]interface{}
+	om, cleanup := capturePlaceOrder(&body)
+	defer cleanup()
+
+	_, err := om.SubmitMarketOrder("MESH6", models.SideSell, 1)
+	_, hasTIF := body["timeInForce"]
+	_, hasPrice := body["price"]
+	check("Market order is submitted without timeInForce or price", err == nil && !hasTIF && !hasPrice)
+}
+
+func testInvalidOrderRejectedBeforeSubmit() {
+	sim := execution.NewSimulatedExecutor()
+	sim.SetMarket("MESH6", 5000, "2026-01-05T15:00:00Z")
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500, EnableRiskChecks: true}}
+	om := execution.NewSimulatedOrderManager(sim, cfg, logger.NewLogger(10, logger.LevelDebug))
+
+	order, err := om.SubmitOrder("MESH6", models.SideSell, 1, execution.OrderOptions{Type: models.TypeStop, StopPrice: 5005})
+	check("Sell stop above the simulator's price is rejected", err != nil && order.Status == models.StatusRejected)
+	check("Rejection names the side of the market", strings.Contains(order.RejectReason, "below the market"))
+	check("Rejected order never reached the simulator", len(sim.GetFills()) == 0)
+}
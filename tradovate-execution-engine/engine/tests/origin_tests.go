@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+)
+
+// RunOriginTests executes all tests for order origins and per-origin trade stats.
+func RunOriginTests() {
+	testOrderOrigins()
+	testTradeAttribution()
+	testOriginBreakdown()
+}
+
+func testOrderOrigins() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(20, logger.LevelWarn))
+	defer cleanup()
+
+	order, _ := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Orders with no strategy on the symbol are manual", order.Origin == models.OriginManual)
+
+	om.SetSymbolOrigin("MESH6", "2")
+	order, _ = om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Orders on a strategy's symbol are the strategy's", order.Origin == "2")
+	order, _ = om.Flatten("MESH6", models.SideSell, 2)
+	check("Flatten orders are attributed the same way", order.Origin == "2")
+	order, _ = om.SubmitOrder("MESH6", models.SideBuy, 1, execution.OrderOptions{Origin: models.OriginManual})
+	check("An origin given with the order wins", order.Origin == models.OriginManual)
+	order, _ = om.SubmitMarketOrder("MNQH6", models.SideBuy, 1)
+	check("Other symbols stay manual", order.Origin == models.OriginManual)
+
+	om.ClearSymbolOrigin("MESH6", "3")
+	order, _ = om.SubmitMarketOrder("MESH6", models.SideSell, 1)
+	check("Only the owning instance clears its symbol", order.Origin == "2")
+	om.ClearSymbolOrigin("MESH6", "2")
+	order, _ = om.SubmitMarketOrder("MESH6", models.SideSell, 1)
+	check("Orders are manual again once the strategy stops", order.Origin == models.OriginManual)
+}
+
+func testTradeAttribution() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(20, logger.LevelWarn))
+	defer cleanup()
+	pt, trading := newLedgerTracker("")
+	om.SetPortfolioTracker(pt)
+	trading.OnFillUpdate = func(data json.RawMessage) {
+		pt.RecordFill(data)
+		om.HandleFillEvent(data)
+	}
+	props := func(entityType, entity string) {
+		trading.HandleEvent("props", json.RawMessage(`{"entityType":"`+entityType+`","entity":`+entity+`}`))
+	}
+	fill := func(id int, orderID, action string, price float64, minute int) {
+		props("fill", fmt.Sprintf(`{"id":%d,"orderId":%s,"contractId":100,"timestamp":"2026-01-05T15:%02d:00Z","action":"%s","qty":1,"price":%.2f}`,
+			id, orderID, minute, action, price))
+	}
+
+	// Strategy 1 buys and a manual sell closes it: the trade is the strategy's
+	om.SetSymbolOrigin("MESH6", "1")
+	entry, _ := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	om.ClearSymbolOrigin("MESH6", "1")
+	exit, _ := om.SubmitMarketOrder("MESH6", models.SideSell, 1)
+	fill(1, entry.ExternalID, "Buy", 5000, 0)
+	fill(2, exit.ExternalID, "Sell", 5002, 1)
+	props("fillPair", `{"id":70,"positionId":7,"buyFillId":1,"sellFillId":2,"qty":1,"buyPrice":5000,"sellPrice":5002}`)
+
+	// A short opened from another platform
+	fill(3, "99001", "Sell", 5010, 2)
+	fill(4, "99002", "Buy", 5011, 3)
+	props("fillPair", `{"id":71,"positionId":7,"buyFillId":4,"sellFillId":3,"qty":1,"buyPrice":5011,"sellPrice":5010}`)
+
+	trades := pt.GetClosedTrades()
+	check("Both trades are in the ledger", len(trades) == 2)
+	if len(trades) != 2 {
+		return
+	}
+	check("Trade takes the origin of its opening order", trades[0].Origin == "1")
+	check("Orders placed outside the engine are external", trades[1].Origin == models.OriginExternal)
+	check("External orders are counted", om.ExternalOrderCount() == 2)
+	check("Trade line names its origin", strings.HasSuffix(trades[0].String(), "  1"))
+
+	stats := pt.GetOriginStats("1")
+	check("Strategy stats come from its trades", stats.Trades == 1 && stats.Wins == 1 && stats.WinRate == 100)
+	assertEqualsFloat("Strategy realized PnL", 10, stats.RealizedPnL, 0.0001)
+	check("Origins without trades are empty", pt.GetOriginStats("5") == portfolio.OriginStats{Origin: "5"})
+}
+
+func testOriginBreakdown() {
+	trades := []portfolio.ClosedTrade{
+		{ID: 1, PnL: 20, Origin: "10"},
+		{ID: 2, PnL: -5, Origin: "2"},
+		{ID: 3, PnL: 15, Origin: "2"},
+		{ID: 4, PnL: -7, Origin: models.OriginManual},
+		{ID: 5, PnL: 3},
+	}
+	orders := []*models.Order{{Origin: "2"}, {Origin: "2"}, {Origin: "10"}, {Origin: models.OriginManual}}
+	stats := portfolio.OriginBreakdown(trades, orders, 3)
+
+	var names []string
+	for _, s := range stats {
+		names = append(names, s.Origin)
+	}
+	check(fmt.Sprintf("Strategies by instance ID, then manual and external (got %v)", names),
+		fmt.Sprint(names) == "[2 10 manual external]")
+	if len(stats) != 4 {
+		return
+	}
+	check("Orders and trades are counted per origin", stats[0].Orders == 2 && stats[0].Trades == 2 && stats[0].Wins == 1)
+	assertEqualsFloat("Win rate", 50, stats[0].WinRate, 0.0001)
+	assertEqualsFloat("Realized PnL per origin", 10, stats[0].RealizedPnL, 0.0001)
+	check("External orders without trades are listed", stats[3].Orders == 3 && stats[3].Trades == 0)
+
+	report := portfolio.BuildSessionReport(portfolio.SessionSnapshot{Trades: trades, ExternalOrders: 3}, orders, nil)
+	check("Session report breaks results down by origin", len(report.Origins) == 4 &&
+		strings.Contains(report.Text(), "strategy 2   | Orders:   2 | Trades:   2 | Win rate:  50.0% | Realized: $    10.00"))
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunPositionAlertTests executes all tests for the per-position unrealized loss alerts.
+func RunPositionAlertTests() {
+	testPositionAlertLevels()
+	testPositionAlertClearsOnClose()
+	testPositionAlertConfig()
+}
+
+// newAlertTracker returns a tracker holding long 2 MESH6 at 5000 ($10 a point)
+// with alerts at -$100 and -$200, and a func that sends it a trade at price
+func newAlertTracker(log *logger.Logger) (*portfolio.PortfolioTracker, *tradovate.DataSubscriber, func(price float64)) {
+	trading := tradovate.NewDataSubscriptionManager(nullSender{})
+	md := tradovate.NewDataSubscriptionManager(nullSender{})
+	pt := portfolio.NewPortfolioTracker(trading, md, 1, 0, log)
+	pt.Start("demo")
+	pt.SetPositionLossAlerts(100, 200)
+	trading.HandleEvent("user/syncrequest", json.RawMessage(`{"users":[{"id":1}],
+		"positions":[{"id":7,"accountId":1,"contractId":100,"netPos":2,"netPrice":5000}],
+		"contracts":[{"id":100,"name":"MESH6"}],
+		"products":[{"name":"MES","valuePerPoint":5}]}`))
+	trade := func(price float64) {
+		md.HandleEvent(marketdata.EventMarketData, json.RawMessage(fmt.Sprintf(
+			`{"quotes":[{"contractId":100,"entries":{"Trade":{"price":%g,"size":1}}}]}`, price)))
+	}
+	return pt, trading, trade
+}
+
+// activeLevel returns the active alert level of symbol
+func activeLevel(pt *portfolio.PortfolioTracker, symbol string) portfolio.AlertLevel {
+	for _, a := range pt.ActivePositionAlerts() {
+		if a.Symbol == symbol {
+			return a.Level
+		}
+	}
+	return portfolio.AlertNone
+}
+
+func testPositionAlertLevels() {
+	pt, _, trade := newAlertTracker(logger.NewLogger(50, logger.LevelDebug))
+
+	trade(4995)
+	check("No alert above the warning level", len(pt.PositionAlertsSince(0)) == 0 && activeLevel(pt, "MESH6") == portfolio.AlertNone)
+
+	trade(4990)
+	alerts := pt.PositionAlertsSince(0)
+	check("Crossing the warning level alerts", len(alerts) == 1 && alerts[0].Level == portfolio.AlertWarning &&
+		alerts[0].Symbol == "MESH6" && alerts[0].PnL == -100 && alerts[0].Threshold == 100)
+	trade(4988)
+	check("Staying past the level does not alert again", len(pt.PositionAlertsSince(0)) == 1)
+
+	trade(4979)
+	alerts = pt.PositionAlertsSince(alerts[0].Seq)
+	check("Crossing the critical level alerts", len(alerts) == 1 && alerts[0].Level == portfolio.AlertCritical)
+	check("Critical is the active level", activeLevel(pt, "MESH6") == portfolio.AlertCritical)
+
+	trade(4985)
+	check("Recovering to the warning level does not alert", len(pt.PositionAlertsSince(0)) == 2 &&
+		activeLevel(pt, "MESH6") == portfolio.AlertWarning)
+	trade(4979)
+	check("Crossing critical again after recovering alerts again", len(pt.PositionAlertsSince(0)) == 3)
+
+	trade(5000)
+	check("Recovering above every level clears the alert", activeLevel(pt, "MESH6") == portfolio.AlertNone)
+	trade(4990)
+	check("Warning alerts again after recovering", len(pt.PositionAlertsSince(0)) == 4)
+}
+
+func testPositionAlertClearsOnClose() {
+	pt, trading, trade := newAlertTracker(logger.NewLogger(50, logger.LevelDebug))
+	trade(4970)
+	check("Alert is active while the position is open", activeLevel(pt, "MESH6") == portfolio.AlertCritical)
+
+	trading.HandleEvent("props", json.RawMessage(`{"entityType":"position","entity":{"id":7,"accountId":1,"contractId":100,"netPos":0,"netPrice":0}}`))
+	check("Alert clears when the position closes", len(pt.ActivePositionAlerts()) == 0)
+	check("Feed keeps the past alerts", len(pt.PositionAlertsSince(0)) == 1)
+}
+
+func testPositionAlertConfig() {
+	pt, _, trade := newAlertTracker(logger.NewLogger(50, logger.LevelDebug))
+	pt.SetPositionLossAlerts(0, 200)
+	trade(4985)
+	check("Warning level 0 is off", len(pt.PositionAlertsSince(0)) == 0)
+	trade(4975)
+	check("Critical still alerts with the warning off", activeLevel(pt, "MESH6") == portfolio.AlertCritical)
+
+	check("Negative alert levels are rejected", config.RiskConfig{PositionLossWarning: -100}.Validate() != nil)
+	check("Critical below warning is rejected",
+		config.RiskConfig{PositionLossWarning: 200, PositionLossCritical: 100}.Validate() != nil)
+	check("Critical above warning is accepted",
+		config.RiskConfig{PositionLossWarning: 100, PositionLossCritical: 200}.Validate() == nil)
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/strategies"
+)
+
+// RunPositionDriftTests executes all tests for position reconciliation
+func RunPositionDriftTests() {
+	testSyncPositionAfterMissedFill()
+	testPositionDriftAfterReconnect()
+}
+
+func testSyncPositionAfterMissedFill() {
+	risk := config.RiskConfig{MaxContracts: 2, DailyLossLimit: 500, EnableRiskChecks: true}
+	synced, syncedSim, err := newSimulatedCrossover(risk, "1")
+	stale, staleSim, err2 := newSimulatedCrossover(risk, "1")
+	if err != nil || err2 != nil {
+		check("Simulated strategies initialize", false)
+		return
+	}
+	var _ execution.PositionSyncer = synced
+	var _ execution.PositionSyncer = strategies.NewDonchianBreakout("MESH6", 3, true)
+
+	// Both go long on the cross above, then a stop fills while disconnected and
+	// the strategies never hear of it
+	for _, run := range []struct {
+		strategy *strategies.MACrossover
+		sim      *execution.SimulatedExecutor
+	}{{synced, syncedSim}, {stale, staleSim}} {
+		feedBars(run.strategy, run.sim, 10, 10, 10, 10, 10, 11)
+		run.sim.Execute(&models.Order{Symbol: "MESH6", Side: models.SideSell, Quantity: 1, Type: models.TypeMarket})
+	}
+	held, pending := synced.StrategyPosition()
+	check("Strategy still declares the position the missed fill closed", held == 1 && !pending && syncedSim.GetPosition("MESH6").NetPos == 0)
+
+	synced.SyncPosition(syncedSim.GetPosition("MESH6").NetPos)
+	held, _ = synced.StrategyPosition()
+	check("SyncPosition takes the broker's position", held == 0 && synced.GetPosition() == strategies.Flat)
+
+	feedBars(synced, syncedSim, 14, 16, 14, 12)
+	feedBars(stale, staleSim, 14, 16, 14, 12)
+	check("A synced strategy enters with its quantity", syncedSim.GetPosition("MESH6").NetPos == -1)
+	check("A drifted strategy reverses a position it no longer has", staleSim.GetPosition("MESH6").NetPos == -2)
+
+	synced.SyncPosition(-3)
+	held, _ = synced.StrategyPosition()
+	check("A size the strategy does not trade is not held", held == -1 && synced.GetPosition() == strategies.Short)
+}
+
+func testPositionDriftAfterReconnect() {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, logger.NewLogger(100, logger.LevelWarn))
+	// The test's orders go out under the strategy's origin, so they are not throttled
+	cfg := &config.Config{Risk: config.RiskConfig{DailyLossLimit: 500, AutoSyncPositions: true,
+		MaxStrategyOrdersPerMinute: -1, MinOrderIntervalSeconds: -1}}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Disconnect()
+
+	var mu sync.Mutex
+	var events []string
+	e.AddEventHandler(func(ev app.Event) {
+		if ev.Kind == app.EventPositionDrift {
+			mu.Lock()
+			events = append(events, ev.Message)
+			mu.Unlock()
+		}
+	})
+	eventCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events)
+	}
+
+	// Bars too large to close during the test keep the strategy's own orders out of it
+	inst, err := e.AddStrategy("ma_crossover", map[string]string{"fast_length": "2", "slow_length": "3", "update_mode": "1",
+		"bar_type": "volume", "bar_size": "1000000"})
+	if err != nil {
+		check(fmt.Sprintf("Strategy is added (Error: %v)", err), false)
+		return
+	}
+	if err := e.StartStrategy(inst.ID); err != nil {
+		check(fmt.Sprintf("Strategy starts (Error: %v)", err), false)
+		return
+	}
+	waitFor(func() bool { return inst.Runtime.Status() == app.StrategyRunning })
+	syncer := inst.Strategy().(execution.PositionSyncer)
+	symbol := inst.Symbol()
+
+	trade := func(side models.OrderSide, qty, netPos int) bool {
+		e.MarketData().SubscribeQuote(symbol)
+		for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if _, err = e.OrderManager().SubmitMarketOrder(symbol, side, qty); err == nil {
+				break
+			}
+		}
+		return err == nil && waitFor(func() bool { return e.Portfolio().GetPLSummary()[symbol].NetPos == netPos })
+	}
+
+	// The strategy restarts flat; a fill arrives before it is running again
+	if err := e.Reconnect(); err != nil {
+		check(fmt.Sprintf("Demo reconnects (Error: %v)", err), false)
+		return
+	}
+	check("Strategy is restarted by the reconnect", waitFor(func() bool { return inst.Runtime.Status() == app.StrategyRunning }))
+	check("Fill the strategy never saw", trade(models.SideBuy, 1, 1))
+
+	now := time.Now()
+	check("No pass before the user sync has settled", e.CheckPositionDrift(now) == nil)
+	check("A drift is suspected on the first pass after connecting", len(e.CheckPositionDrift(now.Add(6*time.Second))) == 0)
+	drifts := e.CheckPositionDrift(now.Add(11 * time.Second))
+	check("It is acted on when still there on the next pass", len(drifts) == 1 && drifts[0].StrategyID == inst.ID &&
+		drifts[0].Strategy == 0 && drifts[0].Orders == 1 && drifts[0].Broker == 1)
+	held, _ := syncer.StrategyPosition()
+	check("Auto sync corrects the strategy's position", held == 1 && !inst.Runtime.IsDrifted())
+	check("The correction is logged and reported", logged(inst.Log, "Position synced to the broker") && eventCount() == 1)
+	check("Passes wait for the check interval", e.CheckPositionDrift(now.Add(20*time.Second)) == nil)
+
+	// Without auto sync the strategy waits for the operator
+	cfg.Risk.AutoSyncPositions = false
+	e.ApplyConfig(cfg)
+	check("Position closed and reversed elsewhere", trade(models.SideSell, 2, -1))
+	e.CheckPositionDrift(now.Add(45 * time.Second))
+	drifts = e.CheckPositionDrift(now.Add(50 * time.Second))
+	held, _ = syncer.StrategyPosition()
+	check("A drift pauses the strategy", len(drifts) == 1 && inst.Runtime.IsDrifted() && held == 1)
+	check("The pause is logged", logged(inst.Log, "strategy paused until :resync "+inst.ID))
+	check("A paused strategy is not checked again", e.CheckPositionDrift(now.Add(90*time.Second)) == nil)
+
+	netPos, err := e.ResyncPosition(inst.ID)
+	held, _ = syncer.StrategyPosition()
+	check("Resync takes the broker's position", err == nil && netPos == -1 && held == -1 && !inst.Runtime.IsDrifted())
+	_, err = e.ResyncPosition(inst.ID)
+	check("Resync needs a strategy paused for a drift", err != nil)
+
+	// A size the strategy does not trade cannot be synced
+	check("Position added to elsewhere", trade(models.SideBuy, 3, 2))
+	cfg.Risk.AutoSyncPositions = true
+	e.ApplyConfig(cfg)
+	e.CheckPositionDrift(now.Add(125 * time.Second))
+	e.CheckPositionDrift(now.Add(130 * time.Second))
+	check("Auto sync pauses a strategy that cannot hold the position", inst.Runtime.IsDrifted())
+	_, err = e.ResyncPosition(inst.ID)
+	check("Resync is refused until the position is resized", err != nil && inst.Runtime.IsDrifted())
+	check("Position resized by hand", trade(models.SideSell, 1, 1))
+	netPos, err = e.ResyncPosition(inst.ID)
+	check("Resync succeeds once the strategy can hold it", err == nil && netPos == 1 && !inst.Runtime.IsDrifted())
+
+	check("Strategy stops", e.StopStrategy(inst.ID) == nil)
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunPropsRoutingTests executes all tests for routing props entities to their callbacks.
+func RunPropsRoutingTests() {
+	testPropsEntitiesRouted()
+	testUnknownPropsCounted()
+	testDecodeExecutionReport()
+	testExecutionReportPartialFill()
+	testExecutionReportOutOfOrder()
+}
+
+// propsEvent wraps an entity in a props event of entityType
+func propsEvent(entityType, entity string) json.RawMessage {
+	return json.RawMessage(`{"entityType":"` + entityType + `","eventType":"Updated","entity":` + entity + `}`)
+}
+
+func testPropsEntitiesRouted() {
+	ds := tradovate.NewDataSubscriptionManager(nullSender{})
+	got := make(map[string]string)
+	record := func(name string) func(json.RawMessage) {
+		return func(data json.RawMessage) { got[name] = string(data) }
+	}
+	ds.OnOrderUpdate = record("order")
+	ds.OnFillUpdate = record("fill")
+	ds.OnExecutionReport = record("executionReport")
+	ds.OnFillPairUpdate = record("fillPair")
+	ds.OnPositionUpdate = record("position")
+	ds.OnCashBalanceUpdate = record("cashBalance")
+	ds.OnMarginSnapshot = record("marginSnapshot")
+
+	samples := map[string]string{
+		"order":           `{"id":301,"accountId":1,"contractId":100,"action":"Buy","ordStatus":"Working","timestamp":"2026-01-05T15:00:00.000Z"}`,
+		"fill":            `{"id":401,"orderId":301,"contractId":100,"timestamp":"2026-01-05T15:00:01.000Z","action":"Buy","qty":1,"price":5000.25,"active":true}`,
+		"executionReport": `{"id":501,"commandId":9,"accountId":1,"contractId":100,"orderId":301,"execType":"Trade","ordStatus":"PartiallyFilled","action":"Buy","cumQty":1,"avgPx":5000.25,"lastQty":1,"lastPx":5000.25,"timestamp":"2026-01-05T15:00:01.000Z"}`,
+		"fillPair":        `{"id":601,"positionId":7,"buyFillId":401,"sellFillId":402,"qty":1,"buyPrice":5000.25,"sellPrice":5002.5,"active":true}`,
+		"position":        `{"id":7,"accountId":1,"contractId":100,"netPos":1,"netPrice":5000.25}`,
+		"cashBalance":     `{"id":1,"accountId":1,"timestamp":"2026-01-05T15:00:02.000Z","tradeDate":{"year":2026,"month":1,"day":5},"amount":50011.25,"realizedPnL":11.25}`,
+		"marginSnapshot":  `{"id":1,"timestamp":"2026-01-05T15:00:02.000Z","initialMargin":800,"maintenanceMargin":700,"autoLiqLevel":400}`,
+	}
+	for entityType, entity := range samples {
+		ds.HandleEvent("props", propsEvent(entityType, entity))
+	}
+	for entityType, entity := range samples {
+		check("props "+entityType+" reaches its callback", got[entityType] == entity)
+	}
+	check("Routed entities are not counted as unknown", len(ds.GetUnknownPropsStats()) == 0)
+}
+
+func testUnknownPropsCounted() {
+	log := logger.NewLogger(50, logger.LevelDebug)
+	ds := tradovate.NewDataSubscriptionManager(nullSender{})
+	ds.SetLogger(log)
+
+	report := `{"id":9,"commandId":9,"timestamp":"2026-01-05T15:00:00.000Z","commandStatus":"AtExecution"}`
+	for i := 0; i < 3; i++ {
+		ds.HandleEvent("props", propsEvent("commandReport", report))
+	}
+	ds.HandleEvent("props", propsEvent("contractMaturity", `{"id":1}`))
+
+	stats := ds.GetUnknownPropsStats()
+	check("Unknown entity types are counted", stats["commandReport"] == 3 && stats["contractMaturity"] == 1)
+	logged := log.ExportToString()
+	check("Each unknown entity type is logged once",
+		strings.Count(logged, `"commandReport"`) == 1 && strings.Count(logged, `"contractMaturity"`) == 1)
+
+	stats["commandReport"] = 0
+	check("Stats are a copy", ds.GetUnknownPropsStats()["commandReport"] == 3)
+}
+
+func testDecodeExecutionReport() {
+	report, err := tradovate.DecodeExecutionReport(json.RawMessage(
+		`{"id":"502","orderId":"302","contractId":100,"execType":"Trade","ordStatus":"Filled","action":"Sell","cumQty":"2","avgPx":"5001.5","lastQty":1,"lastPx":5002,"timestamp":1767625200000}`))
+	check("Execution report decodes", err == nil)
+	check("Execution report fields are typed",
+		report.ID == 502 && report.OrderID == 302 && report.ContractID == 100 && report.ExecType == "Trade" && report.OrdStatus == "Filled")
+	check("Execution report quantities and prices are read",
+		report.CumQty == 2 && report.LastQty == 1 && report.AvgPx == 5001.5 && report.LastPx == 5002)
+	check("Execution report timestamp is read", report.HasTimestamp && report.Timestamp.Unix() == 1767625200)
+
+	wrapped, err := tradovate.DecodeExecutionReport(propsEvent("executionReport", `{"orderId":303,"execType":"New","ordStatus":"Working"}`))
+	check("Whole props event decodes", err == nil && wrapped.OrderID == 303 && !wrapped.HasTimestamp)
+
+	_, err = tradovate.DecodeExecutionReport(json.RawMessage(`{"id":504,"execType":"Trade"}`))
+	check("Execution report without an order ID is an error", err != nil)
+}
+
+func testExecutionReportPartialFill() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(20, logger.LevelWarn))
+	defer cleanup()
+
+	om.HandleOrderEvent(json.RawMessage(`{"id":99101,"contractId":100,"action":"Buy","orderType":"Limit","ordStatus":"Working","qty":2,"price":5000}`))
+	orders := externalOrders(om)
+	if len(orders) != 1 {
+		check("Working order is tracked", false)
+		return
+	}
+	id := orders[0].ID
+
+	om.HandleExecutionReport(json.RawMessage(`{"id":701,"orderId":99101,"execType":"Trade","ordStatus":"PartiallyFilled","action":"Buy","cumQty":1,"lastQty":1,"lastPx":5000}`))
+	got, _ := om.GetOrder(id)
+	check("Trade report marks the order partially filled", got.Status == models.StatusPartiallyFilled)
+	check("Report does not record the fill itself", got.FilledQty() == 0)
+
+	om.HandleFillEvent(json.RawMessage(`{"id":801,"orderId":99101,"action":"Buy","qty":1,"price":5000}`))
+	om.HandleFillEvent(json.RawMessage(`{"id":802,"orderId":99101,"action":"Buy","qty":1,"price":5001}`))
+	got, _ = om.GetOrder(id)
+	check("Fill entities complete the order once", got.Status == models.StatusFilled && got.FilledQty() == 2)
+
+	om.HandleOrderEvent(json.RawMessage(`{"id":99102,"contractId":100,"action":"Sell","orderType":"Limit","ordStatus":"Working","qty":1,"price":5100}`))
+	om.HandleExecutionReport(json.RawMessage(`{"orderId":99102,"execType":"Rejected","ordStatus":"Rejected","rejectReason":"InsufficientMargin"}`))
+	var rejected models.Order
+	for _, o := range externalOrders(om) {
+		if o.ExternalID == "99102" {
+			rejected = o
+		}
+	}
+	check("Rejected report rejects the order with its reason",
+		rejected.Status == models.StatusRejected && rejected.RejectReason != "" && rejected.RejectReason != "rejected by exchange")
+}
+
+func testExecutionReportOutOfOrder() {
+	om, _, cleanup := newOCOOrderManager(logger.NewLogger(20, logger.LevelWarn))
+	defer cleanup()
+
+	om.HandleOrderEvent(json.RawMessage(`{"id":99201,"contractId":100,"action":"Buy","orderType":"Market","ordStatus":"Filled","qty":2}`))
+	om.HandleExecutionReport(json.RawMessage(`{"orderId":99201,"execType":"Trade","ordStatus":"PartiallyFilled","cumQty":1,"lastQty":1,"lastPx":5000}`))
+	orders := externalOrders(om)
+	check("Late partial fill report does not reopen a filled order", len(orders) == 1 && orders[0].Status == models.StatusFilled)
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunQuoteParsingTests executes all tests for parsing quotes and their typed accessors
+func RunQuoteParsingTests() {
+	testParseFullQuote()
+	testImpliedBidOffer()
+	testTolerantQuoteEntries()
+	testPreOpenQuote()
+}
+
+// parseQuote parses an "md" payload with one quote, captured from Tradovate
+func parseQuote(payload string) (marketdata.Quote, bool) {
+	data, err := marketdata.ParseQuoteData(json.RawMessage(payload))
+	if err != nil || len(data.Quotes) != 1 {
+		check(fmt.Sprintf("Quote payload parses (Error: %v)", err), false)
+		return marketdata.Quote{}, false
+	}
+	return data.Quotes[0], true
+}
+
+func testParseFullQuote() {
+	q, ok := parseQuote(`{"quotes":[{"timestamp":"2026-03-02T15:30:00.123Z","contractId":3570918,"entries":{
+		"Bid":{"price":5012.25,"size":41},
+		"Offer":{"price":5012.5,"size":37},
+		"Trade":{"price":5012.5,"size":2},
+		"TotalTradeVolume":{"size":184220},
+		"SettlementPrice":{"price":4998.75},
+		"HighPrice":{"price":5020},
+		"LowPrice":{"price":4991.5},
+		"OpeningPrice":{"price":4999.25}}}]}`)
+	if !ok {
+		return
+	}
+	check("Quote keeps its contract and timestamp", q.ContractID == 3570918 && q.Timestamp == "2026-03-02T15:30:00.123Z")
+
+	bid, hasBid := q.BestBid()
+	ask, hasAsk := q.BestAsk()
+	check("BestBid has the bid's price and size", hasBid && bid.Price == 5012.25 && bid.Size == 41)
+	check("BestAsk has the offer's price and size", hasAsk && ask.Price == 5012.5 && ask.Size == 37)
+	b, a, ok := q.BestBidOffer()
+	check("BestBidOffer returns both sides", ok && b == bid && a == ask)
+	spread, ok := q.Spread()
+	check("Spread is the offer less the bid", ok && spread == 0.25)
+
+	trade, ok := q.LastTrade()
+	check("LastTrade has the trade's price and size", ok && trade.Price == 5012.5 && trade.Size == 2)
+	volume, ok := q.TotalVolume()
+	check("TotalVolume comes from the size-only entry", ok && volume == 184220)
+	settle, ok := q.Settlement()
+	check("Settlement has no size", ok && settle.Price == 4998.75 && settle.Size == 0)
+}
+
+func testImpliedBidOffer() {
+	q, ok := parseQuote(`{"quotes":[{"contractId":1,"entries":{
+		"Bid":{"price":5012.25,"size":41},"ImpliedBid":{"price":5012.5,"size":3},
+		"Offer":{"price":5012.75,"size":37},"ImpliedOffer":{"price":5013,"size":5}}}]}`)
+	if !ok {
+		return
+	}
+	bid, _ := q.BestBid()
+	ask, _ := q.BestAsk()
+	check("A higher implied bid is the best bid", bid.Price == 5012.5 && bid.Size == 3)
+	check("A worse implied offer is ignored", ask.Price == 5012.75 && ask.Size == 37)
+
+	q, ok = parseQuote(`{"quotes":[{"contractId":1,"entries":{
+		"Bid":{"price":5012.25,"size":41},"ImpliedBid":{"price":5012.25,"size":4},
+		"ImpliedOffer":{"price":5012.5,"size":6}}}]}`)
+	if !ok {
+		return
+	}
+	bid, _ = q.BestBid()
+	ask, hasAsk := q.BestAsk()
+	check("Implied size at the bid's price is added to it", bid.Price == 5012.25 && bid.Size == 45)
+	check("An implied offer stands in for a missing offer", hasAsk && ask.Price == 5012.5 && ask.Size == 6)
+}
+
+func testTolerantQuoteEntries() {
+	q, ok := parseQuote(`{"quotes":[{"contractId":1,"entries":{
+		"Bid":{"price":5012.25},"Offer":{"price":5012.5,"size":37},
+		"Trade":null,"ImpliedBid":"5013","ImpliedOffer":[]}}]}`)
+	if !ok {
+		return
+	}
+	bid, hasBid := q.BestBid()
+	check("An entry without a size parses with size 0", hasBid && bid.Price == 5012.25 && bid.Size == 0)
+	_, hasTrade := q.LastTrade()
+	_, hasImplied := q.Entries[marketdata.EntryImpliedBid]
+	check("Null and malformed entries are dropped", !hasTrade && !hasImplied && len(q.Entries) == 2)
+	ask, _ := q.BestAsk()
+	check("Malformed implied entries do not change the book", ask.Price == 5012.5)
+
+	_, err := marketdata.ParseQuoteData(json.RawMessage(`{"quotes":{"contractId":1}}`))
+	check("A payload whose quotes are not a list is an error", err != nil)
+
+	// The subscriber still dispatches a quote with a bad entry
+	md := tradovate.NewDataSubscriptionManager(nullSender{})
+	var got []marketdata.Quote
+	md.AddQuoteHandler(func(q marketdata.Quote) { got = append(got, q) })
+	md.HandleEvent(marketdata.EventMarketData, json.RawMessage(`{"quotes":[{"contractId":1,"entries":{"Trade":{"price":5012.5,"size":1},"Bid":null}}]}`))
+	check("Quote with a null entry is still dispatched", len(got) == 1 && got[0].Entries["Trade"].Price == 5012.5)
+}
+
+func testPreOpenQuote() {
+	q, ok := parseQuote(`{"quotes":[{"timestamp":"2026-03-01T22:45:00Z","contractId":1,"entries":{
+		"SettlementPrice":{"price":4998.75},"Bid":{},"Offer":{},"TotalTradeVolume":{}}}]}`)
+	if !ok {
+		return
+	}
+	_, hasBid := q.BestBid()
+	_, hasAsk := q.BestAsk()
+	_, hasTrade := q.LastTrade()
+	_, hasVolume := q.TotalVolume()
+	check("A pre-open quote has no bid, offer, trade or volume", !hasBid && !hasAsk && !hasTrade && !hasVolume)
+	_, hasSpread := q.Spread()
+	_, _, hasBBO := q.BestBidOffer()
+	check("A pre-open quote has no spread", !hasSpread && !hasBBO)
+	settle, ok := q.Settlement()
+	check("A pre-open quote carries the settlement price", ok && settle.Price == 4998.75)
+
+	bars := 0
+	b := marketdata.NewBarBuilderFor(marketdata.BarSpec{Type: marketdata.BarTypeTick, Size: 1}, func(marketdata.Bar) { bars++ })
+	b.OnQuote(q)
+	check("A pre-open quote does not count as a trade", bars == 0)
+	if open, ok := parseQuote(`{"quotes":[{"timestamp":"2026-03-01T23:00:00Z","contractId":1,"entries":{"Trade":{"price":4999,"size":1}}}]}`); ok {
+		b.OnQuote(open)
+		check("The first trade after the open does", bars == 1)
+	}
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// RunQuoteQueueTests executes all tests for quote delivery to tick-driven strategies.
+func RunQuoteQueueTests() {
+	testQuoteQueueDropsOldest()
+	testQuoteQueueStop()
+	testQuoteQueueErrors()
+	testUpdateModeFor()
+}
+
+// quoteRecorder is a QuoteHandler that records trade prices. While gate is set
+// each OnQuote waits for it to be closed.
+type quoteRecorder struct {
+	barRecorder
+	mu     sync.Mutex
+	prices []float64
+	gate   chan struct{}
+	fail   bool
+}
+
+func (r *quoteRecorder) OnQuote(q marketdata.Quote) error {
+	if r.gate != nil {
+		<-r.gate
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prices = append(r.prices, q.Entries["Trade"].Price)
+	if r.fail {
+		return errors.New("quote refused")
+	}
+	return nil
+}
+
+func (r *quoteRecorder) seen() []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]float64(nil), r.prices...)
+}
+
+// waitFor polls cond for up to a second
+func waitFor(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return cond()
+}
+
+// modeStrategy is a QuoteHandler that declares its UpdateMode
+type modeStrategy struct {
+	quoteRecorder
+	mode execution.UpdateMode
+}
+
+func (s *modeStrategy) UpdateMode() execution.UpdateMode { return s.mode }
+
+func testQuoteQueueDropsOldest() {
+	rec := &quoteRecorder{gate: make(chan struct{})}
+	q := execution.NewQuoteQueue(rec, 3, nil)
+	defer q.Stop()
+
+	// The first quote is taken by the handler and held at the gate
+	q.Offer(tradeQuote(1))
+	time.Sleep(20 * time.Millisecond)
+
+	dropped := 0
+	for price := 2.0; price <= 6; price++ {
+		if q.Offer(tradeQuote(price)) {
+			dropped++
+		}
+	}
+	check("Offer never blocks on a stalled handler and reports drops", dropped == 2 && q.Dropped() == 2)
+
+	close(rec.gate)
+	check("The newest quotes are kept, oldest first", waitFor(func() bool { return closesEqual(rec.seen(), 1, 4, 5, 6) }))
+}
+
+func testQuoteQueueStop() {
+	rec := &quoteRecorder{gate: make(chan struct{})}
+	q := execution.NewQuoteQueue(rec, 4, nil)
+	q.Offer(tradeQuote(1))
+	time.Sleep(20 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		q.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		check("Stop waits for the quote being handled", false)
+	case <-time.After(20 * time.Millisecond):
+		check("Stop waits for the quote being handled", true)
+	}
+	close(rec.gate)
+	<-stopped
+
+	check("Quotes offered after Stop are ignored", !q.Offer(tradeQuote(2)) && closesEqual(rec.seen(), 1))
+	q.Stop()
+	check("Stop can be called twice", true)
+}
+
+func testQuoteQueueErrors() {
+	rec := &quoteRecorder{fail: true}
+	var mu sync.Mutex
+	var errs []error
+	q := execution.NewQuoteQueue(rec, 4, func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+	defer q.Stop()
+
+	q.Offer(tradeQuote(1))
+	check("OnQuote errors are passed to onError", waitFor(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) == 1
+	}))
+}
+
+func testUpdateModeFor() {
+	check("Strategies without OnQuote take bars", execution.UpdateModeFor(&barRecorder{}) == execution.UpdateOnBarClose)
+	check("A QuoteHandler takes quotes and bars by default", execution.UpdateModeFor(&quoteRecorder{}) == execution.UpdateOnEachTick)
+	check("A declared UpdateMode is used", execution.UpdateModeFor(&modeStrategy{mode: execution.UpdateTicksOnly}) == execution.UpdateTicksOnly)
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunQuoteSubscriptionTests executes all tests for per-symbol quote subscriptions.
+func RunQuoteSubscriptionTests() {
+	testQuoteBatchUnsubscribeSingle()
+	testQuoteReferencesPerSymbol()
+	testQuoteUnsubscribeCoalesced()
+	testQuoteUnsubscribeAllCoalesced()
+	testPortfolioQuoteSubscriptions()
+}
+
+// newQuoteSubscriber returns a subscriber recording what it sends
+func newQuoteSubscriber() (*tradovate.DataSubscriber, *recordingSender) {
+	sender := &recordingSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+	ds.SetLogger(logger.NewLogger(10, logger.LevelWarn))
+	return ds, sender
+}
+
+// quoteMessage reports whether msg is a request to url for exactly symbol, a
+// single value or a list
+func quoteMessage(msg sentMessage, url string, symbol interface{}) bool {
+	body, ok := msg.body.(map[string]interface{})
+	return ok && msg.url == url && reflect.DeepEqual(body["symbol"], symbol)
+}
+
+// quoteRefs returns the reference count of each quote subscription by symbol
+func quoteRefs(ds *tradovate.DataSubscriber) map[interface{}]int {
+	refs := make(map[interface{}]int)
+	for _, info := range ds.GetActiveSubscriptions() {
+		if info.Endpoint == "md/subscribequote" {
+			refs[info.Params["symbol"]] = info.RefCount
+		}
+	}
+	return refs
+}
+
+func testQuoteBatchUnsubscribeSingle() {
+	ds, sender := newQuoteSubscriber()
+
+	check("Batch subscribe succeeds", ds.SubscribeQuotes([]string{"MESH6", "MNQH6", "MESH6"}) == nil)
+	check("Batch is sent as one request listing each symbol once",
+		len(sender.sent) == 1 && quoteMessage(sender.sent[0], "md/subscribequote", []interface{}{"MESH6", "MNQH6"}))
+	refs := quoteRefs(ds)
+	check("Each symbol of the batch is tracked on its own", len(refs) == 2 && refs["MESH6"] == 1 && refs["MNQH6"] == 1)
+
+	check("Unsubscribing one symbol of the batch succeeds", ds.UnsubscribeQuote("MESH6") == nil)
+	check("Only that symbol is unsubscribed",
+		len(sender.sent) == 2 && quoteMessage(sender.sent[1], "md/unsubscribequote", "MESH6"))
+	refs = quoteRefs(ds)
+	check("The rest of the batch stays subscribed", len(refs) == 1 && refs["MNQH6"] == 1)
+
+	ds.UnsubscribeQuote("MESH6")
+	check("Unsubscribing again sends nothing", len(sender.sent) == 2)
+}
+
+func testQuoteReferencesPerSymbol() {
+	ds, sender := newQuoteSubscriber()
+
+	ds.SubscribeQuote("MESH6")
+	ds.SubscribeQuotes([]string{"MESH6", "MNQH6"})
+	check("Batch only requests symbols not already streaming",
+		len(sender.sent) == 2 && quoteMessage(sender.sent[1], "md/subscribequote", "MNQH6"))
+	check("Shared symbol gains a reference", quoteRefs(ds)["MESH6"] == 2)
+
+	ds.UnsubscribeQuotes([]string{"MESH6", "MNQH6"})
+	check("Releasing the batch stops only unshared symbols",
+		len(sender.sent) == 3 && quoteMessage(sender.sent[2], "md/unsubscribequote", "MNQH6"))
+	check("Shared symbol keeps its other reference", quoteRefs(ds)["MESH6"] == 1)
+
+	ds.UnsubscribeQuote("MESH6")
+	check("Last reference stops the stream",
+		len(sender.sent) == 4 && quoteMessage(sender.sent[3], "md/unsubscribequote", "MESH6"))
+	check("Nothing is left subscribed", len(quoteRefs(ds)) == 0)
+}
+
+func testQuoteUnsubscribeCoalesced() {
+	ds, sender := newQuoteSubscriber()
+
+	ds.SubscribeQuote("MESH6")
+	ds.SubscribeQuote("MNQH6")
+	ds.SubscribeQuote(3001)
+	ds.UnsubscribeQuotes([]string{"MESH6", "MNQH6"})
+	check("Separately subscribed symbols are released in one request",
+		len(sender.sent) == 4 && quoteMessage(sender.sent[3], "md/unsubscribequote", []interface{}{"MESH6", "MNQH6"}))
+	check("Contract ID subscription is left alone", quoteRefs(ds)[3001] == 1)
+}
+
+func testQuoteUnsubscribeAllCoalesced() {
+	ds, sender := newQuoteSubscriber()
+
+	ds.SubscribeQuotes([]string{"MESH6", "MNQH6"})
+	ds.SubscribeQuote("MESH6")
+	ds.SubscribeDOM("MESH6")
+	sender.sent = nil
+
+	ds.UnsubscribeAll()
+	var quoteUnsubs []sentMessage
+	dom := false
+	for _, msg := range sender.sent {
+		switch msg.url {
+		case "md/unsubscribequote":
+			quoteUnsubs = append(quoteUnsubs, msg)
+		case "md/unsubscribedom":
+			dom = true
+		}
+	}
+	symbols := []interface{}{}
+	if len(quoteUnsubs) == 1 {
+		symbols, _ = quoteUnsubs[0].body.(map[string]interface{})["symbol"].([]interface{})
+	}
+	check("UnsubscribeAll stops every quote stream in one request", len(quoteUnsubs) == 1 && len(symbols) == 2)
+	check("UnsubscribeAll still stops other streams", dom)
+	check("UnsubscribeAll clears every reference", len(ds.GetActiveSubscriptions()) == 0)
+}
+
+func testPortfolioQuoteSubscriptions() {
+	md, sender := newQuoteSubscriber()
+	trading := tradovate.NewDataSubscriptionManager(nullSender{})
+	pt := portfolio.NewPortfolioTracker(trading, md, 1, 0, logger.NewLogger(10, logger.LevelWarn))
+	pt.Start("demo")
+
+	sync, _ := json.Marshal(map[string]interface{}{
+		"users":     []map[string]interface{}{{"id": 1}},
+		"contracts": []map[string]interface{}{{"id": 100, "name": "MESH6"}, {"id": 101, "name": "MNQH6"}},
+		"positions": []map[string]interface{}{
+			{"id": 7, "accountId": 1, "contractId": 100, "netPos": 1},
+			{"id": 8, "accountId": 1, "contractId": 101, "netPos": -2},
+		},
+	})
+	trading.HandleEvent("user/syncrequest", sync)
+	check("Open positions from user sync are subscribed in one request",
+		len(sender.sent) == 1 && quoteMessage(sender.sent[0], "md/subscribequote", []interface{}{"MESH6", "MNQH6"}))
+
+	for i := 0; i < 3; i++ {
+		trading.HandleEvent("props", json.RawMessage(`{"entityType":"position","entity":{"id":7,"accountId":1,"contractId":100,"netPos":2}}`))
+	}
+	check("Position updates do not stack references", len(sender.sent) == 1 && quoteRefs(md)["MESH6"] == 1)
+
+	// A strategy on the same contract releasing its own reference leaves the tracker's stream running
+	md.SubscribeQuote("MESH6")
+	md.UnsubscribeQuote("MESH6")
+	check("Tracker's quotes survive another subscriber's release", quoteRefs(md)["MESH6"] == 1)
+}
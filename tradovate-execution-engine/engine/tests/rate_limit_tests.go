@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"time"
+	"tradovate-execution-engine/engine/internal/auth"
+)
+
+// RunRateLimitTests executes all tests for REST rate limiting.
+func RunRateLimitTests() {
+	testTokenBucketBurstAndRefill()
+	testEndpointGroups()
+	testParsePenalty()
+}
+
+func testTokenBucketBurstAndRefill() {
+	rl := auth.NewRateLimiter(2, 1) // Burst of 2, one per second
+	start := time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)
+
+	check("First request in burst is immediate", rl.Reserve("order", start) == 0)
+	check("Second request in burst is immediate", rl.Reserve("order", start) == 0)
+	check("Third request waits one second", rl.Reserve("order", start) == time.Second)
+	check("Other groups have their own bucket", rl.Reserve("account", start) == 0)
+
+	// After 3s the bucket has refilled past the debt
+	check("Bucket refills over time", rl.Reserve("order", start.Add(3*time.Second)) == 0)
+}
+
+func testEndpointGroups() {
+	check("placeorder is in the order group", auth.EndpointGroup("/v1/order/placeorder") == "order")
+	check("account list is in the account group", auth.EndpointGroup("/v1/account/list") == "account")
+}
+
+func testParsePenalty() {
+	p, ok := auth.ParsePenalty([]byte(`{"p-ticket":"abc","p-time":15,"p-captcha":false}`))
+	check("Penalty body is detected", ok && p.Ticket == "abc")
+	assertEqualsFloat("Penalty time is parsed", 15, p.Time, 0.001)
+
+	_, ok = auth.ParsePenalty([]byte(`{"orderId":123}`))
+	check("Normal body is not a penalty", !ok)
+
+	_, ok = auth.ParsePenalty([]byte(`[{"id":1}]`))
+	check("Array body is not a penalty", !ok)
+}
@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/auth"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunReauthTests executes all tests for token expiry and re-authentication
+func RunReauthTests() {
+	testTimeToExpiry()
+	testReauthenticateSameUser()
+	testReauthenticateOtherUser()
+	testRenewalFailureReported()
+	testWebSocketReauthorize()
+	testEngineReauthWithoutSession()
+}
+
+// newLoginTokenManager returns a token manager logged in to s, without a token cache
+func newLoginTokenManager(s *fakeAuthServer) (*auth.TokenManager, error) {
+	cfg := &config.Config{}
+	cfg.Tradovate.Username = "tester"
+	cfg.Tradovate.Password = "secret"
+	cfg.Tradovate.Environment = "demo"
+	tm := auth.NewTokenManager(cfg)
+	tm.SetSessionForTest(s.srv.URL, "", 0)
+	tm.SetLogger(logger.NewLogger(50, logger.LevelDebug))
+	return tm, tm.Authenticate()
+}
+
+func testTimeToExpiry() {
+	tm := auth.NewTokenManager(&config.Config{})
+	_, ok := tm.TimeToExpiry()
+	check("No token has no expiry", !ok)
+
+	tm.SetSessionForTest("http://localhost", "token", 1)
+	left, ok := tm.TimeToExpiry()
+	check("A session counts down to its expiry", ok && left > 59*time.Minute && left <= time.Hour)
+
+	s := newFakeAuthServer(-time.Minute)
+	defer s.srv.Close()
+	expired, err := newLoginTokenManager(s)
+	left, ok = expired.TimeToExpiry()
+	check("An expired token has a negative time left", err == nil && ok && left < 0)
+}
+
+func testReauthenticateSameUser() {
+	s := newFakeAuthServer(time.Hour)
+	defer s.srv.Close()
+	tm, err := newLoginTokenManager(s)
+	if err != nil {
+		check(fmt.Sprintf("Token manager logs in (Error: %v)", err), false)
+		return
+	}
+
+	err = tm.Reauthenticate()
+	token, _ := tm.GetAccessToken()
+	mdToken, _ := tm.GetMDAccessToken()
+	check("Re-authentication creates a new session", err == nil && s.logins.Load() == 2)
+	check("The new session's tokens replace the old", token == "login-token-2" && mdToken == "md-login-token-2")
+	check("The user is unchanged", tm.GetUserID() == 7)
+}
+
+func testReauthenticateOtherUser() {
+	s := newFakeAuthServer(time.Hour)
+	defer s.srv.Close()
+	tm, err := newLoginTokenManager(s)
+	if err != nil {
+		check(fmt.Sprintf("Token manager logs in (Error: %v)", err), false)
+		return
+	}
+
+	s.userID.Store(8)
+	err = tm.Reauthenticate()
+	token, _ := tm.GetAccessToken()
+	check("A session for another user is refused", errors.Is(err, auth.ErrUserChanged))
+	check("The connected session is kept", token == "login-token-1" && tm.GetUserID() == 7)
+}
+
+func testRenewalFailureReported() {
+	s := newFakeAuthServer(2 * time.Minute)
+	defer s.srv.Close()
+	s.rejectRenew.Store(true)
+	tm, err := newLoginTokenManager(s)
+	if err != nil {
+		check(fmt.Sprintf("Token manager logs in (Error: %v)", err), false)
+		return
+	}
+
+	var failures atomic.Int32
+	var renewed atomic.Bool
+	tm.StartTokenRefreshMonitor(func() { renewed.Store(true) }, func(error) { failures.Add(1) })
+	defer tm.StopTokenRefreshMonitor()
+
+	// A token this close to expiry is renewed after a second
+	time.Sleep(time.Second)
+	check("A failed renewal is reported", waitFor(func() bool { return failures.Load() == 1 }))
+	time.Sleep(1500 * time.Millisecond)
+	check("A failed renewal is not retried every second", failures.Load() == 1 && !renewed.Load())
+}
+
+func testWebSocketReauthorize() {
+	server := newWSServer(false)
+	defer server.close()
+
+	idle := tradovate.NewTradovateWebSocketClient("token", "demo", "md")
+	check("An unconnected client cannot be re-authorized", idle.Reauthorize("new-token") != nil)
+
+	client := newTestWSClient(server)
+	if err := client.Connect(); err != nil {
+		check(fmt.Sprintf("Client connects to the local server (Error: %v)", err), false)
+		return
+	}
+	defer client.Disconnect()
+
+	err := client.Reauthorize("new-token")
+	tokens := server.authorizations()
+	check("The open connection is authorized with the new token", err == nil && len(tokens) == 2 && tokens[1] == "new-token")
+	check("The connection is kept", client.IsConnected())
+
+	_, err = client.SendRequest("md/subscribequote", map[string]string{"symbol": "MESH6"})
+	check("Requests still go out on it", err == nil && waitFor(func() bool {
+		for _, f := range server.received() {
+			if strings.HasPrefix(f, "md/subscribequote") {
+				return true
+			}
+		}
+		return false
+	}))
+
+	err = client.Reauthorize("bad")
+	check("A refused token is reported", err != nil && strings.Contains(err.Error(), "401"))
+}
+
+func testEngineReauthWithoutSession() {
+	log := logger.NewLogger(50, logger.LevelDebug)
+	e := app.NewEngine(log, log, log)
+	check("Re-authentication needs a Tradovate session", e.Reauthenticate() != nil)
+	_, ok := e.TokenExpiry()
+	check("No session has no token countdown", !ok)
+	check("No session has no renewal failure", e.TokenRenewalError() == nil && e.CheckTokenRenewal() == nil)
+}
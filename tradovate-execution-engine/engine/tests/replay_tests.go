@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunReplayTests executes all tests for WebSocket recording and replay.
+func RunReplayTests() {
+	testRecorderRedactsAuthorize()
+	testReplayDeliversEventsAndResponses()
+	testReplaySpeed()
+}
+
+// recordedSession is a trading connection: authorize, a sync request answered by
+// its response, then an order event
+var recordedSession = []struct{ dir, frame string }{
+	{tradovate.FrameIn, "o"},
+	{tradovate.FrameOut, "authorize\n1\n\nsecret-access-token"},
+	{tradovate.FrameIn, `a[{"i":1,"s":200}]`},
+	{tradovate.FrameOut, "user/syncrequest\n2\n\n{\"users\":[1]}"},
+	{tradovate.FrameIn, `a[{"i":2,"s":200,"d":{"users":[{"id":1}],"orders":[{"id":301,"ordStatus":"Working","timestamp":"2026-01-05T15:00:00.000Z"}]}}]`},
+	{tradovate.FrameIn, "h"},
+	{tradovate.FrameIn, `a[{"e":"props","d":{"entityType":"order","entity":{"id":301,"ordStatus":"Filled","timestamp":"2026-01-05T15:00:01.000Z"}}}]`},
+}
+
+// recordSession writes recordedSession through a Recorder and returns the file
+func recordSession(dir string) (string, error) {
+	rec, err := tradovate.NewRecorder(dir, "trading")
+	if err != nil {
+		return "", err
+	}
+	for _, f := range recordedSession {
+		rec.Record(f.dir, f.frame)
+	}
+	return rec.Path(), rec.Close()
+}
+
+func testRecorderRedactsAuthorize() {
+	dir, _ := os.MkdirTemp("", "recording")
+	defer os.RemoveAll(dir)
+
+	path, err := recordSession(dir)
+	check("Recording is written under the given directory", err == nil && filepath.Dir(path) == dir &&
+		strings.HasPrefix(filepath.Base(path), "trading-") && strings.HasSuffix(path, ".jsonl.gz"))
+
+	frames, err := tradovate.ReadRecording(path)
+	check("Every frame is read back", err == nil && len(frames) == len(recordedSession))
+
+	leaked := false
+	for _, f := range frames {
+		leaked = leaked || strings.Contains(f.Frame, "secret-access-token")
+	}
+	check("Access token is not in the recording", !leaked)
+	check("Authorize frame keeps its request ID", len(frames) > 1 && frames[1].Frame == "authorize\n1\n\n<redacted>")
+}
+
+func testReplayDeliversEventsAndResponses() {
+	dir, _ := os.MkdirTemp("", "recording")
+	defer os.RemoveAll(dir)
+	path, _ := recordSession(dir)
+
+	var delivered []string
+	ds := newOrderEventSubscriber(&delivered)
+	replayed, err := ds.Replay(path, 0)
+
+	check("Replay counts the inbound frames", err == nil && replayed == 5)
+	check("Sync response is routed by its recorded request ID, then the props event follows",
+		len(delivered) == 2 && delivered[0] == "Working" && delivered[1] == "Filled")
+}
+
+// writeRecording writes frames spaced gap apart, as a Recorder would have
+func writeRecording(path string, frames []string, gap time.Duration) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(file)
+	enc := json.NewEncoder(gz)
+	start := time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)
+	for i, frame := range frames {
+		enc.Encode(tradovate.RecordedFrame{Time: start.Add(time.Duration(i) * gap), Dir: tradovate.FrameIn, Frame: frame})
+	}
+	gz.Close()
+	return file.Close()
+}
+
+func testReplaySpeed() {
+	dir, _ := os.MkdirTemp("", "recording")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "md.jsonl.gz")
+	// Three frames two seconds apart: four seconds of session
+	writeRecording(path, []string{"o", "h", "h"}, 2*time.Second)
+
+	ds := newOrderEventSubscriber(new([]string))
+	start := time.Now()
+	ds.Replay(path, 100)
+	elapsed := time.Since(start)
+	check("Replay at 100x keeps the gaps, scaled down", elapsed >= 35*time.Millisecond && elapsed < time.Second)
+
+	start = time.Now()
+	ds.Replay(path, 0)
+	check("Replay at speed 0 does not pause", time.Since(start) < 20*time.Millisecond)
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/risk"
+)
+
+// RunRiskSupervisorTests executes all tests for the daily loss risk supervisor.
+func RunRiskSupervisorTests() {
+	testSupervisorTripsOncePerBreach()
+	testSupervisorRearmsAfterRecovery()
+	testSupervisorFlattenError()
+	testSupervisorGoroutine()
+	testEngineRiskStateDisconnected()
+}
+
+// supervisorActions counts what the supervisor asked for
+type supervisorActions struct {
+	mu         sync.Mutex
+	stops      int
+	flattens   int
+	running    int
+	flattenErr error
+}
+
+func (a *supervisorActions) StopAllStrategies() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stops++
+	stopped := a.running
+	a.running = 0
+	return stopped
+}
+
+func (a *supervisorActions) FlattenPositions() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.flattens++
+	return a.flattenErr
+}
+
+func (a *supervisorActions) counts() (int, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stops, a.flattens
+}
+
+// newTestSupervisor returns a supervisor over a $500 daily loss limit with two running strategies
+func newTestSupervisor() (*risk.RiskSupervisor, *risk.RiskManager, *supervisorActions) {
+	log := logger.NewLogger(10, logger.LevelWarn)
+	rm := risk.NewRiskManager(&config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}}, log)
+	actions := &supervisorActions{running: 2}
+	return risk.NewRiskSupervisor(rm, actions, actions, log), rm, actions
+}
+
+func testSupervisorTripsOncePerBreach() {
+	rs, rm, actions := newTestSupervisor()
+	var published []risk.SupervisorState
+	rs.AddStateHandler(func(s risk.SupervisorState) { published = append(published, s) })
+
+	rm.SetDailyPnL(-300)
+	state := rs.Check()
+	stops, flattens := actions.counts()
+	check("Inside the limit nothing is done", !state.Tripped && stops == 0 && flattens == 0 && len(published) == 0)
+	check("State carries the daily PnL and limit", state.DailyPnL == -300 && state.Limit == 500)
+
+	rm.SetDailyPnL(-550)
+	state = rs.Check()
+	stops, flattens = actions.counts()
+	check("Breach stops strategies and flattens", state.Tripped && stops == 1 && flattens == 1)
+	check("Tripped state records the strategies stopped", state.Stopped == 2 && !state.TrippedAt.IsZero())
+	check("Trip is published", len(published) == 1 && published[0].Tripped)
+
+	rm.SetDailyPnL(-600)
+	rs.Check()
+	rs.Check()
+	stops, flattens = actions.counts()
+	check("Same breach is acted on only once", stops == 1 && flattens == 1 && len(published) == 1)
+	check("State follows the PnL while tripped", rs.State().DailyPnL == -600 && rs.State().Tripped)
+}
+
+func testSupervisorRearmsAfterRecovery() {
+	rs, rm, actions := newTestSupervisor()
+	var published []risk.SupervisorState
+	rs.AddStateHandler(func(s risk.SupervisorState) { published = append(published, s) })
+
+	rm.SetDailyPnL(-500)
+	rs.Check()
+	rm.SetDailyPnL(-450)
+	state := rs.Check()
+	check("Recovery clears the breach", !state.Tripped && state.Stopped == 0)
+	check("Clear is published", len(published) == 2 && !published[1].Tripped)
+
+	rm.SetDailyPnL(-510)
+	rs.Check()
+	stops, flattens := actions.counts()
+	check("A new breach trips again", stops == 2 && flattens == 2 && len(published) == 3)
+}
+
+func testSupervisorFlattenError() {
+	rs, rm, actions := newTestSupervisor()
+	actions.flattenErr = errors.New("portfolio tracker not initialized")
+
+	rm.SetDailyPnL(-700)
+	state := rs.Check()
+	check("Failed flatten is recorded on the trip", state.Tripped && state.FlattenErr == actions.flattenErr)
+	rs.Check()
+	_, flattens := actions.counts()
+	check("Failed flatten is not retried for the same breach", flattens == 1)
+}
+
+func testSupervisorGoroutine() {
+	rs, rm, actions := newTestSupervisor()
+	rs.Start(5 * time.Millisecond)
+	rs.Start(5 * time.Millisecond)
+
+	rm.SetDailyPnL(-800)
+	check("Running supervisor trips without being asked", waitFor(func() bool { return rs.State().Tripped }))
+	rs.Stop()
+	rs.Stop()
+
+	rm.SetDailyPnL(0)
+	time.Sleep(20 * time.Millisecond)
+	stops, _ := actions.counts()
+	check("Stopped supervisor no longer checks", rs.State().Tripped && stops == 1)
+}
+
+func testEngineRiskStateDisconnected() {
+	quiet := logger.NewLogger(10, logger.LevelWarn)
+	e := app.NewEngine(quiet, quiet, quiet)
+	check("Disconnected engine reports no breach", e.RiskState() == risk.SupervisorState{})
+}
@@ -1,12 +1,19 @@
 package tests
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
 	"tradovate-execution-engine/engine/internal/logger"
 	"tradovate-execution-engine/engine/internal/models"
 	"tradovate-execution-engine/engine/internal/portfolio"
 	"tradovate-execution-engine/engine/internal/risk"
+	"tradovate-execution-engine/engine/internal/schedule"
+	"tradovate-execution-engine/engine/internal/tradovate"
 )
 
 // RunRiskTests executes all tests for the RiskManager.
@@ -14,6 +21,11 @@ func RunRiskTests() {
 	testMaxContractsLimit()
 	testDailyLossLimit()
 	testIsDailyLossExceeded()
+	testOrderLimits()
+	testTradeDateRoll()
+	testDailyPnLFromCashBalance()
+	testSymbolRiskLimits()
+	testRiskConfigValidation()
 }
 
 func testMaxContractsLimit() {
@@ -31,7 +43,7 @@ func testMaxContractsLimit() {
 	order := &models.Order{Side: models.SideBuy, Quantity: 1}
 
 	// 1. Valid order
-	err := rm.CheckOrderRisk(order, pos)
+	err := rm.CheckOrderRisk(order, pos, 0)
 	if err != nil {
 		check(fmt.Sprintf("Risk check should pass for order within limits (Error: %v)", err), false)
 	} else {
@@ -40,21 +52,22 @@ func testMaxContractsLimit() {
 
 	// 2. Order that exceeds limit
 	order.Quantity = 3
-	err = rm.CheckOrderRisk(order, pos)
+	err = rm.CheckOrderRisk(order, pos, 0)
 	check("Risk check should fail for order exceeding max contracts", err != nil)
 
 	// 3. Combined with current position
 	order.Quantity = 2
 	pos.NetPos = 1 // Already Long 1. New order for 2 would make it 3.
-	err = rm.CheckOrderRisk(order, pos)
+	err = rm.CheckOrderRisk(order, pos, 0)
 	check("Risk check should fail when position + new order > max", err != nil)
 
 	// 4. Sell order that exceeds short limit
 	order.Side = models.SideSell
 	order.Quantity = 4
 	pos.NetPos = 0
-	err = rm.CheckOrderRisk(order, pos) // Would make it -4
+	err = rm.CheckOrderRisk(order, pos, 0) // Would make it -4
 	check("Risk check should fail for short order exceeding max contracts", err != nil)
+	check("Max contracts rejection carries its reason", errors.Is(err, risk.ErrMaxContracts))
 }
 
 func testDailyLossLimit() {
@@ -73,7 +86,7 @@ func testDailyLossLimit() {
 
 	// 1. Under loss limit
 	rm.UpdatePnL(-400)
-	err := rm.CheckOrderRisk(order, pos)
+	err := rm.CheckOrderRisk(order, pos, 0)
 	if err != nil {
 		check(fmt.Sprintf("Risk check should pass when under daily loss limit (Error: %v)", err), false)
 	} else {
@@ -82,7 +95,7 @@ func testDailyLossLimit() {
 
 	// 2. Over loss limit
 	rm.UpdatePnL(-200) // Total PnL: -600
-	err = rm.CheckOrderRisk(order, pos)
+	err = rm.CheckOrderRisk(order, pos, 0)
 	check("Risk check should fail when over daily loss limit", err != nil)
 }
 
@@ -95,8 +108,169 @@ func testIsDailyLossExceeded() {
 	}
 	rm := risk.NewRiskManager(cfg, log)
 
-	check("Daily loss not exceeded at $0", !rm.IsDailyLossExceeded(0))
-	check("Daily loss not exceeded at -$499", !rm.IsDailyLossExceeded(-499))
-	check("Daily loss exceeded at -$500", rm.IsDailyLossExceeded(-500))
-	check("Daily loss exceeded at -$1000", rm.IsDailyLossExceeded(-1000))
+	for _, tc := range []struct {
+		pnl      float64
+		exceeded bool
+	}{{0, false}, {-499, false}, {-500, true}, {-1000, true}} {
+		rm.SetDailyPnL(tc.pnl)
+		check(fmt.Sprintf("Daily loss exceeded at $%.0f is %v", tc.pnl, tc.exceeded), rm.IsDailyLossExceeded() == tc.exceeded)
+	}
+}
+
+func testTradeDateRoll() {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	td, _ := schedule.NewTradingDay("", "")
+
+	sunday := time.Date(2026, 1, 4, 17, 0, 0, 0, chicago)
+	check("Sunday 17:00 CT starts Monday's trade date",
+		td.TradeDate(sunday).Equal(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)))
+	check("Monday 16:59 CT is still Monday's trade date",
+		td.TradeDate(time.Date(2026, 1, 5, 16, 59, 0, 0, chicago)).Equal(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)))
+	check("Next roll after Monday 16:59 CT is Monday 17:00 CT",
+		td.NextRoll(time.Date(2026, 1, 5, 16, 59, 0, 0, chicago)).Equal(time.Date(2026, 1, 5, 17, 0, 0, 0, chicago)))
+
+	log := logger.NewLogger(10, logger.LevelDebug)
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 10, DailyLossLimit: 500, EnableRiskChecks: true}}
+	rm := risk.NewRiskManager(cfg, log)
+	now := time.Date(2026, 1, 5, 16, 0, 0, 0, chicago)
+	rm.SetClock(func() time.Time { return now })
+
+	rm.UpdatePnL(-600)
+	rm.IncrementTradeCount()
+	order := &models.Order{Side: models.SideBuy, Quantity: 1}
+	check("Loss limit blocks orders before the roll", errors.Is(rm.CheckOrderRisk(order, nil, 0), risk.ErrDailyLossLimit))
+
+	now = now.Add(59 * time.Minute)
+	check("Loss still counts one minute before the roll", rm.IsDailyLossExceeded())
+
+	now = now.Add(time.Minute)
+	check("Daily PnL resets exactly at the roll", !rm.IsDailyLossExceeded() && rm.GetDailyPnL() == 0)
+	check("Trade count resets at the roll", rm.GetTradeCount() == 0)
+	check("Orders pass again after the roll", rm.CheckOrderRisk(order, nil, 0) == nil)
+	check("Trade date advances at the roll", rm.GetTradeDate().Equal(time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)))
+}
+
+func testDailyPnLFromCashBalance() {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	log := logger.NewLogger(10, logger.LevelDebug)
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 10, DailyLossLimit: 500, EnableRiskChecks: true}}
+
+	md := tradovate.NewDataSubscriptionManager(nullSender{})
+	trading := tradovate.NewDataSubscriptionManager(nullSender{})
+	pt := portfolio.NewPortfolioTracker(trading, md, 1, 0, log)
+	pt.Start("demo")
+
+	om := execution.NewSimulatedOrderManager(execution.NewSimulatedExecutor(), cfg, log)
+	om.SetPortfolioTracker(pt)
+	rm := om.GetRiskManager()
+	rm.SetClock(func() time.Time { return time.Date(2026, 1, 5, 10, 0, 0, 0, chicago) })
+
+	// Local accumulation is ignored once the portfolio is attached
+	rm.UpdatePnL(-1000)
+	check("Portfolio-backed daily PnL ignores local accumulation", !rm.IsDailyLossExceeded())
+
+	cashBalance := func(day int, realized float64) {
+		trading.HandleEvent("props", json.RawMessage(fmt.Sprintf(
+			`{"entityType":"cashBalance","entity":{"accountId":1,"tradeDate":{"year":2026,"month":1,"day":%d},"realizedPnL":%.2f}}`,
+			day, realized)))
+	}
+
+	cashBalance(2, -800)
+	check("Realized PnL from an earlier trade date does not count", rm.GetDailyPnL() == 0)
+
+	cashBalance(5, -550)
+	order := &models.Order{Side: models.SideBuy, Quantity: 1}
+	check("Tick check uses the cash balance realized PnL", rm.IsDailyLossExceeded())
+	check("Pre-order check uses the same value", errors.Is(rm.CheckOrderRisk(order, nil, 0), risk.ErrDailyLossLimit))
+
+	cashBalance(5, -100)
+	check("Both checks clear together when the loss shrinks",
+		!rm.IsDailyLossExceeded() && rm.CheckOrderRisk(order, nil, 0) == nil)
+}
+
+func testOrderLimits() {
+	log := logger.NewLogger(10, logger.LevelDebug)
+	cfg := &config.Config{
+		Risk: config.RiskConfig{
+			MaxContracts:     10,
+			DailyLossLimit:   10000,
+			EnableRiskChecks: true,
+			MaxWorkingOrders: 3,
+			MaxOrderQty:      4,
+		},
+	}
+	rm := risk.NewRiskManager(cfg, log)
+
+	pos := &portfolio.PLEntry{NetPos: 0}
+	order := &models.Order{Side: models.SideBuy, Quantity: 4}
+
+	check("Order at max quantity passes", rm.CheckOrderRisk(order, pos, 2) == nil)
+
+	order.Quantity = 5
+	err := rm.CheckOrderRisk(order, pos, 0)
+	check("Order over max quantity is rejected", errors.Is(err, risk.ErrMaxOrderQty))
+
+	order.Quantity = 1
+	err = rm.CheckOrderRisk(order, pos, 3)
+	check("Order at working order limit is rejected", errors.Is(err, risk.ErrMaxWorkingOrders))
+	check("Working order reason is distinct from max contracts", !errors.Is(err, risk.ErrMaxContracts))
+
+	cfg.Risk.MaxWorkingOrders = 0
+	cfg.Risk.MaxOrderQty = 0
+	order.Quantity = 8
+	check("Zero limits are disabled", rm.CheckOrderRisk(order, pos, 50) == nil)
+}
+
+func testSymbolRiskLimits() {
+	log := logger.NewLogger(10, logger.LevelDebug)
+	cfg := &config.Config{
+		Risk: config.RiskConfig{
+			MaxContracts:     5,
+			DailyLossLimit:   10000,
+			EnableRiskChecks: true,
+			MaxOrderQty:      5,
+			Symbols: map[string]config.SymbolRiskLimits{
+				"CL":  {MaxContracts: 1},
+				"mes": {MaxOrderQty: 2},
+			},
+		},
+	}
+	rm := risk.NewRiskManager(cfg, log)
+	pos := &portfolio.PLEntry{NetPos: 0}
+
+	err := rm.CheckOrderRisk(&models.Order{Symbol: "CLZ6", Side: models.SideBuy, Quantity: 2}, pos, 0)
+	check("Contract uses its product's override", errors.Is(err, risk.ErrMaxContracts))
+	check("Rejection names the product limit", err != nil && strings.Contains(err.Error(), "CL limit of 1"))
+
+	err = rm.CheckOrderRisk(&models.Order{Symbol: "MESH6", Side: models.SideSell, Quantity: 3}, pos, 0)
+	check("Override keys match whatever their case", errors.Is(err, risk.ErrMaxOrderQty) && strings.Contains(err.Error(), "MES limit of 2"))
+	check("Unset override fields use the global value",
+		rm.CheckOrderRisk(&models.Order{Symbol: "MESH6", Side: models.SideSell, Quantity: 2}, &portfolio.PLEntry{NetPos: -3}, 0) == nil)
+
+	err = rm.CheckOrderRisk(&models.Order{Symbol: "NQH6", Side: models.SideBuy, Quantity: 6}, pos, 0)
+	check("Other products fall back to the global limits", errors.Is(err, risk.ErrMaxOrderQty) && strings.Contains(err.Error(), "global limit of 5"))
+	check("A root is not taken for another product's contract",
+		rm.CheckOrderRisk(&models.Order{Symbol: "MCLZ6", Side: models.SideBuy, Quantity: 3}, pos, 0) == nil)
+}
+
+func testRiskConfigValidation() {
+	cases := []struct {
+		name    string
+		symbols string
+		valid   bool
+	}{
+		{"Product roots are accepted", `{"CL":{"maxContracts":2,"maxOrderQty":1,"dailyLossLimit":300},"6E":{}}`, true},
+		{"A contract as key is refused", `{"CLZ6":{"maxContracts":1}}`, false},
+		{"An empty key is refused", `{" ":{"maxContracts":1}}`, false},
+		{"Negative limits are refused", `{"CL":{"dailyLossLimit":-1}}`, false},
+		{"Order size above the position limit is refused", `{"CL":{"maxContracts":1,"maxOrderQty":2}}`, false},
+		{"The same root twice is refused", `{"CL":{},"cl":{}}`, false},
+	}
+	for _, tc := range cases {
+		_, err := config.ParseConfig([]byte(`{"risk":{"maxContracts":5,"symbols":` + tc.symbols + `}}`))
+		check(fmt.Sprintf("%s (Error: %v)", tc.name, err), (err == nil) == tc.valid)
+	}
+
+	_, err := config.ParseConfig([]byte(`{"risk":{"maxContracts":5}}`))
+	check("A config without overrides is valid", err == nil)
 }
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/schedule"
+)
+
+// RunScheduleTests executes all tests for the trading session schedule.
+func RunScheduleTests() {
+	testSessionWindow()
+	testOvernightSession()
+	testFlattenDue()
+	testScheduleRejectsOrders()
+	testMaintenanceWindows()
+}
+
+func testSessionWindow() {
+	s, err := schedule.NewTradingSchedule(config.ScheduleConfig{
+		Enabled: true, Start: "09:30", End: "16:00", Timezone: "America/New_York",
+	})
+	check("Schedule parses", err == nil)
+	if err != nil {
+		return
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+	check("Inside regular hours", s.InSession(time.Date(2026, 1, 5, 10, 0, 0, 0, ny)))
+	check("Before the open", !s.InSession(time.Date(2026, 1, 5, 9, 29, 0, 0, ny)))
+	check("End is exclusive", !s.InSession(time.Date(2026, 1, 5, 16, 0, 0, 0, ny)))
+	check("Timezone is applied", s.InSession(time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)))
+
+	disabled, _ := schedule.NewTradingSchedule(config.ScheduleConfig{})
+	check("Disabled schedule is always in session", disabled.InSession(time.Date(2026, 1, 5, 3, 0, 0, 0, ny)))
+
+	_, err = schedule.NewTradingSchedule(config.ScheduleConfig{Enabled: true, Start: "9am", End: "16:00"})
+	check("Invalid time is rejected", err != nil)
+}
+
+func testOvernightSession() {
+	s, _ := schedule.NewTradingSchedule(config.ScheduleConfig{Enabled: true, Start: "18:00", End: "17:00", Timezone: "UTC"})
+	check("Overnight session includes the evening", s.InSession(time.Date(2026, 1, 5, 20, 0, 0, 0, time.UTC)))
+	check("Overnight session includes the morning", s.InSession(time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC)))
+	check("Overnight session excludes the break", !s.InSession(time.Date(2026, 1, 6, 17, 30, 0, 0, time.UTC)))
+}
+
+func testFlattenDue() {
+	s, _ := schedule.NewTradingSchedule(config.ScheduleConfig{
+		Enabled: true, Start: "09:30", End: "16:00", Timezone: "UTC", FlattenAt: "15:55",
+	})
+	before := time.Date(2026, 1, 5, 15, 54, 59, 0, time.UTC)
+	at := time.Date(2026, 1, 5, 15, 55, 0, 0, time.UTC)
+	check("Flatten fires when the cutoff is crossed", s.FlattenDue(before, at))
+	check("Flatten fires once", !s.FlattenDue(at, at.Add(time.Second)))
+	check("Flatten does not fire without a previous tick", !s.FlattenDue(time.Time{}, at))
+}
+
+func testScheduleRejectsOrders() {
+	log := logger.NewLogger(100, logger.LevelWarn)
+	sim := execution.NewSimulatedExecutor()
+	sim.SetMarket("MESH6", 5000, "T0")
+	om := execution.NewSimulatedOrderManager(sim, &config.Config{Risk: config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500, EnableRiskChecks: true}}, log)
+
+	// A one minute window that has just closed
+	now := time.Now().UTC()
+	closed := now.Add(-2 * time.Minute)
+	s, _ := schedule.NewTradingSchedule(config.ScheduleConfig{
+		Enabled: true, Start: closed.Format("15:04"), End: closed.Add(time.Minute).Format("15:04"), Timezone: "UTC",
+	})
+	om.SetSchedule(s)
+
+	order, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Order outside the session is rejected", err != nil && order.Status == models.StatusRejected)
+	check("Reject reason names the schedule", order != nil && strings.HasPrefix(order.RejectReason, "schedule:"))
+
+	_, err = om.Flatten("MESH6", models.SideSell, 1)
+	check("Flatten orders bypass the schedule", err == nil)
+}
+
+func testMaintenanceWindows() {
+	cfg := config.MaintenanceConfig{Windows: map[string][]string{
+		"live": {"16:00-17:00"},
+		"demo": {"23:30-00:15", "05:00-05:30"},
+	}}
+	live, err := schedule.NewMaintenanceWindows(cfg, "live")
+	check("Maintenance windows parse", err == nil && live.Enabled())
+	if err != nil {
+		return
+	}
+	chicago, _ := time.LoadLocation("America/Chicago")
+	check("Inside the window", live.Active(time.Date(2026, 1, 5, 16, 30, 0, 0, chicago)))
+	check("Window end is exclusive", !live.Active(time.Date(2026, 1, 5, 17, 0, 0, 0, chicago)))
+	check("Windows default to exchange time", live.Active(time.Date(2026, 1, 5, 22, 30, 0, 0, time.UTC)))
+
+	demo, _ := schedule.NewMaintenanceWindows(cfg, "demo")
+	check("Window over midnight includes both sides",
+		demo.Active(time.Date(2026, 1, 5, 23, 45, 0, 0, chicago)) && demo.Active(time.Date(2026, 1, 6, 0, 10, 0, 0, chicago)))
+	check("Each window of the environment applies", demo.Active(time.Date(2026, 1, 6, 5, 10, 0, 0, chicago)))
+	check("Windows belong to their environment", !demo.Active(time.Date(2026, 1, 5, 16, 30, 0, 0, chicago)))
+
+	none, err := schedule.NewMaintenanceWindows(cfg, "sim")
+	check("Environment without windows is never in maintenance", err == nil && !none.Active(time.Date(2026, 1, 5, 16, 30, 0, 0, chicago)))
+
+	_, err = schedule.NewMaintenanceWindows(config.MaintenanceConfig{Windows: map[string][]string{"live": {"16:00"}}}, "live")
+	check("Window without an end is rejected", err != nil)
+	_, err = schedule.NewMaintenanceWindows(config.MaintenanceConfig{Windows: map[string][]string{"live": {"16:00-16:00"}}}, "live")
+	check("Empty window is rejected", err != nil)
+}
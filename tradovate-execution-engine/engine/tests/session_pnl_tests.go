@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/portfolio"
+)
+
+// RunSessionPnLTests executes all tests for session realized PnL across
+// reconnects and trade dates.
+func RunSessionPnLTests() {
+	testSessionPnLAcrossReconnect()
+	testSessionPnLTradeDateRoll()
+	testEngineCarriesSessionOver()
+}
+
+// infosLogged counts the log's info messages that contain text
+func infosLogged(log *logger.Logger, text string) int {
+	n := 0
+	for _, e := range log.GetEntries() {
+		if e.Level == logger.LevelInfo && strings.Contains(e.Message, text) {
+			n++
+		}
+	}
+	return n
+}
+
+func testSessionPnLAcrossReconnect() {
+	log := logger.NewLogger(50, logger.LevelDebug)
+	pt, trading := newBalanceTracker(log)
+	cashBalanceEvent(trading, `{"accountId":1,"tradeDate":{"year":2026,"month":3,"day":9},"amount":50100,"realizedPnL":100}`)
+	cashBalanceEvent(trading, `{"accountId":1,"tradeDate":{"year":2026,"month":3,"day":9},"amount":50250,"realizedPnL":250}`)
+	check("Session realized PnL counts from the first cash balance", pt.GetSessionRealizedPnL() == 150)
+
+	anchor := pt.SessionAnchor()
+	check("The anchor keeps the realized PnL at session start", anchor.Anchored && anchor.RealizedPnL == 100 && !anchor.Start.IsZero())
+
+	// A reconnect builds a new tracker, whose first cash balance is already mid-session
+	fresh, freshTrading := newBalanceTracker(log)
+	fresh.SetSessionAnchor(anchor)
+	cashBalanceEvent(freshTrading, `{"accountId":1,"tradeDate":{"year":2026,"month":3,"day":9},"amount":50300,"realizedPnL":300}`)
+	check("Session realized PnL survives the reconnect", fresh.GetSessionRealizedPnL() == 200)
+	check("Daily realized PnL is Tradovate's", fresh.GetRealizedPnL() == 300)
+	check("Session start survives the reconnect", fresh.SessionAnchor().Start.Equal(anchor.Start))
+
+	// Without the anchor the session would start again at the reconnect
+	lost, lostTrading := newBalanceTracker(log)
+	cashBalanceEvent(lostTrading, `{"accountId":1,"tradeDate":{"year":2026,"month":3,"day":9},"amount":50300,"realizedPnL":300}`)
+	check("A new tracker without an anchor starts a new session", lost.GetSessionRealizedPnL() == 0)
+}
+
+func testSessionPnLTradeDateRoll() {
+	log := logger.NewLogger(50, logger.LevelDebug)
+	pt, trading := newBalanceTracker(log)
+	start := time.Now().Add(-time.Hour).UTC()
+	pt.SetSessionAnchor(portfolio.SessionAnchor{Start: start, AccountID: 1, RealizedPnL: 100, Anchored: true})
+	cashBalanceEvent(trading, `{"accountId":1,"tradeDate":{"year":2026,"month":3,"day":9},"amount":50400,"realizedPnL":400}`)
+	check("Same day without a trade date on the anchor keeps the session", pt.GetSessionRealizedPnL() == 300)
+
+	cashBalanceEvent(trading, `{"accountId":1,"tradeDate":{"year":2026,"month":3,"day":10},"amount":50400,"realizedPnL":0}`)
+	anchor := pt.SessionAnchor()
+	check("A new trade date starts a new session", pt.GetSessionRealizedPnL() == 0 && anchor.Start.After(start))
+	check("The new session is anchored to the new trade date", anchor.TradeDate.Day == 10 && anchor.RealizedPnL == 0)
+	check("The roll is logged", infosLogged(log, "New trade date 2026-03-10") == 1)
+
+	cashBalanceEvent(trading, `{"accountId":1,"tradeDate":{"year":2026,"month":3,"day":10},"amount":50480,"realizedPnL":80}`)
+	check("Session realized PnL counts on the new trade date", pt.GetSessionRealizedPnL() == 80)
+
+	// An anchor carried over from yesterday, with no trade dates to compare
+	stale, staleTrading := newBalanceTracker(log)
+	stale.SetSessionAnchor(portfolio.SessionAnchor{Start: time.Now().Add(-24 * time.Hour).UTC(), AccountID: 1, RealizedPnL: 500, Anchored: true})
+	cashBalanceEvent(staleTrading, `{"accountId":1,"amount":50050,"realizedPnL":50}`)
+	check("Without trade dates a session from an earlier day is not continued",
+		stale.GetSessionRealizedPnL() == 0 && stale.SessionAnchor().RealizedPnL == 50)
+}
+
+func testEngineCarriesSessionOver() {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	cfg := &config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	start := e.SessionStart()
+	e.Disconnect()
+	check("Session start is kept while disconnected", e.SessionStart().Equal(start))
+
+	time.Sleep(10 * time.Millisecond)
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo reconnects (Error: %v)", err), false)
+		return
+	}
+	defer e.Disconnect()
+	check("Reconnecting to the same account continues the session", !start.IsZero() && e.SessionStart().Equal(start))
+}
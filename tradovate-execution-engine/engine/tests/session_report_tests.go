@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/portfolio"
+)
+
+// RunSessionReportTests executes all tests for the end of session report.
+func RunSessionReportTests() {
+	start := time.Date(2026, 1, 5, 14, 30, 0, 0, time.UTC)
+	snap := portfolio.SessionSnapshot{
+		SessionStart:        start,
+		StartingRealizedPnL: 100,
+		EndingRealizedPnL:   350,
+		Positions: map[string]portfolio.PLEntry{
+			"MESH6": {Name: "MESH6", NetPos: 2, BuyPrice: 5000, LastPrice: 5010, PL: 100},
+			"MNQH6": {Name: "MNQH6", NetPos: 0},
+		},
+		RealizedBySymbol: map[string]float64{"MNQH6": 250},
+	}
+	orders := []*models.Order{
+		{ID: "1", Status: models.StatusFilled},
+		{ID: "2", Status: models.StatusFilled},
+		{ID: "3", Status: models.StatusRejected},
+		{ID: "4", Status: models.StatusSubmitted},
+	}
+	history := []portfolio.PnLSample{{Time: start, PnL: 0}, {Time: start.Add(time.Minute), PnL: 350}}
+
+	report := portfolio.BuildSessionReport(snap, orders, history)
+
+	assertEqualsFloat("Session realized PnL is ending minus starting", 250, report.SessionRealizedPnL, 0.001)
+	assertEqualsFloat("Unrealized PnL sums open positions", 100, report.UnrealizedPnL, 0.001)
+	check("Order counts are aggregated", report.Orders == 4 && report.Fills == 2 && report.Rejects == 1)
+	check("Both traded symbols are reported", len(report.Symbols) == 2)
+	if len(report.Symbols) == 2 {
+		mes, mnq := report.Symbols[0], report.Symbols[1]
+		check("Open position is marked open", mes.Symbol == "MESH6" && mes.Open && mes.NetPos == 2 && mes.UnrealizedPnL == 100)
+		check("Closed symbol keeps its realized PnL", mnq.Symbol == "MNQH6" && !mnq.Open && mnq.RealizedPnL == 250)
+	}
+
+	dir, err := os.MkdirTemp("", "session_report")
+	if err != nil {
+		check("Temp dir for report", false)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	txtPath, jsonPath, err := report.WriteFiles(dir)
+	check("Report files are written", err == nil)
+	check("Report is named by session start", strings.Contains(txtPath, "session_report_2026-01-05_143000"))
+
+	data, _ := os.ReadFile(jsonPath)
+	var decoded portfolio.SessionReport
+	check("JSON report round trips", json.Unmarshal(data, &decoded) == nil && decoded.Fills == 2 && len(decoded.PnLHistory) == 2)
+
+	text, _ := os.ReadFile(txtPath)
+	check("Text report lists the open position", strings.Contains(string(text), "OPEN 2 @ 5000.00"))
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"strings"
+	"sync"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/backtest"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+)
+
+// RunSlippageTests executes all tests for the slippage model of simulated fills
+func RunSlippageTests() {
+	testSlippageConfig()
+	testFixedSlippage()
+	testSpreadSlippage()
+	testRandomSlippage()
+	testLimitFills()
+	testRestingLimitCancel()
+	testProductSlippage()
+	testBacktestSlippage()
+}
+
+// newSlippageManager returns a simulated order manager on MESH6, last traded at
+// 5000 with a 0.25 tick, filling with the slippage model cfg
+func newSlippageManager(cfg config.SlippageConfig) (*execution.OrderManager, *execution.SimulatedExecutor) {
+	sim := execution.NewSimulatedExecutor()
+	sim.SetTickSize("MESH6", 0.25)
+	sim.SetMarket("MESH6", 5000, "2026-01-05T15:00:00Z")
+	om := execution.NewSimulatedOrderManager(sim, &config.Config{Slippage: cfg}, logger.NewLogger(10, logger.LevelWarn))
+	return om, sim
+}
+
+func testSlippageConfig() {
+	slippage := config.SlippageConfig{
+		Model:   config.SlippageFixed,
+		Ticks:   1,
+		Symbols: map[string]config.ProductSlippage{"cl": {Model: config.SlippageSpread, Ticks: 2}},
+	}
+	check("Slippage config is valid", slippage.Validate() == nil)
+	check("An unknown model is refused", config.SlippageConfig{Model: "worst"}.Validate() != nil)
+	check("Negative ticks are refused", config.SlippageConfig{Model: config.SlippageFixed, Ticks: -1}.Validate() != nil)
+	check("A contract is refused as a product key",
+		config.SlippageConfig{Symbols: map[string]config.ProductSlippage{"CLZ6": {}}}.Validate() != nil)
+	check("Product models match by root whatever the key's case", slippage.For("CLZ6").Model == config.SlippageSpread)
+	check("Other products use the default", slippage.For("MESH6").Model == config.SlippageFixed)
+	check("No model is none", config.SlippageConfig{}.For("MESH6").Model == config.SlippageNone)
+
+	_, err := config.ParseConfig([]byte(`{"slippage":{"symbols":{"MES":{"model":"fixed","ticks":-2}}}}`))
+	check("Config with negative product ticks is refused on load", err != nil)
+}
+
+func testFixedSlippage() {
+	om, sim := newSlippageManager(config.SlippageConfig{Model: config.SlippageFixed, Ticks: 2})
+	buy, err := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("A market buy fills at once", err == nil && buy.Status == models.StatusFilled)
+	check("A buy fills fixed ticks above the last trade", buy.AvgFillPrice() == 5000.5)
+	sell, _ := om.SubmitMarketOrder("MESH6", models.SideSell, 1)
+	check("A sell fills fixed ticks below the last trade", sell.AvgFillPrice() == 4999.5)
+
+	fills := sim.GetFills()
+	check("Each fill records its model and slippage against the order", len(fills) == 2 &&
+		fills[0].Model == config.SlippageFixed && fills[0].Slippage == 0.5 && fills[1].Slippage == 0.5)
+	if len(sell.Fills) == 1 {
+		check("The order's fill records the slippage too",
+			sell.Fills[0].SlippageModel == config.SlippageFixed && sell.Fills[0].Slippage == 0.5)
+	}
+}
+
+func testSpreadSlippage() {
+	om, sim := newSlippageManager(config.SlippageConfig{Model: config.SlippageSpread, Ticks: 1})
+	buy, _ := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("Without a quote a spread buy fills Ticks above the last trade", buy.AvgFillPrice() == 5000.25)
+
+	sim.SetQuote("MESH6", 4999.5, 5000.75)
+	buy, _ = om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("A spread buy fills at the offer", buy.AvgFillPrice() == 5000.75)
+	sell, _ := om.SubmitMarketOrder("MESH6", models.SideSell, 1)
+	check("A spread sell fills at the bid", sell.AvgFillPrice() == 4999.5)
+	if len(sell.Fills) == 1 {
+		check("The slippage is the distance from the last trade", sell.Fills[0].Slippage == 0.5)
+	}
+}
+
+func testRandomSlippage() {
+	om, sim := newSlippageManager(config.SlippageConfig{Model: config.SlippageRandom, Ticks: 3})
+	for i := 0; i < 40; i++ {
+		om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	}
+	bounded, varied := true, false
+	fills := sim.GetFills()
+	for _, fill := range fills {
+		if fill.Slippage < 0 || fill.Slippage > 0.75 {
+			bounded = false
+		}
+		if fill.Slippage != fills[0].Slippage {
+			varied = true
+		}
+	}
+	check("Random slippage stays within 0 and Ticks", len(fills) == 40 && bounded)
+	check("Random slippage varies between fills", varied)
+
+	om2, again := newSlippageManager(config.SlippageConfig{Model: config.SlippageRandom, Ticks: 3})
+	for i := 0; i < 40; i++ {
+		om2.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	}
+	same := true
+	for i, fill := range again.GetFills() {
+		if i >= len(fills) || fill.Slippage != fills[i].Slippage {
+			same = false
+		}
+	}
+	check("A fresh simulator slips the same way, so backtests repeat", same)
+}
+
+func testLimitFills() {
+	om, sim := newSlippageManager(config.SlippageConfig{Model: config.SlippageFixed, Ticks: 2})
+	var mu sync.Mutex
+	var filled []models.Order
+	om.AddOrderListener(func(o models.Order) {
+		if o.Status == models.StatusFilled {
+			mu.Lock()
+			filled = append(filled, o)
+			mu.Unlock()
+		}
+	})
+
+	marketable, _ := om.SubmitLimitOrder("MESH6", models.SideBuy, 1, 5001)
+	check("A marketable limit buy fills on arrival with slippage", marketable.Status == models.StatusFilled && marketable.AvgFillPrice() == 5000.5)
+	capped, _ := om.SubmitLimitOrder("MESH6", models.SideSell, 1, 4999.75)
+	check("It never fills worse than its limit", capped.Status == models.StatusFilled && capped.AvgFillPrice() == 4999.75)
+
+	buy, err := om.SubmitLimitOrder("MESH6", models.SideBuy, 1, 4998)
+	check("A limit buy below the market rests", err == nil && buy.Status == models.StatusSubmitted && len(buy.Fills) == 0)
+	sim.SetMarket("MESH6", 4998, "2026-01-05T15:01:00Z")
+	check("A trade at the limit does not fill it", buy.Status == models.StatusSubmitted)
+	sim.SetMarket("MESH6", 4997.75, "2026-01-05T15:02:00Z")
+	got, _ := om.GetOrder(buy.ID)
+	check("A trade through the limit fills it at the limit", got.Status == models.StatusFilled && got.AvgFillPrice() == 4998)
+	if len(got.Fills) == 1 {
+		check("A resting fill has no slippage", got.Fills[0].Slippage == 0 && got.Fills[0].SlippageModel == config.SlippageFixed)
+	}
+	mu.Lock()
+	check("Listeners are told of the resting fill", len(filled) == 3 && filled[2].ID == buy.ID)
+	mu.Unlock()
+
+	atPrice, sim2 := newSlippageManager(config.SlippageConfig{Model: config.SlippageNone, LimitAtPrice: true})
+	sell, _ := atPrice.SubmitLimitOrder("MESH6", models.SideSell, 1, 5002)
+	check("A limit sell above the market rests", sell.Status == models.StatusSubmitted)
+	sim2.SetMarket("MESH6", 5002, "2026-01-05T15:01:00Z")
+	got, _ = atPrice.GetOrder(sell.ID)
+	check("With limitAtPrice a trade at the limit fills it", got.Status == models.StatusFilled && got.AvgFillPrice() == 5002)
+	check("The fill moves the simulated position", sim2.GetPosition("MESH6").NetPos == -1)
+}
+
+func testRestingLimitCancel() {
+	om, sim := newSlippageManager(config.SlippageConfig{})
+	order, _ := om.SubmitLimitOrder("MESH6", models.SideBuy, 2, 4990)
+	check("A resting limit can be modified", om.ModifyOrder(order.ID, 1, 4995) == nil && order.Quantity == 1 && order.Price == 4995)
+	check("A resting limit is cancelled in the simulator", om.CancelOrder(order.ID) == nil && order.Status == models.StatusCanceled)
+	sim.SetMarket("MESH6", 4980, "2026-01-05T15:01:00Z")
+	check("A cancelled limit never fills", len(sim.GetFills()) == 0 && order.Status == models.StatusCanceled)
+	check("Cancelling it again fails", om.CancelOrder(order.ID) != nil)
+
+	stop, err := om.SubmitStopOrder("MESH6", models.SideSell, 1, 4990)
+	check("The simulator still refuses stop orders", err != nil && stop.Status != models.StatusFilled &&
+		strings.Contains(err.Error(), "market and limit"))
+}
+
+func testProductSlippage() {
+	om, sim := newSlippageManager(config.SlippageConfig{
+		Model:   config.SlippageFixed,
+		Ticks:   4,
+		Symbols: map[string]config.ProductSlippage{"MES": {Model: config.SlippageNone}},
+	})
+	buy, _ := om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("A product's own model overrides the default", buy.AvgFillPrice() == 5000)
+
+	sim.SetTickSize("MNQH6", 0.25)
+	sim.SetMarket("MNQH6", 20000, "2026-01-05T15:00:00Z")
+	other, _ := om.SubmitMarketOrder("MNQH6", models.SideBuy, 1)
+	check("Other products keep the default", other.AvgFillPrice() == 20001)
+
+	om.ApplyConfig(&config.Config{Slippage: config.SlippageConfig{Model: config.SlippageFixed, Ticks: 1}})
+	buy, _ = om.SubmitMarketOrder("MESH6", models.SideBuy, 1)
+	check("A config reload changes the model", buy.AvgFillPrice() == 5000.25)
+}
+
+func testBacktestSlippage() {
+	cfg := backtestConfig(syntheticBars(10, 10, 10, 10, 10, 11, 14, 16, 14, 12))
+	cfg.TickSize = 0.25
+	cfg.Slippage = config.SlippageConfig{Model: config.SlippageFixed, Ticks: 1}
+	report, err := backtest.Run(cfg, nil)
+	check("Backtest with slippage runs without error", err == nil)
+	if err != nil {
+		return
+	}
+	// The buy of 1 and the reversing sell of 2 each lose a tick, $1.25 a contract
+	assertEqualsFloat("Backtest slippage is costed on every fill", 3.75, report.Slippage, 0.001)
+	assertEqualsFloat("Backtest PnL is after slippage", 1.25, report.TotalPnL, 0.001)
+	check("Backtest fills record the model", len(report.Fills) == 2 && report.Fills[0].Model == config.SlippageFixed)
+	check("The summary shows the slippage", strings.Contains(report.Summary(), "slippage $3.75"))
+
+	cfg.Slippage = config.SlippageConfig{}
+	report, err = backtest.Run(cfg, nil)
+	check("Backtest without slippage fills at the close", err == nil && report.Slippage == 0 && report.TotalPnL == 5)
+}
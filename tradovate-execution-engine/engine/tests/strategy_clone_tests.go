@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"fmt"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+)
+
+// RunStrategyCloneTests executes all tests for cloning a strategy instance onto another symbol
+func RunStrategyCloneTests() {
+	testCloneStrategyParams()
+	testCloneStrategyRefused()
+	testClonesRunSideBySide()
+}
+
+func testCloneStrategyParams() {
+	e := newParamTestEngine()
+	inst, err := e.AddStrategy("ma_crossover", map[string]string{"symbol": "MES", "fast_length": "8", "slow_length": "40"})
+	if err != nil {
+		check(fmt.Sprintf("ma_crossover instance is added (Error: %v)", err), false)
+		return
+	}
+	clone, err := e.CloneStrategy(inst.ID, "MNQ")
+	check("Clone is added as a new stopped instance", err == nil && clone != nil && clone.ID != inst.ID &&
+		clone.Runtime.Status() == app.StrategyDisabled && len(e.Strategies()) == 2)
+	if clone == nil {
+		return
+	}
+	params, original := clone.Params(), inst.Params()
+	check("Clone has the original's parameters", clone.Name == "ma_crossover" && params["fast_length"] == "8" && params["slow_length"] == "40")
+	check("Clone trades the new symbol", params["symbol"] == "MNQ" && original["symbol"] == "MES")
+	check("Clone is a strategy of its own", clone.Strategy() != inst.Strategy() && clone.Log != inst.Log)
+
+	e.SetParam(clone.ID, "fast_length", "5")
+	check("Clone's parameters are its own", inst.Params()["fast_length"] == "8" && clone.Params()["fast_length"] == "5")
+}
+
+func testCloneStrategyRefused() {
+	e := newParamTestEngine()
+	inst, err := e.AddStrategy("ma_crossover", nil)
+	if err != nil {
+		check(fmt.Sprintf("ma_crossover instance is added (Error: %v)", err), false)
+		return
+	}
+	_, err = e.CloneStrategy("99", "MNQ")
+	check("Cloning an unknown instance is refused", err != nil)
+	_, err = e.CloneStrategy(inst.ID, "")
+	check("Cloning without a symbol is refused", err != nil)
+
+	// Constraints between params are only checked at start, so a clone checks them
+	e.SetParam(inst.ID, "fast_length", "50")
+	e.SetParam(inst.ID, "slow_length", "20")
+	_, err = e.CloneStrategy(inst.ID, "MNQ")
+	check("A configuration that would not start is not cloned", err != nil && len(e.Strategies()) == 1)
+}
+
+func testClonesRunSideBySide() {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	if err := e.ConnectDemo(&config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}}); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Disconnect()
+
+	inst, err := e.AddStrategy("ma_crossover", map[string]string{"symbol": "MESH6", "fast_length": "2", "slow_length": "3"})
+	if err != nil {
+		check(fmt.Sprintf("ma_crossover instance is added (Error: %v)", err), false)
+		return
+	}
+	clone, err := e.CloneStrategy(inst.ID, "MNQH6")
+	if err != nil {
+		check(fmt.Sprintf("Instance is cloned (Error: %v)", err), false)
+		return
+	}
+	check("Original starts", e.StartStrategy(inst.ID) == nil)
+	check("Clone starts", e.StartStrategy(clone.ID) == nil)
+	check("Both run", waitFor(func() bool {
+		return inst.Runtime.Status() == app.StrategyRunning && clone.Runtime.Status() == app.StrategyRunning
+	}))
+	check("Each trades its own symbol", inst.Symbol() == "MESH6" && clone.Symbol() == "MNQH6")
+
+	check("Clone stops", e.StopStrategy(clone.ID) == nil)
+	check("Stopping the clone leaves the original running", inst.Runtime.Status() == app.StrategyRunning)
+	check("Original stops", e.StopStrategy(inst.ID) == nil)
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+)
+
+// RunStrategyErrorTests executes all tests for strategies that fail or panic
+// in their callbacks.
+func RunStrategyErrorTests() {
+	testCallStrategy()
+	testWarmupStopsOnFailure()
+	testQuoteQueuePanic()
+	testEngineFailsPanickingStrategy()
+}
+
+// failingRecorder is a barRecorder that fails on the bar numbered failAt,
+// panicking when panics is set
+type failingRecorder struct {
+	barRecorder
+	failAt int
+	panics bool
+}
+
+func (r *failingRecorder) OnBar(timestamp string, close float64) error {
+	if len(r.closes)+1 == r.failAt {
+		if r.panics {
+			var prices map[string]float64
+			prices[timestamp] = close // Writes to a nil map
+		}
+		return fmt.Errorf("bad bar at %s", timestamp)
+	}
+	return r.barRecorder.OnBar(timestamp, close)
+}
+
+// failingWarmup is a failingRecorder asking for more history than the chart
+// brings, so it warms up from paged history
+type failingWarmup struct {
+	failingRecorder
+}
+
+func (w *failingWarmup) WarmupBars() int { return 40 }
+
+func testCallStrategy() {
+	check("A callback without error passes", execution.CallStrategy("OnBar", func() error { return nil }) == nil)
+
+	cause := errors.New("boom")
+	err := execution.CallStrategy("OnBar", func() error { return cause })
+	check("An error is returned, naming the callback", errors.Is(err, cause) && strings.HasPrefix(err.Error(), "OnBar: "))
+
+	err = execution.CallStrategy("OnQuote", func() error { panic("index out of range") })
+	var p *execution.StrategyPanic
+	check("A panic is recovered as a StrategyPanic", errors.As(err, &p) && p.Callback == "OnQuote" && p.Value == "index out of range")
+	check("The panic keeps its stack trace", p != nil && strings.Contains(string(p.Stack), "testCallStrategy"))
+	check("The panic message names the callback", err != nil && err.Error() == "OnQuote panicked: index out of range")
+}
+
+func testWarmupStopsOnFailure() {
+	for _, panics := range []bool{false, true} {
+		kind := "error"
+		if panics {
+			kind = "panic"
+		}
+		rec := &failingRecorder{failAt: 2, panics: panics}
+		var failures []error
+		readies := 0
+		w := execution.NewStrategyWarmup(rec, nil)
+		w.SetReadyHandler(func() { readies++ })
+		w.SetErrorHandler(func(err error) { failures = append(failures, err) })
+
+		w.OnChart(chartUpdate(1, true, minuteBar(0, 10), minuteBar(1, 11), minuteBar(2, 12), minuteBar(3, 13)))
+		check("An OnBar "+kind+" reaches the error handler once", len(failures) == 1 && w.Err() == failures[0])
+		check("No bar is fed after the "+kind, closesEqual(rec.closes, 10))
+		check("A failed strategy never becomes ready after the "+kind, readies == 0)
+
+		w.OnLiveBar(minuteBar(4, 14))
+		check("Live bars are not fed after the "+kind, closesEqual(rec.closes, 10) && len(failures) == 1)
+
+		var p *execution.StrategyPanic
+		check("The "+kind+" is reported as such", errors.As(w.Err(), &p) == panics)
+	}
+}
+
+// panickingQuotes is a QuoteHandler whose OnQuote panics
+type panickingQuotes struct {
+	quoteRecorder
+}
+
+func (q *panickingQuotes) OnQuote(marketdata.Quote) error {
+	panic("quote handler bug")
+}
+
+func testQuoteQueuePanic() {
+	var failures atomic.Int32
+	q := execution.NewQuoteQueue(&panickingQuotes{}, 4, func(err error) {
+		var p *execution.StrategyPanic
+		if errors.As(err, &p) {
+			failures.Add(1)
+		}
+	})
+	defer q.Stop()
+
+	q.Offer(tradeQuote(1))
+	check("An OnQuote panic is passed to onError, not the process", waitFor(func() bool { return failures.Load() == 1 }))
+	q.Offer(tradeQuote(2))
+	check("The queue keeps delivering after a panic", waitFor(func() bool { return failures.Load() == 2 }))
+}
+
+func testEngineFailsPanickingStrategy() {
+	execution.Register("test_failing", func(*logger.Logger) execution.Strategy {
+		return &failingWarmup{failingRecorder{
+			barRecorder: barRecorder{params: []execution.StrategyParam{{Name: "symbol", Type: "string", Value: "MESZ6"}}},
+			failAt:      3,
+			panics:      true,
+		}}
+	})
+
+	strategyLog := logger.NewLogger(200, logger.LevelDebug)
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, strategyLog)
+	var mu sync.Mutex
+	var failures []string
+	e.AddEventHandler(func(ev app.Event) {
+		if ev.Kind == app.EventStrategyStatus && ev.Status == app.StrategyError {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, ev.Message)
+		}
+	})
+	if err := e.ConnectDemo(&config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}}); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Disconnect()
+
+	inst, err := e.AddStrategy("test_failing", nil)
+	if err != nil {
+		check(fmt.Sprintf("Strategy is added (Error: %v)", err), false)
+		return
+	}
+	if err := e.StartStrategy(inst.ID); err != nil {
+		check(fmt.Sprintf("Strategy starts (Error: %v)", err), false)
+		return
+	}
+
+	announced := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), failures...)
+	}
+	failed := waitFor(func() bool { return len(announced()) == 1 })
+	check("A panic in OnBar during warm-up puts the strategy in ERROR", failed && inst.Runtime.Status() == app.StrategyError)
+	if !failed {
+		return
+	}
+	check("The failure is kept for display", strings.Contains(inst.Runtime.Failure(), "OnBar panicked"))
+	check("The stack trace is logged to the strategy log", errorsLogged(strategyLog, "OnBar stack trace") == 1 &&
+		errorsLogged(strategyLog, "failingRecorder") == 1)
+	check("The failure is announced with its message", announced()[0] == inst.Runtime.Failure())
+
+	err = e.StartStrategy(inst.ID)
+	check("A failed strategy cannot be started", err != nil && strings.Contains(err.Error(), "reset it"))
+	check("Parameters can still be changed", e.SetParam(inst.ID, "symbol", "MESH7") == nil)
+
+	check("Reset clears the failure", e.ResetStrategy(inst.ID) == nil &&
+		inst.Runtime.Status() == app.StrategyStopped && inst.Runtime.Failure() == "")
+	check("Only a failed strategy can be reset", e.ResetStrategy(inst.ID) != nil)
+	check("A reset strategy starts again", e.StartStrategy(inst.ID) == nil)
+	waitFor(func() bool { return len(announced()) == 2 })
+}
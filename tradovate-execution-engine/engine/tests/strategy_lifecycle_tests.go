@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/indicators"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/strategies"
+)
+
+// RunStrategyLifecycleTests executes the lifecycle guard tests and the
+// scripted start/stop cycle on demo data. Run the suite with -race to check
+// the cycle for data races.
+func RunStrategyLifecycleTests() {
+	testLifecycleTransitions()
+	testCreateStrategyIsConfigured()
+	testCrossoverLifecycleSequences()
+	testStrategyStartStopCycle()
+}
+
+// lifecycleOf returns the lifecycle state of a guarded strategy
+func lifecycleOf(s execution.Strategy) execution.LifecycleState {
+	if g, ok := s.(execution.LifecycleGuarded); ok {
+		return g.Lifecycle().LifecycleState()
+	}
+	return -1
+}
+
+func testLifecycleTransitions() {
+	type step struct {
+		name string
+		do   func(l *execution.StrategyLifecycle) error
+	}
+	configure := step{"configure", (*execution.StrategyLifecycle).BeginConfigure}
+	beginInit := step{"begin init", (*execution.StrategyLifecycle).BeginInit}
+	endInit := step{"end init", (*execution.StrategyLifecycle).EndInit}
+	run := step{"run", (*execution.StrategyLifecycle).MarkRunning}
+	stop := step{"stop", (*execution.StrategyLifecycle).MarkStopped}
+	reset := step{"reset", func(l *execution.StrategyLifecycle) error { l.ResetLifecycle(); return nil }}
+
+	cases := []struct {
+		name  string
+		steps []step
+		fails string                   // Error expected from the last step, "" if it succeeds
+		state execution.LifecycleState // After the last step
+	}{
+		{"Params are set on a created strategy", []step{configure}, "", execution.LifecycleConfigured},
+		{"A created strategy can be initialized with its defaults", []step{beginInit, endInit}, "", execution.LifecycleInitialized},
+		{"An initialized strategy runs", []step{configure, beginInit, endInit, run}, "", execution.LifecycleRunning},
+		{"A running strategy stops", []step{endInit, run, stop}, "", execution.LifecycleStopped},
+		{"A stopped strategy runs again", []step{endInit, run, stop, run}, "", execution.LifecycleRunning},
+		{"Stopping during warm-up", []step{endInit, stop}, "", execution.LifecycleStopped},
+		{"Stopping twice does nothing", []step{endInit, run, stop, stop}, "", execution.LifecycleStopped},
+		{"Reset returns to configured", []step{endInit, run, stop, reset}, "", execution.LifecycleConfigured},
+		{"Params can be set after reset", []step{endInit, run, reset, configure}, "", execution.LifecycleConfigured},
+		{"Init works after reset", []step{endInit, run, stop, reset, beginInit, endInit}, "", execution.LifecycleInitialized},
+		{"Params are refused once initialized", []step{endInit, configure}, "cannot modify parameters while the strategy is initialized", execution.LifecycleInitialized},
+		{"Params are refused while running", []step{endInit, run, configure}, "cannot modify parameters while the strategy is running", execution.LifecycleRunning},
+		{"Params are refused when stopped until reset", []step{endInit, run, stop, configure}, "reset it first", execution.LifecycleStopped},
+		{"Init is refused twice", []step{endInit, beginInit}, "already initialized (initialized)", execution.LifecycleInitialized},
+		{"Init is refused while running", []step{endInit, run, beginInit}, "already initialized (running)", execution.LifecycleRunning},
+		{"A configured strategy cannot run", []step{configure, run}, "initialize it first", execution.LifecycleConfigured},
+		{"A running strategy cannot run again", []step{endInit, run, run}, "already running", execution.LifecycleRunning},
+		{"A configured strategy cannot stop", []step{configure, stop}, "cannot stop a strategy that is configured", execution.LifecycleConfigured},
+	}
+
+	for _, tc := range cases {
+		var l execution.StrategyLifecycle
+		var err error
+		for _, st := range tc.steps {
+			err = st.do(&l)
+		}
+		ok := err == nil
+		if tc.fails != "" {
+			ok = err != nil && strings.Contains(err.Error(), tc.fails)
+		}
+		check(fmt.Sprintf("%s (%v)", tc.name, err), ok && l.LifecycleState() == tc.state)
+	}
+}
+
+func testCreateStrategyIsConfigured() {
+	quiet := logger.NewLogger(10, logger.LevelError)
+	for _, name := range []string{"ma_crossover", "donchian_breakout"} {
+		strat, err := execution.CreateStrategy(name, quiet)
+		check(name+" is created configured", err == nil && lifecycleOf(strat) == execution.LifecycleConfigured)
+	}
+
+	shared := strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	shared.Init(nil)
+	execution.Register("test_shared_instance", func(*logger.Logger) execution.Strategy { return shared })
+	_, err := execution.CreateStrategy("test_shared_instance", quiet)
+	check("A factory handing out an initialized instance is refused",
+		err != nil && strings.Contains(err.Error(), "already initialized"))
+}
+
+func testCrossoverLifecycleSequences() {
+	closes := []float64{100, 101, 102, 103, 104, 105}
+	feed := func(ma *strategies.MACrossover) {
+		for i, c := range closes {
+			ma.OnBar(fmt.Sprintf("T%d", i), c)
+		}
+	}
+
+	ma := strategies.NewMACrossover("MESH6", 2, 4, indicators.OnBarClose)
+	check("A new crossover is created", ma.LifecycleState() == execution.LifecycleCreated)
+	check("Bars are refused before Init", ma.OnBar("T0", 100) != nil)
+	check("Init succeeds", ma.Init(nil) == nil && ma.LifecycleState() == execution.LifecycleInitialized)
+	feed(ma)
+	check("Warmed-up crossover is ready", ma.IsReady())
+	check("Start marks it running", execution.StartLifecycle(ma) == nil && ma.LifecycleState() == execution.LifecycleRunning)
+	check("Params are refused while running", ma.SetParam("fast_length", "3") != nil)
+	check("Init is refused while running", ma.Init(nil) != nil)
+
+	check("Stop marks it stopped", execution.StopLifecycle(ma) == nil && ma.LifecycleState() == execution.LifecycleStopped)
+	check("Params are refused when stopped until reset", ma.SetParam("fast_length", "3") != nil)
+	ma.Reset()
+	check("Reset returns to configured", ma.LifecycleState() == execution.LifecycleConfigured && !ma.IsReady())
+	check("Bars are refused after reset", ma.OnBar("T9", 100) != nil)
+
+	check("Params can be changed after reset", ma.SetParam("fast_length", "3") == nil)
+	check("Init works again on the same instance", ma.Init(nil) == nil)
+	metrics := ma.GetMetrics()
+	check("Re-initialized averages start empty", metrics["Fast SMA"] == 0 && metrics["Slow SMA"] == 0 && !ma.IsReady())
+
+	// The same bar timestamps as the first run must be taken again, not skipped
+	feed(ma)
+	assertEqualsFloat("Fast SMA uses the new length", (103+104+105)/3.0, ma.GetMetrics()["Fast SMA"], 0.0001)
+	assertEqualsFloat("Slow SMA has only this run's bars", (102+103+104+105)/4.0, ma.GetMetrics()["Slow SMA"], 0.0001)
+	check("Start after re-initialization", execution.StartLifecycle(ma) == nil)
+
+	// A start that fails after Init resets the strategy, and it can start again
+	ma.Reset()
+	ma.SetParam("slow_length", "2")
+	check("Init refuses fast_length >= slow_length", ma.Init(nil) != nil && ma.LifecycleState() == execution.LifecycleConfigured)
+	ma.SetParam("slow_length", "5")
+	check("Init succeeds once params are fixed", ma.Init(nil) == nil)
+}
+
+func testStrategyStartStopCycle() {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	cfg := &config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Disconnect()
+
+	inst, err := e.AddStrategy("ma_crossover", map[string]string{"fast_length": "2", "slow_length": "3", "update_mode": "0"})
+	if err != nil {
+		check(fmt.Sprintf("Strategy is added (Error: %v)", err), false)
+		return
+	}
+
+	// The UI reads these on every tick and render while feed goroutines run the strategy
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			inst.Strategy().GetMetrics()
+			inst.Strategy().GetParams()
+			inst.Runtime.Status()
+			inst.Runtime.IsLive()
+			inst.WarmupProgress()
+			inst.Symbol()
+			e.Strategies()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	started, stopped := true, true
+	for i := 0; i < 3; i++ {
+		if err := e.StartStrategy(inst.ID); err != nil {
+			started = false
+			break
+		}
+		time.Sleep(300 * time.Millisecond)
+		if err := e.StopStrategy(inst.ID); err != nil || inst.Runtime.Status() != app.StrategyStopped {
+			stopped = false
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	check("Strategy starts again after each stop", started)
+	check("Strategy stops cleanly each time", stopped)
+	check("Nothing is left running", !e.AnyRunning())
+	check("Stopped strategy is configured again", lifecycleOf(inst.Strategy()) == execution.LifecycleConfigured)
+
+	// Stop, change a param and start again on the same instance
+	check("Params change after a stop", e.SetParam(inst.ID, "slow_length", "4") == nil)
+	check("Strategy starts with the new params", e.StartStrategy(inst.ID) == nil)
+	slow, _ := execution.FindParam(inst.Strategy(), "slow_length")
+	check("The new param is applied", slow.Value == "4")
+	// It is marked running when warm-up ends, so until then it is initialized
+	state := lifecycleOf(inst.Strategy())
+	check("Started strategy is initialized", state == execution.LifecycleInitialized || state == execution.LifecycleRunning)
+	waitFor(func() bool { return inst.Runtime.Status() == app.StrategyRunning })
+	check("Strategy stops again", e.StopStrategy(inst.ID) == nil && lifecycleOf(inst.Strategy()) == execution.LifecycleConfigured)
+}
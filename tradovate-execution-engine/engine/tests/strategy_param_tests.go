@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"strings"
+	"tradovate-execution-engine/engine/indicators"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/strategies"
+)
+
+// RunStrategyParamTests executes all tests for strategy parameter schemas.
+func RunStrategyParamTests() {
+	testParamValidate()
+	testParamRange()
+	testValidateParamsCrossField()
+	testEngineSetParamChecksSchema()
+	testAddStrategyRejectsInvalidOverrides()
+}
+
+func testParamValidate() {
+	length := execution.StrategyParam{Name: "slow_length", Type: "int", Min: execution.Bound(2), Max: execution.Bound(1000)}
+	check("Integer inside the range is accepted", length.Validate("21") == nil)
+	err := length.Validate("banana")
+	check("Non-numeric value names the param and the type", err != nil && strings.Contains(err.Error(), "slow_length must be a whole number"))
+	err = length.Validate("100000")
+	check("Value above Max is rejected with the range", err != nil && strings.Contains(err.Error(), "2..1000"))
+	check("Value below Min is rejected", length.Validate("1") != nil)
+
+	barType := execution.StrategyParam{Name: "bar_type", Type: "string", Options: []string{"minute", "volume", "tick"}}
+	check("Option is accepted in any case", barType.Validate("Volume") == nil)
+	check("Value outside the options is rejected", barType.Validate("range") != nil)
+
+	free := execution.StrategyParam{Name: "symbol", Type: "string"}
+	check("Unconstrained string accepts anything", free.Validate("MESH6") == nil)
+}
+
+func testParamRange() {
+	check("Range with both bounds", execution.StrategyParam{Min: execution.Bound(1), Max: execution.Bound(500)}.Range() == "1..500")
+	check("Range with only a minimum", execution.StrategyParam{Min: execution.Bound(0)}.Range() == ">= 0")
+	check("Range of options", execution.StrategyParam{Options: []string{"0", "1"}}.Range() == "0|1")
+	check("Range of an unconstrained param is empty", execution.StrategyParam{}.Range() == "")
+}
+
+func testValidateParamsCrossField() {
+	strategy := strategies.NewMACrossover("MESH6", 9, 21, indicators.OnBarClose)
+
+	check("Default MACrossover params are valid", execution.ValidateParams(strategy, nil) == nil)
+	err := execution.ValidateParams(strategy, map[string]string{"fast_length": "30"})
+	check("fast_length above slow_length is rejected by the strategy's hook",
+		err != nil && strings.Contains(err.Error(), "must be less than slow_length"))
+	check("Both lengths changed together are checked as a pair",
+		execution.ValidateParams(strategy, map[string]string{"fast_length": "30", "slow_length": "60"}) == nil)
+	check("Unknown param is rejected", execution.ValidateParams(strategy, map[string]string{"nope": "1"}) != nil)
+}
+
+func newParamTestEngine() *app.Engine {
+	log := logger.NewLogger(10, logger.LevelWarn)
+	return app.NewEngine(log, log, log)
+}
+
+func testEngineSetParamChecksSchema() {
+	e := newParamTestEngine()
+	inst, err := e.AddStrategy("ma_crossover", nil)
+	if err != nil {
+		check("ma_crossover instance is added", false)
+		return
+	}
+
+	err = e.SetParam(inst.ID, "slow_length", "100000")
+	check("SetParam rejects a value outside the range", err != nil && inst.Params()["slow_length"] != "100000")
+	check("SetParam rejects the wrong type", e.SetParam(inst.ID, "fast_length", "banana") != nil)
+	// Cross-field constraints wait for StartStrategy so params can change one at a time
+	check("SetParam accepts fast_length above the current slow_length", e.SetParam(inst.ID, "fast_length", "50") == nil)
+}
+
+func testAddStrategyRejectsInvalidOverrides() {
+	e := newParamTestEngine()
+	_, err := e.AddStrategy("ma_crossover", map[string]string{"fast_length": "21", "slow_length": "9"})
+	check("AddStrategy rejects overrides that break the strategy's constraints", err != nil)
+	check("No instance is left behind", len(e.Strategies()) == 0)
+}
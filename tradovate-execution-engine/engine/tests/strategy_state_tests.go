@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"tradovate-execution-engine/engine/internal/app"
+)
+
+// RunStrategyStateTests executes all tests for saving and restoring the last strategy configuration.
+func RunStrategyStateTests() {
+	testStrategyStateRoundTrip()
+	testStrategyStateDropsStaleParams()
+	testStrategyStateMissing()
+}
+
+func testStrategyStateRoundTrip() {
+	dir, _ := os.MkdirTemp("", "state")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state", "strategy.json")
+
+	e := newParamTestEngine()
+	inst, err := e.AddStrategy("ma_crossover", nil)
+	if err != nil {
+		check("ma_crossover instance is added", false)
+		return
+	}
+	e.SetParam(inst.ID, "symbol", "MNQ")
+	e.SetParam(inst.ID, "fast_length", "8")
+	e.SetParam(inst.ID, "slow_length", "40")
+	check("Strategy state is saved, creating its directory", e.SaveStrategy(inst.ID, path) == nil)
+
+	saved, err := app.LoadSavedStrategy(path)
+	check("Saved state names the strategy", err == nil && saved.Name == "ma_crossover" && saved.Params["fast_length"] == "8")
+
+	restored, dropped, err := newParamTestEngine().RestoreStrategy(path)
+	check("Restore adds an instance", err == nil && restored != nil)
+	if restored == nil {
+		return
+	}
+	params := restored.Params()
+	check("Restored instance has the saved parameters",
+		params["symbol"] == "MNQ" && params["fast_length"] == "8" && params["slow_length"] == "40" && len(dropped) == 0)
+}
+
+func testStrategyStateDropsStaleParams() {
+	dir, _ := os.MkdirTemp("", "state")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "strategy.json")
+
+	// Saved by a version that had a param since removed, with a value now out of range
+	os.WriteFile(path, []byte(`{"name":"ma_crossover","symbol":"MNQH6","params":{"fast_length":"7","slow_length":"100000","trail_ticks":"4"}}`), 0644)
+
+	e := newParamTestEngine()
+	inst, dropped, err := e.RestoreStrategy(path)
+	check("Restore tolerates stale parameters", err == nil && inst != nil)
+	if inst == nil {
+		return
+	}
+	check("Unknown and invalid parameters are dropped", strings.Join(dropped, ",") == "slow_length,trail_ticks")
+	params := inst.Params()
+	check("Dropped parameters keep their defaults", params["slow_length"] == "15" && params["fast_length"] == "7")
+	check("Missing symbol param is taken from the saved symbol", params["symbol"] == "MNQH6")
+	check("Dropped parameters are logged", strings.Contains(inst.Log.ExportToString(), "Dropping saved parameter trail_ticks"))
+}
+
+func testStrategyStateMissing() {
+	dir, _ := os.MkdirTemp("", "state")
+	defer os.RemoveAll(dir)
+
+	e := newParamTestEngine()
+	_, _, err := e.RestoreStrategy(filepath.Join(dir, "strategy.json"))
+	check("Restore without a saved file reports it missing", errors.Is(err, os.ErrNotExist))
+	check("No instance is added", len(e.Strategies()) == 0)
+
+	os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"name":"no_such_strategy","params":{}}`), 0644)
+	_, _, err = e.RestoreStrategy(filepath.Join(dir, "bad.json"))
+	check("Restore of a strategy that no longer exists fails", err != nil && len(e.Strategies()) == 0)
+}
@@ -32,6 +32,147 @@ func RunAllTests() {
 	runTest("MA Crossover Strategy Tests", RunMACrossoverTests)
 	logPrint("\n")
 	runTest("Risk Management Tests", RunRiskTests)
+	logPrint("\n")
+	runTest("Order Event Tests", RunOrderEventTests)
+	logPrint("\n")
+	runTest("Backtest Tests", RunBacktestTests)
+	logPrint("\n")
+	runTest("Rate Limit Tests", RunRateLimitTests)
+	logPrint("\n")
+	runTest("Chart Subscription Tests", RunChartSubscriptionTests)
+	logPrint("\n")
+	runTest("Logger Tests", RunLoggerTests)
+	logPrint("\n")
+	runTest("Account Tests", RunAccountTests)
+	logPrint("\n")
+	runTest("Bar Builder Tests", RunBarBuilderTests)
+	logPrint("\n")
+	runTest("Session Report Tests", RunSessionReportTests)
+	logPrint("\n")
+	runTest("Contract Tests", RunContractTests)
+	logPrint("\n")
+	runTest("Schedule Tests", RunScheduleTests)
+	logPrint("\n")
+	runTest("Order Fill Tests", RunOrderFillTests)
+	logPrint("\n")
+	runTest("DOM Tests", RunDOMTests)
+	logPrint("\n")
+	runTest("Token Cache Tests", RunTokenCacheTests)
+	logPrint("\n")
+	runTest("Order Error Tests", RunOrderErrorTests)
+	logPrint("\n")
+	runTest("Metrics Tests", RunMetricsTests)
+	logPrint("\n")
+	runTest("Trade Ledger Tests", RunTradeLedgerTests)
+
+	logPrint("\n")
+	runTest("Order Type Tests", RunOrderTypeTests)
+
+	logPrint("\n")
+	runTest("Strategy Param Tests", RunStrategyParamTests)
+
+	logPrint("\n")
+	runTest("Replay Tests", RunReplayTests)
+
+	logPrint("\n")
+	runTest("Contract Catalog Tests", RunCatalogTests)
+
+	logPrint("\n")
+	runTest("Latency Tests", RunLatencyTests)
+
+	logPrint("\n")
+	runTest("Kill Switch Tests", RunKillSwitchTests)
+
+	logPrint("\n")
+	runTest("History Tests", RunHistoryTests)
+	logPrint("\n")
+	runTest("Warmup Tests", RunWarmupTests)
+	logPrint("\n")
+	runTest("Balance Tests", RunBalanceTests)
+	logPrint("\n")
+	runTest("OCO Tests", RunOCOTests)
+	logPrint("\n")
+	runTest("Quote Queue Tests", RunQuoteQueueTests)
+	logPrint("\n")
+	runTest("Flatten Plan Tests", RunFlattenPlanTests)
+	logPrint("\n")
+	runTest("Origin Tests", RunOriginTests)
+	logPrint("\n")
+	runTest("Order Decode Tests", RunOrderDecodeTests)
+	logPrint("\n")
+	runTest("Fee Tests", RunFeeTests)
+	logPrint("\n")
+	runTest("Chart Error Tests", RunChartErrorTests)
+	logPrint("\n")
+	runTest("Loss Streak Tests", RunLossStreakTests)
+	logPrint("\n")
+	runTest("Mock Market Tests", RunMockMarketTests)
+	logPrint("\n")
+	runTest("Quote Subscription Tests", RunQuoteSubscriptionTests)
+	logPrint("\n")
+	runTest("External Order Tests", RunExternalOrderTests)
+	logPrint("\n")
+	runTest("Strategy State Tests", RunStrategyStateTests)
+	logPrint("\n")
+	runTest("Risk Supervisor Tests", RunRiskSupervisorTests)
+	logPrint("\n")
+	runTest("Props Routing Tests", RunPropsRoutingTests)
+	logPrint("\n")
+	runTest("Order Throttle Tests", RunOrderThrottleTests)
+	logPrint("\n")
+	runTest("WebSocket Error Tests", RunWSErrorTests)
+	logPrint("\n")
+	runTest("Position Alert Tests", RunPositionAlertTests)
+	logPrint("\n")
+	runTest("Entry Policy Tests", RunEntryPolicyTests)
+	logPrint("\n")
+	runTest("Strategy Lifecycle Tests", RunStrategyLifecycleTests)
+	logPrint("\n")
+	runTest("Session PnL Tests", RunSessionPnLTests)
+	logPrint("\n")
+	runTest("WebSocket Send Queue Tests", RunWSSendQueueTests)
+	logPrint("\n")
+	runTest("Config Validation Tests", RunConfigValidationTests)
+	logPrint("\n")
+	runTest("Strategy Error Tests", RunStrategyErrorTests)
+	logPrint("\n")
+	runTest("Donchian Tests", RunDonchianTests)
+	logPrint("\n")
+	runTest("Mark Price Tests", RunMarkPriceTests)
+	logPrint("\n")
+	runTest("Data Subscriber Tests", RunDataSubscriberTests)
+	logPrint("\n")
+	runTest("Admin API Tests", RunAdminTests)
+	logPrint("\n")
+	runTest("News Lockout Tests", RunNewsTests)
+	logPrint("\n")
+	runTest("Live Interlock Tests", RunLiveInterlockTests)
+	logPrint("\n")
+	runTest("Metrics Recorder Tests", RunMetricsRecorderTests)
+	logPrint("\n")
+	runTest("Position Drift Tests", RunPositionDriftTests)
+	logPrint("\n")
+	runTest("Strategy Clone Tests", RunStrategyCloneTests)
+	logPrint("\n")
+	runTest("Quote Parsing Tests", RunQuoteParsingTests)
+	logPrint("\n")
+	runTest("Order Queue Tests", RunOrderQueueTests)
+	logPrint("\n")
+	runTest("Crossover Order Tests", RunCrossoverOrderTests)
+	logPrint("\n")
+	runTest("Slippage Tests", RunSlippageTests)
+	logPrint("\n")
+	runTest("Reauth Tests", RunReauthTests)
+	logPrint("\n")
+	runTest("Audit Tests", RunAuditTests)
+	logPrint("\n")
+	runTest("UI Command Tests", RunUICommandTests)
+	logPrint("\n")
+	runTest("Order Status Tests", RunOrderStatusTests)
+	logPrint("\n")
+	runTest("Config Diff Tests", RunConfigDiffTests)
+	logPrint("\n")
+	runTest("Trailing Stop Tests", RunTrailingStopTests)
 
 	logPrint("=======================================")
 	logPrintf("Test Run Complete. Total: %d, Passed: %d, Failed: %d\n", totalTests, totalTests-failedTests, failedTests)
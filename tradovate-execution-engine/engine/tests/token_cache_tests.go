@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/auth"
+)
+
+// RunTokenCacheTests executes all tests for persisted session reuse.
+func RunTokenCacheTests() {
+	testTokenCacheReusedOnRestart()
+	testTokenCacheInvalidatedOnCredentialChange()
+	testTokenCacheInvalidatedOn401()
+	testTokenManagersIndependent()
+}
+
+// fakeAuthServer is Tradovate's /v1/auth for userID, 7 to start with. It
+// counts logins and renewals and numbers login tokens "login-token-1" on; its
+// tokens expire after expiresIn and renewals fail with 401 while rejectRenew is set.
+type fakeAuthServer struct {
+	srv         *httptest.Server
+	logins      atomic.Int32
+	renewals    atomic.Int32
+	userID      atomic.Int32
+	rejectRenew atomic.Bool
+	expiresIn   time.Duration
+}
+
+func newFakeAuthServer(expiresIn time.Duration) *fakeAuthServer {
+	f := &fakeAuthServer{expiresIn: expiresIn}
+	f.userID.Store(7)
+	f.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := ""
+		switch r.URL.Path {
+		case "/v1/auth/accesstokenrequest":
+			token = fmt.Sprintf("login-token-%d", f.logins.Add(1))
+		case "/v1/auth/renewaccesstoken":
+			f.renewals.Add(1)
+			if f.rejectRenew.Load() {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			token = "renewed-token"
+		default:
+			// Every other endpoint treats the session as revoked
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"accessToken":    token,
+			"mdAccessToken":  "md-" + token,
+			"expirationTime": time.Now().Add(f.expiresIn),
+			"userId":         f.userID.Load(),
+			"name":           "tester",
+		})
+	}))
+	return f
+}
+
+// newCachingTokenManager builds a fresh token manager pointed at the fake server
+func newCachingTokenManager(cfg *config.Config, baseURL, cachePath string) *auth.TokenManager {
+	tm := auth.NewTokenManager(cfg)
+	tm.SetSessionForTest(baseURL, "", 0)
+	tm.EnableTokenCache(cachePath)
+	return tm
+}
+
+func tokenCacheTestConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Tradovate.Username = "tester"
+	cfg.Tradovate.Password = "secret"
+	cfg.Tradovate.DeviceID = "device-1"
+	cfg.Tradovate.Environment = "demo"
+	cfg.Tradovate.CacheTokens = true
+	return cfg
+}
+
+func testTokenCacheReusedOnRestart() {
+	f := newFakeAuthServer(time.Hour)
+	defer f.srv.Close()
+	dir, _ := os.MkdirTemp("", "token-cache")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token_cache.json")
+	cfg := tokenCacheTestConfig()
+
+	tm := newCachingTokenManager(cfg, f.srv.URL, path)
+	err := tm.Login()
+	check("First start logs in with credentials", err == nil && f.logins.Load() == 1 && f.renewals.Load() == 0)
+
+	info, err := os.Stat(path)
+	check("Token cache is written readable only by the owner", err == nil && info.Mode().Perm() == 0600)
+
+	tm = newCachingTokenManager(cfg, f.srv.URL, path)
+	err = tm.Login()
+	token, _ := tm.GetAccessToken()
+	check("Restart renews the cached session instead of logging in",
+		err == nil && f.logins.Load() == 1 && f.renewals.Load() == 1 && token == "renewed-token")
+	check("Cached user ID is restored", tm.GetUserID() == 7)
+}
+
+func testTokenCacheInvalidatedOnCredentialChange() {
+	f := newFakeAuthServer(time.Hour)
+	defer f.srv.Close()
+	dir, _ := os.MkdirTemp("", "token-cache")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token_cache.json")
+	cfg := tokenCacheTestConfig()
+
+	newCachingTokenManager(cfg, f.srv.URL, path).Login()
+
+	cfg.Tradovate.DeviceID = "device-2"
+	newCachingTokenManager(cfg, f.srv.URL, path).Login()
+	check("Changed device ID forces a full login", f.logins.Load() == 2 && f.renewals.Load() == 0)
+
+	cfg.Tradovate.Environment = "live"
+	newCachingTokenManager(cfg, f.srv.URL, path).Login()
+	check("Changed environment forces a full login", f.logins.Load() == 3 && f.renewals.Load() == 0)
+}
+
+func testTokenCacheInvalidatedOn401() {
+	f := newFakeAuthServer(time.Hour)
+	defer f.srv.Close()
+	dir, _ := os.MkdirTemp("", "token-cache")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token_cache.json")
+	cfg := tokenCacheTestConfig()
+
+	newCachingTokenManager(cfg, f.srv.URL, path).Login()
+
+	// The server has revoked the session
+	f.rejectRenew.Store(true)
+	tm := newCachingTokenManager(cfg, f.srv.URL, path)
+	err := tm.Login()
+	token, _ := tm.GetAccessToken()
+	check("Rejected renewal falls back to a full login",
+		err == nil && f.renewals.Load() == 1 && f.logins.Load() == 2 && token == "login-token-2")
+
+	tm = newCachingTokenManager(cfg, f.srv.URL, path)
+	resp, err := tm.MakeAuthenticatedRequest("GET", "/v1/account/list", nil, token)
+	if err == nil {
+		resp.Body.Close()
+	}
+	_, statErr := os.Stat(path)
+	check("A 401 response deletes the token cache", os.IsNotExist(statErr))
+}
+
+func testTokenManagersIndependent() {
+	demoCfg := tokenCacheTestConfig()
+	liveCfg := tokenCacheTestConfig()
+	liveCfg.Tradovate.Environment = "live"
+
+	demo := auth.NewTokenManager(demoCfg)
+	live := auth.NewTokenManager(liveCfg)
+	check("Each call creates its own token manager", demo != live)
+	check("First manager keeps its environment",
+		demo.GetBaseURL() == config.GetHTTPBaseURL("demo") && live.GetBaseURL() == config.GetHTTPBaseURL("live"))
+
+	demo.SetSessionForTest("http://127.0.0.1:1", "demo-token", 1)
+	token, _ := demo.GetAccessToken()
+	check("Session of one manager is not shared", token == "demo-token" && demo.IsAuthenticated() && !live.IsAuthenticated())
+	check("Environments cache to separate files", auth.TokenCachePath("demo") != auth.TokenCachePath("live"))
+	check("Deprecated accessor returns the newest manager", auth.GetTokenManager() == live)
+}
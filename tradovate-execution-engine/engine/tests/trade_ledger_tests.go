@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunTradeLedgerTests executes all tests for per-trade realized PnL from fill pairs.
+func RunTradeLedgerTests() {
+	testLongTradeFromUserSync()
+	testShortTradeFromProps()
+	testTradesInSessionReport()
+}
+
+// newLedgerTracker starts a tracker that has synced MESH6 (id 100, $5 a point)
+// with fills forwarded the way the engine does
+func newLedgerTracker(sync string) (*portfolio.PortfolioTracker, *tradovate.DataSubscriber) {
+	log := logger.NewLogger(10, logger.LevelInfo)
+	trading := tradovate.NewDataSubscriptionManager(nullSender{})
+	md := tradovate.NewDataSubscriptionManager(nullSender{})
+	pt := portfolio.NewPortfolioTracker(trading, md, 1, 0, log)
+	trading.OnFillUpdate = pt.RecordFill
+	pt.Start("demo")
+
+	if sync == "" {
+		sync = `{"users":[{"id":1}]}`
+	}
+	var payload map[string]interface{}
+	json.Unmarshal([]byte(sync), &payload)
+	payload["contracts"] = []map[string]interface{}{{"id": 100, "name": "MESH6"}}
+	payload["products"] = []map[string]interface{}{{"name": "MES", "valuePerPoint": 5, "tickSize": 0.25}}
+	data, _ := json.Marshal(payload)
+	trading.HandleEvent("user/syncrequest", data)
+	return pt, trading
+}
+
+func testLongTradeFromUserSync() {
+	pt, _ := newLedgerTracker(`{"users":[{"id":1}],
+		"positions":[{"id":7,"accountId":1,"contractId":100,"netPos":0}],
+		"fills":[
+			{"id":1,"orderId":11,"contractId":100,"timestamp":"2026-01-05T15:00:00Z","action":"Buy","qty":2,"price":5000},
+			{"id":2,"orderId":12,"contractId":100,"timestamp":"2026-01-05T15:05:00Z","action":"Sell","qty":2,"price":5003.5}],
+		"fillPairs":[{"id":50,"positionId":7,"buyFillId":1,"sellFillId":2,"qty":2,"buyPrice":5000,"sellPrice":5003.5}]}`)
+
+	trades := pt.GetClosedTrades()
+	check("Fill pair from user sync becomes a closed trade", len(trades) == 1)
+	if len(trades) != 1 {
+		return
+	}
+	t := trades[0]
+	check("Buy first is a long trade", t.Side == "Long" && t.Symbol == "MESH6" && t.Qty == 2)
+	check("Long entry and exit prices", t.EntryPrice == 5000 && t.ExitPrice == 5003.5)
+	assertEqualsFloat("Trade points", 3.5, t.Points, 0.0001)
+	assertEqualsFloat("Trade PnL uses value per point", 35, t.PnL, 0.0001)
+	check("Trade times come from its fills",
+		t.EntryTime.Equal(time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)) && t.ExitTime.Equal(time.Date(2026, 1, 5, 15, 5, 0, 0, time.UTC)))
+}
+
+func testShortTradeFromProps() {
+	pt, trading := newLedgerTracker("")
+	props := func(entityType, entity string) {
+		trading.HandleEvent("props", json.RawMessage(`{"entityType":"`+entityType+`","entity":`+entity+`}`))
+	}
+
+	// A later trade listed first, and its pair ahead of its fills
+	props("fillPair", `{"id":61,"positionId":7,"buyFillId":4,"sellFillId":3,"qty":1,"buyPrice":5010,"sellPrice":5006}`)
+	props("fill", `{"id":3,"orderId":13,"contractId":100,"timestamp":"2026-01-05T16:00:00Z","action":"Sell","qty":1,"price":5006}`)
+	props("fill", `{"id":4,"orderId":14,"contractId":100,"timestamp":"2026-01-05T16:02:00Z","action":"Buy","qty":1,"price":5010}`)
+
+	props("fill", `{"id":5,"orderId":15,"contractId":100,"timestamp":"2026-01-05T15:30:00Z","action":"Buy","qty":1,"price":5000}`)
+	props("fill", `{"id":6,"orderId":16,"contractId":100,"timestamp":"2026-01-05T15:31:00Z","action":"Sell","qty":1,"price":5001}`)
+	props("fillPair", `{"id":60,"positionId":7,"buyFillId":5,"sellFillId":6,"qty":1,"buyPrice":5000,"sellPrice":5001}`)
+
+	trades := pt.GetClosedTrades()
+	check("Props fill pairs are added to the ledger", len(trades) == 2)
+	if len(trades) != 2 {
+		return
+	}
+	check("Closed trades are chronological", trades[0].ID == 60 && trades[1].ID == 61)
+	short := trades[1]
+	check("Sell first is a short trade", short.Side == "Short" && short.EntryPrice == 5006 && short.ExitPrice == 5010)
+	assertEqualsFloat("Losing short PnL", -20, short.PnL, 0.0001)
+}
+
+func testTradesInSessionReport() {
+	start := time.Date(2026, 1, 5, 15, 15, 0, 0, time.UTC)
+	snap := portfolio.SessionSnapshot{
+		SessionStart: start,
+		Trades: []portfolio.ClosedTrade{
+			{ID: 1, Symbol: "MESH6", Side: "Long", Qty: 1, EntryPrice: 5000, ExitPrice: 5002, Points: 2, PnL: 10, ExitTime: start.Add(-time.Minute)},
+			{ID: 2, Symbol: "MESH6", Side: "Short", Qty: 1, EntryPrice: 5004, ExitPrice: 5001, Points: 3, PnL: 15, ExitTime: start.Add(time.Minute)},
+		},
+	}
+	report := portfolio.BuildSessionReport(snap, nil, nil)
+	check("Report keeps only trades closed in the session", len(report.Trades) == 1 && report.Trades[0].ID == 2)
+	check("Text report lists the trade", strings.Contains(report.Text(), "MESH6      Short   1  5004.00 -> 5001.00  +3.00 pts  $15.00"))
+}
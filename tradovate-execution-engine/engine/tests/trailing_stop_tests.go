@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/internal/portfolio"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunTrailingStopTests executes all tests for stops that follow price
+func RunTrailingStopTests() {
+	testTrailingStopFollowsPrice()
+	testTrailingStopBrokerCallsUnlocked()
+	testTrailingStopReplacedWhenGone()
+	testTrailingStopFilled()
+	testTrailingStopReleasesQuotes()
+	testTrailingStopMinStep()
+}
+
+// trailingStops is a trailing stop manager for a MESH6 position with the order
+// manager and exchange behind it and the feeds its events are delivered on
+type trailingStops struct {
+	tsm     *execution.TrailingStopManager
+	om      *execution.OrderManager
+	ex      *ocoExchange
+	md      *tradovate.DataSubscriber
+	trading *tradovate.DataSubscriber
+}
+
+// newTrailingStops returns trailing stops for a MESH6 position of netPos
+func newTrailingStops(netPos int) (*trailingStops, func()) {
+	log := logger.NewLogger(50, logger.LevelWarn)
+	om, ex, cleanup := newOCOOrderManager(log)
+	trading := tradovate.NewDataSubscriptionManager(nullSender{})
+	md := tradovate.NewDataSubscriptionManager(nullSender{})
+	pt := portfolio.NewPortfolioTracker(trading, md, 1, 0, log)
+	pt.Start("demo")
+	trading.HandleEvent("user/syncrequest", json.RawMessage(fmt.Sprintf(`{"users":[{"id":1}],
+		"positions":[{"id":7,"accountId":1,"contractId":100,"netPos":%d,"netPrice":5000}],
+		"contracts":[{"id":100,"name":"MESH6"}],
+		"products":[{"name":"MES","valuePerPoint":5,"tickSize":0.25}]}`, netPos)))
+	om.SetPortfolioTracker(pt)
+	return &trailingStops{tsm: execution.NewTrailingStopManager(om, pt, md, log), om: om, ex: ex, md: md, trading: trading}, cleanup
+}
+
+// tradeAt delivers a MESH6 trade at price
+func tradeAt(md *tradovate.DataSubscriber, price float64) {
+	md.HandleEvent(marketdata.EventMarketData, json.RawMessage(fmt.Sprintf(`{"quotes":[{"contractId":100,"entries":{"Trade":{"price":%g,"size":1}}}]}`, price)))
+}
+
+// activeStop returns the price of the MESH6 trailing stop, 0 when none is placed
+func activeStop(tsm *execution.TrailingStopManager) float64 {
+	for _, ts := range tsm.GetActive() {
+		if ts.Symbol == "MESH6" && ts.StopOrder != nil {
+			return ts.StopPrice
+		}
+	}
+	return 0
+}
+
+// exchangeCounts returns how many orders ex placed, cancelled and modified
+func exchangeCounts(ex *ocoExchange) (placed, cancelled, modified int) {
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	return len(ex.placed), len(ex.cancelled), len(ex.modified)
+}
+
+// quoteSubscriptions returns how many references md holds on MESH6 quotes
+func quoteSubscriptions(md *tradovate.DataSubscriber) int {
+	for _, info := range md.GetActiveSubscriptions() {
+		if info.Endpoint == "md/subscribequote" && info.Params["symbol"] == "MESH6" {
+			return info.RefCount
+		}
+	}
+	return 0
+}
+
+func testTrailingStopFollowsPrice() {
+	ts, cleanup := newTrailingStops(1)
+	defer cleanup()
+	tsm, ex, md := ts.tsm, ts.ex, ts.md
+
+	tradeAt(md, 5000)
+	if err := tsm.Start("MESH6", 4, 1); err != nil {
+		check(fmt.Sprintf("Trailing stop starts (Error: %v)", err), false)
+		return
+	}
+	check("Start places the stop 4 ticks below the last price", activeStop(tsm) == 4999)
+
+	tradeAt(md, 5002)
+	check("A higher trade moves the stop up", waitFor(func() bool { return activeStop(tsm) == 5001 }))
+	tradeAt(md, 4999.5)
+	time.Sleep(20 * time.Millisecond)
+	placed, _, modified := exchangeCounts(ex)
+	check("A lower trade leaves the stop where it is", activeStop(tsm) == 5001 && placed == 1 && modified == 1)
+
+	check("Stop succeeds", tsm.Stop("MESH6") == nil)
+	_, cancelled, _ := exchangeCounts(ex)
+	check("Stop cancels the stop order", cancelled == 1 && len(tsm.GetActive()) == 0)
+}
+
+func testTrailingStopBrokerCallsUnlocked() {
+	ts, cleanup := newTrailingStops(1)
+	defer cleanup()
+	tsm, ex, md := ts.tsm, ts.ex, ts.md
+
+	tradeAt(md, 5000)
+	if err := tsm.Start("MESH6", 4, 1); err != nil {
+		check(fmt.Sprintf("Trailing stop starts (Error: %v)", err), false)
+		return
+	}
+
+	hold := make(chan struct{})
+	ex.mu.Lock()
+	ex.hold = hold
+	ex.mu.Unlock()
+	released := false
+	release := func() {
+		if !released {
+			close(hold)
+			released = true
+		}
+	}
+	defer release()
+
+	go tradeAt(md, 5002)
+	if !waitFor(func() bool { ex.mu.Lock(); defer ex.mu.Unlock(); return ex.held == 1 }) {
+		check("The stop move reaches the exchange", false)
+		return
+	}
+
+	answered := make(chan struct{})
+	go func() {
+		tsm.GetActive()
+		close(answered)
+	}()
+	select {
+	case <-answered:
+		check("The manager answers while a stop move is with the broker", true)
+	case <-time.After(time.Second):
+		check("The manager answers while a stop move is with the broker", false)
+	}
+
+	release()
+	check("The held move is applied", waitFor(func() bool { return activeStop(tsm) == 5001 }))
+	tradeAt(md, 5003)
+	check("The stop moves again once the broker answers", waitFor(func() bool { return activeStop(tsm) == 5002 }))
+	placed, _, modified := exchangeCounts(ex)
+	check("Each trade sent one request", placed == 1 && modified == 2)
+}
+
+func testTrailingStopReplacedWhenGone() {
+	ts, cleanup := newTrailingStops(1)
+	defer cleanup()
+
+	tradeAt(ts.md, 5000)
+	if err := ts.tsm.Start("MESH6", 4, 1); err != nil {
+		check(fmt.Sprintf("Trailing stop starts (Error: %v)", err), false)
+		return
+	}
+	first := ts.tsm.GetActive()[0].StopOrder
+	orderEvent(ts.om, first.ID, "Canceled")
+
+	tradeAt(ts.md, 5002)
+	placed := func() int { n, _, _ := exchangeCounts(ts.ex); return n }
+	check("A stop cancelled at the broker is placed again", waitFor(func() bool { return placed() == 2 && activeStop(ts.tsm) == 5001 }))
+	_, cancelled, modified := exchangeCounts(ts.ex)
+	check("The cancelled stop is not modified or cancelled again", modified == 0 && cancelled == 0)
+	if active := ts.tsm.GetActive(); len(active) == 1 && active[0].StopOrder != nil {
+		check("The new stop replaces the cancelled one", active[0].StopOrder.ID != first.ID)
+	}
+}
+
+func testTrailingStopFilled() {
+	ts, cleanup := newTrailingStops(1)
+	defer cleanup()
+
+	tradeAt(ts.md, 5000)
+	if err := ts.tsm.Start("MESH6", 4, 1); err != nil {
+		check(fmt.Sprintf("Trailing stop starts (Error: %v)", err), false)
+		return
+	}
+	fillLeg(ts.om, ts.tsm.GetActive()[0].StopOrder.ID, 1, 1)
+	ts.trading.HandleEvent("props", json.RawMessage(`{"entityType":"position","entity":{"id":7,"accountId":1,"contractId":100,"netPos":0,"netPrice":0}}`))
+
+	tradeAt(ts.md, 4998)
+	check("A filled stop ends the trail", waitFor(func() bool { return len(ts.tsm.GetActive()) == 0 }))
+	placed, cancelled, _ := exchangeCounts(ts.ex)
+	check("A filled stop is not cancelled or replaced", placed == 1 && cancelled == 0)
+}
+
+func testTrailingStopReleasesQuotes() {
+	ts, cleanup := newTrailingStops(1)
+	defer cleanup()
+
+	// The portfolio tracker holds its own reference for the open position
+	tradeAt(ts.md, 5000)
+	before := quoteSubscriptions(ts.md)
+	if err := ts.tsm.Start("MESH6", 4, 1); err != nil {
+		check(fmt.Sprintf("Trailing stop starts (Error: %v)", err), false)
+		return
+	}
+	check("A trailing stop subscribes to quotes", quoteSubscriptions(ts.md) == before+1)
+	check("A second trail on the symbol is refused", ts.tsm.Start("MESH6", 4, 1) != nil)
+	check("The refused trail keeps no quotes", quoteSubscriptions(ts.md) == before+1)
+	ts.tsm.Stop("MESH6")
+	check("Stop releases the quotes", quoteSubscriptions(ts.md) == before)
+
+	ts.tsm.Start("MESH6", 4, 1)
+	ts.trading.HandleEvent("props", json.RawMessage(`{"entityType":"position","entity":{"id":7,"accountId":1,"contractId":100,"netPos":0,"netPrice":0}}`))
+	held := quoteSubscriptions(ts.md)
+	tradeAt(ts.md, 5001)
+	check("A trail removed on a flat position releases the quotes", waitFor(func() bool {
+		return len(ts.tsm.GetActive()) == 0 && quoteSubscriptions(ts.md) == held-1
+	}))
+}
+
+func testTrailingStopMinStep() {
+	ts, cleanup := newTrailingStops(1)
+	defer cleanup()
+
+	tradeAt(ts.md, 5000)
+	if err := ts.tsm.Start("MESH6", 4, 3); err != nil {
+		check(fmt.Sprintf("Trailing stop starts (Error: %v)", err), false)
+		return
+	}
+	tradeAt(ts.md, 5000.5)
+	time.Sleep(20 * time.Millisecond)
+	check("A move under the min step leaves the stop", activeStop(ts.tsm) == 4999)
+	tradeAt(ts.md, 5000.75)
+	check("A move of the min step moves the stop", waitFor(func() bool { return activeStop(ts.tsm) == 4999.75 }))
+
+	ts.tsm.Stop("MESH6")
+	tradeAt(ts.md, 5000)
+	ts.tsm.Start("MESH6", 4, 1)
+	tradeAt(ts.md, 5000.25)
+	check("A later trail keeps its own min step", waitFor(func() bool { return activeStop(ts.tsm) == 4999.25 }))
+}
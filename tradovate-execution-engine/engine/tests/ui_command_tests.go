@@ -0,0 +1,309 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"tradovate-execution-engine/engine/UI"
+	"tradovate-execution-engine/engine/config"
+	"tradovate-execution-engine/engine/internal/app"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/models"
+	"tradovate-execution-engine/engine/internal/testsupport"
+)
+
+// RunUICommandTests executes all tests for the command bar's registered handlers
+func RunUICommandTests() {
+	testUICommandDispatch()
+	testUIOrderCommands()
+	testUIOrderFields()
+	testUIStrategyCommands()
+	testUIFlattenCommands()
+	testUIClosePosition()
+	testUIReloadSlippage()
+	testUIReloadAllOrNothing()
+}
+
+// commandCase is one line of command bar input and part of the status it should leave
+type commandCase struct {
+	name  string
+	input string
+	want  string
+}
+
+// runCommandCases runs each case in order on s, so a case sees what earlier ones did
+func runCommandCases(s *UI.CommandSession, cases []commandCase) {
+	for _, c := range cases {
+		status := s.Run(c.input)
+		check(c.name, strings.Contains(status, c.want))
+		if !strings.Contains(status, c.want) {
+			logPrintf("    %s: got %q, want %q\n", c.input, status, c.want)
+		}
+	}
+}
+
+// newCommandSession returns a session on a disconnected engine
+func newCommandSession() *UI.CommandSession {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	return UI.NewCommandSession(app.NewEngine(quiet, quiet, quiet), quiet)
+}
+
+func testUICommandDispatch() {
+	runCommandCases(newCommandSession(), []commandCase{
+		{"An unknown command is reported", ":bogus", "Unknown command: bogus"},
+		{"A command runs without its colon", "help", "Switched to Commands"},
+		{"Missing arguments show the usage", ":clone", "Usage: :clone <symbol>"},
+		{"A command's own checks still apply", ":mode sideways", "Invalid mode"},
+		{"Mode takes its full name", ":mode live", "Switched to LIVE mode"},
+	})
+}
+
+func testUIOrderCommands() {
+	runCommandCases(newCommandSession(), []commandCase{
+		{"Buy needs a connection", ":buy MESH6 1", "Must be connected to API to trade"},
+	})
+
+	broker := testsupport.NewFakeBroker()
+	s := newCommandSession()
+	s.SetBroker(broker)
+	runCommandCases(s, []commandCase{
+		{"Buy is refused in Visual mode", ":buy MESH6 1", "Cannot place orders in Visual mode"},
+	})
+
+	s.SetLive(true)
+	runCommandCases(s, []commandCase{
+		{"Buy without a quantity shows the usage", ":buy MESH6", "Usage: :buy <symbol> <quantity>"},
+		{"Buy needs a whole quantity", ":buy MESH6 one", "Invalid quantity format"},
+		{"Sell with a bad order type shows the usage", ":sell MESH6 1 limit", "Usage: :sell"},
+		{"Buy places a market order", ":buy MESH6 2", "BUY order placed for MESH6 (ID: fake-1)"},
+		{"Sell places a limit order", ":sell MESH6 1 limit 5000.25 gtc", "SELL order placed for MESH6 (ID: fake-2)"},
+		{"A price off the tick is not sent", ":buy MESH6 1 limit 5000.1", "Order not sent: price 5000.1 is not on the 0.25 tick"},
+	})
+	check("Only valid orders reach the broker", len(broker.Orders()) == 2)
+
+	broker.FailSubmits(errors.New("broker down"))
+	runCommandCases(s, []commandCase{
+		{"A broker error is shown", ":buy MESH6 1", "Order failed: broker down"},
+	})
+}
+
+func testUIOrderFields() {
+	broker := testsupport.NewFakeBroker()
+	s := newCommandSession()
+	s.SetBroker(broker)
+	s.SetLive(true)
+
+	s.Run(":buy MESH6 2")
+	s.Run(":sell MESH6 1 limit 5000.25 gtc")
+	orders := broker.Orders()
+	if len(orders) != 2 {
+		check(fmt.Sprintf("Both orders reach the broker (got %d)", len(orders)), false)
+		return
+	}
+	buy, sell := orders[0], orders[1]
+	check("Buy is a market order for the quantity typed", buy.Side == models.SideBuy && buy.Type == models.TypeMarket && buy.Quantity == 2)
+	check("Sell carries its limit and time in force", sell.Side == models.SideSell && sell.Type == models.TypeLimit &&
+		sell.Price == 5000.25 && sell.TimeInForce == models.TIFGTC)
+	check("Command bar orders are manual", buy.Origin == models.OriginManual && sell.Origin == models.OriginManual)
+}
+
+func testUIStrategyCommands() {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	s := UI.NewCommandSession(e, quiet)
+
+	// Each case runs on the state the ones before it left
+	runCommandCases(s, []commandCase{
+		{"Set needs a strategy", ":set fast_length 8", "No strategy selected"},
+		{"Start needs a strategy", ":start", "No strategy selected"},
+		{"Stop needs a strategy", ":stop", "No strategy selected"},
+		{"An unknown strategy is not added", ":strategy add nope", "Failed to load strategy"},
+		{"Strategy add loads an instance", ":strategy add ma_crossover", "Loaded strategy"},
+		{"Set without a value shows the usage", ":set fast_length", "Usage: :set <param> <value>"},
+		{"Set refuses a bad value", ":set fast_length abc", "Cannot set parameter"},
+		{"Set changes a parameter", ":set fast_length 8", "Set fast_length = 8"},
+		{"Stop reports an instance that is not running", ":stop", "is not running"},
+		{"Start names an unknown instance", ":start 99", "No strategy selected"},
+		{"Start needs a connection", ":start", "Cannot start strategy"},
+		{"A strategy name on its own adds an instance", ":strategy ma_crossover", "Loaded strategy"},
+	})
+
+	list := e.Strategies()
+	if len(list) != 2 {
+		check(fmt.Sprintf("Two instances are added (got %d)", len(list)), false)
+		return
+	}
+	first, second := list[0].ID, list[1].ID
+	check("The added instance is shown", s.Selected() == second)
+	runCommandCases(s, []commandCase{
+		{"Strategy <id> shows an instance", ":strategy " + first, "Showing strategy"},
+		{"Strategy remove drops an instance", ":strategy remove " + second, "Removed strategy " + second},
+		{"Strategy restore adds the last saved one", ":strategy restore", "Restored strategy"},
+	})
+	check("Show selects the instance", len(e.Strategies()) == 2 && e.Strategies()[0].ID == first)
+	if list := e.Strategies(); len(list) == 2 {
+		check("Restore keeps the saved parameters", list[1].Params()["fast_length"] == "8")
+	}
+}
+
+func testUIFlattenCommands() {
+	runCommandCases(newCommandSession(), []commandCase{
+		{"Flatten needs a connection", ":flatten", "Must be connected to API to flatten positions"},
+		{"Flatten! needs a connection", ":flatten!", "Must be connected to API to flatten positions"},
+	})
+
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	// A loss limit, or the risk supervisor takes the flat start as a breach
+	cfg := &config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Shutdown(false)
+	s := UI.NewCommandSession(e, quiet)
+
+	runCommandCases(s, []commandCase{
+		{"Flatten is refused in Visual mode", ":flatten", "Cannot flatten in Visual mode"},
+	})
+	s.SetLive(true)
+	runCommandCases(s, []commandCase{
+		{"Flatten with nothing open says so", ":flatten", "Nothing to flatten"},
+	})
+
+	if !openDemoPosition(e) {
+		return
+	}
+	pt := e.Portfolio()
+
+	runCommandCases(s, []commandCase{
+		{"Flatten previews an open position", ":flatten", "Send the flatten shown above? y/n"},
+	})
+	check("Flatten waits for y/n", s.Confirming() == "flatten")
+	runCommandCases(s, []commandCase{
+		{"Flatten! skips the preview", ":flatten!", "All positions flattened"},
+	})
+	check("Flatten! closes the position", waitFor(func() bool { return pt.GetPLSummary()["MESH6"].NetPos == 0 }))
+}
+
+// openDemoPosition buys one MESH6 on the demo engine e and reports whether the
+// position opened
+func openDemoPosition(e *app.Engine) bool {
+	e.MarketData().SubscribeQuote("MESH6")
+	var err error
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err = e.OrderManager().SubmitMarketOrder("MESH6", models.SideBuy, 1); err == nil {
+			break
+		}
+	}
+	if err != nil || !waitFor(func() bool { return e.Portfolio().GetPLSummary()["MESH6"].NetPos == 1 }) {
+		check(fmt.Sprintf("Demo position opens (Error: %v)", err), false)
+		return false
+	}
+	return true
+}
+
+func testUIClosePosition() {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	cfg := &config.Config{Risk: config.RiskConfig{DailyLossLimit: 500}}
+	if err := e.ConnectDemo(cfg); err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Shutdown(false)
+	s := UI.NewCommandSession(e, quiet)
+	s.SetLive(true)
+	if !openDemoPosition(e) {
+		return
+	}
+
+	runCommandCases(s, []commandCase{
+		{"Close sends the opposing order", ":close MESH6", "Closing MESH6: Sell 1"},
+	})
+	var statuses []string
+	closed := waitFor(func() bool {
+		status := s.CheckCloses()
+		if len(statuses) == 0 || statuses[len(statuses)-1] != status {
+			statuses = append(statuses, status)
+		}
+		return strings.Contains(status, "position closed")
+	})
+	check(fmt.Sprintf("Close reports the position closed (got %q)", statuses), closed)
+	check("Close never reports the position still open", !strings.Contains(strings.Join(statuses, "|"), "still open"))
+}
+
+// newReloadSession returns a session on a connected demo engine whose :reload
+// reads a config file under the temp directory, and that file's path
+func newReloadSession() (*UI.CommandSession, *app.Engine, string, error) {
+	quiet := logger.NewLogger(100, logger.LevelError)
+	e := app.NewEngine(quiet, quiet, quiet)
+	cfg := &config.Config{Risk: config.RiskConfig{MaxContracts: 5, DailyLossLimit: 500}}
+	if err := e.ConnectDemo(cfg); err != nil {
+		return nil, nil, "", err
+	}
+	path := filepath.Join(os.TempDir(), "ui_command_reload_config.json")
+	s := UI.NewCommandSession(e, quiet)
+	s.SetConfigPath(path)
+	return s, e, path, nil
+}
+
+func testUIReloadSlippage() {
+	s, e, path, err := newReloadSession()
+	if err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Shutdown(false)
+	defer os.Remove(path)
+
+	saved := *e.Config()
+	config.SaveConfig(path, &saved)
+	runCommandCases(s, []commandCase{
+		{"Reloading an unchanged config says so", ":reload", "no changes detected"},
+	})
+
+	saved.Slippage = config.SlippageConfig{Model: config.SlippageFixed, Ticks: 2}
+	config.SaveConfig(path, &saved)
+	runCommandCases(s, []commandCase{
+		{"A slippage-only reload is reported as applied", ":reload", "Applied 2"},
+	})
+	check("The reloaded slippage reaches the engine", e.Config().Slippage.Ticks == 2)
+}
+
+func testUIReloadAllOrNothing() {
+	s, e, path, err := newReloadSession()
+	if err != nil {
+		check(fmt.Sprintf("Demo connects (Error: %v)", err), false)
+		return
+	}
+	defer e.Shutdown(false)
+	defer os.Remove(path)
+
+	running := *e.Config()
+	edited := running
+	edited.Risk.MaxContracts = 3
+	edited.Schedule = config.ScheduleConfig{Enabled: true, Start: "bogus", End: "16:00"}
+	config.SaveConfig(path, &edited)
+	runCommandCases(s, []commandCase{
+		{"A bad section fails the reload", ":reload", "Schedule not applied"},
+	})
+	check("Sections before the bad one are not applied", e.Config().Risk.MaxContracts == running.Risk.MaxContracts)
+
+	edited.Schedule = running.Schedule
+	edited.Fees.Commission = 0.5
+	config.SaveConfig(path, &edited)
+	runCommandCases(s, []commandCase{
+		{"The status names the sections changed", ":reload", "Applied 2 risk/fee change(s) without reconnecting"},
+	})
+	check("Every changed section is applied", e.Config().Risk.MaxContracts == 3 && e.Config().Fees.Commission == 0.5)
+
+	edited.Tradovate.Username = "someone"
+	config.SaveConfig(path, &edited)
+	runCommandCases(s, []commandCase{
+		{"A credential change alone only asks for a reconnect", ":reload", "Reconnect (!) required for: username"},
+	})
+}
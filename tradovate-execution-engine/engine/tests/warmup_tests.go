@@ -0,0 +1,261 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"tradovate-execution-engine/engine/internal/execution"
+	"tradovate-execution-engine/engine/internal/logger"
+	"tradovate-execution-engine/engine/internal/marketdata"
+	"tradovate-execution-engine/engine/strategies"
+)
+
+// RunWarmupTests executes all tests for strategy warm-up and the live hand-off.
+func RunWarmupTests() {
+	testWarmupEOHWithBars()
+	testWarmupInterleavedCharts()
+	testWarmupChartPayloads()
+	testWarmupForeignCharts()
+	testWarmupSeam()
+	testWarmupSeamSameBar()
+	testWarmupPreloadOverlap()
+	testWarmupBarsFor()
+	testWarmupReadiness()
+	testWarmupReadyWithoutReporter()
+	testMACrossoverIsReady()
+}
+
+// barRecorder is a strategy that records the closes it is fed
+type barRecorder struct {
+	closes []float64
+	params []execution.StrategyParam
+}
+
+func (r *barRecorder) Name() string                         { return "recorder" }
+func (r *barRecorder) Description() string                  { return "" }
+func (r *barRecorder) GetParams() []execution.StrategyParam { return r.params }
+func (r *barRecorder) SetParam(string, string) error        { return nil }
+func (r *barRecorder) Init(execution.Broker) error          { return nil }
+func (r *barRecorder) GetMetrics() map[string]float64       { return nil }
+func (r *barRecorder) Reset()                               {}
+func (r *barRecorder) SetLogger(*logger.Logger)             {}
+func (r *barRecorder) OnBar(_ string, close float64) error {
+	r.closes = append(r.closes, close)
+	return nil
+}
+
+// primedRecorder is a barRecorder that reports itself ready after a number of bars
+type primedRecorder struct {
+	barRecorder
+	primedAfter int
+}
+
+func (r *primedRecorder) IsReady() bool { return len(r.closes) >= r.primedAfter }
+
+var warmupBase = time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)
+
+// minuteBar is the bar for minute i after warmupBase
+func minuteBar(i int, close float64) marketdata.Bar {
+	return marketdata.Bar{Timestamp: warmupBase.Add(time.Duration(i) * time.Minute).Format(time.RFC3339), Close: close}
+}
+
+func chartUpdate(id int, eoh bool, bars ...marketdata.Bar) marketdata.ChartUpdate {
+	return marketdata.ChartUpdate{Charts: []marketdata.Chart{{ID: id, Bars: bars, EOH: eoh}}}
+}
+
+func closesEqual(got []float64, want ...float64) bool {
+	return fmt.Sprint(got) == fmt.Sprint(want)
+}
+
+func testWarmupEOHWithBars() {
+	rec := &barRecorder{}
+	lives := 0
+	w := execution.NewStrategyWarmup(rec, func() { lives++ })
+
+	w.OnChart(chartUpdate(1, false, minuteBar(0, 10), minuteBar(1, 11)))
+	check("Nothing is fed before the end of history", len(rec.closes) == 0 && !w.Live())
+
+	w.OnChart(chartUpdate(1, true, minuteBar(2, 12), minuteBar(3, 13)))
+	check("Bars sent with the end of history are used", closesEqual(rec.closes, 10, 11, 12))
+	check("Strategy goes live once", w.Live() && lives == 1)
+
+	w.OnChart(chartUpdate(1, true))
+	check("A repeated end of history is ignored", lives == 1)
+}
+
+func testWarmupInterleavedCharts() {
+	rec := &barRecorder{}
+	w := execution.NewStrategyWarmup(rec, nil)
+
+	w.OnChart(marketdata.ChartUpdate{Charts: []marketdata.Chart{
+		{ID: 1, Bars: []marketdata.Bar{minuteBar(3, 13), minuteBar(1, 11)}},
+		{ID: 2, Bars: []marketdata.Bar{minuteBar(2, 12), minuteBar(1, 11)}},
+	}})
+	w.OnChart(chartUpdate(2, false, minuteBar(0, 10), minuteBar(4, 14)))
+	w.OnChart(chartUpdate(1, true))
+	check(fmt.Sprintf("Interleaved charts are fed in time order, each bar once (got %v)", rec.closes),
+		closesEqual(rec.closes, 10, 11, 12, 13))
+}
+
+// chartPayload parses a chart event body as Tradovate sends it
+func chartPayload(payload string) marketdata.ChartUpdate {
+	var update marketdata.ChartUpdate
+	if err := json.Unmarshal([]byte(payload), &update); err != nil {
+		check(fmt.Sprintf("Chart payload parses (Error: %v)", err), false)
+	}
+	return update
+}
+
+func testWarmupChartPayloads() {
+	rec := &barRecorder{}
+	w := execution.NewStrategyWarmup(rec, nil)
+	w.OnChart(chartPayload(`{"charts":[{"id":12,"td":20260105,"bars":[
+		{"timestamp":"2026-01-05T15:00:00Z","close":10},{"timestamp":"2026-01-05T15:01:00Z","close":11},
+		{"timestamp":"2026-01-05T15:02:00Z","close":12}],"eoh":true}]}`))
+	check("Bars on the chart object carrying the end of history are fed", w.Live() && closesEqual(rec.closes, 10, 11) && w.Fed() == 2)
+
+	rec = &barRecorder{}
+	w = execution.NewStrategyWarmup(rec, nil)
+	w.OnChart(chartPayload(`{"charts":[{"id":12,"td":20260105,"bars":[{"timestamp":"2026-01-05T15:00:00Z","close":10}]}]}`))
+	w.OnChart(chartPayload(`{"charts":[
+		{"id":12,"td":20260105,"bars":[{"timestamp":"2026-01-05T15:01:00Z","close":11},{"timestamp":"2026-01-05T15:02:00Z","close":12}]},
+		{"id":12,"eoh":true}]}`))
+	check("The last bars and the end of history in separate objects of one update are all used", w.Live() && closesEqual(rec.closes, 10, 11))
+	w.OnLiveBar(minuteBar(3, 13))
+	check("The newest history bar is fed before the first live bar", closesEqual(rec.closes, 10, 11, 12, 13))
+
+	rec = &barRecorder{}
+	w = execution.NewStrategyWarmup(rec, nil)
+	w.OnChart(chartPayload(`{"charts":[{"id":12,"eoh":true},
+		{"id":12,"bars":[{"timestamp":"2026-01-05T15:00:00Z","close":10},{"timestamp":"2026-01-05T15:01:00Z","close":11}]}]}`))
+	check("Bars after the end of history in the same update continue the history", w.Live() && closesEqual(rec.closes, 10))
+}
+
+func testWarmupForeignCharts() {
+	rec := &barRecorder{}
+	lives := 0
+	w := execution.NewStrategyWarmup(rec, func() { lives++ })
+	w.SetChartIDs(12, 11)
+
+	w.OnChart(chartPayload(`{"charts":[
+		{"id":11,"bars":[{"timestamp":"2026-01-05T15:00:00Z","close":10}]},
+		{"id":31,"bars":[{"timestamp":"2026-01-05T15:00:00Z","close":99},{"timestamp":"2026-01-05T15:05:00Z","close":99}]},
+		{"id":12,"bars":[{"timestamp":"2026-01-05T15:01:00Z","close":11}]},
+		{"id":31,"eoh":true}]}`))
+	check("Another chart's end of history is ignored", !w.Live() && lives == 0)
+
+	w.OnChart(chartPayload(`{"charts":[
+		{"id":12,"bars":[{"timestamp":"2026-01-05T15:02:00Z","close":12}],"eoh":true},
+		{"id":32,"bars":[{"timestamp":"2026-01-05T15:06:00Z","close":99}]}]}`))
+	check("The strategy's own chart ends its history", w.Live() && lives == 1)
+	check(fmt.Sprintf("Only the strategy's chart IDs are fed (got %v)", rec.closes), closesEqual(rec.closes, 10, 11))
+
+	w.OnChart(chartPayload(`{"charts":[{"id":32,"bars":[{"timestamp":"2026-01-05T15:07:00Z","close":99}]}]}`))
+	w.OnLiveBar(minuteBar(3, 13))
+	check("Another chart's bars do not move the held bar", closesEqual(rec.closes, 10, 11, 12, 13))
+}
+
+func testWarmupSeam() {
+	rec := &barRecorder{}
+	w := execution.NewStrategyWarmup(rec, nil)
+	w.OnChart(chartUpdate(1, true, minuteBar(0, 10), minuteBar(1, 11), minuteBar(2, 12)))
+	check("Newest history bar is held while it may be forming", closesEqual(rec.closes, 10, 11))
+
+	w.OnChart(chartUpdate(1, false, minuteBar(2, 12.5)))
+	w.OnLiveBar(minuteBar(3, 13))
+	check("Held bar is fed with its latest close before the first live bar", closesEqual(rec.closes, 10, 11, 12.5, 13))
+
+	w.OnChart(chartUpdate(1, false, minuteBar(4, 99)))
+	w.OnLiveBar(minuteBar(3, 13))
+	check("Chart bars after the hand-off and repeated live bars are dropped", closesEqual(rec.closes, 10, 11, 12.5, 13))
+
+	w.OnLiveBar(minuteBar(4, 14))
+	check("Live bars continue after the seam", closesEqual(rec.closes, 10, 11, 12.5, 13, 14) && w.Fed() == 5)
+}
+
+func testWarmupSeamSameBar() {
+	rec := &barRecorder{}
+	w := execution.NewStrategyWarmup(rec, nil)
+	w.OnLiveBar(minuteBar(0, 1))
+	check("Live bars before the end of history are dropped", len(rec.closes) == 0)
+
+	w.OnChart(chartUpdate(1, true, minuteBar(0, 10), minuteBar(1, 11)))
+	w.OnLiveBar(minuteBar(1, 11.75))
+	check("A live bar for the held bar's time replaces it", closesEqual(rec.closes, 10, 11.75))
+}
+
+func testWarmupPreloadOverlap() {
+	rec := &barRecorder{}
+	w := execution.NewStrategyWarmup(rec, nil)
+	w.Preload([]marketdata.Bar{minuteBar(0, 10), minuteBar(1, 11), minuteBar(2, 12)})
+	check("Preloaded history is fed up to its newest bar", closesEqual(rec.closes, 10, 11))
+
+	w.OnChart(chartUpdate(1, true, minuteBar(1, 11), minuteBar(2, 12.25), minuteBar(3, 13)))
+	check("Chart bars overlapping the preload are not fed twice", closesEqual(rec.closes, 10, 11, 12.25))
+}
+
+func testWarmupBarsFor() {
+	log := logger.NewLogger(10, logger.LevelDebug)
+	ma := strategies.NewDefaultMACrossover(log)
+	ma.SetParam("slow_length", "60")
+	check("WarmupProvider sets the warm-up", execution.WarmupBarsFor(ma) == 62)
+
+	rec := &barRecorder{params: []execution.StrategyParam{
+		{Name: "fast_period", Type: "int", Value: "9"},
+		{Name: "atr_length", Type: "int", Value: "40"},
+		{Name: "quantity", Type: "int", Value: "100"},
+	}}
+	check("Largest length or period param sets the warm-up otherwise", execution.WarmupBarsFor(rec) == 41)
+	check("Strategies without either get the default", execution.WarmupBarsFor(&barRecorder{}) == execution.DefaultWarmupBars)
+}
+
+func testWarmupReadiness() {
+	rec := &primedRecorder{primedAfter: 4}
+	var events []string
+	var w *execution.StrategyWarmup
+	w = execution.NewStrategyWarmup(rec, func() {
+		fed, _ := w.Progress() // Callbacks run outside the warm-up's lock
+		events = append(events, fmt.Sprintf("live %d", fed))
+	})
+	w.SetReadyHandler(func() { events = append(events, fmt.Sprintf("ready %d", w.Fed())) })
+
+	w.OnChart(chartUpdate(1, true, minuteBar(0, 10), minuteBar(1, 11), minuteBar(2, 12)))
+	fed, _ := w.Progress()
+	check("Short history goes live without being ready", w.Live() && !w.Ready() && fed == 2)
+	check("Only the live callback has run", fmt.Sprint(events) == "[live 2]")
+
+	w.OnLiveBar(minuteBar(3, 13))
+	check("Live bar that primes the strategy makes it ready", w.Ready() && fmt.Sprint(events) == "[live 2 ready 4]")
+
+	w.OnLiveBar(minuteBar(4, 14))
+	check("Ready callback runs once", len(events) == 2)
+}
+
+func testWarmupReadyWithoutReporter() {
+	rec := &barRecorder{params: []execution.StrategyParam{{Name: "slow_length", Type: "int", Value: "9"}}}
+	ready := 0
+	w := execution.NewStrategyWarmup(rec, nil)
+	w.SetReadyHandler(func() { ready++ })
+
+	w.Preload([]marketdata.Bar{minuteBar(0, 10), minuteBar(1, 11)})
+	check("Preloaded history is not ready before the end of history", !w.Ready() && ready == 0)
+	w.OnChart(chartUpdate(1, true, minuteBar(2, 12)))
+	_, need := w.Progress()
+	check("Strategy without IsReady is ready once live", w.Ready() && ready == 1)
+	check("Progress reports the bars the strategy asked for", need == 10)
+}
+
+func testMACrossoverIsReady() {
+	ma := strategies.NewDefaultMACrossover(logger.NewLogger(10, logger.LevelWarn))
+	ma.SetParam("fast_length", "2")
+	ma.SetParam("slow_length", "3")
+	check("Uninitialized MA crossover is not ready", !ma.IsReady())
+	ma.Init(nil)
+
+	for i := 0; i < 3; i++ {
+		ma.OnBar(minuteBar(i, 100+float64(i)).Timestamp, 100+float64(i))
+	}
+	check("One full slow average is not enough for a cross", !ma.IsReady())
+	ma.OnBar(minuteBar(3, 103).Timestamp, 103)
+	check("Slow average for this bar and the one before makes it ready", ma.IsReady())
+}
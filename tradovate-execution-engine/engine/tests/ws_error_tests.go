@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"tradovate-execution-engine/engine/internal/tradovate"
+)
+
+// RunWSErrorTests executes all tests for refused WebSocket requests.
+func RunWSErrorTests() {
+	testRefusedQuoteSubscription()
+	testRefusalInBatchFrame()
+	testRefusedUserSync()
+	testChartErrorIsWSError()
+}
+
+// recordedFrame is one frame of a scripted exchange
+type recordedFrame struct{ dir, frame string }
+
+// authorizedFrames open and authorize a connection
+var authorizedFrames = []recordedFrame{
+	{tradovate.FrameIn, "o"},
+	{tradovate.FrameOut, "authorize\n1\n\ntoken"},
+	{tradovate.FrameIn, `a[{"i":1,"s":200}]`},
+}
+
+// replayFrames records an authorized connection followed by frames and replays
+// it into ds, so responses pass through the client's frame parsing
+func replayFrames(ds *tradovate.DataSubscriber, frames ...recordedFrame) error {
+	dir, err := os.MkdirTemp("", "recording")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	rec, err := tradovate.NewRecorder(dir, "md")
+	if err != nil {
+		return err
+	}
+	for _, f := range append(authorizedFrames, frames...) {
+		rec.Record(f.dir, f.frame)
+	}
+	if err := rec.Close(); err != nil {
+		return err
+	}
+	_, err = ds.Replay(rec.Path(), 0)
+	return err
+}
+
+func testRefusedQuoteSubscription() {
+	ds, sender := newQuoteSubscriber()
+	var mesErrors, otherErrors []*tradovate.WSError
+	ds.AddRequestErrorHandlerFor("MESH6", func(err *tradovate.WSError) { mesErrors = append(mesErrors, err) })
+	ds.AddRequestErrorHandlerFor("NQH6", func(err *tradovate.WSError) { otherErrors = append(otherErrors, err) })
+	removed := ds.AddRequestErrorHandlerFor("", func(err *tradovate.WSError) { otherErrors = append(otherErrors, err) })
+	ds.RemoveRequestErrorHandler(removed)
+
+	ds.SubscribeQuote("MESH6")
+	err := replayFrames(ds,
+		recordedFrame{tradovate.FrameOut, "md/subscribequote\n1\n\n{\"symbol\":\"MESH6\"}"},
+		recordedFrame{tradovate.FrameIn, `a[{"i":1,"s":404,"d":"Unknown symbol: MESH6"}]`})
+	check("Error frame replays", err == nil)
+
+	check("Refused quote subscription reaches its symbol's handler", len(mesErrors) == 1)
+	if len(mesErrors) == 1 {
+		wsErr := mesErrors[0]
+		check("WSError carries the request, status and reason",
+			wsErr.RequestID == 1 && wsErr.URL == "md/subscribequote" && wsErr.Status == 404 && wsErr.StatusText == "Unknown symbol: MESH6")
+		check("WSError names the refused symbol", len(wsErr.Symbols) == 1 && wsErr.Symbols[0] == "MESH6")
+	}
+	check("Other symbols' and removed handlers are not called", len(otherErrors) == 0)
+	check("Refused quote subscription is no longer active", len(quoteRefs(ds)) == 0)
+
+	ds.SubscribeQuote("MESH6")
+	check("Subscribing again sends a new request", len(sender.sent) == 2 && quoteMessage(sender.sent[1], "md/subscribequote", "MESH6"))
+}
+
+func testRefusalInBatchFrame() {
+	ds, _ := newQuoteSubscriber()
+	var refused []string
+	ds.AddRequestErrorHandlerFor("", func(err *tradovate.WSError) { refused = append(refused, err.Symbols...) })
+
+	ds.SubscribeQuote("MESH6")
+	ds.SubscribeQuote("NQH6")
+	replayFrames(ds,
+		recordedFrame{tradovate.FrameOut, "md/subscribequote\n1\n\n{\"symbol\":\"MESH6\"}"},
+		recordedFrame{tradovate.FrameOut, "md/subscribequote\n2\n\n{\"symbol\":\"NQH6\"}"},
+		recordedFrame{tradovate.FrameIn, `a[{"i":1,"s":200,"d":{}},{"i":2,"s":404,"statusText":"No market data entitlement"}]`})
+
+	refs := quoteRefs(ds)
+	check("Only the refused request is reported", len(refused) == 1 && refused[0] == "NQH6")
+	check("Accepted subscription stays active", refs["MESH6"] == 1 && refs["NQH6"] == 0)
+}
+
+func testRefusedUserSync() {
+	sender := &recordingSender{}
+	ds := tradovate.NewDataSubscriptionManager(sender)
+	var got error
+	ds.AddRequestErrorHandlerFor("", func(err *tradovate.WSError) { got = err })
+
+	ds.SubscribeUserSyncRequests([]int{1}, []int{7})
+	replayFrames(ds,
+		recordedFrame{tradovate.FrameOut, "user/syncrequest\n1\n\n{\"users\":[1],\"accounts\":[7]}"},
+		recordedFrame{tradovate.FrameIn, `a[{"i":1,"s":403,"statusText":"Access denied"}]`})
+
+	var wsErr *tradovate.WSError
+	check("Refused user sync is reported as a WSError", errors.As(got, &wsErr) && wsErr.Status == 403 && len(wsErr.Symbols) == 0)
+	check("WSError message names the endpoint and reason", got != nil &&
+		strings.Contains(got.Error(), "user/syncrequest") && strings.Contains(got.Error(), "Access denied"))
+	check("Refused user sync is no longer active", len(ds.GetActiveSubscriptions()) == 0)
+
+	ds.SubscribeUserSyncRequests([]int{1}, []int{7})
+	check("User sync can be requested again", len(sender.sent) == 2)
+}
+
+func testChartErrorIsWSError() {
+	ds, _ := newQuoteSubscriber()
+	var quoteErrors int
+	var chartErr error
+	ds.AddRequestErrorHandlerFor("", func(*tradovate.WSError) { quoteErrors++ })
+	ds.AddChartErrorHandlerFor("", func(err *tradovate.ChartError) { chartErr = err })
+
+	ds.SubscribeChart(testChartParams())
+	replayFrames(ds,
+		recordedFrame{tradovate.FrameOut, "md/getchart\n1\n\n{}"},
+		recordedFrame{tradovate.FrameIn, `a[{"i":1,"s":404,"d":"Unknown symbol"}]`})
+
+	var wsErr *tradovate.WSError
+	check("Refused chart still goes to the chart error handlers", chartErr != nil && quoteErrors == 0)
+	check("ChartError unwraps to a WSError", errors.As(chartErr, &wsErr) &&
+		wsErr.URL == "md/getchart" && wsErr.Status == 404 && wsErr.StatusText == "Unknown symbol")
+}
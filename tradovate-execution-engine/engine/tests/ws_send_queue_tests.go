@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+	"tradovate-execution-engine/engine/internal/tradovate"
+
+	"github.com/gorilla/websocket"
+)
+
+// RunWSSendQueueTests executes all tests for the WebSocket send queue.
+func RunWSSendQueueTests() {
+	testSendQueueDelivers()
+	testStalledConnectionFails()
+}
+
+// wsServer is a local Tradovate WebSocket endpoint. It opens each connection,
+// answers every authorize frame, refusing the token "bad", and records the
+// frames it reads. A stalled server stops reading after the first
+// authorization so the client's writes back up.
+type wsServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	frames  []string
+	stalled bool
+	release chan struct{}
+}
+
+func newWSServer(stalled bool) *wsServer {
+	s := &wsServer{stalled: stalled, release: make(chan struct{})}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte("o"))
+		for {
+			_, frame, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.frames = append(s.frames, string(frame))
+			s.mu.Unlock()
+
+			parts := strings.SplitN(string(frame), "\n", 4)
+			if len(parts) != 4 || parts[0] != "authorize" {
+				continue
+			}
+			reply := fmt.Sprintf(`a[{"i":%s,"s":200}]`, parts[1])
+			if parts[3] == "bad" {
+				reply = fmt.Sprintf(`a[{"i":%s,"s":401,"d":"Access is denied"}]`, parts[1])
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(reply))
+			if s.stalled {
+				<-s.release
+				return
+			}
+		}
+	}))
+	return s
+}
+
+// received returns the frames read so far, heartbeats and authorizations left out
+func (s *wsServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var frames []string
+	for _, f := range s.frames {
+		if f != "[]" && !strings.HasPrefix(f, "authorize\n") {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// authorizations returns the tokens of the authorize frames read so far
+func (s *wsServer) authorizations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tokens []string
+	for _, f := range s.frames {
+		if parts := strings.SplitN(f, "\n", 4); len(parts) == 4 && parts[0] == "authorize" {
+			tokens = append(tokens, parts[3])
+		}
+	}
+	return tokens
+}
+
+func (s *wsServer) close() {
+	close(s.release)
+	s.Close()
+}
+
+func newTestWSClient(s *wsServer) *tradovate.TradovateWebSocketClient {
+	client := tradovate.NewTradovateWebSocketClient("token", "demo", "md")
+	client.SetURL("ws" + strings.TrimPrefix(s.URL, "http"))
+	return client
+}
+
+func testSendQueueDelivers() {
+	server := newWSServer(false)
+	defer server.close()
+	client := newTestWSClient(server)
+	if err := client.Connect(); err != nil {
+		check(fmt.Sprintf("Client connects to the local server (Error: %v)", err), false)
+		return
+	}
+	defer client.Disconnect()
+
+	var ids []int
+	for i := 0; i < 20; i++ {
+		id, err := client.SendRequest("md/getchart", map[string]int{"n": i})
+		if err != nil {
+			check(fmt.Sprintf("Requests are queued (Error: %v)", err), false)
+			return
+		}
+		ids = append(ids, id)
+	}
+	check("Each queued request gets its own ID", len(ids) == 20 && ids[0] != ids[19])
+	check("Queued requests are all written", waitFor(func() bool { return len(server.received()) == 20 }))
+
+	frames := server.received()
+	inOrder := len(frames) == 20
+	for i, f := range frames {
+		inOrder = inOrder && strings.HasPrefix(f, fmt.Sprintf("md/getchart\n%d\n", ids[i]))
+	}
+	check("Requests are written in the order they were sent", inOrder)
+	check("Client stays connected", client.IsConnected())
+}
+
+func testStalledConnectionFails() {
+	server := newWSServer(true)
+	defer server.close()
+	client := newTestWSClient(server)
+	client.SetSendQueue(2, time.Second)
+	failed := make(chan error, 1)
+	client.SetSendErrorHandler(func(err error) { failed <- err })
+	if err := client.Connect(); err != nil {
+		check(fmt.Sprintf("Client connects to the local server (Error: %v)", err), false)
+		return
+	}
+	defer client.Disconnect()
+
+	// Frames large enough that a server which stops reading fills the socket buffers
+	body := map[string]string{"pad": strings.Repeat("x", 1<<20)}
+	var err error
+	var slowest time.Duration
+	for i := 0; i < 100 && err == nil; i++ {
+		began := time.Now()
+		_, err = client.SendRequest("md/getchart", body)
+		slowest = max(slowest, time.Since(began))
+	}
+	check("A full queue refuses the send with ErrSendQueueFull", errors.Is(err, tradovate.ErrSendQueueFull))
+	check("Sending never waits on the stalled connection", slowest < 500*time.Millisecond)
+
+	// Keep sending until the socket buffers are full and a write times out
+	var writeErr error
+	deadline, timedOut := time.After(5*time.Second), false
+	for writeErr == nil && !timedOut {
+		select {
+		case writeErr = <-failed:
+		case <-deadline:
+			timedOut = true
+		default:
+			if _, err := client.SendRequest("md/getchart", body); errors.Is(err, tradovate.ErrSendQueueFull) {
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+	}
+	check("A write past its deadline reports the error", writeErr != nil)
+	check("The failed connection is closed", !client.IsConnected())
+	_, err = client.SendRequest("md/getchart", nil)
+	check("Sends after the failure are refused", err != nil && !errors.Is(err, tradovate.ErrSendQueueFull))
+}